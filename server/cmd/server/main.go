@@ -3,6 +3,7 @@ package main
 
 import (
 	"log"
+	_ "time/tzdata" // Embed timezone data for LEMMA_TIMEZONE on systems without it
 
 	"lemma/internal/app"
 	"lemma/internal/logging"
@@ -35,14 +36,14 @@ func main() {
 	}
 
 	server := app.NewServer(options)
-	defer func() {
-		if err := server.Close(); err != nil {
-			logging.Error("Failed to close server:", err)
-		}
-	}()
 
-	// Start server
-	if err := server.Start(); err != nil {
+	// Start server, the server is closed before exiting on an error as well,
+	// so commits waiting to be pushed are not lost
+	err = server.Start()
+	if closeErr := server.Close(); closeErr != nil {
+		logging.Error("Failed to close server", "error", closeErr.Error())
+	}
+	if err != nil {
 		log.Fatal("Server error:", err)
 	}
 }
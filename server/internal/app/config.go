@@ -3,8 +3,11 @@ package app
 import (
 	"fmt"
 	"lemma/internal/db"
+	"lemma/internal/events"
 	"lemma/internal/logging"
+	"lemma/internal/models"
 	"lemma/internal/secrets"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -12,6 +15,9 @@ import (
 	"time"
 )
 
+// hstsPreloadMinMaxAge is the shortest max-age accepted by the HSTS preload list
+const hstsPreloadMinMaxAge = 365 * 24 * time.Hour
+
 // Config holds the configuration for the application
 type Config struct {
 	DBURL             string
@@ -29,6 +35,96 @@ type Config struct {
 	RateLimitWindow   time.Duration
 	IsDevelopment     bool
 	LogLevel          logging.LogLevel
+	DefaultPageSize   int
+	MaxPageSize       int
+	DefaultHomeFile   string
+	// Timezone is the IANA timezone used for date and time template variables
+	Timezone string
+	// LogExcludePaths are path prefixes whose requests are left out of the access log, e.g. health checks
+	LogExcludePaths []string
+	// Languages overrides the language hints of file extensions, e.g. .tpl=html
+	Languages map[string]string
+	// RenderAllowedElements are the HTML elements kept in rendered markdown, empty uses the built-in allowlist
+	RenderAllowedElements []string
+
+	// SecureCookiesOnly refuses to issue auth cookies on requests not made over HTTPS, it cannot be used in development
+	SecureCookiesOnly bool
+	// HSTSMaxAge sends Strict-Transport-Security with this max-age, 0 disables the header
+	HSTSMaxAge time.Duration
+	// HSTSPreload adds includeSubDomains and preload to the HSTS header, it requires a max-age of at least a year
+	HSTSPreload bool
+	// RoleRateLimits are the requests per RateLimitWindow each authenticated user may make by role,
+	// users of roles without a limit are only limited by IP on the public routes
+	RoleRateLimits map[models.UserRole]int
+	// RequestTimeout cancels requests running longer than this, event streams and websockets are exempt.
+	// 0 disables the timeout.
+	RequestTimeout time.Duration
+
+	// SQLiteOptions are extra driver options for SQLite connections, e.g. _journal_mode=WAL
+	SQLiteOptions map[string]string
+
+	// StatsRefreshInterval is how often cached file statistics are recomputed, 0 disables the refresh
+	StatsRefreshInterval time.Duration
+	// ActivityRetention is how long workspace activity entries are kept, 0 keeps them forever
+	ActivityRetention time.Duration
+	// ActivityMaxEntries is how many of the newest workspace activity entries are kept, 0 disables the limit
+	ActivityMaxEntries int
+	// ActivityArchivePath is a file pruned activity entries are appended to as JSON lines, empty discards them
+	ActivityArchivePath string
+	// TrashRetention is how long deleted files are kept in the trash, 0 keeps them until they are removed
+	TrashRetention time.Duration
+	// SessionRefreshWindow is how close to expiry an access token is reissued on a request, 0 disables the refresh
+	SessionRefreshWindow time.Duration
+
+	// AllowedGitHosts restricts workspace git remotes to these hosts, empty allows all
+	AllowedGitHosts []string
+	// BlockPrivateGitHosts rejects non-http(s) git remotes and remotes on localhost or private IPs
+	BlockPrivateGitHosts bool
+	// GitCommitIdentityFallback commits as the acting user when a workspace has no commit name or email
+	GitCommitIdentityFallback bool
+	// GitPushGracePeriod delays pushing after an auto-commit so that commits made within it are pushed together,
+	// 0 pushes after every commit
+	GitPushGracePeriod time.Duration
+	// GitPushMaxPendingCommits pushes before the grace period ends once this many commits wait, 0 disables the limit
+	GitPushMaxPendingCommits int
+	// FollowSymlinks allows symlinks inside workspaces, by default they are hidden and operations on them rejected
+	FollowSymlinks bool
+	// MaxTreeNodes limits how many entries a directory handled by recursive operations may contain, 0 disables the limit
+	MaxTreeNodes int
+	// MaxTreeDepth limits how deeply nested a directory handled by recursive operations may be, 0 disables the limit
+	MaxTreeDepth int
+	// MaxContentSize is the largest request body in bytes accepted when saving a file, 0 disables the limit
+	MaxContentSize int64
+	// MaxFileSize is the largest file in bytes accepted when saving or uploading a file, 0 disables the limit
+	MaxFileSize int64
+	// MaxUploadSize is the largest upload request in bytes, covering all of its files, 0 disables the limit
+	MaxUploadSize int64
+	// MaxUploadMemory is how many bytes of an upload are held in memory, the rest is stored in temporary files
+	MaxUploadMemory int64
+	// MaxFileVersions is how many previous versions of a file are kept when it is saved, 0 disables file versions
+	MaxFileVersions int
+
+	// MaxEventStreamsPerUser limits the concurrent workspace event streams of a user, 0 disables the limit
+	MaxEventStreamsPerUser int
+	// MaxEventStreamsPerWorkspace limits the concurrent event streams of a workspace, 0 disables the limit
+	MaxEventStreamsPerWorkspace int
+	// EventStreamLimitPolicy decides whether streams over a limit are rejected or replace the oldest stream
+	EventStreamLimitPolicy events.LimitPolicy
+
+	// MinWorkspacesPerUser is how many workspaces a user must keep, deleting below it is rejected
+	MinWorkspacesPerUser int
+	// MaxWorkspacesPerUser is how many workspaces a user may create, 0 disables the limit
+	MaxWorkspacesPerUser int
+
+	// ReadOnlyMode rejects all requests that modify data, except logging in and out
+	ReadOnlyMode bool
+	// UniqueDisplayNames rejects creating or renaming users to a display name that is already taken
+	UniqueDisplayNames bool
+	// WorkspaceNameNormalization cleans up workspace names and rejects names that collide after normalization
+	WorkspaceNameNormalization models.WorkspaceNameNormalization
+
+	// EnableProfiling serves the runtime profiles of net/http/pprof to admins under /api/v1/admin/debug/pprof
+	EnableProfiling bool
 }
 
 // DefaultConfig returns a new Config instance with default values
@@ -42,6 +138,32 @@ func DefaultConfig() *Config {
 		RateLimitRequests: 100,
 		RateLimitWindow:   time.Minute * 15,
 		IsDevelopment:     false,
+		DefaultPageSize:   100,
+		MaxPageSize:       1000,
+		DefaultHomeFile:   "index.md",
+		Timezone:          "UTC",
+		LogExcludePaths:   []string{"/healthz", "/readyz", "/metrics"},
+
+		RequestTimeout:       time.Second * 30,
+		StatsRefreshInterval: time.Minute * 5,
+		ActivityRetention:    time.Hour * 24 * 30,
+		TrashRetention:       time.Hour * 24 * 30,
+		SessionRefreshWindow: time.Minute * 5,
+		MaxTreeNodes:         10000,
+		MaxTreeDepth:         64,
+		MaxContentSize:       10 << 20,
+		MaxFileSize:          100 << 20,
+		MaxUploadSize:        512 << 20,
+		MaxUploadMemory:      32 << 20,
+		MaxFileVersions:      20,
+
+		MaxEventStreamsPerUser:      5,
+		MaxEventStreamsPerWorkspace: 10,
+		EventStreamLimitPolicy:      events.PolicyReject,
+
+		MinWorkspacesPerUser: 1,
+
+		WorkspaceNameNormalization: models.WorkspaceNamesUnchanged,
 	}
 }
 
@@ -64,9 +186,66 @@ Then start the server again.`)
 		}
 	}
 
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("invalid LEMMA_TIMEZONE: %w", err)
+	}
+
+	for role, limit := range c.RoleRateLimits {
+		if role != models.RoleAdmin && role != models.RoleEditor && role != models.RoleViewer {
+			return fmt.Errorf("invalid LEMMA_ROLE_RATE_LIMITS: unknown role %q", role)
+		}
+		if limit < 1 {
+			return fmt.Errorf("invalid LEMMA_ROLE_RATE_LIMITS: limit of %s must be positive, got %d", role, limit)
+		}
+	}
+
+	if c.SecureCookiesOnly && c.IsDevelopment {
+		return fmt.Errorf("invalid LEMMA_SECURE_COOKIES_ONLY: cookies are not secure in development mode")
+	}
+
+	if c.RequestTimeout < 0 {
+		return fmt.Errorf("invalid LEMMA_REQUEST_TIMEOUT: %s is negative", c.RequestTimeout)
+	}
+
+	if c.HSTSMaxAge < 0 {
+		return fmt.Errorf("invalid LEMMA_HSTS_MAX_AGE: %s is negative", c.HSTSMaxAge)
+	}
+
+	// The HSTS preload list requires a max-age of at least a year, shorter ones are rejected on submission
+	if c.HSTSPreload && c.HSTSMaxAge < hstsPreloadMinMaxAge {
+		return fmt.Errorf("invalid LEMMA_HSTS_PRELOAD: requires LEMMA_HSTS_MAX_AGE of at least %s, got %s",
+			hstsPreloadMinMaxAge, c.HSTSMaxAge)
+	}
+
+	if c.EventStreamLimitPolicy != events.PolicyReject && c.EventStreamLimitPolicy != events.PolicyCloseOldest {
+		return fmt.Errorf("invalid LEMMA_EVENT_STREAM_LIMIT_POLICY: %q, expected %q or %q",
+			c.EventStreamLimitPolicy, events.PolicyReject, events.PolicyCloseOldest)
+	}
+
+	if c.MaxWorkspacesPerUser > 0 && c.MaxWorkspacesPerUser < c.MinWorkspacesPerUser {
+		return fmt.Errorf("invalid LEMMA_MAX_WORKSPACES_PER_USER: %d is below LEMMA_MIN_WORKSPACES_PER_USER %d",
+			c.MaxWorkspacesPerUser, c.MinWorkspacesPerUser)
+	}
+
+	switch c.WorkspaceNameNormalization {
+	case models.WorkspaceNamesUnchanged, models.WorkspaceNamesTrimmed, models.WorkspaceNamesCaseInsensitive:
+	default:
+		return fmt.Errorf("invalid LEMMA_WORKSPACE_NAME_NORMALIZATION: %q, expected %q, %q or %q",
+			c.WorkspaceNameNormalization, models.WorkspaceNamesUnchanged, models.WorkspaceNamesTrimmed, models.WorkspaceNamesCaseInsensitive)
+	}
+
 	return nil
 }
 
+// Location returns the configured timezone, falling back to UTC if it cannot be loaded
+func (c *Config) Location() *time.Location {
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
 // Redact redacts sensitive fields from a Config instance
 func (c *Config) Redact() *Config {
 	redacted := *c
@@ -74,6 +253,11 @@ func (c *Config) Redact() *Config {
 	redacted.AdminEmail = "[REDACTED]"
 	redacted.EncryptionKey = "[REDACTED]"
 	redacted.JWTSigningKey = "[REDACTED]"
+	if u, err := url.Parse(c.DBURL); err == nil && u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			redacted.DBURL = u.Redacted()
+		}
+	}
 	return &redacted
 }
 
@@ -110,6 +294,27 @@ func LoadConfig() (*Config, error) {
 		config.IsDevelopment = env == "development"
 	}
 
+	if secureOnly := os.Getenv("LEMMA_SECURE_COOKIES_ONLY"); secureOnly != "" {
+		parsed, err := strconv.ParseBool(secureOnly)
+		if err == nil {
+			config.SecureCookiesOnly = parsed
+		}
+	}
+
+	if maxAgeStr := os.Getenv("LEMMA_HSTS_MAX_AGE"); maxAgeStr != "" {
+		parsed, err := time.ParseDuration(maxAgeStr)
+		if err == nil {
+			config.HSTSMaxAge = parsed
+		}
+	}
+
+	if preload := os.Getenv("LEMMA_HSTS_PRELOAD"); preload != "" {
+		parsed, err := strconv.ParseBool(preload)
+		if err == nil {
+			config.HSTSPreload = parsed
+		}
+	}
+
 	if dbURL := os.Getenv("LEMMA_DB_URL"); dbURL != "" {
 		dbType, dataSource, err := ParseDBURL(dbURL)
 		if err != nil {
@@ -119,6 +324,10 @@ func LoadConfig() (*Config, error) {
 		config.DBType = dbType
 	}
 
+	if sqliteOptions := os.Getenv("LEMMA_SQLITE_OPTIONS"); sqliteOptions != "" {
+		config.SQLiteOptions = parseKeyValueList(sqliteOptions)
+	}
+
 	if workDir := os.Getenv("LEMMA_WORKDIR"); workDir != "" {
 		config.WorkDir = workDir
 	}
@@ -139,6 +348,171 @@ func LoadConfig() (*Config, error) {
 		config.CORSOrigins = strings.Split(corsOrigins, ",")
 	}
 
+	// An empty value logs all requests
+	if logExcludePaths, ok := os.LookupEnv("LEMMA_LOG_EXCLUDE_PATHS"); ok {
+		config.LogExcludePaths = nil
+		if logExcludePaths != "" {
+			config.LogExcludePaths = strings.Split(logExcludePaths, ",")
+		}
+	}
+
+	if allowed := os.Getenv("LEMMA_RENDER_ALLOWED_ELEMENTS"); allowed != "" {
+		for _, element := range strings.Split(allowed, ",") {
+			if element = strings.ToLower(strings.TrimSpace(element)); element != "" {
+				config.RenderAllowedElements = append(config.RenderAllowedElements, element)
+			}
+		}
+	}
+
+	if languages := os.Getenv("LEMMA_LANGUAGES"); languages != "" {
+		config.Languages = parseLanguageMap(languages)
+	}
+
+	if allowedGitHosts := os.Getenv("LEMMA_ALLOWED_GIT_HOSTS"); allowedGitHosts != "" {
+		config.AllowedGitHosts = strings.Split(allowedGitHosts, ",")
+	}
+
+	if blockPrivate := os.Getenv("LEMMA_BLOCK_PRIVATE_GIT_HOSTS"); blockPrivate != "" {
+		parsed, err := strconv.ParseBool(blockPrivate)
+		if err == nil {
+			config.BlockPrivateGitHosts = parsed
+		}
+	}
+
+	if identityFallback := os.Getenv("LEMMA_GIT_COMMIT_IDENTITY_FALLBACK"); identityFallback != "" {
+		parsed, err := strconv.ParseBool(identityFallback)
+		if err == nil {
+			config.GitCommitIdentityFallback = parsed
+		}
+	}
+
+	if gracePeriodStr := os.Getenv("LEMMA_GIT_PUSH_GRACE_PERIOD"); gracePeriodStr != "" {
+		parsed, err := time.ParseDuration(gracePeriodStr)
+		if err == nil {
+			config.GitPushGracePeriod = parsed
+		}
+	}
+
+	if maxPendingStr := os.Getenv("LEMMA_GIT_PUSH_MAX_PENDING_COMMITS"); maxPendingStr != "" {
+		parsed, err := strconv.Atoi(maxPendingStr)
+		if err == nil {
+			config.GitPushMaxPendingCommits = parsed
+		}
+	}
+
+	if followSymlinks := os.Getenv("LEMMA_FOLLOW_SYMLINKS"); followSymlinks != "" {
+		parsed, err := strconv.ParseBool(followSymlinks)
+		if err == nil {
+			config.FollowSymlinks = parsed
+		}
+	}
+
+	if maxNodesStr := os.Getenv("LEMMA_MAX_TREE_NODES"); maxNodesStr != "" {
+		parsed, err := strconv.Atoi(maxNodesStr)
+		if err == nil {
+			config.MaxTreeNodes = parsed
+		}
+	}
+
+	if maxDepthStr := os.Getenv("LEMMA_MAX_TREE_DEPTH"); maxDepthStr != "" {
+		parsed, err := strconv.Atoi(maxDepthStr)
+		if err == nil {
+			config.MaxTreeDepth = parsed
+		}
+	}
+
+	if maxSizeStr := os.Getenv("LEMMA_MAX_CONTENT_SIZE"); maxSizeStr != "" {
+		parsed, err := strconv.ParseInt(maxSizeStr, 10, 64)
+		if err == nil {
+			config.MaxContentSize = parsed
+		}
+	}
+
+	if maxSizeStr := os.Getenv("LEMMA_MAX_FILE_SIZE"); maxSizeStr != "" {
+		parsed, err := strconv.ParseInt(maxSizeStr, 10, 64)
+		if err == nil {
+			config.MaxFileSize = parsed
+		}
+	}
+
+	if maxSizeStr := os.Getenv("LEMMA_MAX_UPLOAD_SIZE"); maxSizeStr != "" {
+		parsed, err := strconv.ParseInt(maxSizeStr, 10, 64)
+		if err == nil {
+			config.MaxUploadSize = parsed
+		}
+	}
+
+	if maxMemoryStr := os.Getenv("LEMMA_MAX_UPLOAD_MEMORY"); maxMemoryStr != "" {
+		parsed, err := strconv.ParseInt(maxMemoryStr, 10, 64)
+		if err == nil && parsed > 0 {
+			config.MaxUploadMemory = parsed
+		}
+	}
+
+	if maxVersionsStr := os.Getenv("LEMMA_MAX_FILE_VERSIONS"); maxVersionsStr != "" {
+		parsed, err := strconv.Atoi(maxVersionsStr)
+		if err == nil {
+			config.MaxFileVersions = parsed
+		}
+	}
+
+	if maxStreamsStr := os.Getenv("LEMMA_MAX_EVENT_STREAMS_PER_USER"); maxStreamsStr != "" {
+		parsed, err := strconv.Atoi(maxStreamsStr)
+		if err == nil {
+			config.MaxEventStreamsPerUser = parsed
+		}
+	}
+
+	if maxStreamsStr := os.Getenv("LEMMA_MAX_EVENT_STREAMS_PER_WORKSPACE"); maxStreamsStr != "" {
+		parsed, err := strconv.Atoi(maxStreamsStr)
+		if err == nil {
+			config.MaxEventStreamsPerWorkspace = parsed
+		}
+	}
+
+	if policy := os.Getenv("LEMMA_EVENT_STREAM_LIMIT_POLICY"); policy != "" {
+		config.EventStreamLimitPolicy = events.LimitPolicy(policy)
+	}
+
+	if minWorkspacesStr := os.Getenv("LEMMA_MIN_WORKSPACES_PER_USER"); minWorkspacesStr != "" {
+		parsed, err := strconv.Atoi(minWorkspacesStr)
+		if err == nil && parsed >= 0 {
+			config.MinWorkspacesPerUser = parsed
+		}
+	}
+
+	if maxWorkspacesStr := os.Getenv("LEMMA_MAX_WORKSPACES_PER_USER"); maxWorkspacesStr != "" {
+		parsed, err := strconv.Atoi(maxWorkspacesStr)
+		if err == nil {
+			config.MaxWorkspacesPerUser = parsed
+		}
+	}
+
+	if readOnly := os.Getenv("LEMMA_READ_ONLY"); readOnly != "" {
+		parsed, err := strconv.ParseBool(readOnly)
+		if err == nil {
+			config.ReadOnlyMode = parsed
+		}
+	}
+
+	if uniqueNames := os.Getenv("LEMMA_UNIQUE_DISPLAY_NAMES"); uniqueNames != "" {
+		parsed, err := strconv.ParseBool(uniqueNames)
+		if err == nil {
+			config.UniqueDisplayNames = parsed
+		}
+	}
+
+	if normalization := os.Getenv("LEMMA_WORKSPACE_NAME_NORMALIZATION"); normalization != "" {
+		config.WorkspaceNameNormalization = models.WorkspaceNameNormalization(normalization)
+	}
+
+	if profiling := os.Getenv("LEMMA_ENABLE_PROFILING"); profiling != "" {
+		parsed, err := strconv.ParseBool(profiling)
+		if err == nil {
+			config.EnableProfiling = parsed
+		}
+	}
+
 	config.AdminEmail = os.Getenv("LEMMA_ADMIN_EMAIL")
 	config.AdminPassword = os.Getenv("LEMMA_ADMIN_PASSWORD")
 	config.EncryptionKey = os.Getenv("LEMMA_ENCRYPTION_KEY")
@@ -159,6 +533,79 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	if roleLimits := os.Getenv("LEMMA_ROLE_RATE_LIMITS"); roleLimits != "" {
+		config.RoleRateLimits = parseRoleRateLimits(roleLimits)
+	}
+
+	if timeoutStr := os.Getenv("LEMMA_REQUEST_TIMEOUT"); timeoutStr != "" {
+		parsed, err := time.ParseDuration(timeoutStr)
+		if err == nil {
+			config.RequestTimeout = parsed
+		}
+	}
+
+	// Configure pagination
+	if pageSizeStr := os.Getenv("LEMMA_DEFAULT_PAGE_SIZE"); pageSizeStr != "" {
+		parsed, err := strconv.Atoi(pageSizeStr)
+		if err == nil {
+			config.DefaultPageSize = parsed
+		}
+	}
+
+	if maxPageSizeStr := os.Getenv("LEMMA_MAX_PAGE_SIZE"); maxPageSizeStr != "" {
+		parsed, err := strconv.Atoi(maxPageSizeStr)
+		if err == nil {
+			config.MaxPageSize = parsed
+		}
+	}
+
+	if homeFile := os.Getenv("LEMMA_DEFAULT_HOME_FILE"); homeFile != "" {
+		config.DefaultHomeFile = homeFile
+	}
+
+	if timezone := os.Getenv("LEMMA_TIMEZONE"); timezone != "" {
+		config.Timezone = timezone
+	}
+
+	if intervalStr := os.Getenv("LEMMA_STATS_REFRESH_INTERVAL"); intervalStr != "" {
+		parsed, err := time.ParseDuration(intervalStr)
+		if err == nil {
+			config.StatsRefreshInterval = parsed
+		}
+	}
+
+	if retentionStr := os.Getenv("LEMMA_ACTIVITY_RETENTION"); retentionStr != "" {
+		parsed, err := time.ParseDuration(retentionStr)
+		if err == nil {
+			config.ActivityRetention = parsed
+		}
+	}
+
+	if maxEntriesStr := os.Getenv("LEMMA_ACTIVITY_MAX_ENTRIES"); maxEntriesStr != "" {
+		parsed, err := strconv.Atoi(maxEntriesStr)
+		if err == nil {
+			config.ActivityMaxEntries = parsed
+		}
+	}
+
+	if archivePath := os.Getenv("LEMMA_ACTIVITY_ARCHIVE_PATH"); archivePath != "" {
+		config.ActivityArchivePath = archivePath
+	}
+
+	if retentionStr := os.Getenv("LEMMA_TRASH_RETENTION"); retentionStr != "" {
+		parsed, err := time.ParseDuration(retentionStr)
+		if err == nil {
+			config.TrashRetention = parsed
+		}
+	}
+
+	if windowStr := os.Getenv("LEMMA_SESSION_REFRESH_WINDOW"); windowStr != "" {
+		parsed, err := time.ParseDuration(windowStr)
+		if err == nil {
+			config.SessionRefreshWindow = parsed
+		}
+	}
+
 	// Configure log level, if isDevelopment is set, default to debug
 	if logLevel := os.Getenv("LEMMA_LOG_LEVEL"); logLevel != "" {
 		parsed := logging.ParseLogLevel(logLevel)
@@ -176,3 +623,43 @@ func LoadConfig() (*Config, error) {
 
 	return config, nil
 }
+
+// parseKeyValueList parses a comma separated list of key=value pairs, malformed pairs are skipped
+func parseKeyValueList(value string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" {
+			continue
+		}
+		result[key] = val
+	}
+	return result
+}
+
+// parseRoleRateLimits parses a comma separated list of role=limit pairs, pairs with a malformed limit are skipped
+func parseRoleRateLimits(value string) map[models.UserRole]int {
+	limits := make(map[models.UserRole]int)
+	for role, limit := range parseKeyValueList(value) {
+		parsed, err := strconv.Atoi(strings.TrimSpace(limit))
+		if err != nil {
+			continue
+		}
+		limits[models.UserRole(strings.ToLower(role))] = parsed
+	}
+	return limits
+}
+
+// parseLanguageMap parses a comma separated list of extension=language pairs,
+// extensions are lower cased and prefixed with a dot if it is missing
+func parseLanguageMap(value string) map[string]string {
+	languages := make(map[string]string)
+	for ext, language := range parseKeyValueList(value) {
+		ext = strings.ToLower(ext)
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		languages[ext] = strings.TrimSpace(language)
+	}
+	return languages
+}
@@ -45,7 +45,9 @@ func initSecretsService(cfg *Config) (secrets.Service, error) {
 func initDatabase(cfg *Config, secretsService secrets.Service) (db.Database, error) {
 	logging.Debug("initializing database", "path", cfg.DBURL)
 
-	database, err := db.Init(cfg.DBType, cfg.DBURL, secretsService)
+	database, err := db.InitWithOptions(cfg.DBType, cfg.DBURL, secretsService, db.Options{
+		SQLiteOptions: cfg.SQLiteOptions,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
@@ -54,9 +56,21 @@ func initDatabase(cfg *Config, secretsService secrets.Service) (db.Database, err
 		return nil, fmt.Errorf("failed to apply database migrations: %w", err)
 	}
 
+	database.SetDecryptHook(logDecryption)
+
 	return database, nil
 }
 
+// logDecryption logs the decryption of an encrypted field for auditing credential use
+func logDecryption(event db.DecryptEvent) {
+	logging.WithGroup("db").Debug("encrypted field decrypted",
+		"model", event.Model,
+		"field", event.Field,
+		"recordID", event.RecordID,
+		"userID", event.UserID,
+		"workspaceID", event.WorkspaceID)
+}
+
 // initAuth initializes JWT and session services
 func initAuth(cfg *Config, database db.Database) (auth.JWTManager, auth.SessionManager, auth.CookieManager, error) {
 	logging.Debug("initializing authentication services")
@@ -88,7 +102,9 @@ func initAuth(cfg *Config, database db.Database) (auth.JWTManager, auth.SessionM
 	}
 
 	sessionManager := auth.NewSessionService(database, jwtManager)
-	cookieService := auth.NewCookieService(cfg.IsDevelopment, cfg.Domain)
+	cookieService := auth.NewCookieServiceWithOptions(cfg.IsDevelopment, cfg.Domain, auth.CookieOptions{
+		EnforceSecure: cfg.SecureCookiesOnly,
+	})
 
 	return jwtManager, sessionManager, cookieService, nil
 }
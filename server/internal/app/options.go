@@ -4,6 +4,7 @@ import (
 	"lemma/internal/auth"
 	"lemma/internal/db"
 	"lemma/internal/logging"
+	"lemma/internal/mail"
 	"lemma/internal/storage"
 )
 
@@ -15,6 +16,7 @@ type Options struct {
 	JWTManager     auth.JWTManager
 	SessionManager auth.SessionManager
 	CookieService  auth.CookieManager
+	Mailer         mail.Mailer
 }
 
 // DefaultOptions creates server options with default configuration
@@ -32,7 +34,17 @@ func DefaultOptions(cfg *Config) (*Options, error) {
 	}
 
 	// Initialize storage
-	storageManager := storage.NewService(cfg.WorkDir)
+	storageManager := storage.NewServiceWithOptions(cfg.WorkDir, storage.Options{
+		AllowedGitHosts:       cfg.AllowedGitHosts,
+		BlockPrivateGitHosts:  cfg.BlockPrivateGitHosts,
+		FollowSymlinks:        cfg.FollowSymlinks,
+		MaxTreeNodes:          cfg.MaxTreeNodes,
+		MaxTreeDepth:          cfg.MaxTreeDepth,
+		MaxFileVersions:       cfg.MaxFileVersions,
+		MaxIncludeSize:        cfg.MaxContentSize,
+		PushGracePeriod:       cfg.GitPushGracePeriod,
+		PushMaxPendingCommits: cfg.GitPushMaxPendingCommits,
+	})
 
 	// Initialize logger
 	logging.Setup(cfg.LogLevel)
@@ -55,5 +67,6 @@ func DefaultOptions(cfg *Config) (*Options, error) {
 		JWTManager:     jwtManager,
 		SessionManager: sessionService,
 		CookieService:  cookieService,
+		Mailer:         mail.NewLogMailer(),
 	}, nil
 }
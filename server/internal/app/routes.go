@@ -3,9 +3,10 @@ package app
 import (
 	"lemma/internal/auth"
 	"lemma/internal/context"
+	"lemma/internal/events"
 	"lemma/internal/handlers"
 	"lemma/internal/logging"
-	"time"
+	"lemma/internal/render"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -24,36 +25,75 @@ func setupRouter(o Options) *chi.Mux {
 	r := chi.NewRouter()
 
 	// Basic middleware
-	r.Use(middleware.Logger)
+	r.Use(handlers.SkipLogging(middleware.Logger, o.Config.LogExcludePaths...))
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
-	r.Use(middleware.Timeout(30 * time.Second))
+	// Streams and profiles stay open for as long as the client asks, so they are not cut off by the timeout
+	if o.Config.RequestTimeout > 0 {
+		r.Use(handlers.SkipStreams(middleware.Timeout(o.Config.RequestTimeout)))
+	}
+	r.Use(handlers.MethodNotAllowed)
 
 	// Security headers
 	r.Use(secure.New(secure.Options{
 		SSLRedirect:     false,
 		SSLProxyHeaders: map[string]string{"X-Forwarded-Proto": "https"},
-		IsDevelopment:   o.Config.IsDevelopment,
+		// Only sent on HTTPS requests, browsers ignore the header over plain HTTP
+		STSSeconds:           int64(o.Config.HSTSMaxAge.Seconds()),
+		STSIncludeSubdomains: o.Config.HSTSPreload,
+		STSPreload:           o.Config.HSTSPreload,
+		IsDevelopment:        o.Config.IsDevelopment,
 	}).Handler)
 
 	// CORS if origins are configured
 	if len(o.Config.CORSOrigins) > 0 {
 		r.Use(cors.Handler(cors.Options{
-			AllowedOrigins:   o.Config.CORSOrigins,
-			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowedHeaders:   []string{"Accept", "Content-Type", "X-CSRF-Token"},
-			ExposedHeaders:   []string{"X-CSRF-Token"},
+			AllowedOrigins: o.Config.CORSOrigins,
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Accept", "Content-Type", "X-CSRF-Token", "If-Match"},
+			ExposedHeaders: []string{
+				"X-CSRF-Token",
+				"ETag",
+				handlers.HeaderPaginationLimit,
+				handlers.HeaderPaginationOffset,
+				handlers.HeaderTotalCount,
+			},
 			AllowCredentials: true,
 			MaxAge:           300,
 		}))
 	}
 
 	// Initialize auth middleware and handler
-	authMiddleware := auth.NewMiddleware(o.JWTManager, o.SessionManager, o.CookieService)
+	authMiddleware := auth.NewMiddlewareWithOptions(o.JWTManager, o.SessionManager, o.CookieService, auth.MiddlewareOptions{
+		RefreshWindow: o.Config.SessionRefreshWindow,
+	})
 	handler := &handlers.Handler{
-		DB:      o.Database,
-		Storage: o.Storage,
+		DB:              o.Database,
+		Storage:         o.Storage,
+		DefaultPageSize: o.Config.DefaultPageSize,
+		MaxPageSize:     o.Config.MaxPageSize,
+		DefaultHomeFile: o.Config.DefaultHomeFile,
+		Languages:       o.Config.Languages,
+		Renderer:        render.New(render.Options{AllowedElements: o.Config.RenderAllowedElements}),
+		Location:        o.Config.Location(),
+		Mailer:          o.Mailer,
+		MaxContentSize:  o.Config.MaxContentSize,
+		MaxFileSize:     o.Config.MaxFileSize,
+		MaxUploadSize:   o.Config.MaxUploadSize,
+		MaxUploadMemory: o.Config.MaxUploadMemory,
+		AllowedOrigins:  o.Config.CORSOrigins,
+
+		UniqueDisplayNames:         o.Config.UniqueDisplayNames,
+		WorkspaceNameNormalization: o.Config.WorkspaceNameNormalization,
+		MinWorkspaces:              o.Config.MinWorkspacesPerUser,
+		MaxWorkspaces:              o.Config.MaxWorkspacesPerUser,
+		CommitIdentityFallback:     o.Config.GitCommitIdentityFallback,
+		Events: events.NewHub(events.Options{
+			MaxPerUser:      o.Config.MaxEventStreamsPerUser,
+			MaxPerWorkspace: o.Config.MaxEventStreamsPerWorkspace,
+			Policy:          o.Config.EventStreamLimitPolicy,
+		}),
 	}
 
 	if o.Config.IsDevelopment {
@@ -64,6 +104,20 @@ func setupRouter(o Options) *chi.Mux {
 
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
+		// Unknown API paths get JSON errors instead of the SPA. Set before any subrouter
+		// is mounted, subrouters copy the handler when they are mounted.
+		r.NotFound(handlers.NotFound())
+
+		// Reject all changes in read-only mode, refreshing tokens is needed to stay logged in
+		if o.Config.ReadOnlyMode {
+			r.Use(handlers.ReadOnlyMode(
+				"/api/v1/auth/login",
+				"/api/v1/auth/logout",
+				"/api/v1/auth/logout-all",
+				"/api/v1/auth/refresh",
+			))
+		}
+
 		// Public routes (no authentication required)
 		r.Group(func(r chi.Router) {
 			// Rate limiting for authentication endpoints to prevent brute force attacks
@@ -76,20 +130,32 @@ func setupRouter(o Options) *chi.Mux {
 
 			r.Post("/auth/login", handler.Login(o.SessionManager, o.CookieService))
 			r.Post("/auth/refresh", handler.RefreshToken(o.SessionManager, o.CookieService))
+			r.Post("/auth/forgot-password", handler.ForgotPassword())
+			r.Post("/auth/reset-password", handler.ResetPassword(o.SessionManager))
 		})
 
 		// Protected routes (authentication required)
 		r.Group(func(r chi.Router) {
 			r.Use(authMiddleware.Authenticate)
 			r.Use(context.WithUserContextMiddleware)
+			if len(o.Config.RoleRateLimits) > 0 {
+				r.Use(handlers.RoleRateLimit(o.Config.RoleRateLimits, o.Config.RateLimitWindow))
+			}
 
 			// Auth routes
 			r.Post("/auth/logout", handler.Logout(o.SessionManager, o.CookieService))
+			r.Post("/auth/logout-all", handler.LogoutAll(o.SessionManager, o.CookieService))
 			r.Get("/auth/me", handler.GetCurrentUser())
+			r.Get("/auth/sessions", handler.ListSessions())
+			r.Delete("/auth/sessions/{sessionId}", handler.RevokeSession(o.CookieService))
+			r.Get("/bootstrap", handler.GetBootstrap())
 
 			// User profile routes
-			r.Put("/profile", handler.UpdateProfile())
+			r.Put("/profile", handler.UpdateProfile(o.SessionManager, o.CookieService))
 			r.Delete("/profile", handler.DeleteAccount())
+			r.Get("/profile/export", handler.ExportUserData())
+			r.Get("/profile/preferences", handler.GetPreferences())
+			r.Put("/profile/preferences", handler.UpdatePreferences())
 
 			// Admin-only routes
 			r.Route("/admin", func(r chi.Router) {
@@ -101,13 +167,24 @@ func setupRouter(o Options) *chi.Mux {
 					r.Get("/{userId}", handler.AdminGetUser())
 					r.Put("/{userId}", handler.AdminUpdateUser())
 					r.Delete("/{userId}", handler.AdminDeleteUser())
+					r.Get("/{userId}/export", handler.AdminExportUserData())
 				})
 				// Workspace management
 				r.Route("/workspaces", func(r chi.Router) {
 					r.Get("/", handler.AdminListWorkspaces())
+					r.Patch("/settings", handler.AdminBatchUpdateWorkspaceSettings())
+					r.Post("/{workspaceId}/reindex", handler.AdminReindexWorkspace())
+					r.Post("/{workspaceId}/transfer", handler.AdminTransferWorkspace())
+					r.Get("/{workspaceId}/access-log", handler.AdminGetAccessLog())
 				})
-				// System stats
+				r.Post("/reindex", handler.AdminReindexAll())
+				// System stats and configuration
 				r.Get("/stats", handler.AdminGetSystemStats())
+				r.Get("/config", handler.AdminGetConfig(o.Config.Redact()))
+				// Runtime profiles for debugging
+				if o.Config.EnableProfiling {
+					r.Mount("/debug/pprof", handlers.Profiling())
+				}
 			})
 
 			// Workspace routes
@@ -125,26 +202,72 @@ func setupRouter(o Options) *chi.Mux {
 					r.Get("/", handler.GetWorkspace())
 					r.Put("/", handler.UpdateWorkspace())
 					r.Delete("/", handler.DeleteWorkspace())
+					r.Post("/rename", handler.RenameWorkspace())
+					r.Get("/activity", handler.GetWorkspaceActivity())
+					r.Get("/events", handler.StreamEvents())
+					r.Get("/ws", handler.WorkspaceWebSocket())
+					r.Get("/export", handler.ExportWorkspace())
+					r.Get("/notes", handler.ListNotesByTag())
 
 					// File routes
 					r.Route("/files", func(r chi.Router) {
 						r.Get("/", handler.ListFiles())
 						r.Get("/last", handler.GetLastOpenedFile())
 						r.Put("/last", handler.UpdateLastOpenedFile())
+						r.Get("/home", handler.GetHomeFile())
+						r.Put("/home", handler.UpdateHomeFile())
 						r.Get("/lookup", handler.LookupFileByName())
+						r.Get("/by-extension", handler.ListFilesByExtension())
+						r.Get("/search", handler.SearchFiles())
+						r.Get("/wordcount", handler.GetWordCount())
+						r.Get("/tail", handler.GetFileTail())
+						r.Get("/hash", handler.GetFileHash())
+						r.Get("/render", handler.RenderFile())
+						r.Get("/changed", handler.ListChangedFiles())
+						r.Get("/recent", handler.ListRecentFiles())
+						r.Get("/versions", handler.ListFileVersions())
+						r.Get("/versions/content", handler.GetFileVersionContent())
+						r.Post("/versions/restore", handler.RestoreFileVersion())
+						r.Get("/frontmatter", handler.GetFrontmatter())
+						r.Put("/frontmatter", handler.UpdateFrontmatter())
+						r.Get("/meta", handler.GetFileMeta())
+						r.Get("/backlinks", handler.GetBacklinks())
 
 						r.Post("/upload", handler.UploadFile())
+						r.Post("/batch-save", handler.BatchSaveFiles())
 						r.Post("/move", handler.MoveFile())
+						r.Post("/copy", handler.CopyFile())
+						r.Post("/transfer", handler.TransferFile())
 
 						r.Post("/", handler.SaveFile())
 						r.Get("/content", handler.GetFileContent())
 						r.Delete("/", handler.DeleteFile())
 					})
 
+					// Directory routes
+					r.Route("/dirs", func(r chi.Router) {
+						r.Post("/", handler.CreateDirectory())
+						r.Delete("/", handler.DeleteDirectory())
+					})
+
+					// Trash routes
+					r.Route("/trash", func(r chi.Router) {
+						r.Get("/", handler.ListTrash())
+						r.Post("/restore", handler.RestoreTrashedFile())
+						r.Post("/purge", handler.PurgeTrash())
+						r.Delete("/", handler.DeleteTrashedFile())
+					})
+
 					// Git routes
 					r.Route("/git", func(r chi.Router) {
 						r.Post("/commit", handler.StageCommitAndPush())
 						r.Post("/pull", handler.PullChanges())
+						r.Get("/log", handler.GetCommitHistory())
+						r.Get("/status", handler.GetGitStatus())
+						r.Get("/diff", handler.GetDiff())
+						r.Get("/deleted", handler.ListDeletedFiles())
+						r.Post("/restore", handler.RestoreDeletedFile())
+						r.Get("/bundle", handler.GetBundle())
 					})
 				})
 			})
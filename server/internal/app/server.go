@@ -1,16 +1,39 @@
 package app
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"lemma/internal/db"
 	"lemma/internal/logging"
+	"lemma/internal/models"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
 
+// activityPruneInterval is how often activity entries past the retention are removed
+const activityPruneInterval = time.Hour
+
+// trashPurgeInterval is how often deleted files past the retention are removed from the trash
+const trashPurgeInterval = time.Hour
+
+// shutdownTimeout is how long requests in flight may take to finish once the server is stopped,
+// open event streams and the remaining requests are cut off after it
+const shutdownTimeout = 10 * time.Second
+
+// readHeaderTimeout is how long a client may take to send the request headers
+const readHeaderTimeout = 10 * time.Second
+
 // Server represents the HTTP server and its dependencies
 type Server struct {
 	router  *chi.Mux
 	options *Options
+	stop    chan struct{}
 }
 
 // NewServer creates a new server instance with the given options
@@ -18,20 +41,67 @@ func NewServer(options *Options) *Server {
 	return &Server{
 		router:  setupRouter(*options),
 		options: options,
+		stop:    make(chan struct{}),
 	}
 }
 
-// Start configures and starts the HTTP server
+// Start configures and starts the HTTP server. It returns once the process receives
+// an interrupt or termination signal and the requests in flight have finished,
+// Close has to be called afterwards to flush pending work and release the dependencies.
 func (s *Server) Start() error {
+	if interval := s.options.Config.StatsRefreshInterval; interval > 0 {
+		s.options.Storage.StartCacheRefresh(interval)
+	}
+
+	if s.options.Config.ActivityRetention > 0 || s.options.Config.ActivityMaxEntries > 0 {
+		go s.pruneActivity()
+	}
+
+	if retention := s.options.Config.TrashRetention; retention > 0 {
+		go s.purgeTrash(retention)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Start server
 	addr := ":" + s.options.Config.Port
-	logging.Info("starting server", "address", addr)
-	return http.ListenAndServe(addr, s.router)
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.router,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		logging.Info("starting server", "address", addr)
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	logging.Info("stopping server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logging.Warn("requests did not finish before shutdown", "error", err.Error())
+		httpServer.Close()
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
 // Close handles graceful shutdown of server dependencies
 func (s *Server) Close() error {
 	logging.Info("shutting down server")
+	close(s.stop)
+	s.options.Storage.StopCacheRefresh()
+	s.options.Storage.FlushPendingPushes()
 	return s.options.Database.Close()
 }
 
@@ -39,3 +109,67 @@ func (s *Server) Close() error {
 func (s *Server) Router() chi.Router {
 	return s.router
 }
+
+// pruneActivity removes activity entries past the configured retention until the server is closed.
+// If an archive path is configured, the entries are appended to it before they are removed.
+func (s *Server) pruneActivity() {
+	ticker := time.NewTicker(activityPruneInterval)
+	defer ticker.Stop()
+
+	config := s.options.Config
+	for {
+		opts := db.ActivityPruneOptions{MaxEntries: config.ActivityMaxEntries}
+		if config.ActivityRetention > 0 {
+			opts.Before = time.Now().Add(-config.ActivityRetention)
+		}
+		if config.ActivityArchivePath != "" {
+			opts.Archive = func(entries []*models.Activity) error {
+				return archiveActivity(config.ActivityArchivePath, entries)
+			}
+		}
+		if _, err := s.options.Database.PruneActivity(opts); err != nil {
+			logging.Warn("failed to prune workspace activity", "error", err.Error())
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// archiveActivity appends entries to the file at path as JSON lines
+func archiveActivity(path string, entries []*models.Activity) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+// purgeTrash removes deleted files older than retention from the trash until the server is closed
+func (s *Server) purgeTrash(retention time.Duration) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.options.Storage.PurgeTrash(time.Now().Add(-retention)); err != nil {
+			logging.Warn("failed to purge trash", "error", err.Error())
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.stop:
+			return
+		}
+	}
+}
@@ -2,8 +2,10 @@
 package auth
 
 import (
+	"errors"
 	"lemma/internal/logging"
 	"net/http"
+	"strings"
 )
 
 var logger logging.Logger
@@ -19,23 +21,39 @@ func getCookieLogger() logging.Logger {
 	return getAuthLogger().WithGroup("cookie")
 }
 
+// ErrInsecureTransport is returned when auth cookies would be issued on a request not made over HTTPS
+var ErrInsecureTransport = errors.New("auth cookies are only issued over HTTPS")
+
 // CookieManager interface defines methods for generating cookies
 type CookieManager interface {
 	GenerateAccessTokenCookie(token string) *http.Cookie
 	GenerateRefreshTokenCookie(token string) *http.Cookie
 	GenerateCSRFCookie(token string) *http.Cookie
 	InvalidateCookie(cookieType string) *http.Cookie
+	CheckTransport(r *http.Request) error
+}
+
+// CookieOptions holds the optional settings of the cookie service
+type CookieOptions struct {
+	// EnforceSecure refuses to issue auth cookies on requests not made over HTTPS, ignored in development
+	EnforceSecure bool
 }
 
 // CookieService
 type cookieManager struct {
-	Domain   string
-	Secure   bool
-	SameSite http.SameSite
+	Domain        string
+	Secure        bool
+	SameSite      http.SameSite
+	EnforceSecure bool
 }
 
 // NewCookieService creates a new cookie service
 func NewCookieService(isDevelopment bool, domain string) CookieManager {
+	return NewCookieServiceWithOptions(isDevelopment, domain, CookieOptions{})
+}
+
+// NewCookieServiceWithOptions creates a new cookie service with the given options
+func NewCookieServiceWithOptions(isDevelopment bool, domain string, options CookieOptions) CookieManager {
 	log := getCookieLogger()
 
 	secure := !isDevelopment
@@ -47,18 +65,38 @@ func NewCookieService(isDevelopment bool, domain string) CookieManager {
 		sameSite = http.SameSiteStrictMode
 	}
 
+	enforceSecure := options.EnforceSecure && !isDevelopment
+
 	log.Debug("creating cookie service",
 		"secure", secure,
+		"enforceSecure", enforceSecure,
 		"sameSite", sameSite,
 		"domain", domain)
 
 	return &cookieManager{
-		Domain:   domain,
-		Secure:   secure,
-		SameSite: sameSite,
+		Domain:        domain,
+		Secure:        secure,
+		SameSite:      sameSite,
+		EnforceSecure: enforceSecure,
 	}
 }
 
+// CheckTransport returns ErrInsecureTransport if secure transport is enforced and r was not made over HTTPS,
+// either directly or through a proxy setting X-Forwarded-Proto. Auth cookies must not be issued then.
+func (c *cookieManager) CheckTransport(r *http.Request) error {
+	if !c.EnforceSecure || isSecureRequest(r) {
+		return nil
+	}
+	getCookieLogger().Warn("refusing to issue auth cookies over plain HTTP",
+		"clientIP", r.RemoteAddr)
+	return ErrInsecureTransport
+}
+
+// isSecureRequest reports whether r was made over HTTPS
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
 // GenerateAccessTokenCookie creates a new cookie for the access token
 func (c *cookieManager) GenerateAccessTokenCookie(token string) *http.Cookie {
 	log := getCookieLogger()
@@ -3,6 +3,7 @@ package auth
 
 import (
 	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"lemma/internal/logging"
 	"time"
@@ -25,9 +26,10 @@ const (
 // Claims represents the custom claims we store in JWT tokens
 type Claims struct {
 	jwt.RegisteredClaims           // Embedded standard JWT claims
-	UserID               int       `json:"uid"`  // User identifier
-	Role                 string    `json:"role"` // User role (admin, editor, viewer)
-	Type                 TokenType `json:"type"` // Token type (access or refresh)
+	UserID               int       `json:"uid"`   // User identifier
+	Role                 string    `json:"role"`  // User role (admin, editor, viewer)
+	Type                 TokenType `json:"type"`  // Token type (access or refresh)
+	Nonce                string    `json:"nonce"` // Random value that keeps tokens issued in the same second distinct
 }
 
 // JWTConfig holds the configuration for the JWT service
@@ -97,6 +99,7 @@ func (s *jwtService) generateToken(userID int, role string, sessionID string, to
 		UserID: userID,
 		Role:   role,
 		Type:   tokenType,
+		Nonce:  hex.EncodeToString(nonce),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
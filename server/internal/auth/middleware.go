@@ -5,6 +5,7 @@ import (
 	"lemma/internal/context"
 	"lemma/internal/logging"
 	"net/http"
+	"time"
 )
 
 func getMiddlewareLogger() logging.Logger {
@@ -16,14 +17,28 @@ type Middleware struct {
 	jwtManager     JWTManager
 	sessionManager SessionManager
 	cookieManager  CookieManager
+	refreshWindow  time.Duration
+}
+
+// MiddlewareOptions configures optional behaviour of the authentication middleware
+type MiddlewareOptions struct {
+	// RefreshWindow reissues the access token cookie of a valid session when the token
+	// expires within this window, 0 disables the refresh
+	RefreshWindow time.Duration
 }
 
 // NewMiddleware creates a new authentication middleware
 func NewMiddleware(jwtManager JWTManager, sessionManager SessionManager, cookieManager CookieManager) *Middleware {
+	return NewMiddlewareWithOptions(jwtManager, sessionManager, cookieManager, MiddlewareOptions{})
+}
+
+// NewMiddlewareWithOptions creates a new authentication middleware with the given options
+func NewMiddlewareWithOptions(jwtManager JWTManager, sessionManager SessionManager, cookieManager CookieManager, options MiddlewareOptions) *Middleware {
 	return &Middleware{
 		jwtManager:     jwtManager,
 		sessionManager: sessionManager,
 		cookieManager:  cookieManager,
+		refreshWindow:  options.RefreshWindow,
 	}
 }
 
@@ -92,10 +107,16 @@ func (m *Middleware) Authenticate(next http.Handler) http.Handler {
 			}
 		}
 
+		// Extend active sessions by reissuing access tokens that are about to expire
+		if m.refreshWindow > 0 && claims.ExpiresAt != nil && time.Until(claims.ExpiresAt.Time) < m.refreshWindow {
+			m.refreshAccessToken(w, r, claims)
+		}
+
 		// Create handler context with user information
 		hctx := &context.HandlerContext{
-			UserID:   claims.UserID,
-			UserRole: claims.Role,
+			UserID:    claims.UserID,
+			UserRole:  claims.Role,
+			SessionID: claims.ID,
 		}
 
 		// Add context to request and continue
@@ -103,6 +124,33 @@ func (m *Middleware) Authenticate(next http.Handler) http.Handler {
 	})
 }
 
+// refreshAccessToken sets a new access token cookie for the session of claims.
+// The CSRF cookie is reissued with its current value so it does not expire before the access token.
+// Failures are logged and the request continues with the current token.
+func (m *Middleware) refreshAccessToken(w http.ResponseWriter, r *http.Request, claims *Claims) {
+	log := getMiddlewareLogger().With(
+		"handler", "Authenticate",
+		"userID", claims.UserID,
+		"clientIP", r.RemoteAddr,
+	)
+
+	if err := m.cookieManager.CheckTransport(r); err != nil {
+		return
+	}
+
+	token, err := m.jwtManager.GenerateAccessToken(claims.UserID, claims.Role, claims.ID)
+	if err != nil {
+		log.Error("failed to refresh access token", "error", err.Error())
+		return
+	}
+
+	http.SetCookie(w, m.cookieManager.GenerateAccessTokenCookie(token))
+	if csrfCookie, err := r.Cookie("csrf_token"); err == nil {
+		http.SetCookie(w, m.cookieManager.GenerateCSRFCookie(csrfCookie.Value))
+	}
+	log.Debug("refreshed access token", "sessionID", claims.ID)
+}
+
 // RequireRole returns a middleware that ensures the user has the required role
 func (m *Middleware) RequireRole(role string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
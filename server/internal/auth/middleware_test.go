@@ -29,8 +29,8 @@ func (m *mockSessionManager) CreateSession(_ int, _ string) (*models.Session, st
 	return nil, "", nil // Not needed for these tests
 }
 
-func (m *mockSessionManager) RefreshSession(_ string) (string, error) {
-	return "", nil // Not needed for these tests
+func (m *mockSessionManager) RefreshSession(_ string) (string, string, error) {
+	return "", "", nil // Not needed for these tests
 }
 
 func (m *mockSessionManager) ValidateSession(sessionID string) (*models.Session, error) {
@@ -46,6 +46,15 @@ func (m *mockSessionManager) InvalidateSession(token string) error {
 	return nil
 }
 
+func (m *mockSessionManager) InvalidateAllUserSessions(userID int) error {
+	for id, session := range m.sessions {
+		if session.UserID == userID {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
 func (m *mockSessionManager) CleanExpiredSessions() error {
 	return nil
 }
@@ -229,6 +238,98 @@ func TestAuthenticateMiddleware(t *testing.T) {
 	}
 }
 
+func TestAuthenticateRefresh(t *testing.T) {
+	sessionManager := newMockSessionManager()
+	cookieManager := auth.NewCookieService(true, "localhost")
+
+	testCases := []struct {
+		name          string
+		tokenExpiry   time.Duration
+		refreshWindow time.Duration
+		wantRefresh   bool
+	}{
+		{
+			name:          "token near expiry is refreshed",
+			tokenExpiry:   time.Minute,
+			refreshWindow: 5 * time.Minute,
+			wantRefresh:   true,
+		},
+		{
+			name:          "fresh token is not refreshed",
+			tokenExpiry:   15 * time.Minute,
+			refreshWindow: 5 * time.Minute,
+			wantRefresh:   false,
+		},
+		{
+			name:          "refresh disabled",
+			tokenExpiry:   time.Minute,
+			refreshWindow: 0,
+			wantRefresh:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jwtService, _ := auth.NewJWTService(auth.JWTConfig{
+				SigningKey:         "test-key",
+				AccessTokenExpiry:  tc.tokenExpiry,
+				RefreshTokenExpiry: 24 * time.Hour,
+			})
+			middleware := auth.NewMiddlewareWithOptions(jwtService, sessionManager, cookieManager, auth.MiddlewareOptions{
+				RefreshWindow: tc.refreshWindow,
+			})
+
+			sessionID := tc.name
+			sessionManager.sessions[sessionID] = &models.Session{
+				ID:        sessionID,
+				UserID:    1,
+				ExpiresAt: time.Now().Add(24 * time.Hour),
+			}
+
+			token, _ := jwtService.GenerateAccessToken(1, "editor", sessionID)
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.AddCookie(cookieManager.GenerateAccessTokenCookie(token))
+			req.AddCookie(cookieManager.GenerateCSRFCookie("test-csrf-token"))
+			w := newMockResponseWriter()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			middleware.Authenticate(next).ServeHTTP(w, req)
+
+			if w.statusCode != http.StatusOK {
+				t.Fatalf("status code = %v, want %v", w.statusCode, http.StatusOK)
+			}
+
+			cookies := map[string]*http.Cookie{}
+			for _, cookie := range (&http.Response{Header: w.Header()}).Cookies() {
+				cookies[cookie.Name] = cookie
+			}
+
+			accessCookie, refreshed := cookies["access_token"]
+			if refreshed != tc.wantRefresh {
+				t.Fatalf("access token refreshed = %v, want %v", refreshed, tc.wantRefresh)
+			}
+			if !refreshed {
+				return
+			}
+
+			claims, err := jwtService.ValidateToken(accessCookie.Value)
+			if err != nil {
+				t.Fatalf("refreshed token is invalid: %v", err)
+			}
+			if claims.ID != sessionID || claims.UserID != 1 || claims.Role != "editor" {
+				t.Errorf("refreshed claims = %+v, want session %s for user 1 with role editor", claims, sessionID)
+			}
+
+			csrfCookie, ok := cookies["csrf_token"]
+			if !ok || csrfCookie.Value != "test-csrf-token" {
+				t.Errorf("CSRF cookie was not reissued with the current token")
+			}
+		})
+	}
+}
+
 func TestRequireRole(t *testing.T) {
 	config := auth.JWTConfig{
 		SigningKey:         "test-key",
@@ -17,9 +17,10 @@ func getSessionLogger() logging.Logger {
 // SessionManager is an interface for managing user sessions
 type SessionManager interface {
 	CreateSession(userID int, role string) (*models.Session, string, error)
-	RefreshSession(refreshToken string) (string, error)
+	RefreshSession(refreshToken string) (string, string, error)
 	ValidateSession(sessionID string) (*models.Session, error)
 	InvalidateSession(token string) error
+	InvalidateAllUserSessions(userID int) error
 	CleanExpiredSessions() error
 }
 
@@ -85,31 +86,53 @@ func (s *sessionManager) CreateSession(userID int, role string) (*models.Session
 	return session, accessToken, nil
 }
 
-// RefreshSession creates a new access token using a refreshToken
-func (s *sessionManager) RefreshSession(refreshToken string) (string, error) {
+// RefreshSession exchanges a refresh token for a new access token and a new refresh token.
+// The used refresh token is replaced in the session, so it is rejected from now on.
+func (s *sessionManager) RefreshSession(refreshToken string) (string, string, error) {
+	log := getSessionLogger()
+
 	// Get session from database
 	session, err := s.db.GetSessionByRefreshToken(refreshToken)
 	if err != nil {
-		return "", fmt.Errorf("invalid session: %w", err)
+		return "", "", fmt.Errorf("invalid session: %w", err)
 	}
 
 	// Validate the refresh token
 	claims, err := s.jwtManager.ValidateToken(refreshToken)
 	if err != nil {
-		return "", fmt.Errorf("invalid refresh token: %w", err)
+		return "", "", fmt.Errorf("invalid refresh token: %w", err)
 	}
 
 	if claims.UserID != session.UserID {
-		return "", fmt.Errorf("token does not match session")
+		return "", "", fmt.Errorf("token does not match session")
+	}
+
+	newRefreshToken, err := s.jwtManager.GenerateRefreshToken(claims.UserID, claims.Role, session.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	newClaims, err := s.jwtManager.ValidateToken(newRefreshToken)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to validate refresh token: %w", err)
+	}
+
+	if err := s.db.RotateRefreshToken(session.ID, refreshToken, newRefreshToken, newClaims.ExpiresAt.Time); err != nil {
+		return "", "", fmt.Errorf("failed to rotate refresh token: %w", err)
 	}
 
 	// Generate a new access token
-	newToken, err := s.jwtManager.GenerateAccessToken(claims.UserID, claims.Role, session.ID)
+	accessToken, err := s.jwtManager.GenerateAccessToken(claims.UserID, claims.Role, session.ID)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	return newToken, nil
+	log.Debug("rotated refresh token",
+		"sessionId", session.ID,
+		"userId", session.UserID,
+		"expiresAt", newClaims.ExpiresAt.Time)
+
+	return accessToken, newRefreshToken, nil
 }
 
 // ValidateSession checks if a session with the given sessionID is valid
@@ -151,6 +174,21 @@ func (s *sessionManager) InvalidateSession(token string) error {
 	return nil
 }
 
+// InvalidateAllUserSessions removes all sessions of the user with the given userID,
+// so the tokens issued for them are rejected from now on
+func (s *sessionManager) InvalidateAllUserSessions(userID int) error {
+	log := getSessionLogger()
+
+	if err := s.db.DeleteSessionsByUserID(userID); err != nil {
+		return err
+	}
+
+	log.Debug("invalidated all sessions of user",
+		"userId", userID)
+
+	return nil
+}
+
 // CleanExpiredSessions removes all expired sessions from the database
 func (s *sessionManager) CleanExpiredSessions() error {
 	log := getSessionLogger()
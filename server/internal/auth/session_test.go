@@ -52,6 +52,28 @@ func (m *mockSessionStore) GetSessionByRefreshToken(refreshToken string) (*model
 	return session, nil
 }
 
+func (m *mockSessionStore) GetSessionsByUserID(userID int) ([]*models.Session, error) {
+	var sessions []*models.Session
+	for _, session := range m.sessions {
+		if session.UserID == userID && session.ExpiresAt.After(time.Now()) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *mockSessionStore) RotateRefreshToken(sessionID, oldRefreshToken, newRefreshToken string, expiresAt time.Time) error {
+	session, exists := m.sessions[sessionID]
+	if !exists || session.RefreshToken != oldRefreshToken {
+		return errors.New("session not found or expired")
+	}
+	delete(m.sessionsByToken, oldRefreshToken)
+	session.RefreshToken = newRefreshToken
+	session.ExpiresAt = expiresAt
+	m.sessionsByToken[newRefreshToken] = session
+	return nil
+}
+
 func (m *mockSessionStore) DeleteSession(sessionID string) error {
 	session, exists := m.sessions[sessionID]
 	if !exists {
@@ -62,6 +84,16 @@ func (m *mockSessionStore) DeleteSession(sessionID string) error {
 	return nil
 }
 
+func (m *mockSessionStore) DeleteSessionsByUserID(userID int) error {
+	for id, session := range m.sessions {
+		if session.UserID == userID {
+			delete(m.sessionsByToken, session.RefreshToken)
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
 func (m *mockSessionStore) CleanExpiredSessions() error {
 	for id, session := range m.sessions {
 		if session.ExpiresAt.Before(time.Now()) {
@@ -307,7 +339,7 @@ func TestRefreshSession(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			refreshToken := tc.setupSession()
-			newAccessToken, err := sessionService.RefreshSession(refreshToken)
+			newAccessToken, newRefreshToken, err := sessionService.RefreshSession(refreshToken)
 
 			if tc.wantErr {
 				if err == nil {
@@ -332,6 +364,34 @@ func TestRefreshSession(t *testing.T) {
 			if claims.Type != auth.AccessToken {
 				t.Errorf("token type = %v, want access token", claims.Type)
 			}
+
+			// Verify the refresh token was rotated
+			if newRefreshToken == refreshToken {
+				t.Error("refresh token was not rotated")
+			}
+			claims, err = jwtService.ValidateToken(newRefreshToken)
+			if err != nil {
+				t.Errorf("failed to validate new refresh token: %v", err)
+				return
+			}
+			if claims.Type != auth.RefreshToken {
+				t.Errorf("token type = %v, want refresh token", claims.Type)
+			}
+
+			if _, err := mockDB.GetSessionByRefreshToken(refreshToken); err == nil {
+				t.Error("old refresh token still resolves to a session")
+			}
+			if _, err := mockDB.GetSessionByRefreshToken(newRefreshToken); err != nil {
+				t.Errorf("new refresh token does not resolve to a session: %v", err)
+			}
+
+			// The old refresh token cannot be exchanged again
+			if _, _, err := sessionService.RefreshSession(refreshToken); err == nil {
+				t.Error("expected error refreshing with the old refresh token, got nil")
+			}
+			if _, _, err := sessionService.RefreshSession(newRefreshToken); err != nil {
+				t.Errorf("failed to refresh with the new refresh token: %v", err)
+			}
 		})
 	}
 }
@@ -383,3 +443,44 @@ func TestCleanExpiredSessions(t *testing.T) {
 		t.Error("expired session was not removed")
 	}
 }
+
+func TestInvalidateAllUserSessions(t *testing.T) {
+	config := auth.JWTConfig{
+		SigningKey:         "test-key",
+		AccessTokenExpiry:  15 * time.Minute,
+		RefreshTokenExpiry: 24 * time.Hour,
+	}
+	jwtService, _ := auth.NewJWTService(config)
+	mockDB := newMockSessionStore()
+	sessionService := auth.NewSessionService(mockDB, jwtService)
+
+	first, _, err := sessionService.CreateSession(1, "editor")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	second, _, err := sessionService.CreateSession(1, "editor")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	other, _, err := sessionService.CreateSession(2, "editor")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	if err := sessionService.InvalidateAllUserSessions(1); err != nil {
+		t.Fatalf("unexpected error invalidating sessions: %v", err)
+	}
+
+	for _, session := range []*models.Session{first, second} {
+		if _, err := sessionService.ValidateSession(session.ID); err == nil {
+			t.Errorf("session %s was not invalidated", session.ID)
+		}
+		if _, _, err := sessionService.RefreshSession(session.RefreshToken); err == nil {
+			t.Errorf("refresh token of session %s still works", session.ID)
+		}
+	}
+
+	if _, err := sessionService.ValidateSession(other.ID); err != nil {
+		t.Errorf("session of another user was invalidated: %v", err)
+	}
+}
@@ -18,14 +18,16 @@ const (
 
 // UserClaims represents user information from authentication
 type UserClaims struct {
-	UserID int
-	Role   string
+	UserID    int
+	Role      string
+	SessionID string
 }
 
 // HandlerContext holds the request-specific data available to all handlers
 type HandlerContext struct {
 	UserID    int
 	UserRole  string
+	SessionID string            // ID of the session the request was authenticated with
 	Workspace *models.Workspace // Optional, only set for workspace routes
 }
 
@@ -69,7 +71,8 @@ func GetUserFromContext(ctx context.Context) (*UserClaims, error) {
 	}
 
 	return &UserClaims{
-		UserID: hctx.UserID,
-		Role:   hctx.UserRole,
+		UserID:    hctx.UserID,
+		Role:      hctx.UserRole,
+		SessionID: hctx.SessionID,
 	}, nil
 }
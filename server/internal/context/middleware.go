@@ -23,8 +23,9 @@ func WithUserContextMiddleware(next http.Handler) http.Handler {
 		}
 
 		hctx := &HandlerContext{
-			UserID:   claims.UserID,
-			UserRole: claims.Role,
+			UserID:    claims.UserID,
+			UserRole:  claims.Role,
+			SessionID: claims.SessionID,
 		}
 
 		r = WithHandlerContext(r, hctx)
@@ -55,7 +56,7 @@ func WithWorkspaceContextMiddleware(db db.WorkspaceReader) func(http.Handler) ht
 				return
 			}
 
-			workspace, err := db.GetWorkspaceByName(ctx.UserID, decodedWorkspaceName)
+			workspace, err := db.GetWorkspaceByNameContext(r.Context(), ctx.UserID, decodedWorkspaceName)
 			if err != nil {
 				log.Error("failed to get workspace",
 					"error", err,
@@ -21,6 +21,14 @@ func (m *MockDB) GetWorkspaceByName(userID int, workspaceName string) (*models.W
 	return m.GetWorkspaceByNameFunc(userID, workspaceName)
 }
 
+func (m *MockDB) GetWorkspaceByNameContext(_ stdctx.Context, userID int, workspaceName string) (*models.Workspace, error) {
+	return m.GetWorkspaceByNameFunc(userID, workspaceName)
+}
+
+func (m *MockDB) GetWorkspaceByNormalizedName(_ int, _ string, _ bool) (*models.Workspace, error) {
+	return nil, nil
+}
+
 func (m *MockDB) GetWorkspaceByID(_ int) (*models.Workspace, error) {
 	return nil, nil
 }
@@ -33,6 +41,10 @@ func (m *MockDB) GetAllWorkspaces() ([]*models.Workspace, error) {
 	return nil, nil
 }
 
+func (m *MockDB) GetAllWorkspacesContext(_ stdctx.Context) ([]*models.Workspace, error) {
+	return nil, nil
+}
+
 func TestWithUserContextMiddleware(t *testing.T) {
 	tests := []struct {
 		name       string
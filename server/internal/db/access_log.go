@@ -0,0 +1,57 @@
+package db
+
+import (
+	"fmt"
+
+	"lemma/internal/models"
+)
+
+// CreateFileAccess inserts a new entry into the access log of a workspace
+func (db *database) CreateFileAccess(access *models.FileAccess) error {
+	query, err := db.NewQuery().
+		InsertStruct(access, "file_access_log")
+	if err != nil {
+		return fmt.Errorf("failed to create query: %w", err)
+	}
+
+	query.Returning("id", "created_at")
+
+	err = db.QueryRow(query.String(), query.Args()...).
+		Scan(&access.ID, &access.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert file access: %w", err)
+	}
+
+	return nil
+}
+
+// GetFileAccessLog retrieves the access log of a workspace, newest first.
+// At most limit entries are returned. If beforeID is positive, only entries
+// older than the entry with that ID are returned.
+func (db *database) GetFileAccessLog(workspaceID, limit, beforeID int) ([]*models.FileAccess, error) {
+	query := db.NewQuery()
+	query, err := query.SelectStruct(&models.FileAccess{}, "file_access_log")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query: %w", err)
+	}
+
+	query = query.Where("workspace_id = ").Placeholder(workspaceID)
+	if beforeID > 0 {
+		query = query.And("id < ").Placeholder(beforeID)
+	}
+	query = query.OrderBy("id DESC").Limit(limit)
+
+	rows, err := db.Query(query.String(), query.Args()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query file access log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.FileAccess{}
+	err = db.ScanStructs(rows, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan file access log: %w", err)
+	}
+
+	return entries, nil
+}
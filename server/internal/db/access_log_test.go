@@ -0,0 +1,95 @@
+package db_test
+
+import (
+	"testing"
+
+	"lemma/internal/db"
+	"lemma/internal/models"
+	_ "lemma/internal/testenv"
+)
+
+func TestFileAccessLogOperations(t *testing.T) {
+	database, err := db.NewTestSQLiteDB(&mockSecrets{})
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer database.Close()
+
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	user, err := database.CreateUser(&models.User{
+		Email:        "access@example.com",
+		DisplayName:  "Access User",
+		PasswordHash: "hash",
+		Role:         models.RoleEditor,
+		Theme:        "dark",
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	workspaceID := user.LastWorkspaceID
+
+	record := func(accessType models.FileAccessType, path string) *models.FileAccess {
+		t.Helper()
+		access := &models.FileAccess{
+			WorkspaceID: workspaceID,
+			UserID:      user.ID,
+			Type:        accessType,
+			Path:        path,
+			IPAddress:   "192.0.2.1",
+		}
+		if err := database.CreateFileAccess(access); err != nil {
+			t.Fatalf("failed to create file access: %v", err)
+		}
+		return access
+	}
+
+	t.Run("CreateFileAccess", func(t *testing.T) {
+		access := record(models.FileAccessRead, "notes/a.md")
+		if access.ID == 0 {
+			t.Error("expected non-zero ID")
+		}
+		if access.CreatedAt.IsZero() {
+			t.Error("expected CreatedAt to be set")
+		}
+	})
+
+	t.Run("GetFileAccessLog", func(t *testing.T) {
+		record(models.FileAccessRead, "notes/b.md")
+		last := record(models.FileAccessExport, "notes")
+
+		entries, err := database.GetFileAccessLog(workspaceID, 2, 0)
+		if err != nil {
+			t.Fatalf("failed to get access log: %v", err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("got %d entries, want 2", len(entries))
+		}
+		if entries[0].ID != last.ID || entries[0].Type != models.FileAccessExport {
+			t.Errorf("first entry = %+v, want newest entry %+v", entries[0], last)
+		}
+		if entries[0].UserID != user.ID || entries[0].Path != "notes" || entries[0].IPAddress != "192.0.2.1" {
+			t.Errorf("first entry = %+v, want user %d, path notes and IP 192.0.2.1", entries[0], user.ID)
+		}
+
+		// Next page continues after the cursor
+		next, err := database.GetFileAccessLog(workspaceID, 2, entries[1].ID)
+		if err != nil {
+			t.Fatalf("failed to get access log: %v", err)
+		}
+		if len(next) != 1 || next[0].Path != "notes/a.md" {
+			t.Errorf("next page = %+v, want only notes/a.md", next)
+		}
+
+		// Other workspaces have no entries
+		other, err := database.GetFileAccessLog(workspaceID+1000, 10, 0)
+		if err != nil {
+			t.Fatalf("failed to get access log: %v", err)
+		}
+		if len(other) != 0 {
+			t.Errorf("got %d entries for other workspace, want 0", len(other))
+		}
+	})
+}
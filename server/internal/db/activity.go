@@ -0,0 +1,177 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"lemma/internal/models"
+)
+
+// CreateActivity inserts a new workspace activity record into the database
+func (db *database) CreateActivity(activity *models.Activity) error {
+	query, err := db.NewQuery().
+		InsertStruct(activity, "workspace_activity")
+	if err != nil {
+		return fmt.Errorf("failed to create query: %w", err)
+	}
+
+	query.Returning("id", "created_at")
+
+	err = db.QueryRow(query.String(), query.Args()...).
+		Scan(&activity.ID, &activity.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert activity: %w", err)
+	}
+
+	return nil
+}
+
+// GetWorkspaceActivity retrieves the activity of a workspace, newest first.
+// At most limit entries are returned. If beforeID is positive, only entries
+// older than the entry with that ID are returned.
+func (db *database) GetWorkspaceActivity(workspaceID, limit, beforeID int) ([]*models.Activity, error) {
+	query := db.NewQuery()
+	query, err := query.SelectStruct(&models.Activity{}, "workspace_activity")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query: %w", err)
+	}
+
+	query = query.Where("workspace_id = ").Placeholder(workspaceID)
+	if beforeID > 0 {
+		query = query.And("id < ").Placeholder(beforeID)
+	}
+	query = query.OrderBy("id DESC").Limit(limit)
+
+	rows, err := db.Query(query.String(), query.Args()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activity: %w", err)
+	}
+	defer rows.Close()
+
+	activity := []*models.Activity{}
+	err = db.ScanStructs(rows, &activity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan activity: %w", err)
+	}
+
+	return activity, nil
+}
+
+// defaultActivityPruneBatchSize is the number of activity entries PruneActivity removes per statement if no batch size is set
+const defaultActivityPruneBatchSize = 1000
+
+// ActivityPruneOptions selects the activity entries removed by PruneActivity
+type ActivityPruneOptions struct {
+	// Before removes the entries created before this time, zero disables the age limit
+	Before time.Time
+	// MaxEntries keeps at most this many of the newest entries, 0 disables the count limit
+	MaxEntries int
+	// BatchSize is the number of entries removed per statement, 0 uses a default of 1000
+	BatchSize int
+	// Archive is called with each batch of entries, oldest first, before the batch is removed.
+	// An error stops pruning and keeps the batch.
+	Archive func(entries []*models.Activity) error
+}
+
+// DeleteActivityBefore removes all activity entries created before cutoff
+// and returns the number of removed entries
+func (db *database) DeleteActivityBefore(cutoff time.Time) (int64, error) {
+	return db.PruneActivity(ActivityPruneOptions{Before: cutoff})
+}
+
+// PruneActivity removes the activity entries past the age or count limit of opts, oldest first,
+// and returns the number of removed entries. Entries are removed in batches so pruning a large
+// backlog does not lock the table for long.
+func (db *database) PruneActivity(opts ActivityPruneOptions) (int64, error) {
+	log := getLogger().WithGroup("activity")
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultActivityPruneBatchSize
+	}
+
+	// Entries up to the newest one beyond the count limit are removed
+	var maxPrunedID int
+	if opts.MaxEntries > 0 {
+		query := db.NewQuery().
+			Select("id").
+			From("workspace_activity").
+			OrderBy("id DESC").
+			Limit(1).
+			Offset(opts.MaxEntries)
+		err := db.QueryRow(query.String(), query.Args()...).Scan(&maxPrunedID)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, fmt.Errorf("failed to find activity over the limit: %w", err)
+		}
+	}
+	if opts.Before.IsZero() && maxPrunedID == 0 {
+		return 0, nil
+	}
+
+	// prunable adds the condition matching the entries to remove
+	prunable := func(query *Query) {
+		query.StartGroup()
+		if !opts.Before.IsZero() {
+			query.Write("created_at < ").Placeholder(opts.Before.UTC())
+			if maxPrunedID > 0 {
+				query.Or("id <= ").Placeholder(maxPrunedID)
+			}
+		} else {
+			query.Write("id <= ").Placeholder(maxPrunedID)
+		}
+		query.EndGroup()
+	}
+
+	var removed int64
+	for {
+		query, err := db.NewQuery().SelectStruct(&models.Activity{}, "workspace_activity")
+		if err != nil {
+			return removed, fmt.Errorf("failed to create query: %w", err)
+		}
+		prunable(query)
+		query = query.OrderBy("id").Limit(opts.BatchSize)
+
+		rows, err := db.Query(query.String(), query.Args()...)
+		if err != nil {
+			return removed, fmt.Errorf("failed to query activity: %w", err)
+		}
+		batch := []*models.Activity{}
+		err = db.ScanStructs(rows, &batch)
+		rows.Close()
+		if err != nil {
+			return removed, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		if opts.Archive != nil {
+			if err := opts.Archive(batch); err != nil {
+				return removed, fmt.Errorf("failed to archive activity: %w", err)
+			}
+		}
+
+		// The batch is every prunable entry up to its last ID
+		deleteQuery := db.NewQuery().
+			Delete().
+			From("workspace_activity").
+			Where("id <= ").Placeholder(batch[len(batch)-1].ID)
+		prunable(deleteQuery)
+
+		result, err := db.Exec(deleteQuery.String(), deleteQuery.Args()...)
+		if err != nil {
+			return removed, fmt.Errorf("failed to delete activity: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return removed, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		removed += rowsAffected
+
+		if len(batch) < opts.BatchSize {
+			break
+		}
+	}
+
+	log.Debug("pruned workspace activity", "entries_removed", removed)
+	return removed, nil
+}
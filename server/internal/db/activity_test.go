@@ -0,0 +1,194 @@
+package db_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"lemma/internal/db"
+	"lemma/internal/models"
+	_ "lemma/internal/testenv"
+)
+
+func TestActivityOperations(t *testing.T) {
+	database, err := db.NewTestSQLiteDB(&mockSecrets{})
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer database.Close()
+
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	user, err := database.CreateUser(&models.User{
+		Email:        "activity@example.com",
+		DisplayName:  "Activity User",
+		PasswordHash: "hash",
+		Role:         models.RoleEditor,
+		Theme:        "dark",
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	workspaceID := user.LastWorkspaceID
+
+	record := func(activityType models.ActivityType, path string) *models.Activity {
+		t.Helper()
+		activity := &models.Activity{
+			WorkspaceID: workspaceID,
+			UserID:      user.ID,
+			Type:        activityType,
+			Path:        path,
+		}
+		if err := database.CreateActivity(activity); err != nil {
+			t.Fatalf("failed to create activity: %v", err)
+		}
+		return activity
+	}
+
+	t.Run("CreateActivity", func(t *testing.T) {
+		activity := record(models.ActivityFileSaved, "notes/a.md")
+		if activity.ID == 0 {
+			t.Error("expected non-zero ID")
+		}
+		if activity.CreatedAt.IsZero() {
+			t.Error("expected CreatedAt to be set")
+		}
+	})
+
+	t.Run("GetWorkspaceActivity", func(t *testing.T) {
+		record(models.ActivityFileMoved, "notes/b.md")
+		last := record(models.ActivityFileDeleted, "notes/c.md")
+
+		entries, err := database.GetWorkspaceActivity(workspaceID, 2, 0)
+		if err != nil {
+			t.Fatalf("failed to get activity: %v", err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("got %d entries, want 2", len(entries))
+		}
+		if entries[0].ID != last.ID || entries[0].Type != models.ActivityFileDeleted {
+			t.Errorf("first entry = %+v, want newest entry %+v", entries[0], last)
+		}
+		if entries[0].UserID != user.ID || entries[0].Path != "notes/c.md" {
+			t.Errorf("first entry = %+v, want actor %d and path notes/c.md", entries[0], user.ID)
+		}
+
+		// Next page continues after the cursor
+		next, err := database.GetWorkspaceActivity(workspaceID, 2, entries[1].ID)
+		if err != nil {
+			t.Fatalf("failed to get activity: %v", err)
+		}
+		if len(next) != 1 || next[0].Path != "notes/a.md" {
+			t.Errorf("next page = %+v, want only notes/a.md", next)
+		}
+
+		// Other workspaces have no activity
+		other, err := database.GetWorkspaceActivity(workspaceID+1000, 10, 0)
+		if err != nil {
+			t.Fatalf("failed to get activity: %v", err)
+		}
+		if len(other) != 0 {
+			t.Errorf("got %d entries for other workspace, want 0", len(other))
+		}
+	})
+
+	t.Run("DeleteActivityBefore", func(t *testing.T) {
+		// Nothing is older than an hour ago
+		removed, err := database.DeleteActivityBefore(time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("failed to delete activity: %v", err)
+		}
+		if removed != 0 {
+			t.Errorf("removed %d entries, want 0", removed)
+		}
+
+		removed, err = database.DeleteActivityBefore(time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("failed to delete activity: %v", err)
+		}
+		if removed != 3 {
+			t.Errorf("removed %d entries, want 3", removed)
+		}
+	})
+	t.Run("PruneActivity", func(t *testing.T) {
+		// Five entries a day apart, the oldest first
+		var entries []*models.Activity
+		for i := 0; i < 5; i++ {
+			activity := record(models.ActivityFileSaved, "prune.md")
+			createdAt := time.Now().Add(-time.Duration(5-i) * 24 * time.Hour).UTC()
+			if _, err := database.TestDB().Exec("UPDATE workspace_activity SET created_at = ? WHERE id = ?", createdAt, activity.ID); err != nil {
+				t.Fatalf("failed to backdate activity: %v", err)
+			}
+			entries = append(entries, activity)
+		}
+		recent := record(models.ActivityFileSaved, "recent.md")
+
+		remaining := func(t *testing.T) []int {
+			t.Helper()
+			activity, err := database.GetWorkspaceActivity(workspaceID, 100, 0)
+			if err != nil {
+				t.Fatalf("failed to get activity: %v", err)
+			}
+			var ids []int
+			for _, a := range activity {
+				ids = append(ids, a.ID)
+			}
+			return ids
+		}
+
+		// Entries older than the retention are removed, in batches passed to the archive first
+		var archived [][]int
+		removed, err := database.PruneActivity(db.ActivityPruneOptions{
+			Before:    time.Now().Add(-36 * time.Hour),
+			BatchSize: 2,
+			Archive: func(batch []*models.Activity) error {
+				var ids []int
+				for _, a := range batch {
+					ids = append(ids, a.ID)
+				}
+				archived = append(archived, ids)
+				return nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to prune activity: %v", err)
+		}
+		if removed != 4 {
+			t.Errorf("removed %d entries, want 4", removed)
+		}
+		want := [][]int{{entries[0].ID, entries[1].ID}, {entries[2].ID, entries[3].ID}}
+		if !reflect.DeepEqual(archived, want) {
+			t.Errorf("archived batches = %v, want %v", archived, want)
+		}
+		if got := remaining(t); !reflect.DeepEqual(got, []int{recent.ID, entries[4].ID}) {
+			t.Errorf("remaining = %v, want the two recent entries", got)
+		}
+
+		// A failing archive keeps the entries
+		_, err = database.PruneActivity(db.ActivityPruneOptions{
+			MaxEntries: 1,
+			Archive:    func([]*models.Activity) error { return errors.New("disk full") },
+		})
+		if err == nil {
+			t.Error("expected archive error")
+		}
+		if got := remaining(t); len(got) != 2 {
+			t.Errorf("remaining = %v, want both entries kept", got)
+		}
+
+		// Only the newest entries within the count limit are kept
+		removed, err = database.PruneActivity(db.ActivityPruneOptions{MaxEntries: 1})
+		if err != nil {
+			t.Fatalf("failed to prune activity: %v", err)
+		}
+		if removed != 1 {
+			t.Errorf("removed %d entries, want 1", removed)
+		}
+		if got := remaining(t); !reflect.DeepEqual(got, []int{recent.ID}) {
+			t.Errorf("remaining = %v, want only %d", got, recent.ID)
+		}
+	})
+}
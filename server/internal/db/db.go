@@ -2,8 +2,10 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"lemma/internal/logging"
 	"lemma/internal/models"
@@ -24,21 +26,31 @@ const (
 type UserStore interface {
 	CreateUser(user *models.User) (*models.User, error)
 	GetUserByEmail(email string) (*models.User, error)
+	GetUserByDisplayName(displayName string) (*models.User, error)
 	GetUserByID(userID int) (*models.User, error)
+	GetUserByIDContext(ctx context.Context, userID int) (*models.User, error)
 	GetAllUsers() ([]*models.User, error)
+	ListUsers(filter UserFilter) ([]*models.User, int, error)
 	UpdateUser(user *models.User) error
+	UpdateUserFields(user *models.User, fields ...string) error
 	DeleteUser(userID int) error
 	UpdateLastWorkspace(userID int, workspaceName string) error
 	GetLastWorkspaceName(userID int) (string, error)
+	GetDefaultWorkspace(userID int) (*models.Workspace, error)
 	CountAdminUsers() (int, error)
+	GetUserPreferences(userID int) (string, error)
+	UpdateUserPreferences(userID int, preferences string) error
 }
 
 // WorkspaceReader defines the methods for reading workspace data from the database
 type WorkspaceReader interface {
 	GetWorkspaceByID(workspaceID int) (*models.Workspace, error)
 	GetWorkspaceByName(userID int, workspaceName string) (*models.Workspace, error)
+	GetWorkspaceByNameContext(ctx context.Context, userID int, workspaceName string) (*models.Workspace, error)
+	GetWorkspaceByNormalizedName(userID int, normalizedName string, ignoreCase bool) (*models.Workspace, error)
 	GetWorkspacesByUserID(userID int) ([]*models.Workspace, error)
 	GetAllWorkspaces() ([]*models.Workspace, error)
+	GetAllWorkspacesContext(ctx context.Context) ([]*models.Workspace, error)
 }
 
 // WorkspaceWriter defines the methods for writing workspace data to the database
@@ -49,8 +61,11 @@ type WorkspaceWriter interface {
 	UpdateWorkspaceSettings(workspace *models.Workspace) error
 	DeleteWorkspaceTx(tx *sql.Tx, workspaceID int) error
 	UpdateLastWorkspaceTx(tx *sql.Tx, userID, workspaceID int) error
+	TransferWorkspaceTx(tx *sql.Tx, workspace *models.Workspace) error
+	BatchUpdateWorkspaceSettings(filter WorkspaceFilter, patch *models.WorkspaceSettingsPatch) ([]*models.Workspace, error)
 	UpdateLastOpenedFile(workspaceID int, filePath string) error
 	GetLastOpenedFile(workspaceID int) (string, error)
+	UpdateHomeFile(workspaceID int, filePath string) error
 }
 
 // WorkspaceStore defines the methods for interacting with workspace data in the database
@@ -64,10 +79,33 @@ type SessionStore interface {
 	CreateSession(session *models.Session) error
 	GetSessionByRefreshToken(refreshToken string) (*models.Session, error)
 	GetSessionByID(sessionID string) (*models.Session, error)
+	GetSessionsByUserID(userID int) ([]*models.Session, error)
+	RotateRefreshToken(sessionID, oldRefreshToken, newRefreshToken string, expiresAt time.Time) error
 	DeleteSession(sessionID string) error
+	DeleteSessionsByUserID(userID int) error
 	CleanExpiredSessions() error
 }
 
+// PasswordResetStore defines the methods for interacting with password reset tokens in the database
+type PasswordResetStore interface {
+	CreatePasswordResetToken(token *models.PasswordResetToken) error
+	ResetPasswordWithToken(tokenHash, passwordHash string) (int, error)
+}
+
+// ActivityStore defines the methods for interacting with workspace activity in the database
+type ActivityStore interface {
+	CreateActivity(activity *models.Activity) error
+	GetWorkspaceActivity(workspaceID, limit, beforeID int) ([]*models.Activity, error)
+	DeleteActivityBefore(cutoff time.Time) (int64, error)
+	PruneActivity(opts ActivityPruneOptions) (int64, error)
+}
+
+// AccessLogStore defines the methods for interacting with the file access log in the database
+type AccessLogStore interface {
+	CreateFileAccess(access *models.FileAccess) error
+	GetFileAccessLog(workspaceID, limit, beforeID int) ([]*models.FileAccess, error)
+}
+
 // SystemStore defines the methods for interacting with system stats in the database
 type SystemStore interface {
 	GetSystemStats() (*UserStats, error)
@@ -83,9 +121,18 @@ type Database interface {
 	UserStore
 	WorkspaceStore
 	SessionStore
+	PasswordResetStore
+	ActivityStore
+	AccessLogStore
 	SystemStore
 	StructScanner
+	DecryptAuditor
 	Begin() (*sql.Tx, error)
+	WithTx(opts *sql.TxOptions, fn func(tx *sql.Tx) error) error
+	CountRows(q *Query) (int, error)
+	ExecContext(ctx context.Context, q *Query) (sql.Result, error)
+	QueryContext(ctx context.Context, q *Query) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, q *Query) *sql.Row
 	Close() error
 	Migrate() error
 }
@@ -99,12 +146,15 @@ var (
 	_ UserStore      = (*database)(nil)
 	_ WorkspaceStore = (*database)(nil)
 	_ SessionStore   = (*database)(nil)
+	_ ActivityStore  = (*database)(nil)
+	_ AccessLogStore = (*database)(nil)
 	_ SystemStore    = (*database)(nil)
 
 	// Sub-interfaces
 	_ WorkspaceReader = (*database)(nil)
 	_ WorkspaceWriter = (*database)(nil)
 	_ StructScanner   = (*database)(nil)
+	_ DecryptAuditor  = (*database)(nil)
 )
 
 var logger logging.Logger
@@ -121,14 +171,31 @@ type database struct {
 	*sql.DB
 	secretsService secrets.Service
 	dbType         DBType
+	decryptHook    DecryptHook
+}
+
+// Options configures how the database connection is opened
+type Options struct {
+	// SQLiteOptions are driver options appended to the SQLite DSN, e.g. _journal_mode=WAL
+	SQLiteOptions map[string]string
 }
 
 // Init initializes the database connection
 func Init(dbType DBType, dbURL string, secretsService secrets.Service) (Database, error) {
+	return InitWithOptions(dbType, dbURL, secretsService, Options{})
+}
+
+// InitWithOptions initializes the database connection with the given options
+func InitWithOptions(dbType DBType, dbURL string, secretsService secrets.Service, options Options) (Database, error) {
 
 	switch dbType {
 	case DBTypeSQLite:
-		db, err := initSQLite(dbURL)
+		dsn, err := BuildSQLiteDSN(dbURL, options.SQLiteOptions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SQLite options: %w", err)
+		}
+
+		db, err := initSQLite(dsn)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize SQLite database: %w", err)
 		}
@@ -202,3 +269,31 @@ func (db *database) Close() error {
 func (db *database) NewQuery() *Query {
 	return NewQuery(db.dbType, db.secretsService)
 }
+
+// CountRows runs q, which must select a single integer such as COUNT(*), and returns the result
+func (db *database) CountRows(q *Query) (int, error) {
+	if q.dbType != db.dbType {
+		return 0, fmt.Errorf("failed to count rows: query built for %s, database is %s", q.dbType, db.dbType)
+	}
+
+	var count int
+	if err := db.QueryRow(q.String(), q.Args()...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+	return count, nil
+}
+
+// ExecContext runs q without returning rows, the statement is cancelled when ctx is done
+func (db *database) ExecContext(ctx context.Context, q *Query) (sql.Result, error) {
+	return db.DB.ExecContext(ctx, q.String(), q.Args()...)
+}
+
+// QueryContext runs q and returns the resulting rows, the query is cancelled when ctx is done
+func (db *database) QueryContext(ctx context.Context, q *Query) (*sql.Rows, error) {
+	return db.DB.QueryContext(ctx, q.String(), q.Args()...)
+}
+
+// QueryRowContext runs q, which is expected to return at most one row, the query is cancelled when ctx is done
+func (db *database) QueryRowContext(ctx context.Context, q *Query) *sql.Row {
+	return db.DB.QueryRowContext(ctx, q.String(), q.Args()...)
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"reflect"
+)
+
+// DecryptEvent describes the decryption of an encrypted field while scanning a record.
+// It never contains the decrypted value.
+type DecryptEvent struct {
+	Model       string // name of the struct being scanned, e.g. "Workspace"
+	Field       string // name of the decrypted struct field, e.g. "GitToken"
+	RecordID    int    // value of the ID field, 0 if not present
+	UserID      int    // value of the UserID field, 0 if not present
+	WorkspaceID int    // ID of the workspace the field belongs to, 0 if not a workspace
+}
+
+// DecryptHook is called each time an encrypted field is decrypted
+type DecryptHook func(event DecryptEvent)
+
+// DecryptAuditor allows observing decryption of encrypted fields
+type DecryptAuditor interface {
+	SetDecryptHook(hook DecryptHook)
+}
+
+// SetDecryptHook sets the hook called on each decryption of an encrypted field.
+// It should be set before the database is used concurrently.
+func (db *database) SetDecryptHook(hook DecryptHook) {
+	db.decryptHook = hook
+}
+
+// auditDecrypt calls the decrypt hook, if set, with the decryption of fieldName in destVal
+func (db *database) auditDecrypt(destVal reflect.Value, fieldName string) {
+	if db.decryptHook == nil {
+		return
+	}
+
+	event := DecryptEvent{
+		Model:    destVal.Type().Name(),
+		Field:    fieldName,
+		RecordID: intField(destVal, "ID"),
+		UserID:   intField(destVal, "UserID"),
+	}
+	if event.Model == "Workspace" {
+		event.WorkspaceID = event.RecordID
+	} else {
+		event.WorkspaceID = intField(destVal, "WorkspaceID")
+	}
+	db.decryptHook(event)
+}
+
+// intField returns the value of the named int field of v, or 0 if there is no such field
+func intField(v reflect.Value, name string) int {
+	field := v.FieldByName(name)
+	if !field.IsValid() || field.Kind() != reflect.Int {
+		return 0
+	}
+	return int(field.Int())
+}
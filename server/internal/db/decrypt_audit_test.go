@@ -0,0 +1,94 @@
+package db_test
+
+import (
+	"testing"
+
+	"lemma/internal/db"
+	"lemma/internal/models"
+	_ "lemma/internal/testenv"
+)
+
+func TestDecryptHook(t *testing.T) {
+	database, err := db.NewTestSQLiteDB(&mockSecrets{})
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer database.Close()
+
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	user, err := database.CreateUser(&models.User{
+		Email:        "audit@example.com",
+		DisplayName:  "Audit User",
+		PasswordHash: "hash",
+		Role:         models.RoleEditor,
+		Theme:        "dark",
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	var events []db.DecryptEvent
+	database.SetDecryptHook(func(event db.DecryptEvent) {
+		events = append(events, event)
+	})
+
+	t.Run("fires on workspace fetch with encrypted token", func(t *testing.T) {
+		events = nil
+		workspace := &models.Workspace{
+			UserID:         user.ID,
+			Name:           "Git Workspace",
+			GitEnabled:     true,
+			GitURL:         "https://github.com/user/repo",
+			GitUser:        "username",
+			GitToken:       "secret-token",
+			GitCommitName:  "Test User",
+			GitCommitEmail: "test@example.com",
+		}
+		workspace.SetDefaultSettings()
+		if err := database.CreateWorkspace(workspace); err != nil {
+			t.Fatalf("failed to create workspace: %v", err)
+		}
+
+		if _, err := database.GetWorkspaceByID(workspace.ID); err != nil {
+			t.Fatalf("failed to get workspace: %v", err)
+		}
+
+		if len(events) != 1 {
+			t.Fatalf("hook called %d times, want 1", len(events))
+		}
+
+		want := db.DecryptEvent{
+			Model:       "Workspace",
+			Field:       "GitToken",
+			RecordID:    workspace.ID,
+			UserID:      user.ID,
+			WorkspaceID: workspace.ID,
+		}
+		if events[0] != want {
+			t.Errorf("event = %+v, want %+v", events[0], want)
+		}
+	})
+
+	t.Run("does not fire without encrypted token", func(t *testing.T) {
+		events = nil
+		workspace := &models.Workspace{
+			UserID: user.ID,
+			Name:   "Plain Workspace",
+		}
+		workspace.SetDefaultSettings()
+		if err := database.CreateWorkspace(workspace); err != nil {
+			t.Fatalf("failed to create workspace: %v", err)
+		}
+
+		if _, err := database.GetWorkspaceByID(workspace.ID); err != nil {
+			t.Fatalf("failed to get workspace: %v", err)
+		}
+
+		if len(events) != 0 {
+			t.Errorf("hook called %d times, want 0", len(events))
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"lemma/internal/models"
+)
+
+// GetWorkspaceGitRemotes returns the additional git remotes of a workspace ordered by name
+func (db *database) GetWorkspaceGitRemotes(workspaceID int) ([]models.GitRemote, error) {
+	query, err := db.NewQuery().
+		SelectStruct(&models.GitRemote{}, "workspace_git_remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query: %w", err)
+	}
+	query = query.Where("workspace_id = ").Placeholder(workspaceID).
+		OrderBy("name ASC")
+
+	rows, err := db.Query(query.String(), query.Args()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query git remotes: %w", err)
+	}
+	defer rows.Close()
+
+	remotes := []models.GitRemote{}
+	if err := db.ScanStructs(rows, &remotes); err != nil {
+		return nil, fmt.Errorf("failed to scan git remotes: %w", err)
+	}
+	return remotes, nil
+}
+
+// replaceGitRemotesTx replaces the additional git remotes of a workspace in a transaction
+func (db *database) replaceGitRemotesTx(tx *sql.Tx, workspaceID int, remotes []models.GitRemote) error {
+	query := db.NewQuery().
+		Delete().
+		From("workspace_git_remotes").
+		Where("workspace_id = ").Placeholder(workspaceID)
+	if _, err := tx.Exec(query.String(), query.Args()...); err != nil {
+		return fmt.Errorf("failed to delete git remotes: %w", err)
+	}
+
+	for i := range remotes {
+		remotes[i].WorkspaceID = workspaceID
+		query, err := db.NewQuery().
+			InsertStruct(&remotes[i], "workspace_git_remotes")
+		if err != nil {
+			return fmt.Errorf("failed to create query: %w", err)
+		}
+		query.Returning("id")
+
+		if err := tx.QueryRow(query.String(), query.Args()...).Scan(&remotes[i].ID); err != nil {
+			return fmt.Errorf("failed to insert git remote %s: %w", remotes[i].Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+-- 002_workspace_settings_backfill.up.sql (PostgreSQL version)
+
+-- Apply default settings to workspaces created before the settings existed
+UPDATE workspaces SET theme = 'dark' WHERE theme IS NULL OR theme = '';
+UPDATE workspaces SET git_commit_msg_template = '${action} ${filename}'
+    WHERE git_commit_msg_template IS NULL OR git_commit_msg_template = '';
+UPDATE workspaces SET git_auto_commit = FALSE WHERE git_enabled = FALSE;
@@ -0,0 +1,2 @@
+-- 003_user_preferences.down.sql (PostgreSQL version)
+ALTER TABLE users DROP COLUMN preferences;
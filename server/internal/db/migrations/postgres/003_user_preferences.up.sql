@@ -0,0 +1,4 @@
+-- 003_user_preferences.up.sql (PostgreSQL version)
+
+-- Opaque UI preferences blob stored as JSON text
+ALTER TABLE users ADD COLUMN preferences TEXT;
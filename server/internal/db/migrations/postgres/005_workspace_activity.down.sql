@@ -0,0 +1,4 @@
+-- 005_workspace_activity.down.sql (PostgreSQL version)
+DROP INDEX IF EXISTS idx_workspace_activity_created_at;
+DROP INDEX IF EXISTS idx_workspace_activity_workspace_id;
+DROP TABLE IF EXISTS workspace_activity;
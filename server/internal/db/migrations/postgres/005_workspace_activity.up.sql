@@ -0,0 +1,18 @@
+-- 005_workspace_activity.up.sql (PostgreSQL version)
+
+-- Create workspace activity table for the activity feed
+CREATE TABLE IF NOT EXISTS workspace_activity (
+    id SERIAL PRIMARY KEY,
+    workspace_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    path TEXT,
+    target_path TEXT,
+    message TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_activity_workspace_id ON workspace_activity(workspace_id, id);
+CREATE INDEX IF NOT EXISTS idx_workspace_activity_created_at ON workspace_activity(created_at);
@@ -0,0 +1,3 @@
+-- 006_workspace_save_hooks.down.sql (PostgreSQL version)
+ALTER TABLE workspaces DROP COLUMN trim_trailing_whitespace;
+ALTER TABLE workspaces DROP COLUMN normalize_line_endings;
@@ -0,0 +1,2 @@
+-- 007_workspace_resolve_includes.down.sql (PostgreSQL version)
+ALTER TABLE workspaces DROP COLUMN resolve_includes;
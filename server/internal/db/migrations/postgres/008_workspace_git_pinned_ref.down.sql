@@ -0,0 +1,2 @@
+-- 008_workspace_git_pinned_ref.down.sql (PostgreSQL version)
+ALTER TABLE workspaces DROP COLUMN git_pinned_ref;
@@ -0,0 +1,4 @@
+-- 010_workspace_markdown_lint.down.sql (PostgreSQL version)
+ALTER TABLE workspaces DROP COLUMN lint_required_frontmatter;
+ALTER TABLE workspaces DROP COLUMN lint_strict;
+ALTER TABLE workspaces DROP COLUMN lint_markdown;
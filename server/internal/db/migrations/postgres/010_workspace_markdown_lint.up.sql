@@ -0,0 +1,6 @@
+-- 010_workspace_markdown_lint.up.sql (PostgreSQL version)
+
+-- Markdown lint run when files are saved
+ALTER TABLE workspaces ADD COLUMN lint_markdown BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE workspaces ADD COLUMN lint_strict BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE workspaces ADD COLUMN lint_required_frontmatter TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- 011_workspace_git_remotes.down.sql (PostgreSQL version)
+DROP TABLE IF EXISTS workspace_git_remotes;
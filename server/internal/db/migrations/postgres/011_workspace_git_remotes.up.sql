@@ -0,0 +1,13 @@
+-- 011_workspace_git_remotes.up.sql (PostgreSQL version)
+
+-- Additional git remotes a workspace pushes to, e.g. mirrors
+CREATE TABLE IF NOT EXISTS workspace_git_remotes (
+    id SERIAL PRIMARY KEY,
+    workspace_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    git_user TEXT NOT NULL,
+    git_token TEXT NOT NULL,
+    FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    UNIQUE (workspace_id, name)
+);
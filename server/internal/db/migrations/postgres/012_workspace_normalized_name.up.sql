@@ -0,0 +1,6 @@
+-- 012_workspace_normalized_name.up.sql (PostgreSQL version)
+
+-- Form of the workspace name compared for uniqueness when names are normalized
+ALTER TABLE workspaces ADD COLUMN normalized_name TEXT NOT NULL DEFAULT '';
+UPDATE workspaces SET normalized_name = name;
+CREATE INDEX IF NOT EXISTS idx_workspaces_normalized_name ON workspaces(user_id, normalized_name);
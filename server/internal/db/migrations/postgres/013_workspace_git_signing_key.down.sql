@@ -0,0 +1,2 @@
+-- 013_workspace_git_signing_key.down.sql (PostgreSQL version)
+ALTER TABLE workspaces DROP COLUMN git_signing_key;
@@ -0,0 +1,4 @@
+-- 013_workspace_git_signing_key.up.sql (PostgreSQL version)
+
+-- Encrypted GPG or SSH private key the commits of a workspace are signed with
+ALTER TABLE workspaces ADD COLUMN git_signing_key TEXT;
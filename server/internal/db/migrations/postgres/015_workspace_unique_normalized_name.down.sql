@@ -0,0 +1,3 @@
+-- 015_workspace_unique_normalized_name.down.sql (PostgreSQL version)
+DROP INDEX IF EXISTS idx_workspaces_normalized_name;
+CREATE INDEX IF NOT EXISTS idx_workspaces_normalized_name ON workspaces(user_id, normalized_name);
@@ -0,0 +1,2 @@
+-- 002_workspace_settings_backfill.down.sql
+-- Backfilled default settings are kept, nothing to revert
@@ -0,0 +1,2 @@
+-- 003_user_preferences.down.sql
+ALTER TABLE users DROP COLUMN preferences;
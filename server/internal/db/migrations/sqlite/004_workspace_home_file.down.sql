@@ -0,0 +1,2 @@
+-- 004_workspace_home_file.down.sql
+ALTER TABLE workspaces DROP COLUMN home_file;
@@ -0,0 +1,4 @@
+-- 004_workspace_home_file.up.sql
+
+-- Canonical landing file of a workspace
+ALTER TABLE workspaces ADD COLUMN home_file TEXT;
@@ -0,0 +1,5 @@
+-- 006_workspace_save_hooks.up.sql
+
+-- Built-in save hooks toggled per workspace
+ALTER TABLE workspaces ADD COLUMN normalize_line_endings BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE workspaces ADD COLUMN trim_trailing_whitespace BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- 007_workspace_resolve_includes.up.sql
+
+-- Transclusion of {{include: path}} directives toggled per workspace
+ALTER TABLE workspaces ADD COLUMN resolve_includes BOOLEAN NOT NULL DEFAULT 0;
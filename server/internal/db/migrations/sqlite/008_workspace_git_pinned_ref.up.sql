@@ -0,0 +1,4 @@
+-- 008_workspace_git_pinned_ref.up.sql
+
+-- Tag or commit a workspace is pinned to as a read-only snapshot
+ALTER TABLE workspaces ADD COLUMN git_pinned_ref TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- 009_password_reset_tokens.down.sql
+DROP INDEX IF EXISTS idx_password_reset_tokens_user_id;
+DROP TABLE IF EXISTS password_reset_tokens;
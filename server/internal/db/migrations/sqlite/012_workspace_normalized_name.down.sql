@@ -0,0 +1,3 @@
+-- 012_workspace_normalized_name.down.sql
+DROP INDEX IF EXISTS idx_workspaces_normalized_name;
+ALTER TABLE workspaces DROP COLUMN normalized_name;
@@ -0,0 +1,4 @@
+-- 014_file_access_log.down.sql
+DROP INDEX IF EXISTS idx_file_access_log_workspace_id;
+DROP TABLE IF EXISTS file_access_log;
+ALTER TABLE workspaces DROP COLUMN access_logging;
@@ -0,0 +1,18 @@
+-- 014_file_access_log.up.sql
+
+-- Opt-in log of who read which file of a workspace
+ALTER TABLE workspaces ADD COLUMN access_logging BOOLEAN NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS file_access_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    workspace_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    path TEXT NOT NULL,
+    ip_address TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_access_log_workspace_id ON file_access_log(workspace_id, id);
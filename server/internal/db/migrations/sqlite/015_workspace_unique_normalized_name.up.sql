@@ -0,0 +1,15 @@
+-- 015_workspace_unique_normalized_name.up.sql
+
+-- Workspace names identify workspaces in URLs, so a normalized name is unique per user.
+-- Existing duplicates keep the name on the oldest workspace, the others get their id appended.
+UPDATE workspaces
+SET name = name || ' (' || CAST(id AS TEXT) || ')',
+    normalized_name = normalized_name || ' (' || CAST(id AS TEXT) || ')'
+WHERE EXISTS (
+    SELECT 1 FROM workspaces AS older
+    WHERE older.user_id = workspaces.user_id
+      AND older.normalized_name = workspaces.normalized_name
+      AND older.id < workspaces.id
+);
+DROP INDEX IF EXISTS idx_workspaces_normalized_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workspaces_normalized_name ON workspaces(user_id, normalized_name);
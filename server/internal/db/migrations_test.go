@@ -26,6 +26,8 @@ func TestMigrate(t *testing.T) {
 			"users",
 			"workspaces",
 			"sessions",
+			"workspace_activity",
+			"password_reset_tokens",
 			"schema_migrations",
 		}
 
@@ -44,6 +46,10 @@ func TestMigrate(t *testing.T) {
 			{"sessions", "idx_sessions_expires_at"},
 			{"sessions", "idx_sessions_refresh_token"},
 			{"workspaces", "idx_workspaces_user_id"},
+			{"workspaces", "idx_workspaces_normalized_name"},
+			{"workspace_activity", "idx_workspace_activity_workspace_id"},
+			{"workspace_activity", "idx_workspace_activity_created_at"},
+			{"password_reset_tokens", "idx_password_reset_tokens_user_id"},
 		}
 		for _, idx := range indexes {
 			if !indexExists(t, database, idx.table, idx.name) {
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"lemma/internal/models"
+)
+
+// CreatePasswordResetToken inserts a new password reset token into the database.
+// Expired tokens of all users are removed at the same time.
+func (db *database) CreatePasswordResetToken(token *models.PasswordResetToken) error {
+	return db.WithTx(serializableTx, func(tx *sql.Tx) error {
+		cleanup := db.NewQuery().
+			Delete().
+			From("password_reset_tokens").
+			Where("expires_at <=").
+			Placeholder(time.Now())
+		if _, err := tx.Exec(cleanup.String(), cleanup.Args()...); err != nil {
+			return fmt.Errorf("failed to clean expired password reset tokens: %w", err)
+		}
+
+		query, err := db.NewQuery().
+			InsertStruct(token, "password_reset_tokens")
+		if err != nil {
+			return fmt.Errorf("failed to create query: %w", err)
+		}
+		query.Returning("id", "created_at")
+
+		err = tx.QueryRow(query.String(), query.Args()...).
+			Scan(&token.ID, &token.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to store password reset token: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ResetPasswordWithToken sets the password hash of the user the unexpired token with tokenHash belongs to
+// and returns the ID of that user. The token and all other reset tokens of the user are deleted,
+// so a token can be used exactly once.
+func (db *database) ResetPasswordWithToken(tokenHash, passwordHash string) (int, error) {
+	var userID int
+	err := db.WithTx(serializableTx, func(tx *sql.Tx) error {
+		query := db.NewQuery().
+			Delete().
+			From("password_reset_tokens").
+			Where("token_hash = ").
+			Placeholder(tokenHash).
+			And("expires_at >").
+			Placeholder(time.Now()).
+			Returning("user_id")
+
+		err := tx.QueryRow(query.String(), query.Args()...).Scan(&userID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("password reset token not found or expired")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to consume password reset token: %w", err)
+		}
+
+		update := db.NewQuery().
+			Update("users").
+			Set("password_hash").Placeholder(passwordHash).
+			Where("id = ").Placeholder(userID)
+		if _, err := tx.Exec(update.String(), update.Args()...); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		cleanup := db.NewQuery().
+			Delete().
+			From("password_reset_tokens").
+			Where("user_id = ").
+			Placeholder(userID)
+		if _, err := tx.Exec(cleanup.String(), cleanup.Args()...); err != nil {
+			return fmt.Errorf("failed to delete password reset tokens: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
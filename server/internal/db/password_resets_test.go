@@ -0,0 +1,100 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"lemma/internal/db"
+	"lemma/internal/models"
+	_ "lemma/internal/testenv"
+)
+
+func TestPasswordResetTokens(t *testing.T) {
+	database, err := db.NewTestSQLiteDB(&mockSecrets{})
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer database.Close()
+
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	user, err := database.CreateUser(&models.User{
+		Email:        "reset@example.com",
+		DisplayName:  "Reset User",
+		PasswordHash: "old-hash",
+		Role:         models.RoleEditor,
+		Theme:        "dark",
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	createToken := func(t *testing.T, hash string, expiresAt time.Time) {
+		t.Helper()
+		token := &models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: hash,
+			ExpiresAt: expiresAt,
+		}
+		if err := database.CreatePasswordResetToken(token); err != nil {
+			t.Fatalf("failed to create password reset token: %v", err)
+		}
+		if token.ID == 0 {
+			t.Error("expected non-zero ID")
+		}
+	}
+
+	t.Run("reset with valid token", func(t *testing.T) {
+		createToken(t, "valid", time.Now().Add(time.Hour))
+		createToken(t, "other", time.Now().Add(time.Hour))
+
+		userID, err := database.ResetPasswordWithToken("valid", "new-hash")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if userID != user.ID {
+			t.Errorf("userID = %d, want %d", userID, user.ID)
+		}
+
+		updated, err := database.GetUserByID(user.ID)
+		if err != nil {
+			t.Fatalf("failed to get user: %v", err)
+		}
+		if updated.PasswordHash != "new-hash" {
+			t.Errorf("PasswordHash = %q, want %q", updated.PasswordHash, "new-hash")
+		}
+	})
+
+	t.Run("token reuse", func(t *testing.T) {
+		if _, err := database.ResetPasswordWithToken("valid", "reused-hash"); err == nil {
+			t.Error("expected error for used token, got nil")
+		}
+		// Using a token invalidates the other tokens of the user
+		if _, err := database.ResetPasswordWithToken("other", "reused-hash"); err == nil {
+			t.Error("expected error for token of a reset user, got nil")
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		createToken(t, "expired", time.Now().Add(-time.Minute))
+		if _, err := database.ResetPasswordWithToken("expired", "expired-hash"); err == nil {
+			t.Error("expected error for expired token, got nil")
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		if _, err := database.ResetPasswordWithToken("unknown", "unknown-hash"); err == nil {
+			t.Error("expected error for unknown token, got nil")
+		}
+
+		updated, err := database.GetUserByID(user.ID)
+		if err != nil {
+			t.Fatalf("failed to get user: %v", err)
+		}
+		if updated.PasswordHash != "new-hash" {
+			t.Errorf("PasswordHash = %q, want %q", updated.PasswordHash, "new-hash")
+		}
+	})
+}
@@ -3,9 +3,14 @@ package db
 import (
 	"fmt"
 	"lemma/internal/secrets"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
+// postgresPlaceholder matches the numbered placeholders of PostgreSQL queries
+var postgresPlaceholder = regexp.MustCompile(`\$(\d+)`)
+
 type JoinType string
 
 const (
@@ -22,6 +27,7 @@ type Query struct {
 	secretsService secrets.Service
 	pos            int // tracks the current placeholder position
 	hasSelect      bool
+	distinct       bool
 	hasFrom        bool
 	hasWhere       bool
 	hasOrderBy     bool
@@ -46,12 +52,34 @@ func NewQuery(dbType DBType, secretsService secrets.Service) *Query {
 func (q *Query) Select(columns ...string) *Query {
 	if !q.hasSelect {
 		q.Write("SELECT ")
+		if q.distinct {
+			q.Write("DISTINCT ")
+		}
 		q.Write(strings.Join(columns, ", "))
 		q.hasSelect = true
 	}
 	return q
 }
 
+// Distinct makes the query return only distinct rows, it can be called before or after Select
+func (q *Query) Distinct() *Query {
+	if q.distinct {
+		return q
+	}
+	q.distinct = true
+	if q.hasSelect {
+		sql := q.builder.String()
+		q.builder.Reset()
+		q.Write(strings.Replace(sql, "SELECT ", "SELECT DISTINCT ", 1))
+	}
+	return q
+}
+
+// SelectCountDistinct adds a SELECT clause counting the distinct values of column
+func (q *Query) SelectCountDistinct(column string) *Query {
+	return q.Select("COUNT(DISTINCT " + column + ")")
+}
+
 // From adds a FROM clause
 func (q *Query) From(table string) *Query {
 	if !q.hasFrom {
@@ -89,6 +117,98 @@ func (q *Query) WhereIn(column string, count int) *Query {
 	return q
 }
 
+// WhereExists adds a WHERE EXISTS condition with sub as the subquery.
+// The arguments of sub are appended to the query, so arguments of earlier
+// placeholders must already have been added.
+func (q *Query) WhereExists(sub *Query) *Query {
+	if !q.hasWhere {
+		q.Write(" WHERE ")
+		q.hasWhere = true
+	} else {
+		q.Write(" AND ")
+	}
+	q.Write("EXISTS (")
+	q.writeSubquery(sub)
+	q.Write(")")
+	return q
+}
+
+// likeEscaper escapes the LIKE wildcards and the escape character itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escapes s so that it matches literally in a LIKE pattern built for WhereLike or WhereILike
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// WhereLike adds a WHERE column LIKE condition with pattern as its argument.
+// User input in pattern must be escaped with EscapeLike.
+func (q *Query) WhereLike(column, pattern string) *Query {
+	return q.whereLike(column, "LIKE", pattern)
+}
+
+// WhereILike is WhereLike matching case-insensitively, which uses ILIKE on PostgreSQL.
+// SQLite LIKE already ignores case for ASCII characters.
+func (q *Query) WhereILike(column, pattern string) *Query {
+	if q.dbType == DBTypePostgres {
+		return q.whereLike(column, "ILIKE", pattern)
+	}
+	return q.whereLike(column, "LIKE", pattern)
+}
+
+// ILike writes a case-insensitive column LIKE condition like WhereILike, but without a WHERE or AND,
+// for use in a group combined with Or
+func (q *Query) ILike(column, pattern string) *Query {
+	if q.dbType == DBTypePostgres {
+		return q.like(column, "ILIKE", pattern)
+	}
+	return q.like(column, "LIKE", pattern)
+}
+
+func (q *Query) whereLike(column, operator, pattern string) *Query {
+	if !q.hasWhere {
+		q.Write(" WHERE ")
+		q.hasWhere = true
+	} else {
+		q.Write(" AND ")
+	}
+	return q.like(column, operator, pattern)
+}
+
+func (q *Query) like(column, operator, pattern string) *Query {
+	q.Write(column)
+	q.Write(" " + operator + " ")
+	q.Placeholder(pattern)
+	q.Write(` ESCAPE '\'`)
+	return q
+}
+
+// Exists wraps the query as SELECT EXISTS(...), which returns whether the query matches any row
+func (q *Query) Exists() *Query {
+	inner := q.builder.String()
+	q.builder.Reset()
+	q.Write("SELECT EXISTS(")
+	q.Write(inner)
+	q.Write(")")
+	return q
+}
+
+// writeSubquery writes sub into the query and appends its arguments.
+// PostgreSQL placeholders of sub are renumbered to continue after the placeholders of the query.
+func (q *Query) writeSubquery(sub *Query) {
+	subSQL := sub.String()
+	if q.dbType == DBTypePostgres && q.pos > 0 {
+		offset := q.pos
+		subSQL = postgresPlaceholder.ReplaceAllStringFunc(subSQL, func(placeholder string) string {
+			n, _ := strconv.Atoi(placeholder[1:])
+			return fmt.Sprintf("$%d", n+offset)
+		})
+	}
+	q.Write(subSQL)
+	q.args = append(q.args, sub.args...)
+	q.pos += sub.pos
+}
+
 // And adds an AND condition
 func (q *Query) And(condition string) *Query {
 	q.Write(" AND ")
@@ -184,6 +304,18 @@ func (q *Query) Values(count int) *Query {
 	return q
 }
 
+// ValuesRows adds a VALUES clause with rowCount rows of colsPerRow placeholders each.
+// The arguments are added with AddArgs row by row, in the column order of Insert.
+func (q *Query) ValuesRows(rowCount, colsPerRow int) *Query {
+	for i := range rowCount {
+		if i > 0 {
+			q.Write(", ")
+		}
+		q.Values(colsPerRow)
+	}
+	return q
+}
+
 // Update starts an UPDATE statement
 func (q *Query) Update(table string) *Query {
 	q.Write("UPDATE ")
@@ -203,6 +335,78 @@ func (q *Query) Set(column string) *Query {
 	return q
 }
 
+// SetExpr assigns the result of an SQL expression to a column in UpdateFrom
+type SetExpr struct {
+	Column string
+	Value  string
+}
+
+// UpdateFrom writes an update of table that sets columns from the rows of source whose sourceKey
+// equals key of table. Rows of table without a matching source row are left unchanged.
+// Source may be a table or a subquery with an alias and should match at most one row per updated row.
+// PostgreSQL uses UPDATE ... FROM, SQLite a correlated subquery per column restricted by
+// WHERE key IN (SELECT sourceKey ...). filter, if not nil, writes extra conditions on source rows;
+// it is called once for every subquery, so arguments must be added with Placeholder.
+// Further conditions on the updated rows can be added with Where.
+func (q *Query) UpdateFrom(table, key, source, sourceKey string, set []SetExpr, filter func(q *Query)) *Query {
+	match := sourceKey + " = " + table + "." + key
+	writeFilter := func(prefix string) {
+		if filter != nil {
+			q.Write(prefix)
+			q.Write("(")
+			filter(q)
+			q.Write(")")
+		}
+	}
+
+	q.Write("UPDATE ")
+	q.Write(table)
+	q.Write(" SET ")
+
+	if q.dbType == DBTypePostgres {
+		for i, expr := range set {
+			if i > 0 {
+				q.Write(", ")
+			}
+			q.Write(expr.Column)
+			q.Write(" = ")
+			q.Write(expr.Value)
+		}
+		q.Write(" FROM ")
+		q.Write(source)
+		q.Write(" WHERE ")
+		q.Write(match)
+		writeFilter(" AND ")
+		q.hasWhere = true
+		return q
+	}
+
+	for i, expr := range set {
+		if i > 0 {
+			q.Write(", ")
+		}
+		q.Write(expr.Column)
+		q.Write(" = (SELECT ")
+		q.Write(expr.Value)
+		q.Write(" FROM ")
+		q.Write(source)
+		q.Write(" WHERE ")
+		q.Write(match)
+		writeFilter(" AND ")
+		q.Write(")")
+	}
+	q.Write(" WHERE ")
+	q.Write(key)
+	q.Write(" IN (SELECT ")
+	q.Write(sourceKey)
+	q.Write(" FROM ")
+	q.Write(source)
+	writeFilter(" WHERE ")
+	q.Write(")")
+	q.hasWhere = true
+	return q
+}
+
 // Delete starts a DELETE statement
 func (q *Query) Delete() *Query {
 	q.Write("DELETE")
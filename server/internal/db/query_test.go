@@ -1,10 +1,15 @@
 package db_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"lemma/internal/db"
+	"lemma/internal/models"
 )
 
 func TestNewQuery(t *testing.T) {
@@ -854,3 +859,857 @@ func TestComplexQueries(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateFrom(t *testing.T) {
+	set := []db.SetExpr{
+		{Column: "last_workspace_id", Value: "w.id"},
+		{Column: "theme", Value: "w.theme"},
+	}
+	source := "(SELECT user_id, MIN(id) AS id, MIN(theme) AS theme FROM workspaces GROUP BY user_id) w"
+	filter := func(q *db.Query) {
+		q.Write("w.id > ").Placeholder(10)
+	}
+
+	tests := []struct {
+		name     string
+		dbType   db.DBType
+		buildFn  func(*db.Query) *db.Query
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:   "SQLite",
+			dbType: db.DBTypeSQLite,
+			buildFn: func(q *db.Query) *db.Query {
+				return q.UpdateFrom("users", "id", source, "w.user_id", set, nil)
+			},
+			wantSQL: "UPDATE users SET last_workspace_id = (SELECT w.id FROM " + source + " WHERE w.user_id = users.id), " +
+				"theme = (SELECT w.theme FROM " + source + " WHERE w.user_id = users.id) " +
+				"WHERE id IN (SELECT w.user_id FROM " + source + ")",
+			wantArgs: []any{},
+		},
+		{
+			name:   "Postgres",
+			dbType: db.DBTypePostgres,
+			buildFn: func(q *db.Query) *db.Query {
+				return q.UpdateFrom("users", "id", source, "w.user_id", set, nil)
+			},
+			wantSQL:  "UPDATE users SET last_workspace_id = w.id, theme = w.theme FROM " + source + " WHERE w.user_id = users.id",
+			wantArgs: []any{},
+		},
+		{
+			name:   "SQLite with filter and where",
+			dbType: db.DBTypeSQLite,
+			buildFn: func(q *db.Query) *db.Query {
+				return q.UpdateFrom("users", "id", "workspaces w", "w.user_id", set[:1], filter).
+					Where("role = ").Placeholder("editor")
+			},
+			wantSQL: "UPDATE users SET last_workspace_id = (SELECT w.id FROM workspaces w WHERE w.user_id = users.id AND (w.id > ?)) " +
+				"WHERE id IN (SELECT w.user_id FROM workspaces w WHERE (w.id > ?)) AND role = ?",
+			wantArgs: []any{10, 10, "editor"},
+		},
+		{
+			name:   "Postgres with filter and where",
+			dbType: db.DBTypePostgres,
+			buildFn: func(q *db.Query) *db.Query {
+				return q.UpdateFrom("users", "id", "workspaces w", "w.user_id", set[:1], filter).
+					Where("role = ").Placeholder("editor")
+			},
+			wantSQL:  "UPDATE users SET last_workspace_id = w.id FROM workspaces w WHERE w.user_id = users.id AND (w.id > $1) AND role = $2",
+			wantArgs: []any{10, "editor"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := db.NewQuery(tt.dbType, &mockSecrets{})
+			q = tt.buildFn(q)
+
+			if got := q.String(); got != tt.wantSQL {
+				t.Errorf("Query.String() = %q, want %q", got, tt.wantSQL)
+			}
+			if got := q.Args(); !reflect.DeepEqual(got, tt.wantArgs) {
+				t.Errorf("Query.Args() = %v, want %v", got, tt.wantArgs)
+			}
+		})
+	}
+
+	t.Run("SQLite execution", func(t *testing.T) {
+		database, err := db.NewTestSQLiteDB(&mockSecrets{})
+		if err != nil {
+			t.Fatalf("failed to create test database: %v", err)
+		}
+		defer database.Close()
+		if err := database.Migrate(); err != nil {
+			t.Fatalf("failed to run migrations: %v", err)
+		}
+
+		users := make([]*models.User, 3)
+		for i := range users {
+			users[i], err = database.CreateUser(&models.User{
+				Email:        fmt.Sprintf("user%d@example.com", i),
+				DisplayName:  "User",
+				PasswordHash: "hash",
+				Role:         models.RoleEditor,
+				Theme:        "dark",
+			})
+			if err != nil {
+				t.Fatalf("failed to create user: %v", err)
+			}
+		}
+
+		// The second user gets a newer workspace, the third user is excluded by the filter
+		newer := &models.Workspace{UserID: users[1].ID, Name: "Newer"}
+		if err := database.CreateWorkspace(newer); err != nil {
+			t.Fatalf("failed to create workspace: %v", err)
+		}
+		if _, err := database.TestDB().Exec("UPDATE users SET last_workspace_id = 0"); err != nil {
+			t.Fatalf("failed to reset last workspaces: %v", err)
+		}
+
+		q := db.NewQuery(db.DBTypeSQLite, &mockSecrets{}).
+			UpdateFrom("users", "id",
+				"(SELECT user_id, MAX(id) AS id FROM workspaces GROUP BY user_id) w", "w.user_id",
+				[]db.SetExpr{{Column: "last_workspace_id", Value: "w.id"}},
+				func(q *db.Query) { q.Write("w.user_id != ").Placeholder(users[2].ID) })
+		if _, err := database.TestDB().Exec(q.String(), q.Args()...); err != nil {
+			t.Fatalf("failed to execute update: %v", err)
+		}
+
+		want := map[int]int{
+			users[0].ID: users[0].LastWorkspaceID,
+			users[1].ID: newer.ID,
+			users[2].ID: 0,
+		}
+		for userID, wantWorkspaceID := range want {
+			var got int
+			err := database.TestDB().QueryRow("SELECT last_workspace_id FROM users WHERE id = ?", userID).Scan(&got)
+			if err != nil {
+				t.Fatalf("failed to read user: %v", err)
+			}
+			if got != wantWorkspaceID {
+				t.Errorf("user %d last_workspace_id = %d, want %d", userID, got, wantWorkspaceID)
+			}
+		}
+	})
+}
+
+func TestValuesRows(t *testing.T) {
+	users := [][]any{
+		{"first@example.com", "First", "hash", models.RoleEditor, "dark"},
+		{"second@example.com", "Second", "hash", models.RoleViewer, "light"},
+		{"third@example.com", "Third", "hash", models.RoleAdmin, "dark"},
+	}
+	columns := []string{"email", "display_name", "password_hash", "role", "theme"}
+
+	buildFn := func(q *db.Query) *db.Query {
+		q = q.Insert("users", columns...).ValuesRows(len(users), len(columns))
+		for _, user := range users {
+			q = q.AddArgs(user...)
+		}
+		return q.Returning("id")
+	}
+
+	var wantArgs []any
+	for _, user := range users {
+		wantArgs = append(wantArgs, user...)
+	}
+
+	tests := []struct {
+		name    string
+		dbType  db.DBType
+		wantSQL string
+	}{
+		{
+			name:   "SQLite",
+			dbType: db.DBTypeSQLite,
+			wantSQL: "INSERT INTO users (email, display_name, password_hash, role, theme) VALUES " +
+				"(?, ?, ?, ?, ?), (?, ?, ?, ?, ?), (?, ?, ?, ?, ?) RETURNING id",
+		},
+		{
+			name:   "Postgres",
+			dbType: db.DBTypePostgres,
+			wantSQL: "INSERT INTO users (email, display_name, password_hash, role, theme) VALUES " +
+				"($1, $2, $3, $4, $5), ($6, $7, $8, $9, $10), ($11, $12, $13, $14, $15) RETURNING id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := buildFn(db.NewQuery(tt.dbType, &mockSecrets{}))
+
+			if got := q.String(); got != tt.wantSQL {
+				t.Errorf("Query.String() = %q, want %q", got, tt.wantSQL)
+			}
+			if got := q.Args(); !reflect.DeepEqual(got, wantArgs) {
+				t.Errorf("Query.Args() = %v, want %v", got, wantArgs)
+			}
+		})
+	}
+
+	t.Run("placeholders continue after rows", func(t *testing.T) {
+		q := db.NewQuery(db.DBTypePostgres, &mockSecrets{}).
+			Insert("t", "a").
+			ValuesRows(2, 1).
+			Write(" ON CONFLICT (a) DO UPDATE SET a = ").Placeholder(3)
+
+		want := "INSERT INTO t (a) VALUES ($1), ($2) ON CONFLICT (a) DO UPDATE SET a = $3"
+		if got := q.String(); got != want {
+			t.Errorf("Query.String() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("SQLite execution", func(t *testing.T) {
+		database, err := db.NewTestSQLiteDB(&mockSecrets{})
+		if err != nil {
+			t.Fatalf("failed to create test database: %v", err)
+		}
+		defer database.Close()
+		if err := database.Migrate(); err != nil {
+			t.Fatalf("failed to run migrations: %v", err)
+		}
+
+		q := buildFn(db.NewQuery(db.DBTypeSQLite, &mockSecrets{}))
+		rows, err := database.TestDB().Query(q.String(), q.Args()...)
+		if err != nil {
+			t.Fatalf("failed to execute insert: %v", err)
+		}
+		defer rows.Close()
+
+		var ids []int
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				t.Fatalf("failed to scan id: %v", err)
+			}
+			ids = append(ids, id)
+		}
+		if len(ids) != len(users) {
+			t.Fatalf("inserted %d rows, want %d", len(ids), len(users))
+		}
+
+		for i, id := range ids {
+			var email, displayName string
+			err := database.TestDB().QueryRow("SELECT email, display_name FROM users WHERE id = ?", id).Scan(&email, &displayName)
+			if err != nil {
+				t.Fatalf("failed to read user: %v", err)
+			}
+			if email != users[i][0] || displayName != users[i][1] {
+				t.Errorf("user %d = %s (%s), want %s (%s)", id, email, displayName, users[i][0], users[i][1])
+			}
+		}
+	})
+}
+
+func TestCountRows(t *testing.T) {
+	database, err := db.NewTestSQLiteDB(&mockSecrets{})
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	roles := []models.UserRole{models.RoleAdmin, models.RoleEditor, models.RoleAdmin}
+	users := make([]*models.User, len(roles))
+	for i, role := range roles {
+		users[i], err = database.CreateUser(&models.User{
+			Email:        fmt.Sprintf("count%d@example.com", i),
+			DisplayName:  "User",
+			PasswordHash: "hash",
+			Role:         role,
+			Theme:        "dark",
+		})
+		if err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+
+	// Every user gets a default workspace, the second one two more
+	for _, name := range []string{"Second", "Third"} {
+		if err := database.CreateWorkspace(&models.Workspace{UserID: users[1].ID, Name: name}); err != nil {
+			t.Fatalf("failed to create workspace: %v", err)
+		}
+	}
+
+	newQuery := func() *db.Query {
+		return db.NewQuery(db.DBTypeSQLite, &mockSecrets{})
+	}
+
+	tests := []struct {
+		name  string
+		query *db.Query
+		want  int
+	}{
+		{
+			name:  "admin users",
+			query: newQuery().Select("COUNT(*)").From("users").Where("role = ").Placeholder(models.RoleAdmin),
+			want:  2,
+		},
+		{
+			name:  "workspaces of user",
+			query: newQuery().Select("COUNT(*)").From("workspaces").Where("user_id = ").Placeholder(users[1].ID),
+			want:  3,
+		},
+		{
+			name: "workspaces of editors with join",
+			query: newQuery().Select("COUNT(*)").From("workspaces w").
+				Join(db.InnerJoin, "users u", "u.id = w.user_id").
+				Where("u.role = ").Placeholder(models.RoleEditor),
+			want: 3,
+		},
+		{
+			name:  "no matching rows",
+			query: newQuery().Select("COUNT(*)").From("users").Where("email = ").Placeholder("nobody@example.com"),
+			want:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := database.CountRows(tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CountRows() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid query", func(t *testing.T) {
+		got, err := database.CountRows(newQuery().Select("COUNT(*)").From("missing_table"))
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+		if got != 0 {
+			t.Errorf("CountRows() = %d, want 0", got)
+		}
+	})
+
+	t.Run("wrong dialect", func(t *testing.T) {
+		q := db.NewQuery(db.DBTypePostgres, &mockSecrets{}).Select("COUNT(*)").From("users")
+		if _, err := database.CountRows(q); err == nil {
+			t.Error("expected error for a PostgreSQL query, got nil")
+		}
+	})
+}
+
+func TestQueryContext(t *testing.T) {
+	database, err := db.NewTestSQLiteDB(&mockSecrets{})
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	// slowQuery counts to a billion, which takes far longer than the test waits before cancelling
+	slowQuery := func() *db.Query {
+		return db.NewQuery(db.DBTypeSQLite, &mockSecrets{}).
+			Write("WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 1000000000) ").
+			Select("COUNT(*)").From("c")
+	}
+	cancelSoon := func() context.Context {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		return ctx
+	}
+
+	t.Run("QueryContext", func(t *testing.T) {
+		rows, err := database.QueryContext(cancelSoon(), slowQuery())
+		if err == nil {
+			defer rows.Close()
+			for rows.Next() {
+			}
+			err = rows.Err()
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("QueryRowContext", func(t *testing.T) {
+		var count int
+		err := database.QueryRowContext(cancelSoon(), slowQuery()).Scan(&count)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("ExecContext", func(t *testing.T) {
+		_, err := database.ExecContext(cancelSoon(), slowQuery())
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("store method", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := database.GetAllWorkspacesContext(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		if _, err := database.GetAllWorkspacesContext(context.Background()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestExists(t *testing.T) {
+	workspaceLookup := func(dbType db.DBType) *db.Query {
+		return db.NewQuery(dbType, &mockSecrets{}).
+			Select("1").
+			From("workspaces").
+			Where("workspaces.user_id = users.id").
+			Where("workspaces.name = ").Placeholder("Notes").
+			Where("workspaces.theme = ").Placeholder("dark")
+	}
+
+	tests := []struct {
+		name     string
+		dbType   db.DBType
+		buildFn  func(*db.Query) *db.Query
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:   "WhereExists SQLite",
+			dbType: db.DBTypeSQLite,
+			buildFn: func(q *db.Query) *db.Query {
+				return q.Select("id").From("users").
+					Where("role = ").Placeholder("editor").
+					WhereExists(workspaceLookup(db.DBTypeSQLite)).
+					Where("id > ").Placeholder(10)
+			},
+			wantSQL: "SELECT id FROM users WHERE role = ? AND EXISTS (SELECT 1 FROM workspaces " +
+				"WHERE workspaces.user_id = users.id AND workspaces.name = ? AND workspaces.theme = ?) AND id > ?",
+			wantArgs: []any{"editor", "Notes", "dark", 10},
+		},
+		{
+			name:   "WhereExists Postgres",
+			dbType: db.DBTypePostgres,
+			buildFn: func(q *db.Query) *db.Query {
+				return q.Select("id").From("users").
+					Where("role = ").Placeholder("editor").
+					WhereExists(workspaceLookup(db.DBTypePostgres)).
+					Where("id > ").Placeholder(10)
+			},
+			wantSQL: "SELECT id FROM users WHERE role = $1 AND EXISTS (SELECT 1 FROM workspaces " +
+				"WHERE workspaces.user_id = users.id AND workspaces.name = $2 AND workspaces.theme = $3) AND id > $4",
+			wantArgs: []any{"editor", "Notes", "dark", 10},
+		},
+		{
+			name:   "WhereExists as first condition Postgres",
+			dbType: db.DBTypePostgres,
+			buildFn: func(q *db.Query) *db.Query {
+				return q.Select("id").From("users").
+					WhereExists(workspaceLookup(db.DBTypePostgres)).
+					Where("id > ").Placeholder(10)
+			},
+			wantSQL: "SELECT id FROM users WHERE EXISTS (SELECT 1 FROM workspaces " +
+				"WHERE workspaces.user_id = users.id AND workspaces.name = $1 AND workspaces.theme = $2) AND id > $3",
+			wantArgs: []any{"Notes", "dark", 10},
+		},
+		{
+			name:   "Exists SQLite",
+			dbType: db.DBTypeSQLite,
+			buildFn: func(q *db.Query) *db.Query {
+				return q.Select("1").From("workspaces").
+					Where("user_id = ").Placeholder(1).
+					Where("name = ").Placeholder("Notes").
+					Exists()
+			},
+			wantSQL:  "SELECT EXISTS(SELECT 1 FROM workspaces WHERE user_id = ? AND name = ?)",
+			wantArgs: []any{1, "Notes"},
+		},
+		{
+			name:   "Exists Postgres",
+			dbType: db.DBTypePostgres,
+			buildFn: func(q *db.Query) *db.Query {
+				return q.Select("1").From("workspaces").
+					Where("user_id = ").Placeholder(1).
+					Where("name = ").Placeholder("Notes").
+					Exists()
+			},
+			wantSQL:  "SELECT EXISTS(SELECT 1 FROM workspaces WHERE user_id = $1 AND name = $2)",
+			wantArgs: []any{1, "Notes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.buildFn(db.NewQuery(tt.dbType, &mockSecrets{}))
+
+			if got := q.String(); got != tt.wantSQL {
+				t.Errorf("Query.String() = %q, want %q", got, tt.wantSQL)
+			}
+			if got := q.Args(); !reflect.DeepEqual(got, tt.wantArgs) {
+				t.Errorf("Query.Args() = %v, want %v", got, tt.wantArgs)
+			}
+		})
+	}
+
+	t.Run("SQLite execution", func(t *testing.T) {
+		database, err := db.NewTestSQLiteDB(&mockSecrets{})
+		if err != nil {
+			t.Fatalf("failed to create test database: %v", err)
+		}
+		defer database.Close()
+		if err := database.Migrate(); err != nil {
+			t.Fatalf("failed to run migrations: %v", err)
+		}
+
+		user, err := database.CreateUser(&models.User{
+			Email:        "exists@example.com",
+			DisplayName:  "User",
+			PasswordHash: "hash",
+			Role:         models.RoleEditor,
+			Theme:        "dark",
+		})
+		if err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		if err := database.CreateWorkspace(&models.Workspace{UserID: user.ID, Name: "Notes", Theme: "dark"}); err != nil {
+			t.Fatalf("failed to create workspace: %v", err)
+		}
+
+		for _, tc := range []struct {
+			name string
+			want bool
+		}{
+			{name: "Notes", want: true},
+			{name: "Missing", want: false},
+		} {
+			q := db.NewQuery(db.DBTypeSQLite, &mockSecrets{}).
+				Select("1").From("workspaces").
+				Where("user_id = ").Placeholder(user.ID).
+				Where("name = ").Placeholder(tc.name).
+				Exists()
+
+			var exists bool
+			if err := database.TestDB().QueryRow(q.String(), q.Args()...).Scan(&exists); err != nil {
+				t.Fatalf("failed to execute query: %v", err)
+			}
+			if exists != tc.want {
+				t.Errorf("workspace %q exists = %v, want %v", tc.name, exists, tc.want)
+			}
+		}
+
+		q := db.NewQuery(db.DBTypeSQLite, &mockSecrets{}).
+			Select("email").From("users").
+			Where("role = ").Placeholder(models.RoleEditor).
+			WhereExists(workspaceLookup(db.DBTypeSQLite))
+		var email string
+		if err := database.TestDB().QueryRow(q.String(), q.Args()...).Scan(&email); err != nil {
+			t.Fatalf("failed to execute query: %v", err)
+		}
+		if email != user.Email {
+			t.Errorf("email = %q, want %q", email, user.Email)
+		}
+	})
+}
+
+func TestDistinct(t *testing.T) {
+	tests := []struct {
+		name         string
+		buildFn      func(*db.Query) *db.Query
+		wantSQLite   string
+		wantPostgres string
+		wantArgs     []any
+	}{
+		{
+			name: "distinct before select",
+			buildFn: func(q *db.Query) *db.Query {
+				return q.Distinct().Select("id", "name").From("users")
+			},
+			wantSQLite:   "SELECT DISTINCT id, name FROM users",
+			wantPostgres: "SELECT DISTINCT id, name FROM users",
+			wantArgs:     []any{},
+		},
+		{
+			name: "distinct after select",
+			buildFn: func(q *db.Query) *db.Query {
+				return q.Select("id", "name").From("users").Distinct()
+			},
+			wantSQLite:   "SELECT DISTINCT id, name FROM users",
+			wantPostgres: "SELECT DISTINCT id, name FROM users",
+			wantArgs:     []any{},
+		},
+		{
+			name: "distinct called twice",
+			buildFn: func(q *db.Query) *db.Query {
+				return q.Distinct().Select("id").Distinct().From("users")
+			},
+			wantSQLite:   "SELECT DISTINCT id FROM users",
+			wantPostgres: "SELECT DISTINCT id FROM users",
+			wantArgs:     []any{},
+		},
+		{
+			name: "distinct with where",
+			buildFn: func(q *db.Query) *db.Query {
+				return q.Distinct().Select("user_id").From("workspaces").
+					Where("theme = ").Placeholder("dark")
+			},
+			wantSQLite:   "SELECT DISTINCT user_id FROM workspaces WHERE theme = ?",
+			wantPostgres: "SELECT DISTINCT user_id FROM workspaces WHERE theme = $1",
+			wantArgs:     []any{"dark"},
+		},
+		{
+			name: "count distinct",
+			buildFn: func(q *db.Query) *db.Query {
+				return q.SelectCountDistinct("user_id").From("workspaces")
+			},
+			wantSQLite:   "SELECT COUNT(DISTINCT user_id) FROM workspaces",
+			wantPostgres: "SELECT COUNT(DISTINCT user_id) FROM workspaces",
+			wantArgs:     []any{},
+		},
+		{
+			name: "count distinct with where and group by",
+			buildFn: func(q *db.Query) *db.Query {
+				return q.SelectCountDistinct("user_id").
+					From("workspaces").
+					Where("git_enabled = ").Placeholder(true).
+					GroupBy("theme")
+			},
+			wantSQLite:   "SELECT COUNT(DISTINCT user_id) FROM workspaces WHERE git_enabled = ? GROUP BY theme",
+			wantPostgres: "SELECT COUNT(DISTINCT user_id) FROM workspaces WHERE git_enabled = $1 GROUP BY theme",
+			wantArgs:     []any{true},
+		},
+	}
+
+	for _, tt := range tests {
+		for dbType, wantSQL := range map[db.DBType]string{
+			db.DBTypeSQLite:   tt.wantSQLite,
+			db.DBTypePostgres: tt.wantPostgres,
+		} {
+			t.Run(fmt.Sprintf("%s %s", tt.name, dbType), func(t *testing.T) {
+				q := tt.buildFn(db.NewQuery(dbType, &mockSecrets{}))
+
+				if got := q.String(); got != wantSQL {
+					t.Errorf("Query.String() = %q, want %q", got, wantSQL)
+				}
+				if got := q.Args(); !reflect.DeepEqual(got, tt.wantArgs) {
+					t.Errorf("Query.Args() = %v, want %v", got, tt.wantArgs)
+				}
+			})
+		}
+	}
+
+	t.Run("SQLite execution", func(t *testing.T) {
+		database, err := db.NewTestSQLiteDB(&mockSecrets{})
+		if err != nil {
+			t.Fatalf("failed to create test database: %v", err)
+		}
+		defer database.Close()
+		if err := database.Migrate(); err != nil {
+			t.Fatalf("failed to run migrations: %v", err)
+		}
+
+		user, err := database.CreateUser(&models.User{
+			Email:        "distinct@example.com",
+			DisplayName:  "User",
+			PasswordHash: "hash",
+			Role:         models.RoleEditor,
+			Theme:        "dark",
+		})
+		if err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		for _, name := range []string{"Notes", "Journal"} {
+			if err := database.CreateWorkspace(&models.Workspace{UserID: user.ID, Name: name, Theme: "dark"}); err != nil {
+				t.Fatalf("failed to create workspace: %v", err)
+			}
+		}
+
+		count, err := database.CountRows(db.NewQuery(db.DBTypeSQLite, &mockSecrets{}).SelectCountDistinct("user_id").From("workspaces"))
+		if err != nil {
+			t.Fatalf("failed to count rows: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("CountRows = %d, want 1", count)
+		}
+	})
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "notes", want: "notes"},
+		{input: "100%", want: `100\%`},
+		{input: "my_file", want: `my\_file`},
+		{input: `back\slash`, want: `back\\slash`},
+		{input: `\%_`, want: `\\\%\_`},
+		{input: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := db.EscapeLike(tt.input); got != tt.want {
+				t.Errorf("EscapeLike(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWhereLike(t *testing.T) {
+	tests := []struct {
+		name         string
+		buildFn      func(*db.Query) *db.Query
+		wantSQLite   string
+		wantPostgres string
+		wantArgs     []any
+	}{
+		{
+			name: "like",
+			buildFn: func(q *db.Query) *db.Query {
+				return q.Select("id").From("workspaces").WhereLike("name", db.EscapeLike("50%")+"%")
+			},
+			wantSQLite:   `SELECT id FROM workspaces WHERE name LIKE ? ESCAPE '\'`,
+			wantPostgres: `SELECT id FROM workspaces WHERE name LIKE $1 ESCAPE '\'`,
+			wantArgs:     []any{`50\%%`},
+		},
+		{
+			name: "like after where",
+			buildFn: func(q *db.Query) *db.Query {
+				return q.Select("id").From("workspaces").
+					Where("user_id = ").Placeholder(1).
+					WhereLike("name", "%notes%")
+			},
+			wantSQLite:   `SELECT id FROM workspaces WHERE user_id = ? AND name LIKE ? ESCAPE '\'`,
+			wantPostgres: `SELECT id FROM workspaces WHERE user_id = $1 AND name LIKE $2 ESCAPE '\'`,
+			wantArgs:     []any{1, "%notes%"},
+		},
+		{
+			name: "case-insensitive like",
+			buildFn: func(q *db.Query) *db.Query {
+				return q.Select("id").From("users").WhereILike("display_name", "john%")
+			},
+			wantSQLite:   `SELECT id FROM users WHERE display_name LIKE ? ESCAPE '\'`,
+			wantPostgres: `SELECT id FROM users WHERE display_name ILIKE $1 ESCAPE '\'`,
+			wantArgs:     []any{"john%"},
+		},
+		{
+			name: "case-insensitive like in a group",
+			buildFn: func(q *db.Query) *db.Query {
+				return q.Select("id").From("users").
+					Where("role = ").Placeholder("editor").
+					StartGroup().
+					ILike("email", "%jo%").
+					Write(" OR ").
+					ILike("display_name", "%jo%").
+					EndGroup()
+			},
+			wantSQLite:   `SELECT id FROM users WHERE role = ? AND (email LIKE ? ESCAPE '\' OR display_name LIKE ? ESCAPE '\')`,
+			wantPostgres: `SELECT id FROM users WHERE role = $1 AND (email ILIKE $2 ESCAPE '\' OR display_name ILIKE $3 ESCAPE '\')`,
+			wantArgs:     []any{"editor", "%jo%", "%jo%"},
+		},
+	}
+
+	for _, tt := range tests {
+		for dbType, wantSQL := range map[db.DBType]string{
+			db.DBTypeSQLite:   tt.wantSQLite,
+			db.DBTypePostgres: tt.wantPostgres,
+		} {
+			t.Run(fmt.Sprintf("%s %s", tt.name, dbType), func(t *testing.T) {
+				q := tt.buildFn(db.NewQuery(dbType, &mockSecrets{}))
+
+				if got := q.String(); got != wantSQL {
+					t.Errorf("Query.String() = %q, want %q", got, wantSQL)
+				}
+				if got := q.Args(); !reflect.DeepEqual(got, tt.wantArgs) {
+					t.Errorf("Query.Args() = %v, want %v", got, tt.wantArgs)
+				}
+			})
+		}
+	}
+
+	t.Run("SQLite execution", func(t *testing.T) {
+		database, err := db.NewTestSQLiteDB(&mockSecrets{})
+		if err != nil {
+			t.Fatalf("failed to create test database: %v", err)
+		}
+		defer database.Close()
+		if err := database.Migrate(); err != nil {
+			t.Fatalf("failed to run migrations: %v", err)
+		}
+
+		user, err := database.CreateUser(&models.User{
+			Email:        "like@example.com",
+			DisplayName:  "User",
+			PasswordHash: "hash",
+			Role:         models.RoleEditor,
+			Theme:        "dark",
+		})
+		if err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		for _, name := range []string{"100% done", "100 percent", "my_notes", "myXnotes", `back\slash`} {
+			if err := database.CreateWorkspace(&models.Workspace{UserID: user.ID, Name: name, Theme: "dark"}); err != nil {
+				t.Fatalf("failed to create workspace: %v", err)
+			}
+		}
+
+		countTests := []struct {
+			name    string
+			buildFn func(*db.Query) *db.Query
+			want    int
+		}{
+			{
+				name: "literal percent",
+				buildFn: func(q *db.Query) *db.Query {
+					return q.WhereLike("name", db.EscapeLike("100%")+"%")
+				},
+				want: 1,
+			},
+			{
+				name: "unescaped percent",
+				buildFn: func(q *db.Query) *db.Query {
+					return q.WhereLike("name", "100%")
+				},
+				want: 2,
+			},
+			{
+				name: "literal underscore",
+				buildFn: func(q *db.Query) *db.Query {
+					return q.WhereLike("name", "%"+db.EscapeLike("_notes"))
+				},
+				want: 1,
+			},
+			{
+				name: "literal backslash",
+				buildFn: func(q *db.Query) *db.Query {
+					return q.WhereLike("name", db.EscapeLike(`back\`)+"%")
+				},
+				want: 1,
+			},
+			{
+				name: "case-insensitive",
+				buildFn: func(q *db.Query) *db.Query {
+					return q.WhereILike("name", "MY%")
+				},
+				want: 2,
+			},
+		}
+
+		for _, tt := range countTests {
+			t.Run(tt.name, func(t *testing.T) {
+				q := db.NewQuery(db.DBTypeSQLite, &mockSecrets{}).
+					Select("COUNT(*)").
+					From("workspaces").
+					Where("user_id = ").Placeholder(user.ID)
+				count, err := database.CountRows(tt.buildFn(q))
+				if err != nil {
+					t.Fatalf("failed to count rows: %v", err)
+				}
+				if count != tt.want {
+					t.Errorf("CountRows = %d, want %d", count, tt.want)
+				}
+			})
+		}
+	})
+}
@@ -73,6 +73,64 @@ func (db *database) GetSessionByID(sessionID string) (*models.Session, error) {
 	return session, nil
 }
 
+// GetSessionsByUserID retrieves the unexpired sessions of a user, newest first
+func (db *database) GetSessionsByUserID(userID int) ([]*models.Session, error) {
+	query := db.NewQuery()
+	query, err := query.SelectStruct(&models.Session{}, "sessions")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query: %w", err)
+	}
+	query = query.Where("user_id = ").
+		Placeholder(userID).
+		And("expires_at >").
+		Placeholder(time.Now()).
+		OrderBy("created_at DESC", "id")
+
+	rows, err := db.Query(query.String(), query.Args()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*models.Session
+	if err := db.ScanStructs(rows, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to scan sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RotateRefreshToken replaces the refresh token of a session and extends its expiry.
+// The session is only updated if oldRefreshToken is still its current refresh token,
+// so a refresh token can be exchanged exactly once.
+func (db *database) RotateRefreshToken(sessionID, oldRefreshToken, newRefreshToken string, expiresAt time.Time) error {
+	return db.WithTx(serializableTx, func(tx *sql.Tx) error {
+		query := db.NewQuery().
+			Update("sessions").
+			Set("refresh_token").Placeholder(newRefreshToken).
+			Set("expires_at").Placeholder(expiresAt).
+			Where("id = ").Placeholder(sessionID).
+			And("refresh_token = ").Placeholder(oldRefreshToken).
+			And("expires_at >").Placeholder(time.Now())
+
+		result, err := tx.Exec(query.String(), query.Args()...)
+		if err != nil {
+			return fmt.Errorf("failed to rotate refresh token: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("session not found or expired")
+		}
+
+		return nil
+	})
+}
+
 // DeleteSession removes a session from the database
 func (db *database) DeleteSession(sessionID string) error {
 	query := db.NewQuery().
@@ -98,6 +156,29 @@ func (db *database) DeleteSession(sessionID string) error {
 	return nil
 }
 
+// DeleteSessionsByUserID removes all sessions of a user from the database
+func (db *database) DeleteSessionsByUserID(userID int) error {
+	log := getLogger().WithGroup("sessions")
+	query := db.NewQuery().
+		Delete().
+		From("sessions").
+		Where("user_id = ").
+		Placeholder(userID)
+
+	result, err := db.Exec(query.String(), query.Args()...)
+	if err != nil {
+		return fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	log.Debug("deleted user sessions", "user_id", userID, "sessions_removed", rowsAffected)
+	return nil
+}
+
 // CleanExpiredSessions removes all expired sessions from the database
 func (db *database) CleanExpiredSessions() error {
 	log := getLogger().WithGroup("sessions")
@@ -1,6 +1,7 @@
 package db_test
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -181,6 +182,44 @@ func TestSessionOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("RotateRefreshToken", func(t *testing.T) {
+		session := &models.Session{
+			ID:           uuid.New().String(),
+			UserID:       user.ID,
+			RefreshToken: "rotate-old-token",
+			ExpiresAt:    time.Now().Add(time.Hour),
+			CreatedAt:    time.Now(),
+		}
+		if err := database.CreateSession(session); err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+
+		expiresAt := time.Now().Add(24 * time.Hour)
+		if err := database.RotateRefreshToken(session.ID, "rotate-old-token", "rotate-new-token", expiresAt); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := database.GetSessionByRefreshToken("rotate-old-token"); err == nil {
+			t.Error("old refresh token still resolves to a session")
+		}
+		rotated, err := database.GetSessionByRefreshToken("rotate-new-token")
+		if err != nil {
+			t.Fatalf("failed to get rotated session: %v", err)
+		}
+		if rotated.ID != session.ID {
+			t.Errorf("ID = %v, want %v", rotated.ID, session.ID)
+		}
+		if !rotated.ExpiresAt.After(session.ExpiresAt) {
+			t.Errorf("ExpiresAt = %v, want it extended past %v", rotated.ExpiresAt, session.ExpiresAt)
+		}
+
+		// A refresh token can only be rotated once
+		err = database.RotateRefreshToken(session.ID, "rotate-old-token", "rotate-other-token", expiresAt)
+		if err == nil || !strings.Contains(err.Error(), "session not found or expired") {
+			t.Errorf("error = %v, want error containing %q", err, "session not found or expired")
+		}
+	})
+
 	t.Run("DeleteSession", func(t *testing.T) {
 		session := &models.Session{
 			ID:           uuid.New().String(),
@@ -239,6 +278,128 @@ func TestSessionOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("GetSessionsByUserID", func(t *testing.T) {
+		otherUser, err := database.CreateUser(&models.User{
+			Email:        "sessions@example.com",
+			DisplayName:  "Sessions User",
+			PasswordHash: "hash",
+			Role:         "editor",
+			Theme:        "dark",
+		})
+		if err != nil {
+			t.Fatalf("failed to create test user: %v", err)
+		}
+
+		first := &models.Session{
+			ID:           uuid.New().String(),
+			UserID:       otherUser.ID,
+			RefreshToken: "list-token-first",
+			ExpiresAt:    time.Now().Add(24 * time.Hour),
+			CreatedAt:    time.Now(),
+		}
+		second := &models.Session{
+			ID:           uuid.New().String(),
+			UserID:       otherUser.ID,
+			RefreshToken: "list-token-second",
+			ExpiresAt:    time.Now().Add(24 * time.Hour),
+			CreatedAt:    time.Now(),
+		}
+		expired := &models.Session{
+			ID:           uuid.New().String(),
+			UserID:       otherUser.ID,
+			RefreshToken: "list-token-expired",
+			ExpiresAt:    time.Now().Add(-time.Hour),
+			CreatedAt:    time.Now().Add(-2 * time.Hour),
+		}
+		for _, s := range []*models.Session{first, second, expired} {
+			if err := database.CreateSession(s); err != nil {
+				t.Fatalf("failed to create session: %v", err)
+			}
+		}
+
+		sessions, err := database.GetSessionsByUserID(otherUser.ID)
+		if err != nil {
+			t.Fatalf("failed to get sessions: %v", err)
+		}
+		if len(sessions) != 2 {
+			t.Fatalf("got %d sessions, want 2", len(sessions))
+		}
+		// Creation times are set by the database, so both sessions may share one
+		ids := map[string]bool{sessions[0].ID: true, sessions[1].ID: true}
+		if !ids[first.ID] || !ids[second.ID] {
+			t.Errorf("sessions = [%s, %s], want %s and %s",
+				sessions[0].ID, sessions[1].ID, first.ID, second.ID)
+		}
+		for _, session := range sessions {
+			if session.RefreshToken == expired.RefreshToken {
+				t.Error("expired session was returned")
+			}
+		}
+
+		sessions, err = database.GetSessionsByUserID(999)
+		if err != nil {
+			t.Fatalf("failed to get sessions: %v", err)
+		}
+		if len(sessions) != 0 {
+			t.Errorf("got %d sessions for unknown user, want 0", len(sessions))
+		}
+	})
+
+	t.Run("DeleteSessionsByUserID", func(t *testing.T) {
+		otherUser, err := database.CreateUser(&models.User{
+			Email:        "delete-sessions@example.com",
+			DisplayName:  "Delete Sessions User",
+			PasswordHash: "hash",
+			Role:         "editor",
+			Theme:        "dark",
+		})
+		if err != nil {
+			t.Fatalf("failed to create test user: %v", err)
+		}
+
+		kept := &models.Session{
+			ID:           uuid.New().String(),
+			UserID:       user.ID,
+			RefreshToken: "delete-all-kept-token",
+			ExpiresAt:    time.Now().Add(24 * time.Hour),
+			CreatedAt:    time.Now(),
+		}
+		if err := database.CreateSession(kept); err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if err := database.CreateSession(&models.Session{
+				ID:           uuid.New().String(),
+				UserID:       otherUser.ID,
+				RefreshToken: fmt.Sprintf("delete-all-token-%d", i),
+				ExpiresAt:    time.Now().Add(24 * time.Hour),
+				CreatedAt:    time.Now(),
+			}); err != nil {
+				t.Fatalf("failed to create session: %v", err)
+			}
+		}
+
+		if err := database.DeleteSessionsByUserID(otherUser.ID); err != nil {
+			t.Fatalf("failed to delete sessions: %v", err)
+		}
+
+		sessions, err := database.GetSessionsByUserID(otherUser.ID)
+		if err != nil {
+			t.Fatalf("failed to get sessions: %v", err)
+		}
+		if len(sessions) != 0 {
+			t.Errorf("got %d sessions after deletion, want 0", len(sessions))
+		}
+		if _, err := database.GetSessionByID(kept.ID); err != nil {
+			t.Errorf("session of another user was deleted: %v", err)
+		}
+
+		// Deleting without any sessions is not an error
+		if err := database.DeleteSessionsByUserID(otherUser.ID); err != nil {
+			t.Errorf("unexpected error deleting no sessions: %v", err)
+		}
+	})
+
 	t.Run("CleanExpiredSessions", func(t *testing.T) {
 		// Create a mix of valid and expired sessions
 		sessions := []*models.Session{
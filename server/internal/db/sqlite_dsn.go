@@ -0,0 +1,78 @@
+package db
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// sqliteOptionValidators lists the DSN options that may be passed to the SQLite driver
+// together with a validator for their values
+var sqliteOptionValidators = map[string]func(string) bool{
+	"_journal_mode": oneOf("DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"),
+	"_synchronous":  oneOf("OFF", "NORMAL", "FULL", "EXTRA"),
+	"_txlock":       oneOf("deferred", "immediate", "exclusive"),
+	"_busy_timeout": isNonNegativeInt,
+	"_foreign_keys": isBool,
+	"cache":         oneOf("shared", "private"),
+	"mode":          oneOf("ro", "rw", "rwc", "memory"),
+	"immutable":     isBool,
+}
+
+// BuildSQLiteDSN appends the given driver options to a SQLite path. Only a known set of
+// options is accepted, unknown options or invalid values result in an error.
+func BuildSQLiteDSN(path string, options map[string]string) (string, error) {
+	if len(options) == 0 {
+		return path, nil
+	}
+
+	if strings.Contains(path, "?") {
+		return "", fmt.Errorf("database path must not contain query parameters when SQLite options are set")
+	}
+
+	params := url.Values{}
+	needsURI := false
+	for key, value := range options {
+		validate, ok := sqliteOptionValidators[key]
+		if !ok {
+			return "", fmt.Errorf("unsupported SQLite option: %s", key)
+		}
+		if !validate(value) {
+			return "", fmt.Errorf("invalid value for SQLite option %s: %q", key, value)
+		}
+		// Options without the underscore prefix are handled by SQLite itself and
+		// are only honoured for URI filenames
+		if !strings.HasPrefix(key, "_") {
+			needsURI = true
+		}
+		params.Set(key, value)
+	}
+
+	if needsURI && !strings.HasPrefix(path, "file:") {
+		path = "file:" + path
+	}
+
+	return path + "?" + params.Encode(), nil
+}
+
+func oneOf(allowed ...string) func(string) bool {
+	return func(value string) bool {
+		for _, a := range allowed {
+			if strings.EqualFold(value, a) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func isNonNegativeInt(value string) bool {
+	n, err := strconv.Atoi(value)
+	return err == nil && n >= 0
+}
+
+func isBool(value string) bool {
+	_, err := strconv.ParseBool(value)
+	return err == nil
+}
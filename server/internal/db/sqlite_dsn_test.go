@@ -0,0 +1,126 @@
+package db_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lemma/internal/db"
+	_ "lemma/internal/testenv"
+)
+
+func TestBuildSQLiteDSN(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		options map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "no options",
+			path: "lemma.db",
+			want: "lemma.db",
+		},
+		{
+			name:    "driver options",
+			path:    "lemma.db",
+			options: map[string]string{"_journal_mode": "WAL", "_busy_timeout": "5000"},
+			want:    "lemma.db?_busy_timeout=5000&_journal_mode=WAL",
+		},
+		{
+			name:    "sqlite options use URI filename",
+			path:    ":memory:",
+			options: map[string]string{"cache": "shared"},
+			want:    "file::memory:?cache=shared",
+		},
+		{
+			name:    "existing URI filename",
+			path:    "file:lemma.db",
+			options: map[string]string{"mode": "ro"},
+			want:    "file:lemma.db?mode=ro",
+		},
+		{
+			name:    "unknown option",
+			path:    "lemma.db",
+			options: map[string]string{"_auth_pass": "secret"},
+			wantErr: "unsupported SQLite option",
+		},
+		{
+			name:    "invalid value",
+			path:    "lemma.db",
+			options: map[string]string{"_journal_mode": "WAL&_auth=1"},
+			wantErr: "invalid value",
+		},
+		{
+			name:    "negative busy timeout",
+			path:    "lemma.db",
+			options: map[string]string{"_busy_timeout": "-1"},
+			wantErr: "invalid value",
+		},
+		{
+			name:    "path with query",
+			path:    "lemma.db?_auth=1",
+			options: map[string]string{"_journal_mode": "WAL"},
+			wantErr: "must not contain query parameters",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := db.BuildSQLiteDSN(tc.path, tc.options)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("BuildSQLiteDSN() error = %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildSQLiteDSN() unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("BuildSQLiteDSN() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInitWithSQLiteOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lemma.db")
+	database, err := db.InitWithOptions(db.DBTypeSQLite, path, &mockSecrets{}, db.Options{
+		SQLiteOptions: map[string]string{"_journal_mode": "WAL", "_busy_timeout": "5000"},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	tx, err := database.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var journalMode string
+	if err := tx.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil {
+		t.Fatalf("failed to read journal mode: %v", err)
+	}
+	if !strings.EqualFold(journalMode, "wal") {
+		t.Errorf("journal_mode = %q, want wal", journalMode)
+	}
+
+	var busyTimeout int
+	if err := tx.QueryRow("PRAGMA busy_timeout").Scan(&busyTimeout); err != nil {
+		t.Fatalf("failed to read busy timeout: %v", err)
+	}
+	if busyTimeout != 5000 {
+		t.Errorf("busy_timeout = %d, want 5000", busyTimeout)
+	}
+
+	_, err = db.InitWithOptions(db.DBTypeSQLite, path, &mockSecrets{}, db.Options{
+		SQLiteOptions: map[string]string{"_loc": "auto"},
+	})
+	if err == nil {
+		t.Error("expected error for disallowed option")
+	}
+}
@@ -20,6 +20,11 @@ type DBField struct {
 
 // StructTagsToFields converts a struct to a slice of DBField instances
 func StructTagsToFields(s any) ([]DBField, error) {
+	return structFields(s, true)
+}
+
+// structFields converts a struct to DBField instances, zero omitempty fields are left out if skipEmpty is set
+func structFields(s any, skipEmpty bool) ([]DBField, error) {
 	v := reflect.ValueOf(s)
 
 	if v.Kind() == reflect.Ptr {
@@ -64,7 +69,7 @@ func StructTagsToFields(s any) ([]DBField, error) {
 			for _, opt := range parts[1:] {
 				switch opt {
 				case "omitempty":
-					if reflect.DeepEqual(v.Field(i).Interface(), reflect.Zero(f.Type).Interface()) {
+					if skipEmpty && reflect.DeepEqual(v.Field(i).Interface(), reflect.Zero(f.Type).Interface()) {
 						ommit = true
 					}
 				case "default":
@@ -179,6 +184,47 @@ func (q *Query) UpdateStruct(s any, table string) (*Query, error) {
 	return q, nil
 }
 
+// UpdateStructFields creates an UPDATE query from a struct that only sets the named columns,
+// in the given order. Named columns are set even if they are empty omitempty or default fields.
+// An error is returned if a name does not match the db tag of a struct field.
+func (q *Query) UpdateStructFields(s any, table string, fields ...string) (*Query, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields to update")
+	}
+
+	allFields, err := structFields(s, false)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]DBField, len(allFields))
+	for _, f := range allFields {
+		byName[f.Name] = f
+	}
+
+	q = q.Update(table)
+
+	for _, name := range fields {
+		f, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q for table %s", name, table)
+		}
+
+		value := f.Value
+		if f.encrypted {
+			encValue, err := q.secretsService.Encrypt(value.(string))
+			if err != nil {
+				return nil, err
+			}
+			value = encValue
+		}
+
+		q = q.Set(f.Name).Placeholder(value)
+	}
+
+	return q, nil
+}
+
 // SelectStruct creates a SELECT query from a struct
 func (q *Query) SelectStruct(s any, table string) (*Query, error) {
 	fields, err := StructTagsToFields(s)
@@ -254,6 +300,7 @@ func (db *database) scanStructInstance(destVal reflect.Value, scanner Scanner) e
 				return err
 			}
 			field.SetString(decValue)
+			db.auditDecrypt(destVal, fieldName)
 		}
 	}
 
@@ -138,6 +138,94 @@ func TestStructTagsToFields(t *testing.T) {
 }
 
 // TestStructQueries tests the struct-based query methods using the test database
+func TestUpdateStructFields(t *testing.T) {
+	type testStruct struct {
+		ID          int    `db:"id,default"`
+		Email       string `db:"email"`
+		DisplayName string `db:"display_name"`
+		Token       string `db:"token,omitempty"`
+		Skip        string `db:"-"`
+	}
+	value := testStruct{ID: 1, Email: "user@example.com", DisplayName: "New Name"}
+
+	tests := []struct {
+		name     string
+		dbType   db.DBType
+		fields   []string
+		wantSQL  string
+		wantArgs []any
+		wantErr  bool
+	}{
+		{
+			name:     "single field SQLite",
+			dbType:   db.DBTypeSQLite,
+			fields:   []string{"display_name"},
+			wantSQL:  "UPDATE users SET display_name = ?",
+			wantArgs: []any{"New Name"},
+		},
+		{
+			name:     "single field Postgres",
+			dbType:   db.DBTypePostgres,
+			fields:   []string{"display_name"},
+			wantSQL:  "UPDATE users SET display_name = $1",
+			wantArgs: []any{"New Name"},
+		},
+		{
+			name:     "fields in the given order",
+			dbType:   db.DBTypePostgres,
+			fields:   []string{"email", "display_name"},
+			wantSQL:  "UPDATE users SET email = $1, display_name = $2",
+			wantArgs: []any{"user@example.com", "New Name"},
+		},
+		{
+			name:     "empty omitempty field is written when named",
+			dbType:   db.DBTypeSQLite,
+			fields:   []string{"token"},
+			wantSQL:  "UPDATE users SET token = ?",
+			wantArgs: []any{""},
+		},
+		{
+			name:    "unknown field",
+			dbType:  db.DBTypeSQLite,
+			fields:  []string{"display_name", "password_hash"},
+			wantErr: true,
+		},
+		{
+			name:    "ignored field",
+			dbType:  db.DBTypeSQLite,
+			fields:  []string{"skip"},
+			wantErr: true,
+		},
+		{
+			name:    "no fields",
+			dbType:  db.DBTypeSQLite,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := db.NewQuery(tt.dbType, &mockSecrets{}).UpdateStructFields(value, "users", tt.fields...)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := q.String(); got != tt.wantSQL {
+				t.Errorf("Query.String() = %q, want %q", got, tt.wantSQL)
+			}
+			if got := q.Args(); !reflect.DeepEqual(got, tt.wantArgs) {
+				t.Errorf("Query.Args() = %v, want %v", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
 func TestStructQueries(t *testing.T) {
 	// Setup test database
 	database, err := db.NewTestSQLiteDB(&mockSecrets{})
@@ -237,6 +325,37 @@ func TestStructQueries(t *testing.T) {
 		}
 	})
 
+	t.Run("UpdateUserFields", func(t *testing.T) {
+		// Another change made after the user was loaded must not be overwritten
+		stale := *user
+		if _, err := database.TestDB().Exec("UPDATE users SET email = ? WHERE id = ?", "changed@example.com", user.ID); err != nil {
+			t.Fatalf("Failed to change email: %v", err)
+		}
+
+		stale.DisplayName = "Partially Updated"
+		if err := database.UpdateUserFields(&stale, "display_name"); err != nil {
+			t.Fatalf("Failed to update user fields: %v", err)
+		}
+
+		updatedUser, err := database.GetUserByID(user.ID)
+		if err != nil {
+			t.Fatalf("Failed to get updated user: %v", err)
+		}
+		if updatedUser.DisplayName != "Partially Updated" {
+			t.Errorf("DisplayName = %v, want %v", updatedUser.DisplayName, "Partially Updated")
+		}
+		if updatedUser.Email != "changed@example.com" {
+			t.Errorf("Email = %v, want %v", updatedUser.Email, "changed@example.com")
+		}
+
+		if err := database.UpdateUserFields(&stale, "nickname"); err == nil {
+			t.Error("expected error for unknown field, got nil")
+		}
+
+		// The next test cases compare against the stored user
+		user = updatedUser
+	})
+
 	t.Run("ScanStructs", func(t *testing.T) {
 		// Create another user to test multiple rows
 		secondUser := &models.User{
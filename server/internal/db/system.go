@@ -14,12 +14,13 @@ type UserStats struct {
 // GetSystemStats returns system-wide statistics
 func (db *database) GetSystemStats() (*UserStats, error) {
 	stats := &UserStats{}
+	var err error
 
 	// Get total users
 	query := db.NewQuery().
 		Select("COUNT(*)").
 		From("users")
-	err := db.QueryRow(query.String()).Scan(&stats.TotalUsers)
+	stats.TotalUsers, err = db.CountRows(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total users count: %w", err)
 	}
@@ -28,19 +29,18 @@ func (db *database) GetSystemStats() (*UserStats, error) {
 	query = db.NewQuery().
 		Select("COUNT(*)").
 		From("workspaces")
-	err = db.QueryRow(query.String()).Scan(&stats.TotalWorkspaces)
+	stats.TotalWorkspaces, err = db.CountRows(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total workspaces count: %w", err)
 	}
 
 	// Get active users (users with activity in last 30 days)
 	query = db.NewQuery().
-		Select("COUNT(DISTINCT user_id)").
+		SelectCountDistinct("user_id").
 		From("sessions").
 		Where("created_at >").
 		TimeSince(30)
-	err = db.QueryRow(query.String()).
-		Scan(&stats.ActiveUsers)
+	stats.ActiveUsers, err = db.CountRows(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active users count: %w", err)
 	}
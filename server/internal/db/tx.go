@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// maxTxAttempts is how many times a transaction is attempted when it fails with a serialization conflict
+const maxTxAttempts = 3
+
+// txRetryDelay is the base delay between attempts, it grows linearly with each attempt
+var txRetryDelay = 10 * time.Millisecond
+
+// serializableTx is used for multi-step operations that must not interleave with concurrent writes
+var serializableTx = &sql.TxOptions{Isolation: sql.LevelSerializable}
+
+// WithTx runs fn in a transaction started with opts and commits it if fn returns no error.
+// If the transaction fails with a serialization conflict it is rolled back and fn is run again,
+// so fn must not have side effects outside the transaction.
+func (db *database) WithTx(opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	log := getLogger()
+
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = db.runTx(opts, fn)
+		if err == nil || !isSerializationFailure(err) {
+			return err
+		}
+
+		log.Debug("transaction conflict, retrying",
+			"attempt", attempt,
+			"error", err.Error())
+		if attempt < maxTxAttempts {
+			time.Sleep(txRetryDelay * time.Duration(attempt))
+		}
+	}
+
+	return fmt.Errorf("transaction failed after %d attempts: %w", maxTxAttempts, err)
+}
+
+func (db *database) runTx(opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(context.Background(), db.txOptions(opts))
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// txOptions maps the transaction options to the database. SQLite transactions are always
+// serializable and the driver ignores isolation levels, so the options are dropped there.
+func (db *database) txOptions(opts *sql.TxOptions) *sql.TxOptions {
+	if db.dbType == DBTypeSQLite {
+		return nil
+	}
+	return opts
+}
+
+// isSerializationFailure reports whether err is a conflict with a concurrent transaction
+// that may succeed when retried
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// serialization_failure and deadlock_detected
+		return pqErr.Code == "40001" || pqErr.Code == "40P01"
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+
+	return false
+}
@@ -0,0 +1,121 @@
+package db_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"lemma/internal/db"
+	"lemma/internal/models"
+	_ "lemma/internal/testenv"
+
+	"github.com/lib/pq"
+)
+
+func TestWithTx(t *testing.T) {
+	database, err := db.NewTestSQLiteDB(&mockSecrets{})
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer database.Close()
+
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	user, err := database.CreateUser(&models.User{
+		Email:        "tx@example.com",
+		DisplayName:  "Tx User",
+		PasswordHash: "hash",
+		Role:         models.RoleEditor,
+		Theme:        "dark",
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	setDisplayName := func(tx *sql.Tx, name string) error {
+		_, err := tx.Exec("UPDATE users SET display_name = ? WHERE id = ?", name, user.ID)
+		return err
+	}
+
+	displayName := func() string {
+		t.Helper()
+		u, err := database.GetUserByID(user.ID)
+		if err != nil {
+			t.Fatalf("failed to get user: %v", err)
+		}
+		return u.DisplayName
+	}
+
+	serializable := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	conflict := &pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"}
+
+	t.Run("retries serialization conflict", func(t *testing.T) {
+		attempts := 0
+		err := database.WithTx(serializable, func(tx *sql.Tx) error {
+			attempts++
+			if err := setDisplayName(tx, "attempt"); err != nil {
+				return err
+			}
+			if attempts == 1 {
+				return conflict
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("WithTx() unexpected error: %v", err)
+		}
+		if attempts != 2 {
+			t.Errorf("attempts = %d, want 2", attempts)
+		}
+		if got := displayName(); got != "attempt" {
+			t.Errorf("display name = %q, want %q", got, "attempt")
+		}
+	})
+
+	t.Run("gives up after repeated conflicts", func(t *testing.T) {
+		attempts := 0
+		err := database.WithTx(serializable, func(tx *sql.Tx) error {
+			attempts++
+			if err := setDisplayName(tx, "conflicted"); err != nil {
+				return err
+			}
+			return conflict
+		})
+		if !errors.Is(err, conflict) {
+			t.Errorf("WithTx() error = %v, want wrapped conflict", err)
+		}
+		if attempts != 3 {
+			t.Errorf("attempts = %d, want 3", attempts)
+		}
+		if got := displayName(); got != "attempt" {
+			t.Errorf("display name = %q, want changes rolled back", got)
+		}
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		failure := errors.New("constraint violated")
+		attempts := 0
+		err := database.WithTx(nil, func(tx *sql.Tx) error {
+			attempts++
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Errorf("WithTx() error = %v, want %v", err, failure)
+		}
+		if attempts != 1 {
+			t.Errorf("attempts = %d, want 1", attempts)
+		}
+	})
+
+	t.Run("read only options", func(t *testing.T) {
+		err := database.WithTx(&sql.TxOptions{ReadOnly: true}, func(tx *sql.Tx) error {
+			var count int
+			return tx.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+		})
+		if err != nil {
+			t.Errorf("WithTx() unexpected error: %v", err)
+		}
+	})
+}
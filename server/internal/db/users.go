@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"lemma/internal/models"
@@ -11,55 +12,52 @@ func (db *database) CreateUser(user *models.User) (*models.User, error) {
 	log := getLogger().WithGroup("users")
 	log.Debug("creating user", "email", user.Email)
 
-	tx, err := db.Begin()
+	var defaultWorkspace *models.Workspace
+	err := db.WithTx(serializableTx, func(tx *sql.Tx) error {
+		query, err := db.NewQuery().
+			InsertStruct(user, "users")
+
+		if err != nil {
+			return fmt.Errorf("failed to create query: %w", err)
+		}
+
+		query.Returning("id", "created_at")
+
+		err = tx.QueryRow(query.String(), query.Args()...).
+			Scan(&user.ID, &user.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert user: %w", err)
+		}
+
+		// Create default workspace with default settings
+		defaultWorkspace = &models.Workspace{
+			UserID: user.ID,
+			Name:   "Main",
+		}
+		defaultWorkspace.SetDefaultSettings()
+
+		// Create workspace with settings
+		err = db.createWorkspaceTx(tx, defaultWorkspace)
+		if err != nil {
+			return fmt.Errorf("failed to create default workspace: %w", err)
+		}
+
+		// Update user's last workspace ID
+		query = db.NewQuery().
+			Update("users").
+			Set("last_workspace_id").
+			Placeholder(defaultWorkspace.ID).
+			Where("id = ").
+			Placeholder(user.ID)
+		_, err = tx.Exec(query.String(), query.Args()...)
+		if err != nil {
+			return fmt.Errorf("failed to update last workspace ID: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	query, err := db.NewQuery().
-		InsertStruct(user, "users")
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to create query: %w", err)
-	}
-
-	query.Returning("id", "created_at")
-
-	err = tx.QueryRow(query.String(), query.Args()...).
-		Scan(&user.ID, &user.CreatedAt)
-	if err != nil {
-		return nil, fmt.Errorf("failed to insert user: %w", err)
-	}
-
-	// Create default workspace with default settings
-	defaultWorkspace := &models.Workspace{
-		UserID: user.ID,
-		Name:   "Main",
-	}
-	defaultWorkspace.SetDefaultSettings()
-
-	// Create workspace with settings
-	err = db.createWorkspaceTx(tx, defaultWorkspace)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create default workspace: %w", err)
-	}
-
-	// Update user's last workspace ID
-	query = db.NewQuery().
-		Update("users").
-		Set("last_workspace_id").
-		Placeholder(defaultWorkspace.ID).
-		Where("id = ").
-		Placeholder(user.ID)
-	_, err = tx.Exec(query.String(), query.Args()...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to update last workspace ID: %w", err)
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, err
 	}
 
 	log.Debug("created user", "user_id", user.ID)
@@ -94,6 +92,11 @@ func (db *database) createWorkspaceTx(tx *sql.Tx, workspace *models.Workspace) e
 
 // GetUserByID retrieves a user by its ID
 func (db *database) GetUserByID(id int) (*models.User, error) {
+	return db.GetUserByIDContext(context.Background(), id)
+}
+
+// GetUserByIDContext is GetUserByID with the query cancelled when ctx is done
+func (db *database) GetUserByIDContext(ctx context.Context, id int) (*models.User, error) {
 	user := &models.User{}
 	query := db.NewQuery()
 	query, err := query.SelectStruct(user, "users")
@@ -102,7 +105,7 @@ func (db *database) GetUserByID(id int) (*models.User, error) {
 	}
 
 	query = query.Where("id = ").Placeholder(id)
-	row := db.QueryRow(query.String(), query.Args()...)
+	row := db.QueryRowContext(ctx, query)
 	err = db.ScanStruct(row, user)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("user not found")
@@ -136,6 +139,32 @@ func (db *database) GetUserByEmail(email string) (*models.User, error) {
 	return user, nil
 }
 
+// GetUserByDisplayName retrieves a user by its display name, ignoring case.
+// SQLite's LOWER only folds ASCII letters, so on SQLite names differing in the case
+// of other letters, e.g. "Émile" and "émile", do not match.
+func (db *database) GetUserByDisplayName(displayName string) (*models.User, error) {
+	user := &models.User{}
+	query := db.NewQuery()
+	query, err := query.SelectStruct(user, "users")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query: %w", err)
+	}
+
+	query = query.Where("LOWER(display_name) = LOWER(").Placeholder(displayName).Write(")").
+		OrderBy("id").Limit(1)
+	row := db.QueryRow(query.String(), query.Args()...)
+	err = db.ScanStruct(row, user)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	return user, nil
+}
+
 // UpdateUser updates an existing user record in the database
 func (db *database) UpdateUser(user *models.User) error {
 	query := db.NewQuery()
@@ -162,6 +191,32 @@ func (db *database) UpdateUser(user *models.User) error {
 	return nil
 }
 
+// UpdateUserFields updates only the named columns of a user record, e.g. "display_name"
+func (db *database) UpdateUserFields(user *models.User, fields ...string) error {
+	query := db.NewQuery()
+	query, err := query.UpdateStructFields(user, "users", fields...)
+	if err != nil {
+		return fmt.Errorf("failed to create query: %w", err)
+	}
+	query = query.Where("id = ").Placeholder(user.ID)
+
+	result, err := db.Exec(query.String(), query.Args()...)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
 // GetAllUsers retrieves all users from the database
 func (db *database) GetAllUsers() ([]*models.User, error) {
 	query := db.NewQuery()
@@ -186,43 +241,90 @@ func (db *database) GetAllUsers() ([]*models.User, error) {
 	return users, nil
 }
 
-func (db *database) UpdateLastWorkspace(userID int, workspaceName string) error {
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
+// UserFilter selects a page of users, an empty filter matches all users
+type UserFilter struct {
+	Role   models.UserRole // Only users with this role, empty matches any role
+	Search string          // Only users whose email or display name contains this text, ignoring case
+	Limit  int             // Maximum number of users returned, 0 returns all users
+	Offset int             // Number of users skipped, only applied together with a limit
+}
 
-	// Find workspace ID from name
-	workspaceQuery := db.NewQuery().
-		Select("id").
-		From("workspaces").
-		Where("user_id = ").Placeholder(userID).
-		And("name = ").Placeholder(workspaceName)
+// apply adds the conditions of the filter to query
+func (f UserFilter) apply(query *Query) *Query {
+	if f.Role != "" {
+		query = query.Where("role = ").Placeholder(f.Role)
+	}
+	if f.Search != "" {
+		pattern := "%" + EscapeLike(f.Search) + "%"
+		query = query.StartGroup().
+			ILike("email", pattern).
+			Write(" OR ").
+			ILike("display_name", pattern).
+			EndGroup()
+	}
+	return query
+}
 
-	var workspaceID int
-	err = tx.QueryRow(workspaceQuery.String(), workspaceQuery.Args()...).Scan(&workspaceID)
+// ListUsers returns the page of users matching the filter ordered by ID,
+// together with the total number of matching users
+func (db *database) ListUsers(filter UserFilter) ([]*models.User, int, error) {
+	countQuery := filter.apply(db.NewQuery().Select("COUNT(*)").From("users"))
+	total, err := db.CountRows(countQuery)
 	if err != nil {
-		return fmt.Errorf("failed to find workspace: %w", err)
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
 	}
 
-	// Update user's last workspace
-	updateQuery := db.NewQuery().
-		Update("users").
-		Set("last_workspace_id").Placeholder(workspaceID).
-		Where("id = ").Placeholder(userID)
-
-	_, err = tx.Exec(updateQuery.String(), updateQuery.Args()...)
+	query, err := db.NewQuery().SelectStruct(&models.User{}, "users")
 	if err != nil {
-		return fmt.Errorf("failed to update last workspace: %w", err)
+		return nil, 0, fmt.Errorf("failed to create query: %w", err)
+	}
+	query = filter.apply(query).OrderBy("id ASC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit).Offset(filter.Offset)
 	}
 
-	err = tx.Commit()
+	rows, err := db.Query(query.String(), query.Args()...)
 	if err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, 0, fmt.Errorf("failed to query users: %w", err)
 	}
+	defer rows.Close()
 
-	return nil
+	users := []*models.User{}
+	if err := db.ScanStructs(rows, &users); err != nil {
+		return nil, 0, fmt.Errorf("failed to scan users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+func (db *database) UpdateLastWorkspace(userID int, workspaceName string) error {
+	return db.WithTx(serializableTx, func(tx *sql.Tx) error {
+		// Find workspace ID from name
+		workspaceQuery := db.NewQuery().
+			Select("id").
+			From("workspaces").
+			Where("user_id = ").Placeholder(userID).
+			And("name = ").Placeholder(workspaceName)
+
+		var workspaceID int
+		err := tx.QueryRow(workspaceQuery.String(), workspaceQuery.Args()...).Scan(&workspaceID)
+		if err != nil {
+			return fmt.Errorf("failed to find workspace: %w", err)
+		}
+
+		// Update user's last workspace
+		updateQuery := db.NewQuery().
+			Update("users").
+			Set("last_workspace_id").Placeholder(workspaceID).
+			Where("id = ").Placeholder(userID)
+
+		_, err = tx.Exec(updateQuery.String(), updateQuery.Args()...)
+		if err != nil {
+			return fmt.Errorf("failed to update last workspace: %w", err)
+		}
+
+		return nil
+	})
 }
 
 // DeleteUser deletes a user and all their workspaces
@@ -230,39 +332,35 @@ func (db *database) DeleteUser(id int) error {
 	log := getLogger().WithGroup("users")
 	log.Debug("deleting user", "user_id", id)
 
-	tx, err := db.Begin()
+	err := db.WithTx(serializableTx, func(tx *sql.Tx) error {
+		// Delete all user's workspaces first
+		log.Debug("deleting user workspaces", "user_id", id)
+
+		deleteWorkspacesQuery := db.NewQuery().
+			Delete().
+			From("workspaces").
+			Where("user_id = ").Placeholder(id)
+
+		_, err := tx.Exec(deleteWorkspacesQuery.String(), deleteWorkspacesQuery.Args()...)
+		if err != nil {
+			return fmt.Errorf("failed to delete workspaces: %w", err)
+		}
+
+		// Delete the user
+		deleteUserQuery := db.NewQuery().
+			Delete().
+			From("users").
+			Where("id = ").Placeholder(id)
+
+		_, err = tx.Exec(deleteUserQuery.String(), deleteUserQuery.Args()...)
+		if err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Delete all user's workspaces first
-	log.Debug("deleting user workspaces", "user_id", id)
-
-	deleteWorkspacesQuery := db.NewQuery().
-		Delete().
-		From("workspaces").
-		Where("user_id = ").Placeholder(id)
-
-	_, err = tx.Exec(deleteWorkspacesQuery.String(), deleteWorkspacesQuery.Args()...)
-	if err != nil {
-		return fmt.Errorf("failed to delete workspaces: %w", err)
-	}
-
-	// Delete the user
-	deleteUserQuery := db.NewQuery().
-		Delete().
-		From("users").
-		Where("id = ").Placeholder(id)
-
-	_, err = tx.Exec(deleteUserQuery.String(), deleteUserQuery.Args()...)
-	if err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return err
 	}
 
 	log.Debug("deleted user", "user_id", id)
@@ -290,6 +388,78 @@ func (db *database) GetLastWorkspaceName(userID int) (string, error) {
 	return workspaceName, nil
 }
 
+// GetDefaultWorkspace returns the workspace a user should open, which is the last workspace if it still exists.
+// Otherwise the user's oldest workspace is returned and stored as the new last workspace.
+func (db *database) GetDefaultWorkspace(userID int) (*models.Workspace, error) {
+	log := getLogger().WithGroup("users")
+	workspace := &models.Workspace{}
+
+	err := db.WithTx(serializableTx, func(tx *sql.Tx) error {
+		userQuery := db.NewQuery().
+			Select("last_workspace_id").
+			From("users").
+			Where("id = ").Placeholder(userID)
+
+		var lastWorkspaceID sql.NullInt64
+		err := tx.QueryRow(userQuery.String(), userQuery.Args()...).Scan(&lastWorkspaceID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("user not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch last workspace id: %w", err)
+		}
+
+		if lastWorkspaceID.Valid {
+			lastQuery, err := db.NewQuery().SelectStruct(workspace, "workspaces")
+			if err != nil {
+				return fmt.Errorf("failed to create query: %w", err)
+			}
+			lastQuery = lastQuery.
+				Where("id = ").Placeholder(lastWorkspaceID.Int64).
+				And("user_id = ").Placeholder(userID)
+
+			err = db.ScanStruct(tx.QueryRow(lastQuery.String(), lastQuery.Args()...), workspace)
+			if err == nil {
+				return nil
+			}
+			if err != sql.ErrNoRows {
+				return fmt.Errorf("failed to fetch last workspace: %w", err)
+			}
+		}
+
+		// The last workspace is unset or was deleted, fall back to the oldest one
+		oldestQuery, err := db.NewQuery().SelectStruct(workspace, "workspaces")
+		if err != nil {
+			return fmt.Errorf("failed to create query: %w", err)
+		}
+		oldestQuery = oldestQuery.
+			Where("user_id = ").Placeholder(userID).
+			OrderBy("created_at ASC", "id ASC").
+			Limit(1)
+
+		err = db.ScanStruct(tx.QueryRow(oldestQuery.String(), oldestQuery.Args()...), workspace)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("no workspaces found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch oldest workspace: %w", err)
+		}
+
+		log.Debug("last workspace missing, falling back to oldest workspace",
+			"user_id", userID,
+			"workspace_id", workspace.ID)
+		return db.UpdateLastWorkspaceTx(tx, userID, workspace.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows created before settings existed may have empty values
+	workspace.SetDefaultSettings()
+
+	return workspace, nil
+}
+
 // CountAdminUsers returns the number of admin users in the system
 func (db *database) CountAdminUsers() (int, error) {
 	query := db.NewQuery().
@@ -297,11 +467,54 @@ func (db *database) CountAdminUsers() (int, error) {
 		From("users").
 		Where("role = ").Placeholder(models.RoleAdmin)
 
-	var count int
-	err := db.QueryRow(query.String(), query.Args()...).Scan(&count)
+	count, err := db.CountRows(query)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count admin users: %w", err)
 	}
 
 	return count, nil
 }
+
+// GetUserPreferences retrieves the UI preferences JSON of a user.
+// Returns an empty string if the user has no preferences stored.
+func (db *database) GetUserPreferences(userID int) (string, error) {
+	query := db.NewQuery().
+		Select("preferences").
+		From("users").
+		Where("id = ").Placeholder(userID)
+
+	var preferences sql.NullString
+	err := db.QueryRow(query.String(), query.Args()...).Scan(&preferences)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch user preferences: %w", err)
+	}
+
+	return preferences.String, nil
+}
+
+// UpdateUserPreferences replaces the UI preferences JSON of a user
+func (db *database) UpdateUserPreferences(userID int, preferences string) error {
+	query := db.NewQuery().
+		Update("users").
+		Set("preferences").Placeholder(preferences).
+		Where("id = ").Placeholder(userID)
+
+	result, err := db.Exec(query.String(), query.Args()...)
+	if err != nil {
+		return fmt.Errorf("failed to update user preferences: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
@@ -207,6 +207,62 @@ func TestUserOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("GetUserByDisplayName", func(t *testing.T) {
+		createdUser, err := database.CreateUser(&models.User{
+			Email:        "getbyname@example.com",
+			DisplayName:  "Get By Name User",
+			PasswordHash: "hash",
+			Role:         models.RoleEditor,
+			Theme:        "dark",
+		})
+		if err != nil {
+			t.Fatalf("failed to create test user: %v", err)
+		}
+
+		testCases := []struct {
+			name        string
+			displayName string
+			wantErr     bool
+		}{
+			{
+				name:        "existing user",
+				displayName: "Get By Name User",
+				wantErr:     false,
+			},
+			{
+				name:        "different case",
+				displayName: "get by name user",
+				wantErr:     false,
+			},
+			{
+				name:        "non-existent user",
+				displayName: "Nobody",
+				wantErr:     true,
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				user, err := database.GetUserByDisplayName(tc.displayName)
+
+				if tc.wantErr {
+					if err == nil {
+						t.Error("expected error, got nil")
+					}
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if user.ID != createdUser.ID {
+					t.Errorf("ID = %v, want %v", user.ID, createdUser.ID)
+				}
+			})
+		}
+	})
+
 	t.Run("UpdateUser", func(t *testing.T) {
 		// Create a test user first
 		user, err := database.CreateUser(&models.User{
@@ -305,6 +361,81 @@ func TestUserOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("ListUsers", func(t *testing.T) {
+		for _, u := range []*models.User{
+			{Email: "list.one@listing.test", DisplayName: "First Listed", PasswordHash: "hash", Role: models.RoleEditor, Theme: "dark"},
+			{Email: "list.two@listing.test", DisplayName: "Second Listed", PasswordHash: "hash", Role: models.RoleViewer, Theme: "dark"},
+			{Email: "third@listing.test", DisplayName: "List_Three", PasswordHash: "hash", Role: models.RoleViewer, Theme: "dark"},
+		} {
+			if _, err := database.CreateUser(u); err != nil {
+				t.Fatalf("failed to create test user: %v", err)
+			}
+		}
+
+		testCases := []struct {
+			name       string
+			filter     db.UserFilter
+			wantEmails []string
+			wantTotal  int
+		}{
+			{
+				name:       "search email and display name",
+				filter:     db.UserFilter{Search: "LIST"},
+				wantEmails: []string{"list.one@listing.test", "list.two@listing.test", "third@listing.test"},
+				wantTotal:  3,
+			},
+			{
+				name:       "search escapes wildcards",
+				filter:     db.UserFilter{Search: "list_"},
+				wantEmails: []string{"third@listing.test"},
+				wantTotal:  1,
+			},
+			{
+				name:       "role",
+				filter:     db.UserFilter{Search: "list", Role: models.RoleViewer},
+				wantEmails: []string{"list.two@listing.test", "third@listing.test"},
+				wantTotal:  2,
+			},
+			{
+				name:       "first page",
+				filter:     db.UserFilter{Search: "list", Limit: 2},
+				wantEmails: []string{"list.one@listing.test", "list.two@listing.test"},
+				wantTotal:  3,
+			},
+			{
+				name:       "last page",
+				filter:     db.UserFilter{Search: "list", Limit: 2, Offset: 2},
+				wantEmails: []string{"third@listing.test"},
+				wantTotal:  3,
+			},
+			{
+				name:       "offset past the end",
+				filter:     db.UserFilter{Search: "list", Limit: 2, Offset: 3},
+				wantEmails: []string{},
+				wantTotal:  3,
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				users, total, err := database.ListUsers(tc.filter)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if total != tc.wantTotal {
+					t.Errorf("total = %d, want %d", total, tc.wantTotal)
+				}
+				emails := make([]string, len(users))
+				for i, u := range users {
+					emails[i] = u.Email
+				}
+				if strings.Join(emails, ",") != strings.Join(tc.wantEmails, ",") {
+					t.Errorf("users = %v, want %v", emails, tc.wantEmails)
+				}
+			})
+		}
+	})
+
 	t.Run("UpdateLastWorkspace", func(t *testing.T) {
 		// Create a test user with multiple workspaces
 		user, err := database.CreateUser(&models.User{
@@ -344,6 +475,119 @@ func TestUserOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("GetDefaultWorkspace", func(t *testing.T) {
+		user, err := database.CreateUser(&models.User{
+			Email:        "default-workspace@example.com",
+			DisplayName:  "Default Workspace User",
+			PasswordHash: "hash",
+			Role:         models.RoleEditor,
+			Theme:        "dark",
+		})
+		if err != nil {
+			t.Fatalf("failed to create test user: %v", err)
+		}
+
+		workspace := &models.Workspace{
+			UserID: user.ID,
+			Name:   "Deleted Workspace",
+		}
+		if err := database.CreateWorkspace(workspace); err != nil {
+			t.Fatalf("failed to create additional workspace: %v", err)
+		}
+		if err := database.UpdateLastWorkspace(user.ID, workspace.Name); err != nil {
+			t.Fatalf("failed to update last workspace: %v", err)
+		}
+
+		// The existing last workspace is returned as is
+		got, err := database.GetDefaultWorkspace(user.ID)
+		if err != nil {
+			t.Fatalf("failed to get default workspace: %v", err)
+		}
+		if got.ID != workspace.ID {
+			t.Errorf("GetDefaultWorkspace() ID = %v, want %v", got.ID, workspace.ID)
+		}
+
+		// Deleting the last workspace leaves a stale reference
+		if err := database.DeleteWorkspace(workspace.ID); err != nil {
+			t.Fatalf("failed to delete workspace: %v", err)
+		}
+
+		got, err = database.GetDefaultWorkspace(user.ID)
+		if err != nil {
+			t.Fatalf("failed to get default workspace: %v", err)
+		}
+		if got.ID != user.LastWorkspaceID {
+			t.Errorf("GetDefaultWorkspace() ID = %v, want the initial workspace %v", got.ID, user.LastWorkspaceID)
+		}
+
+		lastWorkspace, err := database.GetLastWorkspaceName(user.ID)
+		if err != nil {
+			t.Fatalf("failed to get last workspace: %v", err)
+		}
+		if lastWorkspace != got.Name {
+			t.Errorf("LastWorkspace = %v, want %v", lastWorkspace, got.Name)
+		}
+
+		// Without any workspace there is nothing to fall back to
+		if err := database.DeleteWorkspace(got.ID); err != nil {
+			t.Fatalf("failed to delete workspace: %v", err)
+		}
+		if _, err := database.GetDefaultWorkspace(user.ID); err == nil {
+			t.Error("expected error when the user has no workspaces, got nil")
+		}
+	})
+
+	t.Run("UserPreferences", func(t *testing.T) {
+		user, err := database.CreateUser(&models.User{
+			Email:        "preferences@example.com",
+			DisplayName:  "Preferences User",
+			PasswordHash: "hash",
+			Role:         models.RoleEditor,
+			Theme:        "dark",
+		})
+		if err != nil {
+			t.Fatalf("failed to create test user: %v", err)
+		}
+
+		// No preferences stored yet
+		preferences, err := database.GetUserPreferences(user.ID)
+		if err != nil {
+			t.Fatalf("failed to get preferences: %v", err)
+		}
+		if preferences != "" {
+			t.Errorf("preferences = %q, want empty", preferences)
+		}
+
+		want := `{"sidebarWidth":250,"editor":{"fontSize":14}}`
+		if err := database.UpdateUserPreferences(user.ID, want); err != nil {
+			t.Fatalf("failed to update preferences: %v", err)
+		}
+
+		preferences, err = database.GetUserPreferences(user.ID)
+		if err != nil {
+			t.Fatalf("failed to get preferences: %v", err)
+		}
+		if preferences != want {
+			t.Errorf("preferences = %q, want %q", preferences, want)
+		}
+
+		// Typed fields are unaffected
+		fetched, err := database.GetUserByID(user.ID)
+		if err != nil {
+			t.Fatalf("failed to get user: %v", err)
+		}
+		if fetched.Theme != "dark" {
+			t.Errorf("Theme = %v, want dark", fetched.Theme)
+		}
+
+		if _, err := database.GetUserPreferences(99999); err == nil {
+			t.Error("expected error for non-existent user, got nil")
+		}
+		if err := database.UpdateUserPreferences(99999, want); err == nil {
+			t.Error("expected error for non-existent user, got nil")
+		}
+	})
+
 	t.Run("DeleteUser", func(t *testing.T) {
 		// Create a test user
 		user, err := database.CreateUser(&models.User{
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"lemma/internal/models"
@@ -18,6 +19,10 @@ func (db *database) CreateWorkspace(workspace *models.Workspace) error {
 	if workspace.Theme == "" {
 		workspace.SetDefaultSettings()
 	}
+	// The normalized name is unique per user, so it is never left empty
+	if workspace.NormalizedName == "" {
+		workspace.NormalizedName = workspace.Name
+	}
 
 	query, err := db.NewQuery().
 		InsertStruct(workspace, "workspaces")
@@ -28,13 +33,18 @@ func (db *database) CreateWorkspace(workspace *models.Workspace) error {
 
 	query.Returning("id", "created_at")
 
-	err = db.QueryRow(query.String(), query.Args()...).
-		Scan(&workspace.ID, &workspace.CreatedAt)
-	if err != nil {
-		return fmt.Errorf("failed to insert workspace: %w", err)
-	}
-
-	return nil
+	return db.WithTx(serializableTx, func(tx *sql.Tx) error {
+		err := tx.QueryRow(query.String(), query.Args()...).
+			Scan(&workspace.ID, &workspace.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert workspace: %w", err)
+		}
+
+		if len(workspace.GitRemotes) > 0 {
+			return db.replaceGitRemotesTx(tx, workspace.ID, workspace.GitRemotes)
+		}
+		return nil
+	})
 }
 
 // GetWorkspaceByID retrieves a workspace by its ID
@@ -57,11 +67,23 @@ func (db *database) GetWorkspaceByID(id int) (*models.Workspace, error) {
 		return nil, fmt.Errorf("failed to fetch workspace: %w", err)
 	}
 
+	// Rows created before settings existed may have empty values
+	workspace.SetDefaultSettings()
+
+	if workspace.GitRemotes, err = db.GetWorkspaceGitRemotes(workspace.ID); err != nil {
+		return nil, err
+	}
+
 	return workspace, nil
 }
 
 // GetWorkspaceByName retrieves a workspace by its name and user ID
 func (db *database) GetWorkspaceByName(userID int, workspaceName string) (*models.Workspace, error) {
+	return db.GetWorkspaceByNameContext(context.Background(), userID, workspaceName)
+}
+
+// GetWorkspaceByNameContext is GetWorkspaceByName with the query cancelled when ctx is done
+func (db *database) GetWorkspaceByNameContext(ctx context.Context, userID int, workspaceName string) (*models.Workspace, error) {
 	workspace := &models.Workspace{}
 	query := db.NewQuery()
 	query, err := query.SelectStruct(workspace, "workspaces")
@@ -71,6 +93,43 @@ func (db *database) GetWorkspaceByName(userID int, workspaceName string) (*model
 	query = query.Where("user_id = ").Placeholder(userID).
 		And("name = ").Placeholder(workspaceName)
 
+	row := db.QueryRowContext(ctx, query)
+	err = db.ScanStruct(row, workspace)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspace: %w", err)
+	}
+
+	// Rows created before settings existed may have empty values
+	workspace.SetDefaultSettings()
+
+	if workspace.GitRemotes, err = db.GetWorkspaceGitRemotes(workspace.ID); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// GetWorkspaceByNormalizedName retrieves a workspace of the user by its normalized name.
+// With ignoreCase the names are compared ignoring case, which also matches rows whose
+// normalized name was stored before names were lowercased.
+func (db *database) GetWorkspaceByNormalizedName(userID int, normalizedName string, ignoreCase bool) (*models.Workspace, error) {
+	workspace := &models.Workspace{}
+	query, err := db.NewQuery().SelectStruct(workspace, "workspaces")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query: %w", err)
+	}
+	query = query.Where("user_id = ").Placeholder(userID)
+	if ignoreCase {
+		query = query.And("LOWER(normalized_name) = LOWER(").Placeholder(normalizedName).Write(")")
+	} else {
+		query = query.And("normalized_name = ").Placeholder(normalizedName)
+	}
+	query = query.OrderBy("id").Limit(1)
+
 	row := db.QueryRow(query.String(), query.Args()...)
 	err = db.ScanStruct(row, workspace)
 
@@ -81,11 +140,16 @@ func (db *database) GetWorkspaceByName(userID int, workspaceName string) (*model
 		return nil, fmt.Errorf("failed to fetch workspace: %w", err)
 	}
 
+	workspace.SetDefaultSettings()
 	return workspace, nil
 }
 
-// UpdateWorkspace updates a workspace record in the database
+// UpdateWorkspace updates a workspace record in the database.
+// The additional git remotes are replaced unless GitRemotes is nil.
 func (db *database) UpdateWorkspace(workspace *models.Workspace) error {
+	if workspace.NormalizedName == "" {
+		workspace.NormalizedName = workspace.Name
+	}
 
 	query := db.NewQuery()
 	query, err := query.
@@ -96,12 +160,16 @@ func (db *database) UpdateWorkspace(workspace *models.Workspace) error {
 		return fmt.Errorf("failed to create query: %w", err)
 	}
 
-	_, err = db.Exec(query.String(), query.Args()...)
-	if err != nil {
-		return fmt.Errorf("failed to update workspace: %w", err)
-	}
+	return db.WithTx(serializableTx, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(query.String(), query.Args()...); err != nil {
+			return fmt.Errorf("failed to update workspace: %w", err)
+		}
 
-	return nil
+		if workspace.GitRemotes != nil {
+			return db.replaceGitRemotesTx(tx, workspace.ID, workspace.GitRemotes)
+		}
+		return nil
+	})
 }
 
 // GetWorkspacesByUserID retrieves all workspaces for a user
@@ -126,6 +194,10 @@ func (db *database) GetWorkspacesByUserID(userID int) ([]*models.Workspace, erro
 		return nil, fmt.Errorf("failed to scan workspaces: %w", err)
 	}
 
+	for _, workspace := range workspaces {
+		workspace.SetDefaultSettings()
+	}
+
 	return workspaces, nil
 }
 
@@ -210,6 +282,104 @@ func (db *database) UpdateLastWorkspaceTx(tx *sql.Tx, userID, workspaceID int) e
 	return nil
 }
 
+// TransferWorkspaceTx writes the owner and the git credentials of a workspace in a transaction,
+// the other settings are left unchanged. The additional git remotes are replaced unless GitRemotes is nil.
+func (db *database) TransferWorkspaceTx(tx *sql.Tx, workspace *models.Workspace) error {
+	query, err := db.NewQuery().
+		UpdateStructFields(workspace, "workspaces", "user_id", "git_enabled", "git_user", "git_token", "git_signing_key")
+	if err != nil {
+		return fmt.Errorf("failed to create query: %w", err)
+	}
+	query = query.Where("id = ").Placeholder(workspace.ID)
+
+	result, err := tx.Exec(query.String(), query.Args()...)
+	if err != nil {
+		return fmt.Errorf("failed to transfer workspace in transaction: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected in transaction: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("workspace not found")
+	}
+
+	if workspace.GitRemotes == nil {
+		return nil
+	}
+	return db.replaceGitRemotesTx(tx, workspace.ID, workspace.GitRemotes)
+}
+
+// WorkspaceFilter selects the workspaces of a batch operation, an empty filter matches all workspaces
+type WorkspaceFilter struct {
+	UserID       int   // Only workspaces of this user, 0 matches any user
+	WorkspaceIDs []int // Only workspaces with these IDs, empty matches any workspace
+}
+
+// BatchUpdateWorkspaceSettings applies a settings patch to all workspaces matching the filter in a transaction.
+// The returned workspaces only have their ID, owner and the patched settings set.
+func (db *database) BatchUpdateWorkspaceSettings(filter WorkspaceFilter, patch *models.WorkspaceSettingsPatch) ([]*models.Workspace, error) {
+	var updated []*models.Workspace
+	err := db.WithTx(serializableTx, func(tx *sql.Tx) error {
+		updated = nil
+
+		query := db.NewQuery().
+			Select("id", "user_id", "git_enabled", "git_pinned_ref").
+			From("workspaces")
+		if filter.UserID != 0 {
+			query = query.Where("user_id = ").Placeholder(filter.UserID)
+		}
+		if len(filter.WorkspaceIDs) > 0 {
+			args := make([]any, len(filter.WorkspaceIDs))
+			for i, id := range filter.WorkspaceIDs {
+				args[i] = id
+			}
+			query = query.WhereIn("id", len(args)).AddArgs(args...)
+		}
+		query = query.OrderBy("id")
+
+		rows, err := tx.Query(query.String(), query.Args()...)
+		if err != nil {
+			return fmt.Errorf("failed to query workspaces: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			workspace := &models.Workspace{}
+			if err := rows.Scan(&workspace.ID, &workspace.UserID, &workspace.GitEnabled, &workspace.GitPinnedRef); err != nil {
+				return fmt.Errorf("failed to scan workspace: %w", err)
+			}
+			updated = append(updated, workspace)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to iterate workspaces: %w", err)
+		}
+		rows.Close()
+
+		for _, workspace := range updated {
+			columns := patch.Apply(workspace)
+			query, err := db.NewQuery().
+				UpdateStructFields(workspace, "workspaces", columns...)
+			if err != nil {
+				return fmt.Errorf("failed to create query: %w", err)
+			}
+			query = query.Where("id = ").Placeholder(workspace.ID)
+
+			if _, err := tx.Exec(query.String(), query.Args()...); err != nil {
+				return fmt.Errorf("failed to update workspace settings: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
 // UpdateLastOpenedFile updates the last opened file path for a workspace
 func (db *database) UpdateLastOpenedFile(workspaceID int, filePath string) error {
 	query := db.NewQuery().
@@ -225,6 +395,21 @@ func (db *database) UpdateLastOpenedFile(workspaceID int, filePath string) error
 	return nil
 }
 
+// UpdateHomeFile updates the home file path for a workspace
+func (db *database) UpdateHomeFile(workspaceID int, filePath string) error {
+	query := db.NewQuery().
+		Update("workspaces").
+		Set("home_file").Placeholder(filePath).
+		Where("id = ").Placeholder(workspaceID)
+
+	_, err := db.Exec(query.String(), query.Args()...)
+	if err != nil {
+		return fmt.Errorf("failed to update home file: %w", err)
+	}
+
+	return nil
+}
+
 // GetLastOpenedFile retrieves the last opened file path for a workspace
 func (db *database) GetLastOpenedFile(workspaceID int) (string, error) {
 	query := db.NewQuery().
@@ -251,13 +436,18 @@ func (db *database) GetLastOpenedFile(workspaceID int) (string, error) {
 
 // GetAllWorkspaces retrieves all workspaces in the database
 func (db *database) GetAllWorkspaces() ([]*models.Workspace, error) {
+	return db.GetAllWorkspacesContext(context.Background())
+}
+
+// GetAllWorkspacesContext is GetAllWorkspaces with the query cancelled when ctx is done
+func (db *database) GetAllWorkspacesContext(ctx context.Context) ([]*models.Workspace, error) {
 	query := db.NewQuery()
 	query, err := query.SelectStruct(&models.Workspace{}, "workspaces")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create query: %w", err)
 	}
 
-	rows, err := db.Query(query.String(), query.Args()...)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query workspaces: %w", err)
 	}
@@ -269,5 +459,9 @@ func (db *database) GetAllWorkspaces() ([]*models.Workspace, error) {
 		return nil, fmt.Errorf("failed to scan workspaces: %w", err)
 	}
 
+	for _, workspace := range workspaces {
+		workspace.SetDefaultSettings()
+	}
+
 	return workspaces, nil
 }
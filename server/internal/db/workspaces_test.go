@@ -56,6 +56,15 @@ func TestWorkspaceOperations(t *testing.T) {
 				wantErr:     true,
 				errContains: "FOREIGN KEY constraint failed",
 			},
+			{
+				name: "duplicate name",
+				workspace: &models.Workspace{
+					UserID: user.ID,
+					Name:   "Test Workspace",
+				},
+				wantErr:     true,
+				errContains: "UNIQUE constraint failed",
+			},
 			{
 				name: "with git settings",
 				workspace: &models.Workspace{
@@ -164,6 +173,35 @@ func TestWorkspaceOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("GetWorkspaceWithMissingSettings", func(t *testing.T) {
+		// Simulate a legacy row with settings that were never populated
+		var id int
+		err := database.TestDB().QueryRow(`
+			INSERT INTO workspaces (user_id, name, normalized_name, git_auto_commit, git_commit_msg_template)
+			VALUES (?, ?, ?, 1, NULL)
+			RETURNING id`,
+			user.ID, "Legacy Workspace", "Legacy Workspace",
+		).Scan(&id)
+		if err != nil {
+			t.Fatalf("failed to insert legacy workspace: %v", err)
+		}
+
+		result, err := database.GetWorkspaceByID(id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.GitCommitMsgTemplate != "${action} ${filename}" {
+			t.Errorf("GitCommitMsgTemplate = %q, want %q", result.GitCommitMsgTemplate, "${action} ${filename}")
+		}
+		if result.Theme != "dark" {
+			t.Errorf("Theme = %q, want %q", result.Theme, "dark")
+		}
+		if result.GitAutoCommit {
+			t.Error("GitAutoCommit should be false when git is disabled")
+		}
+	})
+
 	t.Run("GetWorkspaceByName", func(t *testing.T) {
 		// Create a test workspace first
 		workspace := &models.Workspace{
@@ -368,6 +406,29 @@ func TestWorkspaceOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("UpdateHomeFile", func(t *testing.T) {
+		workspace := &models.Workspace{
+			UserID: user.ID,
+			Name:   "Home File Workspace",
+		}
+		workspace.SetDefaultSettings()
+		if err := database.CreateWorkspace(workspace); err != nil {
+			t.Fatalf("failed to create test workspace: %v", err)
+		}
+
+		if err := database.UpdateHomeFile(workspace.ID, "docs/home.md"); err != nil {
+			t.Fatalf("failed to update home file: %v", err)
+		}
+
+		fetched, err := database.GetWorkspaceByID(workspace.ID)
+		if err != nil {
+			t.Fatalf("failed to get workspace: %v", err)
+		}
+		if fetched.HomeFile != "docs/home.md" {
+			t.Errorf("HomeFile = %v, want %v", fetched.HomeFile, "docs/home.md")
+		}
+	})
+
 	t.Run("DeleteWorkspace", func(t *testing.T) {
 		// Create a test workspace
 		workspace := &models.Workspace{
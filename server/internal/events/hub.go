@@ -0,0 +1,185 @@
+// Package events provides a hub publishing workspace changes to event stream subscribers
+package events
+
+import (
+	"errors"
+	"sync"
+)
+
+// subscriptionBuffer is the number of events queued for a subscriber, further events are dropped
+const subscriptionBuffer = 16
+
+// LimitPolicy decides what happens when a subscription limit is reached
+type LimitPolicy string
+
+// Limit policies
+const (
+	// PolicyReject rejects new subscriptions with ErrTooManySubscriptions
+	PolicyReject LimitPolicy = "reject"
+	// PolicyCloseOldest closes the oldest subscriptions to make room for the new one
+	PolicyCloseOldest LimitPolicy = "close-oldest"
+)
+
+// ErrTooManySubscriptions is returned when a subscription limit is reached and the policy rejects new ones
+var ErrTooManySubscriptions = errors.New("too many event stream subscriptions")
+
+// Event is a change published to the subscribers of a workspace
+type Event struct {
+	Type string
+	Data any
+}
+
+// Options configures the limits of a Hub
+type Options struct {
+	// MaxPerUser limits the concurrent subscriptions of a user, 0 disables the limit
+	MaxPerUser int
+	// MaxPerWorkspace limits the concurrent subscriptions to a workspace, 0 disables the limit
+	MaxPerWorkspace int
+	// Policy decides whether new subscriptions are rejected or the oldest ones closed, defaults to PolicyReject
+	Policy LimitPolicy
+}
+
+// Hub tracks the active subscriptions and publishes events to them
+type Hub struct {
+	options Options
+
+	mu   sync.Mutex
+	subs []*Subscription // oldest first
+}
+
+// Subscription receives the events of a workspace until it is closed
+type Subscription struct {
+	UserID      int
+	WorkspaceID int
+
+	hub    *Hub
+	events chan Event
+	done   chan struct{}
+}
+
+// NewHub creates a hub enforcing the given limits
+func NewHub(options Options) *Hub {
+	return &Hub{options: options}
+}
+
+// Subscribe registers a subscription of userID to the events of workspaceID.
+// When a limit is reached ErrTooManySubscriptions is returned, or with PolicyCloseOldest
+// the oldest subscriptions of the user or workspace are closed.
+func (h *Hub) Subscribe(userID, workspaceID int) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	byUser := func(s *Subscription) bool { return s.UserID == userID }
+	byWorkspace := func(s *Subscription) bool { return s.WorkspaceID == workspaceID }
+
+	if h.options.Policy != PolicyCloseOldest {
+		if h.full(h.options.MaxPerUser, byUser) || h.full(h.options.MaxPerWorkspace, byWorkspace) {
+			return nil, ErrTooManySubscriptions
+		}
+	} else {
+		h.closeOldest(h.options.MaxPerUser, byUser)
+		h.closeOldest(h.options.MaxPerWorkspace, byWorkspace)
+	}
+
+	sub := &Subscription{
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		hub:         h,
+		events:      make(chan Event, subscriptionBuffer),
+		done:        make(chan struct{}),
+	}
+	h.subs = append(h.subs, sub)
+	return sub, nil
+}
+
+// Publish sends event to all subscribers of workspaceID without blocking,
+// subscribers that fall behind miss the event.
+func (h *Hub) Publish(workspaceID int, event Event) {
+	h.publish(workspaceID, event, nil)
+}
+
+// publish sends event to the subscribers of workspaceID except skip
+func (h *Hub) publish(workspaceID int, event Event, skip *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, sub := range h.subs {
+		if sub.WorkspaceID != workspaceID || sub == skip {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+// Count returns the number of active subscriptions
+func (h *Hub) Count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// full reports whether the subscriptions matching match reached max
+func (h *Hub) full(max int, match func(*Subscription) bool) bool {
+	return max > 0 && h.count(match) >= max
+}
+
+// closeOldest closes the oldest subscriptions matching match until there is room for one more
+func (h *Hub) closeOldest(max int, match func(*Subscription) bool) {
+	if max <= 0 {
+		return
+	}
+	for excess := h.count(match) - max + 1; excess > 0; excess-- {
+		for _, sub := range h.subs {
+			if match(sub) {
+				h.remove(sub)
+				break
+			}
+		}
+	}
+}
+
+func (h *Hub) count(match func(*Subscription) bool) int {
+	n := 0
+	for _, sub := range h.subs {
+		if match(sub) {
+			n++
+		}
+	}
+	return n
+}
+
+// remove unregisters sub and signals its Done channel, it must be called with h.mu held
+func (h *Hub) remove(sub *Subscription) {
+	for i, s := range h.subs {
+		if s == sub {
+			h.subs = append(h.subs[:i], h.subs[i+1:]...)
+			close(sub.done)
+			return
+		}
+	}
+}
+
+// Events returns the channel delivering the published events
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Done returns a channel that is closed when the subscription is closed, including by the hub
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Broadcast publishes event to the other subscribers of the workspace, e.g. the presence of a client
+func (s *Subscription) Broadcast(event Event) {
+	s.hub.publish(s.WorkspaceID, event, s)
+}
+
+// Close unregisters the subscription, it is safe to call more than once
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
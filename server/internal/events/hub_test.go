@@ -0,0 +1,166 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	"lemma/internal/events"
+)
+
+// isClosed reports whether the subscription was closed
+func isClosed(sub *events.Subscription) bool {
+	select {
+	case <-sub.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestSubscribeLimits(t *testing.T) {
+	t.Run("reject per user", func(t *testing.T) {
+		hub := events.NewHub(events.Options{MaxPerUser: 2, Policy: events.PolicyReject})
+
+		first, err := hub.Subscribe(1, 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := hub.Subscribe(1, 2); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := hub.Subscribe(1, 3); !errors.Is(err, events.ErrTooManySubscriptions) {
+			t.Errorf("expected ErrTooManySubscriptions, got %v", err)
+		}
+		if isClosed(first) {
+			t.Error("expected the existing subscription to stay open")
+		}
+
+		// Other users are not affected
+		if _, err := hub.Subscribe(2, 1); err != nil {
+			t.Errorf("unexpected error for another user: %v", err)
+		}
+
+		// Closing a subscription makes room for a new one
+		first.Close()
+		first.Close()
+		if _, err := hub.Subscribe(1, 3); err != nil {
+			t.Errorf("unexpected error after closing a subscription: %v", err)
+		}
+		if got := hub.Count(); got != 3 {
+			t.Errorf("Count() = %d, want 3", got)
+		}
+	})
+
+	t.Run("reject per workspace", func(t *testing.T) {
+		hub := events.NewHub(events.Options{MaxPerWorkspace: 1})
+
+		if _, err := hub.Subscribe(1, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := hub.Subscribe(2, 1); !errors.Is(err, events.ErrTooManySubscriptions) {
+			t.Errorf("expected ErrTooManySubscriptions, got %v", err)
+		}
+		if _, err := hub.Subscribe(1, 2); err != nil {
+			t.Errorf("unexpected error for another workspace: %v", err)
+		}
+	})
+
+	t.Run("close oldest", func(t *testing.T) {
+		hub := events.NewHub(events.Options{MaxPerUser: 2, Policy: events.PolicyCloseOldest})
+
+		subs := make([]*events.Subscription, 0, 4)
+		for i := 0; i < 4; i++ {
+			sub, err := hub.Subscribe(1, 1)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			subs = append(subs, sub)
+		}
+
+		for i, want := range []bool{true, true, false, false} {
+			if got := isClosed(subs[i]); got != want {
+				t.Errorf("subscription %d closed = %v, want %v", i, got, want)
+			}
+		}
+		if got := hub.Count(); got != 2 {
+			t.Errorf("Count() = %d, want 2", got)
+		}
+
+		// Closing an evicted subscription again is a no-op
+		subs[0].Close()
+	})
+
+	t.Run("no limits", func(t *testing.T) {
+		hub := events.NewHub(events.Options{})
+		for i := 0; i < 100; i++ {
+			if _, err := hub.Subscribe(1, 1); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	})
+}
+
+func TestPublish(t *testing.T) {
+	hub := events.NewHub(events.Options{})
+
+	sub, err := hub.Subscribe(1, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other, err := hub.Subscribe(2, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hub.Publish(1, events.Event{Type: "file_saved", Data: "notes.md"})
+
+	select {
+	case event := <-sub.Events():
+		if event.Type != "file_saved" || event.Data != "notes.md" {
+			t.Errorf("unexpected event %+v", event)
+		}
+	default:
+		t.Error("expected an event for the workspace subscriber")
+	}
+
+	select {
+	case event := <-other.Events():
+		t.Errorf("unexpected event for another workspace: %+v", event)
+	default:
+	}
+
+	// A subscriber that does not read does not block publishing
+	for i := 0; i < 100; i++ {
+		hub.Publish(1, events.Event{Type: "file_saved"})
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	hub := events.NewHub(events.Options{})
+
+	sender, err := hub.Subscribe(1, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	receiver, err := hub.Subscribe(2, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sender.Broadcast(events.Event{Type: "presence", Data: "notes.md"})
+
+	select {
+	case event := <-receiver.Events():
+		if event.Type != "presence" || event.Data != "notes.md" {
+			t.Errorf("unexpected event %+v", event)
+		}
+	default:
+		t.Error("expected an event for the other subscriber")
+	}
+
+	select {
+	case event := <-sender.Events():
+		t.Errorf("unexpected event for the sender: %+v", event)
+	default:
+	}
+}
@@ -0,0 +1,84 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+)
+
+// ErrEmptyRepository is returned when a bundle is requested for a repository without commits
+var ErrEmptyRepository = errors.New("repository has no commits")
+
+// bundleHeader is the signature of the v2 git bundle format
+const bundleHeader = "# v2 git bundle\n"
+
+// CreateBundle writes a git bundle of HEAD and all references of the repository to w.
+// The bundle holds the full history and can be cloned or fetched from with git.
+// ErrEmptyRepository is returned, before anything is written, if the repository has no commits.
+func (c *client) CreateBundle(w io.Writer) error {
+	if c.repo == nil {
+		return fmt.Errorf("repository not initialized")
+	}
+
+	head, err := c.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return ErrEmptyRepository
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	refs, err := c.repo.References()
+	if err != nil {
+		return fmt.Errorf("failed to list references: %w", err)
+	}
+
+	var bundleRefs []*plumbing.Reference
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference && ref.Name() != plumbing.HEAD {
+			bundleRefs = append(bundleRefs, ref)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list references: %w", err)
+	}
+	sort.Slice(bundleRefs, func(i, j int) bool {
+		return bundleRefs[i].Name() < bundleRefs[j].Name()
+	})
+	bundleRefs = append([]*plumbing.Reference{plumbing.NewHashReference(plumbing.HEAD, head.Hash())}, bundleRefs...)
+
+	tips := make([]plumbing.Hash, 0, len(bundleRefs))
+	for _, ref := range bundleRefs {
+		tips = append(tips, ref.Hash())
+	}
+	objects, err := revlist.Objects(c.repo.Storer, tips, nil)
+	if err != nil {
+		return fmt.Errorf("failed to collect objects: %w", err)
+	}
+
+	buf := bufio.NewWriter(w)
+	if _, err := buf.WriteString(bundleHeader); err != nil {
+		return err
+	}
+	for _, ref := range bundleRefs {
+		if _, err := fmt.Fprintf(buf, "%s %s\n", ref.Hash(), ref.Name()); err != nil {
+			return err
+		}
+	}
+	if err := buf.WriteByte('\n'); err != nil {
+		return err
+	}
+
+	if _, err := packfile.NewEncoder(buf, c.repo.Storer, false).Encode(objects, 10); err != nil {
+		return fmt.Errorf("failed to write pack: %w", err)
+	}
+
+	return buf.Flush()
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// ErrRefNotFound is returned when a tag, branch or commit to check out does not exist
+var ErrRefNotFound = errors.New("ref not found")
+
+// ErrUncommittedChanges is returned when a checkout would discard changes in the working tree
+var ErrUncommittedChanges = errors.New("working tree has uncommitted changes")
+
+// Checkout checks out the given tag, branch or commit with a detached HEAD.
+// An empty ref checks out the local branch again, so the repository tracks its remote.
+// ErrUncommittedChanges is returned if the working tree is not clean.
+func (c *client) Checkout(ref string) error {
+	log := getLogger().With(
+		"workDir", c.WorkDir,
+		"ref", ref,
+	)
+
+	if c.repo == nil {
+		return fmt.Errorf("repository not initialized")
+	}
+
+	w, err := c.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := w.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get status: %w", err)
+	}
+	if !status.IsClean() {
+		return ErrUncommittedChanges
+	}
+
+	options := &git.CheckoutOptions{}
+	if ref == "" {
+		branch, err := c.localBranch()
+		if err != nil {
+			return err
+		}
+		options.Branch = branch
+	} else {
+		hash, err := c.repo.ResolveRevision(plumbing.Revision(ref))
+		if err != nil {
+			return ErrRefNotFound
+		}
+		options.Hash = *hash
+	}
+
+	if err := w.Checkout(options); err != nil {
+		return fmt.Errorf("failed to checkout: %w", err)
+	}
+
+	log.Debug("checked out ref")
+	return nil
+}
+
+// localBranch returns the local branch created by the clone
+func (c *client) localBranch() (plumbing.ReferenceName, error) {
+	branches, err := c.repo.Branches()
+	if err != nil {
+		return "", fmt.Errorf("failed to list branches: %w", err)
+	}
+	defer branches.Close()
+
+	ref, err := branches.Next()
+	if err != nil {
+		return "", fmt.Errorf("repository has no local branch: %w", err)
+	}
+	return ref.Name(), nil
+}
+
+// isDetached reports whether HEAD points at a commit instead of a branch
+func (c *client) isDetached() bool {
+	head, err := c.repo.Reference(plumbing.HEAD, false)
+	return err == nil && head.Type() == plumbing.HashReference
+}
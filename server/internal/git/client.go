@@ -2,7 +2,9 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -28,10 +30,33 @@ type Config struct {
 // Client defines the interface for Git operations
 type Client interface {
 	Clone() error
-	Pull() error
-	Commit(message string) (CommitHash, error)
+	Pull() (PullResult, error)
+	Commit(message string, author Author, signer Signer) (CommitHash, error)
 	Push() error
+	PushTo(remote Remote) error
 	EnsureRepo() error
+	DiffWorkingTree(path string) (string, error)
+	ListDeletedFiles() ([]string, error)
+	ListDeletedFilesSince(commit string) ([]string, error)
+	ReadFileFromHistory(path string) ([]byte, error)
+	Log(limit int) ([]Commit, error)
+	Status() (GitStatus, error)
+	CreateBundle(w io.Writer) error
+	Checkout(ref string) error
+}
+
+// Remote is an additional repository the changes are pushed to, e.g. a mirror of the primary remote
+type Remote struct {
+	Name     string
+	URL      string
+	Username string
+	Token    string
+}
+
+// Author is the name and email a commit is attributed to
+type Author struct {
+	Name  string
+	Email string
 }
 
 // CommitHash represents a Git commit hash
@@ -96,45 +121,13 @@ func (c *client) Clone() error {
 	return nil
 }
 
-// Pull pulls the latest changes from the remote repository
-func (c *client) Pull() error {
-	log := getLogger().With(
-		"workDir", c.WorkDir,
-	)
-
-	if c.repo == nil {
-		return fmt.Errorf("repository not initialized")
-	}
-
-	w, err := c.repo.Worktree()
-	if err != nil {
-		return fmt.Errorf("failed to get worktree: %w", err)
-	}
-
-	auth := &http.BasicAuth{
-		Username: c.Username,
-		Password: c.Token,
-	}
-
-	err = w.Pull(&git.PullOptions{
-		Auth:     auth,
-		Progress: os.Stdout,
-	})
-	if err != nil && err != git.NoErrAlreadyUpToDate {
-		return fmt.Errorf("failed to pull changes: %w", err)
-	}
-
-	if err == git.NoErrAlreadyUpToDate {
-		log.Debug("repository already up to date")
-	} else {
-		log.Debug("pulled latest changes")
-	}
-
-	return nil
-}
+// ErrNothingToCommit is returned when a commit is attempted on a working tree without changes
+var ErrNothingToCommit = errors.New("nothing to commit")
 
-// Commit commits the changes in the repository with the given message
-func (c *client) Commit(message string) (CommitHash, error) {
+// Commit stages all changes in the working tree and commits them with the given message.
+// The commit is attributed to author, empty fields are taken from the configured commit name and email.
+// The commit is signed by signer unless it is nil. ErrNothingToCommit is returned if there are no changes.
+func (c *client) Commit(message string, author Author, signer Signer) (CommitHash, error) {
 	log := getLogger().With(
 		"workDir", c.WorkDir,
 	)
@@ -153,13 +146,28 @@ func (c *client) Commit(message string) (CommitHash, error) {
 		return CommitHash(plumbing.ZeroHash), fmt.Errorf("failed to add changes: %w", err)
 	}
 
-	hash, err := w.Commit(message, &git.CommitOptions{
+	if author.Name == "" {
+		author.Name = c.CommitName
+	}
+	if author.Email == "" {
+		author.Email = c.CommitEmail
+	}
+
+	options := &git.CommitOptions{
 		Author: &object.Signature{
-			Name:  c.CommitName,
-			Email: c.CommitEmail,
+			Name:  author.Name,
+			Email: author.Email,
 			When:  time.Now(),
 		},
-	})
+	}
+	if signer != nil {
+		options.Signer = signer
+	}
+
+	hash, err := w.Commit(message, options)
+	if errors.Is(err, git.ErrEmptyCommit) {
+		return CommitHash(plumbing.ZeroHash), ErrNothingToCommit
+	}
 	if err != nil {
 		return CommitHash(plumbing.ZeroHash), fmt.Errorf("failed to commit changes: %w", err)
 	}
@@ -201,7 +209,41 @@ func (c *client) Push() error {
 	return nil
 }
 
-// EnsureRepo ensures the local repository is cloned and up-to-date
+// PushTo pushes the changes to an additional remote repository with the credentials of the remote
+func (c *client) PushTo(remote Remote) error {
+	log := getLogger().With(
+		"workDir", c.WorkDir,
+		"remote", remote.Name,
+	)
+
+	if c.repo == nil {
+		return fmt.Errorf("repository not initialized")
+	}
+
+	auth := &http.BasicAuth{
+		Username: remote.Username,
+		Password: remote.Token,
+	}
+
+	err := c.repo.Push(&git.PushOptions{
+		RemoteURL: remote.URL,
+		Auth:      auth,
+		Progress:  os.Stdout,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to push changes to %s: %w", remote.Name, err)
+	}
+
+	if err == git.NoErrAlreadyUpToDate {
+		log.Debug("remote already up to date")
+	} else {
+		log.Debug("pushed repository changes")
+	}
+	return nil
+}
+
+// EnsureRepo ensures the local repository is cloned and up-to-date.
+// A repository with a checked out tag or commit is not pulled.
 func (c *client) EnsureRepo() error {
 	log := getLogger().With(
 		"workDir", c.WorkDir,
@@ -220,5 +262,12 @@ func (c *client) EnsureRepo() error {
 		return fmt.Errorf("failed to open existing repository: %w", err)
 	}
 
-	return c.Pull()
+	// A detached HEAD is a checked out tag or commit, pulling would move it
+	if c.isDetached() {
+		log.Debug("repository is pinned to a ref, skipping pull")
+		return nil
+	}
+
+	_, err = c.Pull()
+	return err
 }
@@ -0,0 +1,45 @@
+package git
+
+import (
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"syscall"
+	"time"
+
+	transportclient "github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// IsPrivateIP reports whether ip is a loopback, private, unspecified or link-local address
+func IsPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast()
+}
+
+// BlockPrivateHosts makes all git clients of the process refuse http(s) connections to private
+// addresses. The address is checked when the connection is made, so a host name validated before
+// cannot be pointed at a private address afterwards, and redirects are covered as well.
+func BlockPrivateHosts() {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || IsPrivateIP(ip) {
+				return fmt.Errorf("connection to %s is not allowed", host)
+			}
+			return nil
+		},
+	}
+
+	transport := nethttp.DefaultTransport.(*nethttp.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	httpClient := http.NewClient(&nethttp.Client{Transport: transport})
+
+	transportclient.InstallProtocol("http", httpClient)
+	transportclient.InstallProtocol("https", httpClient)
+}
@@ -0,0 +1,199 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/binary"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// DiffWorkingTree returns a unified diff of the working tree against HEAD.
+// If path is not empty, only changes to that file or directory are included.
+// An empty string is returned when there are no changes.
+func (c *client) DiffWorkingTree(path string) (string, error) {
+	if c.repo == nil {
+		return "", fmt.Errorf("repository not initialized")
+	}
+
+	w, err := c.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := w.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+
+	var head *object.Tree
+	ref, err := c.repo.Head()
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		// No commits yet, everything is new
+	case err != nil:
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	default:
+		commit, err := c.repo.CommitObject(ref.Hash())
+		if err != nil {
+			return "", fmt.Errorf("failed to get HEAD commit: %w", err)
+		}
+		if head, err = commit.Tree(); err != nil {
+			return "", fmt.Errorf("failed to get HEAD tree: %w", err)
+		}
+	}
+
+	path = strings.Trim(filepath.ToSlash(path), "/")
+	var paths []string
+	for p, s := range status {
+		if s.Staging == git.Unmodified && s.Worktree == git.Unmodified {
+			continue
+		}
+		if path != "" && p != path && !strings.HasPrefix(p, path+"/") {
+			continue
+		}
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	patch := &workingTreePatch{}
+	for _, p := range paths {
+		filePatch, err := c.diffFile(head, p)
+		if err != nil {
+			return "", err
+		}
+		if filePatch != nil {
+			patch.filePatches = append(patch.filePatches, filePatch)
+		}
+	}
+
+	if len(patch.filePatches) == 0 {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := fdiff.NewUnifiedEncoder(&buf, fdiff.DefaultContextLines).Encode(patch); err != nil {
+		return "", fmt.Errorf("failed to encode diff: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// diffFile builds the patch for a single path, nil is returned if the content is unchanged
+func (c *client) diffFile(head *object.Tree, path string) (*workingTreeFilePatch, error) {
+	patch := &workingTreeFilePatch{}
+	var fromContent, toContent string
+
+	if head != nil {
+		file, err := head.File(path)
+		if err != nil && !errors.Is(err, object.ErrFileNotFound) {
+			return nil, fmt.Errorf("failed to read %s from HEAD: %w", path, err)
+		}
+		if file != nil {
+			isBinary, err := file.IsBinary()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s from HEAD: %w", path, err)
+			}
+			patch.binary = isBinary
+			if !isBinary {
+				if fromContent, err = file.Contents(); err != nil {
+					return nil, fmt.Errorf("failed to read %s from HEAD: %w", path, err)
+				}
+			}
+			patch.from = &workingTreeFile{hash: file.Hash, mode: file.Mode, path: path}
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.WorkDir, filepath.FromSlash(path)))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err == nil {
+		hash := plumbing.ComputeHash(plumbing.BlobObject, data)
+		if patch.from != nil && patch.from.hash == hash {
+			return nil, nil
+		}
+		isBinary, err := binary.IsBinary(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		patch.binary = patch.binary || isBinary
+		toContent = string(data)
+		patch.to = &workingTreeFile{hash: hash, mode: filemode.Regular, path: path}
+	}
+
+	if patch.from == nil && patch.to == nil {
+		return nil, nil
+	}
+
+	if !patch.binary {
+		for _, d := range diff.Do(fromContent, toContent) {
+			op := fdiff.Equal
+			switch d.Type {
+			case diffmatchpatch.DiffInsert:
+				op = fdiff.Add
+			case diffmatchpatch.DiffDelete:
+				op = fdiff.Delete
+			}
+			patch.chunks = append(patch.chunks, &workingTreeChunk{content: d.Text, op: op})
+		}
+	}
+
+	return patch, nil
+}
+
+// workingTreePatch implements diff.Patch for changes between HEAD and the working tree
+type workingTreePatch struct {
+	filePatches []fdiff.FilePatch
+}
+
+func (p *workingTreePatch) FilePatches() []fdiff.FilePatch { return p.filePatches }
+func (p *workingTreePatch) Message() string                { return "" }
+
+type workingTreeFilePatch struct {
+	from, to *workingTreeFile
+	binary   bool
+	chunks   []fdiff.Chunk
+}
+
+func (p *workingTreeFilePatch) IsBinary() bool        { return p.binary }
+func (p *workingTreeFilePatch) Chunks() []fdiff.Chunk { return p.chunks }
+
+// Files returns the from and to files, a missing side must be a nil interface
+func (p *workingTreeFilePatch) Files() (from, to fdiff.File) {
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+type workingTreeFile struct {
+	hash plumbing.Hash
+	mode filemode.FileMode
+	path string
+}
+
+func (f *workingTreeFile) Hash() plumbing.Hash     { return f.hash }
+func (f *workingTreeFile) Mode() filemode.FileMode { return f.mode }
+func (f *workingTreeFile) Path() string            { return f.path }
+
+type workingTreeChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c *workingTreeChunk) Content() string       { return c.content }
+func (c *workingTreeChunk) Type() fdiff.Operation { return c.op }
@@ -0,0 +1,195 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// ErrFileNotInHistory is returned when no commit contains the requested file
+var ErrFileNotInHistory = errors.New("file not found in history")
+
+// ErrCommitNotFound is returned when a revision does not resolve to a commit
+var ErrCommitNotFound = errors.New("commit not found")
+
+// Commit is a commit in the history of a repository
+type Commit struct {
+	Hash    string
+	Author  Author
+	Message string
+	Time    time.Time
+}
+
+// Log returns at most limit commits reachable from HEAD, newest first.
+// A repository without commits has an empty history.
+func (c *client) Log(limit int) ([]Commit, error) {
+	if c.repo == nil {
+		return nil, fmt.Errorf("repository not initialized")
+	}
+
+	history := []Commit{}
+	if limit < 1 {
+		return history, nil
+	}
+
+	ref, err := c.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	commits, err := c.repo.Log(&git.LogOptions{From: ref.Hash(), Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer commits.Close()
+
+	err = commits.ForEach(func(commit *object.Commit) error {
+		history = append(history, Commit{
+			Hash:    commit.Hash.String(),
+			Author:  Author{Name: commit.Author.Name, Email: commit.Author.Email},
+			Message: commit.Message,
+			Time:    commit.Author.When.UTC(),
+		})
+		if len(history) == limit {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return history, nil
+}
+
+// ListDeletedFiles returns the paths of files that exist in HEAD but are missing from the working tree
+func (c *client) ListDeletedFiles() ([]string, error) {
+	if c.repo == nil {
+		return nil, fmt.Errorf("repository not initialized")
+	}
+
+	w, err := c.repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := w.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	deleted := []string{}
+	for path, s := range status {
+		if s.Worktree == git.Deleted || s.Staging == git.Deleted {
+			deleted = append(deleted, path)
+		}
+	}
+	sort.Strings(deleted)
+
+	return deleted, nil
+}
+
+// ListDeletedFilesSince returns the paths of files that exist in the given commit but are missing from the working tree.
+// ErrCommitNotFound is returned if the revision cannot be resolved.
+func (c *client) ListDeletedFilesSince(commit string) ([]string, error) {
+	if c.repo == nil {
+		return nil, fmt.Errorf("repository not initialized")
+	}
+
+	hash, err := c.repo.ResolveRevision(plumbing.Revision(commit))
+	if err != nil {
+		return nil, ErrCommitNotFound
+	}
+
+	commitObj, err := c.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, ErrCommitNotFound
+	}
+
+	tree, err := commitObj.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit tree: %w", err)
+	}
+
+	deleted := []string{}
+	err = tree.Files().ForEach(func(file *object.File) error {
+		_, err := os.Lstat(filepath.Join(c.WorkDir, filepath.FromSlash(file.Name)))
+		if os.IsNotExist(err) {
+			deleted = append(deleted, file.Name)
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare commit tree: %w", err)
+	}
+	sort.Strings(deleted)
+
+	return deleted, nil
+}
+
+// ReadFileFromHistory returns the content of path in the most recent commit that contains it.
+// ErrFileNotInHistory is returned if no commit reachable from HEAD contains the file.
+func (c *client) ReadFileFromHistory(path string) ([]byte, error) {
+	if c.repo == nil {
+		return nil, fmt.Errorf("repository not initialized")
+	}
+
+	ref, err := c.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, ErrFileNotInHistory
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	commits, err := c.repo.Log(&git.LogOptions{From: ref.Hash()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer commits.Close()
+
+	path = strings.Trim(filepath.ToSlash(path), "/")
+	var content []byte
+	err = commits.ForEach(func(commit *object.Commit) error {
+		file, err := commit.File(path)
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		reader, err := file.Reader()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		if content, err = io.ReadAll(reader); err != nil {
+			return err
+		}
+		return storer.ErrStop
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from history: %w", path, err)
+	}
+	if content == nil {
+		return nil, ErrFileNotInHistory
+	}
+
+	return content, nil
+}
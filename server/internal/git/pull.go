@@ -0,0 +1,253 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// PullResult describes the changes brought in by a pull
+type PullResult struct {
+	// UpToDate is set when the remote had no new commits
+	UpToDate bool
+	// Files are the paths added, modified or deleted by the pulled commits
+	Files []string
+}
+
+// Reasons of a PullConflictError
+const (
+	// PullConflictDiverged means local commits are missing from the remote, Files are changed on both sides
+	PullConflictDiverged = "diverged"
+	// PullConflictUncommittedChanges means the working tree has changes, Files are the changed files
+	PullConflictUncommittedChanges = "uncommitted_changes"
+)
+
+// PullConflictError is returned when the remote changes cannot be applied to the working tree.
+// Only fast-forward pulls are supported, so diverged histories are not merged.
+type PullConflictError struct {
+	Reason string
+	Files  []string
+}
+
+func (e *PullConflictError) Error() string {
+	return fmt.Sprintf("pull conflict (%s): %d files", e.Reason, len(e.Files))
+}
+
+// IsPullConflictError checks if the error is a PullConflictError
+func IsPullConflictError(err error) bool {
+	var conflictErr *PullConflictError
+	return errors.As(err, &conflictErr)
+}
+
+// Pull pulls the latest changes from the remote repository and returns the changed files.
+// A PullConflictError is returned if the history diverged or the working tree has uncommitted changes.
+func (c *client) Pull() (PullResult, error) {
+	log := getLogger().With(
+		"workDir", c.WorkDir,
+	)
+
+	if c.repo == nil {
+		return PullResult{}, fmt.Errorf("repository not initialized")
+	}
+
+	w, err := c.repo.Worktree()
+	if err != nil {
+		return PullResult{}, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	// The zero hash stands for a repository without commits
+	var before plumbing.Hash
+	head, err := c.repo.Head()
+	if err == nil {
+		before = head.Hash()
+	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return PullResult{}, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	auth := &http.BasicAuth{
+		Username: c.Username,
+		Password: c.Token,
+	}
+
+	err = w.Pull(&git.PullOptions{
+		Auth:     auth,
+		Progress: os.Stdout,
+	})
+	switch {
+	case err == git.NoErrAlreadyUpToDate:
+		log.Debug("repository already up to date")
+		return PullResult{UpToDate: true, Files: []string{}}, nil
+	case errors.Is(err, git.ErrNonFastForwardUpdate):
+		files, err := c.divergedFiles(before)
+		if err != nil {
+			return PullResult{}, err
+		}
+		log.Debug("pull rejected, history diverged")
+		return PullResult{}, &PullConflictError{Reason: PullConflictDiverged, Files: files}
+	case errors.Is(err, git.ErrUnstagedChanges):
+		// go-git moves the branch before it checks the working tree, move it back
+		// so that the uncommitted changes are not shown against the remote commit
+		if !before.IsZero() {
+			if err := c.setBranch(before); err != nil {
+				return PullResult{}, err
+			}
+		}
+		files, err := uncommittedFiles(w)
+		if err != nil {
+			return PullResult{}, err
+		}
+		log.Debug("pull rejected, working tree has uncommitted changes")
+		return PullResult{}, &PullConflictError{Reason: PullConflictUncommittedChanges, Files: files}
+	case err != nil:
+		return PullResult{}, fmt.Errorf("failed to pull changes: %w", err)
+	}
+
+	head, err = c.repo.Head()
+	if err != nil {
+		return PullResult{}, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	files, err := c.changedFiles(before, head.Hash())
+	if err != nil {
+		return PullResult{}, err
+	}
+
+	log.Debug("pulled latest changes",
+		"files", len(files))
+	return PullResult{Files: files}, nil
+}
+
+// changedFiles returns the sorted paths that differ between the trees of two commits,
+// a zero from hash compares against an empty tree
+func (c *client) changedFiles(from, to plumbing.Hash) ([]string, error) {
+	fromTree, err := c.commitTree(from)
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := c.commitTree(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff trees: %w", err)
+	}
+
+	files := []string{}
+	for _, change := range changes {
+		if change.To.Name != "" {
+			files = append(files, change.To.Name)
+		}
+		if change.From.Name != "" && change.From.Name != change.To.Name {
+			files = append(files, change.From.Name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// commitTree returns the tree of a commit, nil for the zero hash
+func (c *client) commitTree(hash plumbing.Hash) (*object.Tree, error) {
+	if hash.IsZero() {
+		return nil, nil
+	}
+	commit, err := c.repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit tree: %w", err)
+	}
+	return tree, nil
+}
+
+// divergedFiles returns the files changed both by the local commits since head and by the fetched
+// remote commits since their common ancestor, the files that would conflict in a merge
+func (c *client) divergedFiles(head plumbing.Hash) ([]string, error) {
+	branch, err := c.repo.Head()
+	if err != nil || !branch.Name().IsBranch() {
+		return []string{}, nil
+	}
+	remote, err := c.repo.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch.Name().Short()), true)
+	if err != nil {
+		return []string{}, nil
+	}
+
+	localCommit, err := c.repo.CommitObject(head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", head, err)
+	}
+	remoteCommit, err := c.repo.CommitObject(remote.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", remote.Hash(), err)
+	}
+	bases, err := localCommit.MergeBase(remoteCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge base: %w", err)
+	}
+	var base plumbing.Hash
+	if len(bases) > 0 {
+		base = bases[0].Hash
+	}
+
+	local, err := c.changedFiles(base, head)
+	if err != nil {
+		return nil, err
+	}
+	remoteFiles, err := c.changedFiles(base, remote.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]bool, len(local))
+	for _, file := range local {
+		changed[file] = true
+	}
+	files := []string{}
+	for _, file := range remoteFiles {
+		if changed[file] {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// setBranch points the checked out branch at hash without touching the working tree
+func (c *client) setBranch(hash plumbing.Hash) error {
+	head, err := c.repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	name := plumbing.HEAD
+	if head.Type() == plumbing.SymbolicReference {
+		name = head.Target()
+	}
+	if err := c.repo.Storer.SetReference(plumbing.NewHashReference(name, hash)); err != nil {
+		return fmt.Errorf("failed to reset branch: %w", err)
+	}
+	return nil
+}
+
+// uncommittedFiles returns the tracked files with changes in the working tree
+func uncommittedFiles(w *git.Worktree) ([]string, error) {
+	status, err := w.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	files := []string{}
+	for path, s := range status {
+		if s.Worktree != git.Unmodified && s.Worktree != git.Untracked {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
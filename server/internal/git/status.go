@@ -0,0 +1,129 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// GitStatus is the state of the working tree and of the checked out branch against the remote
+type GitStatus struct {
+	// Modified are the tracked files with uncommitted changes, including deleted files
+	Modified []string
+	// Untracked are the files that are neither committed nor ignored
+	Untracked []string
+	// Ahead is the number of local commits missing from the remote
+	Ahead int
+	// Behind is the number of remote commits missing locally
+	Behind int
+}
+
+// Clean reports whether the working tree has no uncommitted changes
+func (s GitStatus) Clean() bool {
+	return len(s.Modified) == 0 && len(s.Untracked) == 0
+}
+
+// Status returns the uncommitted changes of the working tree and how many commits the branch is ahead
+// and behind the remote. The remote branch is compared as of the last pull or push, it is not fetched.
+func (c *client) Status() (GitStatus, error) {
+	if c.repo == nil {
+		return GitStatus{}, fmt.Errorf("repository not initialized")
+	}
+
+	w, err := c.repo.Worktree()
+	if err != nil {
+		return GitStatus{}, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := w.Status()
+	if err != nil {
+		return GitStatus{}, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	result := GitStatus{Modified: []string{}, Untracked: []string{}}
+	for path, s := range status {
+		switch {
+		case s.Worktree == git.Untracked:
+			result.Untracked = append(result.Untracked, path)
+		case s.Worktree != git.Unmodified || s.Staging != git.Unmodified:
+			result.Modified = append(result.Modified, path)
+		}
+	}
+	sort.Strings(result.Modified)
+	sort.Strings(result.Untracked)
+
+	head, err := c.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return result, nil
+	}
+	if err != nil {
+		return GitStatus{}, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if !head.Name().IsBranch() {
+		return result, nil
+	}
+
+	// A branch that was never pushed is ahead by all of its commits
+	var remoteHash plumbing.Hash
+	remote, err := c.repo.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, head.Name().Short()), true)
+	if err == nil {
+		remoteHash = remote.Hash()
+	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return GitStatus{}, fmt.Errorf("failed to get remote branch: %w", err)
+	}
+
+	if result.Ahead, err = c.countMissing(head.Hash(), remoteHash); err != nil {
+		return GitStatus{}, err
+	}
+	if result.Behind, err = c.countMissing(remoteHash, head.Hash()); err != nil {
+		return GitStatus{}, err
+	}
+	return result, nil
+}
+
+// countMissing returns the number of commits reachable from from that are not reachable from to,
+// a zero hash has no commits
+func (c *client) countMissing(from, to plumbing.Hash) (int, error) {
+	if from.IsZero() || from == to {
+		return 0, nil
+	}
+
+	reachable := make(map[plumbing.Hash]bool)
+	if !to.IsZero() {
+		if err := c.walkCommits(to, func(commit *object.Commit) {
+			reachable[commit.Hash] = true
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	missing := 0
+	err := c.walkCommits(from, func(commit *object.Commit) {
+		if !reachable[commit.Hash] {
+			missing++
+		}
+	})
+	return missing, err
+}
+
+// walkCommits calls fn for every commit reachable from hash
+func (c *client) walkCommits(hash plumbing.Hash, fn func(commit *object.Commit)) error {
+	commits, err := c.repo.Log(&git.LogOptions{From: hash})
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	defer commits.Close()
+
+	err = commits.ForEach(func(commit *object.Commit) error {
+		fn(commit)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+
+	"lemma/internal/context"
+	"lemma/internal/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// AccessLogResponse represents a page of the access log of a workspace
+type AccessLogResponse struct {
+	Entries []*models.FileAccess `json:"entries"`
+	// NextCursor is passed as cursor to fetch the next page, omitted on the last page
+	NextCursor int `json:"nextCursor,omitempty"`
+}
+
+// AdminGetAccessLog godoc
+// @Summary Get the access log of a workspace
+// @Description Returns who read or downloaded which file of the workspace, newest entries first.
+// @Description Entries are only recorded while access logging is enabled for the workspace.
+// @Tags Admin
+// @Security CookieAuth
+// @ID adminGetAccessLog
+// @Produce json
+// @Param workspaceId path int true "Workspace ID"
+// @Param limit query int false "Maximum number of entries to return"
+// @Param cursor query int false "Cursor returned by the previous page"
+// @Success 200 {object} AccessLogResponse
+// @Failure 400 {object} ErrorResponse "Invalid workspace ID"
+// @Failure 400 {object} ErrorResponse "Invalid limit"
+// @Failure 400 {object} ErrorResponse "Invalid cursor"
+// @Failure 404 {object} ErrorResponse "Workspace not found"
+// @Failure 500 {object} ErrorResponse "Failed to get access log"
+// @Router /admin/workspaces/{workspaceId}/access-log [get]
+func (h *Handler) AdminGetAccessLog() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getAdminLogger().With(
+			"handler", "AdminGetAccessLog",
+			"adminID", ctx.UserID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		workspaceID, err := strconv.Atoi(chi.URLParam(r, "workspaceId"))
+		if err != nil {
+			log.Debug("invalid workspace ID format",
+				"workspaceIDParam", chi.URLParam(r, "workspaceId"),
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid workspace ID", http.StatusBadRequest)
+			return
+		}
+
+		page, ok := h.parsePagination(w, r)
+		if !ok {
+			return
+		}
+		limit := page.Limit
+
+		cursor := 0
+		if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+			parsed, err := strconv.Atoi(cursorStr)
+			if err != nil || parsed < 0 {
+				respondError(w, "Invalid cursor", http.StatusBadRequest)
+				return
+			}
+			cursor = parsed
+		}
+
+		if _, err := h.DB.GetWorkspaceByID(workspaceID); err != nil {
+			log.Debug("workspace not found",
+				"workspaceID", workspaceID,
+				"error", err.Error(),
+			)
+			respondError(w, "Workspace not found", http.StatusNotFound)
+			return
+		}
+
+		// Fetch one extra entry to know whether there is a next page
+		entries, err := h.DB.GetFileAccessLog(workspaceID, limit+1, cursor)
+		if err != nil {
+			log.Error("failed to fetch access log from database",
+				"workspaceID", workspaceID,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to get access log", http.StatusInternalServerError)
+			return
+		}
+
+		response := AccessLogResponse{Entries: entries}
+		if len(entries) > limit {
+			response.Entries = entries[:limit]
+			response.NextCursor = entries[limit-1].ID
+		}
+
+		respondJSON(w, response)
+	}
+}
+
+// recordFileAccess adds an entry to the access log of the workspace if access logging is enabled for it.
+// The file has already been read, so failures are only logged.
+func (h *Handler) recordFileAccess(r *http.Request, ctx *context.HandlerContext, accessType models.FileAccessType, path string) {
+	if !ctx.Workspace.AccessLogging {
+		return
+	}
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	err = h.DB.CreateFileAccess(&models.FileAccess{
+		WorkspaceID: ctx.Workspace.ID,
+		UserID:      ctx.UserID,
+		Type:        accessType,
+		Path:        path,
+		IPAddress:   ip,
+	})
+	if err != nil {
+		getFilesLogger().Warn("failed to record file access",
+			"workspaceID", ctx.Workspace.ID,
+			"type", accessType,
+			"error", err.Error(),
+		)
+	}
+}
@@ -0,0 +1,135 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLogHandlers_Integration(t *testing.T) {
+	runWithDatabases(t, testAccessLogHandlers)
+}
+
+func testAccessLogHandlers(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarness(t, dbConfig)
+	defer h.teardown(t)
+
+	createWorkspace := func(t *testing.T, name string, accessLogging bool) *models.Workspace {
+		t.Helper()
+		workspace := &models.Workspace{
+			UserID:        h.RegularTestUser.session.UserID,
+			Name:          name,
+			AccessLogging: accessLogging,
+		}
+		rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(workspace))
+		return workspace
+	}
+
+	getAccessLog := func(t *testing.T, workspaceID int, query string) handlers.AccessLogResponse {
+		t.Helper()
+		rr := h.makeRequest(t, http.MethodGet, fmt.Sprintf("/api/v1/admin/workspaces/%d/access-log%s", workspaceID, query), nil, h.AdminTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response handlers.AccessLogResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		return response
+	}
+
+	logged := createWorkspace(t, "Access Log Workspace", true)
+	require.True(t, logged.AccessLogging)
+	loggedURL := "/api/v1/workspaces/" + url.PathEscape(logged.Name)
+
+	rr := h.makeRequest(t, http.MethodPost, loggedURL+"/files?file_path="+url.QueryEscape("secret.md"), "content", h.RegularTestUser)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	t.Run("saving is not an access", func(t *testing.T) {
+		assert.Empty(t, getAccessLog(t, logged.ID, "").Entries)
+	})
+
+	t.Run("reads produce entries", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, loggedURL+"/files/content?file_path="+url.QueryEscape("secret.md"), nil, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, loggedURL+"/files/tail?file_path="+url.QueryEscape("secret.md"), nil, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, loggedURL+"/export", nil, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		entries := getAccessLog(t, logged.ID, "").Entries
+		require.Len(t, entries, 3)
+
+		// Newest first
+		assert.Equal(t, models.FileAccessExport, entries[0].Type)
+		assert.Empty(t, entries[0].Path)
+		for _, entry := range entries[1:] {
+			assert.Equal(t, models.FileAccessRead, entry.Type)
+			assert.Equal(t, "secret.md", entry.Path)
+		}
+		for _, entry := range entries {
+			assert.Equal(t, logged.ID, entry.WorkspaceID)
+			assert.Equal(t, h.RegularTestUser.session.UserID, entry.UserID)
+			assert.Equal(t, "192.0.2.1", entry.IPAddress)
+			assert.False(t, entry.CreatedAt.IsZero())
+		}
+	})
+
+	t.Run("missing files are not logged", func(t *testing.T) {
+		before := len(getAccessLog(t, logged.ID, "").Entries)
+
+		rr := h.makeRequest(t, http.MethodGet, loggedURL+"/files/content?file_path="+url.QueryEscape("missing.md"), nil, h.RegularTestUser)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+
+		assert.Len(t, getAccessLog(t, logged.ID, "").Entries, before)
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		page := getAccessLog(t, logged.ID, "?limit=2")
+		require.Len(t, page.Entries, 2)
+		require.NotZero(t, page.NextCursor)
+
+		next := getAccessLog(t, logged.ID, fmt.Sprintf("?limit=2&cursor=%d", page.NextCursor))
+		require.Len(t, next.Entries, 1)
+		assert.Zero(t, next.NextCursor)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		workspace := createWorkspace(t, "Unlogged Workspace", false)
+		workspaceURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name)
+
+		rr := h.makeRequest(t, http.MethodPost, workspaceURL+"/files?file_path="+url.QueryEscape("public.md"), "content", h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+		rr = h.makeRequest(t, http.MethodGet, workspaceURL+"/files/content?file_path="+url.QueryEscape("public.md"), nil, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		assert.Empty(t, getAccessLog(t, workspace.ID, "").Entries)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/admin/workspaces/abc/access-log", nil, h.AdminTestUser)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, "/api/v1/admin/workspaces/999999/access-log", nil, h.AdminTestUser)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, fmt.Sprintf("/api/v1/admin/workspaces/%d/access-log?limit=-1", logged.ID), nil, h.AdminTestUser)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("admin only", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, fmt.Sprintf("/api/v1/admin/workspaces/%d/access-log", logged.ID), nil, h.RegularTestUser)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
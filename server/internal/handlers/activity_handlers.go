@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"lemma/internal/context"
+	"lemma/internal/events"
+	"lemma/internal/logging"
+	"lemma/internal/models"
+)
+
+// ActivityResponse represents a page of the workspace activity feed
+type ActivityResponse struct {
+	Entries []*models.Activity `json:"entries"`
+	// NextCursor is passed as cursor to fetch the next page, omitted on the last page
+	NextCursor int `json:"nextCursor,omitempty"`
+}
+
+func getActivityLogger() logging.Logger {
+	return getHandlersLogger().WithGroup("activity")
+}
+
+// GetWorkspaceActivity godoc
+// @Summary Get workspace activity
+// @Description Returns the activity feed of the workspace, newest entries first
+// @Tags workspaces
+// @ID getWorkspaceActivity
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param limit query int false "Maximum number of entries to return"
+// @Param cursor query int false "Cursor returned by the previous page"
+// @Success 200 {object} ActivityResponse
+// @Failure 400 {object} ErrorResponse "Invalid limit"
+// @Failure 400 {object} ErrorResponse "Invalid cursor"
+// @Failure 500 {object} ErrorResponse "Failed to get activity"
+// @Router /workspaces/{workspace_name}/activity [get]
+func (h *Handler) GetWorkspaceActivity() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getActivityLogger().With(
+			"handler", "GetWorkspaceActivity",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		page, ok := h.parsePagination(w, r)
+		if !ok {
+			return
+		}
+		limit := page.Limit
+
+		cursor := 0
+		if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+			parsed, err := strconv.Atoi(cursorStr)
+			if err != nil || parsed < 0 {
+				respondError(w, "Invalid cursor", http.StatusBadRequest)
+				return
+			}
+			cursor = parsed
+		}
+
+		// Fetch one extra entry to know whether there is a next page
+		entries, err := h.DB.GetWorkspaceActivity(ctx.Workspace.ID, limit+1, cursor)
+		if err != nil {
+			log.Error("failed to fetch activity from database",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to get activity", http.StatusInternalServerError)
+			return
+		}
+
+		response := ActivityResponse{Entries: entries}
+		if len(entries) > limit {
+			response.Entries = entries[:limit]
+			response.NextCursor = entries[limit-1].ID
+		}
+
+		respondJSON(w, response)
+	}
+}
+
+// recordActivity adds an entry to the workspace activity feed.
+// The operation it records has already happened, so failures are only logged.
+func (h *Handler) recordActivity(activity *models.Activity) {
+	if err := h.DB.CreateActivity(activity); err != nil {
+		getActivityLogger().Warn("failed to record activity",
+			"workspaceID", activity.WorkspaceID,
+			"type", activity.Type,
+			"error", err.Error(),
+		)
+	}
+
+	if h.Events != nil {
+		h.Events.Publish(activity.WorkspaceID, events.Event{Type: string(activity.Type), Data: activity})
+	}
+}
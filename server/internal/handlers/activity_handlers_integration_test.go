@@ -0,0 +1,122 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityHandlers_Integration(t *testing.T) {
+	runWithDatabases(t, testActivityHandlers)
+}
+
+func testActivityHandlers(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarness(t, dbConfig)
+	defer h.teardown(t)
+
+	workspace := &models.Workspace{
+		UserID: h.RegularTestUser.session.UserID,
+		Name:   "Activity Test Workspace",
+	}
+	rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, h.RegularTestUser)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(workspace))
+
+	workspaceURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name)
+	filesURL := workspaceURL + "/files"
+
+	getActivity := func(t *testing.T, query string) handlers.ActivityResponse {
+		t.Helper()
+		rr := h.makeRequest(t, http.MethodGet, workspaceURL+"/activity"+query, nil, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response handlers.ActivityResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		return response
+	}
+
+	t.Run("empty feed", func(t *testing.T) {
+		response := getActivity(t, "")
+		assert.Empty(t, response.Entries)
+		assert.Zero(t, response.NextCursor)
+	})
+
+	t.Run("save and delete produce entries", func(t *testing.T) {
+		filePath := "activity.md"
+		rr := h.makeRequest(t, http.MethodPost, filesURL+"?file_path="+url.QueryEscape(filePath), "content", h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodDelete, filesURL+"?file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		response := getActivity(t, "")
+		require.Len(t, response.Entries, 2)
+
+		// Newest first
+		deleted, saved := response.Entries[0], response.Entries[1]
+		assert.Equal(t, models.ActivityFileDeleted, deleted.Type)
+		assert.Equal(t, filePath, deleted.Path)
+		assert.Equal(t, h.RegularTestUser.session.UserID, deleted.UserID)
+		assert.False(t, deleted.CreatedAt.IsZero())
+
+		assert.Equal(t, models.ActivityFileSaved, saved.Type)
+		assert.Equal(t, filePath, saved.Path)
+		assert.Equal(t, workspace.ID, saved.WorkspaceID)
+	})
+
+	t.Run("move produces entry with both paths", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodPost, filesURL+"?file_path="+url.QueryEscape("src.md"), "content", h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		moveURL := fmt.Sprintf("%s/move?src_path=%s&dest_path=%s", filesURL, url.QueryEscape("src.md"), url.QueryEscape("dst.md"))
+		rr = h.makeRequest(t, http.MethodPost, moveURL, nil, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		response := getActivity(t, "?limit=1")
+		require.Len(t, response.Entries, 1)
+		assert.Equal(t, models.ActivityFileMoved, response.Entries[0].Type)
+		assert.Equal(t, "src.md", response.Entries[0].Path)
+		assert.Equal(t, "dst.md", response.Entries[0].TargetPath)
+	})
+
+	t.Run("cursor pagination", func(t *testing.T) {
+		all := getActivity(t, "")
+		require.Len(t, all.Entries, 4)
+		assert.Zero(t, all.NextCursor)
+
+		first := getActivity(t, "?limit=3")
+		require.Len(t, first.Entries, 3)
+		require.NotZero(t, first.NextCursor)
+
+		second := getActivity(t, fmt.Sprintf("?limit=3&cursor=%d", first.NextCursor))
+		require.Len(t, second.Entries, 1)
+		assert.Zero(t, second.NextCursor)
+		assert.Equal(t, all.Entries[3].ID, second.Entries[0].ID)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, workspaceURL+"/activity?limit=abc", nil, h.RegularTestUser)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, workspaceURL+"/activity?cursor=-1", nil, h.RegularTestUser)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("other users cannot read the feed", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, workspaceURL+"/activity", nil, h.AdminTestUser)
+		assert.NotEqual(t, http.StatusOK, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, workspaceURL+"/activity", nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
@@ -2,6 +2,7 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
 	"lemma/internal/context"
 	"lemma/internal/db"
@@ -10,6 +11,7 @@ import (
 	"lemma/internal/storage"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -34,6 +36,32 @@ type UpdateUserRequest struct {
 	Theme       string          `json:"theme,omitempty"`
 }
 
+// TransferWorkspaceRequest holds the request fields for transferring a workspace to another user
+type TransferWorkspaceRequest struct {
+	UserID int `json:"userId"`
+	// KeepGitCredentials keeps the git remote credentials of the previous owner,
+	// by default they are removed and git is disabled for the workspace
+	KeepGitCredentials bool `json:"keepGitCredentials,omitempty"`
+}
+
+// WorkspaceSelection selects the workspaces of a batch update, exactly one of the fields must be set
+type WorkspaceSelection struct {
+	All          bool  `json:"all,omitempty"`
+	UserID       int   `json:"userId,omitempty"`
+	WorkspaceIDs []int `json:"workspaceIds,omitempty"`
+}
+
+// BatchUpdateWorkspaceSettingsRequest holds the request fields for updating the settings of many workspaces
+type BatchUpdateWorkspaceSettingsRequest struct {
+	Filter   WorkspaceSelection            `json:"filter"`
+	Settings models.WorkspaceSettingsPatch `json:"settings"`
+}
+
+// BatchUpdateWorkspaceSettingsResponse holds the number of workspaces updated by a batch update
+type BatchUpdateWorkspaceSettingsResponse struct {
+	Updated int `json:"updated"`
+}
+
 // WorkspaceStats holds workspace statistics
 type WorkspaceStats struct {
 	UserID             int       `json:"userID"`
@@ -44,6 +72,12 @@ type WorkspaceStats struct {
 	*storage.FileCountStats
 }
 
+// ReindexResponse holds the results of reindexing all workspaces
+type ReindexResponse struct {
+	Workspaces []*storage.ReindexResult `json:"workspaces"`
+	DurationMs int64                    `json:"durationMs"`
+}
+
 // SystemStats holds system-wide statistics
 type SystemStats struct {
 	*db.UserStats
@@ -56,12 +90,20 @@ func getAdminLogger() logging.Logger {
 
 // AdminListUsers godoc
 // @Summary List all users
-// @Description Returns the list of all users
+// @Description Returns a page of users ordered by ID, optionally filtered by role and by text in the email or display name
 // @Tags Admin
 // @Security CookieAuth
 // @ID adminListUsers
 // @Produce json
+// @Param limit query int false "Maximum number of items to return"
+// @Param offset query int false "Number of items to skip"
+// @Param role query string false "Only users with this role" Enums(admin, editor, viewer)
+// @Param search query string false "Only users whose email or display name contains this text, ignoring case"
 // @Success 200 {array} models.User
+// @Header 200 {int} X-Pagination-Limit "Effective limit"
+// @Header 200 {int} X-Total-Count "Total number of matching items"
+// @Failure 400 {object} ErrorResponse "Invalid limit"
+// @Failure 400 {object} ErrorResponse "Invalid role"
 // @Failure 500 {object} ErrorResponse "Failed to list users"
 // @Router /admin/users [get]
 func (h *Handler) AdminListUsers() http.HandlerFunc {
@@ -76,7 +118,23 @@ func (h *Handler) AdminListUsers() http.HandlerFunc {
 			"clientIP", r.RemoteAddr,
 		)
 
-		users, err := h.DB.GetAllUsers()
+		page, ok := h.parsePagination(w, r)
+		if !ok {
+			return
+		}
+
+		filter := db.UserFilter{
+			Role:   models.UserRole(r.URL.Query().Get("role")),
+			Search: strings.TrimSpace(r.URL.Query().Get("search")),
+			Limit:  page.Limit,
+			Offset: page.Offset,
+		}
+		if filter.Role != "" && filter.Role != models.RoleAdmin && filter.Role != models.RoleEditor && filter.Role != models.RoleViewer {
+			respondError(w, "Invalid role", http.StatusBadRequest)
+			return
+		}
+
+		users, total, err := h.DB.ListUsers(filter)
 		if err != nil {
 			log.Error("failed to fetch users from database",
 				"error", err.Error(),
@@ -85,6 +143,7 @@ func (h *Handler) AdminListUsers() http.HandlerFunc {
 			return
 		}
 
+		setPaginationHeaders(w, page, total)
 		respondJSON(w, users)
 	}
 }
@@ -103,7 +162,9 @@ func (h *Handler) AdminListUsers() http.HandlerFunc {
 // @Failure 400 {object} ErrorResponse "Email, password, and role are required"
 // @Failure 400 {object} ErrorResponse "Password must be at least 8 characters"
 // @Failure 409 {object} ErrorResponse "Email already exists"
+// @Failure 409 {object} ErrorResponse "Display name already in use"
 // @Failure 500 {object} ErrorResponse "Failed to hash password"
+// @Failure 500 {object} ErrorResponse "Failed to check display name"
 // @Failure 500 {object} ErrorResponse "Failed to create user"
 // @Failure 500 {object} ErrorResponse "Failed to initialize user workspace"
 // @Router /admin/users [post]
@@ -149,6 +210,24 @@ func (h *Handler) AdminCreateUser() http.HandlerFunc {
 			return
 		}
 
+		if req.DisplayName != "" {
+			taken, err := h.displayNameTaken(req.DisplayName, 0)
+			if err != nil {
+				log.Error("failed to check display name",
+					"error", err.Error(),
+				)
+				respondError(w, "Failed to check display name", http.StatusInternalServerError)
+				return
+			}
+			if taken {
+				log.Warn("attempted to create user with existing display name",
+					"displayName", req.DisplayName,
+				)
+				respondError(w, "Display name already in use", http.StatusConflict)
+				return
+			}
+		}
+
 		if len(req.Password) < 8 {
 			log.Debug("password too short",
 				"passwordLength", len(req.Password),
@@ -279,7 +358,9 @@ func (h *Handler) AdminGetUser() http.HandlerFunc {
 // @Failure 400 {object} ErrorResponse "Invalid user ID"
 // @Failure 400 {object} ErrorResponse "Invalid request body"
 // @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 409 {object} ErrorResponse "Display name already in use"
 // @Failure 500 {object} ErrorResponse "Failed to hash password"
+// @Failure 500 {object} ErrorResponse "Failed to check display name"
 // @Failure 500 {object} ErrorResponse "Failed to update user"
 // @Router /admin/users/{userId} [put]
 func (h *Handler) AdminUpdateUser() http.HandlerFunc {
@@ -323,20 +404,43 @@ func (h *Handler) AdminUpdateUser() http.HandlerFunc {
 			return
 		}
 
-		// Track what's being updated for logging
+		// Track what's being updated for logging, only the changed columns are written
 		updates := make(map[string]any)
+		var fields []string
 
 		if req.Email != "" {
 			user.Email = req.Email
 			updates["email"] = req.Email
+			fields = append(fields, "email")
 		}
 		if req.DisplayName != "" {
+			if !strings.EqualFold(req.DisplayName, user.DisplayName) {
+				taken, err := h.displayNameTaken(req.DisplayName, user.ID)
+				if err != nil {
+					log.Error("failed to check display name",
+						"targetUserID", userID,
+						"error", err.Error(),
+					)
+					respondError(w, "Failed to check display name", http.StatusInternalServerError)
+					return
+				}
+				if taken {
+					log.Debug("display name change rejected - already in use",
+						"targetUserID", userID,
+						"displayName", req.DisplayName,
+					)
+					respondError(w, "Display name already in use", http.StatusConflict)
+					return
+				}
+			}
 			user.DisplayName = req.DisplayName
 			updates["displayName"] = req.DisplayName
+			fields = append(fields, "display_name")
 		}
 		if req.Role != "" {
 			user.Role = req.Role
 			updates["role"] = req.Role
+			fields = append(fields, "role")
 		}
 		if req.Theme != "" {
 			// Validate theme value, fallback to "dark" if invalid
@@ -348,6 +452,7 @@ func (h *Handler) AdminUpdateUser() http.HandlerFunc {
 			}
 			user.Theme = req.Theme
 			updates["theme"] = req.Theme
+			fields = append(fields, "theme")
 		}
 		if req.Password != "" {
 			hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -360,15 +465,18 @@ func (h *Handler) AdminUpdateUser() http.HandlerFunc {
 			}
 			user.PasswordHash = string(hashedPassword)
 			updates["passwordUpdated"] = true
+			fields = append(fields, "password_hash")
 		}
 
-		if err := h.DB.UpdateUser(user); err != nil {
-			log.Error("failed to update user in database",
-				"error", err.Error(),
-				"targetUserID", userID,
-			)
-			respondError(w, "Failed to update user", http.StatusInternalServerError)
-			return
+		if len(fields) > 0 {
+			if err := h.DB.UpdateUserFields(user, fields...); err != nil {
+				log.Error("failed to update user in database",
+					"error", err.Error(),
+					"targetUserID", userID,
+				)
+				respondError(w, "Failed to update user", http.StatusInternalServerError)
+				return
+			}
 		}
 
 		log.Debug("user updated",
@@ -465,7 +573,14 @@ func (h *Handler) AdminDeleteUser() http.HandlerFunc {
 // @Security CookieAuth
 // @ID adminListWorkspaces
 // @Produce json
+// @Param limit query int false "Maximum number of items to return"
+// @Param offset query int false "Number of items to skip"
+// @Param recompute query bool false "Recompute file stats with a full walk instead of serving cached ones"
+// @Param fresh query bool false "Deprecated alias of recompute"
 // @Success 200 {array} WorkspaceStats
+// @Header 200 {int} X-Pagination-Limit "Effective limit"
+// @Header 200 {int} X-Total-Count "Total number of items"
+// @Failure 400 {object} ErrorResponse "Invalid limit"
 // @Failure 500 {object} ErrorResponse "Failed to list workspaces"
 // @Failure 500 {object} ErrorResponse "Failed to get user"
 // @Failure 500 {object} ErrorResponse "Failed to get file stats"
@@ -482,7 +597,12 @@ func (h *Handler) AdminListWorkspaces() http.HandlerFunc {
 			"clientIP", r.RemoteAddr,
 		)
 
-		workspaces, err := h.DB.GetAllWorkspaces()
+		page, ok := h.parsePagination(w, r)
+		if !ok {
+			return
+		}
+
+		workspaces, err := h.DB.GetAllWorkspacesContext(r.Context())
 		if err != nil {
 			log.Error("failed to fetch workspaces from database",
 				"error", err.Error(),
@@ -490,13 +610,15 @@ func (h *Handler) AdminListWorkspaces() http.HandlerFunc {
 			respondError(w, "Failed to list workspaces", http.StatusInternalServerError)
 			return
 		}
+		workspaces = paginate(w, workspaces, page)
+		fresh := r.URL.Query().Get("fresh") == "true"
 
 		workspacesStats := make([]*WorkspaceStats, 0, len(workspaces))
 
 		for _, ws := range workspaces {
 			workspaceData := &WorkspaceStats{}
 
-			user, err := h.DB.GetUserByID(ws.UserID)
+			user, err := h.DB.GetUserByIDContext(r.Context(), ws.UserID)
 			if err != nil {
 				log.Error("failed to fetch user for workspace",
 					"error", err.Error(),
@@ -513,7 +635,7 @@ func (h *Handler) AdminListWorkspaces() http.HandlerFunc {
 			workspaceData.WorkspaceName = ws.Name
 			workspaceData.WorkspaceCreatedAt = ws.CreatedAt
 
-			fileStats, err := h.Storage.GetFileStats(ws.UserID, ws.ID)
+			fileStats, err := h.Storage.GetFileStats(ws.UserID, ws.ID, fresh)
 			if err != nil {
 				log.Error("failed to fetch file stats for workspace",
 					"error", err.Error(),
@@ -532,6 +654,153 @@ func (h *Handler) AdminListWorkspaces() http.HandlerFunc {
 	}
 }
 
+// AdminReindexWorkspace godoc
+// @Summary Reindex a workspace
+// @Description Rebuilds the caches of a workspace from disk, e.g. after files were changed outside of the application
+// @Tags Admin
+// @Security CookieAuth
+// @ID adminReindexWorkspace
+// @Produce json
+// @Param workspaceId path int true "Workspace ID"
+// @Success 200 {object} storage.ReindexResult
+// @Failure 400 {object} ErrorResponse "Invalid workspace ID"
+// @Failure 404 {object} ErrorResponse "Workspace not found"
+// @Failure 500 {object} ErrorResponse "Failed to reindex workspace"
+// @Router /admin/workspaces/{workspaceId}/reindex [post]
+func (h *Handler) AdminReindexWorkspace() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getAdminLogger().With(
+			"handler", "AdminReindexWorkspace",
+			"adminID", ctx.UserID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		workspaceID, err := strconv.Atoi(chi.URLParam(r, "workspaceId"))
+		if err != nil {
+			log.Debug("invalid workspace ID format",
+				"workspaceIDParam", chi.URLParam(r, "workspaceId"),
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid workspace ID", http.StatusBadRequest)
+			return
+		}
+
+		workspace, err := h.DB.GetWorkspaceByID(workspaceID)
+		if err != nil {
+			log.Debug("workspace not found",
+				"workspaceID", workspaceID,
+				"error", err.Error(),
+			)
+			respondError(w, "Workspace not found", http.StatusNotFound)
+			return
+		}
+
+		result, err := h.Storage.Reindex(workspace.UserID, workspace.ID)
+		if err != nil {
+			log.Error("failed to reindex workspace",
+				"workspaceID", workspace.ID,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to reindex workspace", http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("workspace reindexed",
+			"workspaceID", workspace.ID,
+			"durationMs", result.DurationMs,
+		)
+		respondJSON(w, result)
+	}
+}
+
+// AdminReindexAll godoc
+// @Summary Reindex all workspaces
+// @Description Rebuilds the caches of all workspaces from disk
+// @Tags Admin
+// @Security CookieAuth
+// @ID adminReindexAll
+// @Produce json
+// @Success 200 {object} ReindexResponse
+// @Failure 500 {object} ErrorResponse "Failed to list workspaces"
+// @Failure 500 {object} ErrorResponse "Failed to reindex workspace"
+// @Router /admin/reindex [post]
+func (h *Handler) AdminReindexAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getAdminLogger().With(
+			"handler", "AdminReindexAll",
+			"adminID", ctx.UserID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		workspaces, err := h.DB.GetAllWorkspaces()
+		if err != nil {
+			log.Error("failed to fetch workspaces from database",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to list workspaces", http.StatusInternalServerError)
+			return
+		}
+
+		start := time.Now()
+		response := ReindexResponse{
+			Workspaces: make([]*storage.ReindexResult, 0, len(workspaces)),
+		}
+		for _, ws := range workspaces {
+			result, err := h.Storage.Reindex(ws.UserID, ws.ID)
+			if err != nil {
+				log.Error("failed to reindex workspace",
+					"workspaceID", ws.ID,
+					"error", err.Error(),
+				)
+				respondError(w, "Failed to reindex workspace", http.StatusInternalServerError)
+				return
+			}
+			response.Workspaces = append(response.Workspaces, result)
+		}
+		response.DurationMs = time.Since(start).Milliseconds()
+
+		log.Info("all workspaces reindexed",
+			"workspaceCount", len(workspaces),
+			"durationMs", response.DurationMs,
+		)
+		respondJSON(w, response)
+	}
+}
+
+// AdminGetConfig godoc
+// @Summary Get server configuration
+// @Description Get the effective server configuration as an admin, with credentials and keys redacted
+// @Tags Admin
+// @Security CookieAuth
+// @ID adminGetConfig
+// @Produce json
+// @Success 200 {object} object "Redacted configuration"
+// @Router /admin/config [get]
+func (h *Handler) AdminGetConfig(config any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getAdminLogger().With(
+			"handler", "AdminGetConfig",
+			"adminID", ctx.UserID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		log.Debug("configuration requested")
+		respondJSON(w, config)
+	}
+}
+
 // AdminGetSystemStats godoc
 // @Summary Get system statistics
 // @Description Get system-wide statistics as an admin
@@ -539,6 +808,7 @@ func (h *Handler) AdminListWorkspaces() http.HandlerFunc {
 // @Security CookieAuth
 // @ID adminGetSystemStats
 // @Produce json
+// @Param fresh query bool false "Recompute file stats instead of serving cached ones"
 // @Success 200 {object} SystemStats
 // @Failure 500 {object} ErrorResponse "Failed to get user stats"
 // @Failure 500 {object} ErrorResponse "Failed to get file stats"
@@ -564,7 +834,9 @@ func (h *Handler) AdminGetSystemStats() http.HandlerFunc {
 			return
 		}
 
-		fileStats, err := h.Storage.GetTotalFileStats()
+		query := r.URL.Query()
+		recompute := query.Get("recompute") == "true" || query.Get("fresh") == "true"
+		fileStats, err := h.Storage.GetTotalFileStats(recompute)
 		if err != nil {
 			log.Error("failed to fetch file statistics",
 				"error", err.Error(),
@@ -581,3 +853,312 @@ func (h *Handler) AdminGetSystemStats() http.HandlerFunc {
 		respondJSON(w, stats)
 	}
 }
+
+// AdminTransferWorkspace godoc
+// @Summary Transfer a workspace
+// @Description Moves a workspace and its files to another user, e.g. when offboarding the owner.
+// @Description The git credentials of the previous owner are removed unless keepGitCredentials is set.
+// @Tags Admin
+// @Security CookieAuth
+// @ID adminTransferWorkspace
+// @Accept json
+// @Produce json
+// @Param workspaceId path int true "Workspace ID"
+// @Param body body TransferWorkspaceRequest true "Transfer request"
+// @Success 200 {object} models.Workspace
+// @Failure 400 {object} ErrorResponse "Invalid workspace ID"
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 400 {object} ErrorResponse "Workspace already belongs to the user"
+// @Failure 404 {object} ErrorResponse "Workspace not found"
+// @Failure 404 {object} ErrorResponse "Workspace owner not found"
+// @Failure 404 {object} ErrorResponse "Target user not found"
+// @Failure 409 {object} ErrorResponse "Target user already has a workspace with this name"
+// @Failure 500 {object} ErrorResponse "Failed to transfer workspace"
+// @Router /admin/workspaces/{workspaceId}/transfer [post]
+func (h *Handler) AdminTransferWorkspace() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getAdminLogger().With(
+			"handler", "AdminTransferWorkspace",
+			"adminID", ctx.UserID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		workspaceID, err := strconv.Atoi(chi.URLParam(r, "workspaceId"))
+		if err != nil {
+			log.Debug("invalid workspace ID format",
+				"workspaceIDParam", chi.URLParam(r, "workspaceId"),
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid workspace ID", http.StatusBadRequest)
+			return
+		}
+
+		var req TransferWorkspaceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Debug("failed to decode request body",
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		workspace, err := h.DB.GetWorkspaceByID(workspaceID)
+		if err != nil {
+			log.Debug("workspace not found",
+				"workspaceID", workspaceID,
+				"error", err.Error(),
+			)
+			respondError(w, "Workspace not found", http.StatusNotFound)
+			return
+		}
+		fromUserID := workspace.UserID
+
+		if _, err := h.DB.GetUserByID(fromUserID); err != nil {
+			log.Error("workspace owner not found",
+				"workspaceID", workspaceID,
+				"ownerID", fromUserID,
+				"error", err.Error(),
+			)
+			respondError(w, "Workspace owner not found", http.StatusNotFound)
+			return
+		}
+
+		if _, err := h.DB.GetUserByID(req.UserID); err != nil {
+			log.Debug("target user not found",
+				"targetUserID", req.UserID,
+				"error", err.Error(),
+			)
+			respondError(w, "Target user not found", http.StatusNotFound)
+			return
+		}
+
+		if req.UserID == fromUserID {
+			respondError(w, "Workspace already belongs to the user", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := h.DB.GetWorkspaceByName(req.UserID, workspace.Name); err == nil {
+			log.Debug("target user already has a workspace with the name",
+				"targetUserID", req.UserID,
+				"workspaceName", workspace.Name,
+			)
+			respondError(w, "Target user already has a workspace with this name", http.StatusConflict)
+			return
+		}
+		if h.workspaceNameTaken(req.UserID, workspace.NormalizedName, workspace.ID) {
+			log.Debug("target user already has a workspace with the normalized name",
+				"targetUserID", req.UserID,
+				"workspaceName", workspace.Name,
+			)
+			respondError(w, "Target user already has a workspace with this name", http.StatusConflict)
+			return
+		}
+
+		// The credentials of the additional remotes belong to the previous owner as well,
+		// they are only stored again if the credentials are kept
+		workspace.UserID = req.UserID
+		remotes := workspace.GitRemotes
+		workspace.GitRemotes = nil
+		if !req.KeepGitCredentials {
+			workspace.GitEnabled = false
+			workspace.GitUser = ""
+			workspace.GitToken = ""
+			workspace.GitSigningKey = ""
+			workspace.GitRemotes = []models.GitRemote{}
+			remotes = nil
+		}
+
+		// The database update is only committed once the files were moved
+		tx, err := h.DB.Begin()
+		if err != nil {
+			log.Error("failed to start database transaction",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to transfer workspace", http.StatusInternalServerError)
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+				log.Error("failed to rollback transaction",
+					"error", err.Error(),
+				)
+			}
+		}()
+
+		if err := h.DB.TransferWorkspaceTx(tx, workspace); err != nil {
+			log.Error("failed to update workspace owner",
+				"workspaceID", workspaceID,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to transfer workspace", http.StatusInternalServerError)
+			return
+		}
+
+		if err := h.Storage.MoveWorkspaceStorage(fromUserID, req.UserID, workspaceID); err != nil {
+			log.Error("failed to move workspace directory",
+				"workspaceID", workspaceID,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to transfer workspace", http.StatusInternalServerError)
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			log.Error("failed to commit transaction",
+				"error", err.Error(),
+			)
+			if err := h.Storage.MoveWorkspaceStorage(req.UserID, fromUserID, workspaceID); err != nil {
+				log.Error("failed to move workspace directory back",
+					"workspaceID", workspaceID,
+					"error", err.Error(),
+				)
+			}
+			respondError(w, "Failed to transfer workspace", http.StatusInternalServerError)
+			return
+		}
+		workspace.GitRemotes = remotes
+
+		// The repository moved with the files, only the client has to be set up for the new path
+		if workspace.GitEnabled {
+			if err := h.Storage.SetupGitRepo(
+				req.UserID,
+				workspace.ID,
+				workspace.GitURL,
+				workspace.GitUser,
+				workspace.GitToken,
+				workspace.GitCommitName,
+				workspace.GitCommitEmail,
+			); err != nil {
+				log.Warn("failed to setup git repository for the new owner",
+					"workspaceID", workspaceID,
+					"error", err.Error(),
+				)
+			} else if err := h.Storage.SetGitSigningKey(req.UserID, workspace.ID, workspace.GitSigningKey); err != nil {
+				log.Warn("failed to set git signing key for the new owner",
+					"workspaceID", workspaceID,
+					"error", err.Error(),
+				)
+			} else if err := h.Storage.SetGitRemotes(req.UserID, workspace.ID, gitRemotes(remotes)); err != nil {
+				log.Warn("failed to set additional git remotes for the new owner",
+					"workspaceID", workspaceID,
+					"error", err.Error(),
+				)
+			}
+		}
+
+		log.Info("workspace transferred",
+			"workspaceID", workspaceID,
+			"fromUserID", fromUserID,
+			"toUserID", req.UserID,
+			"keepGitCredentials", req.KeepGitCredentials,
+		)
+		respondJSON(w, workspace)
+	}
+}
+
+// AdminBatchUpdateWorkspaceSettings godoc
+// @Summary Update settings of many workspaces
+// @Description Applies a partial settings update to all workspaces, the workspaces of a user or the workspaces with the given IDs. Git credentials are never changed, git can only be disabled.
+// @Tags Admin
+// @Security CookieAuth
+// @ID adminBatchUpdateWorkspaceSettings
+// @Accept json
+// @Produce json
+// @Param body body BatchUpdateWorkspaceSettingsRequest true "Filter and settings to apply"
+// @Success 200 {object} BatchUpdateWorkspaceSettingsResponse
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 400 {object} ErrorResponse "Exactly one workspace filter is required"
+// @Failure 400 {object} ErrorResponse "No settings to update"
+// @Failure 400 {object} ErrorResponse "Invalid settings"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Failed to update workspace settings"
+// @Router /admin/workspaces/settings [patch]
+func (h *Handler) AdminBatchUpdateWorkspaceSettings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getAdminLogger().With(
+			"handler", "AdminBatchUpdateWorkspaceSettings",
+			"adminID", ctx.UserID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		var req BatchUpdateWorkspaceSettingsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Debug("failed to decode request body",
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		selected := 0
+		if req.Filter.All {
+			selected++
+		}
+		if req.Filter.UserID != 0 {
+			selected++
+		}
+		if len(req.Filter.WorkspaceIDs) > 0 {
+			selected++
+		}
+		if selected != 1 {
+			respondError(w, "Exactly one workspace filter is required", http.StatusBadRequest)
+			return
+		}
+
+		if req.Settings.IsEmpty() {
+			respondError(w, "No settings to update", http.StatusBadRequest)
+			return
+		}
+
+		if err := req.Settings.Validate(); err != nil {
+			log.Debug("invalid settings",
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid settings: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if req.Filter.UserID != 0 {
+			if _, err := h.DB.GetUserByID(req.Filter.UserID); err != nil {
+				log.Debug("user not found",
+					"userID", req.Filter.UserID,
+					"error", err.Error(),
+				)
+				respondError(w, "User not found", http.StatusNotFound)
+				return
+			}
+		}
+
+		filter := db.WorkspaceFilter{
+			UserID:       req.Filter.UserID,
+			WorkspaceIDs: req.Filter.WorkspaceIDs,
+		}
+		updated, err := h.DB.BatchUpdateWorkspaceSettings(filter, &req.Settings)
+		if err != nil {
+			log.Error("failed to update workspace settings",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to update workspace settings", http.StatusInternalServerError)
+			return
+		}
+
+		if req.Settings.GitEnabled != nil && !*req.Settings.GitEnabled {
+			for _, workspace := range updated {
+				h.Storage.DisableGitRepo(workspace.UserID, workspace.ID)
+			}
+		}
+
+		log.Info("workspace settings updated",
+			"workspaceCount", len(updated),
+		)
+		respondJSON(w, BatchUpdateWorkspaceSettingsResponse{Updated: len(updated)})
+	}
+}
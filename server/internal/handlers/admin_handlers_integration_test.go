@@ -6,10 +6,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"lemma/internal/handlers"
 	"lemma/internal/models"
+	"lemma/internal/storage"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -47,6 +53,106 @@ func testAdminHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			assert.Equal(t, http.StatusUnauthorized, rr.Code)
 		})
 
+		t.Run("list users pagination", func(t *testing.T) {
+			// Omitted limit uses the default page size
+			rr := h.makeRequest(t, http.MethodGet, "/api/v1/admin/users", nil, h.AdminTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "20", rr.Header().Get(handlers.HeaderPaginationLimit))
+			assert.Equal(t, "0", rr.Header().Get(handlers.HeaderPaginationOffset))
+
+			// Over-max limit is clamped
+			rr = h.makeRequest(t, http.MethodGet, "/api/v1/admin/users?limit=5000", nil, h.AdminTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "50", rr.Header().Get(handlers.HeaderPaginationLimit))
+
+			// Limit and offset select a page
+			rr = h.makeRequest(t, http.MethodGet, "/api/v1/admin/users?limit=1&offset=1", nil, h.AdminTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "1", rr.Header().Get(handlers.HeaderPaginationLimit))
+			assert.Equal(t, "1", rr.Header().Get(handlers.HeaderPaginationOffset))
+
+			var users []*models.User
+			err := json.NewDecoder(rr.Body).Decode(&users)
+			require.NoError(t, err)
+			assert.Len(t, users, 1)
+			total := rr.Header().Get(handlers.HeaderTotalCount)
+			assert.NotEqual(t, "", total)
+			assert.NotEqual(t, "1", total)
+
+			// Invalid limit
+			rr = h.makeRequest(t, http.MethodGet, "/api/v1/admin/users?limit=abc", nil, h.AdminTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+
+		t.Run("list users filters", func(t *testing.T) {
+			for _, req := range []handlers.CreateUserRequest{
+				{Email: "zeta.one@filter.test", DisplayName: "Alpha Filter", Password: "password123", Role: models.RoleEditor},
+				{Email: "zeta.two@filter.test", DisplayName: "Beta Filter", Password: "password123", Role: models.RoleViewer},
+				{Email: "gamma@filter.test", DisplayName: "Zeta Three", Password: "password123", Role: models.RoleViewer},
+			} {
+				rr := h.makeRequest(t, http.MethodPost, "/api/v1/admin/users", req, h.AdminTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+			}
+
+			listUsers := func(query string) ([]*models.User, string) {
+				rr := h.makeRequest(t, http.MethodGet, "/api/v1/admin/users?"+query, nil, h.AdminTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				var users []*models.User
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&users))
+				return users, rr.Header().Get(handlers.HeaderTotalCount)
+			}
+			emails := func(users []*models.User) []string {
+				result := make([]string, len(users))
+				for i, user := range users {
+					result[i] = user.Email
+				}
+				return result
+			}
+
+			// Search matches the email and the display name, ignoring case
+			users, total := listUsers("search=ZETA")
+			assert.Equal(t, "3", total)
+			assert.Equal(t, []string{"zeta.one@filter.test", "zeta.two@filter.test", "gamma@filter.test"}, emails(users))
+
+			users, total = listUsers("search=" + url.QueryEscape("beta filter"))
+			assert.Equal(t, "1", total)
+			assert.Equal(t, []string{"zeta.two@filter.test"}, emails(users))
+
+			// Like wildcards in the search are matched literally
+			users, total = listUsers("search=" + url.QueryEscape("%"))
+			assert.Equal(t, "0", total)
+			assert.Empty(t, users)
+
+			// Role filtering
+			users, total = listUsers("search=zeta&role=viewer")
+			assert.Equal(t, "2", total)
+			assert.Equal(t, []string{"zeta.two@filter.test", "gamma@filter.test"}, emails(users))
+
+			users, _ = listUsers("role=admin")
+			for _, user := range users {
+				assert.Equal(t, models.RoleAdmin, user.Role)
+			}
+			assert.True(t, containsUser(users, h.AdminTestUser.userModel), "Admin user not found in admin list")
+
+			// Pagination boundaries, the total counts all matching users
+			users, total = listUsers("search=zeta&limit=2")
+			assert.Equal(t, "3", total)
+			assert.Equal(t, []string{"zeta.one@filter.test", "zeta.two@filter.test"}, emails(users))
+
+			users, total = listUsers("search=zeta&limit=2&offset=2")
+			assert.Equal(t, "3", total)
+			assert.Equal(t, []string{"gamma@filter.test"}, emails(users))
+
+			users, total = listUsers("search=zeta&limit=2&offset=3")
+			assert.Equal(t, "3", total)
+			assert.NotNil(t, users)
+			assert.Empty(t, users)
+
+			// Invalid role
+			rr := h.makeRequest(t, http.MethodGet, "/api/v1/admin/users?role=owner", nil, h.AdminTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+
 		t.Run("create user", func(t *testing.T) {
 			createReq := handlers.CreateUserRequest{
 				Email:       "newuser@test.com",
@@ -201,6 +307,344 @@ func testAdminHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			rr = h.makeRequest(t, http.MethodGet, "/api/v1/admin/workspaces", nil, h.RegularTestUser)
 			assert.Equal(t, http.StatusForbidden, rr.Code)
 		})
+
+		t.Run("reindex workspace", func(t *testing.T) {
+			workspace := &models.Workspace{
+				UserID: h.RegularTestUser.session.UserID,
+				Name:   "Reindex Workspace",
+			}
+			rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(workspace))
+
+			getTotalFiles := func() int {
+				rr := h.makeRequest(t, http.MethodGet, "/api/v1/admin/workspaces", nil, h.AdminTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var workspaces []*handlers.WorkspaceStats
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&workspaces))
+				for _, ws := range workspaces {
+					if ws.WorkspaceID == workspace.ID {
+						return ws.TotalFiles
+					}
+				}
+				t.Fatalf("workspace %d not found in stats", workspace.ID)
+				return 0
+			}
+
+			assert.Equal(t, 0, getTotalFiles())
+
+			// Change files on disk outside of the application
+			workspacePath := h.Storage.GetWorkspacePath(workspace.UserID, workspace.ID)
+			err := os.WriteFile(filepath.Join(workspacePath, "external.md"), []byte("external"), 0644)
+			require.NoError(t, err)
+
+			// Cached stats don't see the external change until reindexed
+			assert.Equal(t, 0, getTotalFiles())
+
+			reindexURL := fmt.Sprintf("/api/v1/admin/workspaces/%d/reindex", workspace.ID)
+			rr = h.makeRequest(t, http.MethodPost, reindexURL, nil, h.AdminTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var result storage.ReindexResult
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&result))
+			assert.Equal(t, workspace.ID, result.WorkspaceID)
+			assert.NotEmpty(t, result.Caches)
+
+			assert.Equal(t, 1, getTotalFiles())
+
+			// Whole instance reindex
+			rr = h.makeRequest(t, http.MethodPost, "/api/v1/admin/reindex", nil, h.AdminTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var response handlers.ReindexResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			assert.NotEmpty(t, response.Workspaces)
+
+			// Unknown workspace
+			rr = h.makeRequest(t, http.MethodPost, "/api/v1/admin/workspaces/99999/reindex", nil, h.AdminTestUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+
+			// Non-admin
+			rr = h.makeRequest(t, http.MethodPost, reindexURL, nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusForbidden, rr.Code)
+		})
+
+		t.Run("batch update workspace settings", func(t *testing.T) {
+			owner := h.createTestUser(t, "batchsettings@test.com", "password123", models.RoleEditor)
+			other := h.createTestUser(t, "batchother@test.com", "password123", models.RoleEditor)
+
+			createWorkspace := func(t *testing.T, user *testUser, name string) *models.Workspace {
+				t.Helper()
+				workspace := &models.Workspace{Name: name, Theme: "dark"}
+				rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, user)
+				require.Equal(t, http.StatusOK, rr.Code)
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(workspace))
+				return workspace
+			}
+			first := createWorkspace(t, owner, "Batch One")
+			second := createWorkspace(t, owner, "Batch Two")
+			untouched := createWorkspace(t, other, "Batch Other")
+
+			theme := func(t *testing.T, workspaceID int) string {
+				t.Helper()
+				workspace, err := h.DB.GetWorkspaceByID(workspaceID)
+				require.NoError(t, err)
+				return workspace.Theme
+			}
+			light := "light"
+			disabled := false
+			enabled := true
+
+			t.Run("by workspace ids", func(t *testing.T) {
+				req := handlers.BatchUpdateWorkspaceSettingsRequest{
+					Filter:   handlers.WorkspaceSelection{WorkspaceIDs: []int{first.ID, second.ID}},
+					Settings: models.WorkspaceSettingsPatch{Theme: &light},
+				}
+				rr := h.makeRequest(t, http.MethodPatch, "/api/v1/admin/workspaces/settings", req, h.AdminTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var resp handlers.BatchUpdateWorkspaceSettingsResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+				assert.Equal(t, 2, resp.Updated)
+
+				assert.Equal(t, "light", theme(t, first.ID))
+				assert.Equal(t, "light", theme(t, second.ID))
+				assert.Equal(t, "dark", theme(t, untouched.ID))
+			})
+
+			t.Run("by user keeps git credentials", func(t *testing.T) {
+				gitWorkspace, err := h.DB.GetWorkspaceByID(first.ID)
+				require.NoError(t, err)
+				gitWorkspace.GitEnabled = true
+				gitWorkspace.GitURL = "https://example.com/repo.git"
+				gitWorkspace.GitUser = "git-user"
+				gitWorkspace.GitToken = "git-token"
+				gitWorkspace.GitAutoCommit = true
+				require.NoError(t, h.DB.UpdateWorkspace(gitWorkspace))
+
+				req := handlers.BatchUpdateWorkspaceSettingsRequest{
+					Filter:   handlers.WorkspaceSelection{UserID: owner.userModel.ID},
+					Settings: models.WorkspaceSettingsPatch{GitEnabled: &disabled, AutoSave: &enabled},
+				}
+				rr := h.makeRequest(t, http.MethodPatch, "/api/v1/admin/workspaces/settings", req, h.AdminTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				// The default workspace of the user is updated as well
+				ownerWorkspaces, err := h.DB.GetWorkspacesByUserID(owner.userModel.ID)
+				require.NoError(t, err)
+				var resp handlers.BatchUpdateWorkspaceSettingsResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+				assert.Equal(t, len(ownerWorkspaces), resp.Updated)
+
+				stored, err := h.DB.GetWorkspaceByID(first.ID)
+				require.NoError(t, err)
+				assert.False(t, stored.GitEnabled)
+				assert.False(t, stored.GitAutoCommit)
+				assert.True(t, stored.AutoSave)
+				assert.Equal(t, "light", stored.Theme)
+				assert.Equal(t, "git-user", stored.GitUser)
+				assert.Equal(t, "git-token", stored.GitToken)
+
+				stored, err = h.DB.GetWorkspaceByID(untouched.ID)
+				require.NoError(t, err)
+				assert.False(t, stored.AutoSave)
+			})
+
+			t.Run("all workspaces", func(t *testing.T) {
+				dark := "dark"
+				req := handlers.BatchUpdateWorkspaceSettingsRequest{
+					Filter:   handlers.WorkspaceSelection{All: true},
+					Settings: models.WorkspaceSettingsPatch{Theme: &dark},
+				}
+				rr := h.makeRequest(t, http.MethodPatch, "/api/v1/admin/workspaces/settings", req, h.AdminTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				workspaces, err := h.DB.GetAllWorkspaces()
+				require.NoError(t, err)
+				var resp handlers.BatchUpdateWorkspaceSettingsResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+				assert.Equal(t, len(workspaces), resp.Updated)
+				for _, workspace := range workspaces {
+					assert.Equal(t, "dark", workspace.Theme)
+				}
+			})
+
+			t.Run("invalid requests", func(t *testing.T) {
+				invalidTheme := "blue"
+				tests := []struct {
+					name     string
+					req      handlers.BatchUpdateWorkspaceSettingsRequest
+					wantCode int
+				}{
+					{
+						name:     "no filter",
+						req:      handlers.BatchUpdateWorkspaceSettingsRequest{Settings: models.WorkspaceSettingsPatch{Theme: &light}},
+						wantCode: http.StatusBadRequest,
+					},
+					{
+						name: "several filters",
+						req: handlers.BatchUpdateWorkspaceSettingsRequest{
+							Filter:   handlers.WorkspaceSelection{All: true, UserID: owner.userModel.ID},
+							Settings: models.WorkspaceSettingsPatch{Theme: &light},
+						},
+						wantCode: http.StatusBadRequest,
+					},
+					{
+						name:     "no settings",
+						req:      handlers.BatchUpdateWorkspaceSettingsRequest{Filter: handlers.WorkspaceSelection{All: true}},
+						wantCode: http.StatusBadRequest,
+					},
+					{
+						name: "invalid theme",
+						req: handlers.BatchUpdateWorkspaceSettingsRequest{
+							Filter:   handlers.WorkspaceSelection{All: true},
+							Settings: models.WorkspaceSettingsPatch{Theme: &invalidTheme},
+						},
+						wantCode: http.StatusBadRequest,
+					},
+					{
+						name: "enable git",
+						req: handlers.BatchUpdateWorkspaceSettingsRequest{
+							Filter:   handlers.WorkspaceSelection{All: true},
+							Settings: models.WorkspaceSettingsPatch{GitEnabled: &enabled},
+						},
+						wantCode: http.StatusBadRequest,
+					},
+					{
+						name: "unknown user",
+						req: handlers.BatchUpdateWorkspaceSettingsRequest{
+							Filter:   handlers.WorkspaceSelection{UserID: 99999},
+							Settings: models.WorkspaceSettingsPatch{Theme: &light},
+						},
+						wantCode: http.StatusNotFound,
+					},
+				}
+
+				for _, tt := range tests {
+					t.Run(tt.name, func(t *testing.T) {
+						rr := h.makeRequest(t, http.MethodPatch, "/api/v1/admin/workspaces/settings", tt.req, h.AdminTestUser)
+						assert.Equal(t, tt.wantCode, rr.Code)
+					})
+				}
+
+				// Non-admin
+				req := handlers.BatchUpdateWorkspaceSettingsRequest{
+					Filter:   handlers.WorkspaceSelection{All: true},
+					Settings: models.WorkspaceSettingsPatch{Theme: &light},
+				}
+				rr := h.makeRequest(t, http.MethodPatch, "/api/v1/admin/workspaces/settings", req, h.RegularTestUser)
+				assert.Equal(t, http.StatusForbidden, rr.Code)
+				assert.Equal(t, "dark", theme(t, first.ID))
+			})
+		})
+
+		t.Run("transfer workspace", func(t *testing.T) {
+			fromUser := h.createTestUser(t, "transferfrom@test.com", "password123", models.RoleEditor)
+			toUser := h.createTestUser(t, "transferto@test.com", "password123", models.RoleEditor)
+
+			createWorkspace := func(t *testing.T, name string) *models.Workspace {
+				t.Helper()
+				workspace := &models.Workspace{Name: name}
+				rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, fromUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(workspace))
+
+				// Git settings are written directly to avoid setting up a repository
+				workspace.GitEnabled = true
+				workspace.GitURL = "https://example.com/repo.git"
+				workspace.GitUser = "previous-owner"
+				workspace.GitToken = "secret-token"
+				workspace.GitCommitName = "Previous Owner"
+				workspace.GitCommitEmail = "previous@example.com"
+				workspace.GitSigningKey = "previous-signing-key"
+				workspace.GitRemotes = []models.GitRemote{
+					{Name: "mirror", URL: "https://example.com/mirror.git", User: "previous-owner", Token: "mirror-token"},
+				}
+				require.NoError(t, h.DB.UpdateWorkspace(workspace))
+				return workspace
+			}
+			transfer := func(workspaceID int, req handlers.TransferWorkspaceRequest, user *testUser) *httptest.ResponseRecorder {
+				return h.makeRequest(t, http.MethodPost, fmt.Sprintf("/api/v1/admin/workspaces/%d/transfer", workspaceID), req, user)
+			}
+
+			workspace := createWorkspace(t, "Transfer Workspace")
+			filesURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name) + "/files"
+			rr := h.makeRequestRaw(t, http.MethodPost, filesURL+"?file_path="+url.QueryEscape("notes/handover.md"), strings.NewReader("handover"), fromUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			t.Run("invalid requests", func(t *testing.T) {
+				rr := transfer(workspace.ID, handlers.TransferWorkspaceRequest{UserID: 99999}, h.AdminTestUser)
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+
+				rr = transfer(99999, handlers.TransferWorkspaceRequest{UserID: toUser.userModel.ID}, h.AdminTestUser)
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+
+				rr = transfer(workspace.ID, handlers.TransferWorkspaceRequest{UserID: fromUser.userModel.ID}, h.AdminTestUser)
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+				rr = transfer(workspace.ID, handlers.TransferWorkspaceRequest{UserID: toUser.userModel.ID}, h.RegularTestUser)
+				assert.Equal(t, http.StatusForbidden, rr.Code)
+			})
+
+			t.Run("successful transfer", func(t *testing.T) {
+				rr := transfer(workspace.ID, handlers.TransferWorkspaceRequest{UserID: toUser.userModel.ID}, h.AdminTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var transferred models.Workspace
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&transferred))
+				assert.Equal(t, toUser.userModel.ID, transferred.UserID)
+
+				stored, err := h.DB.GetWorkspaceByID(workspace.ID)
+				require.NoError(t, err)
+				assert.Equal(t, toUser.userModel.ID, stored.UserID)
+				assert.Equal(t, workspace.Name, stored.Name)
+
+				// The credentials of the previous owner are removed
+				assert.False(t, stored.GitEnabled)
+				assert.Empty(t, stored.GitToken)
+				assert.Empty(t, stored.GitUser)
+				assert.Empty(t, stored.GitSigningKey)
+				assert.Empty(t, stored.GitRemotes)
+				assert.Equal(t, workspace.GitURL, stored.GitURL)
+
+				// The files moved with the workspace
+				contentURL := filesURL + "/content?file_path=" + url.QueryEscape("notes/handover.md")
+				rr = h.makeRequest(t, http.MethodGet, contentURL, nil, toUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "handover", rr.Body.String())
+
+				rr = h.makeRequest(t, http.MethodGet, contentURL, nil, fromUser)
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+			})
+
+			t.Run("name taken by target user", func(t *testing.T) {
+				duplicate := createWorkspace(t, "Transfer Workspace")
+				rr := transfer(duplicate.ID, handlers.TransferWorkspaceRequest{UserID: toUser.userModel.ID}, h.AdminTestUser)
+				assert.Equal(t, http.StatusConflict, rr.Code)
+
+				stored, err := h.DB.GetWorkspaceByID(duplicate.ID)
+				require.NoError(t, err)
+				assert.Equal(t, fromUser.userModel.ID, stored.UserID)
+			})
+
+			t.Run("keep git credentials", func(t *testing.T) {
+				gitWorkspace := createWorkspace(t, "Git Transfer Workspace")
+				req := handlers.TransferWorkspaceRequest{UserID: toUser.userModel.ID, KeepGitCredentials: true}
+				rr := transfer(gitWorkspace.ID, req, h.AdminTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				stored, err := h.DB.GetWorkspaceByID(gitWorkspace.ID)
+				require.NoError(t, err)
+				assert.Equal(t, toUser.userModel.ID, stored.UserID)
+				assert.True(t, stored.GitEnabled)
+				assert.Equal(t, "secret-token", stored.GitToken)
+				assert.Equal(t, "previous-owner", stored.GitUser)
+				assert.Equal(t, "previous-signing-key", stored.GitSigningKey)
+				require.Len(t, stored.GitRemotes, 1)
+				assert.Equal(t, "mirror-token", stored.GitRemotes[0].Token)
+			})
+		})
 	})
 
 	t.Run("system stats", func(t *testing.T) {
@@ -226,11 +670,50 @@ func testAdminHandlers(t *testing.T, dbConfig DatabaseConfig) {
 		assert.GreaterOrEqual(t, stats.ActiveUsers, 2)     // Our test users should be active
 		assert.GreaterOrEqual(t, stats.TotalFiles, 0)
 		assert.GreaterOrEqual(t, stats.TotalSize, int64(0))
+		assert.False(t, stats.CachedAt.IsZero())
+
+		// Cached stats are served until recomputed
+		cachedAt := stats.CachedAt
+		rr = h.makeRequest(t, http.MethodGet, "/api/v1/admin/stats", nil, h.AdminTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&stats))
+		assert.True(t, stats.CachedAt.Equal(cachedAt))
+
+		rr = h.makeRequest(t, http.MethodGet, "/api/v1/admin/stats?recompute=true", nil, h.AdminTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&stats))
+		assert.True(t, stats.CachedAt.After(cachedAt))
+
+		cachedAt = stats.CachedAt
+		rr = h.makeRequest(t, http.MethodGet, "/api/v1/admin/stats?fresh=true", nil, h.AdminTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&stats))
+		assert.True(t, stats.CachedAt.After(cachedAt))
 
 		// Test with non-admin session
 		rr = h.makeRequest(t, http.MethodGet, "/api/v1/admin/stats", nil, h.RegularTestUser)
 		assert.Equal(t, http.StatusForbidden, rr.Code)
 	})
+
+	t.Run("config", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/admin/config", nil, h.AdminTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var config map[string]any
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&config))
+		assert.Equal(t, "[REDACTED]", config["AdminPassword"])
+		assert.Equal(t, "[REDACTED]", config["EncryptionKey"])
+		assert.Equal(t, "[REDACTED]", config["JWTSigningKey"])
+		assert.Equal(t, "index.md", config["DefaultHomeFile"])
+		assert.Equal(t, float64(50), config["MaxPageSize"])
+		assert.NotContains(t, rr.Body.String(), "admin123")
+
+		rr = h.makeRequest(t, http.MethodGet, "/api/v1/admin/config", nil, h.RegularTestUser)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, "/api/v1/admin/config", nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
 }
 
 // Helper function to check if a user exists in a slice of users
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -27,6 +28,14 @@ type LoginResponse struct {
 	ExpiresAt time.Time    `json:"expiresAt,omitempty"`
 }
 
+// SessionResponse describes a login session of the current user
+type SessionResponse struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Current   bool      `json:"current"`
+}
+
 func getAuthLogger() logging.Logger {
 	return getHandlersLogger().WithGroup("auth")
 }
@@ -43,6 +52,7 @@ func getAuthLogger() logging.Logger {
 // @Failure 400 {object} ErrorResponse "Invalid request body"
 // @Failure 400 {object} ErrorResponse "Email and password are required"
 // @Failure 401 {object} ErrorResponse "Invalid credentials"
+// @Failure 403 {object} ErrorResponse "HTTPS is required"
 // @Failure 500 {object} ErrorResponse "Failed to create session"
 // @Failure 500 {object} ErrorResponse "Failed to generate CSRF token"
 // @Router /auth/login [post]
@@ -53,6 +63,11 @@ func (h *Handler) Login(authManager auth.SessionManager, cookieService auth.Cook
 			"clientIP", r.RemoteAddr,
 		)
 
+		if err := cookieService.CheckTransport(r); err != nil {
+			respondError(w, "HTTPS is required", http.StatusForbidden)
+			return
+		}
+
 		var req LoginRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.Debug("failed to decode request body",
@@ -173,9 +188,7 @@ func (h *Handler) Logout(authManager auth.SessionManager, cookieService auth.Coo
 			return
 		}
 
-		http.SetCookie(w, cookieService.InvalidateCookie("access_token"))
-		http.SetCookie(w, cookieService.InvalidateCookie("refresh_token"))
-		http.SetCookie(w, cookieService.InvalidateCookie("csrf_token"))
+		invalidateAuthCookies(w, cookieService)
 
 		log.Info("user logged out successfully",
 			"sessionID", sessionCookie.Value,
@@ -184,9 +197,52 @@ func (h *Handler) Logout(authManager auth.SessionManager, cookieService auth.Coo
 	}
 }
 
+// LogoutAll godoc
+// @Summary Logout everywhere
+// @Description Invalidates all sessions of the user, including the current one
+// @Tags auth
+// @ID logoutAll
+// @Security CookieAuth
+// @Success 204 "No Content"
+// @Failure 500 {object} ErrorResponse "Failed to invalidate sessions"
+// @Router /auth/logout-all [post]
+func (h *Handler) LogoutAll(authManager auth.SessionManager, cookieService auth.CookieManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getAuthLogger().With(
+			"handler", "LogoutAll",
+			"userID", ctx.UserID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		if err := authManager.InvalidateAllUserSessions(ctx.UserID); err != nil {
+			log.Error("failed to invalidate sessions",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to invalidate sessions", http.StatusInternalServerError)
+			return
+		}
+
+		invalidateAuthCookies(w, cookieService)
+
+		log.Info("user logged out of all sessions")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// invalidateAuthCookies clears the cookies set on login
+func invalidateAuthCookies(w http.ResponseWriter, cookieService auth.CookieManager) {
+	http.SetCookie(w, cookieService.InvalidateCookie("access_token"))
+	http.SetCookie(w, cookieService.InvalidateCookie("refresh_token"))
+	http.SetCookie(w, cookieService.InvalidateCookie("csrf_token"))
+}
+
 // RefreshToken godoc
 // @Summary Refresh token
-// @Description Refreshes the access token using the refresh token
+// @Description Exchanges the refresh token for a new access token and a new refresh token, the used refresh token is invalidated
 // @Tags auth
 // @ID refreshToken
 // @Accept json
@@ -195,6 +251,7 @@ func (h *Handler) Logout(authManager auth.SessionManager, cookieService auth.Coo
 // @Header 200 {string} X-CSRF-Token "New CSRF token"
 // @Failure 400 {object} ErrorResponse "Refresh token required"
 // @Failure 401 {object} ErrorResponse "Invalid refresh token"
+// @Failure 403 {object} ErrorResponse "HTTPS is required"
 // @Failure 500 {object} ErrorResponse "Failed to generate CSRF token"
 // @Router /auth/refresh [post]
 func (h *Handler) RefreshToken(authManager auth.SessionManager, cookieService auth.CookieManager) http.HandlerFunc {
@@ -204,6 +261,11 @@ func (h *Handler) RefreshToken(authManager auth.SessionManager, cookieService au
 			"clientIP", r.RemoteAddr,
 		)
 
+		if err := cookieService.CheckTransport(r); err != nil {
+			respondError(w, "HTTPS is required", http.StatusForbidden)
+			return
+		}
+
 		refreshCookie, err := r.Cookie("refresh_token")
 		if err != nil {
 			log.Debug("missing refresh token cookie",
@@ -213,7 +275,7 @@ func (h *Handler) RefreshToken(authManager auth.SessionManager, cookieService au
 			return
 		}
 
-		accessToken, err := authManager.RefreshSession(refreshCookie.Value)
+		accessToken, refreshToken, err := authManager.RefreshSession(refreshCookie.Value)
 		if err != nil {
 			log.Error("failed to refresh session",
 				"error", err.Error(),
@@ -233,6 +295,7 @@ func (h *Handler) RefreshToken(authManager auth.SessionManager, cookieService au
 		csrfTokenString := hex.EncodeToString(csrfToken)
 
 		http.SetCookie(w, cookieService.GenerateAccessTokenCookie(accessToken))
+		http.SetCookie(w, cookieService.GenerateRefreshTokenCookie(refreshToken))
 		http.SetCookie(w, cookieService.GenerateCSRFCookie(csrfTokenString))
 
 		w.Header().Set("X-CSRF-Token", csrfTokenString)
@@ -274,3 +337,100 @@ func (h *Handler) GetCurrentUser() http.HandlerFunc {
 		respondJSON(w, user)
 	}
 }
+
+// ListSessions godoc
+// @Summary List sessions
+// @Description Returns the active sessions of the current user, newest first
+// @Tags auth
+// @ID listSessions
+// @Security CookieAuth
+// @Produce json
+// @Success 200 {array} SessionResponse
+// @Failure 500 {object} ErrorResponse "Failed to list sessions"
+// @Router /auth/sessions [get]
+func (h *Handler) ListSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getAuthLogger().With(
+			"handler", "ListSessions",
+			"userID", ctx.UserID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		sessions, err := h.DB.GetSessionsByUserID(ctx.UserID)
+		if err != nil {
+			log.Error("failed to fetch sessions",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to list sessions", http.StatusInternalServerError)
+			return
+		}
+
+		response := make([]SessionResponse, 0, len(sessions))
+		for _, session := range sessions {
+			response = append(response, SessionResponse{
+				ID:        session.ID,
+				CreatedAt: session.CreatedAt,
+				ExpiresAt: session.ExpiresAt,
+				Current:   session.ID == ctx.SessionID,
+			})
+		}
+
+		respondJSON(w, response)
+	}
+}
+
+// RevokeSession godoc
+// @Summary Revoke session
+// @Description Logs out one session of the current user. Revoking the current session also clears the auth cookies.
+// @Tags auth
+// @ID revokeSession
+// @Security CookieAuth
+// @Param sessionId path string true "Session ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} ErrorResponse "Session not found"
+// @Failure 500 {object} ErrorResponse "Failed to revoke session"
+// @Router /auth/sessions/{sessionId} [delete]
+func (h *Handler) RevokeSession(cookieService auth.CookieManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		sessionID := chi.URLParam(r, "sessionId")
+		log := getAuthLogger().With(
+			"handler", "RevokeSession",
+			"userID", ctx.UserID,
+			"sessionID", sessionID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		// Sessions of other users are reported as missing so their IDs cannot be probed
+		session, err := h.DB.GetSessionByID(sessionID)
+		if err != nil || session.UserID != ctx.UserID {
+			log.Debug("session not found")
+			respondError(w, "Session not found", http.StatusNotFound)
+			return
+		}
+
+		if err := h.DB.DeleteSession(session.ID); err != nil {
+			log.Error("failed to delete session",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to revoke session", http.StatusInternalServerError)
+			return
+		}
+
+		if session.ID == ctx.SessionID {
+			invalidateAuthCookies(w, cookieService)
+		}
+
+		log.Info("session revoked",
+			"current", session.ID == ctx.SessionID,
+		)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
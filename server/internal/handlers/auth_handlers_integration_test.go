@@ -160,6 +160,7 @@ func testAuthHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			// Verify new cookies
 			cookies := rr.Result().Cookies()
 			var foundAccessToken, foundCSRF bool
+			var newRefreshToken string
 			for _, cookie := range cookies {
 				switch cookie.Name {
 				case "access_token":
@@ -169,11 +170,33 @@ func testAuthHandlers(t *testing.T, dbConfig DatabaseConfig) {
 					foundCSRF = true
 					assert.Equal(t, 900, cookie.MaxAge)
 				case "refresh_token":
-					t.Error("refresh token should not be renewed")
+					newRefreshToken = cookie.Value
 				}
 			}
 			assert.True(t, foundAccessToken, "new access_token cookie not found")
 			assert.True(t, foundCSRF, "new csrf_token cookie not found")
+			require.NotEmpty(t, newRefreshToken, "new refresh_token cookie not found")
+			assert.NotEqual(t, h.RegularTestUser.session.RefreshToken, newRefreshToken)
+
+			// The old refresh token is rejected once it has been used
+			req = h.newRequest(t, http.MethodPost, "/api/v1/auth/refresh", nil)
+			h.addAuthCookies(t, req, h.RegularTestUser)
+			h.addCSRFCookie(t, req)
+			rr = h.executeRequest(req)
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+			h.RegularTestUser.session.RefreshToken = newRefreshToken
+			req = h.newRequest(t, http.MethodPost, "/api/v1/auth/refresh", nil)
+			h.addAuthCookies(t, req, h.RegularTestUser)
+			h.addCSRFCookie(t, req)
+			rr = h.executeRequest(req)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			for _, cookie := range rr.Result().Cookies() {
+				if cookie.Name == "refresh_token" {
+					h.RegularTestUser.session.RefreshToken = cookie.Value
+				}
+			}
 		})
 
 		t.Run("refresh token edge cases", func(t *testing.T) {
@@ -300,6 +323,103 @@ func testAuthHandlers(t *testing.T, dbConfig DatabaseConfig) {
 		})
 	})
 
+	t.Run("logout everywhere", func(t *testing.T) {
+		user := h.createTestUser(t, "logoutall@test.com", "password123", models.RoleEditor)
+		otherSession, otherToken, err := h.SessionManager.CreateSession(user.userModel.ID, string(user.userModel.Role))
+		require.NoError(t, err)
+		other := &testUser{userModel: user.userModel, accessToken: otherToken, session: otherSession}
+
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/auth/me", nil, other)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodPost, "/api/v1/auth/logout-all", nil, user)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		cookies := rr.Result().Cookies()
+		assert.Len(t, cookies, 3)
+		for _, cookie := range cookies {
+			assert.True(t, cookie.MaxAge < 0, "cookie should be invalidated")
+		}
+
+		// The access tokens of all sessions are rejected, not only the one used to log out
+		for _, session := range []*testUser{user, other} {
+			rr = h.makeRequest(t, http.MethodGet, "/api/v1/auth/me", nil, session)
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		}
+
+		// Sessions of other users are kept
+		rr = h.makeRequest(t, http.MethodGet, "/api/v1/auth/me", nil, h.AdminTestUser)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("sessions", func(t *testing.T) {
+		sessionsUser := h.createTestUser(t, "sessions@test.com", "password123", models.RoleEditor)
+		otherSession, _, err := h.SessionManager.CreateSession(sessionsUser.userModel.ID, string(sessionsUser.userModel.Role))
+		require.NoError(t, err)
+
+		t.Run("list sessions", func(t *testing.T) {
+			rr := h.makeRequest(t, http.MethodGet, "/api/v1/auth/sessions", nil, sessionsUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.NotContains(t, rr.Body.String(), sessionsUser.session.RefreshToken)
+
+			var sessions []handlers.SessionResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&sessions))
+			require.Len(t, sessions, 2)
+
+			current := map[string]bool{}
+			for _, session := range sessions {
+				current[session.ID] = session.Current
+				assert.False(t, session.ExpiresAt.IsZero())
+			}
+			assert.Equal(t, map[string]bool{sessionsUser.session.ID: true, otherSession.ID: false}, current)
+		})
+
+		t.Run("revoke session of another user", func(t *testing.T) {
+			path := "/api/v1/auth/sessions/" + h.AdminTestUser.session.ID
+			rr := h.makeRequest(t, http.MethodDelete, path, nil, sessionsUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+
+			// The session is still valid
+			rr = h.makeRequest(t, http.MethodGet, "/api/v1/auth/me", nil, h.AdminTestUser)
+			assert.Equal(t, http.StatusOK, rr.Code)
+		})
+
+		t.Run("revoke unknown session", func(t *testing.T) {
+			rr := h.makeRequest(t, http.MethodDelete, "/api/v1/auth/sessions/unknown", nil, sessionsUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		})
+
+		t.Run("revoke other session", func(t *testing.T) {
+			rr := h.makeRequest(t, http.MethodDelete, "/api/v1/auth/sessions/"+otherSession.ID, nil, sessionsUser)
+			require.Equal(t, http.StatusNoContent, rr.Code)
+			assert.Empty(t, rr.Result().Cookies())
+
+			_, err := h.DB.GetSessionByID(otherSession.ID)
+			assert.Error(t, err)
+
+			rr = h.makeRequest(t, http.MethodGet, "/api/v1/auth/sessions", nil, sessionsUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			var sessions []handlers.SessionResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&sessions))
+			require.Len(t, sessions, 1)
+			assert.Equal(t, sessionsUser.session.ID, sessions[0].ID)
+		})
+
+		t.Run("revoke current session", func(t *testing.T) {
+			rr := h.makeRequest(t, http.MethodDelete, "/api/v1/auth/sessions/"+sessionsUser.session.ID, nil, sessionsUser)
+			require.Equal(t, http.StatusNoContent, rr.Code)
+
+			cookies := rr.Result().Cookies()
+			assert.Len(t, cookies, 3)
+			for _, cookie := range cookies {
+				assert.True(t, cookie.MaxAge < 0, "cookie should be invalidated")
+			}
+
+			rr = h.makeRequest(t, http.MethodGet, "/api/v1/auth/me", nil, sessionsUser)
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		})
+	})
+
 	t.Run("get current user", func(t *testing.T) {
 
 		getTestUser := h.createTestUser(t, "testgetuser@test.com", "password123", models.RoleEditor)
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+
+	"lemma/internal/context"
+	"lemma/internal/logging"
+	"lemma/internal/models"
+	"lemma/internal/storage"
+)
+
+// BootstrapResponse contains everything the client needs to render the app on startup
+type BootstrapResponse struct {
+	User              *models.User        `json:"user"`
+	Workspaces        []*models.Workspace `json:"workspaces"`
+	LastWorkspaceName string              `json:"lastWorkspaceName"`
+	Workspace         *models.Workspace   `json:"workspace"`
+	Files             []storage.FileNode  `json:"files"`
+}
+
+func getBootstrapLogger() logging.Logger {
+	return getHandlersLogger().WithGroup("bootstrap")
+}
+
+// GetBootstrap godoc
+// @Summary Get initial app state
+// @Description Returns the current user, their workspaces, the last opened workspace and its file tree in one response.
+// @Description If the last opened workspace no longer exists, the oldest workspace is used instead and becomes the last workspace.
+// @Tags auth
+// @ID getBootstrap
+// @Security CookieAuth
+// @Produce json
+// @Success 200 {object} BootstrapResponse
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Failed to list workspaces"
+// @Failure 500 {object} ErrorResponse "Failed to get last workspace"
+// @Failure 500 {object} ErrorResponse "Failed to list files"
+// @Router /bootstrap [get]
+func (h *Handler) GetBootstrap() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getBootstrapLogger().With(
+			"handler", "GetBootstrap",
+			"userID", ctx.UserID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		user, err := h.DB.GetUserByID(ctx.UserID)
+		if err != nil {
+			log.Error("failed to fetch user",
+				"error", err.Error(),
+			)
+			respondError(w, "User not found", http.StatusNotFound)
+			return
+		}
+
+		workspaces, err := h.DB.GetWorkspacesByUserID(ctx.UserID)
+		if err != nil {
+			log.Error("failed to fetch workspaces from database",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to list workspaces", http.StatusInternalServerError)
+			return
+		}
+
+		response := &BootstrapResponse{
+			User:       user,
+			Workspaces: workspaces,
+			Files:      []storage.FileNode{},
+		}
+
+		if len(workspaces) == 0 {
+			respondJSON(w, response)
+			return
+		}
+
+		response.Workspace, err = h.DB.GetDefaultWorkspace(ctx.UserID)
+		if err != nil {
+			log.Error("failed to resolve last workspace",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to get last workspace", http.StatusInternalServerError)
+			return
+		}
+		response.LastWorkspaceName = response.Workspace.Name
+
+		files, err := h.Storage.ListFilesRecursively(ctx.UserID, response.Workspace.ID, false)
+		if err != nil {
+			log.Error("failed to list files in workspace",
+				"error", err.Error(),
+				"workspaceID", response.Workspace.ID,
+			)
+			respondError(w, "Failed to list files", http.StatusInternalServerError)
+			return
+		}
+		if files != nil {
+			response.Files = files
+		}
+
+		respondJSON(w, response)
+	}
+}
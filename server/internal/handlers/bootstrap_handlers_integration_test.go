@@ -0,0 +1,91 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapHandlers_Integration(t *testing.T) {
+	runWithDatabases(t, testBootstrapHandlers)
+}
+
+func testBootstrapHandlers(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarness(t, dbConfig)
+	defer h.teardown(t)
+
+	getBootstrap := func(t *testing.T, user *testUser) handlers.BootstrapResponse {
+		t.Helper()
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/bootstrap", nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response handlers.BootstrapResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		return response
+	}
+
+	t.Run("default workspace", func(t *testing.T) {
+		response := getBootstrap(t, h.RegularTestUser)
+
+		require.NotNil(t, response.User)
+		assert.Equal(t, h.RegularTestUser.userModel.ID, response.User.ID)
+		assert.Equal(t, h.RegularTestUser.userModel.Email, response.User.Email)
+		assert.Empty(t, response.User.PasswordHash)
+
+		require.NotEmpty(t, response.Workspaces)
+		require.NotNil(t, response.Workspace)
+		assert.Equal(t, response.LastWorkspaceName, response.Workspace.Name)
+		assert.NotNil(t, response.Files)
+	})
+
+	t.Run("last workspace with files", func(t *testing.T) {
+		workspace := &models.Workspace{Name: "Bootstrap Workspace", Theme: "light"}
+		rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(workspace))
+
+		filesURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name) + "/files"
+		rr = h.makeRequest(t, http.MethodPost, filesURL+"?file_path="+url.QueryEscape("notes/first.md"), "content", h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		lastReq := struct {
+			WorkspaceName string `json:"workspaceName"`
+		}{WorkspaceName: workspace.Name}
+		rr = h.makeRequest(t, http.MethodPut, "/api/v1/workspaces/_op/last", lastReq, h.RegularTestUser)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		response := getBootstrap(t, h.RegularTestUser)
+		assert.Equal(t, workspace.Name, response.LastWorkspaceName)
+		require.NotNil(t, response.Workspace)
+		assert.Equal(t, workspace.ID, response.Workspace.ID)
+		assert.Equal(t, "light", response.Workspace.Theme)
+
+		require.Len(t, response.Files, 1)
+		assert.Equal(t, "notes", response.Files[0].Name)
+		require.Len(t, response.Files[0].Children, 1)
+		assert.Equal(t, "notes/first.md", response.Files[0].Children[0].Path)
+	})
+
+	t.Run("only includes own data", func(t *testing.T) {
+		response := getBootstrap(t, h.AdminTestUser)
+		assert.Equal(t, h.AdminTestUser.userModel.ID, response.User.ID)
+		for _, workspace := range response.Workspaces {
+			assert.Equal(t, h.AdminTestUser.userModel.ID, workspace.UserID)
+			assert.NotEqual(t, "Bootstrap Workspace", workspace.Name)
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/bootstrap", nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
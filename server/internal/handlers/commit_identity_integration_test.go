@@ -0,0 +1,100 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"lemma/internal/app"
+	"lemma/internal/git"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitIdentity_Integration(t *testing.T) {
+	runWithDatabases(t, testCommitIdentity)
+}
+
+func testCommitIdentity(t *testing.T, dbConfig DatabaseConfig) {
+	autoCommitWorkspace := func(name string) *models.Workspace {
+		return &models.Workspace{
+			Name:          name,
+			GitEnabled:    true,
+			GitURL:        "https://github.com/test/repo.git",
+			GitUser:       "testuser",
+			GitToken:      "testtoken",
+			GitAutoCommit: true,
+		}
+	}
+
+	t.Run("identity required", func(t *testing.T) {
+		h := setupTestHarness(t, dbConfig)
+		defer h.teardown(t)
+
+		user := h.createTestUser(t, "identity@test.com", "password123", models.RoleEditor)
+
+		t.Run("create without identity", func(t *testing.T) {
+			rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", autoCommitWorkspace("No Identity"), user)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Contains(t, rr.Body.String(), "Commit author name and email are required")
+		})
+
+		t.Run("update without identity", func(t *testing.T) {
+			workspace := autoCommitWorkspace("With Identity")
+			workspace.GitCommitName = "Workspace Author"
+			workspace.GitCommitEmail = "workspace@example.com"
+			rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, user)
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(workspace))
+
+			workspace.GitCommitEmail = ""
+			rr = h.makeRequest(t, http.MethodPut, "/api/v1/workspaces/"+url.PathEscape(workspace.Name), workspace, user)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Contains(t, rr.Body.String(), "Commit author name and email are required")
+		})
+
+		t.Run("auto-commit disabled", func(t *testing.T) {
+			workspace := autoCommitWorkspace("Manual Commits")
+			workspace.GitAutoCommit = false
+			rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, user)
+			assert.Equal(t, http.StatusOK, rr.Code)
+		})
+	})
+
+	t.Run("user fallback", func(t *testing.T) {
+		h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+			config.GitCommitIdentityFallback = true
+		})
+		defer h.teardown(t)
+
+		user := h.createTestUser(t, "fallback@test.com", "password123", models.RoleEditor)
+
+		t.Run("commit as user", func(t *testing.T) {
+			workspace := autoCommitWorkspace("Fallback Identity")
+			rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, user)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			h.MockGit.Reset()
+			rr = h.makeRequest(t, http.MethodPost, "/api/v1/workspaces/"+url.PathEscape(workspace.Name)+"/git/commit", map[string]string{"message": "fallback"}, user)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, git.Author{Name: user.userModel.DisplayName, Email: "fallback@test.com"}, h.MockGit.GetLastAuthor())
+		})
+
+		t.Run("workspace identity takes precedence", func(t *testing.T) {
+			workspace := autoCommitWorkspace("Partial Identity")
+			workspace.GitCommitName = "Workspace Author"
+			rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, user)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			h.MockGit.Reset()
+			rr = h.makeRequest(t, http.MethodPost, "/api/v1/workspaces/"+url.PathEscape(workspace.Name)+"/git/commit", map[string]string{"message": "partial"}, user)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, git.Author{Name: "Workspace Author", Email: "fallback@test.com"}, h.MockGit.GetLastAuthor())
+		})
+	})
+}
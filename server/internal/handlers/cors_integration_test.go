@@ -0,0 +1,63 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"lemma/internal/app"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS_Integration(t *testing.T) {
+	runWithDatabases(t, testCORS)
+}
+
+func testCORS(t *testing.T, dbConfig DatabaseConfig) {
+	const origin = "https://app.example.com"
+	h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+		config.CORSOrigins = []string{origin}
+	})
+	defer h.teardown(t)
+
+	preflight := func(t *testing.T, path, method, headers string) *http.Response {
+		t.Helper()
+		requestHeaders := map[string]string{
+			"Origin":                        origin,
+			"Access-Control-Request-Method": method,
+		}
+		if headers != "" {
+			requestHeaders["Access-Control-Request-Headers"] = headers
+		}
+		return h.makeRequestRaw(t, http.MethodOptions, path, nil, nil, requestHeaders).Result()
+	}
+
+	t.Run("patch is allowed", func(t *testing.T) {
+		resp := preflight(t, "/api/v1/admin/workspaces/settings", http.MethodPatch, "")
+		assert.Equal(t, origin, resp.Header.Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, strings.Split(resp.Header.Get("Access-Control-Allow-Methods"), ", "), http.MethodPatch)
+	})
+
+	t.Run("conditional saves", func(t *testing.T) {
+		resp := preflight(t, "/api/v1/workspaces/Main/files?file_path=note.md", http.MethodPost, "If-Match")
+		assert.Equal(t, origin, resp.Header.Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "If-Match", resp.Header.Get("Access-Control-Allow-Headers"))
+
+		// The ETag sent back with If-Match has to be readable by the client
+		resp = h.makeRequestRaw(t, http.MethodGet, "/api/v1/workspaces/Main/files", nil, h.RegularTestUser, map[string]string{
+			"Origin": origin,
+		}).Result()
+		assert.Contains(t, strings.Split(resp.Header.Get("Access-Control-Expose-Headers"), ", "), "Etag")
+	})
+
+	t.Run("unknown origin", func(t *testing.T) {
+		resp := h.makeRequestRaw(t, http.MethodOptions, "/api/v1/admin/workspaces/settings", nil, nil, map[string]string{
+			"Origin":                        "https://evil.example.com",
+			"Access-Control-Request-Method": http.MethodPatch,
+		}).Result()
+		assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+	})
+}
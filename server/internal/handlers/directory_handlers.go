@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+
+	"lemma/internal/context"
+	"lemma/internal/models"
+	"lemma/internal/storage"
+)
+
+// CreateDirectory godoc
+// @Summary Create directory
+// @Description Creates an empty directory in the user's workspace together with its missing parents
+// @Tags files
+// @ID createDirectory
+// @Security CookieAuth
+// @Param workspace_name path string true "Workspace name"
+// @Param path query string true "Directory path"
+// @Success 204 "No Content - Directory created successfully"
+// @Failure 400 {object} ErrorResponse "Invalid directory path"
+// @Failure 409 {object} ErrorResponse "Directory already exists"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
+// @Failure 500 {object} ErrorResponse "Failed to create directory"
+// @Router /workspaces/{workspace_name}/dirs [post]
+func (h *Handler) CreateDirectory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "CreateDirectory",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		dirPath := r.URL.Query().Get("path")
+		decodedPath, err := url.PathUnescape(dirPath)
+		if err != nil || decodedPath == "" {
+			log.Debug("invalid directory path",
+				"dirPath", dirPath,
+			)
+			respondError(w, "Invalid directory path", http.StatusBadRequest)
+			return
+		}
+
+		err = h.Storage.CreateDirectory(ctx.UserID, ctx.Workspace.ID, decodedPath)
+		if err != nil {
+			if storage.IsWorkspacePinnedError(err) {
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
+				return
+			}
+
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid directory path attempted",
+					"dirPath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid directory path", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, os.ErrExist) {
+				respondError(w, "Directory already exists", http.StatusConflict)
+				return
+			}
+
+			log.Error("failed to create directory",
+				"dirPath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to create directory", http.StatusInternalServerError)
+			return
+		}
+
+		h.recordActivity(&models.Activity{
+			WorkspaceID: ctx.Workspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityDirCreated,
+			Path:        decodedPath,
+		})
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// DeleteDirectory godoc
+// @Summary Delete directory
+// @Description Moves a directory with all its content to the trash of the workspace.
+// @Description The workspace root and the .git directory cannot be deleted.
+// @Tags files
+// @ID deleteDirectory
+// @Security CookieAuth
+// @Param workspace_name path string true "Workspace name"
+// @Param path query string true "Directory path"
+// @Success 204 "No Content - Directory deleted successfully"
+// @Failure 400 {object} ErrorResponse "Invalid directory path"
+// @Failure 400 {object} ErrorResponse "Directory is too large to delete"
+// @Failure 404 {object} ErrorResponse "Directory not found"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
+// @Failure 500 {object} ErrorResponse "Failed to delete directory"
+// @Router /workspaces/{workspace_name}/dirs [delete]
+func (h *Handler) DeleteDirectory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "DeleteDirectory",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		dirPath := r.URL.Query().Get("path")
+		decodedPath, err := url.PathUnescape(dirPath)
+		if err != nil || decodedPath == "" {
+			log.Debug("invalid directory path",
+				"dirPath", dirPath,
+			)
+			respondError(w, "Invalid directory path", http.StatusBadRequest)
+			return
+		}
+
+		err = h.Storage.DeleteDirectory(ctx.UserID, ctx.Workspace.ID, decodedPath)
+		if err != nil {
+			if storage.IsWorkspacePinnedError(err) {
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
+				return
+			}
+
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid directory path attempted",
+					"dirPath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid directory path", http.StatusBadRequest)
+				return
+			}
+
+			if storage.IsTreeLimitError(err) {
+				log.Debug("directory exceeds tree limits",
+					"dirPath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Directory is too large to delete", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				respondError(w, "Directory not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("failed to delete directory",
+				"dirPath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to delete directory", http.StatusInternalServerError)
+			return
+		}
+
+		h.recordActivity(&models.Activity{
+			WorkspaceID: ctx.Workspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityDirDeleted,
+			Path:        decodedPath,
+		})
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
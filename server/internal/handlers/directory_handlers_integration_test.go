@@ -0,0 +1,88 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"lemma/internal/models"
+	"lemma/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectoryHandlers_Integration(t *testing.T) {
+	runWithDatabases(t, testDirectoryHandlers)
+}
+
+func testDirectoryHandlers(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarness(t, dbConfig)
+	defer h.teardown(t)
+
+	user := h.createTestUser(t, "dirs@test.com", "password123", models.RoleEditor)
+
+	workspace := &models.Workspace{Name: "Directories Workspace"}
+	rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, user)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	workspaceURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name)
+	dirsURL := func(path string) string {
+		return workspaceURL + "/dirs?path=" + url.QueryEscape(path)
+	}
+	listFiles := func(t *testing.T) []storage.FileNode {
+		t.Helper()
+		rr := h.makeRequest(t, http.MethodGet, workspaceURL+"/files", nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var nodes []storage.FileNode
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&nodes))
+		return nodes
+	}
+
+	t.Run("create nested directory", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodPost, dirsURL("projects/drafts"), nil, user)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		nodes := listFiles(t)
+		require.Len(t, nodes, 1)
+		assert.Equal(t, "projects", nodes[0].Name)
+		require.Len(t, nodes[0].Children, 1)
+		assert.Equal(t, "drafts", nodes[0].Children[0].Name)
+
+		rr = h.makeRequest(t, http.MethodPost, dirsURL("projects/drafts"), nil, user)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("delete populated directory", func(t *testing.T) {
+		rr := h.makeRequestRaw(t, http.MethodPost, workspaceURL+"/files?file_path="+url.QueryEscape("projects/drafts/note.md"), strings.NewReader("draft"), user)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodDelete, dirsURL("projects"), nil, user)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		assert.Empty(t, listFiles(t))
+
+		rr = h.makeRequest(t, http.MethodDelete, dirsURL("projects"), nil, user)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		// The directory can be restored from the trash with its content
+		rr = h.makeRequest(t, http.MethodPost, workspaceURL+"/trash/restore?path=projects", nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		rr = h.makeRequest(t, http.MethodGet, workspaceURL+"/files/content?file_path="+url.QueryEscape("projects/drafts/note.md"), nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "draft", rr.Body.String())
+	})
+
+	t.Run("rejected paths", func(t *testing.T) {
+		for _, path := range []string{"../../etc", "..", ".", "", ".git"} {
+			rr := h.makeRequest(t, http.MethodPost, dirsURL(path), nil, user)
+			assert.Equal(t, http.StatusBadRequest, rr.Code, "create %q", path)
+
+			rr = h.makeRequest(t, http.MethodDelete, dirsURL(path), nil, user)
+			assert.Equal(t, http.StatusBadRequest, rr.Code, "delete %q", path)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"lemma/internal/app"
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueDisplayNames_Integration(t *testing.T) {
+	runWithDatabases(t, testUniqueDisplayNames)
+}
+
+func testUniqueDisplayNames(t *testing.T, dbConfig DatabaseConfig) {
+	t.Run("enabled", func(t *testing.T) {
+		h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+			config.UniqueDisplayNames = true
+		})
+		defer h.teardown(t)
+
+		// Both test users are created directly in the database as "Test User"
+		t.Run("create user with taken name", func(t *testing.T) {
+			createReq := handlers.CreateUserRequest{
+				Email:       "taken@test.com",
+				DisplayName: "test user",
+				Password:    "password123",
+				Role:        models.RoleEditor,
+			}
+			rr := h.makeRequest(t, http.MethodPost, "/api/v1/admin/users", createReq, h.AdminTestUser)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+		})
+
+		var created models.User
+		t.Run("create user with unique name", func(t *testing.T) {
+			createReq := handlers.CreateUserRequest{
+				Email:       "unique@test.com",
+				DisplayName: "Unique Name",
+				Password:    "password123",
+				Role:        models.RoleEditor,
+			}
+			rr := h.makeRequest(t, http.MethodPost, "/api/v1/admin/users", createReq, h.AdminTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+		})
+
+		t.Run("admin update to taken name", func(t *testing.T) {
+			updateReq := handlers.UpdateUserRequest{DisplayName: "Test User"}
+			path := fmt.Sprintf("/api/v1/admin/users/%d", created.ID)
+			rr := h.makeRequest(t, http.MethodPut, path, updateReq, h.AdminTestUser)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+
+			// Keeping the own name, with different case, is allowed
+			updateReq = handlers.UpdateUserRequest{DisplayName: "UNIQUE NAME"}
+			rr = h.makeRequest(t, http.MethodPut, path, updateReq, h.AdminTestUser)
+			assert.Equal(t, http.StatusOK, rr.Code)
+		})
+
+		t.Run("update profile to taken name", func(t *testing.T) {
+			displayName := "unique name"
+			updateReq := handlers.UpdateProfileRequest{DisplayName: &displayName}
+			rr := h.makeRequest(t, http.MethodPut, "/api/v1/profile", updateReq, h.RegularTestUser)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+
+			displayName = "Regular User"
+			updateReq = handlers.UpdateProfileRequest{DisplayName: &displayName}
+			rr = h.makeRequest(t, http.MethodPut, "/api/v1/profile", updateReq, h.RegularTestUser)
+			assert.Equal(t, http.StatusOK, rr.Code)
+		})
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		h := setupTestHarness(t, dbConfig)
+		defer h.teardown(t)
+
+		createReq := handlers.CreateUserRequest{
+			Email:       "duplicate@test.com",
+			DisplayName: "Test User",
+			Password:    "password123",
+			Role:        models.RoleEditor,
+		}
+		rr := h.makeRequest(t, http.MethodPost, "/api/v1/admin/users", createReq, h.AdminTestUser)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		displayName := "Test User"
+		updateReq := handlers.UpdateProfileRequest{DisplayName: &displayName}
+		rr = h.makeRequest(t, http.MethodPut, "/api/v1/profile", updateReq, h.RegularTestUser)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// lookupEncoding returns the text encoding with the given name, e.g. windows-1252 or shift_jis.
+// A nil encoding is returned for an empty name and for UTF-8, whose content is passed through unchanged.
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if enc == unicode.UTF8 {
+		return nil, nil
+	}
+	return enc, nil
+}
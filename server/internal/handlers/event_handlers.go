@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"lemma/internal/context"
+	"lemma/internal/events"
+	"lemma/internal/logging"
+)
+
+// eventKeepAliveInterval is how often a comment is sent on idle event streams
+const eventKeepAliveInterval = 15 * time.Second
+
+// streamPath matches the workspace event stream and websocket routes, which stay open as long as the client is connected,
+// and the runtime profiles, which record for as long as the client asks
+var streamPath = regexp.MustCompile(`^/api/v1/(workspaces/[^/]+/(events|ws)|admin/debug/pprof(/.*)?)$`)
+
+// SkipStreams wraps middleware, e.g. a request timeout, so that it is not applied to the workspace
+// event streams and websockets, or to the runtime profiles
+func SkipStreams(middleware func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		wrapped := middleware(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The escaped path keeps a slash in a workspace name from matching a stream route
+			if streamPath.MatchString(r.URL.EscapedPath()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			wrapped.ServeHTTP(w, r)
+		})
+	}
+}
+
+func getEventsLogger() logging.Logger {
+	return getHandlersLogger().WithGroup("events")
+}
+
+// StreamEvents godoc
+// @Summary Stream workspace events
+// @Description Streams the activity of the workspace as server-sent events, the event name is the activity type.
+// @Description The number of concurrent streams per user and workspace is limited, clients are expected to reconnect when a stream ends.
+// @Tags workspaces
+// @ID streamEvents
+// @Security CookieAuth
+// @Produce text/event-stream
+// @Param workspace_name path string true "Workspace name"
+// @Success 200 {string} string "Event stream"
+// @Failure 429 {object} ErrorResponse "Too many event streams"
+// @Failure 500 {object} ErrorResponse "Streaming not supported"
+// @Router /workspaces/{workspace_name}/events [get]
+func (h *Handler) StreamEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getEventsLogger().With(
+			"handler", "StreamEvents",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		flusher, ok := w.(http.Flusher)
+		if !ok || h.Events == nil {
+			log.Error("event streaming not supported")
+			respondError(w, "Streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		sub, err := h.Events.Subscribe(ctx.UserID, ctx.Workspace.ID)
+		if errors.Is(err, events.ErrTooManySubscriptions) {
+			log.Debug("event stream limit reached")
+			respondError(w, "Too many event streams", http.StatusTooManyRequests)
+			return
+		}
+		if err != nil {
+			log.Error("failed to subscribe to events",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to subscribe to events", http.StatusInternalServerError)
+			return
+		}
+		defer sub.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		keepAlive := time.NewTicker(eventKeepAliveInterval)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-sub.Done():
+				log.Debug("event stream closed by the hub")
+				return
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+			case event := <-sub.Events():
+				data, err := json.Marshal(event.Data)
+				if err != nil {
+					log.Error("failed to encode event",
+						"type", event.Type,
+						"error", err.Error(),
+					)
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,175 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"lemma/internal/app"
+	"lemma/internal/events"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventHandlers_Integration(t *testing.T) {
+	runWithDatabases(t, testEventHandlers)
+}
+
+func testEventHandlers(t *testing.T, dbConfig DatabaseConfig) {
+	setup := func(t *testing.T, policy events.LimitPolicy) (*testHarness, *httptest.Server, string) {
+		h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+			config.MaxEventStreamsPerUser = 2
+			config.EventStreamLimitPolicy = policy
+		})
+		t.Cleanup(func() { h.teardown(t) })
+
+		server := httptest.NewServer(h.Server.Router())
+		t.Cleanup(server.Close)
+
+		workspace := &models.Workspace{Name: "Events Workspace"}
+		rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		return h, server, "/api/v1/workspaces/" + url.PathEscape(workspace.Name)
+	}
+
+	// openStream connects to the event stream, the stream is closed when the test ends
+	openStream := func(t *testing.T, h *testHarness, server *httptest.Server, path string) *http.Response {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path+"/events", nil)
+		require.NoError(t, err)
+		h.addAuthCookies(t, req, h.RegularTestUser)
+
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	t.Run("receive events", func(t *testing.T) {
+		h, server, workspaceURL := setup(t, events.PolicyReject)
+
+		resp := openStream(t, h, server, workspaceURL)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		rr := h.makeRequestRaw(t, http.MethodPost, workspaceURL+"/files?file_path="+url.QueryEscape("live.md"), strings.NewReader("content"), h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		lines := make(chan string, 64)
+		go func() {
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+			close(lines)
+		}()
+
+		var received []string
+		for len(received) < 2 {
+			select {
+			case line := <-lines:
+				if line != "" {
+					received = append(received, line)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for event, received %v", received)
+			}
+		}
+		assert.Equal(t, "event: "+string(models.ActivityFileSaved), received[0])
+		assert.Contains(t, received[1], `"path":"live.md"`)
+	})
+
+	t.Run("stream outlives request timeout", func(t *testing.T) {
+		h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+			config.RequestTimeout = 100 * time.Millisecond
+		})
+		t.Cleanup(func() { h.teardown(t) })
+		server := httptest.NewServer(h.Server.Router())
+		t.Cleanup(server.Close)
+
+		workspace := &models.Workspace{Name: "Long Stream Workspace"}
+		rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+		workspaceURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name)
+
+		resp := openStream(t, h, server, workspaceURL)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// Well past the deadline of regular requests
+		time.Sleep(500 * time.Millisecond)
+
+		rr = h.makeRequestRaw(t, http.MethodPost, workspaceURL+"/files?file_path="+url.QueryEscape("late.md"), strings.NewReader("content"), h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		lines := make(chan string, 64)
+		go func() {
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+			close(lines)
+		}()
+
+		for {
+			select {
+			case line, ok := <-lines:
+				require.True(t, ok, "stream closed before the event was received")
+				if strings.Contains(line, `"path":"late.md"`) {
+					return
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for event")
+			}
+		}
+	})
+
+	t.Run("reject over limit", func(t *testing.T) {
+		h, server, workspaceURL := setup(t, events.PolicyReject)
+
+		for i := 0; i < 2; i++ {
+			resp := openStream(t, h, server, workspaceURL)
+			require.Equal(t, http.StatusOK, resp.StatusCode, fmt.Sprintf("stream %d", i))
+		}
+
+		resp := openStream(t, h, server, workspaceURL)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	})
+
+	t.Run("close oldest over limit", func(t *testing.T) {
+		h, server, workspaceURL := setup(t, events.PolicyCloseOldest)
+
+		oldest := openStream(t, h, server, workspaceURL)
+		require.Equal(t, http.StatusOK, oldest.StatusCode)
+		for i := 0; i < 2; i++ {
+			resp := openStream(t, h, server, workspaceURL)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+
+		// The oldest stream ends once it has been replaced
+		done := make(chan error, 1)
+		go func() {
+			_, err := io.ReadAll(oldest.Body)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected the oldest stream to be closed")
+		}
+	})
+}
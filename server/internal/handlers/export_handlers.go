@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"lemma/internal/context"
+	"lemma/internal/logging"
+	"lemma/internal/models"
+	"lemma/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// UserDataExport is the profile document included in a user data export
+type UserDataExport struct {
+	ExportedAt  time.Time           `json:"exportedAt"`
+	User        *models.User        `json:"user"`
+	Preferences json.RawMessage     `json:"preferences"`
+	Workspaces  []*models.Workspace `json:"workspaces"`
+}
+
+// exportNameReplacer replaces the characters of workspace names that would create extra directories in an archive
+var exportNameReplacer = strings.NewReplacer("/", "_", "\\", "_")
+
+// exportDirName returns the directory of a workspace in a data export
+func exportDirName(workspace *models.Workspace) string {
+	name := exportNameReplacer.Replace(workspace.Name)
+	if name == "" || name == "." || name == ".." {
+		name = fmt.Sprintf("workspace-%d", workspace.ID)
+	}
+	return path.Join("workspaces", name)
+}
+
+// ExportUserData godoc
+// @Summary Export user data
+// @Description Downloads a zip archive with the profile, preferences and workspace settings of the user as profile.json and the files of all their workspaces. Git credentials are not included.
+// @Tags users
+// @ID exportUserData
+// @Security CookieAuth
+// @Produce application/zip
+// @Success 200 {file} file "Zip archive"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Failed to export user data"
+// @Router /profile/export [get]
+func (h *Handler) ExportUserData() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getProfileLogger().With(
+			"handler", "ExportUserData",
+			"userID", ctx.UserID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		h.writeUserDataExport(w, ctx.UserID, log)
+	}
+}
+
+// AdminExportUserData godoc
+// @Summary Export data of a user
+// @Description Downloads the data export of a user as an admin, see /profile/export
+// @Tags Admin
+// @Security CookieAuth
+// @ID adminExportUserData
+// @Produce application/zip
+// @Param userId path int true "User ID"
+// @Success 200 {file} file "Zip archive"
+// @Failure 400 {object} ErrorResponse "Invalid user ID"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Failed to export user data"
+// @Router /admin/users/{userId}/export [get]
+func (h *Handler) AdminExportUserData() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getAdminLogger().With(
+			"handler", "AdminExportUserData",
+			"adminID", ctx.UserID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		userID, err := strconv.Atoi(chi.URLParam(r, "userId"))
+		if err != nil {
+			log.Debug("invalid user ID format",
+				"userIDParam", chi.URLParam(r, "userId"),
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		h.writeUserDataExport(w, userID, log.With("targetUserID", userID))
+	}
+}
+
+// writeUserDataExport streams the data export of a user as a zip archive.
+// Errors can only be reported before the archive is started, later ones are logged.
+func (h *Handler) writeUserDataExport(w http.ResponseWriter, userID int, log logging.Logger) {
+	user, err := h.DB.GetUserByID(userID)
+	if err != nil {
+		log.Debug("user not found",
+			"error", err.Error(),
+		)
+		respondError(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	preferences, err := h.DB.GetUserPreferences(userID)
+	if err != nil {
+		log.Error("failed to fetch preferences from database",
+			"error", err.Error(),
+		)
+		respondError(w, "Failed to export user data", http.StatusInternalServerError)
+		return
+	}
+	if preferences == "" {
+		preferences = "{}"
+	}
+
+	workspaces, err := h.DB.GetWorkspacesByUserID(userID)
+	if err != nil {
+		log.Error("failed to fetch workspaces from database",
+			"error", err.Error(),
+		)
+		respondError(w, "Failed to export user data", http.StatusInternalServerError)
+		return
+	}
+	for _, workspace := range workspaces {
+		workspace.GitToken = ""
+	}
+
+	profile, err := json.MarshalIndent(UserDataExport{
+		ExportedAt:  time.Now().UTC(),
+		User:        user,
+		Preferences: json.RawMessage(preferences),
+		Workspaces:  workspaces,
+	}, "", "  ")
+	if err != nil {
+		log.Error("failed to encode profile",
+			"error", err.Error(),
+		)
+		respondError(w, "Failed to export user data", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="lemma-export-%d.zip"`, userID))
+
+	zw := zip.NewWriter(w)
+	profileWriter, err := zw.Create("profile.json")
+	if err == nil {
+		_, err = profileWriter.Write(profile)
+	}
+	if err != nil {
+		log.Error("failed to write profile to archive",
+			"error", err.Error(),
+		)
+		return
+	}
+
+	for _, workspace := range workspaces {
+		if err := h.Storage.ExportWorkspace(userID, workspace.ID, zw, exportDirName(workspace)); err != nil {
+			log.Error("failed to export workspace",
+				"workspaceID", workspace.ID,
+				"error", err.Error(),
+			)
+			return
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		log.Error("failed to finish archive",
+			"error", err.Error(),
+		)
+		return
+	}
+
+	log.Info("user data exported",
+		"workspaceCount", len(workspaces),
+	)
+}
+
+// ExportWorkspace godoc
+// @Summary Export workspace files
+// @Description Downloads a zip archive of the files of the workspace, or of the directory or file given by path.
+// @Description Paths in the archive are relative to path. The .git directory is not included.
+// @Tags workspaces
+// @ID exportWorkspace
+// @Security CookieAuth
+// @Produce application/zip
+// @Param workspace_name path string true "Workspace name"
+// @Param path query string false "Directory or file to export, the whole workspace if empty"
+// @Param format query string false "Archive format, only zip is supported" default(zip)
+// @Success 200 {file} file "Zip archive"
+// @Failure 400 {object} ErrorResponse "Unsupported format"
+// @Failure 400 {object} ErrorResponse "Invalid path"
+// @Failure 404 {object} ErrorResponse "Path not found"
+// @Failure 500 {object} ErrorResponse "Failed to export workspace"
+// @Router /workspaces/{workspace_name}/export [get]
+func (h *Handler) ExportWorkspace() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getWorkspaceLogger().With(
+			"handler", "ExportWorkspace",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		if format := r.URL.Query().Get("format"); format != "" && format != "zip" {
+			respondError(w, "Unsupported format", http.StatusBadRequest)
+			return
+		}
+
+		subPath := r.URL.Query().Get("path")
+		filename := exportNameReplacer.Replace(ctx.Workspace.Name)
+		if base := path.Base(filepath.ToSlash(subPath)); subPath != "" && base != "." && base != "/" {
+			filename += "-" + base
+		}
+
+		zw := &attachmentWriter{
+			w:           w,
+			contentType: "application/zip",
+			filename:    filename + ".zip",
+		}
+		err := h.Storage.WriteZip(ctx.UserID, ctx.Workspace.ID, subPath, zw)
+		if err == nil || zw.started {
+			h.recordFileAccess(r, ctx, models.FileAccessExport, subPath)
+		}
+		if err == nil {
+			return
+		}
+
+		// Once the archive is being streamed errors can only be logged
+		if zw.started {
+			log.Error("failed to stream archive",
+				"path", subPath,
+				"error", err.Error(),
+			)
+			return
+		}
+
+		if storage.IsPathValidationError(err) {
+			log.Error("invalid path attempted",
+				"path", subPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+
+		if os.IsNotExist(err) {
+			respondError(w, "Path not found", http.StatusNotFound)
+			return
+		}
+
+		log.Error("failed to export workspace",
+			"path", subPath,
+			"error", err.Error(),
+		)
+		respondError(w, "Failed to export workspace", http.StatusInternalServerError)
+	}
+}
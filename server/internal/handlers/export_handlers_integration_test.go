@@ -0,0 +1,169 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportHandlers_Integration(t *testing.T) {
+	runWithDatabases(t, testExportHandlers)
+}
+
+func testExportHandlers(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarness(t, dbConfig)
+	defer h.teardown(t)
+
+	user := h.createTestUser(t, "export@test.com", "password123", models.RoleEditor)
+	other := h.createTestUser(t, "exportother@test.com", "password123", models.RoleEditor)
+
+	workspace := &models.Workspace{Name: "Export Workspace"}
+	rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, user)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(workspace))
+
+	// Git settings are written directly to avoid setting up a repository
+	workspace.GitEnabled = true
+	workspace.GitURL = "https://example.com/repo.git"
+	workspace.GitUser = "export-user"
+	workspace.GitToken = "export-secret-token"
+	workspace.GitCommitName = "Export User"
+	workspace.GitCommitEmail = "export@example.com"
+	require.NoError(t, h.DB.UpdateWorkspace(workspace))
+
+	saveFile := func(t *testing.T, testUser *testUser, workspaceName, filePath, content string) {
+		t.Helper()
+		fileURL := fmt.Sprintf("/api/v1/workspaces/%s/files?file_path=%s", url.PathEscape(workspaceName), url.QueryEscape(filePath))
+		rr := h.makeRequestRaw(t, http.MethodPost, fileURL, strings.NewReader(content), testUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+	saveFile(t, user, workspace.Name, "notes/first.md", "first note")
+	saveFile(t, user, workspace.Name, "second.md", "second note")
+
+	otherWorkspace := &models.Workspace{Name: "Other Export Workspace"}
+	rr = h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", otherWorkspace, other)
+	require.Equal(t, http.StatusOK, rr.Code)
+	saveFile(t, other, otherWorkspace.Name, "private.md", "not yours")
+
+	readArchive := func(t *testing.T, r io.Reader) map[string]string {
+		t.Helper()
+		body, err := io.ReadAll(r)
+		require.NoError(t, err)
+		zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		require.NoError(t, err)
+
+		files := make(map[string]string)
+		for _, file := range zr.File {
+			rc, err := file.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(rc)
+			rc.Close()
+			require.NoError(t, err)
+			files[file.Name] = string(content)
+		}
+		return files
+	}
+
+	assertExport := func(t *testing.T, files map[string]string) {
+		t.Helper()
+		assert.Equal(t, "first note", files["workspaces/Export Workspace/notes/first.md"])
+		assert.Equal(t, "second note", files["workspaces/Export Workspace/second.md"])
+
+		require.Contains(t, files, "profile.json")
+		var profile handlers.UserDataExport
+		require.NoError(t, json.Unmarshal([]byte(files["profile.json"]), &profile))
+		assert.Equal(t, user.userModel.ID, profile.User.ID)
+		assert.Equal(t, "export@test.com", profile.User.Email)
+
+		var names []string
+		for _, ws := range profile.Workspaces {
+			assert.Equal(t, user.userModel.ID, ws.UserID)
+			assert.Empty(t, ws.GitToken)
+			names = append(names, ws.Name)
+		}
+		assert.Contains(t, names, "Export Workspace")
+
+		for name, content := range files {
+			assert.NotContains(t, name, "Other Export Workspace")
+			assert.NotContains(t, content, "not yours")
+			assert.NotContains(t, content, "export-secret-token")
+			assert.NotContains(t, content, "password")
+		}
+	}
+
+	t.Run("export own data", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/profile/export", nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/zip", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+
+		assertExport(t, readArchive(t, rr.Body))
+	})
+
+	t.Run("admin export", func(t *testing.T) {
+		path := fmt.Sprintf("/api/v1/admin/users/%d/export", user.userModel.ID)
+		rr := h.makeRequest(t, http.MethodGet, path, nil, h.AdminTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		assertExport(t, readArchive(t, rr.Body))
+
+		rr = h.makeRequest(t, http.MethodGet, path, nil, other)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, "/api/v1/admin/users/99999/export", nil, h.AdminTestUser)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, "/api/v1/admin/users/invalid/export", nil, h.AdminTestUser)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("export workspace", func(t *testing.T) {
+		saveFile(t, user, workspace.Name, "notes/deep/third.md", "third note")
+		exportURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name) + "/export"
+
+		rr := h.makeRequest(t, http.MethodGet, exportURL, nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/zip", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), `filename="Export Workspace.zip"`)
+		assert.Equal(t, map[string]string{
+			"notes/first.md":      "first note",
+			"notes/deep/third.md": "third note",
+			"second.md":           "second note",
+		}, readArchive(t, rr.Body))
+
+		rr = h.makeRequest(t, http.MethodGet, exportURL+"?format=zip&path=notes", nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), `filename="Export Workspace-notes.zip"`)
+		assert.Equal(t, map[string]string{
+			"first.md":      "first note",
+			"deep/third.md": "third note",
+		}, readArchive(t, rr.Body))
+
+		rr = h.makeRequest(t, http.MethodGet, exportURL+"?path=missing", nil, user)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Empty(t, rr.Header().Get("Content-Disposition"))
+
+		rr = h.makeRequest(t, http.MethodGet, exportURL+"?path="+url.QueryEscape("../../etc"), nil, user)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, exportURL+"?format=tar", nil, user)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, exportURL, nil, other)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -1,16 +1,26 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"io"
 	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"lemma/internal/context"
+	"lemma/internal/git"
 	"lemma/internal/logging"
+	"lemma/internal/models"
 	"lemma/internal/storage"
 )
 
@@ -19,16 +29,109 @@ type LookupResponse struct {
 	Paths []string `json:"paths"`
 }
 
+// SearchResponse represents a response to a content search request
+type SearchResponse struct {
+	Results []storage.SearchResult `json:"results"`
+}
+
+// ChangedFilesResponse represents a response to a changed files request
+type ChangedFilesResponse struct {
+	Files        []storage.ChangedFile `json:"files"`
+	DeletedFiles []string              `json:"deletedFiles,omitempty"`
+	CheckedAt    time.Time             `json:"checkedAt"`
+}
+
+// RecentFilesResponse represents a response to a recent files request
+type RecentFilesResponse struct {
+	Files []storage.FileNode `json:"files"`
+}
+
+// FileHashResponse represents a response to a file hash request
+type FileHashResponse struct {
+	FilePath string `json:"filePath"`
+	Hash     string `json:"hash"`
+	Size     int64  `json:"size"`
+}
+
 // SaveFileResponse represents a response to a save file request
 type SaveFileResponse struct {
 	FilePath  string    `json:"filePath"`
 	Size      int64     `json:"size"`
 	UpdatedAt time.Time `json:"updatedAt"`
+	// Warnings are the markdown lint issues of a file saved with lint in warning mode
+	Warnings []storage.LintIssue `json:"warnings,omitempty"`
+}
+
+// ErrorCodeLintFailed is the error code of a save rejected by a strict markdown lint
+const ErrorCodeLintFailed = "lint_failed"
+
+// LintErrorResponse represents a save rejected by a strict markdown lint
+type LintErrorResponse struct {
+	ErrorResponse
+	Issues []storage.LintIssue `json:"issues"`
+}
+
+// ErrorCodeFileConflict is the error code of a save rejected because the file changed since it was read
+const ErrorCodeFileConflict = "file_conflict"
+
+// FileConflictResponse represents a save rejected by If-Match, with the current content of the file
+type FileConflictResponse struct {
+	ErrorResponse
+	// ETag of the current content, empty if the file no longer exists
+	ETag    string `json:"etag"`
+	Content string `json:"content"`
+}
+
+// BatchSaveFile represents a single file in a batch save request
+type BatchSaveFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// BatchSaveResponse represents a response to a batch save request
+type BatchSaveResponse struct {
+	FilePaths []string  `json:"filePaths"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
 // UploadFilesResponse represents a response to an upload files request
 type UploadFilesResponse struct {
-	FilePaths []string `json:"filePaths"`
+	FilePaths    []string           `json:"filePaths"`
+	SkippedPaths []string           `json:"skippedPaths,omitempty"`
+	Results      []UploadFileResult `json:"results"`
+}
+
+// UploadFileResult represents the outcome of uploading a single file
+type UploadFileResult struct {
+	Filename string `json:"filename"`
+	Path     string `json:"path"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Upload statuses of a single file
+const (
+	uploadStatusUploaded = "uploaded"
+	uploadStatusSkipped  = "skipped"
+	uploadStatusFailed   = "failed"
+)
+
+// defaultMaxUploadMemory is how many bytes of an upload are held in memory if no limit is configured
+const defaultMaxUploadMemory = 32 << 20
+
+// Upload conflict modes for files that already exist
+const (
+	uploadConflictOverwrite = "overwrite"
+	uploadConflictSkip      = "skip"
+	uploadConflictRename    = "rename"
+)
+
+// TransferFileRequest represents a request to move a file to another workspace
+type TransferFileRequest struct {
+	SourcePath           string `json:"sourcePath"`
+	DestinationWorkspace string `json:"destinationWorkspace"`
+	DestinationPath      string `json:"destinationPath"`
+	Overwrite            bool   `json:"overwrite,omitempty"`
 }
 
 // LastOpenedFileResponse represents a response to a last opened file request
@@ -36,19 +139,31 @@ type LastOpenedFileResponse struct {
 	LastOpenedFilePath string `json:"lastOpenedFilePath"`
 }
 
+// HomeFileResponse represents a response to a home file request
+type HomeFileResponse struct {
+	HomeFilePath string `json:"homeFilePath"`
+}
+
 func getFilesLogger() logging.Logger {
 	return getHandlersLogger().WithGroup("files")
 }
 
 // ListFiles godoc
 // @Summary List files
-// @Description Lists all files in the user's workspace
+// @Description Lists all files in the user's workspace.
+// @Description With stat, files include their size and modification time and directories the totals of their files.
+// @Description With format=flat the relative paths of the files are returned as a flat list instead of a tree.
 // @Tags files
 // @ID listFiles
 // @Security CookieAuth
 // @Produce json
 // @Param workspace_name path string true "Workspace name"
+// @Param stat query bool false "Include file sizes and modification times"
+// @Param format query string false "Response format, tree (default) or flat"
+// @Param dirs query bool false "Include directory paths in the flat list"
 // @Success 200 {array} storage.FileNode
+// @Success 200 {array} string "Flat list of paths"
+// @Failure 400 {object} ErrorResponse "Unsupported format"
 // @Failure 500 {object} ErrorResponse "Failed to list files"
 // @Router /workspaces/{workspace_name}/files [get]
 func (h *Handler) ListFiles() http.HandlerFunc {
@@ -64,7 +179,18 @@ func (h *Handler) ListFiles() http.HandlerFunc {
 			"clientIP", r.RemoteAddr,
 		)
 
-		files, err := h.Storage.ListFilesRecursively(ctx.UserID, ctx.Workspace.ID)
+		format := r.URL.Query().Get("format")
+		if format != "" && format != "tree" && format != "flat" {
+			log.Debug("unsupported list format requested",
+				"format", format,
+			)
+			respondError(w, "Unsupported format", http.StatusBadRequest)
+			return
+		}
+
+		// A flat list has no room for stats, so they are not collected
+		withStat := r.URL.Query().Get("stat") == "true" && format != "flat"
+		files, err := h.Storage.ListFilesRecursively(ctx.UserID, ctx.Workspace.ID, withStat)
 		if err != nil {
 			log.Error("failed to list files in workspace",
 				"error", err.Error(),
@@ -73,6 +199,10 @@ func (h *Handler) ListFiles() http.HandlerFunc {
 			return
 		}
 
+		if format == "flat" {
+			respondJSON(w, storage.FlattenFileNodes(files, r.URL.Query().Get("dirs") == "true"))
+			return
+		}
 		respondJSON(w, files)
 	}
 }
@@ -86,6 +216,7 @@ func (h *Handler) ListFiles() http.HandlerFunc {
 // @Produce json
 // @Param workspace_name path string true "Workspace name"
 // @Param filename query string true "File name"
+// @Param caseSensitive query bool false "Match the file name exactly instead of ignoring case"
 // @Success 200 {object} LookupResponse
 // @Failure 400 {object} ErrorResponse "Filename is required"
 // @Failure 404 {object} ErrorResponse "File not found"
@@ -121,7 +252,9 @@ func (h *Handler) LookupFileByName() http.HandlerFunc {
 			return
 		}
 
-		filePaths, err := h.Storage.FindFileByName(ctx.UserID, ctx.Workspace.ID, decodedFilename)
+		caseSensitive := r.URL.Query().Get("caseSensitive") == "true"
+
+		filePaths, err := h.Storage.FindFileByName(ctx.UserID, ctx.Workspace.ID, decodedFilename, caseSensitive)
 		if err != nil {
 			if !os.IsNotExist(err) {
 				log.Error("failed to lookup file",
@@ -141,340 +274,1608 @@ func (h *Handler) LookupFileByName() http.HandlerFunc {
 	}
 }
 
-// GetFileContent godoc
-// @Summary Get file content
-// @Description Returns the content of a file in the user's workspace
+// ListFilesByExtension godoc
+// @Summary List files by extension
+// @Description Returns the paths of the files in the user's workspace with one of the given extensions, ordered by path.
+// @Description Extensions are matched ignoring case, the .git directory is skipped.
 // @Tags files
-// @ID getFileContent
+// @ID listFilesByExtension
 // @Security CookieAuth
-// @Produce plain
+// @Produce json
 // @Param workspace_name path string true "Workspace name"
-// @Param file_path query string true "File path"
-// @Success 200 {string} string "Raw file content"
-// @Failure 400 {object} ErrorResponse "Invalid file path"
-// @Failure 404 {object} ErrorResponse "File not found"
-// @Failure 500 {object} ErrorResponse "Failed to read file"
-// @Failure 500 {object} ErrorResponse "Failed to write response"
-// @Router /workspaces/{workspace_name}/files/content [get]
-func (h *Handler) GetFileContent() http.HandlerFunc {
+// @Param ext query string true "Comma-separated extensions, e.g. md,png"
+// @Success 200 {object} LookupResponse
+// @Failure 400 {object} ErrorResponse "ext is required"
+// @Failure 500 {object} ErrorResponse "Failed to list files"
+// @Router /workspaces/{workspace_name}/files/by-extension [get]
+func (h *Handler) ListFilesByExtension() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, ok := context.GetRequestContext(w, r)
 		if !ok {
 			return
 		}
 		log := getFilesLogger().With(
-			"handler", "GetFileContent",
+			"handler", "ListFilesByExtension",
 			"userID", ctx.UserID,
 			"workspaceID", ctx.Workspace.ID,
 			"clientIP", r.RemoteAddr,
 		)
 
-		filePath := r.URL.Query().Get("file_path")
-		decodedPath, err := url.PathUnescape(filePath)
+		var extensions []string
+		for _, ext := range strings.Split(r.URL.Query().Get("ext"), ",") {
+			if ext = strings.TrimSpace(ext); ext != "" {
+				extensions = append(extensions, ext)
+			}
+		}
+		if len(extensions) == 0 {
+			log.Debug("missing ext parameter")
+			respondError(w, "ext is required", http.StatusBadRequest)
+			return
+		}
+
+		filePaths, err := h.Storage.ListFilesByExtension(ctx.UserID, ctx.Workspace.ID, extensions)
 		if err != nil {
-			log.Error("failed to decode file path",
-				"filePath", filePath,
+			log.Error("failed to list files by extension",
+				"extensions", extensions,
 				"error", err.Error(),
 			)
-			respondError(w, "Invalid file path", http.StatusBadRequest)
+			respondError(w, "Failed to list files", http.StatusInternalServerError)
 			return
 		}
 
-		content, err := h.Storage.GetFileContent(ctx.UserID, ctx.Workspace.ID, decodedPath)
-		if err != nil {
-			if storage.IsPathValidationError(err) {
-				log.Error("invalid file path attempted",
-					"filePath", decodedPath,
-					"error", err.Error(),
-				)
-				respondError(w, "Invalid file path", http.StatusBadRequest)
-				return
-			}
+		respondJSON(w, &LookupResponse{Paths: filePaths})
+	}
+}
 
-			if os.IsNotExist(err) {
-				log.Debug("file not found",
-					"filePath", decodedPath,
-				)
-				respondError(w, "File not found", http.StatusNotFound)
-				return
-			}
+const (
+	// searchContextLines is the number of lines around each match SearchFiles returns
+	searchContextLines = 1
+	// searchSnippets is the number of matching lines SearchFiles returns per file when grouping by file
+	searchSnippets = 3
+)
 
-			log.Error("failed to read file content",
-				"filePath", filePath,
-				"error", err.Error(),
-			)
-			respondError(w, "Failed to read file", http.StatusInternalServerError)
+// SearchFiles godoc
+// @Summary Search file contents
+// @Description Returns the text files whose content contains the query, ignoring case, with the number of matching lines
+// @Description and the first few of them with the lines around them. Files with the most matches come first.
+// @Description With groupBy=match each matching line is a result instead. Binary and large files are skipped.
+// @Tags files
+// @ID searchFiles
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param q query string true "Text to search for"
+// @Param groupBy query string false "Return a result per file or per matching line" Enums(file, match) default(file)
+// @Param limit query int false "Maximum number of results to return, at most the max page size"
+// @Success 200 {object} SearchResponse
+// @Failure 400 {object} ErrorResponse "q is required"
+// @Failure 400 {object} ErrorResponse "Invalid groupBy"
+// @Failure 400 {object} ErrorResponse "Invalid limit"
+// @Failure 500 {object} ErrorResponse "Failed to search files"
+// @Router /workspaces/{workspace_name}/files/search [get]
+func (h *Handler) SearchFiles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
 			return
 		}
+		log := getFilesLogger().With(
+			"handler", "SearchFiles",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
 
-		// Detect MIME type based on file extension
-		contentType := mime.TypeByExtension(filepath.Ext(decodedPath))
-		if contentType == "" {
-			// Fallback to text/plain if MIME type cannot be determined
-			contentType = "text/plain"
+		query := r.URL.Query().Get("q")
+		if strings.TrimSpace(query) == "" {
+			log.Debug("missing q parameter")
+			respondError(w, "q is required", http.StatusBadRequest)
+			return
 		}
-		w.Header().Set("Content-Type", contentType)
-		_, err = w.Write(content)
+
+		page, ok := h.parsePagination(w, r)
+		if !ok {
+			return
+		}
+
+		groupBy := storage.SearchGrouping(r.URL.Query().Get("groupBy"))
+		switch groupBy {
+		case "":
+			groupBy = storage.SearchByFile
+		case storage.SearchByFile, storage.SearchByMatch:
+		default:
+			respondError(w, "Invalid groupBy", http.StatusBadRequest)
+			return
+		}
+
+		results, err := h.Storage.SearchContent(ctx.UserID, ctx.Workspace.ID, query, storage.SearchOptions{
+			Limit:        page.Limit,
+			GroupBy:      groupBy,
+			MaxSnippets:  searchSnippets,
+			ContextLines: searchContextLines,
+		})
 		if err != nil {
-			log.Error("failed to write response",
-				"filePath", filePath,
+			log.Error("failed to search files",
 				"error", err.Error(),
 			)
-			respondError(w, "Failed to write response", http.StatusInternalServerError)
+			respondError(w, "Failed to search files", http.StatusInternalServerError)
 			return
 		}
+
+		respondJSON(w, SearchResponse{Results: results})
 	}
 }
 
-// SaveFile godoc
-// @Summary Save file
-// @Description Saves the content of a file in the user's workspace
+// ListRecentFiles godoc
+// @Summary List recently modified files
+// @Description Returns the most recently modified files of the workspace with their size and modification time, newest first
 // @Tags files
-// @ID saveFile
+// @ID listRecentFiles
 // @Security CookieAuth
-// @Accept plain
 // @Produce json
 // @Param workspace_name path string true "Workspace name"
-// @Param file_path query string true "File path"
-// @Success 200 {object} SaveFileResponse
-// @Failure 400 {object} ErrorResponse "Failed to read request body"
-// @Failure 400 {object} ErrorResponse "Invalid file path"
-// @Failure 500 {object} ErrorResponse "Failed to save file"
-// @Router /workspaces/{workspace_name}/files/ [post]
-func (h *Handler) SaveFile() http.HandlerFunc {
+// @Param limit query int false "Maximum number of files to return, at most the max page size"
+// @Success 200 {object} RecentFilesResponse
+// @Failure 400 {object} ErrorResponse "Invalid limit"
+// @Failure 500 {object} ErrorResponse "Failed to list recent files"
+// @Router /workspaces/{workspace_name}/files/recent [get]
+func (h *Handler) ListRecentFiles() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, ok := context.GetRequestContext(w, r)
 		if !ok {
 			return
 		}
 		log := getFilesLogger().With(
-			"handler", "SaveFile",
+			"handler", "ListRecentFiles",
 			"userID", ctx.UserID,
 			"workspaceID", ctx.Workspace.ID,
 			"clientIP", r.RemoteAddr,
 		)
 
-		filePath := r.URL.Query().Get("file_path")
-		// URL-decode the file path
-		decodedPath, err := url.PathUnescape(filePath)
+		page, ok := h.parsePagination(w, r)
+		if !ok {
+			return
+		}
+
+		files, err := h.Storage.ListRecentFiles(ctx.UserID, ctx.Workspace.ID, page.Limit)
 		if err != nil {
-			log.Error("failed to decode file path",
-				"filePath", filePath,
+			log.Error("failed to list recent files",
 				"error", err.Error(),
 			)
-			respondError(w, "Invalid file path", http.StatusBadRequest)
+			respondError(w, "Failed to list recent files", http.StatusInternalServerError)
 			return
 		}
 
-		content, err := io.ReadAll(r.Body)
+		respondJSON(w, RecentFilesResponse{Files: files})
+	}
+}
+
+// ListChangedFiles godoc
+// @Summary List changed files
+// @Description Returns the files modified after the given time, and for git workspaces optionally the files deleted since a commit.
+// @Description The returned checkedAt time can be used as the since parameter of the next request.
+// @Tags files
+// @ID listChangedFiles
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param since query string true "RFC 3339 timestamp"
+// @Param commit query string false "Commit to list deleted files since, requires git"
+// @Success 200 {object} ChangedFilesResponse
+// @Failure 400 {object} ErrorResponse "Invalid since timestamp"
+// @Failure 400 {object} ErrorResponse "Git is not enabled for this workspace"
+// @Failure 400 {object} ErrorResponse "Commit not found"
+// @Failure 500 {object} ErrorResponse "Failed to list changed files"
+// @Failure 500 {object} ErrorResponse "Failed to list deleted files"
+// @Router /workspaces/{workspace_name}/files/changed [get]
+func (h *Handler) ListChangedFiles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "ListChangedFiles",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		since, err := time.Parse(time.RFC3339, r.URL.Query().Get("since"))
 		if err != nil {
-			log.Error("failed to read request body",
-				"filePath", decodedPath,
-				"error", err.Error(),
+			log.Debug("invalid since parameter",
+				"since", r.URL.Query().Get("since"),
 			)
-			respondError(w, "Failed to read request body", http.StatusBadRequest)
+			respondError(w, "Invalid since timestamp", http.StatusBadRequest)
 			return
 		}
 
-		err = h.Storage.SaveFile(ctx.UserID, ctx.Workspace.ID, decodedPath, content)
-		if err != nil {
-			if storage.IsPathValidationError(err) {
-				log.Error("invalid file path attempted",
-					"filePath", decodedPath,
-					"error", err.Error(),
-				)
-				respondError(w, "Invalid file path", http.StatusBadRequest)
-				return
-			}
+		commit := r.URL.Query().Get("commit")
+		if commit != "" && !ctx.Workspace.GitEnabled {
+			respondError(w, "Git is not enabled for this workspace", http.StatusBadRequest)
+			return
+		}
 
-			log.Error("failed to save file",
-				"filePath", filePath,
-				"contentSize", len(content),
+		// Taken before the walk so files modified during it are returned again by the next request
+		checkedAt := time.Now().UTC()
+
+		files, err := h.Storage.ListChangedFiles(ctx.UserID, ctx.Workspace.ID, since)
+		if err != nil {
+			log.Error("failed to list changed files",
 				"error", err.Error(),
 			)
-			respondError(w, "Failed to save file", http.StatusInternalServerError)
+			respondError(w, "Failed to list changed files", http.StatusInternalServerError)
 			return
 		}
 
-		response := SaveFileResponse{
-			FilePath:  filePath,
-			Size:      int64(len(content)),
-			UpdatedAt: time.Now().UTC(),
+		response := ChangedFilesResponse{Files: files, CheckedAt: checkedAt}
+		if commit != "" {
+			deleted, err := h.Storage.ListDeletedFilesSince(ctx.UserID, ctx.Workspace.ID, commit)
+			if errors.Is(err, git.ErrCommitNotFound) {
+				log.Debug("commit not found",
+					"commit", commit,
+				)
+				respondError(w, "Commit not found", http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				log.Error("failed to list deleted files",
+					"commit", commit,
+					"error", err.Error(),
+				)
+				respondError(w, "Failed to list deleted files", http.StatusInternalServerError)
+				return
+			}
+			response.DeletedFiles = deleted
 		}
 
 		respondJSON(w, response)
 	}
 }
 
-// UploadFile godoc
-// @Summary Upload files
-// @Description Uploads one or more files to the user's workspace
+// contentStreamThreshold is the size in bytes above which file content is streamed to the
+// client instead of being buffered in memory. It is above the default save limit, so files that
+// can be edited are read once and get an ETag computed from the bytes that are sent.
+const contentStreamThreshold = 16 << 20
+
+// Cache-Control values for file content. Content requested by its hash never changes,
+// any other request may return changed content and has to be revalidated with the ETag.
+// File content belongs to a user, so it must not be stored by shared caches.
+const (
+	cacheControlImmutable  = "private, max-age=31536000, immutable"
+	cacheControlRevalidate = "no-cache"
+)
+
+// GetFileContent godoc
+// @Summary Get file content
+// @Description Returns the content of a file in the user's workspace
 // @Tags files
-// @ID uploadFile
+// @ID getFileContent
 // @Security CookieAuth
-// @Accept multipart/form-data
-// @Produce json
+// @Produce plain
 // @Param workspace_name path string true "Workspace name"
-// @Param file_path query string true "Directory path"
-// @Param files formData file true "Files to upload"
-// @Success 200 {object} UploadFilesResponse
-// @Failure 400 {object} ErrorResponse "No files found in form"
-// @Failure 400 {object} ErrorResponse "file_path is required"
+// @Param file_path query string true "File path"
+// @Param resolveIncludes query bool false "Expand {{include: path}} directives if enabled for the workspace"
+// @Param hash query string false "SHA-256 hash of the content, content matching it is served as immutable"
+// @Param encoding query string false "Encoding of the stored file, e.g. windows-1252, the content is converted to UTF-8"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {string} string "Raw file content"
+// @Header 200 {string} X-Language "Language of the file for syntax highlighting, derived from its extension"
+// @Header 200 {string} ETag "Checksum of the stored file, also with an encoding or resolved includes, not set for files streamed above 16 MiB"
+// @Success 304 "Not Modified - The cached copy is current"
 // @Failure 400 {object} ErrorResponse "Invalid file path"
-// @Failure 400 {object} ErrorResponse "Empty file uploaded"
-// @Failure 400 {object} ErrorResponse "Failed to get file from form"
-// @Failure 500 {object} ErrorResponse "Failed to read uploaded file"
-// @Failure 500 {object} ErrorResponse "Failed to save file"
-// @Router /workspaces/{workspace_name}/files/upload/ [post]
-func (h *Handler) UploadFile() http.HandlerFunc {
+// @Failure 400 {object} ErrorResponse "Unsupported encoding"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 422 {object} ErrorResponse "Too much included content"
+// @Failure 500 {object} ErrorResponse "Failed to read file"
+// @Failure 500 {object} ErrorResponse "Failed to write response"
+// @Router /workspaces/{workspace_name}/files/content [get]
+func (h *Handler) GetFileContent() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, ok := context.GetRequestContext(w, r)
 		if !ok {
 			return
 		}
 		log := getFilesLogger().With(
-			"handler", "UploadFile",
+			"handler", "GetFileContent",
 			"userID", ctx.UserID,
 			"workspaceID", ctx.Workspace.ID,
 			"clientIP", r.RemoteAddr,
 		)
 
-		// Parse multipart form (max 32MB in memory)
-		err := r.ParseMultipartForm(32 << 20)
+		filePath := r.URL.Query().Get("file_path")
+		decodedPath, err := url.PathUnescape(filePath)
 		if err != nil {
-			log.Error("failed to parse multipart form",
+			log.Error("failed to decode file path",
+				"filePath", filePath,
 				"error", err.Error(),
 			)
-			respondError(w, "Failed to parse form", http.StatusBadRequest)
-			return
-		}
-
-		form := r.MultipartForm
-		if form == nil || len(form.File) == 0 {
-			log.Debug("no files found in form")
-			respondError(w, "No files found in form", http.StatusBadRequest)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
 			return
 		}
 
-		uploadPath := r.URL.Query().Get("file_path")
-		decodedPath, err := url.PathUnescape(uploadPath)
+		enc, err := lookupEncoding(r.URL.Query().Get("encoding"))
 		if err != nil {
-			log.Error("failed to decode file path",
-				"filePath", uploadPath,
-				"error", err.Error(),
+			log.Debug("unsupported encoding requested",
+				"encoding", r.URL.Query().Get("encoding"),
 			)
-			respondError(w, "Invalid file path", http.StatusBadRequest)
+			respondError(w, "Unsupported encoding", http.StatusBadRequest)
 			return
 		}
 
-		uploadedPaths := []string{}
-
-		for _, formFile := range form.File["files"] {
+		// Detect MIME type based on file extension
+		contentType := mime.TypeByExtension(filepath.Ext(decodedPath))
+		if contentType == "" {
+			// Fallback to text/plain if MIME type cannot be determined
+			contentType = "text/plain"
+		}
 
-			if formFile.Filename == "" || formFile.Size == 0 {
-				log.Debug("empty file uploaded",
-					"fileName", formFile.Filename,
-					"fileSize", formFile.Size,
-				)
-				respondError(w, "Empty file uploaded", http.StatusBadRequest)
-				return
+		if ctx.Workspace.ResolveIncludes && r.URL.Query().Get("resolveIncludes") == "true" {
+			content, err := h.Storage.ResolveIncludes(ctx.UserID, ctx.Workspace.ID, decodedPath)
+			if err == nil && enc != nil {
+				content, err = enc.NewDecoder().Bytes(content)
 			}
+			switch {
+			case err == nil:
+				h.recordFileAccess(r, ctx, models.FileAccessRead, decodedPath)
+				w.Header().Set("Content-Type", contentType)
+				h.setLanguageHeader(w, decodedPath)
+				// The ETag is the checksum of the file on disk, so it can be sent back with If-Match.
+				// Included files can change while the file does not, so the content is never cached.
+				if hash, err := h.hashFile(ctx.UserID, ctx.Workspace.ID, decodedPath); err != nil {
+					log.Error("failed to hash file",
+						"filePath", decodedPath,
+						"error", err.Error(),
+					)
+				} else {
+					w.Header().Set("ETag", `"`+hash+`"`)
+				}
+				w.Header().Set("Cache-Control", cacheControlRevalidate)
+				if _, err := w.Write(content); err != nil {
+					log.Error("failed to write response",
+						"filePath", decodedPath,
+						"error", err.Error(),
+					)
+				}
+				return
+			case storage.IsPathValidationError(err):
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			case os.IsNotExist(err):
+				respondError(w, "File not found", http.StatusNotFound)
+				return
+			case errors.Is(err, storage.ErrIncludeLimit):
+				log.Debug("included content exceeds the limit",
+					"filePath", decodedPath,
+				)
+				respondError(w, "Too much included content", http.StatusUnprocessableEntity)
+				return
+			case !errors.Is(err, storage.ErrBinaryFile):
+				log.Error("failed to resolve includes",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Failed to read file", http.StatusInternalServerError)
+				return
+			}
+			// Binary files have no includes and are served as they are
+		}
+
+		file, info, err := h.Storage.OpenFile(ctx.UserID, ctx.Workspace.ID, decodedPath)
+		if err != nil {
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				log.Debug("file not found",
+					"filePath", decodedPath,
+				)
+				respondError(w, "File not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("failed to read file content",
+				"filePath", filePath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to read file", http.StatusInternalServerError)
+			return
+		}
+		defer file.Close()
+		h.recordFileAccess(r, ctx, models.FileAccessRead, decodedPath)
+
+		// The ETag is the checksum of the file on disk, which is also what If-Match is compared
+		// with on save, so the raw bytes are hashed as they are read
+		hasher := sha256.New()
+		var content io.Reader = io.TeeReader(file, hasher)
+		if enc != nil {
+			content = enc.NewDecoder().Reader(content)
+		}
+
+		// Read up to the stream threshold so small files can be served in one piece
+		// and read errors can still be reported to the client
+		head, err := io.ReadAll(io.LimitReader(content, contentStreamThreshold+1))
+		if err != nil {
+			log.Error("failed to read file content",
+				"filePath", filePath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to read file", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		h.setLanguageHeader(w, decodedPath)
+
+		if len(head) <= contentStreamThreshold {
+			// The whole file was read, so the hash covers exactly the content that is sent
+			if setContentCacheHeaders(w, r, hex.EncodeToString(hasher.Sum(nil))) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(head)))
+			_, err = w.Write(head)
+			if err != nil {
+				log.Error("failed to write response",
+					"filePath", filePath,
+					"error", err.Error(),
+				)
+				respondError(w, "Failed to write response", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		// Large files are streamed, the response has already started so errors can only
+		// be logged. Their hash is only known once all of it was sent, so they get no ETag
+		// and are always revalidated. Transcoded content has a different length.
+		w.Header().Set("Cache-Control", cacheControlRevalidate)
+		log.Debug("streaming large file",
+			"filePath", decodedPath,
+			"size", info.Size(),
+		)
+		if enc == nil {
+			w.Header().Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+		}
+		if _, err := io.Copy(w, io.MultiReader(bytes.NewReader(head), content)); err != nil {
+			log.Error("failed to stream file content",
+				"filePath", filePath,
+				"error", err.Error(),
+			)
+		}
+	}
+}
+
+// hashFile returns the hex encoded SHA-256 hash of the content of a file
+func (h *Handler) hashFile(userID, workspaceID int, filePath string) (string, error) {
+	hash, _, err := h.Storage.FileHash(userID, workspaceID, filePath)
+	return hash, err
+}
+
+// ifMatchChecksum returns the content checksum required by the If-Match header of a request,
+// "*" if any existing file matches. Only the first of several listed ETags is used.
+func ifMatchChecksum(r *http.Request) (string, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return "", false
+	}
+	candidate, _, _ := strings.Cut(header, ",")
+	candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+	return strings.Trim(candidate, `"`), true
+}
+
+// setContentCacheHeaders sets the ETag and Cache-Control headers of file content with the given hash.
+// It reports whether the copy cached by the client, given by If-None-Match, is still current.
+func setContentCacheHeaders(w http.ResponseWriter, r *http.Request, hash string) bool {
+	etag := `"` + hash + `"`
+	w.Header().Set("ETag", etag)
+	if r.URL.Query().Get("hash") == hash {
+		w.Header().Set("Cache-Control", cacheControlImmutable)
+	} else {
+		w.Header().Set("Cache-Control", cacheControlRevalidate)
+	}
+
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetWordCount godoc
+// @Summary Get word count
+// @Description Returns word, character and line counts and a reading time estimate for a text file.
+// @Description With recursive=true the counts of all text files in the directory are summed up.
+// @Tags files
+// @ID getWordCount
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string false "File path, or directory path if recursive"
+// @Param recursive query bool false "Aggregate counts of all files in the directory"
+// @Success 200 {object} storage.TextStats
+// @Failure 400 {object} ErrorResponse "file_path is required"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 400 {object} ErrorResponse "File is not a text file"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 500 {object} ErrorResponse "Failed to count words"
+// @Router /workspaces/{workspace_name}/files/wordcount [get]
+func (h *Handler) GetWordCount() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "GetWordCount",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("file_path")
+		recursive := r.URL.Query().Get("recursive") == "true"
+		if filePath == "" && !recursive {
+			log.Debug("missing file_path parameter")
+			respondError(w, "file_path is required", http.StatusBadRequest)
+			return
+		}
+
+		// URL-decode the file path
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil {
+			log.Error("failed to decode file path",
+				"filePath", filePath,
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		stats, err := h.Storage.GetTextStats(ctx.UserID, ctx.Workspace.ID, decodedPath, recursive)
+		if err != nil {
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, storage.ErrBinaryFile) {
+				log.Debug("word count requested for binary file",
+					"filePath", decodedPath,
+				)
+				respondError(w, "File is not a text file", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				log.Debug("file not found",
+					"filePath", decodedPath,
+				)
+				respondError(w, "File not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("failed to count words",
+				"filePath", decodedPath,
+				"recursive", recursive,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to count words", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, stats)
+	}
+}
+
+const (
+	// defaultTailLines is the number of lines returned by GetFileTail if lines is not set
+	defaultTailLines = 10
+	// maxTailLines is the maximum number of lines GetFileTail returns
+	maxTailLines = 10000
+)
+
+// GetFileTail godoc
+// @Summary Get the end of a file
+// @Description Returns the last lines of a text file without reading the whole file, e.g. for logs and journals
+// @Description At most the last 1 MiB is read, if the requested lines are longer only the whole lines within it are returned.
+// @Tags files
+// @ID getFileTail
+// @Security CookieAuth
+// @Produce plain
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "File path"
+// @Param lines query int false "Number of lines to return, at most 10000" default(10)
+// @Success 200 {string} string "Last lines of the file"
+// @Failure 400 {object} ErrorResponse "file_path is required"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 400 {object} ErrorResponse "Invalid lines"
+// @Failure 400 {object} ErrorResponse "File is not a text file"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 500 {object} ErrorResponse "Failed to read file"
+// @Router /workspaces/{workspace_name}/files/tail [get]
+func (h *Handler) GetFileTail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "GetFileTail",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("file_path")
+		if filePath == "" {
+			log.Debug("missing file_path parameter")
+			respondError(w, "file_path is required", http.StatusBadRequest)
+			return
+		}
+
+		// URL-decode the file path
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil {
+			log.Error("failed to decode file path",
+				"filePath", filePath,
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		lines := defaultTailLines
+		if linesStr := r.URL.Query().Get("lines"); linesStr != "" {
+			parsed, err := strconv.Atoi(linesStr)
+			if err != nil || parsed < 1 {
+				respondError(w, "Invalid lines", http.StatusBadRequest)
+				return
+			}
+			lines = min(parsed, maxTailLines)
+		}
+
+		tail, err := h.Storage.TailFile(ctx.UserID, ctx.Workspace.ID, decodedPath, lines)
+		if err != nil {
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, storage.ErrBinaryFile) {
+				log.Debug("tail requested for binary file",
+					"filePath", decodedPath,
+				)
+				respondError(w, "File is not a text file", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				log.Debug("file not found",
+					"filePath", decodedPath,
+				)
+				respondError(w, "File not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("failed to read file tail",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to read file", http.StatusInternalServerError)
+			return
+		}
+
+		h.recordFileAccess(r, ctx, models.FileAccessRead, decodedPath)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if _, err := w.Write(tail); err != nil {
+			log.Error("failed to write response",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+		}
+	}
+}
+
+// GetFileHash godoc
+// @Summary Get a file hash
+// @Description Returns the hex encoded SHA-256 hash and the size of a file, e.g. to verify a sync without downloading the file
+// @Tags files
+// @ID getFileHash
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "File path"
+// @Success 200 {object} FileHashResponse
+// @Failure 400 {object} ErrorResponse "file_path is required"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 500 {object} ErrorResponse "Failed to hash file"
+// @Router /workspaces/{workspace_name}/files/hash [get]
+func (h *Handler) GetFileHash() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "GetFileHash",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("file_path")
+		if filePath == "" {
+			log.Debug("missing file_path parameter")
+			respondError(w, "file_path is required", http.StatusBadRequest)
+			return
+		}
+
+		// URL-decode the file path
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil {
+			log.Error("failed to decode file path",
+				"filePath", filePath,
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		hash, size, err := h.Storage.FileHash(ctx.UserID, ctx.Workspace.ID, decodedPath)
+		if err != nil {
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				log.Debug("file not found",
+					"filePath", decodedPath,
+				)
+				respondError(w, "File not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("failed to hash file",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to hash file", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, FileHashResponse{
+			FilePath: decodedPath,
+			Hash:     hash,
+			Size:     size,
+		})
+	}
+}
+
+// SaveFile godoc
+// @Summary Save file
+// @Description Saves the content of a file in the user's workspace.
+// @Description The save hooks enabled in the workspace settings, e.g. line ending normalization, are applied to the content.
+// @Description If markdown lint is enabled, markdown files with issues are rejected in strict mode
+// @Description and saved with the issues returned as warnings otherwise.
+// @Description With an If-Match header holding the ETag returned by getFileContent the file is only saved
+// @Description if it has not changed since, otherwise the current content is returned with 409.
+// @Description The response carries the ETag of the saved content.
+// @Tags files
+// @ID saveFile
+// @Security CookieAuth
+// @Accept plain
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "File path"
+// @Param encoding query string false "Encoding to store the UTF-8 request body in, e.g. windows-1252"
+// @Param If-Match header string false "ETag of the content the change is based on"
+// @Success 200 {object} SaveFileResponse
+// @Failure 400 {object} ErrorResponse "Failed to read request body"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 400 {object} ErrorResponse "Unsupported encoding"
+// @Failure 400 {object} ErrorResponse "Content cannot be represented in the encoding"
+// @Failure 400 {object} ErrorResponse "Rejected by a save hook"
+// @Failure 400 {object} LintErrorResponse "Markdown lint failed"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
+// @Failure 409 {object} FileConflictResponse "File was modified"
+// @Failure 413 {object} ErrorResponse "Content too large"
+// @Failure 413 {object} ErrorResponse "File too large"
+// @Failure 500 {object} ErrorResponse "Failed to save file"
+// @Router /workspaces/{workspace_name}/files/ [post]
+func (h *Handler) SaveFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "SaveFile",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("file_path")
+		// URL-decode the file path
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil {
+			log.Error("failed to decode file path",
+				"filePath", filePath,
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		// Limit the body before reading it, so an oversized request is never buffered
+		if h.MaxContentSize > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, h.MaxContentSize)
+		}
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				log.Debug("request body too large",
+					"filePath", decodedPath,
+					"limit", maxBytesErr.Limit,
+				)
+				respondError(w, "Content too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			log.Error("failed to read request body",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		enc, err := lookupEncoding(r.URL.Query().Get("encoding"))
+		if err != nil {
+			log.Debug("unsupported encoding requested",
+				"encoding", r.URL.Query().Get("encoding"),
+			)
+			respondError(w, "Unsupported encoding", http.StatusBadRequest)
+			return
+		}
+		if enc != nil {
+			content, err = enc.NewEncoder().Bytes(content)
+			if err != nil {
+				log.Debug("failed to encode content",
+					"filePath", decodedPath,
+					"encoding", r.URL.Query().Get("encoding"),
+					"error", err.Error(),
+				)
+				respondError(w, "Content cannot be represented in the encoding", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if h.MaxFileSize > 0 && int64(len(content)) > h.MaxFileSize {
+			log.Debug("file too large",
+				"filePath", decodedPath,
+				"size", len(content),
+				"maxSize", h.MaxFileSize,
+			)
+			respondError(w, "File too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		hooks := saveHooks(ctx.Workspace)
+		lint := lintHook(ctx.Workspace)
+		if lint != nil {
+			hooks = append(hooks, lint)
+		}
+
+		if checksum, ok := ifMatchChecksum(r); ok {
+			err = h.Storage.SaveFileIfMatch(ctx.UserID, ctx.Workspace.ID, decodedPath, content, checksum, hooks...)
+		} else {
+			err = h.Storage.SaveFile(ctx.UserID, ctx.Workspace.ID, decodedPath, content, hooks...)
+		}
+		if err != nil {
+			var mismatchErr *storage.ChecksumMismatchError
+			if errors.As(err, &mismatchErr) {
+				log.Debug("stale save rejected",
+					"filePath", decodedPath,
+				)
+				response := FileConflictResponse{
+					ErrorResponse: ErrorResponse{Message: "File was modified", Code: ErrorCodeFileConflict},
+					Content:       string(mismatchErr.Content),
+				}
+				if mismatchErr.Checksum != "" {
+					response.ETag = `"` + mismatchErr.Checksum + `"`
+					w.Header().Set("ETag", response.ETag)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusConflict)
+				respondJSON(w, response)
+				return
+			}
+			if storage.IsWorkspacePinnedError(err) {
+				log.Debug("write to pinned workspace rejected",
+					"error", err.Error(),
+				)
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
+				return
+			}
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			var lintErr *storage.LintError
+			if errors.As(err, &lintErr) {
+				log.Debug("save rejected by markdown lint",
+					"filePath", decodedPath,
+					"issues", len(lintErr.Issues),
+				)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				respondJSON(w, LintErrorResponse{
+					ErrorResponse: ErrorResponse{Message: "Markdown lint failed", Code: ErrorCodeLintFailed},
+					Issues:        lintErr.Issues,
+				})
+				return
+			}
+
+			if storage.IsSaveHookError(err) {
+				log.Debug("save rejected by hook",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Failed to save file: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			log.Error("failed to save file",
+				"filePath", filePath,
+				"contentSize", len(content),
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to save file", http.StatusInternalServerError)
+			return
+		}
+
+		h.recordActivity(&models.Activity{
+			WorkspaceID: ctx.Workspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityFileSaved,
+			Path:        decodedPath,
+		})
+
+		// Save hooks may have changed the content, so the ETag is taken from the saved file
+		if hash, err := h.hashFile(ctx.UserID, ctx.Workspace.ID, decodedPath); err != nil {
+			log.Error("failed to hash saved file",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+		} else {
+			w.Header().Set("ETag", `"`+hash+`"`)
+		}
+
+		response := SaveFileResponse{
+			FilePath:  filePath,
+			Size:      int64(len(content)),
+			UpdatedAt: time.Now().UTC(),
+		}
+		if lint != nil {
+			response.Warnings = lint.Issues()
+		}
+
+		respondJSON(w, response)
+	}
+}
+
+// lintHook returns the markdown lint hook enabled in the workspace settings, nil if lint is disabled
+func lintHook(workspace *models.Workspace) *storage.LintHook {
+	if !workspace.LintMarkdown {
+		return nil
+	}
+
+	var required []string
+	for _, field := range strings.Split(workspace.LintRequiredFrontmatter, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			required = append(required, field)
+		}
+	}
+	return storage.NewLintHook(storage.DefaultLinter(required), workspace.LintStrict)
+}
+
+// saveHooks returns the built-in save hooks enabled in the workspace settings
+func saveHooks(workspace *models.Workspace) []storage.SaveHook {
+	var hooks []storage.SaveHook
+	if workspace.NormalizeLineEndings {
+		hooks = append(hooks, storage.NormalizeLineEndingsHook())
+	}
+	if workspace.TrimTrailingWhitespace {
+		hooks = append(hooks, storage.TrimTrailingWhitespaceHook())
+	}
+	return hooks
+}
+
+// BatchSaveFiles godoc
+// @Summary Save multiple files
+// @Description Saves several files in the user's workspace, all or nothing.
+// @Description If any file fails to save, the files already written are rolled back.
+// @Description Files are saved like with saveFile, replaced content is kept as a file version
+// @Description and the save hooks and markdown lint enabled in the workspace settings are applied.
+// @Tags files
+// @ID batchSaveFiles
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param body body []BatchSaveFile true "Files to save"
+// @Success 200 {object} BatchSaveResponse
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 400 {object} ErrorResponse "No files provided"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 400 {object} ErrorResponse "Rejected by a save hook"
+// @Failure 400 {object} LintErrorResponse "Markdown lint failed"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
+// @Failure 413 {object} ErrorResponse "Content too large"
+// @Failure 413 {object} ErrorResponse "File too large"
+// @Failure 500 {object} ErrorResponse "Failed to save file"
+// @Router /workspaces/{workspace_name}/files/batch-save [post]
+func (h *Handler) BatchSaveFiles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "BatchSaveFiles",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		if h.MaxContentSize > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, h.MaxContentSize)
+		}
+		var req []BatchSaveFile
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				log.Debug("request body too large",
+					"limit", maxBytesErr.Limit,
+				)
+				respondError(w, "Content too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			log.Error("failed to decode request body",
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if len(req) == 0 {
+			log.Debug("no files provided")
+			respondError(w, "No files provided", http.StatusBadRequest)
+			return
+		}
+
+		files := make([]storage.FileContent, len(req))
+		filePaths := make([]string, len(req))
+		for i, file := range req {
+			if h.MaxFileSize > 0 && int64(len(file.Content)) > h.MaxFileSize {
+				log.Debug("file too large",
+					"filePath", file.Path,
+					"size", len(file.Content),
+					"maxSize", h.MaxFileSize,
+				)
+				respondError(w, "File too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			files[i] = storage.FileContent{Path: file.Path, Content: []byte(file.Content)}
+			filePaths[i] = file.Path
+		}
+
+		hooks := saveHooks(ctx.Workspace)
+		if lint := lintHook(ctx.Workspace); lint != nil {
+			hooks = append(hooks, lint)
+		}
+
+		err := h.Storage.SaveFiles(ctx.UserID, ctx.Workspace.ID, files, hooks...)
+		if err != nil {
+			if storage.IsWorkspacePinnedError(err) {
+				log.Debug("write to pinned workspace rejected",
+					"error", err.Error(),
+				)
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
+				return
+			}
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			var batchErr *storage.BatchSaveError
+			var lintErr *storage.LintError
+			if errors.As(err, &lintErr) && errors.As(err, &batchErr) {
+				log.Debug("batch save rejected by markdown lint",
+					"filePath", batchErr.Path,
+					"issues", len(lintErr.Issues),
+				)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				respondJSON(w, LintErrorResponse{
+					ErrorResponse: ErrorResponse{Message: "Markdown lint failed: " + batchErr.Path, Code: ErrorCodeLintFailed},
+					Issues:        lintErr.Issues,
+				})
+				return
+			}
+
+			if storage.IsSaveHookError(err) {
+				log.Debug("batch save rejected by hook",
+					"error", err.Error(),
+				)
+				respondError(w, "Failed to save file: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if errors.As(err, &batchErr) {
+				log.Error("failed to save file in batch",
+					"filePath", batchErr.Path,
+					"error", err.Error(),
+				)
+				respondError(w, "Failed to save file: "+batchErr.Path, http.StatusInternalServerError)
+				return
+			}
+
+			log.Error("failed to save files",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to save files", http.StatusInternalServerError)
+			return
+		}
+
+		for _, filePath := range filePaths {
+			h.recordActivity(&models.Activity{
+				WorkspaceID: ctx.Workspace.ID,
+				UserID:      ctx.UserID,
+				Type:        models.ActivityFileSaved,
+				Path:        filePath,
+			})
+		}
+
+		// Commit the whole batch at once, files are already saved so failures are only logged
+		if err := h.autoCommit(ctx.UserID, ctx.Workspace, strings.Join(filePaths, ", "), "update"); err != nil {
+			log.Warn("failed to commit batch save",
+				"fileCount", len(filePaths),
+				"error", err.Error(),
+			)
+		}
+
+		respondJSON(w, BatchSaveResponse{
+			FilePaths: filePaths,
+			UpdatedAt: time.Now().UTC(),
+		})
+	}
+}
+
+// UploadFile godoc
+// @Summary Upload files
+// @Description Uploads one or more files to the user's workspace.
+// @Description Existing files are overwritten unless onConflict is skip, which keeps them and reports them as skipped,
+// @Description or rename, which saves the upload under a free name like "name (1).md".
+// @Description Each file is uploaded independently, the results list the outcome per file.
+// @Description If any file fails, e.g. because it is larger than the file size limit, the response status is 207 Multi-Status.
+// @Tags files
+// @ID uploadFile
+// @Security CookieAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "Directory path"
+// @Param onConflict query string false "How to handle existing files: overwrite (default), skip or rename"
+// @Param files formData file true "Files to upload"
+// @Success 200 {object} UploadFilesResponse
+// @Success 207 {object} UploadFilesResponse "Some files failed to upload"
+// @Failure 400 {object} ErrorResponse "No files found in form"
+// @Failure 400 {object} ErrorResponse "file_path is required"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 400 {object} ErrorResponse "Invalid onConflict value"
+// @Failure 413 {object} ErrorResponse "Upload too large"
+// @Router /workspaces/{workspace_name}/files/upload/ [post]
+func (h *Handler) UploadFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "UploadFile",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		// Limit the body before parsing it, files beyond the memory limit are stored in temporary files
+		if h.MaxUploadSize > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, h.MaxUploadSize)
+		}
+		maxMemory := h.MaxUploadMemory
+		if maxMemory <= 0 {
+			maxMemory = defaultMaxUploadMemory
+		}
+		err := r.ParseMultipartForm(maxMemory)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				log.Debug("upload too large",
+					"limit", maxBytesErr.Limit,
+				)
+				respondError(w, "Upload too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			log.Error("failed to parse multipart form",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		form := r.MultipartForm
+		if form == nil || len(form.File) == 0 {
+			log.Debug("no files found in form")
+			respondError(w, "No files found in form", http.StatusBadRequest)
+			return
+		}
+
+		uploadPath := r.URL.Query().Get("file_path")
+		decodedPath, err := url.PathUnescape(uploadPath)
+		if err != nil {
+			log.Error("failed to decode file path",
+				"filePath", uploadPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		onConflict := r.URL.Query().Get("onConflict")
+		switch onConflict {
+		case "":
+			onConflict = uploadConflictOverwrite
+		case uploadConflictOverwrite, uploadConflictSkip, uploadConflictRename:
+		default:
+			log.Debug("invalid onConflict value",
+				"onConflict", onConflict,
+			)
+			respondError(w, "Invalid onConflict value", http.StatusBadRequest)
+			return
+		}
+
+		// An invalid directory makes every file fail, reject the request as a whole
+		if _, err := h.Storage.ValidatePath(ctx.UserID, ctx.Workspace.ID, decodedPath); err != nil {
+			log.Error("invalid file path attempted",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		response := UploadFilesResponse{
+			FilePaths: []string{},
+			Results:   []UploadFileResult{},
+		}
+		status := http.StatusOK
+
+		for _, formFile := range form.File["files"] {
+			result := h.uploadFile(ctx.UserID, ctx.Workspace.ID, formFile, decodedPath, onConflict, log)
+			switch result.Status {
+			case uploadStatusUploaded:
+				response.FilePaths = append(response.FilePaths, result.Path)
+				h.recordActivity(&models.Activity{
+					WorkspaceID: ctx.Workspace.ID,
+					UserID:      ctx.UserID,
+					Type:        models.ActivityFileUploaded,
+					Path:        result.Path,
+				})
+			case uploadStatusSkipped:
+				response.SkippedPaths = append(response.SkippedPaths, result.Path)
+			case uploadStatusFailed:
+				status = http.StatusMultiStatus
+			}
+			response.Results = append(response.Results, result)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error("failed to encode response",
+				"error", err.Error(),
+			)
+		}
+	}
+}
+
+// uploadFile saves a single uploaded file to dir, failures are reported in the result
+func (h *Handler) uploadFile(userID, workspaceID int, formFile *multipart.FileHeader, dir, onConflict string, log logging.Logger) UploadFileResult {
+	// Use filepath.Join to properly construct the path
+	filePath := filepath.Join(dir, formFile.Filename)
+	failed := func(message string) UploadFileResult {
+		return UploadFileResult{
+			Filename: formFile.Filename,
+			Path:     filePath,
+			Status:   uploadStatusFailed,
+			Error:    message,
+		}
+	}
+
+	if formFile.Filename == "" || formFile.Size == 0 {
+		log.Debug("empty file uploaded",
+			"fileName", formFile.Filename,
+			"fileSize", formFile.Size,
+		)
+		return failed("Empty file uploaded")
+	}
+
+	// Validate file size to prevent excessive memory allocation
+	if h.MaxFileSize > 0 && formFile.Size > h.MaxFileSize {
+		log.Debug("file too large",
+			"fileName", formFile.Filename,
+			"fileSize", formFile.Size,
+			"maxSize", h.MaxFileSize,
+		)
+		return failed("File too large")
+	}
+
+	// Open the uploaded file
+	file, err := formFile.Open()
+	if err != nil {
+		log.Error("failed to get file from form",
+			"error", err.Error(),
+		)
+		return failed("Failed to get file from form")
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Error("failed to close uploaded file",
+				"error", err.Error(),
+			)
+		}
+	}()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		log.Error("failed to read uploaded file",
+			"filePath", filePath,
+			"error", err.Error(),
+		)
+		return failed("Failed to read uploaded file")
+	}
+
+	if onConflict != uploadConflictOverwrite {
+		uniquePath, err := h.Storage.UniqueFilePath(userID, workspaceID, filePath)
+		if err != nil {
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", filePath,
+					"error", err.Error(),
+				)
+				return failed("Invalid file path")
+			}
+
+			log.Error("failed to check for existing file",
+				"filePath", filePath,
+				"error", err.Error(),
+			)
+			return failed("Failed to save file")
+		}
+
+		if uniquePath != filePath && onConflict == uploadConflictSkip {
+			log.Debug("skipping existing file",
+				"filePath", filePath,
+			)
+			return UploadFileResult{Filename: formFile.Filename, Path: filePath, Status: uploadStatusSkipped}
+		}
+		filePath = uniquePath
+	}
+
+	err = h.Storage.SaveFile(userID, workspaceID, filePath, content)
+	if err != nil {
+		if storage.IsWorkspacePinnedError(err) {
+			log.Debug("write to pinned workspace rejected",
+				"error", err.Error(),
+			)
+			return failed("Workspace is pinned to a git ref and read-only")
+		}
+		if storage.IsPathValidationError(err) {
+			log.Error("invalid file path attempted",
+				"filePath", filePath,
+				"error", err.Error(),
+			)
+			return failed("Invalid file path")
+		}
+
+		log.Error("failed to save file",
+			"filePath", filePath,
+			"contentSize", len(content),
+			"error", err.Error(),
+		)
+		return failed("Failed to save file")
+	}
+
+	return UploadFileResult{Filename: formFile.Filename, Path: filePath, Status: uploadStatusUploaded}
+}
+
+// MoveFile godoc
+// @Summary Move file
+// @Description Moves a file to a new location in the user's workspace
+// @Tags files
+// @ID moveFile
+// @Security CookieAuth
+// @Param workspace_name path string true "Workspace name"
+// @Param src_path query string true "Source file path"
+// @Param dest_path query string true "Destination file path"
+// @Success 204 "No Content - File moved successfully"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 400 {object} ErrorResponse "Directory is too large to move"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
+// @Failure 500 {object} ErrorResponse "Failed to move file"
+// @Router /workspaces/{workspace_name}/files/move [post]
+func (h *Handler) MoveFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "MoveFile",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		srcPath := r.URL.Query().Get("src_path")
+		destPath := r.URL.Query().Get("dest_path")
+		if srcPath == "" || destPath == "" {
+			log.Debug("missing src_path or dest_path parameter")
+			respondError(w, "src_path and dest_path are required", http.StatusBadRequest)
+			return
+		}
+
+		// URL-decode the source and destination paths
+		decodedSrcPath, err := url.PathUnescape(srcPath)
+		if err != nil {
+			log.Error("failed to decode source file path",
+				"srcPath", srcPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid source file path", http.StatusBadRequest)
+			return
+		}
+
+		decodedDestPath, err := url.PathUnescape(destPath)
+		if err != nil {
+			log.Error("failed to decode destination file path",
+				"destPath", destPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid destination file path", http.StatusBadRequest)
+			return
+		}
 
-			// Validate file size to prevent excessive memory allocation
-			// TODO: Make this configurable
-			const maxFileSize = 100 * 1024 * 1024 // 100MB
-			if formFile.Size > maxFileSize {
-				log.Debug("file too large",
-					"fileName", formFile.Filename,
-					"fileSize", formFile.Size,
-					"maxSize", maxFileSize,
+		err = h.Storage.MoveFile(ctx.UserID, ctx.Workspace.ID, decodedSrcPath, decodedDestPath)
+		if err != nil {
+			if storage.IsWorkspacePinnedError(err) {
+				log.Debug("write to pinned workspace rejected",
+					"error", err.Error(),
 				)
-				respondError(w, "File too large", http.StatusBadRequest)
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
 				return
 			}
-
-			// Open the uploaded file
-			file, err := formFile.Open()
-			if err != nil {
-				log.Error("failed to get file from form",
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"srcPath", decodedSrcPath,
+					"destPath", decodedDestPath,
 					"error", err.Error(),
 				)
-				respondError(w, "Failed to get file from form", http.StatusBadRequest)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
 				return
 			}
-			defer func() {
-				if err := file.Close(); err != nil {
-					log.Error("failed to close uploaded file",
-						"error", err.Error(),
-					)
-				}
-			}()
-
-			// Use filepath.Join to properly construct the path
-		filePath := filepath.Join(decodedPath, formFile.Filename)
-
-			content, err := io.ReadAll(file)
-			if err != nil {
-				log.Error("failed to read uploaded file",
-					"filePath", filePath,
+			if storage.IsTreeLimitError(err) {
+				log.Debug("directory exceeds tree limits",
+					"srcPath", decodedSrcPath,
 					"error", err.Error(),
 				)
-				respondError(w, "Failed to read uploaded file", http.StatusInternalServerError)
+				respondError(w, "Directory is too large to move", http.StatusBadRequest)
 				return
 			}
-
-			err = h.Storage.SaveFile(ctx.UserID, ctx.Workspace.ID, filePath, content)
-			if err != nil {
-				if storage.IsPathValidationError(err) {
-					log.Error("invalid file path attempted",
-						"filePath", filePath,
-						"error", err.Error(),
-					)
-					respondError(w, "Invalid file path", http.StatusBadRequest)
-					return
-				}
-
-				log.Error("failed to save file",
-					"filePath", filePath,
-					"contentSize", len(content),
-					"error", err.Error(),
+			if os.IsNotExist(err) {
+				log.Debug("file not found",
+					"srcPath", decodedSrcPath,
 				)
-				respondError(w, "Failed to save file", http.StatusInternalServerError)
+				respondError(w, "File not found", http.StatusNotFound)
 				return
 			}
-
-			uploadedPaths = append(uploadedPaths, filePath)
+			log.Error("failed to move file",
+				"srcPath", decodedSrcPath,
+				"destPath", decodedDestPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to move file", http.StatusInternalServerError)
+			return
 		}
 
-		response := UploadFilesResponse{
-			FilePaths: uploadedPaths,
+		h.recordActivity(&models.Activity{
+			WorkspaceID: ctx.Workspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityFileMoved,
+			Path:        decodedSrcPath,
+			TargetPath:  decodedDestPath,
+		})
+
+		response := SaveFileResponse{
+			FilePath:  decodedDestPath,
+			Size:      -1, // Size is not applicable for move operation
+			UpdatedAt: time.Now().UTC(),
 		}
 		respondJSON(w, response)
 	}
 }
 
-// MoveFile godoc
-// @Summary Move file
-// @Description Moves a file to a new location in the user's workspace
+// CopyFile godoc
+// @Summary Copy file
+// @Description Copies a file to a new location in the user's workspace.
+// @Description An existing destination file is only replaced if overwrite is set.
 // @Tags files
-// @ID moveFile
+// @ID copyFile
 // @Security CookieAuth
+// @Produce json
 // @Param workspace_name path string true "Workspace name"
 // @Param src_path query string true "Source file path"
 // @Param dest_path query string true "Destination file path"
-// @Success 204 "No Content - File moved successfully"
+// @Param overwrite query bool false "Replace an existing destination file"
+// @Success 200 {object} SaveFileResponse
 // @Failure 400 {object} ErrorResponse "Invalid file path"
 // @Failure 404 {object} ErrorResponse "File not found"
-// @Failure 500 {object} ErrorResponse "Failed to move file"
-// @Router /workspaces/{workspace_name}/files/move [post]
-func (h *Handler) MoveFile() http.HandlerFunc {
+// @Failure 409 {object} ErrorResponse "Destination file already exists"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
+// @Failure 500 {object} ErrorResponse "Failed to copy file"
+// @Router /workspaces/{workspace_name}/files/copy [post]
+func (h *Handler) CopyFile() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, ok := context.GetRequestContext(w, r)
 		if !ok {
 			return
 		}
 		log := getFilesLogger().With(
-			"handler", "MoveFile",
+			"handler", "CopyFile",
 			"userID", ctx.UserID,
 			"workspaceID", ctx.Workspace.ID,
 			"clientIP", r.RemoteAddr,
@@ -488,7 +1889,6 @@ func (h *Handler) MoveFile() http.HandlerFunc {
 			return
 		}
 
-		// URL-decode the source and destination paths
 		decodedSrcPath, err := url.PathUnescape(srcPath)
 		if err != nil {
 			log.Error("failed to decode source file path",
@@ -509,8 +1909,17 @@ func (h *Handler) MoveFile() http.HandlerFunc {
 			return
 		}
 
-		err = h.Storage.MoveFile(ctx.UserID, ctx.Workspace.ID, decodedSrcPath, decodedDestPath)
+		overwrite := r.URL.Query().Get("overwrite") == "true"
+
+		err = h.Storage.CopyFile(ctx.UserID, ctx.Workspace.ID, decodedSrcPath, decodedDestPath, overwrite)
 		if err != nil {
+			if storage.IsWorkspacePinnedError(err) {
+				log.Debug("write to pinned workspace rejected",
+					"error", err.Error(),
+				)
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
+				return
+			}
 			if storage.IsPathValidationError(err) {
 				log.Error("invalid file path attempted",
 					"srcPath", decodedSrcPath,
@@ -520,6 +1929,13 @@ func (h *Handler) MoveFile() http.HandlerFunc {
 				respondError(w, "Invalid file path", http.StatusBadRequest)
 				return
 			}
+			if errors.Is(err, os.ErrExist) {
+				log.Debug("copy destination exists",
+					"destPath", decodedDestPath,
+				)
+				respondError(w, "Destination file already exists", http.StatusConflict)
+				return
+			}
 			if os.IsNotExist(err) {
 				log.Debug("file not found",
 					"srcPath", decodedSrcPath,
@@ -527,18 +1943,176 @@ func (h *Handler) MoveFile() http.HandlerFunc {
 				respondError(w, "File not found", http.StatusNotFound)
 				return
 			}
-			log.Error("failed to move file",
+			log.Error("failed to copy file",
 				"srcPath", decodedSrcPath,
 				"destPath", decodedDestPath,
 				"error", err.Error(),
 			)
-			respondError(w, "Failed to move file", http.StatusInternalServerError)
+			respondError(w, "Failed to copy file", http.StatusInternalServerError)
 			return
 		}
 
+		h.recordActivity(&models.Activity{
+			WorkspaceID: ctx.Workspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityFileCopied,
+			Path:        decodedSrcPath,
+			TargetPath:  decodedDestPath,
+		})
+
 		response := SaveFileResponse{
 			FilePath:  decodedDestPath,
-			Size:      -1, // Size is not applicable for move operation
+			Size:      -1, // Size is not applicable for copy operation
+			UpdatedAt: time.Now().UTC(),
+		}
+		respondJSON(w, response)
+	}
+}
+
+// TransferFile godoc
+// @Summary Transfer file
+// @Description Moves a file from the user's workspace to another workspace owned by the user.
+// @Description An existing destination file is only replaced if overwrite is set, its content is kept as a file version.
+// @Tags files
+// @ID transferFile
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param workspace_name path string true "Source workspace name"
+// @Param body body TransferFileRequest true "Transfer request"
+// @Success 200 {object} SaveFileResponse
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 400 {object} ErrorResponse "sourcePath, destinationWorkspace and destinationPath are required"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 400 {object} ErrorResponse "Source and destination are the same file"
+// @Failure 404 {object} ErrorResponse "Destination workspace not found"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 409 {object} ErrorResponse "Destination file already exists"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
+// @Failure 500 {object} ErrorResponse "Failed to transfer file"
+// @Router /workspaces/{workspace_name}/files/transfer [post]
+func (h *Handler) TransferFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "TransferFile",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		var req TransferFileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode request body",
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if req.SourcePath == "" || req.DestinationWorkspace == "" || req.DestinationPath == "" {
+			log.Debug("missing transfer parameters")
+			respondError(w, "sourcePath, destinationWorkspace and destinationPath are required", http.StatusBadRequest)
+			return
+		}
+
+		// Destination is looked up among the user's own workspaces, so access is implied
+		destWorkspace, err := h.DB.GetWorkspaceByName(ctx.UserID, req.DestinationWorkspace)
+		if err != nil {
+			log.Debug("destination workspace not found",
+				"destinationWorkspace", req.DestinationWorkspace,
+			)
+			respondError(w, "Destination workspace not found", http.StatusNotFound)
+			return
+		}
+
+		err = h.Storage.TransferFile(ctx.UserID, ctx.Workspace.ID, req.SourcePath, destWorkspace.ID, req.DestinationPath, req.Overwrite)
+		if err != nil {
+			if storage.IsWorkspacePinnedError(err) {
+				log.Debug("write to pinned workspace rejected",
+					"error", err.Error(),
+				)
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
+				return
+			}
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"srcPath", req.SourcePath,
+					"destPath", req.DestinationPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+			if errors.Is(err, storage.ErrSameFile) {
+				log.Debug("transfer onto the source file rejected",
+					"srcPath", req.SourcePath,
+					"destPath", req.DestinationPath,
+				)
+				respondError(w, "Source and destination are the same file", http.StatusBadRequest)
+				return
+			}
+			if errors.Is(err, os.ErrExist) {
+				log.Debug("transfer destination exists",
+					"destPath", req.DestinationPath,
+				)
+				respondError(w, "Destination file already exists", http.StatusConflict)
+				return
+			}
+			if os.IsNotExist(err) {
+				log.Debug("file not found",
+					"srcPath", req.SourcePath,
+				)
+				respondError(w, "File not found", http.StatusNotFound)
+				return
+			}
+			log.Error("failed to transfer file",
+				"srcPath", req.SourcePath,
+				"destPath", req.DestinationPath,
+				"destWorkspaceID", destWorkspace.ID,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to transfer file", http.StatusInternalServerError)
+			return
+		}
+
+		// Recorded in both workspaces, the target path of the source entry names the destination workspace
+		h.recordActivity(&models.Activity{
+			WorkspaceID: ctx.Workspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityFileTransferred,
+			Path:        req.SourcePath,
+			TargetPath:  destWorkspace.Name + ":" + req.DestinationPath,
+		})
+		h.recordActivity(&models.Activity{
+			WorkspaceID: destWorkspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityFileTransferred,
+			Path:        ctx.Workspace.Name + ":" + req.SourcePath,
+			TargetPath:  req.DestinationPath,
+		})
+
+		// The file has already been transferred, so git failures are logged but not returned
+		if err := h.autoCommit(ctx.UserID, ctx.Workspace, req.SourcePath, "delete"); err != nil {
+			log.Warn("failed to commit transfer in source workspace",
+				"srcPath", req.SourcePath,
+				"error", err.Error(),
+			)
+		}
+		if err := h.autoCommit(ctx.UserID, destWorkspace, req.DestinationPath, "create"); err != nil {
+			log.Warn("failed to commit transfer in destination workspace",
+				"destPath", req.DestinationPath,
+				"destWorkspaceID", destWorkspace.ID,
+				"error", err.Error(),
+			)
+		}
+
+		response := SaveFileResponse{
+			FilePath:  req.DestinationPath,
+			Size:      -1, // Size is not applicable for transfer operation
 			UpdatedAt: time.Now().UTC(),
 		}
 		respondJSON(w, response)
@@ -547,15 +2121,19 @@ func (h *Handler) MoveFile() http.HandlerFunc {
 
 // DeleteFile godoc
 // @Summary Delete file
-// @Description Deletes a file in the user's workspace
+// @Description Moves a file in the user's workspace to the trash of the workspace.
+// @Description Trashed files can be restored until they are removed from the trash or purged.
+// @Description With ifExists=true a missing file is treated as already deleted so the request can be retried safely.
 // @Tags files
 // @ID deleteFile
 // @Security CookieAuth
 // @Param workspace_name path string true "Workspace name"
 // @Param file_path query string true "File path"
+// @Param ifExists query bool false "Respond with 204 instead of 404 if the file does not exist"
 // @Success 204 "No Content - File deleted successfully"
 // @Failure 400 {object} ErrorResponse "Invalid file path"
 // @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
 // @Failure 500 {object} ErrorResponse "Failed to delete file"
 // @Router /workspaces/{workspace_name}/files/ [delete]
 func (h *Handler) DeleteFile() http.HandlerFunc {
@@ -591,6 +2169,13 @@ func (h *Handler) DeleteFile() http.HandlerFunc {
 
 		err = h.Storage.DeleteFile(ctx.UserID, ctx.Workspace.ID, decodedPath)
 		if err != nil {
+			if storage.IsWorkspacePinnedError(err) {
+				log.Debug("write to pinned workspace rejected",
+					"error", err.Error(),
+				)
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
+				return
+			}
 			if storage.IsPathValidationError(err) {
 				log.Error("invalid file path attempted",
 					"filePath", decodedPath,
@@ -601,6 +2186,13 @@ func (h *Handler) DeleteFile() http.HandlerFunc {
 			}
 
 			if os.IsNotExist(err) {
+				if r.URL.Query().Get("ifExists") == "true" {
+					log.Debug("file already deleted",
+						"filePath", decodedPath,
+					)
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
 				log.Debug("file not found",
 					"filePath", decodedPath,
 				)
@@ -616,6 +2208,13 @@ func (h *Handler) DeleteFile() http.HandlerFunc {
 			return
 		}
 
+		h.recordActivity(&models.Activity{
+			WorkspaceID: ctx.Workspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityFileDeleted,
+			Path:        decodedPath,
+		})
+
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
@@ -752,3 +2351,137 @@ func (h *Handler) UpdateLastOpenedFile() http.HandlerFunc {
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
+
+// GetHomeFile godoc
+// @Summary Get home file
+// @Description Returns the path of the home file of the user's workspace.
+// @Description Falls back to the default home file if none is set or the set file no longer exists.
+// @Description The path is empty if neither file exists.
+// @Tags files
+// @ID getHomeFile
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Success 200 {object} HomeFileResponse
+// @Router /workspaces/{workspace_name}/files/home [get]
+func (h *Handler) GetHomeFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "GetHomeFile",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		homeFile := ctx.Workspace.HomeFile
+		if homeFile != "" && !h.fileExists(ctx.UserID, ctx.Workspace.ID, homeFile) {
+			log.Debug("home file no longer exists",
+				"filePath", homeFile,
+			)
+			homeFile = ""
+		}
+
+		if homeFile == "" && h.DefaultHomeFile != "" && h.fileExists(ctx.UserID, ctx.Workspace.ID, h.DefaultHomeFile) {
+			homeFile = h.DefaultHomeFile
+		}
+
+		respondJSON(w, &HomeFileResponse{HomeFilePath: homeFile})
+	}
+}
+
+// UpdateHomeFile godoc
+// @Summary Update home file
+// @Description Sets the home file of the user's workspace
+// @Tags files
+// @ID updateHomeFile
+// @Security CookieAuth
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "File path"
+// @Success 204 "No Content - Home file updated successfully"
+// @Failure 400 {object} ErrorResponse "file_path is required"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 500 {object} ErrorResponse "Failed to update home file"
+// @Router /workspaces/{workspace_name}/files/home [put]
+func (h *Handler) UpdateHomeFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "UpdateHomeFile",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("file_path")
+		if filePath == "" {
+			log.Debug("missing file_path parameter")
+			respondError(w, "file_path is required", http.StatusBadRequest)
+			return
+		}
+
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil {
+			log.Error("failed to decode file path",
+				"filePath", filePath,
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		info, err := h.Storage.StatFile(ctx.UserID, ctx.Workspace.ID, decodedPath)
+		if err == nil && info.IsDir() {
+			err = os.ErrNotExist
+		}
+		if err != nil {
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				log.Debug("file not found",
+					"filePath", decodedPath,
+				)
+				respondError(w, "File not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("failed to validate file path",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to update home file", http.StatusInternalServerError)
+			return
+		}
+
+		if err := h.DB.UpdateHomeFile(ctx.Workspace.ID, decodedPath); err != nil {
+			log.Error("failed to update home file in database",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to update home file", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// fileExists reports whether filePath is a file in the workspace
+func (h *Handler) fileExists(userID, workspaceID int, filePath string) bool {
+	info, err := h.Storage.StatFile(userID, workspaceID, filePath)
+	return err == nil && !info.IsDir()
+}
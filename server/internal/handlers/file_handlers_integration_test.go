@@ -3,13 +3,24 @@
 package handlers_test
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/rand"
+	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"lemma/internal/app"
+	"lemma/internal/handlers"
 	"lemma/internal/models"
 	"lemma/internal/storage"
 
@@ -74,6 +85,77 @@ func testFileHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			assert.Equal(t, filePath, files[0].Name)
 		})
 
+		t.Run("get large file", func(t *testing.T) {
+			// Several megabytes, still below the stream threshold so it is read once and hashed
+			content := strings.Repeat("large file content\n", 200000)
+			filePath := "large.txt"
+
+			rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(filePath), strings.NewReader(content), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "text/plain; charset=utf-8", rr.Header().Get("Content-Type"))
+			assert.Equal(t, len(content), rr.Body.Len())
+			assert.Equal(t, content, rr.Body.String())
+			assert.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+
+			// Large files get an ETag of the content that was sent, so they can be saved with If-Match
+			sum := sha256.Sum256([]byte(content))
+			hash := hex.EncodeToString(sum[:])
+			assert.Equal(t, `"`+hash+`"`, rr.Header().Get("ETag"))
+			rr = h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(filePath), strings.NewReader(content), h.RegularTestUser, map[string]string{"If-Match": rr.Header().Get("ETag")})
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			// Hash-addressed requests are verified before streaming
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?hash="+hash+"&file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "private, max-age=31536000, immutable", rr.Header().Get("Cache-Control"))
+			assert.Equal(t, `"`+hash+`"`, rr.Header().Get("ETag"))
+			assert.Equal(t, len(content), rr.Body.Len())
+
+			rr = h.makeRequest(t, http.MethodDelete, baseURL+"?file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusNoContent, rr.Code)
+		})
+
+		t.Run("download large file", func(t *testing.T) {
+			// Larger than the stream threshold so the content is streamed. The file is
+			// written and read in chunks so the test does not hold it in memory.
+			const size = 20 << 20
+			workspacePath := h.Storage.GetWorkspacePath(workspace.UserID, workspace.ID)
+			file, err := os.Create(filepath.Join(workspacePath, "download.bin"))
+			require.NoError(t, err)
+			written := sha256.New()
+			_, err = io.CopyN(io.MultiWriter(file, written), rand.New(rand.NewSource(1)), size)
+			require.NoError(t, file.Close())
+			require.NoError(t, err)
+
+			server := httptest.NewServer(h.Server.Router())
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodGet, server.URL+baseURL+"/content?file_path=download.bin", nil)
+			require.NoError(t, err)
+			h.addAuthCookies(t, req, h.RegularTestUser)
+			resp, err := server.Client().Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, int64(size), resp.ContentLength)
+			// The hash of streamed content is not known before it is sent
+			assert.Empty(t, resp.Header.Get("ETag"))
+			assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+			read := sha256.New()
+			n, err := io.Copy(read, resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, int64(size), n)
+			assert.Equal(t, written.Sum(nil), read.Sum(nil))
+
+			rr := h.makeRequest(t, http.MethodDelete, baseURL+"?file_path=download.bin", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusNoContent, rr.Code)
+		})
+
 		t.Run("save and list nested files", func(t *testing.T) {
 			files := map[string]string{
 				"docs/readme.md":         "README content",
@@ -116,6 +198,71 @@ func testFileHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			assert.Len(t, notesDir.Children, 2) // meeting-notes.md and todo.md
 		})
 
+		t.Run("list files flat", func(t *testing.T) {
+			rr := h.makeRequest(t, http.MethodGet, baseURL, nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			var fileNodes []storage.FileNode
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&fileNodes))
+
+			var filePaths, allPaths []string
+			var collect func(nodes []storage.FileNode)
+			collect = func(nodes []storage.FileNode) {
+				for _, node := range nodes {
+					allPaths = append(allPaths, node.Path)
+					if len(node.Children) == 0 {
+						filePaths = append(filePaths, node.Path)
+					}
+					collect(node.Children)
+				}
+			}
+			collect(fileNodes)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"?format=flat", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			var paths []string
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&paths))
+			assert.Equal(t, filePaths, paths)
+			assert.Contains(t, paths, filepath.Join("docs", "api", "endpoints.md"))
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"?format=flat&dirs=true", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&paths))
+			assert.Equal(t, allPaths, paths)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"?format=csv", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+
+		t.Run("list files with stat", func(t *testing.T) {
+			rr := h.makeRequest(t, http.MethodGet, baseURL, nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.NotContains(t, rr.Body.String(), "modTime")
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"?stat=true", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var fileNodes []storage.FileNode
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&fileNodes))
+
+			var notesDir *storage.FileNode
+			for i := range fileNodes {
+				if fileNodes[i].Name == "notes" {
+					notesDir = &fileNodes[i]
+				}
+			}
+			require.NotNil(t, notesDir)
+			require.Len(t, notesDir.Children, 2)
+
+			var total int64
+			for _, child := range notesDir.Children {
+				assert.Positive(t, child.Size)
+				assert.False(t, child.ModTime.IsZero())
+				assert.False(t, child.ModTime.After(notesDir.ModTime))
+				total += child.Size
+			}
+			assert.Equal(t, total, notesDir.Size)
+		})
+
 		t.Run("lookup file by name", func(t *testing.T) {
 			// Look up a file that exists in multiple locations
 			filename := "readme.md"
@@ -139,6 +286,694 @@ func testFileHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			assert.Equal(t, http.StatusNotFound, rr.Code)
 		})
 
+		t.Run("lookup file by name case sensitivity", func(t *testing.T) {
+			// Files differing only by case
+			for _, path := range []string{"case/Notes.md", "case/notes.md", "case/sub/NOTES.md"} {
+				rr := h.makeRequest(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(path), "content", h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+			}
+
+			lookup := func(query string) []string {
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/lookup?"+query, nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var response handlers.LookupResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				return response.Paths
+			}
+
+			// Case-insensitive by default
+			assert.ElementsMatch(t,
+				[]string{"case/Notes.md", "case/notes.md", "case/sub/NOTES.md"},
+				lookup("filename=notes.md"))
+
+			// Exact match only
+			assert.Equal(t, []string{"case/Notes.md"}, lookup("filename=Notes.md&caseSensitive=true"))
+			assert.Equal(t, []string{"case/sub/NOTES.md"}, lookup("filename=NOTES.md&caseSensitive=true"))
+
+			rr := h.makeRequest(t, http.MethodGet, baseURL+"/lookup?filename=nOtEs.md&caseSensitive=true", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+
+			for _, path := range []string{"case/Notes.md", "case/notes.md", "case/sub/NOTES.md"} {
+				rr := h.makeRequest(t, http.MethodDelete, baseURL+"?file_path="+url.QueryEscape(path), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusNoContent, rr.Code)
+			}
+		})
+
+		t.Run("list files by extension", func(t *testing.T) {
+			for _, path := range []string{"ext/data.csv", "ext/sub/more.CSV", "ext/icon.svg"} {
+				rr := h.makeRequest(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(path), "content", h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+			}
+
+			byExtension := func(ext string) []string {
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/by-extension?ext="+url.QueryEscape(ext), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var response handlers.LookupResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				return response.Paths
+			}
+
+			assert.Equal(t, []string{"ext/data.csv", "ext/sub/more.CSV"}, byExtension("csv"))
+			assert.Equal(t, []string{"ext/data.csv", "ext/icon.svg", "ext/sub/more.CSV"}, byExtension("CSV, svg"))
+			assert.Equal(t, []string{}, byExtension("pdf"))
+
+			rr := h.makeRequest(t, http.MethodGet, baseURL+"/by-extension", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			for _, path := range []string{"ext/data.csv", "ext/sub/more.CSV", "ext/icon.svg"} {
+				rr := h.makeRequest(t, http.MethodDelete, baseURL+"?file_path="+url.QueryEscape(path), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusNoContent, rr.Code)
+			}
+		})
+
+		t.Run("search file contents", func(t *testing.T) {
+			files := map[string]string{
+				"search/once.md":  "Visit Zanzibar\n",
+				"search/twice.md": "zanzibar\nand\nZANZIBAR again\n",
+				"search/none.md":  "nothing here\n",
+			}
+			for path, content := range files {
+				rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(path), strings.NewReader(content), h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+			}
+
+			search := func(t *testing.T, query string) []storage.SearchResult {
+				t.Helper()
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/search?"+query, nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				var response handlers.SearchResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				return response.Results
+			}
+
+			results := search(t, "q=zanzibar")
+			require.Len(t, results, 2)
+			assert.Equal(t, "search/twice.md", results[0].Path)
+			assert.Equal(t, 2, results[0].MatchCount)
+			require.Len(t, results[0].Matches, 2)
+			assert.Equal(t, 3, results[0].Matches[1].Line)
+			assert.Equal(t, []string{"and"}, results[0].Matches[1].Before)
+			assert.Equal(t, "search/once.md", results[1].Path)
+
+			results = search(t, "q=zanzibar&limit=1")
+			require.Len(t, results, 1)
+			assert.Equal(t, "search/twice.md", results[0].Path)
+
+			results = search(t, "q=zanzibar&groupBy=match")
+			require.Len(t, results, 3)
+			for i, want := range []struct {
+				path string
+				line int
+			}{{"search/twice.md", 1}, {"search/twice.md", 3}, {"search/once.md", 1}} {
+				assert.Equal(t, want.path, results[i].Path)
+				assert.Equal(t, 1, results[i].MatchCount)
+				require.Len(t, results[i].Matches, 1)
+				assert.Equal(t, want.line, results[i].Matches[0].Line)
+			}
+
+			rr := h.makeRequest(t, http.MethodGet, baseURL+"/search?q=zanzibar&groupBy=line", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/search?q=", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/search?q=zanzibar&limit=-1", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			for path := range files {
+				rr := h.makeRequest(t, http.MethodDelete, baseURL+"?file_path="+url.QueryEscape(path), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusNoContent, rr.Code)
+			}
+		})
+
+		t.Run("batch save", func(t *testing.T) {
+			t.Run("successful batch", func(t *testing.T) {
+				files := []handlers.BatchSaveFile{
+					{Path: "batch-save/one.md", Content: "First note"},
+					{Path: "batch-save/two.md", Content: "Second note"},
+				}
+				rr := h.makeRequest(t, http.MethodPost, baseURL+"/batch-save", files, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var response handlers.BatchSaveResponse
+				err := json.NewDecoder(rr.Body).Decode(&response)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"batch-save/one.md", "batch-save/two.md"}, response.FilePaths)
+
+				for _, file := range files {
+					rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape(file.Path), nil, h.RegularTestUser)
+					require.Equal(t, http.StatusOK, rr.Code)
+					assert.Equal(t, file.Content, rr.Body.String())
+				}
+			})
+
+			t.Run("mid-batch failure rolls back", func(t *testing.T) {
+				// A regular file blocks creating a directory with the same name
+				rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("batch-blocker.md"), strings.NewReader("blocker"), h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				files := []handlers.BatchSaveFile{
+					{Path: "batch-save/one.md", Content: "Overwritten note"},
+					{Path: "batch-save/three.md", Content: "Third note"},
+					{Path: "batch-blocker.md/four.md", Content: "Fourth note"},
+				}
+				rr = h.makeRequest(t, http.MethodPost, baseURL+"/batch-save", files, h.RegularTestUser)
+				require.Equal(t, http.StatusInternalServerError, rr.Code)
+				assert.Contains(t, rr.Body.String(), "batch-blocker.md/four.md")
+
+				// Overwritten file is restored and new file is removed
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("batch-save/one.md"), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "First note", rr.Body.String())
+
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("batch-save/three.md"), nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+			})
+
+			t.Run("invalid path writes nothing", func(t *testing.T) {
+				files := []handlers.BatchSaveFile{
+					{Path: "batch-save/five.md", Content: "Fifth note"},
+					{Path: "../../../etc/passwd", Content: "malicious"},
+				}
+				rr := h.makeRequest(t, http.MethodPost, baseURL+"/batch-save", files, h.RegularTestUser)
+				require.Equal(t, http.StatusBadRequest, rr.Code)
+
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("batch-save/five.md"), nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+			})
+
+			t.Run("overwritten content is kept as a version", func(t *testing.T) {
+				files := []handlers.BatchSaveFile{{Path: "batch-save/two.md", Content: "Second note, revised"}}
+				rr := h.makeRequest(t, http.MethodPost, baseURL+"/batch-save", files, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/versions?file_path="+url.QueryEscape("batch-save/two.md"), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				var versions handlers.FileVersionsResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&versions))
+				require.Len(t, versions.Versions, 1)
+
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/versions/content?file_path="+url.QueryEscape("batch-save/two.md")+"&version="+url.QueryEscape(versions.Versions[0].Version), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "Second note", rr.Body.String())
+			})
+		})
+
+		t.Run("word count", func(t *testing.T) {
+			filePath := "wordcount/essay.md"
+			content := "# Essay\n\nThe quick brown fox.\n"
+
+			rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(filePath), strings.NewReader(content), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/wordcount?file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var stats storage.TextStats
+			err := json.NewDecoder(rr.Body).Decode(&stats)
+			require.NoError(t, err)
+			assert.Equal(t, 6, stats.Words)
+			assert.Equal(t, len(content), stats.Characters)
+			assert.Equal(t, 3, stats.Lines)
+			assert.Equal(t, 1, stats.ReadingTime)
+
+			// Binary files are skipped when aggregating a directory
+			rr = h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("wordcount/image.bin"), strings.NewReader("\x00\x01\x02"), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/wordcount?recursive=true&file_path="+url.QueryEscape("wordcount"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			err = json.NewDecoder(rr.Body).Decode(&stats)
+			require.NoError(t, err)
+			assert.Equal(t, 1, stats.Files)
+			assert.Equal(t, 6, stats.Words)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/wordcount?file_path="+url.QueryEscape("wordcount/image.bin"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/wordcount?file_path="+url.QueryEscape("missing.md"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		})
+
+		t.Run("tail", func(t *testing.T) {
+			filePath := "tail/journal.log"
+			content := "one\ntwo\nthree\nfour\nfive\n"
+
+			rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(filePath), strings.NewReader(content), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/tail?lines=2&file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "four\nfive\n", rr.Body.String())
+			assert.Equal(t, "text/plain; charset=utf-8", rr.Header().Get("Content-Type"))
+
+			// Defaults to the last 10 lines
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/tail?file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, content, rr.Body.String())
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/tail?lines=0&file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/tail", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			rr = h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("tail/image.bin"), strings.NewReader("\x00\x01\x02"), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/tail?file_path="+url.QueryEscape("tail/image.bin"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/tail?file_path="+url.QueryEscape("tail/missing.log"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		})
+
+		t.Run("hash", func(t *testing.T) {
+			filePath := "hash/hello.txt"
+
+			rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(filePath), strings.NewReader("hello"), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/hash?file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var response handlers.FileHashResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			assert.Equal(t, filePath, response.FilePath)
+			assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", response.Hash)
+			assert.Equal(t, int64(5), response.Size)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/hash", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/hash?file_path="+url.QueryEscape("hash/missing.txt"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/hash?file_path="+url.QueryEscape("../../etc/passwd"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+
+		t.Run("render", func(t *testing.T) {
+			filePath := "render/note.md"
+			content := "# Note\n\n- [x] done\n\n<script>alert(1)</script>\n"
+
+			rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(filePath), strings.NewReader(content), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/render?file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+			assert.Contains(t, rr.Body.String(), "<h1>Note</h1>")
+			assert.Contains(t, rr.Body.String(), `<input checked="" disabled="" type="checkbox"> done`)
+			assert.NotContains(t, rr.Body.String(), "script")
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/render", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			rr = h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("render/image.bin"), strings.NewReader("\x00\x01\x02"), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/render?file_path="+url.QueryEscape("render/image.bin"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/render?file_path="+url.QueryEscape("render/missing.md"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/render?file_path="+url.QueryEscape("../../etc/passwd"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+
+		t.Run("save hooks", func(t *testing.T) {
+			content := "# Title  \r\nBody\t\r\n"
+
+			// Hooks are disabled by default
+			rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("hooks/raw.md"), strings.NewReader(content), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("hooks/raw.md"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, content, rr.Body.String())
+
+			hooksWorkspace := &models.Workspace{
+				Name:                   "Save Hooks Workspace",
+				NormalizeLineEndings:   true,
+				TrimTrailingWhitespace: true,
+			}
+			rr = h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", hooksWorkspace, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(hooksWorkspace))
+			assert.True(t, hooksWorkspace.NormalizeLineEndings)
+			assert.True(t, hooksWorkspace.TrimTrailingWhitespace)
+
+			hooksURL := fmt.Sprintf("/api/v1/workspaces/%s/files", url.PathEscape(hooksWorkspace.Name))
+			rr = h.makeRequestRaw(t, http.MethodPost, hooksURL+"?file_path="+url.QueryEscape("note.md"), strings.NewReader(content), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, hooksURL+"/content?file_path="+url.QueryEscape("note.md"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "# Title\nBody\n", rr.Body.String())
+
+			// Batch saves apply the hooks as well
+			files := []handlers.BatchSaveFile{{Path: "batch.md", Content: content}}
+			rr = h.makeRequest(t, http.MethodPost, hooksURL+"/batch-save", files, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			rr = h.makeRequest(t, http.MethodGet, hooksURL+"/content?file_path="+url.QueryEscape("batch.md"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "# Title\nBody\n", rr.Body.String())
+		})
+
+		t.Run("markdown lint", func(t *testing.T) {
+			note := "# Note\n\nNo frontmatter\n"
+			valid := "---\ntitle: Note\n---\n# Note\n"
+
+			for _, strict := range []bool{true, false} {
+				lintWorkspace := &models.Workspace{
+					Name:                    fmt.Sprintf("Lint Workspace %t", strict),
+					LintMarkdown:            true,
+					LintStrict:              strict,
+					LintRequiredFrontmatter: "title",
+				}
+				rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", lintWorkspace, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				lintURL := fmt.Sprintf("/api/v1/workspaces/%s/files", url.PathEscape(lintWorkspace.Name))
+				rr = h.makeRequestRaw(t, http.MethodPost, lintURL+"?file_path="+url.QueryEscape("note.md"), strings.NewReader(note), h.RegularTestUser)
+
+				if strict {
+					require.Equal(t, http.StatusBadRequest, rr.Code)
+					var response handlers.LintErrorResponse
+					require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+					assert.Equal(t, handlers.ErrorCodeLintFailed, response.Code)
+					require.Len(t, response.Issues, 1)
+					assert.Equal(t, "required-frontmatter", response.Issues[0].Rule)
+
+					rr = h.makeRequest(t, http.MethodGet, lintURL+"/content?file_path="+url.QueryEscape("note.md"), nil, h.RegularTestUser)
+					assert.Equal(t, http.StatusNotFound, rr.Code)
+
+					// A batch with a failing file is rejected as a whole
+					files := []handlers.BatchSaveFile{
+						{Path: "batch.md", Content: valid},
+						{Path: "note.md", Content: note},
+					}
+					rr = h.makeRequest(t, http.MethodPost, lintURL+"/batch-save", files, h.RegularTestUser)
+					require.Equal(t, http.StatusBadRequest, rr.Code)
+					require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+					assert.Equal(t, handlers.ErrorCodeLintFailed, response.Code)
+					assert.Contains(t, response.Message, "note.md")
+
+					rr = h.makeRequest(t, http.MethodGet, lintURL+"/content?file_path="+url.QueryEscape("batch.md"), nil, h.RegularTestUser)
+					assert.Equal(t, http.StatusNotFound, rr.Code)
+				} else {
+					require.Equal(t, http.StatusOK, rr.Code)
+					var response handlers.SaveFileResponse
+					require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+					require.Len(t, response.Warnings, 1)
+					assert.Equal(t, "required-frontmatter", response.Warnings[0].Rule)
+
+					rr = h.makeRequest(t, http.MethodGet, lintURL+"/content?file_path="+url.QueryEscape("note.md"), nil, h.RegularTestUser)
+					require.Equal(t, http.StatusOK, rr.Code)
+					assert.Equal(t, note, rr.Body.String())
+				}
+
+				rr = h.makeRequestRaw(t, http.MethodPost, lintURL+"?file_path="+url.QueryEscape("valid.md"), strings.NewReader(valid), h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				var response handlers.SaveFileResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				assert.Empty(t, response.Warnings)
+
+				// Only markdown files are linted
+				rr = h.makeRequestRaw(t, http.MethodPost, lintURL+"?file_path="+url.QueryEscape("plain.txt"), strings.NewReader(note), h.RegularTestUser)
+				assert.Equal(t, http.StatusOK, rr.Code)
+			}
+		})
+
+		t.Run("encoding", func(t *testing.T) {
+			// "Café € naïve" in Windows-1252, which is not valid UTF-8
+			legacy := "Caf\xe9 \x80 na\xefve"
+			decoded := "Café € naïve"
+			contentURL := baseURL + "/content?file_path=" + url.QueryEscape("legacy.txt")
+
+			rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("legacy.txt"), strings.NewReader(legacy), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			// Without an encoding the stored bytes are returned as they are
+			rr = h.makeRequest(t, http.MethodGet, contentURL, nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, legacy, rr.Body.String())
+
+			rr = h.makeRequest(t, http.MethodGet, contentURL+"&encoding=utf-8", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, legacy, rr.Body.String())
+
+			rr = h.makeRequest(t, http.MethodGet, contentURL+"&encoding=windows-1252", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, decoded, rr.Body.String())
+
+			// The ETag is the checksum of the stored bytes, not of the converted content
+			sum := sha256.Sum256([]byte(legacy))
+			assert.Equal(t, `"`+hex.EncodeToString(sum[:])+`"`, rr.Header().Get("ETag"))
+			rr = h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("legacy.txt")+"&encoding=windows-1252", strings.NewReader(decoded), h.RegularTestUser, map[string]string{"If-Match": rr.Header().Get("ETag")})
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			// Saving with an encoding converts the UTF-8 body back to the legacy bytes
+			savedURL := baseURL + "?file_path=" + url.QueryEscape("saved.txt") + "&encoding=windows-1252"
+			rr = h.makeRequestRaw(t, http.MethodPost, savedURL, strings.NewReader(decoded), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("saved.txt"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, legacy, rr.Body.String())
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("saved.txt")+"&encoding=windows-1252", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, decoded, rr.Body.String())
+
+			t.Run("unsupported encoding", func(t *testing.T) {
+				rr := h.makeRequest(t, http.MethodGet, contentURL+"&encoding=klingon", nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+				rr = h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("klingon.txt")+"&encoding=klingon", strings.NewReader(decoded), h.RegularTestUser)
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+			})
+
+			t.Run("content not representable", func(t *testing.T) {
+				rr := h.makeRequestRaw(t, http.MethodPost, savedURL, strings.NewReader("日本語"), h.RegularTestUser)
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+				// The file is left unchanged
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("saved.txt"), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, legacy, rr.Body.String())
+			})
+		})
+
+		t.Run("cache headers", func(t *testing.T) {
+			content := "note content"
+			sum := sha256.Sum256([]byte(content))
+			hash := hex.EncodeToString(sum[:])
+			contentURL := baseURL + "/content?file_path=" + url.QueryEscape("cached.md")
+
+			rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("cached.md"), strings.NewReader(content), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			// Notes are revalidated with their ETag
+			rr = h.makeRequest(t, http.MethodGet, contentURL, nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+			etag := rr.Header().Get("ETag")
+			assert.Equal(t, `"`+hash+`"`, etag)
+
+			req := h.newRequest(t, http.MethodGet, contentURL, nil)
+			req.Header.Set("If-None-Match", etag)
+			h.addAuthCookies(t, req, h.RegularTestUser)
+			rr = h.executeRequest(req)
+			assert.Equal(t, http.StatusNotModified, rr.Code)
+			assert.Empty(t, rr.Body.String())
+
+			req = h.newRequest(t, http.MethodGet, contentURL, nil)
+			req.Header.Set("If-None-Match", `"outdated"`)
+			h.addAuthCookies(t, req, h.RegularTestUser)
+			rr = h.executeRequest(req)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, content, rr.Body.String())
+
+			// Content requested by its hash never changes
+			rr = h.makeRequest(t, http.MethodGet, contentURL+"&hash="+hash, nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "private, max-age=31536000, immutable", rr.Header().Get("Cache-Control"))
+
+			// A stale hash gets the current content, which has to be revalidated
+			rr = h.makeRequest(t, http.MethodGet, contentURL+"&hash=0123", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+			assert.Equal(t, content, rr.Body.String())
+		})
+
+		t.Run("save with if-match", func(t *testing.T) {
+			saveURL := baseURL + "?file_path=" + url.QueryEscape("shared.md")
+			saveIfMatch := func(t *testing.T, etag, content string) *httptest.ResponseRecorder {
+				t.Helper()
+				return h.makeRequestRaw(t, http.MethodPost, saveURL, strings.NewReader(content), h.RegularTestUser, map[string]string{"If-Match": etag})
+			}
+
+			rr := h.makeRequestRaw(t, http.MethodPost, saveURL, strings.NewReader("original"), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			// Both tabs read the same version
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("shared.md"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			etag := rr.Header().Get("ETag")
+			require.NotEmpty(t, etag)
+
+			t.Run("matching write succeeds", func(t *testing.T) {
+				rr := saveIfMatch(t, etag, "first tab")
+				require.Equal(t, http.StatusOK, rr.Code)
+				sum := sha256.Sum256([]byte("first tab"))
+				assert.Equal(t, `"`+hex.EncodeToString(sum[:])+`"`, rr.Header().Get("ETag"))
+			})
+
+			t.Run("stale write returns current content", func(t *testing.T) {
+				rr := saveIfMatch(t, etag, "second tab")
+				require.Equal(t, http.StatusConflict, rr.Code)
+
+				var response handlers.FileConflictResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				assert.Equal(t, handlers.ErrorCodeFileConflict, response.Code)
+				assert.Equal(t, "first tab", response.Content)
+				assert.Equal(t, rr.Header().Get("ETag"), response.ETag)
+
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("shared.md"), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "first tab", rr.Body.String())
+			})
+		})
+
+		t.Run("resolve includes", func(t *testing.T) {
+			files := map[string]string{
+				"page.md":        "# Page\n{{include: parts/intro.md}}\n",
+				"parts/intro.md": "Intro\n",
+			}
+
+			// Includes are only resolved in workspaces that enable them
+			rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("page.md"), strings.NewReader(files["page.md"]), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?resolveIncludes=true&file_path="+url.QueryEscape("page.md"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, files["page.md"], rr.Body.String())
+
+			includesWorkspace := &models.Workspace{
+				Name:            "Includes Workspace",
+				ResolveIncludes: true,
+			}
+			rr = h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", includesWorkspace, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			includesURL := fmt.Sprintf("/api/v1/workspaces/%s/files", url.PathEscape(includesWorkspace.Name))
+			for path, content := range files {
+				rr = h.makeRequestRaw(t, http.MethodPost, includesURL+"?file_path="+url.QueryEscape(path), strings.NewReader(content), h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+			}
+
+			rr = h.makeRequest(t, http.MethodGet, includesURL+"/content?resolveIncludes=true&file_path="+url.QueryEscape("page.md"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "# Page\nIntro\n", rr.Body.String())
+
+			// The ETag is the checksum of the file itself, but the content depends on the
+			// included files and is never answered with Not Modified
+			sum := sha256.Sum256([]byte(files["page.md"]))
+			etag := `"` + hex.EncodeToString(sum[:]) + `"`
+			assert.Equal(t, etag, rr.Header().Get("ETag"))
+			rr = h.makeRequestRaw(t, http.MethodPost, includesURL+"?file_path="+url.QueryEscape("parts/intro.md"), strings.NewReader("Changed\n"), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			rr = h.makeRequestRaw(t, http.MethodGet, includesURL+"/content?resolveIncludes=true&file_path="+url.QueryEscape("page.md"), nil, h.RegularTestUser, map[string]string{"If-None-Match": etag})
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "# Page\nChanged\n", rr.Body.String())
+
+			// Without the parameter the raw content is returned
+			rr = h.makeRequest(t, http.MethodGet, includesURL+"/content?file_path="+url.QueryEscape("page.md"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, files["page.md"], rr.Body.String())
+
+			rr = h.makeRequest(t, http.MethodGet, includesURL+"/content?resolveIncludes=true&file_path="+url.QueryEscape("missing.md"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+
+			// Rendering always resolves the includes of the workspace
+			rr = h.makeRequest(t, http.MethodGet, includesURL+"/render?file_path="+url.QueryEscape("page.md"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, rr.Body.String(), "Changed")
+			assert.NotContains(t, rr.Body.String(), "include:")
+
+			// Each level includes the next one several times, which would multiply the content with every level
+			for level := 0; level < 8; level++ {
+				directive := fmt.Sprintf("{{include: bomb/%d.md}}", level+1)
+				content := strings.Repeat(directive+"\n", 4)
+				rr = h.makeRequestRaw(t, http.MethodPost, includesURL+"?file_path="+url.QueryEscape(fmt.Sprintf("bomb/%d.md", level)), strings.NewReader(content), h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+			}
+			rr = h.makeRequest(t, http.MethodGet, includesURL+"/content?resolveIncludes=true&file_path="+url.QueryEscape("bomb/0.md"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			rr = h.makeRequest(t, http.MethodGet, includesURL+"/render?file_path="+url.QueryEscape("bomb/0.md"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		})
+
+		t.Run("changed files", func(t *testing.T) {
+			for _, path := range []string{"sync/old.md", "sync/new.md"} {
+				rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(path), strings.NewReader("first"), h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+			}
+
+			// Both files were last synced an hour ago, then one of them is modified
+			workspacePath := h.Storage.GetWorkspacePath(workspace.UserID, workspace.ID)
+			past := time.Now().Add(-time.Hour)
+			for _, path := range []string{"sync/old.md", "sync/new.md"} {
+				require.NoError(t, os.Chtimes(filepath.Join(workspacePath, path), past, past))
+			}
+			since := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+
+			rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("sync/new.md"), strings.NewReader("second"), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/changed?since="+url.QueryEscape(since), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var response handlers.ChangedFilesResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			paths := make([]string, 0, len(response.Files))
+			for _, file := range response.Files {
+				paths = append(paths, file.Path)
+			}
+			assert.Contains(t, paths, "sync/new.md")
+			assert.NotContains(t, paths, "sync/old.md")
+			assert.False(t, response.CheckedAt.IsZero())
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/changed?since=yesterday", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/changed", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			// Deletions require git
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/changed?commit=HEAD&since="+url.QueryEscape(since), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+
+		t.Run("recent files", func(t *testing.T) {
+			rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("recent/latest.md"), strings.NewReader("latest"), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/recent?limit=1", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var response handlers.RecentFilesResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			require.Len(t, response.Files, 1)
+			assert.Equal(t, "recent/latest.md", response.Files[0].Path)
+			assert.Equal(t, int64(len("latest")), response.Files[0].Size)
+			assert.False(t, response.Files[0].ModTime.IsZero())
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/recent?limit=-1", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+
 		t.Run("delete file", func(t *testing.T) {
 			filePath := "to-delete.md"
 			content := "This file will be deleted"
@@ -154,6 +989,14 @@ func testFileHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			// Verify file is gone
 			rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
 			assert.Equal(t, http.StatusNotFound, rr.Code)
+
+			// Deleting again fails by default
+			rr = h.makeRequest(t, http.MethodDelete, baseURL+"?file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+
+			// A retried delete succeeds with ifExists
+			rr = h.makeRequest(t, http.MethodDelete, baseURL+"?ifExists=true&file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusNoContent, rr.Code)
 		})
 
 		t.Run("move file", func(t *testing.T) {
@@ -180,6 +1023,162 @@ func testFileHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			assert.Equal(t, content, rr.Body.String())
 		})
 
+		t.Run("copy file", func(t *testing.T) {
+			srcPath := "template.md"
+			destPath := "copies/from-template.md"
+			content := "Template content"
+
+			rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(srcPath), strings.NewReader(content), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			copyURL := baseURL + "/copy?src_path=" + url.QueryEscape(srcPath) + "&dest_path=" + url.QueryEscape(destPath)
+			rr = h.makeRequest(t, http.MethodPost, copyURL, nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			// Both files exist with the same content
+			for _, path := range []string{srcPath, destPath} {
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape(path), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, content, rr.Body.String())
+			}
+
+			t.Run("existing destination", func(t *testing.T) {
+				rr := h.makeRequest(t, http.MethodPost, copyURL, nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusConflict, rr.Code)
+
+				rr = h.makeRequest(t, http.MethodPost, copyURL+"&overwrite=true", nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusOK, rr.Code)
+			})
+
+			t.Run("missing source", func(t *testing.T) {
+				rr := h.makeRequest(t, http.MethodPost, baseURL+"/copy?src_path=missing.md&dest_path=copy.md", nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+			})
+		})
+
+		t.Run("transfer file between workspaces", func(t *testing.T) {
+			srcPath := "to-transfer.md"
+			destPath := "notes/transferred.md"
+			content := "This file will be transferred"
+
+			// Create destination workspace with auto-commit enabled
+			destWorkspace := &models.Workspace{
+				UserID:         h.RegularTestUser.session.UserID,
+				Name:           "Transfer Destination",
+				GitEnabled:     true,
+				GitURL:         "https://github.com/test/repo.git",
+				GitUser:        "testuser",
+				GitToken:       "testtoken",
+				GitAutoCommit:  true,
+				GitCommitName:  "Test User",
+				GitCommitEmail: "test@example.com",
+			}
+			rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", destWorkspace, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			h.MockGit.Reset()
+
+			rr = h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(srcPath), strings.NewReader(content), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			transferReq := handlers.TransferFileRequest{
+				SourcePath:           srcPath,
+				DestinationWorkspace: destWorkspace.Name,
+				DestinationPath:      destPath,
+			}
+			rr = h.makeRequest(t, http.MethodPost, baseURL+"/transfer", transferReq, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			// Verify source is gone
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape(srcPath), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+
+			// Verify destination exists with correct content
+			destURL := fmt.Sprintf("/api/v1/workspaces/%s/files", url.PathEscape(destWorkspace.Name))
+			rr = h.makeRequest(t, http.MethodGet, destURL+"/content?file_path="+url.QueryEscape(destPath), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, content, rr.Body.String())
+
+			// Only the destination workspace has auto-commit enabled
+			assert.Equal(t, 1, h.MockGit.GetCommitCount())
+			assert.Equal(t, "Create "+destPath, h.MockGit.GetLastCommitMessage())
+
+			t.Run("unknown destination workspace", func(t *testing.T) {
+				transferReq := handlers.TransferFileRequest{
+					SourcePath:           destPath,
+					DestinationWorkspace: "nonexistent",
+					DestinationPath:      destPath,
+				}
+				rr := h.makeRequest(t, http.MethodPost, baseURL+"/transfer", transferReq, h.RegularTestUser)
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+			})
+
+			t.Run("onto itself", func(t *testing.T) {
+				rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("transfer/self.md"), strings.NewReader(content), h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				for _, dstPath := range []string{"transfer/self.md", "./transfer/../transfer/self.md"} {
+					transferReq := handlers.TransferFileRequest{
+						SourcePath:           "transfer/self.md",
+						DestinationWorkspace: workspace.Name,
+						DestinationPath:      dstPath,
+						Overwrite:            true,
+					}
+					rr = h.makeRequest(t, http.MethodPost, baseURL+"/transfer", transferReq, h.RegularTestUser)
+					assert.Equal(t, http.StatusBadRequest, rr.Code)
+				}
+
+				// The file is untouched
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("transfer/self.md"), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, content, rr.Body.String())
+			})
+
+			t.Run("existing destination", func(t *testing.T) {
+				rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("transfer/replacement.md"), strings.NewReader("replacement"), h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				transferReq := handlers.TransferFileRequest{
+					SourcePath:           "transfer/replacement.md",
+					DestinationWorkspace: destWorkspace.Name,
+					DestinationPath:      destPath,
+				}
+				rr = h.makeRequest(t, http.MethodPost, baseURL+"/transfer", transferReq, h.RegularTestUser)
+				assert.Equal(t, http.StatusConflict, rr.Code)
+
+				// Source and destination are both kept
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("transfer/replacement.md"), nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusOK, rr.Code)
+				rr = h.makeRequest(t, http.MethodGet, destURL+"/content?file_path="+url.QueryEscape(destPath), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, content, rr.Body.String())
+
+				transferReq.Overwrite = true
+				rr = h.makeRequest(t, http.MethodPost, baseURL+"/transfer", transferReq, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				rr = h.makeRequest(t, http.MethodGet, destURL+"/content?file_path="+url.QueryEscape(destPath), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "replacement", rr.Body.String())
+
+				// The replaced content is kept as a file version
+				rr = h.makeRequest(t, http.MethodGet, destURL+"/versions?file_path="+url.QueryEscape(destPath), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				var versions handlers.FileVersionsResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&versions))
+				assert.Len(t, versions.Versions, 1)
+			})
+
+			t.Run("missing source file", func(t *testing.T) {
+				transferReq := handlers.TransferFileRequest{
+					SourcePath:           "missing.md",
+					DestinationWorkspace: destWorkspace.Name,
+					DestinationPath:      destPath,
+				}
+				rr := h.makeRequest(t, http.MethodPost, baseURL+"/transfer", transferReq, h.RegularTestUser)
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+			})
+		})
+
 		t.Run("rename file in directory", func(t *testing.T) {
 			srcPath := "folder/old-name.md"
 			destPath := "folder/new-name.md"
@@ -239,6 +1238,62 @@ func testFileHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			assert.Equal(t, http.StatusNotFound, rr.Code)
 		})
 
+		t.Run("home file", func(t *testing.T) {
+			getHomeFile := func() string {
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/home", nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var response handlers.HomeFileResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				return response.HomeFilePath
+			}
+
+			// Nothing set and no default file present
+			assert.Empty(t, getHomeFile())
+
+			// Falls back to the default file once it exists
+			rr := h.makeRequest(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("index.md"), "# Index", h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "index.md", getHomeFile())
+
+			// Explicitly set home file takes precedence
+			rr = h.makeRequest(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape("notes/home.md"), "# Home", h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			rr = h.makeRequest(t, http.MethodPut, baseURL+"/home?file_path="+url.QueryEscape("notes/home.md"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusNoContent, rr.Code)
+			assert.Equal(t, "notes/home.md", getHomeFile())
+
+			// Setting a missing or invalid file is rejected
+			rr = h.makeRequest(t, http.MethodPut, baseURL+"/home?file_path="+url.QueryEscape("nonexistent.md"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+			rr = h.makeRequest(t, http.MethodPut, baseURL+"/home?file_path="+url.QueryEscape("../outside.md"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			rr = h.makeRequest(t, http.MethodPut, baseURL+"/home", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			rr = h.makeRequest(t, http.MethodPut, baseURL+"/home?file_path=notes", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+			assert.Equal(t, "notes/home.md", getHomeFile())
+
+			// Updating the workspace keeps the home file
+			rr = h.makeRequest(t, http.MethodGet, strings.TrimSuffix(baseURL, "/files"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			var current models.Workspace
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&current))
+			current.HomeFile = "../outside.md"
+			rr = h.makeRequest(t, http.MethodPut, strings.TrimSuffix(baseURL, "/files"), &current, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "notes/home.md", getHomeFile())
+
+			// Deleted home file falls back to the default
+			rr = h.makeRequest(t, http.MethodDelete, baseURL+"?file_path="+url.QueryEscape("notes/home.md"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusNoContent, rr.Code)
+			assert.Equal(t, "index.md", getHomeFile())
+
+			rr = h.makeRequest(t, http.MethodDelete, baseURL+"?file_path="+url.QueryEscape("index.md"), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusNoContent, rr.Code)
+			assert.Empty(t, getHomeFile())
+		})
+
 		t.Run("unauthorized access", func(t *testing.T) {
 			tests := []struct {
 				name   string
@@ -252,6 +1307,7 @@ func testFileHandlers(t *testing.T, dbConfig DatabaseConfig) {
 				{"delete file", http.MethodDelete, baseURL + "?file_path=" + url.QueryEscape("test.md"), nil},
 				{"get last file", http.MethodGet, baseURL + "/last", nil},
 				{"update last file", http.MethodPut, baseURL + "/last?file_path=" + url.QueryEscape("test.md"), nil},
+				{"get home file", http.MethodGet, baseURL + "/home", nil},
 			}
 
 			for _, tc := range tests {
@@ -361,6 +1417,241 @@ func testFileHandlers(t *testing.T, dbConfig DatabaseConfig) {
 				rr := h.makeUploadRequest(t, baseURL+"/upload?file_path="+url.QueryEscape(invalidPath), files, h.RegularTestUser)
 				assert.Equal(t, http.StatusBadRequest, rr.Code)
 			})
+
+			t.Run("per-file results", func(t *testing.T) {
+				// The oversized file is streamed so the test does not hold it in memory
+				body, writer := io.Pipe()
+				form := multipart.NewWriter(writer)
+				go func() {
+					parts := []struct {
+						name string
+						size int64
+					}{
+						{"valid.md", 5},
+						{"empty.md", 0},
+						{"huge.bin", 100*1024*1024 + 1},
+						{"also-valid.md", 5},
+					}
+					for _, part := range parts {
+						w, err := form.CreateFormFile("files", part.name)
+						if err != nil {
+							writer.CloseWithError(err)
+							return
+						}
+						if _, err := io.CopyN(w, strings.NewReader(strings.Repeat("a", 1<<20)), min(part.size, 1<<20)); err != nil {
+							writer.CloseWithError(err)
+							return
+						}
+						for remaining := part.size - 1<<20; remaining > 0; remaining -= 1 << 20 {
+							if _, err := w.Write(make([]byte, min(remaining, 1<<20))); err != nil {
+								writer.CloseWithError(err)
+								return
+							}
+						}
+					}
+					writer.CloseWithError(form.Close())
+				}()
+
+				rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"/upload?file_path="+url.QueryEscape("mixed"), body, h.RegularTestUser,
+					map[string]string{"Content-Type": form.FormDataContentType()})
+				require.Equal(t, http.StatusMultiStatus, rr.Code)
+
+				var response handlers.UploadFilesResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				assert.Equal(t, []string{"mixed/valid.md", "mixed/also-valid.md"}, response.FilePaths)
+				require.Len(t, response.Results, 4)
+
+				expected := []handlers.UploadFileResult{
+					{Filename: "valid.md", Path: "mixed/valid.md", Status: "uploaded"},
+					{Filename: "empty.md", Path: "mixed/empty.md", Status: "failed", Error: "Empty file uploaded"},
+					{Filename: "huge.bin", Path: "mixed/huge.bin", Status: "failed", Error: "File too large"},
+					{Filename: "also-valid.md", Path: "mixed/also-valid.md", Status: "uploaded"},
+				}
+				assert.Equal(t, expected, response.Results)
+
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("mixed/also-valid.md"), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "aaaaa", rr.Body.String())
+
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("mixed/huge.bin"), nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+			})
+
+			t.Run("conflict handling", func(t *testing.T) {
+				dir := "conflicts"
+				upload := func(t *testing.T, onConflict, content string) handlers.UploadFilesResponse {
+					t.Helper()
+					uploadURL := baseURL + "/upload?file_path=" + url.QueryEscape(dir)
+					if onConflict != "" {
+						uploadURL += "&onConflict=" + onConflict
+					}
+					rr := h.makeUploadRequest(t, uploadURL, map[string]string{"note.md": content}, h.RegularTestUser)
+					require.Equal(t, http.StatusOK, rr.Code)
+
+					var response handlers.UploadFilesResponse
+					require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+					return response
+				}
+				getContent := func(t *testing.T, filePath string) string {
+					t.Helper()
+					rr := h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape(filePath), nil, h.RegularTestUser)
+					require.Equal(t, http.StatusOK, rr.Code)
+					return rr.Body.String()
+				}
+
+				upload(t, "", "original")
+
+				t.Run("overwrite by default", func(t *testing.T) {
+					response := upload(t, "", "overwritten")
+					assert.Equal(t, []string{dir + "/note.md"}, response.FilePaths)
+					assert.Empty(t, response.SkippedPaths)
+					assert.Equal(t, "overwritten", getContent(t, dir+"/note.md"))
+
+					response = upload(t, "overwrite", "original")
+					assert.Equal(t, []string{dir + "/note.md"}, response.FilePaths)
+					assert.Equal(t, "original", getContent(t, dir+"/note.md"))
+				})
+
+				t.Run("skip", func(t *testing.T) {
+					response := upload(t, "skip", "skipped")
+					assert.Empty(t, response.FilePaths)
+					assert.Equal(t, []string{dir + "/note.md"}, response.SkippedPaths)
+					assert.Equal(t, "original", getContent(t, dir+"/note.md"))
+				})
+
+				t.Run("rename", func(t *testing.T) {
+					response := upload(t, "rename", "first copy")
+					assert.Equal(t, []string{dir + "/note (1).md"}, response.FilePaths)
+
+					response = upload(t, "rename", "second copy")
+					assert.Equal(t, []string{dir + "/note (2).md"}, response.FilePaths)
+
+					assert.Equal(t, "original", getContent(t, dir+"/note.md"))
+					assert.Equal(t, "first copy", getContent(t, dir+"/note (1).md"))
+					assert.Equal(t, "second copy", getContent(t, dir+"/note (2).md"))
+				})
+
+				t.Run("invalid mode", func(t *testing.T) {
+					rr := h.makeUploadRequest(t, baseURL+"/upload?file_path="+dir+"&onConflict=merge", map[string]string{"note.md": "x"}, h.RegularTestUser)
+					assert.Equal(t, http.StatusBadRequest, rr.Code)
+				})
+			})
 		})
 	})
 }
+
+func TestSaveFileContentLimit_Integration(t *testing.T) {
+	runWithDatabases(t, testSaveFileContentLimit)
+}
+
+func testSaveFileContentLimit(t *testing.T, dbConfig DatabaseConfig) {
+	const limit = 1024
+
+	h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+		config.MaxContentSize = limit
+	})
+	defer h.teardown(t)
+
+	workspace := &models.Workspace{Name: "Limited Workspace"}
+	rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, h.RegularTestUser)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	fileURL := fmt.Sprintf("/api/v1/workspaces/%s/files?file_path=%s", url.PathEscape(workspace.Name), url.QueryEscape("note.md"))
+
+	t.Run("at the limit", func(t *testing.T) {
+		rr := h.makeRequestRaw(t, http.MethodPost, fileURL, strings.NewReader(strings.Repeat("x", limit)), h.RegularTestUser)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("over the limit", func(t *testing.T) {
+		rr := h.makeRequestRaw(t, http.MethodPost, fileURL, strings.NewReader(strings.Repeat("y", limit+1)), h.RegularTestUser)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+
+		// The stored file is unchanged
+		rr = h.makeRequest(t, http.MethodGet, strings.Replace(fileURL, "/files?", "/files/content?", 1), nil, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, strings.Repeat("x", limit), rr.Body.String())
+	})
+
+	t.Run("batch save over the limit", func(t *testing.T) {
+		baseURL := fmt.Sprintf("/api/v1/workspaces/%s/files", url.PathEscape(workspace.Name))
+		files := []handlers.BatchSaveFile{
+			{Path: "batch.md", Content: strings.Repeat("z", limit)},
+		}
+		rr := h.makeRequest(t, http.MethodPost, baseURL+"/batch-save", files, h.RegularTestUser)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("batch.md"), nil, h.RegularTestUser)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestFileSizeLimits_Integration(t *testing.T) {
+	runWithDatabases(t, testFileSizeLimits)
+}
+
+func testFileSizeLimits(t *testing.T, dbConfig DatabaseConfig) {
+	const (
+		fileLimit   = 1024
+		uploadLimit = 8 * 1024
+	)
+
+	h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+		config.MaxFileSize = fileLimit
+		config.MaxUploadSize = uploadLimit
+		config.MaxUploadMemory = 512
+	})
+	defer h.teardown(t)
+
+	workspace := &models.Workspace{Name: "Size Limited Workspace"}
+	rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, h.RegularTestUser)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	baseURL := fmt.Sprintf("/api/v1/workspaces/%s/files", url.PathEscape(workspace.Name))
+
+	t.Run("save", func(t *testing.T) {
+		fileURL := baseURL + "?file_path=" + url.QueryEscape("note.md")
+
+		rr := h.makeRequestRaw(t, http.MethodPost, fileURL, strings.NewReader(strings.Repeat("x", fileLimit)), h.RegularTestUser)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		rr = h.makeRequestRaw(t, http.MethodPost, fileURL, strings.NewReader(strings.Repeat("y", fileLimit+1)), h.RegularTestUser)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+
+	t.Run("batch save", func(t *testing.T) {
+		files := []handlers.BatchSaveFile{
+			{Path: "batch/small.md", Content: "small"},
+			{Path: "batch/big.md", Content: strings.Repeat("y", fileLimit+1)},
+		}
+		rr := h.makeRequest(t, http.MethodPost, baseURL+"/batch-save", files, h.RegularTestUser)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+
+		// Nothing is written when any file is too large
+		rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("batch/small.md"), nil, h.RegularTestUser)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("upload", func(t *testing.T) {
+		uploadURL := baseURL + "/upload?file_path=" + url.QueryEscape("uploads")
+
+		// Files beyond the memory limit are parsed from temporary files
+		rr := h.makeUploadRequest(t, uploadURL, map[string]string{"under.md": strings.Repeat("x", fileLimit-1)}, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = h.makeUploadRequest(t, uploadURL, map[string]string{"over.md": strings.Repeat("x", fileLimit+1)}, h.RegularTestUser)
+		require.Equal(t, http.StatusMultiStatus, rr.Code)
+		var response handlers.UploadFilesResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		require.Len(t, response.Results, 1)
+		assert.Equal(t, "File too large", response.Results[0].Error)
+
+		// Many files within the file limit can still exceed the upload limit
+		files := map[string]string{}
+		for i := range uploadLimit / fileLimit {
+			files[fmt.Sprintf("part%d.md", i)] = strings.Repeat("x", fileLimit)
+		}
+		rr = h.makeUploadRequest(t, uploadURL, files, h.RegularTestUser)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+}
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"lemma/internal/context"
+	"lemma/internal/models"
+	"lemma/internal/storage"
+)
+
+// FrontmatterResponse represents the frontmatter fields of a file
+type FrontmatterResponse struct {
+	Frontmatter map[string]interface{} `json:"frontmatter"`
+}
+
+// UpdateFrontmatterRequest represents a request to replace the frontmatter of a file
+type UpdateFrontmatterRequest struct {
+	Frontmatter map[string]interface{} `json:"frontmatter"`
+}
+
+// GetFrontmatter godoc
+// @Summary Get file frontmatter
+// @Description Returns the fields of the YAML frontmatter block of a file, empty if the file has none
+// @Tags files
+// @ID getFrontmatter
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "File path"
+// @Success 200 {object} FrontmatterResponse
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 422 {object} ErrorResponse "Malformed frontmatter"
+// @Failure 500 {object} ErrorResponse "Failed to read frontmatter"
+// @Router /workspaces/{workspace_name}/files/frontmatter [get]
+func (h *Handler) GetFrontmatter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "GetFrontmatter",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("file_path")
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil || decodedPath == "" {
+			log.Debug("invalid file path",
+				"filePath", filePath,
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		fields, err := h.Storage.GetFrontmatter(ctx.UserID, ctx.Workspace.ID, decodedPath)
+		if err != nil {
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				respondError(w, "File not found", http.StatusNotFound)
+				return
+			}
+
+			if storage.IsFrontmatterError(err) {
+				log.Debug("malformed frontmatter",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Malformed frontmatter: "+err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+
+			log.Error("failed to read frontmatter",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to read frontmatter", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, FrontmatterResponse{Frontmatter: fields})
+	}
+}
+
+// UpdateFrontmatter godoc
+// @Summary Update file frontmatter
+// @Description Replaces the YAML frontmatter block of a file and keeps the body unchanged.
+// @Description Fields are written in key order, an empty frontmatter removes the block.
+// @Tags files
+// @ID updateFrontmatter
+// @Security CookieAuth
+// @Accept json
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "File path"
+// @Param body body UpdateFrontmatterRequest true "Frontmatter fields"
+// @Success 204 "No Content - Frontmatter updated successfully"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
+// @Failure 422 {object} ErrorResponse "Malformed frontmatter"
+// @Failure 500 {object} ErrorResponse "Failed to update frontmatter"
+// @Router /workspaces/{workspace_name}/files/frontmatter [put]
+func (h *Handler) UpdateFrontmatter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "UpdateFrontmatter",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("file_path")
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil || decodedPath == "" {
+			log.Debug("invalid file path",
+				"filePath", filePath,
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		var req UpdateFrontmatterRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Debug("invalid request body received",
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		err = h.Storage.UpdateFrontmatter(ctx.UserID, ctx.Workspace.ID, decodedPath, req.Frontmatter, saveHooks(ctx.Workspace)...)
+		if err != nil {
+			if storage.IsWorkspacePinnedError(err) {
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
+				return
+			}
+
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				respondError(w, "File not found", http.StatusNotFound)
+				return
+			}
+
+			if storage.IsFrontmatterError(err) {
+				log.Debug("malformed frontmatter",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Malformed frontmatter: "+err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+
+			if storage.IsSaveHookError(err) {
+				respondError(w, "Failed to save file: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			log.Error("failed to update frontmatter",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to update frontmatter", http.StatusInternalServerError)
+			return
+		}
+
+		h.recordActivity(&models.Activity{
+			WorkspaceID: ctx.Workspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityFileSaved,
+			Path:        decodedPath,
+		})
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetFileMeta godoc
+// @Summary Get file metadata
+// @Description Returns the fields of the YAML frontmatter block of a file and the byte offset of the body following it.
+// @Description Files without frontmatter have empty fields and offset 0.
+// @Tags files
+// @ID getFileMeta
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "File path"
+// @Success 200 {object} storage.FileMeta
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 422 {object} ErrorResponse "Malformed frontmatter"
+// @Failure 500 {object} ErrorResponse "Failed to read frontmatter"
+// @Router /workspaces/{workspace_name}/files/meta [get]
+func (h *Handler) GetFileMeta() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "GetFileMeta",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("file_path")
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil || decodedPath == "" {
+			log.Debug("invalid file path",
+				"filePath", filePath,
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		meta, err := h.Storage.GetFileMeta(ctx.UserID, ctx.Workspace.ID, decodedPath)
+		if err != nil {
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				respondError(w, "File not found", http.StatusNotFound)
+				return
+			}
+
+			if storage.IsFrontmatterError(err) {
+				log.Debug("malformed frontmatter",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Malformed frontmatter: "+err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+
+			log.Error("failed to read frontmatter",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to read frontmatter", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, meta)
+	}
+}
+
+// NotesResponse represents the notes matching a frontmatter query
+type NotesResponse struct {
+	Notes []storage.FileMeta `json:"notes"`
+}
+
+// ListNotesByTag godoc
+// @Summary List notes by tag
+// @Description Returns the markdown files of the workspace whose frontmatter tags contain the tag, ordered by path.
+// @Description Tags are compared ignoring case and a leading #. Files without or with malformed frontmatter are skipped.
+// @Tags files
+// @ID listNotesByTag
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param tag query string true "Tag to look for"
+// @Success 200 {object} NotesResponse
+// @Failure 400 {object} ErrorResponse "tag is required"
+// @Failure 500 {object} ErrorResponse "Failed to list notes"
+// @Router /workspaces/{workspace_name}/notes [get]
+func (h *Handler) ListNotesByTag() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "ListNotesByTag",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		tag := strings.TrimSpace(r.URL.Query().Get("tag"))
+		if tag == "" {
+			log.Debug("missing tag parameter")
+			respondError(w, "tag is required", http.StatusBadRequest)
+			return
+		}
+
+		notes, err := h.Storage.ListNotesByTag(ctx.UserID, ctx.Workspace.ID, tag)
+		if err != nil {
+			log.Error("failed to list notes by tag",
+				"tag", tag,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to list notes", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, NotesResponse{Notes: notes})
+	}
+}
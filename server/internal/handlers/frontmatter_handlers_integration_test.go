@@ -0,0 +1,143 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+	"lemma/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrontmatter_Integration(t *testing.T) {
+	runWithDatabases(t, testFrontmatter)
+}
+
+func testFrontmatter(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarness(t, dbConfig)
+	defer h.teardown(t)
+
+	user := h.createTestUser(t, "frontmatter@test.com", "password123", models.RoleEditor)
+
+	workspace := &models.Workspace{Name: "Frontmatter Workspace"}
+	rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, user)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	baseURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name) + "/files"
+	query := "?file_path=" + url.QueryEscape("note.md")
+	body := "# Note\n\n---\n\nSome text.\n"
+
+	saveFile := func(t *testing.T, query, content string) {
+		t.Helper()
+		rr := h.makeRequestRaw(t, http.MethodPost, baseURL+query, strings.NewReader(content), user)
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+	saveFile(t, query, "---\ntitle: Note\ntags: [a, b]\n---\n"+body)
+
+	t.Run("get", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, baseURL+"/frontmatter"+query, nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response handlers.FrontmatterResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, "Note", response.Frontmatter["title"])
+		assert.Equal(t, []interface{}{"a", "b"}, response.Frontmatter["tags"])
+	})
+
+	t.Run("update preserves body", func(t *testing.T) {
+		update := handlers.UpdateFrontmatterRequest{Frontmatter: map[string]interface{}{"title": "Renamed", "draft": true}}
+		rr := h.makeRequest(t, http.MethodPut, baseURL+"/frontmatter"+query, update, user)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, baseURL+"/content"+query, nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "---\ndraft: true\ntitle: Renamed\n---\n"+body, rr.Body.String())
+	})
+
+	t.Run("file without frontmatter", func(t *testing.T) {
+		plainQuery := "?file_path=" + url.QueryEscape("plain.md")
+		saveFile(t, plainQuery, body)
+
+		rr := h.makeRequest(t, http.MethodGet, baseURL+"/frontmatter"+plainQuery, nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"frontmatter":{}}`, rr.Body.String())
+	})
+
+	t.Run("malformed frontmatter", func(t *testing.T) {
+		brokenQuery := "?file_path=" + url.QueryEscape("broken.md")
+		saveFile(t, brokenQuery, "---\ntitle: [Note\n---\n"+body)
+
+		rr := h.makeRequest(t, http.MethodGet, baseURL+"/frontmatter"+brokenQuery, nil, user)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+		update := handlers.UpdateFrontmatterRequest{Frontmatter: map[string]interface{}{"title": "Note"}}
+		rr = h.makeRequest(t, http.MethodPut, baseURL+"/frontmatter"+brokenQuery, update, user)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, baseURL+"/frontmatter?file_path=missing.md", nil, user)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, baseURL+"/frontmatter?file_path="+url.QueryEscape("../../etc/passwd"), nil, user)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("meta", func(t *testing.T) {
+		metaQuery := "?file_path=" + url.QueryEscape("meta.md")
+		saveFile(t, metaQuery, "---\ntitle: Meta\n---\n"+body)
+
+		rr := h.makeRequest(t, http.MethodGet, baseURL+"/meta"+metaQuery, nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var meta storage.FileMeta
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&meta))
+		assert.Equal(t, "meta.md", meta.Path)
+		assert.Equal(t, "Meta", meta.Frontmatter["title"])
+		assert.Equal(t, len("---\ntitle: Meta\n---\n"), meta.BodyOffset)
+
+		rr = h.makeRequest(t, http.MethodGet, baseURL+"/meta?file_path="+url.QueryEscape("plain.md"), nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"path":"plain.md","frontmatter":{},"bodyOffset":0}`, rr.Body.String())
+
+		rr = h.makeRequest(t, http.MethodGet, baseURL+"/meta?file_path="+url.QueryEscape("broken.md"), nil, user)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, baseURL+"/meta?file_path=missing.md", nil, user)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("notes by tag", func(t *testing.T) {
+		saveFile(t, "?file_path="+url.QueryEscape("tagged/first.md"), "---\ntags: [project, Go]\n---\n"+body)
+		saveFile(t, "?file_path="+url.QueryEscape("tagged/second.md"), "---\ntags: go\n---\n"+body)
+		saveFile(t, "?file_path="+url.QueryEscape("tagged/other.md"), "---\ntags: [rust]\n---\n"+body)
+
+		notesURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name) + "/notes"
+		rr := h.makeRequest(t, http.MethodGet, notesURL+"?tag=go", nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response handlers.NotesResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		require.Len(t, response.Notes, 2)
+		assert.Equal(t, "tagged/first.md", response.Notes[0].Path)
+		assert.Equal(t, []interface{}{"project", "Go"}, response.Notes[0].Frontmatter["tags"])
+		assert.Equal(t, "tagged/second.md", response.Notes[1].Path)
+
+		rr = h.makeRequest(t, http.MethodGet, notesURL+"?tag=missing", nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"notes":[]}`, rr.Body.String())
+
+		rr = h.makeRequest(t, http.MethodGet, notesURL, nil, user)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
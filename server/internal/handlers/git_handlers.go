@@ -2,9 +2,18 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"lemma/internal/context"
+	"lemma/internal/git"
 	"lemma/internal/logging"
+	"lemma/internal/models"
+	"lemma/internal/storage"
+	"mime"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 )
 
 // CommitRequest represents a request to commit changes
@@ -15,11 +24,64 @@ type CommitRequest struct {
 // CommitResponse represents a response to a commit request
 type CommitResponse struct {
 	CommitHash string `json:"commitHash" example:"a1b2c3d4"`
+	// NothingToCommit is set when the working tree had no changes, no commit was made
+	NothingToCommit bool `json:"nothingToCommit,omitempty"`
+	// RemoteErrors holds the push errors of additional remotes by remote name
+	RemoteErrors map[string]string `json:"remoteErrors,omitempty"`
 }
 
 // PullResponse represents a response to a pull http request
 type PullResponse struct {
 	Message string `json:"message" example:"Pulled changes from remote"`
+	// UpToDate is set when the remote had no new changes
+	UpToDate bool `json:"upToDate"`
+	// Files are the paths added, modified or deleted by the pull
+	Files []string `json:"files"`
+}
+
+// ErrorCodeGitConflict is the error code of a pull whose changes cannot be applied to the workspace
+const ErrorCodeGitConflict = "git_conflict"
+
+// PullConflictResponse represents a rejected pull. The reason is diverged when the workspace has commits
+// missing from the remote, files are then the files changed on both sides. For uncommitted_changes,
+// files are the uncommitted files.
+type PullConflictResponse struct {
+	ErrorResponse
+	Reason string   `json:"reason" example:"diverged"`
+	Files  []string `json:"files"`
+}
+
+// CommitLogEntry represents a commit in the history of a workspace
+type CommitLogEntry struct {
+	Hash        string    `json:"hash" example:"a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0"`
+	AuthorName  string    `json:"authorName" example:"Jane Doe"`
+	AuthorEmail string    `json:"authorEmail" example:"jane@example.com"`
+	Message     string    `json:"message" example:"Update notes"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// CommitLogResponse lists the commits of a workspace, newest first
+type CommitLogResponse struct {
+	Commits []CommitLogEntry `json:"commits"`
+}
+
+// GitStatusResponse represents the uncommitted changes of a workspace and its state against the remote
+type GitStatusResponse struct {
+	Clean     bool     `json:"clean"`
+	Modified  []string `json:"modified" example:"notes/todo.md"`
+	Untracked []string `json:"untracked" example:"notes/new.md"`
+	Ahead     int      `json:"ahead" example:"2"`
+	Behind    int      `json:"behind" example:"0"`
+}
+
+// DiffResponse represents the uncommitted changes in a workspace as a unified diff
+type DiffResponse struct {
+	Diff string `json:"diff"`
+}
+
+// DeletedFilesResponse lists committed files that were deleted from the workspace
+type DeletedFilesResponse struct {
+	Files []string `json:"files"`
 }
 
 func getGitLogger() logging.Logger {
@@ -28,7 +90,9 @@ func getGitLogger() logging.Logger {
 
 // StageCommitAndPush godoc
 // @Summary Stage, commit, and push changes
-// @Description Stages, commits, and pushes changes to the remote repository
+// @Description Stages, commits, and pushes changes to the remote repository and the additional remotes of the workspace.
+// @Description Failed pushes to additional remotes are listed in remoteErrors without failing the commit.
+// @Description When the working tree has no changes nothing is committed and nothingToCommit is set.
 // @Tags git
 // @ID stageCommitAndPush
 // @Security CookieAuth
@@ -38,6 +102,7 @@ func getGitLogger() logging.Logger {
 // @Success 200 {object} CommitResponse
 // @Failure 400 {object} ErrorResponse "Invalid request body"
 // @Failure 400 {object} ErrorResponse "Commit message is required"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
 // @Failure 500 {object} ErrorResponse "Failed to stage, commit, and push changes"
 // @Router /workspaces/{workspace_name}/git/commit [post]
 func (h *Handler) StageCommitAndPush() http.HandlerFunc {
@@ -68,8 +133,28 @@ func (h *Handler) StageCommitAndPush() http.HandlerFunc {
 			return
 		}
 
-		hash, err := h.Storage.StageCommitAndPush(ctx.UserID, ctx.Workspace.ID, requestBody.Message)
-		if err != nil {
+		author := h.commitAuthor(ctx.UserID, ctx.Workspace)
+		hash, err := h.Storage.StageCommitAndPush(ctx.UserID, ctx.Workspace.ID, requestBody.Message, author)
+		if errors.Is(err, git.ErrNothingToCommit) {
+			log.Debug("nothing to commit")
+			respondJSON(w, CommitResponse{NothingToCommit: true})
+			return
+		}
+		response := CommitResponse{CommitHash: hash.String()}
+		var remoteErr *storage.RemotePushError
+		if errors.As(err, &remoteErr) {
+			log.Warn("failed to push to additional remotes",
+				"error", err.Error(),
+			)
+			response.RemoteErrors = make(map[string]string, len(remoteErr.Failures))
+			for _, failure := range remoteErr.Failures {
+				response.RemoteErrors[failure.Remote] = failure.Err.Error()
+			}
+		} else if err != nil {
+			if storage.IsWorkspacePinnedError(err) {
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
+				return
+			}
 			log.Error("failed to perform git operations",
 				"error", err.Error(),
 				"commitMessage", requestBody.Message,
@@ -78,19 +163,29 @@ func (h *Handler) StageCommitAndPush() http.HandlerFunc {
 			return
 		}
 
-		respondJSON(w, CommitResponse{CommitHash: hash.String()})
+		h.recordActivity(&models.Activity{
+			WorkspaceID: ctx.Workspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityGitCommit,
+			Message:     requestBody.Message,
+		})
+
+		respondJSON(w, response)
 	}
 }
 
 // PullChanges godoc
 // @Summary Pull changes from remote
-// @Description Pulls changes from the remote repository
+// @Description Pulls changes from the remote repository and returns the changed files. Only fast-forward pulls are supported,
+// @Description a pull is rejected with a conflict when the workspace has diverged from the remote or has uncommitted changes.
 // @Tags git
 // @ID pullChanges
 // @Security CookieAuth
 // @Produce json
 // @Param workspace_name path string true "Workspace name"
 // @Success 200 {object} PullResponse
+// @Failure 409 {object} PullConflictResponse "Pull conflicts with local changes"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
 // @Failure 500 {object} ErrorResponse "Failed to pull changes"
 // @Router /workspaces/{workspace_name}/git/pull [post]
 func (h *Handler) PullChanges() http.HandlerFunc {
@@ -106,8 +201,31 @@ func (h *Handler) PullChanges() http.HandlerFunc {
 			"clientIP", r.RemoteAddr,
 		)
 
-		err := h.Storage.Pull(ctx.UserID, ctx.Workspace.ID)
+		result, err := h.Storage.Pull(ctx.UserID, ctx.Workspace.ID)
 		if err != nil {
+			if storage.IsWorkspacePinnedError(err) {
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
+				return
+			}
+			var conflictErr *git.PullConflictError
+			if errors.As(err, &conflictErr) {
+				log.Debug("pull conflicts with local changes",
+					"reason", conflictErr.Reason,
+					"files", len(conflictErr.Files),
+				)
+				message := "Local and remote changes have diverged"
+				if conflictErr.Reason == git.PullConflictUncommittedChanges {
+					message = "Commit the uncommitted changes before pulling"
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusConflict)
+				respondJSON(w, PullConflictResponse{
+					ErrorResponse: ErrorResponse{Message: message, Code: ErrorCodeGitConflict},
+					Reason:        conflictErr.Reason,
+					Files:         conflictErr.Files,
+				})
+				return
+			}
 			log.Error("failed to pull changes from remote",
 				"error", err.Error(),
 			)
@@ -115,6 +233,508 @@ func (h *Handler) PullChanges() http.HandlerFunc {
 			return
 		}
 
-		respondJSON(w, PullResponse{Message: "Successfully pulled changes from remote"})
+		h.recordActivity(&models.Activity{
+			WorkspaceID: ctx.Workspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityGitPull,
+		})
+
+		if result.UpToDate {
+			respondJSON(w, PullResponse{Message: "Already up to date", UpToDate: true, Files: result.Files})
+			return
+		}
+		respondJSON(w, PullResponse{Message: "Successfully pulled changes from remote", Files: result.Files})
+	}
+}
+
+// GetCommitHistory godoc
+// @Summary Get commit history
+// @Description Returns the commits of the workspace repository, newest first
+// @Tags git
+// @ID getCommitHistory
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param limit query int false "Maximum number of commits to return"
+// @Success 200 {object} CommitLogResponse
+// @Failure 400 {object} ErrorResponse "Git is not enabled for this workspace"
+// @Failure 400 {object} ErrorResponse "Invalid limit"
+// @Failure 500 {object} ErrorResponse "Failed to get commit history"
+// @Router /workspaces/{workspace_name}/git/log [get]
+func (h *Handler) GetCommitHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getGitLogger().With(
+			"handler", "GetCommitHistory",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		if !ctx.Workspace.GitEnabled {
+			respondError(w, "Git is not enabled for this workspace", http.StatusBadRequest)
+			return
+		}
+
+		page, ok := h.parsePagination(w, r)
+		if !ok {
+			return
+		}
+		limit := page.Limit
+
+		commits, err := h.Storage.CommitHistory(ctx.UserID, ctx.Workspace.ID, limit)
+		if err != nil {
+			log.Error("failed to get commit history",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to get commit history", http.StatusInternalServerError)
+			return
+		}
+
+		response := CommitLogResponse{Commits: make([]CommitLogEntry, 0, len(commits))}
+		for _, commit := range commits {
+			response.Commits = append(response.Commits, CommitLogEntry{
+				Hash:        commit.Hash,
+				AuthorName:  commit.Author.Name,
+				AuthorEmail: commit.Author.Email,
+				Message:     commit.Message,
+				Timestamp:   commit.Time,
+			})
+		}
+		respondJSON(w, response)
+	}
+}
+
+// GetGitStatus godoc
+// @Summary Get git status
+// @Description Returns the uncommitted changes of the workspace and how many commits it is ahead and behind
+// @Description the remote. The remote is compared as of the last pull or push, it is not fetched.
+// @Tags git
+// @ID getGitStatus
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Success 200 {object} GitStatusResponse
+// @Failure 400 {object} ErrorResponse "Git is not enabled for this workspace"
+// @Failure 500 {object} ErrorResponse "Failed to get git status"
+// @Router /workspaces/{workspace_name}/git/status [get]
+func (h *Handler) GetGitStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getGitLogger().With(
+			"handler", "GetGitStatus",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		if !ctx.Workspace.GitEnabled {
+			respondError(w, "Git is not enabled for this workspace", http.StatusBadRequest)
+			return
+		}
+
+		status, err := h.Storage.GitStatus(ctx.UserID, ctx.Workspace.ID)
+		if err != nil {
+			log.Error("failed to get git status",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to get git status", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, GitStatusResponse{
+			Clean:     status.Clean(),
+			Modified:  status.Modified,
+			Untracked: status.Untracked,
+			Ahead:     status.Ahead,
+			Behind:    status.Behind,
+		})
+	}
+}
+
+// GetDiff godoc
+// @Summary Get uncommitted changes
+// @Description Returns a unified diff of the working tree against HEAD, optionally limited to a file or directory.
+// @Description The diff is empty when there are no uncommitted changes.
+// @Tags git
+// @ID getDiff
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param path query string false "File or directory to limit the diff to"
+// @Success 200 {object} DiffResponse
+// @Failure 400 {object} ErrorResponse "Git is not enabled for this workspace"
+// @Failure 400 {object} ErrorResponse "Invalid path"
+// @Failure 500 {object} ErrorResponse "Failed to get diff"
+// @Router /workspaces/{workspace_name}/git/diff [get]
+func (h *Handler) GetDiff() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getGitLogger().With(
+			"handler", "GetDiff",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		if !ctx.Workspace.GitEnabled {
+			respondError(w, "Git is not enabled for this workspace", http.StatusBadRequest)
+			return
+		}
+
+		path := r.URL.Query().Get("path")
+		diff, err := h.Storage.DiffWorkingTree(ctx.UserID, ctx.Workspace.ID, path)
+		if err != nil {
+			if storage.IsPathValidationError(err) {
+				log.Debug("invalid diff path",
+					"path", path,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid path", http.StatusBadRequest)
+				return
+			}
+
+			log.Error("failed to get diff",
+				"path", path,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to get diff", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, DiffResponse{Diff: diff})
+	}
+}
+
+// ListDeletedFiles godoc
+// @Summary List recently deleted files
+// @Description Returns the files that exist in the last commit but have been deleted from the workspace
+// @Tags git
+// @ID listDeletedFiles
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Success 200 {object} DeletedFilesResponse
+// @Failure 400 {object} ErrorResponse "Git is not enabled for this workspace"
+// @Failure 500 {object} ErrorResponse "Failed to list deleted files"
+// @Router /workspaces/{workspace_name}/git/deleted [get]
+func (h *Handler) ListDeletedFiles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getGitLogger().With(
+			"handler", "ListDeletedFiles",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		if !ctx.Workspace.GitEnabled {
+			respondError(w, "Git is not enabled for this workspace", http.StatusBadRequest)
+			return
+		}
+
+		files, err := h.Storage.ListDeletedFiles(ctx.UserID, ctx.Workspace.ID)
+		if err != nil {
+			log.Error("failed to list deleted files",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to list deleted files", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, DeletedFilesResponse{Files: files})
+	}
+}
+
+// RestoreDeletedFile godoc
+// @Summary Restore a deleted file
+// @Description Restores a deleted file from the most recent commit that contains it
+// @Tags git
+// @ID restoreDeletedFile
+// @Security CookieAuth
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "File path"
+// @Success 204 "No Content - File restored successfully"
+// @Failure 400 {object} ErrorResponse "Git is not enabled for this workspace"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 404 {object} ErrorResponse "File not found in history"
+// @Failure 409 {object} ErrorResponse "File already exists"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
+// @Failure 500 {object} ErrorResponse "Failed to restore file"
+// @Router /workspaces/{workspace_name}/git/restore [post]
+func (h *Handler) RestoreDeletedFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getGitLogger().With(
+			"handler", "RestoreDeletedFile",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		if !ctx.Workspace.GitEnabled {
+			respondError(w, "Git is not enabled for this workspace", http.StatusBadRequest)
+			return
+		}
+
+		filePath := r.URL.Query().Get("file_path")
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil || decodedPath == "" {
+			log.Debug("invalid file path",
+				"filePath", filePath,
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		err = h.Storage.RestoreDeletedFile(ctx.UserID, ctx.Workspace.ID, decodedPath)
+		if err != nil {
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsExist(err) {
+				respondError(w, "File already exists", http.StatusConflict)
+				return
+			}
+
+			if storage.IsWorkspacePinnedError(err) {
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				log.Debug("file not found in history",
+					"filePath", decodedPath,
+				)
+				respondError(w, "File not found in history", http.StatusNotFound)
+				return
+			}
+
+			log.Error("failed to restore file",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to restore file", http.StatusInternalServerError)
+			return
+		}
+
+		h.recordActivity(&models.Activity{
+			WorkspaceID: ctx.Workspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityFileSaved,
+			Path:        decodedPath,
+		})
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetBundle godoc
+// @Summary Download a git bundle
+// @Description Streams a git bundle with the full history of the workspace repository.
+// @Description The bundle can be cloned with git clone, e.g. to migrate the workspace elsewhere.
+// @Tags git
+// @ID getBundle
+// @Security CookieAuth
+// @Produce application/x-git-bundle
+// @Param workspace_name path string true "Workspace name"
+// @Success 200 {file} binary "Git bundle"
+// @Failure 400 {object} ErrorResponse "Git is not enabled for this workspace"
+// @Failure 409 {object} ErrorResponse "Repository has no commits"
+// @Failure 500 {object} ErrorResponse "Failed to create bundle"
+// @Router /workspaces/{workspace_name}/git/bundle [get]
+func (h *Handler) GetBundle() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getGitLogger().With(
+			"handler", "GetBundle",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		if !ctx.Workspace.GitEnabled {
+			respondError(w, "Git is not enabled for this workspace", http.StatusBadRequest)
+			return
+		}
+
+		bw := &attachmentWriter{
+			w:           w,
+			contentType: "application/x-git-bundle",
+			filename:    ctx.Workspace.Name + ".bundle",
+		}
+		err := h.Storage.CreateBundle(ctx.UserID, ctx.Workspace.ID, bw)
+		if err == nil || bw.started {
+			h.recordFileAccess(r, ctx, models.FileAccessBundle, "")
+		}
+		if err == nil {
+			return
+		}
+
+		// Once the bundle is being streamed errors can only be logged
+		if bw.started {
+			log.Error("failed to stream bundle",
+				"error", err.Error(),
+			)
+			return
+		}
+
+		if errors.Is(err, git.ErrEmptyRepository) {
+			respondError(w, "Repository has no commits", http.StatusConflict)
+			return
+		}
+
+		log.Error("failed to create bundle",
+			"error", err.Error(),
+		)
+		respondError(w, "Failed to create bundle", http.StatusInternalServerError)
+	}
+}
+
+// attachmentWriter sets the download headers on the first write,
+// so errors that occur before any content is produced can still be reported
+type attachmentWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (a *attachmentWriter) Write(p []byte) (int, error) {
+	if !a.started {
+		a.w.Header().Set("Content-Type", a.contentType)
+		a.w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.filename}))
+		a.started = true
+	}
+	return a.w.Write(p)
+}
+
+// now returns the current time in the configured timezone
+func (h *Handler) now() time.Time {
+	if h.Location == nil {
+		return time.Now().UTC()
+	}
+	return time.Now().In(h.Location)
+}
+
+// commitAuthor returns the author of commits the user makes in the workspace, the commit identity
+// of the workspace. If commit identity fallback is enabled, missing fields are filled with the
+// display name and email of the user.
+func (h *Handler) commitAuthor(userID int, workspace *models.Workspace) git.Author {
+	author := git.Author{
+		Name:  workspace.GitCommitName,
+		Email: workspace.GitCommitEmail,
+	}
+	if !h.CommitIdentityFallback || (author.Name != "" && author.Email != "") {
+		return author
+	}
+
+	user, err := h.DB.GetUserByID(userID)
+	if err != nil {
+		getGitLogger().Warn("failed to get user for commit identity fallback",
+			"userID", userID,
+			"error", err.Error(),
+		)
+		return author
+	}
+
+	if author.Name == "" {
+		author.Name = user.DisplayName
+	}
+	if author.Name == "" {
+		author.Name = user.Email
+	}
+	if author.Email == "" {
+		author.Email = user.Email
+	}
+	return author
+}
+
+// autoCommit commits and pushes the change to filePath if auto-commit is enabled for the workspace.
+// The commit message is built from the workspace commit message template, ${date} and ${time}
+// are expanded in the configured timezone.
+func (h *Handler) autoCommit(userID int, workspace *models.Workspace, filePath, action string) error {
+	if !workspace.GitEnabled || !workspace.GitAutoCommit {
+		return nil
+	}
+
+	now := h.now()
+	message := strings.NewReplacer(
+		"${filename}", filePath,
+		"${action}", action,
+		"${date}", now.Format(time.DateOnly),
+		"${time}", now.Format(time.TimeOnly),
+	).Replace(workspace.GitCommitMsgTemplate)
+	if message != "" {
+		message = strings.ToUpper(message[:1]) + message[1:]
+	}
+
+	// The commit is made even if pushing to an additional remote failed
+	_, err := h.Storage.StageCommitAndPush(userID, workspace.ID, message, h.commitAuthor(userID, workspace))
+	if errors.Is(err, git.ErrNothingToCommit) {
+		// The file was saved with its committed content
+		return nil
+	}
+	if err != nil && !storage.IsRemotePushError(err) {
+		return err
+	}
+
+	h.recordActivity(&models.Activity{
+		WorkspaceID: workspace.ID,
+		UserID:      userID,
+		Type:        models.ActivityGitCommit,
+		Message:     message,
+	})
+	return err
+}
+
+// pinGitRef checks out ref in the workspace repository, or its branch if ref is empty, and
+// writes the error response if that fails. It reports whether the checkout succeeded.
+func (h *Handler) pinGitRef(w http.ResponseWriter, userID, workspaceID int, ref string, log logging.Logger) bool {
+	err := h.Storage.PinGitRef(userID, workspaceID, ref)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, git.ErrRefNotFound):
+		log.Debug("git ref not found",
+			"ref", ref,
+		)
+		respondError(w, "Git ref not found", http.StatusBadRequest)
+	case errors.Is(err, git.ErrUncommittedChanges):
+		log.Debug("workspace has uncommitted changes",
+			"ref", ref,
+		)
+		respondError(w, "Workspace has uncommitted changes, commit them before changing the pinned ref", http.StatusConflict)
+	default:
+		log.Error("failed to checkout git ref",
+			"ref", ref,
+			"error", err.Error(),
+		)
+		respondError(w, "Failed to checkout git ref", http.StatusInternalServerError)
 	}
+	return false
 }
@@ -3,18 +3,36 @@
 package handlers_test
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
+	"lemma/internal/git"
+	"lemma/internal/handlers"
 	"lemma/internal/models"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 )
 
+// testSigningKey returns a new OpenSSH private key
+func testSigningKey(t *testing.T) string {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(block))
+}
+
 func TestGitHandlers_Integration(t *testing.T) {
 	runWithDatabases(t, testGitHandlers)
 }
@@ -34,6 +52,8 @@ func testGitHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			GitToken:             "testtoken",
 			GitAutoCommit:        true,
 			GitCommitMsgTemplate: "Update: {{message}}",
+			GitCommitName:        "Test User",
+			GitCommitEmail:       "test@example.com",
 		}
 
 		rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, h.RegularTestUser)
@@ -66,6 +86,38 @@ func testGitHandlers(t *testing.T, dbConfig DatabaseConfig) {
 				assert.Equal(t, 1, h.MockGit.GetCommitCount(), "Commit should be called once")
 				assert.Equal(t, 1, h.MockGit.GetPushCount(), "Push should be called once")
 				assert.Equal(t, commitMsg, h.MockGit.GetLastCommitMessage(), "Commit message should match")
+				assert.Equal(t, git.Author{Name: "Test User", Email: "test@example.com"}, h.MockGit.GetLastAuthor(), "Commit author should match")
+			})
+
+			t.Run("auto-commit template in configured timezone", func(t *testing.T) {
+				h.MockGit.Reset()
+				workspace.GitCommitMsgTemplate = "${action} ${filename} on ${date}"
+				rr := h.makeRequest(t, http.MethodPut, "/api/v1/workspaces/"+url.PathEscape(workspace.Name), workspace, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				// The harness runs in UTC+14, take both sides of the request in case the date changes meanwhile
+				location, err := time.LoadLocation("Pacific/Kiritimati")
+				require.NoError(t, err)
+				before := time.Now().In(location).Format(time.DateOnly)
+
+				filesURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name) + "/files"
+				files := []handlers.BatchSaveFile{{Path: "dated.md", Content: "content"}}
+				rr = h.makeRequest(t, http.MethodPost, filesURL+"/batch-save", files, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				after := time.Now().In(location).Format(time.DateOnly)
+				assert.Contains(t, []string{"Update dated.md on " + before, "Update dated.md on " + after}, h.MockGit.GetLastCommitMessage())
+			})
+
+			t.Run("nothing to commit", func(t *testing.T) {
+				h.MockGit.Reset()
+				h.MockGit.SetError(git.ErrNothingToCommit)
+				defer h.MockGit.SetError(nil)
+
+				rr := h.makeRequest(t, http.MethodPost, baseURL+"/commit", map[string]string{"message": "No changes"}, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.JSONEq(t, `{"commitHash":"","nothingToCommit":true}`, rr.Body.String())
+				assert.Equal(t, 0, h.MockGit.GetPushCount(), "Push should not be called")
 			})
 
 			t.Run("empty commit message", func(t *testing.T) {
@@ -92,6 +144,50 @@ func testGitHandlers(t *testing.T, dbConfig DatabaseConfig) {
 
 				h.MockGit.SetError(nil) // Reset error state
 			})
+
+			t.Run("signed commits", func(t *testing.T) {
+				h.MockGit.Reset()
+				workspaceURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name)
+				commit := func(t *testing.T) {
+					t.Helper()
+					rr := h.makeRequest(t, http.MethodPost, baseURL+"/commit", map[string]string{"message": "Signed"}, h.RegularTestUser)
+					require.Equal(t, http.StatusOK, rr.Code)
+				}
+
+				commit(t)
+				assert.Nil(t, h.MockGit.GetLastSigner(), "Commit should not be signed without a key")
+
+				update := func(key *string) *httptest.ResponseRecorder {
+					return h.makeRequest(t, http.MethodPut, workspaceURL, handlers.WorkspaceRequest{Workspace: *workspace, GitSigningKey: key}, h.RegularTestUser)
+				}
+
+				invalid := "not a key"
+				rr := update(&invalid)
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+				key := testSigningKey(t)
+				rr = update(&key)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.NotContains(t, rr.Body.String(), "PRIVATE KEY", "The key should never be returned")
+				commit(t)
+				assert.NotNil(t, h.MockGit.GetLastSigner(), "Commit should be signed with the key")
+
+				// Updates leaving the key out keep it
+				rr = update(nil)
+				require.Equal(t, http.StatusOK, rr.Code)
+				rr = h.makeRequest(t, http.MethodGet, workspaceURL, nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.NotContains(t, rr.Body.String(), "PRIVATE KEY", "The key should never be returned")
+				stored, err := h.DB.GetWorkspaceByID(workspace.ID)
+				require.NoError(t, err)
+				assert.Equal(t, key, stored.GitSigningKey)
+
+				removed := ""
+				rr = update(&removed)
+				require.Equal(t, http.StatusOK, rr.Code)
+				commit(t)
+				assert.Nil(t, h.MockGit.GetLastSigner(), "Commit should not be signed after removing the key")
+			})
 		})
 
 		t.Run("pull changes", func(t *testing.T) {
@@ -101,14 +197,57 @@ func testGitHandlers(t *testing.T, dbConfig DatabaseConfig) {
 				rr := h.makeRequest(t, http.MethodPost, baseURL+"/pull", nil, h.RegularTestUser)
 				require.Equal(t, http.StatusOK, rr.Code)
 
-				var response map[string]string
+				var response handlers.PullResponse
 				err := json.NewDecoder(rr.Body).Decode(&response)
 				require.NoError(t, err)
-				assert.Contains(t, response["message"], "Successfully pulled changes")
+				assert.Contains(t, response.Message, "Successfully pulled changes")
 
 				assert.Equal(t, 1, h.MockGit.GetPullCount(), "Pull should be called once")
 			})
 
+			t.Run("up to date", func(t *testing.T) {
+				h.MockGit.Reset()
+				h.MockGit.SetPullResult(git.PullResult{UpToDate: true})
+
+				rr := h.makeRequest(t, http.MethodPost, baseURL+"/pull", nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var response handlers.PullResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				assert.True(t, response.UpToDate)
+				assert.Equal(t, "Already up to date", response.Message)
+				assert.Empty(t, response.Files)
+			})
+
+			t.Run("changed files", func(t *testing.T) {
+				h.MockGit.Reset()
+				h.MockGit.SetPullResult(git.PullResult{Files: []string{"notes/a.md", "b.md"}})
+
+				rr := h.makeRequest(t, http.MethodPost, baseURL+"/pull", nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var response handlers.PullResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				assert.Contains(t, response.Message, "Successfully pulled changes")
+				assert.False(t, response.UpToDate)
+				assert.Equal(t, []string{"notes/a.md", "b.md"}, response.Files)
+			})
+
+			t.Run("conflict", func(t *testing.T) {
+				h.MockGit.Reset()
+				h.MockGit.SetError(&git.PullConflictError{Reason: git.PullConflictDiverged, Files: []string{"b.md"}})
+				defer h.MockGit.SetError(nil)
+
+				rr := h.makeRequest(t, http.MethodPost, baseURL+"/pull", nil, h.RegularTestUser)
+				require.Equal(t, http.StatusConflict, rr.Code)
+
+				var response handlers.PullConflictResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				assert.Equal(t, handlers.ErrorCodeGitConflict, response.Code)
+				assert.Equal(t, git.PullConflictDiverged, response.Reason)
+				assert.Equal(t, []string{"b.md"}, response.Files)
+			})
+
 			t.Run("git error", func(t *testing.T) {
 				h.MockGit.Reset()
 				h.MockGit.SetError(fmt.Errorf("mock git error"))
@@ -120,6 +259,250 @@ func testGitHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			})
 		})
 
+		t.Run("commit history", func(t *testing.T) {
+			h.MockGit.Reset()
+			defer h.MockGit.Reset()
+
+			commitTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+			commits := make([]git.Commit, 60)
+			for i := range commits {
+				commits[i] = git.Commit{
+					Hash:    fmt.Sprintf("%040d", i),
+					Author:  git.Author{Name: "Test User", Email: "test@example.com"},
+					Message: fmt.Sprintf("Commit %d\n", i),
+					Time:    commitTime.Add(-time.Duration(i) * time.Hour),
+				}
+			}
+			h.MockGit.SetCommits(commits)
+
+			getLog := func(t *testing.T, query string) []map[string]any {
+				t.Helper()
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/log"+query, nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var response struct {
+					Commits []map[string]any `json:"commits"`
+				}
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				return response.Commits
+			}
+
+			t.Run("json shape", func(t *testing.T) {
+				history := getLog(t, "?limit=1")
+				require.Len(t, history, 1)
+				assert.Equal(t, map[string]any{
+					"hash":        fmt.Sprintf("%040d", 0),
+					"authorName":  "Test User",
+					"authorEmail": "test@example.com",
+					"message":     "Commit 0\n",
+					"timestamp":   "2024-03-01T12:00:00Z",
+				}, history[0])
+			})
+
+			t.Run("limit", func(t *testing.T) {
+				assert.Len(t, getLog(t, "?limit=5"), 5)
+				assert.Len(t, getLog(t, ""), 20, "default page size")
+				assert.Len(t, getLog(t, "?limit=0"), 20, "default page size")
+				assert.Len(t, getLog(t, "?limit=100"), 50, "clamped to the max page size")
+
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/log?limit=-1", nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/log?limit=abc", nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+			})
+
+			t.Run("empty history", func(t *testing.T) {
+				h.MockGit.SetCommits(nil)
+				defer h.MockGit.SetCommits(commits)
+
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/log", nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.JSONEq(t, `{"commits":[]}`, rr.Body.String())
+			})
+
+			t.Run("git error", func(t *testing.T) {
+				h.MockGit.SetError(fmt.Errorf("mock git error"))
+				defer h.MockGit.SetError(nil)
+
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/log", nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			})
+		})
+
+		t.Run("status", func(t *testing.T) {
+			h.MockGit.Reset()
+			defer h.MockGit.Reset()
+
+			getStatus := func(t *testing.T) handlers.GitStatusResponse {
+				t.Helper()
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/status", nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var response handlers.GitStatusResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				return response
+			}
+
+			t.Run("clean", func(t *testing.T) {
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/status", nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.JSONEq(t, `{"clean":true,"modified":[],"untracked":[],"ahead":0,"behind":0}`, rr.Body.String())
+			})
+
+			t.Run("dirty", func(t *testing.T) {
+				h.MockGit.SetStatus(git.GitStatus{Modified: []string{"notes/a.md"}, Untracked: []string{"notes/b.md"}})
+				defer h.MockGit.SetStatus(git.GitStatus{})
+
+				status := getStatus(t)
+				assert.False(t, status.Clean)
+				assert.Equal(t, []string{"notes/a.md"}, status.Modified)
+				assert.Equal(t, []string{"notes/b.md"}, status.Untracked)
+			})
+
+			t.Run("ahead of remote", func(t *testing.T) {
+				h.MockGit.SetStatus(git.GitStatus{Ahead: 2, Behind: 1})
+				defer h.MockGit.SetStatus(git.GitStatus{})
+
+				status := getStatus(t)
+				assert.True(t, status.Clean)
+				assert.Equal(t, 2, status.Ahead)
+				assert.Equal(t, 1, status.Behind)
+			})
+
+			t.Run("git error", func(t *testing.T) {
+				h.MockGit.SetError(fmt.Errorf("mock git error"))
+				defer h.MockGit.SetError(nil)
+
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/status", nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			})
+		})
+
+		t.Run("diff", func(t *testing.T) {
+			h.MockGit.Reset()
+
+			getDiff := func(t *testing.T, query string) handlers.DiffResponse {
+				t.Helper()
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/diff"+query, nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var response handlers.DiffResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				return response
+			}
+
+			t.Run("clean working tree", func(t *testing.T) {
+				assert.Empty(t, getDiff(t, "").Diff)
+			})
+
+			t.Run("pending changes", func(t *testing.T) {
+				diff := "diff --git a/notes/a.md b/notes/a.md\n--- a/notes/a.md\n+++ b/notes/a.md\n@@ -1 +1 @@\n-old\n+new\n"
+				h.MockGit.SetDiff(diff)
+
+				assert.Equal(t, diff, getDiff(t, "").Diff)
+				assert.Empty(t, h.MockGit.GetLastDiffPath())
+
+				getDiff(t, "?path="+url.QueryEscape("notes/a.md"))
+				assert.Equal(t, "notes/a.md", h.MockGit.GetLastDiffPath())
+			})
+
+			t.Run("invalid path", func(t *testing.T) {
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/diff?path="+url.QueryEscape("../other"), nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+			})
+
+			t.Run("git error", func(t *testing.T) {
+				h.MockGit.SetError(fmt.Errorf("mock git error"))
+				defer h.MockGit.SetError(nil)
+
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/diff", nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			})
+		})
+
+		t.Run("deleted files", func(t *testing.T) {
+			h.MockGit.Reset()
+			defer h.MockGit.Reset()
+
+			filesURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name) + "/files"
+			restoreURL := baseURL + "/restore?file_path="
+
+			t.Run("list", func(t *testing.T) {
+				rr := h.makeRequest(t, http.MethodGet, baseURL+"/deleted", nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var response handlers.DeletedFilesResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				assert.Empty(t, response.Files)
+
+				h.MockGit.SetDeletedFiles([]string{"notes/gone.md"})
+				rr = h.makeRequest(t, http.MethodGet, baseURL+"/deleted", nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				assert.Equal(t, []string{"notes/gone.md"}, response.Files)
+			})
+
+			t.Run("changed since commit", func(t *testing.T) {
+				since := url.QueryEscape(time.Now().Add(-time.Hour).UTC().Format(time.RFC3339))
+
+				rr := h.makeRequest(t, http.MethodGet, filesURL+"/changed?commit=abc123&since="+since, nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var response handlers.ChangedFilesResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				assert.Equal(t, []string{"notes/gone.md"}, response.DeletedFiles)
+
+				rr = h.makeRequest(t, http.MethodGet, filesURL+"/changed?commit=unknown&since="+since, nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+			})
+
+			t.Run("restore", func(t *testing.T) {
+				h.MockGit.SetHistory(map[string][]byte{"notes/gone.md": []byte("committed content")})
+
+				rr := h.makeRequest(t, http.MethodPost, restoreURL+url.QueryEscape("notes/gone.md"), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusNoContent, rr.Code)
+
+				rr = h.makeRequest(t, http.MethodGet, filesURL+"/content?file_path="+url.QueryEscape("notes/gone.md"), nil, h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, "committed content", rr.Body.String())
+
+				// the file now exists in the workspace
+				rr = h.makeRequest(t, http.MethodPost, restoreURL+url.QueryEscape("notes/gone.md"), nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusConflict, rr.Code)
+			})
+
+			t.Run("not in history", func(t *testing.T) {
+				rr := h.makeRequest(t, http.MethodPost, restoreURL+url.QueryEscape("never.md"), nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+			})
+
+			t.Run("invalid path", func(t *testing.T) {
+				rr := h.makeRequest(t, http.MethodPost, restoreURL+url.QueryEscape("../other.md"), nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+				rr = h.makeRequest(t, http.MethodPost, baseURL+"/restore", nil, h.RegularTestUser)
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+			})
+		})
+
+		t.Run("bundle", func(t *testing.T) {
+			h.MockGit.Reset()
+			defer h.MockGit.Reset()
+
+			h.MockGit.SetBundle([]byte("# v2 git bundle\n"))
+			rr := h.makeRequest(t, http.MethodGet, baseURL+"/bundle", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "# v2 git bundle\n", rr.Body.String())
+			assert.Equal(t, "application/x-git-bundle", rr.Header().Get("Content-Type"))
+			assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+
+			h.MockGit.SetError(git.ErrEmptyRepository)
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/bundle", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+			assert.Empty(t, rr.Header().Get("Content-Disposition"))
+			h.MockGit.SetError(nil)
+		})
+
 		t.Run("unauthorized access", func(t *testing.T) {
 			h.MockGit.Reset()
 
@@ -140,6 +523,11 @@ func testGitHandlers(t *testing.T, dbConfig DatabaseConfig) {
 					method: http.MethodPost,
 					path:   baseURL + "/pull",
 				},
+				{
+					name:   "diff without token",
+					method: http.MethodGet,
+					path:   baseURL + "/diff",
+				},
 			}
 
 			for _, tc := range tests {
@@ -180,6 +568,155 @@ func testGitHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			// Try to pull
 			rr = h.makeRequest(t, http.MethodPost, nonGitBaseURL+"/pull", nil, h.RegularTestUser)
 			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+			// Try to diff
+			rr = h.makeRequest(t, http.MethodGet, nonGitBaseURL+"/diff", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			// Try to get the commit history
+			rr = h.makeRequest(t, http.MethodGet, nonGitBaseURL+"/log", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Contains(t, rr.Body.String(), "Git is not enabled for this workspace")
+
+			// Try to get the status
+			rr = h.makeRequest(t, http.MethodGet, nonGitBaseURL+"/status", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Contains(t, rr.Body.String(), "Git is not enabled for this workspace")
+
+			// Try to list and restore deleted files
+			rr = h.makeRequest(t, http.MethodGet, nonGitBaseURL+"/deleted", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodPost, nonGitBaseURL+"/restore?file_path=a.md", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			// Try to download a bundle
+			rr = h.makeRequest(t, http.MethodGet, nonGitBaseURL+"/bundle", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	})
+
+	t.Run("additional remotes", func(t *testing.T) {
+		workspace := &models.Workspace{
+			UserID:         h.RegularTestUser.session.UserID,
+			Name:           "Mirrored Workspace",
+			GitEnabled:     true,
+			GitURL:         "https://github.com/test/repo.git",
+			GitUser:        "testuser",
+			GitToken:       "testtoken",
+			GitCommitName:  "Test User",
+			GitCommitEmail: "test@example.com",
+		}
+		remote := func(name, url, user, token string) handlers.GitRemoteRequest {
+			return handlers.GitRemoteRequest{GitRemote: models.GitRemote{Name: name, URL: url, User: user}, Token: token}
+		}
+
+		rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", handlers.WorkspaceRequest{
+			Workspace: *workspace,
+			GitRemotes: []handlers.GitRemoteRequest{
+				remote("backup", "https://gitlab.com/test/repo.git", "backupuser", "backuptoken"),
+				remote("mirror", "https://codeberg.org/test/repo.git", "mirroruser", "mirrortoken"),
+			},
+		}, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(workspace))
+
+		workspaceURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name)
+
+		t.Run("remotes are stored", func(t *testing.T) {
+			rr := h.makeRequest(t, http.MethodGet, workspaceURL, nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			// The tokens are stored but never returned
+			assert.NotContains(t, rr.Body.String(), "mirrortoken")
+			var returned models.Workspace
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&returned))
+			require.Len(t, returned.GitRemotes, 2)
+			assert.Equal(t, "backup", returned.GitRemotes[0].Name)
+
+			stored, err := h.DB.GetWorkspaceByID(workspace.ID)
+			require.NoError(t, err)
+			require.Len(t, stored.GitRemotes, 2)
+			assert.Equal(t, "mirrortoken", stored.GitRemotes[1].Token)
+		})
+
+		t.Run("one remote fails", func(t *testing.T) {
+			h.MockGit.Reset()
+			h.MockGit.SetPushToError("mirror", fmt.Errorf("authentication required"))
+
+			rr := h.makeRequest(t, http.MethodPost, workspaceURL+"/git/commit", map[string]string{"message": "Mirror"}, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var response handlers.CommitResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			assert.NotEmpty(t, response.CommitHash)
+			assert.Equal(t, map[string]string{"mirror": "authentication required"}, response.RemoteErrors)
+
+			assert.Equal(t, 1, h.MockGit.GetPushCount(), "Primary remote should be pushed")
+			assert.Equal(t, []string{"backup"}, h.MockGit.GetPushedRemotes())
+		})
+
+		t.Run("invalid remotes", func(t *testing.T) {
+			testCases := []struct {
+				name    string
+				remotes []handlers.GitRemoteRequest
+			}{
+				{
+					name: "duplicate name",
+					remotes: []handlers.GitRemoteRequest{
+						remote("backup", "https://gitlab.com/test/a.git", "u", "t"),
+						remote("backup", "https://gitlab.com/test/b.git", "u", "t"),
+					},
+				},
+				{
+					name:    "primary remote name",
+					remotes: []handlers.GitRemoteRequest{remote("origin", "https://gitlab.com/test/a.git", "u", "t")},
+				},
+				{
+					name:    "invalid URL",
+					remotes: []handlers.GitRemoteRequest{remote("backup", "not a url", "u", "t")},
+				},
+				{
+					name:    "missing token of new remote",
+					remotes: []handlers.GitRemoteRequest{remote("other", "https://gitlab.com/test/a.git", "u", "")},
+				},
+			}
+
+			for _, tc := range testCases {
+				t.Run(tc.name, func(t *testing.T) {
+					update := handlers.WorkspaceRequest{Workspace: *workspace, GitRemotes: tc.remotes}
+					rr := h.makeRequest(t, http.MethodPut, workspaceURL, &update, h.RegularTestUser)
+					assert.Equal(t, http.StatusBadRequest, rr.Code)
+				})
+			}
+		})
+
+		t.Run("update keeps remotes left out", func(t *testing.T) {
+			update := *workspace
+			update.GitRemotes = nil
+			update.Theme = "dark"
+			rr := h.makeRequest(t, http.MethodPut, workspaceURL, &update, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			h.MockGit.Reset()
+			rr = h.makeRequest(t, http.MethodPost, workspaceURL+"/git/commit", map[string]string{"message": "Kept"}, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, []string{"backup", "mirror"}, h.MockGit.GetPushedRemotes())
+		})
+
+		t.Run("update keeps tokens left out", func(t *testing.T) {
+			update := handlers.WorkspaceRequest{
+				Workspace:  *workspace,
+				GitRemotes: []handlers.GitRemoteRequest{remote("mirror", "https://codeberg.org/test/moved.git", "mirroruser", "")},
+			}
+			rr := h.makeRequest(t, http.MethodPut, workspaceURL, &update, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			stored, err := h.DB.GetWorkspaceByID(workspace.ID)
+			require.NoError(t, err)
+			require.Len(t, stored.GitRemotes, 1)
+			assert.Equal(t, "https://codeberg.org/test/moved.git", stored.GitRemotes[0].URL)
+			assert.Equal(t, "mirrortoken", stored.GitRemotes[0].Token)
 		})
 	})
 }
@@ -3,20 +3,65 @@ package handlers
 import (
 	"encoding/json"
 	"lemma/internal/db"
+	"lemma/internal/events"
 	"lemma/internal/logging"
+	"lemma/internal/mail"
+	"lemma/internal/models"
+	"lemma/internal/render"
 	"lemma/internal/storage"
 	"net/http"
+	"time"
 )
 
 // ErrorResponse is a generic error response
 type ErrorResponse struct {
 	Message string `json:"message"`
+	// Code identifies the kind of error for clients, it is only set for some errors
+	Code string `json:"code,omitempty"`
 }
 
 // Handler provides common functionality for all handlers
 type Handler struct {
 	DB      db.Database
 	Storage storage.Manager
+
+	// DefaultPageSize is the limit used by list endpoints when none is requested
+	DefaultPageSize int
+	// MaxPageSize is the largest limit list endpoints return
+	MaxPageSize int
+	// DefaultHomeFile is the home file of workspaces that have none configured
+	DefaultHomeFile string
+	// Languages overrides the built-in file extension to language mapping of content responses
+	Languages map[string]string
+	// Renderer converts markdown files to HTML, nil renders with the default allowlist
+	Renderer *render.Renderer
+	// Location is the timezone used for date and time template variables, nil means UTC
+	Location *time.Location
+	// CommitIdentityFallback commits with the display name and email of the acting user when a workspace
+	// has no commit identity, and allows enabling auto-commit without one
+	CommitIdentityFallback bool
+	// UniqueDisplayNames rejects display names that are already used by another user
+	UniqueDisplayNames bool
+	// WorkspaceNameNormalization cleans up workspace names and rejects names that collide after normalization
+	WorkspaceNameNormalization models.WorkspaceNameNormalization
+	// MinWorkspaces is how many workspaces a user must keep, deleting below it is rejected
+	MinWorkspaces int
+	// MaxWorkspaces is how many workspaces a user may create, 0 disables the limit
+	MaxWorkspaces int
+	// MaxContentSize is the largest request body in bytes accepted when saving a file, 0 disables the limit
+	MaxContentSize int64
+	// MaxFileSize is the largest file in bytes accepted when saving or uploading a file, 0 disables the limit
+	MaxFileSize int64
+	// MaxUploadSize is the largest upload request in bytes, covering all of its files, 0 disables the limit
+	MaxUploadSize int64
+	// MaxUploadMemory is how many bytes of an upload are held in memory, 0 uses the default of 32 MiB
+	MaxUploadMemory int64
+	// Mailer delivers emails like password reset tokens, nil disables sending them
+	Mailer mail.Mailer
+	// Events publishes recorded activity to workspace event streams, nil disables the streams
+	Events *events.Hub
+	// AllowedOrigins are the origins besides the server itself that may open workspace WebSockets
+	AllowedOrigins []string
 }
 
 var logger logging.Logger
@@ -38,6 +38,7 @@ type testHarness struct {
 	RegularTestUser *testUser
 	TempDirectory   string
 	MockGit         *MockGitClient
+	Mailer          *MockMailer
 }
 
 type testUser struct {
@@ -54,6 +55,12 @@ type DatabaseConfig struct {
 // setupTestHarness creates a new test environment
 func setupTestHarness(t *testing.T, dbConfig DatabaseConfig) *testHarness {
 	t.Helper()
+	return setupTestHarnessWithConfig(t, dbConfig, nil)
+}
+
+// setupTestHarnessWithConfig creates the test environment, configure can adjust the server config
+func setupTestHarnessWithConfig(t *testing.T, dbConfig DatabaseConfig, configure func(*app.Config)) *testHarness {
+	t.Helper()
 
 	// Create temporary directory for test files
 	tempDir, err := os.MkdirTemp("", "lemma-test-*")
@@ -95,6 +102,7 @@ func setupTestHarness(t *testing.T, dbConfig DatabaseConfig) *testHarness {
 		NewGitClient: func(url, user, token, path, commitName, commitEmail string) git.Client {
 			return mockGit
 		},
+		MaxFileVersions: 10,
 	}
 	storageSvc := storage.NewServiceWithOptions(tempDir, storageOpts)
 
@@ -111,21 +119,35 @@ func setupTestHarness(t *testing.T, dbConfig DatabaseConfig) *testHarness {
 	// Initialize session service
 	sessionSvc := auth.NewSessionService(database, jwtSvc)
 
-	// Initialize cookie service
-	cookieSvc := auth.NewCookieService(true, "localhost")
-
 	// Create test config
 	testConfig := &app.Config{
-		DBURL:         "sqlite://:memory:",
-		WorkDir:       tempDir,
-		StaticPath:    "../testdata",
-		Port:          "8081",
-		AdminEmail:    "admin@test.com",
-		AdminPassword: "admin123",
-		EncryptionKey: "YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXoxMjM0NTY=",
-		IsDevelopment: true,
+		DBURL:                "sqlite://:memory:",
+		WorkDir:              tempDir,
+		StaticPath:           "../testdata",
+		Port:                 "8081",
+		AdminEmail:           "admin@test.com",
+		AdminPassword:        "admin123",
+		EncryptionKey:        "YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXoxMjM0NTY=",
+		IsDevelopment:        true,
+		DefaultPageSize:      20,
+		MaxPageSize:          50,
+		DefaultHomeFile:      "index.md",
+		Timezone:             "Pacific/Kiritimati",
+		MaxFileSize:          100 << 20,
+		MinWorkspacesPerUser: 1,
 	}
 
+	if configure != nil {
+		configure(testConfig)
+	}
+
+	// Initialize cookie service
+	cookieSvc := auth.NewCookieServiceWithOptions(testConfig.IsDevelopment, "localhost", auth.CookieOptions{
+		EnforceSecure: testConfig.SecureCookiesOnly,
+	})
+
+	mailer := &MockMailer{}
+
 	// Create server options
 	serverOpts := &app.Options{
 		Config:         testConfig,
@@ -134,6 +156,7 @@ func setupTestHarness(t *testing.T, dbConfig DatabaseConfig) *testHarness {
 		JWTManager:     jwtSvc,
 		SessionManager: sessionSvc,
 		CookieService:  cookieSvc,
+		Mailer:         mailer,
 	}
 
 	// Create server
@@ -148,6 +171,7 @@ func setupTestHarness(t *testing.T, dbConfig DatabaseConfig) *testHarness {
 		CookieManager:  cookieSvc,
 		TempDirectory:  tempDir,
 		MockGit:        mockGit,
+		Mailer:         mailer,
 	}
 
 	// Create test users
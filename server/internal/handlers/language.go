@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// defaultLanguages maps file extensions to the language hint sent to the editor
+var defaultLanguages = map[string]string{
+	".md":       "markdown",
+	".markdown": "markdown",
+	".txt":      "plaintext",
+	".py":       "python",
+	".go":       "go",
+	".js":       "javascript",
+	".mjs":      "javascript",
+	".jsx":      "javascript",
+	".ts":       "typescript",
+	".tsx":      "typescript",
+	".json":     "json",
+	".yaml":     "yaml",
+	".yml":      "yaml",
+	".toml":     "toml",
+	".html":     "html",
+	".htm":      "html",
+	".css":      "css",
+	".scss":     "scss",
+	".xml":      "xml",
+	".sh":       "shell",
+	".bash":     "shell",
+	".sql":      "sql",
+	".rs":       "rust",
+	".java":     "java",
+	".c":        "c",
+	".h":        "c",
+	".cpp":      "cpp",
+	".hpp":      "cpp",
+	".rb":       "ruby",
+	".php":      "php",
+	".lua":      "lua",
+	".ini":      "ini",
+	".tex":      "latex",
+}
+
+// fileLanguage returns the language hint for a file based on its extension.
+// Configured languages take precedence over the built-in mapping, an empty
+// configured language disables the hint for the extension.
+func (h *Handler) fileLanguage(filePath string) string {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if ext == "" {
+		return ""
+	}
+	if language, ok := h.Languages[ext]; ok {
+		return language
+	}
+	return defaultLanguages[ext]
+}
+
+// setLanguageHeader sets the X-Language header of a file if its language is known
+func (h *Handler) setLanguageHeader(w http.ResponseWriter, filePath string) {
+	if language := h.fileLanguage(filePath); language != "" {
+		w.Header().Set("X-Language", language)
+	}
+}
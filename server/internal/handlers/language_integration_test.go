@@ -0,0 +1,67 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"lemma/internal/app"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLanguage_Integration(t *testing.T) {
+	runWithDatabases(t, testFileLanguage)
+}
+
+func testFileLanguage(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+		config.Languages = map[string]string{".tpl": "html", ".txt": ""}
+	})
+	defer h.teardown(t)
+
+	workspace := &models.Workspace{Name: "Language Workspace"}
+	rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, h.RegularTestUser)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(workspace))
+
+	baseURL := fmt.Sprintf("/api/v1/workspaces/%s/files", url.PathEscape(workspace.Name))
+
+	testCases := []struct {
+		name     string
+		path     string
+		language string
+	}{
+		{name: "markdown", path: "notes.md", language: "markdown"},
+		{name: "python", path: "scripts/run.py", language: "python"},
+		{name: "upper case extension", path: "config.YAML", language: "yaml"},
+		{name: "configured extension", path: "page.tpl", language: "html"},
+		{name: "disabled extension", path: "plain.txt", language: ""},
+		{name: "unknown extension", path: "data.xyz", language: ""},
+		{name: "no extension", path: "Makefile", language: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(tc.path), strings.NewReader("content"), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape(tc.path), nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tc.language, rr.Header().Get("X-Language"))
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, baseURL+"/content?file_path="+url.QueryEscape("missing.md"), nil, h.RegularTestUser)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Empty(t, rr.Header().Get("X-Language"))
+	})
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"lemma/internal/context"
+)
+
+// BacklinksResponse represents the files linking to a file
+type BacklinksResponse struct {
+	Backlinks []string `json:"backlinks"`
+}
+
+// GetBacklinks godoc
+// @Summary Get backlinks
+// @Description Returns the markdown files of the workspace linking to a file with a [[wiki link]] or a markdown link,
+// @Description ordered by path. Links are resolved by path and otherwise by file name, ignoring case.
+// @Tags files
+// @ID getBacklinks
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "File path"
+// @Success 200 {object} BacklinksResponse
+// @Failure 400 {object} ErrorResponse "file_path is required"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 500 {object} ErrorResponse "Failed to build link graph"
+// @Router /workspaces/{workspace_name}/files/backlinks [get]
+func (h *Handler) GetBacklinks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "GetBacklinks",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("file_path")
+		if filePath == "" {
+			log.Debug("missing file_path parameter")
+			respondError(w, "file_path is required", http.StatusBadRequest)
+			return
+		}
+
+		// URL-decode the file path
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil {
+			log.Error("failed to decode file path",
+				"filePath", filePath,
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := h.Storage.ValidatePath(ctx.UserID, ctx.Workspace.ID, decodedPath); err != nil {
+			log.Error("invalid file path attempted",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		graph, err := h.Storage.BuildLinkGraph(ctx.UserID, ctx.Workspace.ID)
+		if err != nil {
+			log.Error("failed to build link graph",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to build link graph", http.StatusInternalServerError)
+			return
+		}
+
+		if !graph.HasFile(decodedPath) {
+			log.Debug("file not found",
+				"filePath", decodedPath,
+			)
+			respondError(w, "File not found", http.StatusNotFound)
+			return
+		}
+
+		respondJSON(w, BacklinksResponse{Backlinks: graph.BacklinksTo(decodedPath)})
+	}
+}
@@ -0,0 +1,70 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBacklinks_Integration(t *testing.T) {
+	runWithDatabases(t, testBacklinks)
+}
+
+func testBacklinks(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarness(t, dbConfig)
+	defer h.teardown(t)
+
+	workspace := &models.Workspace{Name: "Backlinks Workspace"}
+	rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, h.RegularTestUser)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	baseURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name) + "/files"
+	files := map[string]string{
+		"index.md":         "Start with [[Plan]] or [the ideas](notes/ideas.md).",
+		"notes/ideas.md":   "See [[plan]] and [[Nowhere]].",
+		"projects/plan.md": "Back to [index](../index.md).",
+		"orphan.md":        "No links here.",
+	}
+	for path, content := range files {
+		rr := h.makeRequestRaw(t, http.MethodPost, baseURL+"?file_path="+url.QueryEscape(path), strings.NewReader(content), h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+
+	getBacklinks := func(t *testing.T, path string) []string {
+		t.Helper()
+		rr := h.makeRequest(t, http.MethodGet, baseURL+"/backlinks?file_path="+url.QueryEscape(path), nil, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response handlers.BacklinksResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		return response.Backlinks
+	}
+
+	t.Run("backlinks", func(t *testing.T) {
+		assert.Equal(t, []string{"index.md", "notes/ideas.md"}, getBacklinks(t, "projects/plan.md"))
+		assert.Equal(t, []string{"projects/plan.md"}, getBacklinks(t, "index.md"))
+		assert.Equal(t, []string{"index.md"}, getBacklinks(t, "notes/ideas.md"))
+		assert.Equal(t, []string{}, getBacklinks(t, "orphan.md"))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, baseURL+"/backlinks", nil, h.RegularTestUser)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, baseURL+"/backlinks?file_path="+url.QueryEscape("../../etc/passwd"), nil, h.RegularTestUser)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, baseURL+"/backlinks?file_path="+url.QueryEscape("missing.md"), nil, h.RegularTestUser)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
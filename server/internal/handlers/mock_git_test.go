@@ -4,6 +4,7 @@ package handlers_test
 
 import (
 	"fmt"
+	"io"
 	"lemma/internal/git"
 )
 
@@ -12,6 +13,19 @@ type MockGitClient struct {
 	initialized   bool
 	cloned        bool
 	lastCommitMsg string
+	lastAuthor    git.Author
+	lastSigner    git.Signer
+	diff          string
+	lastDiffPath  string
+	deletedFiles  []string
+	history       map[string][]byte
+	bundle        []byte
+	checkedOut    string
+	pushedTo      []string
+	pushToErrors  map[string]error
+	pullResult    git.PullResult
+	commits       []git.Commit
+	status        git.GitStatus
 	error         error
 
 	pullCount   int
@@ -43,21 +57,27 @@ func (m *MockGitClient) Clone() error {
 }
 
 // Pull implements git.Client
-func (m *MockGitClient) Pull() error {
+func (m *MockGitClient) Pull() (git.PullResult, error) {
 	if m.error != nil {
-		return m.error
+		return git.PullResult{}, m.error
 	}
 	m.pullCount++
-	return nil
+	result := m.pullResult
+	if result.Files == nil {
+		result.Files = []string{}
+	}
+	return result, nil
 }
 
 // Commit implements git.Client
-func (m *MockGitClient) Commit(message string) (git.CommitHash, error) {
+func (m *MockGitClient) Commit(message string, author git.Author, signer git.Signer) (git.CommitHash, error) {
 	if m.error != nil {
 		return git.CommitHash{}, m.error
 	}
 	m.commitCount++
 	m.lastCommitMsg = message
+	m.lastAuthor = author
+	m.lastSigner = signer
 	return git.CommitHash{}, nil
 }
 
@@ -70,6 +90,15 @@ func (m *MockGitClient) Push() error {
 	return nil
 }
 
+// PushTo implements git.Client
+func (m *MockGitClient) PushTo(remote git.Remote) error {
+	if err := m.pushToErrors[remote.Name]; err != nil {
+		return err
+	}
+	m.pushedTo = append(m.pushedTo, remote.Name)
+	return nil
+}
+
 // EnsureRepo implements git.Client
 func (m *MockGitClient) EnsureRepo() error {
 	if m.error != nil {
@@ -80,6 +109,100 @@ func (m *MockGitClient) EnsureRepo() error {
 	return nil
 }
 
+// DiffWorkingTree implements git.Client
+func (m *MockGitClient) DiffWorkingTree(path string) (string, error) {
+	if m.error != nil {
+		return "", m.error
+	}
+	m.lastDiffPath = path
+	return m.diff, nil
+}
+
+// ListDeletedFiles implements git.Client
+func (m *MockGitClient) ListDeletedFiles() ([]string, error) {
+	if m.error != nil {
+		return nil, m.error
+	}
+	if m.deletedFiles == nil {
+		return []string{}, nil
+	}
+	return m.deletedFiles, nil
+}
+
+// ListDeletedFilesSince implements git.Client
+func (m *MockGitClient) ListDeletedFilesSince(commit string) ([]string, error) {
+	if m.error != nil {
+		return nil, m.error
+	}
+	if commit == "unknown" {
+		return nil, git.ErrCommitNotFound
+	}
+	return m.ListDeletedFiles()
+}
+
+// ReadFileFromHistory implements git.Client
+func (m *MockGitClient) ReadFileFromHistory(path string) ([]byte, error) {
+	if m.error != nil {
+		return nil, m.error
+	}
+	content, ok := m.history[path]
+	if !ok {
+		return nil, git.ErrFileNotInHistory
+	}
+	return content, nil
+}
+
+// Log implements git.Client
+func (m *MockGitClient) Log(limit int) ([]git.Commit, error) {
+	if m.error != nil {
+		return nil, m.error
+	}
+	commits := []git.Commit{}
+	for _, commit := range m.commits {
+		if len(commits) == limit {
+			break
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// Status implements git.Client
+func (m *MockGitClient) Status() (git.GitStatus, error) {
+	if m.error != nil {
+		return git.GitStatus{}, m.error
+	}
+	status := m.status
+	if status.Modified == nil {
+		status.Modified = []string{}
+	}
+	if status.Untracked == nil {
+		status.Untracked = []string{}
+	}
+	return status, nil
+}
+
+// CreateBundle implements git.Client
+func (m *MockGitClient) CreateBundle(w io.Writer) error {
+	if m.error != nil {
+		return m.error
+	}
+	_, err := w.Write(m.bundle)
+	return err
+}
+
+// Checkout implements git.Client
+func (m *MockGitClient) Checkout(ref string) error {
+	if m.error != nil {
+		return m.error
+	}
+	if ref == "unknown" {
+		return git.ErrRefNotFound
+	}
+	m.checkedOut = ref
+	return nil
+}
+
 // Helper methods for tests
 
 func (m *MockGitClient) GetCommitCount() int {
@@ -98,6 +221,73 @@ func (m *MockGitClient) GetLastCommitMessage() string {
 	return m.lastCommitMsg
 }
 
+// GetLastAuthor returns the author passed to the last commit
+func (m *MockGitClient) GetLastAuthor() git.Author {
+	return m.lastAuthor
+}
+
+// GetLastSigner returns the signer passed to the last commit, nil for an unsigned commit
+func (m *MockGitClient) GetLastSigner() git.Signer {
+	return m.lastSigner
+}
+
+func (m *MockGitClient) GetLastDiffPath() string {
+	return m.lastDiffPath
+}
+
+// SetDiff sets the diff returned by DiffWorkingTree
+func (m *MockGitClient) SetDiff(diff string) {
+	m.diff = diff
+}
+
+// SetDeletedFiles sets the files returned by ListDeletedFiles and ListDeletedFilesSince
+func (m *MockGitClient) SetDeletedFiles(files []string) {
+	m.deletedFiles = files
+}
+
+// SetHistory sets the committed file contents returned by ReadFileFromHistory
+func (m *MockGitClient) SetHistory(history map[string][]byte) {
+	m.history = history
+}
+
+// GetPushedRemotes returns the names of the additional remotes pushed to
+func (m *MockGitClient) GetPushedRemotes() []string {
+	return m.pushedTo
+}
+
+// SetPushToError makes pushes to the named additional remote fail
+func (m *MockGitClient) SetPushToError(name string, err error) {
+	if m.pushToErrors == nil {
+		m.pushToErrors = make(map[string]error)
+	}
+	m.pushToErrors[name] = err
+}
+
+// GetCheckedOutRef returns the ref of the last checkout, empty after returning to the branch
+func (m *MockGitClient) GetCheckedOutRef() string {
+	return m.checkedOut
+}
+
+// SetCommits sets the history returned by Log, newest first
+func (m *MockGitClient) SetCommits(commits []git.Commit) {
+	m.commits = commits
+}
+
+// SetStatus sets the status returned by Status
+func (m *MockGitClient) SetStatus(status git.GitStatus) {
+	m.status = status
+}
+
+// SetPullResult sets the result returned by Pull
+func (m *MockGitClient) SetPullResult(result git.PullResult) {
+	m.pullResult = result
+}
+
+// SetBundle sets the content written by CreateBundle
+func (m *MockGitClient) SetBundle(bundle []byte) {
+	m.bundle = bundle
+}
+
 func (m *MockGitClient) IsInitialized() bool {
 	return m.initialized
 }
@@ -111,6 +301,19 @@ func (m *MockGitClient) Reset() {
 	m.initialized = false
 	m.cloned = false
 	m.lastCommitMsg = ""
+	m.lastAuthor = git.Author{}
+	m.lastSigner = nil
+	m.diff = ""
+	m.lastDiffPath = ""
+	m.deletedFiles = nil
+	m.history = nil
+	m.bundle = nil
+	m.checkedOut = ""
+	m.pushedTo = nil
+	m.pullResult = git.PullResult{}
+	m.commits = nil
+	m.status = git.GitStatus{}
+	m.pushToErrors = nil
 	m.pullCount = 0
 	m.commitCount = 0
 	m.pushCount = 0
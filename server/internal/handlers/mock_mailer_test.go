@@ -0,0 +1,30 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"sync"
+
+	"lemma/internal/mail"
+)
+
+// MockMailer implements the mail.Mailer interface for testing and records the sent messages
+type MockMailer struct {
+	mu       sync.Mutex
+	messages []mail.Message
+}
+
+// Send implements mail.Mailer
+func (m *MockMailer) Send(msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// GetMessages returns the messages sent so far
+func (m *MockMailer) GetMessages() []mail.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mail.Message(nil), m.messages...)
+}
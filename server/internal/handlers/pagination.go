@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Fallback page sizes used when the handler is not configured with its own
+const (
+	fallbackDefaultPageSize = 100
+	fallbackMaxPageSize     = 1000
+)
+
+// Pagination headers returned by list endpoints
+const (
+	HeaderPaginationLimit  = "X-Pagination-Limit"
+	HeaderPaginationOffset = "X-Pagination-Offset"
+	HeaderTotalCount       = "X-Total-Count"
+)
+
+// Pagination holds the effective limit and offset of a list request
+type Pagination struct {
+	Limit  int
+	Offset int
+}
+
+// pageSizes returns the configured default and max page sizes
+func (h *Handler) pageSizes() (defaultSize, maxSize int) {
+	defaultSize, maxSize = h.DefaultPageSize, h.MaxPageSize
+	if maxSize <= 0 {
+		maxSize = fallbackMaxPageSize
+	}
+	if defaultSize <= 0 {
+		defaultSize = fallbackDefaultPageSize
+	}
+	if defaultSize > maxSize {
+		defaultSize = maxSize
+	}
+	return defaultSize, maxSize
+}
+
+// parsePagination reads the limit and offset query parameters.
+// An omitted limit uses the default page size and a limit over the max page size is clamped.
+// It responds with 400 and returns false if the parameters are invalid.
+func (h *Handler) parsePagination(w http.ResponseWriter, r *http.Request) (Pagination, bool) {
+	defaultSize, maxSize := h.pageSizes()
+	p := Pagination{Limit: defaultSize}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			respondError(w, "Invalid limit", http.StatusBadRequest)
+			return p, false
+		}
+		if limit > 0 {
+			p.Limit = min(limit, maxSize)
+		}
+	}
+
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			respondError(w, "Invalid offset", http.StatusBadRequest)
+			return p, false
+		}
+		p.Offset = offset
+	}
+
+	return p, true
+}
+
+// setPaginationHeaders sets the pagination headers of a page out of total items
+func setPaginationHeaders(w http.ResponseWriter, p Pagination, total int) {
+	w.Header().Set(HeaderPaginationLimit, strconv.Itoa(p.Limit))
+	w.Header().Set(HeaderPaginationOffset, strconv.Itoa(p.Offset))
+	w.Header().Set(HeaderTotalCount, strconv.Itoa(total))
+}
+
+// paginate returns the page of items selected by p and sets the pagination headers
+func paginate[T any](w http.ResponseWriter, items []T, p Pagination) []T {
+	setPaginationHeaders(w, p, len(items))
+
+	start := min(p.Offset, len(items))
+	end := min(start+p.Limit, len(items))
+	return items[start:end]
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"lemma/internal/auth"
+	"lemma/internal/logging"
+	"lemma/internal/mail"
+	"lemma/internal/models"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordResetTokenTTL is how long a password reset token can be used
+const passwordResetTokenTTL = time.Hour
+
+// ForgotPasswordRequest represents a request for a password reset token
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ForgotPasswordResponse is returned for every password reset request, whether the email is registered or not
+type ForgotPasswordResponse struct {
+	Message string `json:"message"`
+}
+
+// ResetPasswordRequest represents a request to set a new password with a reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
+
+// hashResetToken returns the hash a password reset token is stored as
+func hashResetToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Sends a single-use password reset token to the email address if it belongs to a user.
+// @Description The token expires after one hour. The response is the same whether the email is registered or not,
+// @Description the token is sent after the response.
+// @Tags auth
+// @ID forgotPassword
+// @Accept json
+// @Produce json
+// @Param body body ForgotPasswordRequest true "Forgot password request"
+// @Success 200 {object} ForgotPasswordResponse
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Router /auth/forgot-password [post]
+func (h *Handler) ForgotPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := getAuthLogger().With(
+			"handler", "ForgotPassword",
+			"clientIP", r.RemoteAddr,
+		)
+
+		var req ForgotPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Debug("failed to decode request body",
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Failures are only logged, the response must not reveal whether the email is registered
+		response := ForgotPasswordResponse{
+			Message: "If the email is registered, a password reset token has been sent",
+		}
+
+		user, err := h.DB.GetUserByEmail(req.Email)
+		if err != nil {
+			log.Debug("password reset requested for unknown email",
+				"error", err.Error(),
+			)
+			respondJSON(w, response)
+			return
+		}
+
+		// The token is created and sent in the background, so that the response
+		// takes as long for a registered email as for an unknown one
+		go h.sendPasswordResetToken(user, log)
+
+		respondJSON(w, response)
+	}
+}
+
+// sendPasswordResetToken creates a password reset token for user and emails it, failures are only logged
+func (h *Handler) sendPasswordResetToken(user *models.User, log logging.Logger) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		log.Error("failed to generate password reset token",
+			"userID", user.ID,
+			"error", err.Error(),
+		)
+		return
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	resetToken := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTokenTTL),
+	}
+	if err := h.DB.CreatePasswordResetToken(resetToken); err != nil {
+		log.Error("failed to store password reset token",
+			"userID", user.ID,
+			"error", err.Error(),
+		)
+		return
+	}
+
+	if h.Mailer == nil {
+		log.Warn("no mailer configured, password reset token not sent",
+			"userID", user.ID,
+		)
+		return
+	}
+
+	err := h.Mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your Lemma password",
+		Body: fmt.Sprintf("Use this token to set a new password: %s\n\nThe token expires at %s. If you did not request a password reset, you can ignore this email.",
+			token, resetToken.ExpiresAt.UTC().Format(time.RFC1123)),
+		Secrets: []string{token},
+	})
+	if err != nil {
+		log.Error("failed to send password reset email",
+			"userID", user.ID,
+			"error", err.Error(),
+		)
+		return
+	}
+
+	log.Info("password reset token sent",
+		"userID", user.ID,
+	)
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Sets a new password with a password reset token. The token can only be used once
+// @Description and all sessions of the user are invalidated.
+// @Tags auth
+// @ID resetPassword
+// @Accept json
+// @Param body body ResetPasswordRequest true "Reset password request"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 400 {object} ErrorResponse "New password must be at least 8 characters long"
+// @Failure 400 {object} ErrorResponse "Invalid or expired reset token"
+// @Failure 500 {object} ErrorResponse "Failed to process new password"
+// @Failure 500 {object} ErrorResponse "Failed to invalidate sessions"
+// @Router /auth/reset-password [post]
+func (h *Handler) ResetPassword(authManager auth.SessionManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := getAuthLogger().With(
+			"handler", "ResetPassword",
+			"clientIP", r.RemoteAddr,
+		)
+
+		var req ResetPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Debug("failed to decode request body",
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if len(req.NewPassword) < 8 {
+			log.Debug("password reset rejected - too short",
+				"passwordLength", len(req.NewPassword),
+			)
+			respondError(w, "New password must be at least 8 characters long", http.StatusBadRequest)
+			return
+		}
+
+		if req.Token == "" {
+			respondError(w, "Invalid or expired reset token", http.StatusBadRequest)
+			return
+		}
+
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+		if err != nil {
+			log.Error("failed to hash new password",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to process new password", http.StatusInternalServerError)
+			return
+		}
+
+		userID, err := h.DB.ResetPasswordWithToken(hashResetToken(req.Token), string(hashedPassword))
+		if err != nil {
+			log.Debug("password reset with invalid token",
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid or expired reset token", http.StatusBadRequest)
+			return
+		}
+
+		if err := authManager.InvalidateAllUserSessions(userID); err != nil {
+			log.Error("failed to invalidate sessions after password reset",
+				"userID", userID,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to invalidate sessions", http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("password reset",
+			"userID", userID,
+		)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,132 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordReset_Integration(t *testing.T) {
+	runWithDatabases(t, testPasswordReset)
+}
+
+func testPasswordReset(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarness(t, dbConfig)
+	defer h.teardown(t)
+
+	tokenPattern := regexp.MustCompile(`[0-9a-f]{64}`)
+
+	// requestToken requests a reset token for email and returns the token from the sent email
+	requestToken := func(t *testing.T, email string) string {
+		t.Helper()
+		sent := len(h.Mailer.GetMessages())
+
+		rr := h.makeRequest(t, http.MethodPost, "/api/v1/auth/forgot-password", handlers.ForgotPasswordRequest{Email: email}, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		// The email is sent after the response
+		require.Eventually(t, func() bool {
+			return len(h.Mailer.GetMessages()) > sent
+		}, time.Second, 10*time.Millisecond)
+		messages := h.Mailer.GetMessages()
+		require.Len(t, messages, sent+1)
+		assert.Equal(t, email, messages[sent].To)
+		token := tokenPattern.FindString(messages[sent].Body)
+		require.NotEmpty(t, token)
+		return token
+	}
+
+	resetPassword := func(t *testing.T, token, password string) int {
+		t.Helper()
+		rr := h.makeRequest(t, http.MethodPost, "/api/v1/auth/reset-password", handlers.ResetPasswordRequest{
+			Token:       token,
+			NewPassword: password,
+		}, nil)
+		return rr.Code
+	}
+
+	login := func(t *testing.T, email, password string) int {
+		t.Helper()
+		rr := h.makeRequest(t, http.MethodPost, "/api/v1/auth/login", handlers.LoginRequest{
+			Email:    email,
+			Password: password,
+		}, nil)
+		return rr.Code
+	}
+
+	t.Run("unknown email", func(t *testing.T) {
+		sent := len(h.Mailer.GetMessages())
+		rr := h.makeRequest(t, http.MethodPost, "/api/v1/auth/forgot-password", handlers.ForgotPasswordRequest{Email: "nobody@test.com"}, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Len(t, h.Mailer.GetMessages(), sent)
+
+		known := h.makeRequest(t, http.MethodPost, "/api/v1/auth/forgot-password", handlers.ForgotPasswordRequest{Email: "user@test.com"}, nil)
+		require.Equal(t, http.StatusOK, known.Code)
+		assert.Equal(t, known.Body.String(), rr.Body.String())
+
+		// Wait for the token of the known email so that it is not counted by the next test
+		require.Eventually(t, func() bool {
+			return len(h.Mailer.GetMessages()) == sent+1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("reset invalidates sessions", func(t *testing.T) {
+		user := h.createTestUser(t, "reset@test.com", "oldpassword", models.RoleEditor)
+		token := requestToken(t, "reset@test.com")
+
+		require.Equal(t, http.StatusNoContent, resetPassword(t, token, "newpassword"))
+
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/auth/me", nil, user)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+		assert.Equal(t, http.StatusUnauthorized, login(t, "reset@test.com", "oldpassword"))
+		assert.Equal(t, http.StatusOK, login(t, "reset@test.com", "newpassword"))
+	})
+
+	t.Run("token reuse", func(t *testing.T) {
+		h.createTestUser(t, "reuse@test.com", "oldpassword", models.RoleEditor)
+		token := requestToken(t, "reuse@test.com")
+
+		require.Equal(t, http.StatusNoContent, resetPassword(t, token, "newpassword"))
+		assert.Equal(t, http.StatusBadRequest, resetPassword(t, token, "otherpassword"))
+		assert.Equal(t, http.StatusOK, login(t, "reuse@test.com", "newpassword"))
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		user := h.createTestUser(t, "expired@test.com", "oldpassword", models.RoleEditor)
+
+		token := "expired-token"
+		hash := sha256.Sum256([]byte(token))
+		require.NoError(t, h.DB.CreatePasswordResetToken(&models.PasswordResetToken{
+			UserID:    user.userModel.ID,
+			TokenHash: hex.EncodeToString(hash[:]),
+			ExpiresAt: time.Now().Add(-time.Minute),
+		}))
+
+		assert.Equal(t, http.StatusBadRequest, resetPassword(t, token, "newpassword"))
+		assert.Equal(t, http.StatusOK, login(t, "expired@test.com", "oldpassword"))
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		h.createTestUser(t, "short@test.com", "oldpassword", models.RoleEditor)
+		token := requestToken(t, "short@test.com")
+
+		assert.Equal(t, http.StatusBadRequest, resetPassword(t, token, "short"))
+		assert.Equal(t, http.StatusBadRequest, resetPassword(t, "", "newpassword"))
+		assert.Equal(t, http.StatusBadRequest, resetPassword(t, "unknown", "newpassword"))
+
+		// A rejected password does not use up the token
+		assert.Equal(t, http.StatusNoContent, resetPassword(t, token, "newpassword"))
+	})
+}
@@ -0,0 +1,114 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinnedRef_Integration(t *testing.T) {
+	runWithDatabases(t, testPinnedRef)
+}
+
+func testPinnedRef(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarness(t, dbConfig)
+	defer h.teardown(t)
+
+	user := h.createTestUser(t, "pinned@test.com", "password123", models.RoleEditor)
+
+	workspace := &models.Workspace{
+		Name:           "Pinned Workspace",
+		GitEnabled:     true,
+		GitURL:         "https://github.com/test/repo.git",
+		GitUser:        "testuser",
+		GitToken:       "testtoken",
+		GitCommitName:  "Test User",
+		GitCommitEmail: "test@example.com",
+	}
+	rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, user)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(workspace))
+
+	workspaceURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name)
+	fileURL := fmt.Sprintf("%s/files?file_path=%s", workspaceURL, url.QueryEscape("note.md"))
+
+	rr = h.makeRequestRaw(t, http.MethodPost, fileURL, strings.NewReader("content"), user)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	updatePin := func(t *testing.T, ref string) int {
+		t.Helper()
+		workspace.GitPinnedRef = ref
+		rr := h.makeRequest(t, http.MethodPut, workspaceURL, workspace, user)
+		return rr.Code
+	}
+
+	t.Run("pin", func(t *testing.T) {
+		h.MockGit.Reset()
+		require.Equal(t, http.StatusOK, updatePin(t, "v1.0"))
+		assert.Equal(t, "v1.0", h.MockGit.GetCheckedOutRef())
+
+		rr := h.makeRequest(t, http.MethodGet, workspaceURL, nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var got models.Workspace
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+		assert.Equal(t, "v1.0", got.GitPinnedRef)
+	})
+
+	t.Run("writes blocked while pinned", func(t *testing.T) {
+		h.MockGit.Reset()
+
+		rr := h.makeRequestRaw(t, http.MethodPost, fileURL, strings.NewReader("changed"), user)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodDelete, fileURL, nil, user)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodPost, workspaceURL+"/git/commit", map[string]string{"message": "test"}, user)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodPost, workspaceURL+"/git/pull", nil, user)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		assert.Equal(t, 0, h.MockGit.GetCommitCount())
+		assert.Equal(t, 0, h.MockGit.GetPullCount())
+
+		rr = h.makeRequest(t, http.MethodGet, workspaceURL+"/files/content?file_path=note.md", nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "content", rr.Body.String())
+	})
+
+	t.Run("unknown ref", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, updatePin(t, "unknown"))
+		workspace.GitPinnedRef = "v1.0"
+	})
+
+	t.Run("unpin", func(t *testing.T) {
+		h.MockGit.Reset()
+		require.Equal(t, http.StatusOK, updatePin(t, ""))
+		assert.Equal(t, "", h.MockGit.GetCheckedOutRef())
+
+		rr := h.makeRequestRaw(t, http.MethodPost, fileURL, strings.NewReader("changed"), user)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("pin without git", func(t *testing.T) {
+		nonGitWorkspace := &models.Workspace{Name: "Unpinnable Workspace"}
+		rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", nonGitWorkspace, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(nonGitWorkspace))
+
+		nonGitWorkspace.GitPinnedRef = "v1.0"
+		rr = h.makeRequest(t, http.MethodPut, "/api/v1/workspaces/"+url.PathEscape(nonGitWorkspace.Name), nonGitWorkspace, user)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Profiling returns a handler serving the runtime profiles of net/http/pprof, to be mounted
+// behind admin authentication. The index lists the profiles, e.g. heap or goroutine?debug=2,
+// and profile?seconds=30 records a CPU profile.
+func Profiling() http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", pprof.Index)
+	r.Get("/cmdline", pprof.Cmdline)
+	r.Get("/profile", pprof.Profile)
+	r.Get("/symbol", pprof.Symbol)
+	r.Post("/symbol", pprof.Symbol)
+	r.Get("/trace", pprof.Trace)
+	// pprof.Index only resolves named profiles under /debug/pprof/, so they are served by name here
+	r.Get("/{profile}", func(w http.ResponseWriter, r *http.Request) {
+		pprof.Handler(chi.URLParam(r, "profile")).ServeHTTP(w, r)
+	})
+	return r
+}
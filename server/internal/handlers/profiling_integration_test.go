@@ -0,0 +1,67 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"lemma/internal/app"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiling_Integration(t *testing.T) {
+	runWithDatabases(t, testProfiling)
+}
+
+func testProfiling(t *testing.T, dbConfig DatabaseConfig) {
+	t.Run("enabled", func(t *testing.T) {
+		h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+			config.EnableProfiling = true
+		})
+		defer h.teardown(t)
+
+		paths := []string{
+			"/api/v1/admin/debug/pprof/",
+			"/api/v1/admin/debug/pprof/heap",
+			"/api/v1/admin/debug/pprof/goroutine?debug=1",
+		}
+		for _, path := range paths {
+			rr := h.makeRequest(t, http.MethodGet, path, nil, h.AdminTestUser)
+			assert.Equal(t, http.StatusOK, rr.Code, path)
+
+			rr = h.makeRequest(t, http.MethodGet, path, nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusForbidden, rr.Code, path)
+
+			rr = h.makeRequest(t, http.MethodGet, path, nil, nil)
+			assert.Equal(t, http.StatusUnauthorized, rr.Code, path)
+		}
+
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/admin/debug/pprof/unknown", nil, h.AdminTestUser)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("profile outlives request timeout", func(t *testing.T) {
+		h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+			config.EnableProfiling = true
+			config.RequestTimeout = 100 * time.Millisecond
+		})
+		defer h.teardown(t)
+
+		// A cancelled profile still answers 200, it just stops recording early
+		start := time.Now()
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/admin/debug/pprof/profile?seconds=1", nil, h.AdminTestUser)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		h := setupTestHarness(t, dbConfig)
+		defer h.teardown(t)
+
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/admin/debug/pprof/heap", nil, h.AdminTestUser)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
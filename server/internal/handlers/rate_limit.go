@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"lemma/internal/context"
+	"lemma/internal/models"
+
+	"github.com/go-chi/httprate"
+)
+
+// RoleRateLimit returns a middleware limiting the requests of each user within window to the limit of their role.
+// Users whose role has no limit are let through. It must run after the user context middleware.
+// Limited requests get 429 Too Many Requests with the X-RateLimit and Retry-After headers.
+func RoleRateLimit(limits map[models.UserRole]int, window time.Duration) func(http.Handler) http.Handler {
+	limiters := make(map[string]*httprate.RateLimiter, len(limits))
+	for role, limit := range limits {
+		if limit > 0 {
+			limiters[string(role)] = httprate.NewRateLimiter(limit, window)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, ok := context.GetRequestContext(w, r)
+			if !ok {
+				return
+			}
+
+			if limiter, ok := limiters[ctx.UserRole]; ok && limiter.RespondOnLimit(w, r, strconv.Itoa(ctx.UserID)) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"lemma/internal/app"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleRateLimit_Integration(t *testing.T) {
+	runWithDatabases(t, testRoleRateLimit)
+}
+
+func testRoleRateLimit(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+		config.RateLimitWindow = time.Hour
+		config.RoleRateLimits = map[models.UserRole]int{
+			models.RoleViewer: 2,
+			models.RoleEditor: 4,
+		}
+	})
+	defer h.teardown(t)
+
+	viewer := h.createTestUser(t, "viewer@test.com", "password123", models.RoleViewer)
+
+	// allowedRequests returns how many of the requests of user pass before the limit is hit
+	allowedRequests := func(t *testing.T, user *testUser, attempts int) int {
+		t.Helper()
+		for i := 0; i < attempts; i++ {
+			rr := h.makeRequest(t, http.MethodGet, "/api/v1/auth/me", nil, user)
+			if rr.Code == http.StatusTooManyRequests {
+				assert.NotEmpty(t, rr.Header().Get("X-RateLimit-Limit"))
+				assert.Equal(t, "0", rr.Header().Get("X-RateLimit-Remaining"))
+				assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+				return i
+			}
+			require.Equal(t, http.StatusOK, rr.Code)
+		}
+		return attempts
+	}
+
+	t.Run("viewer hits a lower limit than an editor", func(t *testing.T) {
+		assert.Equal(t, 2, allowedRequests(t, viewer, 10))
+		assert.Equal(t, 4, allowedRequests(t, h.RegularTestUser, 10))
+	})
+
+	t.Run("limits are per user", func(t *testing.T) {
+		other := h.createTestUser(t, "other-viewer@test.com", "password123", models.RoleViewer)
+		assert.Equal(t, 2, allowedRequests(t, other, 10))
+	})
+
+	t.Run("roles without a limit", func(t *testing.T) {
+		assert.Equal(t, 10, allowedRequests(t, h.AdminTestUser, 10))
+	})
+
+	t.Run("headers", func(t *testing.T) {
+		editor := h.createTestUser(t, "headers@test.com", "password123", models.RoleEditor)
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/auth/me", nil, editor)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "4", rr.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "3", rr.Header().Get("X-RateLimit-Remaining"))
+	})
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+)
+
+// ReadOnlyMode returns a middleware that rejects requests modifying data with 403 Forbidden.
+// GET, HEAD and OPTIONS requests and requests to the exempt paths, e.g. login and logout, are let through.
+func ReadOnlyMode(exemptPaths ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if slices.Contains(exemptPaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			getHandlersLogger().Debug("rejected write in read-only mode",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"clientIP", r.RemoteAddr,
+			)
+			respondError(w, "Server is in read-only mode", http.StatusForbidden)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"lemma/internal/app"
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnlyMode_Integration(t *testing.T) {
+	runWithDatabases(t, testReadOnlyMode)
+}
+
+func testReadOnlyMode(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+		config.ReadOnlyMode = true
+	})
+	defer h.teardown(t)
+
+	workspace, err := h.DB.GetWorkspaceByID(h.RegularTestUser.userModel.LastWorkspaceID)
+	require.NoError(t, err)
+	filesURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name) + "/files"
+
+	// Files created outside of the API are browsable
+	require.NoError(t, h.Storage.SaveFile(h.RegularTestUser.session.UserID, workspace.ID, "published.md", []byte("content")))
+
+	t.Run("reads work", func(t *testing.T) {
+		reads := []string{
+			"/api/v1/auth/me",
+			"/api/v1/workspaces",
+			"/api/v1/workspaces/" + url.PathEscape(workspace.Name),
+			filesURL,
+			filesURL + "/content?file_path=published.md",
+		}
+		for _, path := range reads {
+			rr := h.makeRequest(t, http.MethodGet, path, nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusOK, rr.Code, path)
+		}
+
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/admin/users", nil, h.AdminTestUser)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("writes are rejected", func(t *testing.T) {
+		writes := []struct {
+			method string
+			path   string
+			body   any
+			user   *testUser
+		}{
+			{http.MethodPost, "/api/v1/workspaces", &models.Workspace{Name: "New Workspace"}, h.RegularTestUser},
+			{http.MethodPut, "/api/v1/workspaces/" + url.PathEscape(workspace.Name), workspace, h.RegularTestUser},
+			{http.MethodDelete, "/api/v1/workspaces/" + url.PathEscape(workspace.Name), nil, h.RegularTestUser},
+			{http.MethodPost, filesURL + "?file_path=new.md", "content", h.RegularTestUser},
+			{http.MethodDelete, filesURL + "?file_path=published.md", nil, h.RegularTestUser},
+			{http.MethodPut, "/api/v1/profile", map[string]string{"displayName": "Changed"}, h.RegularTestUser},
+			{http.MethodPatch, "/api/v1/profile", map[string]string{"displayName": "Changed"}, h.RegularTestUser},
+			{http.MethodPost, "/api/v1/admin/users", map[string]string{"email": "new@test.com"}, h.AdminTestUser},
+		}
+
+		for _, write := range writes {
+			rr := h.makeRequest(t, write.method, write.path, write.body, write.user)
+			require.Equal(t, http.StatusForbidden, rr.Code, "%s %s", write.method, write.path)
+
+			var response handlers.ErrorResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			assert.Equal(t, "Server is in read-only mode", response.Message)
+		}
+
+		// Nothing was changed
+		content, err := h.Storage.GetFileContent(h.RegularTestUser.session.UserID, workspace.ID, "published.md")
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(content))
+
+		workspaces, err := h.DB.GetWorkspacesByUserID(h.RegularTestUser.session.UserID)
+		require.NoError(t, err)
+		assert.Len(t, workspaces, 1)
+	})
+
+	t.Run("login and logout work", func(t *testing.T) {
+		loginReq := handlers.LoginRequest{Email: "user@test.com", Password: "user123"}
+		rr := h.makeRequest(t, http.MethodPost, "/api/v1/auth/login", loginReq, nil)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodPost, "/api/v1/auth/logout", nil, h.RegularTestUser)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+}
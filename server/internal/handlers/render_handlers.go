@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+
+	"lemma/internal/context"
+	"lemma/internal/models"
+	"lemma/internal/render"
+	"lemma/internal/storage"
+)
+
+// renderMarkdown renders content with the configured renderer or the default allowlist
+func (h *Handler) renderMarkdown(content []byte) ([]byte, error) {
+	if h.Renderer == nil {
+		return render.RenderMarkdown(content)
+	}
+	return h.Renderer.RenderMarkdown(content)
+}
+
+// RenderFile godoc
+// @Summary Render a markdown file
+// @Description Returns the markdown file rendered to HTML for clients without a markdown renderer.
+// @Description CommonMark with GFM tables, task lists, strikethrough and autolinks is supported.
+// @Description The HTML is sanitized, elements outside the configured allowlist and unsafe attributes and URLs are removed.
+// @Description If the workspace resolves includes, {{include: path}} directives are expanded before rendering.
+// @Tags files
+// @ID renderFile
+// @Security CookieAuth
+// @Produce html
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "File path"
+// @Success 200 {string} string "Rendered HTML"
+// @Failure 400 {object} ErrorResponse "file_path is required"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 400 {object} ErrorResponse "File is not a text file"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 422 {object} ErrorResponse "Too much included content"
+// @Failure 500 {object} ErrorResponse "Failed to read file"
+// @Failure 500 {object} ErrorResponse "Failed to render file"
+// @Router /workspaces/{workspace_name}/files/render [get]
+func (h *Handler) RenderFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "RenderFile",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("file_path")
+		if filePath == "" {
+			log.Debug("missing file_path parameter")
+			respondError(w, "file_path is required", http.StatusBadRequest)
+			return
+		}
+
+		// URL-decode the file path
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil {
+			log.Error("failed to decode file path",
+				"filePath", filePath,
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		// Includes are always resolved for rendering if the workspace enables them
+		var content []byte
+		if ctx.Workspace.ResolveIncludes {
+			content, err = h.Storage.ResolveIncludes(ctx.UserID, ctx.Workspace.ID, decodedPath)
+		} else {
+			content, err = h.Storage.GetFileContent(ctx.UserID, ctx.Workspace.ID, decodedPath)
+		}
+		if err != nil {
+			if errors.Is(err, storage.ErrBinaryFile) {
+				log.Debug("render requested for binary file",
+					"filePath", decodedPath,
+				)
+				respondError(w, "File is not a text file", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, storage.ErrIncludeLimit) {
+				log.Debug("included content exceeds the limit",
+					"filePath", decodedPath,
+				)
+				respondError(w, "Too much included content", http.StatusUnprocessableEntity)
+				return
+			}
+
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				log.Debug("file not found",
+					"filePath", decodedPath,
+				)
+				respondError(w, "File not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("failed to read file content",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to read file", http.StatusInternalServerError)
+			return
+		}
+
+		html, err := h.renderMarkdown(content)
+		if err != nil {
+			if errors.Is(err, render.ErrBinaryContent) {
+				log.Debug("render requested for binary file",
+					"filePath", decodedPath,
+				)
+				respondError(w, "File is not a text file", http.StatusBadRequest)
+				return
+			}
+
+			log.Error("failed to render file",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to render file", http.StatusInternalServerError)
+			return
+		}
+
+		h.recordFileAccess(r, ctx, models.FileAccessRead, decodedPath)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if _, err := w.Write(html); err != nil {
+			log.Error("failed to write response",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SkipLogging wraps the request logging middleware logger so that requests to paths starting with
+// one of the excluded prefixes, e.g. health checks and metrics scrapes, are served without an access log entry.
+func SkipLogging(logger func(http.Handler) http.Handler, excludedPrefixes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		logged := logger(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range excludedPrefixes {
+				if prefix != "" && strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			logged.ServeHTTP(w, r)
+		})
+	}
+}
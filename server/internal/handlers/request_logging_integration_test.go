@@ -0,0 +1,59 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"lemma/internal/handlers"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := middleware.RequestLogger(&middleware.DefaultLogFormatter{
+		Logger:  log.New(&buf, "", 0),
+		NoColor: true,
+	})
+
+	r := chi.NewRouter()
+	r.Use(handlers.SkipLogging(logger, "/healthz", "/readyz", "/metrics"))
+	r.Get("/*", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	testCases := []struct {
+		path   string
+		logged bool
+	}{
+		{path: "/healthz", logged: false},
+		{path: "/readyz", logged: false},
+		{path: "/metrics", logged: false},
+		{path: "/metrics/process", logged: false},
+		{path: "/api/v1/workspaces", logged: true},
+		{path: "/", logged: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			buf.Reset()
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			// Excluded requests are still served
+			assert.Equal(t, http.StatusOK, rr.Code)
+			if tc.logged {
+				assert.Contains(t, buf.String(), tc.path)
+			} else {
+				assert.Empty(t, buf.String())
+			}
+		})
+	}
+}
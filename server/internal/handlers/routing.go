@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Error codes of responses to requests that match no route
+const (
+	ErrorCodeNotFound         = "not_found"
+	ErrorCodeMethodNotAllowed = "method_not_allowed"
+)
+
+// methodOrder is the order methods are listed in the Allow header
+var methodOrder = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodConnect,
+	http.MethodTrace,
+}
+
+// NotFound returns a handler responding with a JSON error to requests for unknown paths
+func NotFound() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		respondJSON(w, ErrorResponse{Message: "Not found", Code: ErrorCodeNotFound})
+	}
+}
+
+// MethodNotAllowed is a middleware turning the router's empty 405 responses into JSON errors.
+// The router knows which methods the routes for a path support and already sets them as Allow
+// headers in no particular order, they are combined into a single sorted header here.
+func MethodNotAllowed(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&methodNotAllowedWriter{ResponseWriter: w}, r)
+	})
+}
+
+// methodNotAllowedWriter replaces the body of 405 responses that have no content type yet
+type methodNotAllowedWriter struct {
+	http.ResponseWriter
+	replaced bool
+}
+
+func (w *methodNotAllowedWriter) WriteHeader(status int) {
+	header := w.Header()
+	if status != http.StatusMethodNotAllowed || header.Get("Content-Type") != "" {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	w.replaced = true
+	allowed := slices.Clone(header.Values("Allow"))
+	slices.SortFunc(allowed, func(a, b string) int {
+		return slices.Index(methodOrder, a) - slices.Index(methodOrder, b)
+	})
+	header.Set("Allow", strings.Join(allowed, ", "))
+	header.Set("Content-Type", "application/json")
+	w.ResponseWriter.WriteHeader(status)
+	_ = json.NewEncoder(w.ResponseWriter).Encode(ErrorResponse{
+		Message: "Method not allowed",
+		Code:    ErrorCodeMethodNotAllowed,
+	})
+}
+
+func (w *methodNotAllowedWriter) Write(b []byte) (int, error) {
+	if w.replaced {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush keeps streaming responses working behind the middleware
+func (w *methodNotAllowedWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack keeps WebSocket upgrades working behind the middleware
+func (w *methodNotAllowedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap gives http.ResponseController access to the wrapped writer
+func (w *methodNotAllowedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,82 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouting_Integration(t *testing.T) {
+	runWithDatabases(t, testRouting)
+}
+
+func testRouting(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarness(t, dbConfig)
+	defer h.teardown(t)
+
+	decodeError := func(t *testing.T, body []byte) handlers.ErrorResponse {
+		t.Helper()
+		var resp handlers.ErrorResponse
+		require.NoError(t, json.Unmarshal(body, &resp))
+		return resp
+	}
+
+	workspace := &models.Workspace{Name: "Routing Workspace"}
+	rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, h.AdminTestUser)
+	require.Equal(t, http.StatusOK, rr.Code)
+	workspaceURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name)
+
+	t.Run("method not allowed", func(t *testing.T) {
+		testCases := []struct {
+			name   string
+			method string
+			path   string
+			allow  string
+		}{
+			{name: "public route", method: http.MethodGet, path: "/api/v1/auth/login", allow: "POST"},
+			{name: "protected route", method: http.MethodPost, path: "/api/v1/auth/me", allow: "GET"},
+			{name: "several methods", method: http.MethodPatch, path: workspaceURL + "/files", allow: "GET, POST, DELETE"},
+			{name: "admin route", method: http.MethodDelete, path: "/api/v1/admin/stats", allow: "GET"},
+			{name: "static route", method: http.MethodPost, path: "/some/page", allow: "GET, HEAD"},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				rr := h.makeRequest(t, tc.method, tc.path, nil, h.AdminTestUser)
+				require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+				assert.Equal(t, tc.allow, rr.Header().Get("Allow"))
+				assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+				resp := decodeError(t, rr.Body.Bytes())
+				assert.Equal(t, handlers.ErrorCodeMethodNotAllowed, resp.Code)
+				assert.NotEmpty(t, resp.Message)
+			})
+		}
+	})
+
+	t.Run("unknown API path", func(t *testing.T) {
+		for _, path := range []string{"/api/v1/unknown", "/api/v1/admin/unknown", workspaceURL + "/unknown"} {
+			rr := h.makeRequest(t, http.MethodGet, path, nil, h.AdminTestUser)
+			require.Equal(t, http.StatusNotFound, rr.Code, path)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+			resp := decodeError(t, rr.Body.Bytes())
+			assert.Equal(t, handlers.ErrorCodeNotFound, resp.Code)
+		}
+	})
+
+	t.Run("other errors have no code", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/admin/users/99999", nil, h.AdminTestUser)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		assert.NotContains(t, rr.Body.String(), `"code"`)
+	})
+}
@@ -0,0 +1,67 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"lemma/internal/app"
+	"lemma/internal/handlers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecureCookiesOnly_Integration(t *testing.T) {
+	runWithDatabases(t, testSecureCookiesOnly)
+}
+
+func testSecureCookiesOnly(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+		config.IsDevelopment = false
+		config.SecureCookiesOnly = true
+		config.HSTSMaxAge = 365 * 24 * time.Hour
+		config.HSTSPreload = true
+	})
+	defer h.teardown(t)
+
+	login := func(t *testing.T, headers map[string]string) *http.Response {
+		t.Helper()
+		body, err := json.Marshal(handlers.LoginRequest{
+			Email:    "admin@test.com",
+			Password: "admin123",
+		})
+		require.NoError(t, err)
+
+		headers["Content-Type"] = "application/json"
+		return h.makeRequestRaw(t, http.MethodPost, "/api/v1/auth/login", bytes.NewReader(body), nil, headers).Result()
+	}
+
+	t.Run("plain HTTP is refused", func(t *testing.T) {
+		resp := login(t, map[string]string{})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Empty(t, resp.Cookies())
+		assert.Empty(t, resp.Header.Get("Strict-Transport-Security"))
+	})
+
+	t.Run("HTTPS behind a proxy", func(t *testing.T) {
+		resp := login(t, map[string]string{"X-Forwarded-Proto": "https"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		require.NotEmpty(t, resp.Cookies())
+		for _, cookie := range resp.Cookies() {
+			assert.True(t, cookie.Secure, "%s cookie must be Secure", cookie.Name)
+		}
+		assert.Equal(t, "max-age=31536000; includeSubDomains; preload", resp.Header.Get("Strict-Transport-Security"))
+	})
+
+	t.Run("refresh over plain HTTP is refused", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodPost, "/api/v1/auth/refresh", nil, h.RegularTestUser)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Empty(t, rr.Result().Cookies())
+	})
+}
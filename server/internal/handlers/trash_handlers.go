@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"lemma/internal/context"
+	"lemma/internal/models"
+	"lemma/internal/storage"
+)
+
+// TrashResponse represents a response to a list trash request
+type TrashResponse struct {
+	Items []storage.TrashItem `json:"items"`
+}
+
+// RestoreTrashedFileResponse represents a response to a restore from trash request
+type RestoreTrashedFileResponse struct {
+	Path string `json:"path"`
+}
+
+// PurgeTrashResponse represents a response to a purge trash request
+type PurgeTrashResponse struct {
+	Purged int `json:"purged"`
+}
+
+// ListTrash godoc
+// @Summary List trash
+// @Description Returns the deleted files and directories of the workspace, most recently deleted first
+// @Tags files
+// @ID listTrash
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Success 200 {object} TrashResponse
+// @Failure 500 {object} ErrorResponse "Failed to list trash"
+// @Router /workspaces/{workspace_name}/trash [get]
+func (h *Handler) ListTrash() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "ListTrash",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		items, err := h.Storage.ListTrash(ctx.UserID, ctx.Workspace.ID)
+		if err != nil {
+			log.Error("failed to list trash",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to list trash", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, TrashResponse{Items: items})
+	}
+}
+
+// RestoreTrashedFile godoc
+// @Summary Restore a file from the trash
+// @Description Restores the most recently deleted copy of a path to its original location.
+// @Description If a file exists there again, the copy is restored next to it with a numeric suffix, e.g. "note (1).md".
+// @Tags files
+// @ID restoreTrashedFile
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param path query string true "Original path of the deleted file"
+// @Success 200 {object} RestoreTrashedFileResponse
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 404 {object} ErrorResponse "File not found in trash"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
+// @Failure 500 {object} ErrorResponse "Failed to restore file"
+// @Router /workspaces/{workspace_name}/trash/restore [post]
+func (h *Handler) RestoreTrashedFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "RestoreTrashedFile",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("path")
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil || decodedPath == "" {
+			log.Debug("invalid file path",
+				"filePath", filePath,
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		restoredPath, err := h.Storage.RestoreTrashedFile(ctx.UserID, ctx.Workspace.ID, decodedPath)
+		if err != nil {
+			if storage.IsWorkspacePinnedError(err) {
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
+				return
+			}
+
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				log.Debug("file not found in trash",
+					"filePath", decodedPath,
+				)
+				respondError(w, "File not found in trash", http.StatusNotFound)
+				return
+			}
+
+			log.Error("failed to restore file from trash",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to restore file", http.StatusInternalServerError)
+			return
+		}
+
+		h.recordActivity(&models.Activity{
+			WorkspaceID: ctx.Workspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityFileSaved,
+			Path:        restoredPath,
+		})
+
+		respondJSON(w, RestoreTrashedFileResponse{Path: restoredPath})
+	}
+}
+
+// DeleteTrashedFile godoc
+// @Summary Delete a file from the trash
+// @Description Permanently removes all deleted copies of a path from the trash
+// @Tags files
+// @ID deleteTrashedFile
+// @Security CookieAuth
+// @Param workspace_name path string true "Workspace name"
+// @Param path query string true "Original path of the deleted file"
+// @Success 204 "No Content - File removed from trash"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 404 {object} ErrorResponse "File not found in trash"
+// @Failure 500 {object} ErrorResponse "Failed to delete file from trash"
+// @Router /workspaces/{workspace_name}/trash [delete]
+func (h *Handler) DeleteTrashedFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "DeleteTrashedFile",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("path")
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil || decodedPath == "" {
+			log.Debug("invalid file path",
+				"filePath", filePath,
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		err = h.Storage.DeleteTrashedFile(ctx.UserID, ctx.Workspace.ID, decodedPath)
+		if err != nil {
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				respondError(w, "File not found in trash", http.StatusNotFound)
+				return
+			}
+
+			log.Error("failed to delete file from trash",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to delete file from trash", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// PurgeTrash godoc
+// @Summary Purge the trash
+// @Description Permanently removes the files deleted before the given time from the trash of the workspace, all of them by default.
+// @Description Files older than the configured trash retention are also purged automatically.
+// @Tags files
+// @ID purgeTrash
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param before query string false "RFC 3339 timestamp, only files deleted before it are purged"
+// @Success 200 {object} PurgeTrashResponse
+// @Failure 400 {object} ErrorResponse "Invalid before timestamp"
+// @Failure 500 {object} ErrorResponse "Failed to purge trash"
+// @Router /workspaces/{workspace_name}/trash/purge [post]
+func (h *Handler) PurgeTrash() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "PurgeTrash",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		cutoff := time.Now()
+		if before := r.URL.Query().Get("before"); before != "" {
+			parsed, err := time.Parse(time.RFC3339, before)
+			if err != nil {
+				log.Debug("invalid before parameter",
+					"before", before,
+				)
+				respondError(w, "Invalid before timestamp", http.StatusBadRequest)
+				return
+			}
+			cutoff = parsed
+		}
+
+		purged, err := h.Storage.PurgeWorkspaceTrash(ctx.UserID, ctx.Workspace.ID, cutoff)
+		if err != nil {
+			log.Error("failed to purge trash",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to purge trash", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, PurgeTrashResponse{Purged: purged})
+	}
+}
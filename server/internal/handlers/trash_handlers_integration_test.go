@@ -0,0 +1,164 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrash_Integration(t *testing.T) {
+	runWithDatabases(t, testTrash)
+}
+
+func testTrash(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarness(t, dbConfig)
+	defer h.teardown(t)
+
+	user := h.createTestUser(t, "trash@test.com", "password123", models.RoleEditor)
+
+	workspace := &models.Workspace{Name: "Trash Workspace"}
+	rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, user)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	workspaceURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name)
+	notePath := "notes/note.md"
+
+	saveFile := func(t *testing.T, path, content string) {
+		t.Helper()
+		rr := h.makeRequestRaw(t, http.MethodPost, workspaceURL+"/files?file_path="+url.QueryEscape(path), strings.NewReader(content), user)
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+	getContent := func(t *testing.T, path string) (int, string) {
+		t.Helper()
+		rr := h.makeRequest(t, http.MethodGet, workspaceURL+"/files/content?file_path="+url.QueryEscape(path), nil, user)
+		return rr.Code, rr.Body.String()
+	}
+	deleteFile := func(t *testing.T, path string) {
+		t.Helper()
+		rr := h.makeRequest(t, http.MethodDelete, workspaceURL+"/files?file_path="+url.QueryEscape(path), nil, user)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+	}
+	restore := func(t *testing.T, path string) (int, string) {
+		t.Helper()
+		rr := h.makeRequest(t, http.MethodPost, workspaceURL+"/trash/restore?path="+url.QueryEscape(path), nil, user)
+		var response handlers.RestoreTrashedFileResponse
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		}
+		return rr.Code, response.Path
+	}
+
+	t.Run("deleted file is listed in trash", func(t *testing.T) {
+		saveFile(t, notePath, "original")
+		deleteFile(t, notePath)
+
+		code, _ := getContent(t, notePath)
+		assert.Equal(t, http.StatusNotFound, code)
+
+		rr := h.makeRequest(t, http.MethodGet, workspaceURL+"/trash", nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response handlers.TrashResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		require.Len(t, response.Items, 1)
+		assert.Equal(t, notePath, response.Items[0].Path)
+		assert.False(t, response.Items[0].DeletedAt.IsZero())
+
+		rr = h.makeRequest(t, http.MethodGet, workspaceURL+"/files", nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "note.md")
+	})
+
+	t.Run("restore to original path", func(t *testing.T) {
+		code, restored := restore(t, notePath)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, notePath, restored)
+
+		code, content := getContent(t, notePath)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "original", content)
+	})
+
+	t.Run("restore with name collision", func(t *testing.T) {
+		deleteFile(t, notePath)
+		saveFile(t, notePath, "replacement")
+
+		code, restored := restore(t, notePath)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "notes/note (1).md", restored)
+
+		_, content := getContent(t, restored)
+		assert.Equal(t, "original", content)
+		_, content = getContent(t, notePath)
+		assert.Equal(t, "replacement", content)
+	})
+
+	t.Run("delete permanently", func(t *testing.T) {
+		deleteFile(t, notePath)
+
+		rr := h.makeRequest(t, http.MethodDelete, workspaceURL+"/trash?path="+url.QueryEscape(notePath), nil, user)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		code, _ := restore(t, notePath)
+		assert.Equal(t, http.StatusNotFound, code)
+
+		rr = h.makeRequest(t, http.MethodDelete, workspaceURL+"/trash?path="+url.QueryEscape(notePath), nil, user)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		purge := func(t *testing.T, query string) int {
+			t.Helper()
+			rr := h.makeRequest(t, http.MethodPost, workspaceURL+"/trash/purge"+query, nil, user)
+			require.Equal(t, http.StatusOK, rr.Code)
+			var response handlers.PurgeTrashResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			return response.Purged
+		}
+		listTrash := func(t *testing.T) []string {
+			t.Helper()
+			rr := h.makeRequest(t, http.MethodGet, workspaceURL+"/trash", nil, user)
+			require.Equal(t, http.StatusOK, rr.Code)
+			var response handlers.TrashResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			paths := []string{}
+			for _, item := range response.Items {
+				paths = append(paths, item.Path)
+			}
+			return paths
+		}
+
+		saveFile(t, "old.md", "old")
+		deleteFile(t, "old.md")
+		cutoff := time.Now()
+		saveFile(t, "new.md", "new")
+		deleteFile(t, "new.md")
+
+		assert.Equal(t, 1, purge(t, "?before="+url.QueryEscape(cutoff.Format(time.RFC3339Nano))))
+		assert.Equal(t, []string{"new.md"}, listTrash(t))
+
+		assert.Equal(t, 1, purge(t, ""))
+		assert.Empty(t, listTrash(t))
+
+		rr := h.makeRequest(t, http.MethodPost, workspaceURL+"/trash/purge?before=yesterday", nil, user)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		code, _ := restore(t, "../../etc/passwd")
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = restore(t, "")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
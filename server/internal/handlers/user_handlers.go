@@ -2,21 +2,27 @@ package handlers
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 
+	"lemma/internal/auth"
 	"lemma/internal/context"
 	"lemma/internal/logging"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
-// UpdateProfileRequest represents a user profile update request
+// UpdateProfileRequest represents a user profile update request.
+// Omitted or null fields are left unchanged, an empty display name clears it.
 type UpdateProfileRequest struct {
-	DisplayName     string `json:"displayName"`
-	Email           string `json:"email"`
-	CurrentPassword string `json:"currentPassword"`
-	NewPassword     string `json:"newPassword"`
-	Theme           string `json:"theme"`
+	DisplayName     *string `json:"displayName,omitempty"`
+	Email           *string `json:"email,omitempty"`
+	CurrentPassword string  `json:"currentPassword"`
+	NewPassword     string  `json:"newPassword"`
+	Theme           *string `json:"theme,omitempty"`
+	// LogoutEverywhere invalidates all sessions of the user, including the current one, when the password is changed
+	LogoutEverywhere bool `json:"logoutEverywhere"`
 }
 
 // DeleteAccountRequest represents a user account deletion request
@@ -24,13 +30,18 @@ type DeleteAccountRequest struct {
 	Password string `json:"password"`
 }
 
+// maxPreferencesSize is the maximum size in bytes of the stored UI preferences
+const maxPreferencesSize = 64 * 1024
+
 func getProfileLogger() logging.Logger {
 	return getHandlersLogger().WithGroup("profile")
 }
 
 // UpdateProfile godoc
 // @Summary Update profile
-// @Description Updates the user's profile
+// @Description Updates the user's profile. Omitted or null fields are left unchanged, an empty display name clears it.
+// @Description When the password is changed with logoutEverywhere set,
+// @Description all sessions of the user are invalidated and the auth cookies are cleared.
 // @Tags users
 // @ID updateProfile
 // @Security CookieAuth
@@ -42,13 +53,17 @@ func getProfileLogger() logging.Logger {
 // @Failure 400 {object} ErrorResponse "Current password is required to change password"
 // @Failure 400 {object} ErrorResponse "New password must be at least 8 characters long"
 // @Failure 400 {object} ErrorResponse "Current password is required to change email"
+// @Failure 400 {object} ErrorResponse "Email cannot be empty"
 // @Failure 401 {object} ErrorResponse "Current password is incorrect"
 // @Failure 404 {object} ErrorResponse "User not found"
 // @Failure 409 {object} ErrorResponse "Email already in use"
+// @Failure 409 {object} ErrorResponse "Display name already in use"
 // @Failure 500 {object} ErrorResponse "Failed to process new password"
+// @Failure 500 {object} ErrorResponse "Failed to check display name"
 // @Failure 500 {object} ErrorResponse "Failed to update profile"
+// @Failure 500 {object} ErrorResponse "Failed to invalidate sessions"
 // @Router /profile [put]
-func (h *Handler) UpdateProfile() http.HandlerFunc {
+func (h *Handler) UpdateProfile(authManager auth.SessionManager, cookieService auth.CookieManager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, ok := context.GetRequestContext(w, r)
 		if !ok {
@@ -117,7 +132,12 @@ func (h *Handler) UpdateProfile() http.HandlerFunc {
 		}
 
 		// Handle email update if requested
-		if req.Email != "" && req.Email != user.Email {
+		if req.Email != nil && *req.Email != user.Email {
+			if *req.Email == "" {
+				log.Debug("email change rejected - empty email")
+				respondError(w, "Email cannot be empty", http.StatusBadRequest)
+				return
+			}
 			if req.CurrentPassword == "" {
 				log.Warn("attempted email change without current password")
 				respondError(w, "Current password is required to change email", http.StatusBadRequest)
@@ -132,34 +152,53 @@ func (h *Handler) UpdateProfile() http.HandlerFunc {
 				}
 			}
 
-			existingUser, err := h.DB.GetUserByEmail(req.Email)
+			existingUser, err := h.DB.GetUserByEmail(*req.Email)
 			if err == nil && existingUser.ID != user.ID {
 				log.Debug("email change rejected - already in use",
-					"requestedEmail", req.Email,
+					"requestedEmail", *req.Email,
 				)
 				respondError(w, "Email already in use", http.StatusConflict)
 				return
 			}
-			user.Email = req.Email
+			user.Email = *req.Email
 			updates["emailChanged"] = true
 		}
 
-		// Update display name if provided
-		if req.DisplayName != "" {
-			user.DisplayName = req.DisplayName
+		// Update display name if provided, an empty display name clears it
+		if req.DisplayName != nil {
+			displayName := *req.DisplayName
+			if displayName != "" && !strings.EqualFold(displayName, user.DisplayName) {
+				taken, err := h.displayNameTaken(displayName, user.ID)
+				if err != nil {
+					log.Error("failed to check display name",
+						"error", err.Error(),
+					)
+					respondError(w, "Failed to check display name", http.StatusInternalServerError)
+					return
+				}
+				if taken {
+					log.Debug("display name change rejected - already in use",
+						"requestedDisplayName", displayName,
+					)
+					respondError(w, "Display name already in use", http.StatusConflict)
+					return
+				}
+			}
+			user.DisplayName = displayName
 			updates["displayNameChanged"] = true
 		}
 
 		// Update theme if provided
-		if req.Theme != "" {
+		if req.Theme != nil {
+			theme := *req.Theme
 			// Validate theme value, fallback to "dark" if invalid
-			if req.Theme != "light" && req.Theme != "dark" {
+			if theme != "light" && theme != "dark" {
 				log.Debug("invalid theme value, falling back to dark",
-					"theme", req.Theme,
+					"theme", theme,
 				)
-				req.Theme = "dark"
+				theme = "dark"
 			}
-			user.Theme = req.Theme
+			user.Theme = theme
 			updates["themeChanged"] = true
 		}
 
@@ -173,10 +212,39 @@ func (h *Handler) UpdateProfile() http.HandlerFunc {
 			return
 		}
 
+		// Stolen tokens stop working once the password is changed
+		if updates["passwordChanged"] && req.LogoutEverywhere {
+			if err := authManager.InvalidateAllUserSessions(user.ID); err != nil {
+				log.Error("failed to invalidate sessions after password change",
+					"error", err.Error(),
+				)
+				respondError(w, "Failed to invalidate sessions", http.StatusInternalServerError)
+				return
+			}
+			invalidateAuthCookies(w, cookieService)
+			log.Info("invalidated all sessions after password change")
+		}
+
 		respondJSON(w, user)
 	}
 }
 
+// displayNameTaken reports whether unique display names are enforced
+// and displayName is used by a user other than userID
+func (h *Handler) displayNameTaken(displayName string, userID int) (bool, error) {
+	if !h.UniqueDisplayNames {
+		return false, nil
+	}
+	existingUser, err := h.DB.GetUserByDisplayName(displayName)
+	if err != nil {
+		if strings.Contains(err.Error(), "user not found") {
+			return false, nil
+		}
+		return false, err
+	}
+	return existingUser.ID != userID, nil
+}
+
 // DeleteAccount godoc
 // @Summary Delete account
 // @Description Deletes the user's account and all associated data
@@ -290,3 +358,102 @@ func (h *Handler) DeleteAccount() http.HandlerFunc {
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
+
+// GetPreferences godoc
+// @Summary Get preferences
+// @Description Returns the user's UI preferences as stored by the client, or an empty object if none are stored
+// @Tags users
+// @ID getPreferences
+// @Security CookieAuth
+// @Produce json
+// @Success 200 {object} object "Stored preferences"
+// @Failure 500 {object} ErrorResponse "Failed to get preferences"
+// @Router /profile/preferences [get]
+func (h *Handler) GetPreferences() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getProfileLogger().With(
+			"handler", "GetPreferences",
+			"userID", ctx.UserID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		preferences, err := h.DB.GetUserPreferences(ctx.UserID)
+		if err != nil {
+			log.Error("failed to fetch preferences from database",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to get preferences", http.StatusInternalServerError)
+			return
+		}
+
+		if preferences == "" {
+			preferences = "{}"
+		}
+		respondJSON(w, json.RawMessage(preferences))
+	}
+}
+
+// UpdatePreferences godoc
+// @Summary Update preferences
+// @Description Replaces the user's UI preferences with the given JSON document.
+// @Description The document is stored as is and must not exceed 64 KiB.
+// @Tags users
+// @ID updatePreferences
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param body body object true "Preferences"
+// @Success 200 {object} object "Stored preferences"
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 400 {object} ErrorResponse "Preferences must be valid JSON"
+// @Failure 413 {object} ErrorResponse "Preferences too large"
+// @Failure 500 {object} ErrorResponse "Failed to update preferences"
+// @Router /profile/preferences [put]
+func (h *Handler) UpdatePreferences() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getProfileLogger().With(
+			"handler", "UpdatePreferences",
+			"userID", ctx.UserID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxPreferencesSize+1))
+		if err != nil {
+			log.Debug("failed to read request body",
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if len(body) > maxPreferencesSize {
+			log.Debug("preferences too large")
+			respondError(w, "Preferences too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		if !json.Valid(body) {
+			log.Debug("invalid preferences JSON")
+			respondError(w, "Preferences must be valid JSON", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.DB.UpdateUserPreferences(ctx.UserID, string(body)); err != nil {
+			log.Error("failed to update preferences in database",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to update preferences", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, json.RawMessage(body))
+	}
+}
@@ -5,6 +5,7 @@ package handlers_test
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 
 	"lemma/internal/handlers"
@@ -49,8 +50,9 @@ func testUserHandlers(t *testing.T, dbConfig DatabaseConfig) {
 
 	t.Run("update profile", func(t *testing.T) {
 		t.Run("update display name only", func(t *testing.T) {
+			displayName := "Updated Name"
 			updateReq := handlers.UpdateProfileRequest{
-				DisplayName: "Updated Name",
+				DisplayName: &displayName,
 			}
 
 			rr := h.makeRequest(t, http.MethodPut, "/api/v1/profile", updateReq, h.RegularTestUser)
@@ -59,12 +61,60 @@ func testUserHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			var user models.User
 			err := json.NewDecoder(rr.Body).Decode(&user)
 			require.NoError(t, err)
-			assert.Equal(t, updateReq.DisplayName, user.DisplayName)
+			assert.Equal(t, displayName, user.DisplayName)
+		})
+
+		t.Run("partial updates", func(t *testing.T) {
+			update := func(t *testing.T, body string) models.User {
+				t.Helper()
+				rr := h.makeRequestRaw(t, http.MethodPut, "/api/v1/profile", strings.NewReader(body), h.RegularTestUser)
+				require.Equal(t, http.StatusOK, rr.Code)
+
+				var user models.User
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&user))
+				return user
+			}
+
+			user := update(t, `{"displayName":"Partial Name","theme":"light"}`)
+			assert.Equal(t, "Partial Name", user.DisplayName)
+			assert.Equal(t, "light", user.Theme)
+
+			// Omitted and null fields are left unchanged
+			user = update(t, `{"theme":"dark"}`)
+			assert.Equal(t, "Partial Name", user.DisplayName)
+			assert.Equal(t, "dark", user.Theme)
+			user = update(t, `{"displayName":null,"theme":null}`)
+			assert.Equal(t, "Partial Name", user.DisplayName)
+			assert.Equal(t, "dark", user.Theme)
+			assert.Equal(t, currentEmail, user.Email)
+
+			// An empty display name clears it
+			user = update(t, `{"displayName":""}`)
+			assert.Empty(t, user.DisplayName)
+			assert.Equal(t, "dark", user.Theme)
+
+			rr := h.makeRequest(t, http.MethodGet, "/api/v1/auth/me", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&user))
+			assert.Empty(t, user.DisplayName, "cleared display name should be stored")
+		})
+
+		t.Run("clear email", func(t *testing.T) {
+			email := ""
+			updateReq := handlers.UpdateProfileRequest{
+				Email:           &email,
+				CurrentPassword: currentPassword,
+			}
+
+			rr := h.makeRequest(t, http.MethodPut, "/api/v1/profile", updateReq, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Contains(t, rr.Body.String(), "Email cannot be empty")
 		})
 
 		t.Run("update email", func(t *testing.T) {
+			email := "newemail@test.com"
 			updateReq := handlers.UpdateProfileRequest{
-				Email:           "newemail@test.com",
+				Email:           &email,
 				CurrentPassword: currentPassword,
 			}
 
@@ -74,14 +124,15 @@ func testUserHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			var user models.User
 			err := json.NewDecoder(rr.Body).Decode(&user)
 			require.NoError(t, err)
-			assert.Equal(t, updateReq.Email, user.Email)
+			assert.Equal(t, email, user.Email)
 
-			currentEmail = updateReq.Email
+			currentEmail = email
 		})
 
 		t.Run("update email without password", func(t *testing.T) {
+			email := "anotheremail@test.com"
 			updateReq := handlers.UpdateProfileRequest{
-				Email: "anotheremail@test.com",
+				Email: &email,
 			}
 
 			rr := h.makeRequest(t, http.MethodPut, "/api/v1/profile", updateReq, h.RegularTestUser)
@@ -89,8 +140,9 @@ func testUserHandlers(t *testing.T, dbConfig DatabaseConfig) {
 		})
 
 		t.Run("update email with wrong password", func(t *testing.T) {
+			email := "wrongpass@test.com"
 			updateReq := handlers.UpdateProfileRequest{
-				Email:           "wrongpass@test.com",
+				Email:           &email,
 				CurrentPassword: "wrongpassword",
 			}
 
@@ -116,6 +168,10 @@ func testUserHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			rr = h.makeRequest(t, http.MethodPost, "/api/v1/auth/login", loginReq, nil)
 			assert.Equal(t, http.StatusOK, rr.Code)
 
+			// Sessions are kept unless requested otherwise
+			rr = h.makeRequest(t, http.MethodGet, "/api/v1/auth/me", nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusOK, rr.Code)
+
 			currentPassword = updateReq.NewPassword
 		})
 
@@ -150,13 +206,81 @@ func testUserHandlers(t *testing.T, dbConfig DatabaseConfig) {
 
 		t.Run("duplicate email", func(t *testing.T) {
 			updateReq := handlers.UpdateProfileRequest{
-				Email:           h.AdminTestUser.userModel.Email,
+				Email:           &h.AdminTestUser.userModel.Email,
 				CurrentPassword: currentPassword,
 			}
 
 			rr := h.makeRequest(t, http.MethodPut, "/api/v1/profile", updateReq, h.RegularTestUser)
 			assert.Equal(t, http.StatusConflict, rr.Code)
 		})
+
+		t.Run("update password and logout everywhere", func(t *testing.T) {
+			user := h.createTestUser(t, "logouteverywhere@test.com", "password123", models.RoleEditor)
+			otherSession, otherToken, err := h.SessionManager.CreateSession(user.userModel.ID, string(user.userModel.Role))
+			require.NoError(t, err)
+			other := &testUser{userModel: user.userModel, accessToken: otherToken, session: otherSession}
+
+			updateReq := handlers.UpdateProfileRequest{
+				CurrentPassword:  "password123",
+				NewPassword:      "newpassword123",
+				LogoutEverywhere: true,
+			}
+			rr := h.makeRequest(t, http.MethodPut, "/api/v1/profile", updateReq, user)
+			require.Equal(t, http.StatusOK, rr.Code)
+			for _, cookie := range rr.Result().Cookies() {
+				assert.True(t, cookie.MaxAge < 0, "cookie should be invalidated")
+			}
+
+			for _, session := range []*testUser{user, other} {
+				rr = h.makeRequest(t, http.MethodGet, "/api/v1/auth/me", nil, session)
+				assert.Equal(t, http.StatusUnauthorized, rr.Code)
+			}
+		})
+	})
+
+	t.Run("preferences", func(t *testing.T) {
+		t.Run("empty by default", func(t *testing.T) {
+			rr := h.makeRequest(t, http.MethodGet, "/api/v1/profile/preferences", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, `{}`, rr.Body.String())
+		})
+
+		t.Run("round trip", func(t *testing.T) {
+			preferences := `{"sidebarWidth":280,"editor":{"fontSize":14,"vimMode":true}}`
+			rr := h.makeRequestRaw(t, http.MethodPut, "/api/v1/profile/preferences", strings.NewReader(preferences), h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, preferences, rr.Body.String())
+
+			rr = h.makeRequest(t, http.MethodGet, "/api/v1/profile/preferences", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, preferences, rr.Body.String())
+
+			// Preferences are per user
+			rr = h.makeRequest(t, http.MethodGet, "/api/v1/profile/preferences", nil, h.AdminTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, `{}`, rr.Body.String())
+		})
+
+		t.Run("invalid JSON", func(t *testing.T) {
+			rr := h.makeRequestRaw(t, http.MethodPut, "/api/v1/profile/preferences", strings.NewReader(`{"sidebarWidth":`), h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+
+		t.Run("oversized", func(t *testing.T) {
+			preferences := `{"data":"` + strings.Repeat("x", 64*1024) + `"}`
+			rr := h.makeRequestRaw(t, http.MethodPut, "/api/v1/profile/preferences", strings.NewReader(preferences), h.RegularTestUser)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+
+			// Stored preferences are unchanged
+			rr = h.makeRequest(t, http.MethodGet, "/api/v1/profile/preferences", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.NotContains(t, rr.Body.String(), "xxxx")
+		})
+
+		t.Run("unauthorized", func(t *testing.T) {
+			rr := h.makeRequest(t, http.MethodGet, "/api/v1/profile/preferences", nil, nil)
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		})
 	})
 
 	t.Run("delete account", func(t *testing.T) {
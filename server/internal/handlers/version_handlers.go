@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"lemma/internal/context"
+	"lemma/internal/models"
+	"lemma/internal/storage"
+)
+
+// FileVersionsResponse represents a response to a file versions request
+type FileVersionsResponse struct {
+	Versions []storage.FileVersion `json:"versions"`
+}
+
+// ListFileVersions godoc
+// @Summary List file versions
+// @Description Returns the previous versions of a file kept when it was saved, oldest first.
+// @Description The number of versions kept per file is limited by the server configuration.
+// @Tags files
+// @ID listFileVersions
+// @Security CookieAuth
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "File path"
+// @Success 200 {object} FileVersionsResponse
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 500 {object} ErrorResponse "Failed to list file versions"
+// @Router /workspaces/{workspace_name}/files/versions [get]
+func (h *Handler) ListFileVersions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "ListFileVersions",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("file_path")
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil || decodedPath == "" {
+			log.Debug("invalid file path",
+				"filePath", filePath,
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+
+		versions, err := h.Storage.ListFileVersions(ctx.UserID, ctx.Workspace.ID, decodedPath)
+		if err != nil {
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			log.Error("failed to list file versions",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to list file versions", http.StatusInternalServerError)
+			return
+		}
+
+		respondJSON(w, FileVersionsResponse{Versions: versions})
+	}
+}
+
+// GetFileVersionContent godoc
+// @Summary Get file version content
+// @Description Returns the content of a previous version of a file
+// @Tags files
+// @ID getFileVersionContent
+// @Security CookieAuth
+// @Produce plain
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "File path"
+// @Param version query string true "Version"
+// @Success 200 {string} string "Raw content of the version"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 404 {object} ErrorResponse "Version not found"
+// @Failure 500 {object} ErrorResponse "Failed to read file version"
+// @Router /workspaces/{workspace_name}/files/versions/content [get]
+func (h *Handler) GetFileVersionContent() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "GetFileVersionContent",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("file_path")
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil || decodedPath == "" {
+			log.Debug("invalid file path",
+				"filePath", filePath,
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+		version := r.URL.Query().Get("version")
+
+		content, err := h.Storage.GetFileVersion(ctx.UserID, ctx.Workspace.ID, decodedPath, version)
+		if err != nil {
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				log.Debug("file version not found",
+					"filePath", decodedPath,
+					"version", version,
+				)
+				respondError(w, "Version not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("failed to read file version",
+				"filePath", decodedPath,
+				"version", version,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to read file version", http.StatusInternalServerError)
+			return
+		}
+
+		contentType := mime.TypeByExtension(filepath.Ext(decodedPath))
+		if contentType == "" {
+			contentType = "text/plain"
+		}
+		w.Header().Set("Content-Type", contentType)
+		if _, err := w.Write(content); err != nil {
+			log.Error("failed to write response",
+				"filePath", decodedPath,
+				"error", err.Error(),
+			)
+		}
+	}
+}
+
+// RestoreFileVersion godoc
+// @Summary Restore a file version
+// @Description Overwrites a file with a previous version of it.
+// @Description The replaced content is kept as a new version, so the restore can be undone.
+// @Tags files
+// @ID restoreFileVersion
+// @Security CookieAuth
+// @Param workspace_name path string true "Workspace name"
+// @Param file_path query string true "File path"
+// @Param version query string true "Version"
+// @Success 204 "No Content - File restored successfully"
+// @Failure 400 {object} ErrorResponse "Invalid file path"
+// @Failure 404 {object} ErrorResponse "Version not found"
+// @Failure 409 {object} ErrorResponse "Workspace is pinned to a git ref and read-only"
+// @Failure 500 {object} ErrorResponse "Failed to restore file version"
+// @Router /workspaces/{workspace_name}/files/versions/restore [post]
+func (h *Handler) RestoreFileVersion() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getFilesLogger().With(
+			"handler", "RestoreFileVersion",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		filePath := r.URL.Query().Get("file_path")
+		decodedPath, err := url.PathUnescape(filePath)
+		if err != nil || decodedPath == "" {
+			log.Debug("invalid file path",
+				"filePath", filePath,
+			)
+			respondError(w, "Invalid file path", http.StatusBadRequest)
+			return
+		}
+		version := r.URL.Query().Get("version")
+
+		err = h.Storage.RestoreFileVersion(ctx.UserID, ctx.Workspace.ID, decodedPath, version)
+		if err != nil {
+			if storage.IsWorkspacePinnedError(err) {
+				respondError(w, "Workspace is pinned to a git ref and read-only", http.StatusConflict)
+				return
+			}
+
+			if storage.IsPathValidationError(err) {
+				log.Error("invalid file path attempted",
+					"filePath", decodedPath,
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid file path", http.StatusBadRequest)
+				return
+			}
+
+			if os.IsNotExist(err) {
+				log.Debug("file version not found",
+					"filePath", decodedPath,
+					"version", version,
+				)
+				respondError(w, "Version not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("failed to restore file version",
+				"filePath", decodedPath,
+				"version", version,
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to restore file version", http.StatusInternalServerError)
+			return
+		}
+
+		h.recordActivity(&models.Activity{
+			WorkspaceID: ctx.Workspace.ID,
+			UserID:      ctx.UserID,
+			Type:        models.ActivityFileSaved,
+			Path:        decodedPath,
+		})
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,101 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+	"lemma/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileVersions_Integration(t *testing.T) {
+	runWithDatabases(t, testFileVersions)
+}
+
+func testFileVersions(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarness(t, dbConfig)
+	defer h.teardown(t)
+
+	user := h.createTestUser(t, "versions@test.com", "password123", models.RoleEditor)
+
+	workspace := &models.Workspace{Name: "Versions Workspace"}
+	rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, user)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	baseURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name) + "/files"
+	query := "?file_path=" + url.QueryEscape("notes/note.md")
+
+	listVersions := func(t *testing.T) []storage.FileVersion {
+		t.Helper()
+		rr := h.makeRequest(t, http.MethodGet, baseURL+"/versions"+query, nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response handlers.FileVersionsResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		return response.Versions
+	}
+
+	for i := 1; i <= 3; i++ {
+		rr := h.makeRequestRaw(t, http.MethodPost, baseURL+query, strings.NewReader(fmt.Sprintf("version %d", i)), user)
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+
+	t.Run("list versions", func(t *testing.T) {
+		versions := listVersions(t)
+		require.Len(t, versions, 2)
+		assert.Equal(t, int64(len("version 1")), versions[0].Size)
+		assert.True(t, versions[0].CreatedAt.Before(versions[1].CreatedAt))
+	})
+
+	t.Run("get version content", func(t *testing.T) {
+		versions := listVersions(t)
+		rr := h.makeRequest(t, http.MethodGet, baseURL+"/versions/content"+query+"&version="+versions[0].Version, nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "version 1", rr.Body.String())
+
+		rr = h.makeRequest(t, http.MethodGet, baseURL+"/versions/content"+query+"&version=123", nil, user)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("restore version 1", func(t *testing.T) {
+		versions := listVersions(t)
+		rr := h.makeRequest(t, http.MethodPost, baseURL+"/versions/restore"+query+"&version="+versions[0].Version, nil, user)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, baseURL+"/content"+query, nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "version 1", rr.Body.String())
+
+		// The replaced content can be restored again
+		versions = listVersions(t)
+		require.Len(t, versions, 3)
+		rr = h.makeRequest(t, http.MethodGet, baseURL+"/versions/content"+query+"&version="+versions[2].Version, nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "version 3", rr.Body.String())
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodPost, baseURL+"/versions/restore"+query+"&version=latest", nil, user)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("versions are not listed as files", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, baseURL, nil, user)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), ".versions")
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, baseURL+"/versions?file_path="+url.QueryEscape("../../etc/passwd"), nil, user)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
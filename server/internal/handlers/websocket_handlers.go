@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"lemma/internal/context"
+	"lemma/internal/events"
+	"lemma/internal/logging"
+
+	"golang.org/x/net/websocket"
+)
+
+// WebSocket keepalive, a connection that sends nothing for webSocketPongWait, not even the pong
+// answering a ping, is closed
+const (
+	webSocketPingInterval = 30 * time.Second
+	webSocketPongWait     = 60 * time.Second
+	webSocketWriteWait    = 10 * time.Second
+)
+
+// maxWebSocketMessageSize is the largest message accepted from a WebSocket client
+const maxWebSocketMessageSize = 4096
+
+// EventTypePresence is the type of the events telling the clients of a workspace what another client is editing
+const EventTypePresence = "presence"
+
+var errWebSocketMessageTooLarge = errors.New("websocket message too large")
+
+// WebSocketMessage is a message sent to a workspace WebSocket client. The type is the activity type
+// and the data the activity, or presence with a PresenceEvent.
+type WebSocketMessage struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// WebSocketClientMessage is a message sent by a workspace WebSocket client.
+// A presence message reports the file the client is editing, an empty path that it stopped.
+type WebSocketClientMessage struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// PresenceEvent is broadcast to the other clients of a workspace when a client reports what it is editing
+type PresenceEvent struct {
+	UserID int    `json:"userId"`
+	Path   string `json:"path"`
+}
+
+// WorkspaceWebSocket godoc
+// @Summary Connect to the workspace WebSocket
+// @Description Upgrades to a WebSocket that sends the activity of the workspace as JSON messages with type and data.
+// @Description Clients may send {"type":"presence","path":"..."} to tell the other clients which file they are editing,
+// @Description they receive it as a presence message. Connections count towards the event stream limits.
+// @Tags workspaces
+// @ID workspaceWebSocket
+// @Security CookieAuth
+// @Param workspace_name path string true "Workspace name"
+// @Success 101 {string} string "Switching protocols"
+// @Failure 403 {string} string "Origin not allowed"
+// @Failure 429 {object} ErrorResponse "Too many event streams"
+// @Failure 500 {object} ErrorResponse "Streaming not supported"
+// @Router /workspaces/{workspace_name}/ws [get]
+func (h *Handler) WorkspaceWebSocket() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getEventsLogger().With(
+			"handler", "WorkspaceWebSocket",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		if _, ok := w.(http.Hijacker); !ok || h.Events == nil {
+			log.Error("websocket not supported")
+			respondError(w, "Streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		sub, err := h.Events.Subscribe(ctx.UserID, ctx.Workspace.ID)
+		if errors.Is(err, events.ErrTooManySubscriptions) {
+			log.Debug("event stream limit reached")
+			respondError(w, "Too many event streams", http.StatusTooManyRequests)
+			return
+		}
+		if err != nil {
+			log.Error("failed to subscribe to events",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to subscribe to events", http.StatusInternalServerError)
+			return
+		}
+		defer sub.Close()
+
+		server := websocket.Server{
+			Handshake: h.checkWebSocketOrigin,
+			Handler: func(ws *websocket.Conn) {
+				serveWebSocket(ws, sub, log)
+			},
+		}
+		server.ServeHTTP(w, r)
+	}
+}
+
+// checkWebSocketOrigin accepts upgrades from pages of the same host or of the allowed CORS origins,
+// so that other sites cannot open a WebSocket with the cookies of the user
+func (h *Handler) checkWebSocketOrigin(config *websocket.Config, r *http.Request) error {
+	origin, err := websocket.Origin(config, r)
+	if err != nil {
+		return err
+	}
+	if origin == nil {
+		return fmt.Errorf("missing origin")
+	}
+	config.Origin = origin
+
+	if origin.Host == r.Host || slices.Contains(h.AllowedOrigins, "*") ||
+		slices.Contains(h.AllowedOrigins, origin.Scheme+"://"+origin.Host) {
+		return nil
+	}
+	return fmt.Errorf("origin not allowed: %s", origin)
+}
+
+// serveWebSocket sends the events of sub to ws and broadcasts the presence reported by the client
+// until either side closes the connection
+func serveWebSocket(ws *websocket.Conn, sub *events.Subscription, log logging.Logger) {
+	defer ws.Close()
+
+	messages := make(chan []byte)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		readErr <- readWebSocket(ws, messages, done)
+	}()
+
+	ping := time.NewTicker(webSocketPingInterval)
+	defer ping.Stop()
+
+	// The other clients are told when a client that was editing a file goes away
+	editing := ""
+	defer func() {
+		if editing != "" {
+			sub.Broadcast(events.Event{Type: EventTypePresence, Data: PresenceEvent{UserID: sub.UserID}})
+		}
+	}()
+
+	for {
+		var err error
+		select {
+		case <-sub.Done():
+			log.Debug("websocket closed by the hub")
+			return
+		case err := <-readErr:
+			if err != io.EOF {
+				log.Debug("websocket read failed",
+					"error", err.Error(),
+				)
+			}
+			return
+		case <-ping.C:
+			ws.SetWriteDeadline(time.Now().Add(webSocketWriteWait))
+			ws.PayloadType = websocket.PingFrame
+			_, err = ws.Write(nil)
+		case event := <-sub.Events():
+			ws.SetWriteDeadline(time.Now().Add(webSocketWriteWait))
+			err = websocket.JSON.Send(ws, WebSocketMessage{Type: event.Type, Data: event.Data})
+		case data := <-messages:
+			var message WebSocketClientMessage
+			if err := json.Unmarshal(data, &message); err != nil || message.Type != EventTypePresence {
+				log.Debug("ignoring unknown websocket message",
+					"size", len(data),
+				)
+				continue
+			}
+			editing = message.Path
+			sub.Broadcast(events.Event{Type: EventTypePresence, Data: PresenceEvent{UserID: sub.UserID, Path: message.Path}})
+		}
+		if err != nil {
+			log.Debug("websocket write failed",
+				"error", err.Error(),
+			)
+			return
+		}
+	}
+}
+
+// readWebSocket reads the client messages of ws until the connection fails or is closed.
+// Frames are read one by one so that every frame, including the pongs answering the pings,
+// extends the read deadline. Pings and close frames are answered by the websocket package.
+func readWebSocket(ws *websocket.Conn, messages chan<- []byte, done <-chan struct{}) error {
+	for {
+		if err := ws.SetReadDeadline(time.Now().Add(webSocketPongWait)); err != nil {
+			return err
+		}
+		frame, err := ws.NewFrameReader()
+		if err != nil {
+			return err
+		}
+		frame, err = ws.HandleFrame(frame)
+		if err != nil {
+			return err
+		}
+		if frame == nil {
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(frame, maxWebSocketMessageSize+1))
+		if err != nil {
+			return err
+		}
+		if len(data) > maxWebSocketMessageSize {
+			return errWebSocketMessageTooLarge
+		}
+		if trailer := frame.TrailerReader(); trailer != nil {
+			if _, err := io.Copy(io.Discard, trailer); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case messages <- data:
+		case <-done:
+			return io.EOF
+		}
+	}
+}
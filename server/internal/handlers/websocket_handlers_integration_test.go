@@ -0,0 +1,122 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSocketHandlers_Integration(t *testing.T) {
+	runWithDatabases(t, testWebSocketHandlers)
+}
+
+func testWebSocketHandlers(t *testing.T, dbConfig DatabaseConfig) {
+	h := setupTestHarness(t, dbConfig)
+	defer h.teardown(t)
+
+	server := httptest.NewServer(h.Server.Router())
+	defer server.Close()
+
+	workspace := &models.Workspace{Name: "WebSocket Workspace"}
+	rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", workspace, h.RegularTestUser)
+	require.Equal(t, http.StatusOK, rr.Code)
+	workspaceURL := "/api/v1/workspaces/" + url.PathEscape(workspace.Name)
+
+	dial := func(t *testing.T, origin string) (*websocket.Conn, error) {
+		config, err := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+workspaceURL+"/ws", origin)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+		h.addAuthCookies(t, req, h.RegularTestUser)
+		config.Header = http.Header{"Cookie": req.Header["Cookie"]}
+		return websocket.DialConfig(config)
+	}
+
+	connect := func(t *testing.T) *websocket.Conn {
+		ws, err := dial(t, server.URL)
+		require.NoError(t, err)
+		t.Cleanup(func() { ws.Close() })
+		return ws
+	}
+
+	type message struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	receive := func(t *testing.T, ws *websocket.Conn) message {
+		t.Helper()
+		require.NoError(t, ws.SetReadDeadline(time.Now().Add(5*time.Second)))
+		var msg message
+		require.NoError(t, websocket.JSON.Receive(ws, &msg))
+		return msg
+	}
+	receivePresence := func(t *testing.T, ws *websocket.Conn) handlers.PresenceEvent {
+		t.Helper()
+		msg := receive(t, ws)
+		require.Equal(t, handlers.EventTypePresence, msg.Type)
+		var presence handlers.PresenceEvent
+		require.NoError(t, json.Unmarshal(msg.Data, &presence))
+		return presence
+	}
+
+	t.Run("receive events", func(t *testing.T) {
+		ws := connect(t)
+
+		rr := h.makeRequestRaw(t, http.MethodPost, workspaceURL+"/files?file_path="+url.QueryEscape("live.md"), strings.NewReader("content"), h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		msg := receive(t, ws)
+		assert.Equal(t, string(models.ActivityFileSaved), msg.Type)
+		assert.Contains(t, string(msg.Data), `"path":"live.md"`)
+	})
+
+	t.Run("presence and disconnect", func(t *testing.T) {
+		editor := connect(t)
+		viewer := connect(t)
+
+		require.NoError(t, websocket.JSON.Send(editor, map[string]string{"type": "presence", "path": "live.md"}))
+		presence := receivePresence(t, viewer)
+		assert.Equal(t, h.RegularTestUser.userModel.ID, presence.UserID)
+		assert.Equal(t, "live.md", presence.Path)
+
+		// The viewer is told when the editor disconnects
+		require.NoError(t, editor.Close())
+		presence = receivePresence(t, viewer)
+		assert.Equal(t, h.RegularTestUser.userModel.ID, presence.UserID)
+		assert.Empty(t, presence.Path)
+	})
+
+	t.Run("unknown messages are ignored", func(t *testing.T) {
+		ws := connect(t)
+
+		require.NoError(t, websocket.Message.Send(ws, "not json"))
+		rr := h.makeRequestRaw(t, http.MethodDelete, workspaceURL+"/files?file_path="+url.QueryEscape("live.md"), nil, h.RegularTestUser)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		msg := receive(t, ws)
+		assert.Equal(t, string(models.ActivityFileDeleted), msg.Type)
+	})
+
+	t.Run("reject other origins", func(t *testing.T) {
+		_, err := dial(t, "http://evil.example.com")
+		assert.Error(t, err)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		rr := h.makeRequest(t, http.MethodGet, workspaceURL+"/ws", nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
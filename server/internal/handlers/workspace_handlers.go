@@ -3,21 +3,92 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"lemma/internal/context"
+	"lemma/internal/git"
 	"lemma/internal/logging"
 	"lemma/internal/models"
+	"lemma/internal/storage"
 )
 
+// WorkspaceResponse is a workspace together with advisories about its settings
+type WorkspaceResponse struct {
+	*models.Workspace
+	Warnings []string `json:"warnings"`
+}
+
+func newWorkspaceResponse(workspace *models.Workspace) *WorkspaceResponse {
+	return &WorkspaceResponse{Workspace: workspace, Warnings: workspace.Warnings()}
+}
+
+// WorkspaceRequest is a workspace as sent to create or update it.
+// The git signing key and the tokens of the additional remotes are write-only, responses never include them.
+type WorkspaceRequest struct {
+	models.Workspace
+	// GitSigningKey replaces the stored signing key, an empty key removes it and leaving it out keeps it
+	GitSigningKey *string `json:"gitSigningKey,omitempty"`
+	// GitRemotes replaces the additional remotes, a remote without a token keeps the token stored for its name
+	GitRemotes []GitRemoteRequest `json:"gitRemotes,omitempty"`
+}
+
+// GitRemoteRequest is an additional git remote with its write-only token
+type GitRemoteRequest struct {
+	models.GitRemote
+	Token string `json:"token,omitempty"`
+}
+
+// workspace returns the workspace of the request with the secrets left out taken from stored, if any
+func (req *WorkspaceRequest) workspace(stored *models.Workspace) models.Workspace {
+	workspace := req.Workspace
+	// The home file is only set through UpdateHomeFile, which checks that the file exists
+	workspace.HomeFile = ""
+	if stored != nil {
+		workspace.HomeFile = stored.HomeFile
+	}
+	if req.GitSigningKey != nil {
+		workspace.GitSigningKey = *req.GitSigningKey
+	} else if stored != nil {
+		workspace.GitSigningKey = stored.GitSigningKey
+	}
+
+	if req.GitRemotes == nil {
+		workspace.GitRemotes = nil
+		return workspace
+	}
+	workspace.GitRemotes = make([]models.GitRemote, len(req.GitRemotes))
+	for i, remote := range req.GitRemotes {
+		workspace.GitRemotes[i] = remote.GitRemote
+		workspace.GitRemotes[i].Token = remote.Token
+		if remote.Token != "" || stored == nil {
+			continue
+		}
+		for _, storedRemote := range stored.GitRemotes {
+			if storedRemote.Name == remote.Name {
+				workspace.GitRemotes[i].Token = storedRemote.Token
+			}
+		}
+	}
+	return workspace
+}
+
 // DeleteWorkspaceResponse contains the name of the next workspace after deleting the current one
 type DeleteWorkspaceResponse struct {
 	NextWorkspaceName string `json:"nextWorkspaceName"`
 }
 
+// RenameWorkspaceRequest holds the new name of a workspace
+type RenameWorkspaceRequest struct {
+	NewName string `json:"newName"`
+}
+
 // LastWorkspaceNameResponse contains the name of the last opened workspace
 type LastWorkspaceNameResponse struct {
-	LastWorkspaceName string `json:"lastWorkspaceName"`
+	LastWorkspaceName string            `json:"lastWorkspaceName"`
+	Workspace         *models.Workspace `json:"workspace"`
 }
 
 func getWorkspaceLogger() logging.Logger {
@@ -26,12 +97,18 @@ func getWorkspaceLogger() logging.Logger {
 
 // ListWorkspaces godoc
 // @Summary List workspaces
-// @Description Lists all workspaces for the current user
+// @Description Lists all workspaces for the current user. Unlike other list endpoints, all workspaces up to
+// @Description the max page size are returned unless a limit is given.
 // @Tags workspaces
 // @ID listWorkspaces
 // @Security CookieAuth
 // @Produce json
+// @Param limit query int false "Maximum number of items to return, all items up to the max page size if omitted"
+// @Param offset query int false "Number of items to skip"
 // @Success 200 {array} models.Workspace
+// @Header 200 {int} X-Pagination-Limit "Effective limit"
+// @Header 200 {int} X-Total-Count "Total number of items"
+// @Failure 400 {object} ErrorResponse "Invalid limit"
 // @Failure 500 {object} ErrorResponse "Failed to list workspaces"
 // @Router /workspaces [get]
 func (h *Handler) ListWorkspaces() http.HandlerFunc {
@@ -46,6 +123,11 @@ func (h *Handler) ListWorkspaces() http.HandlerFunc {
 			"clientIP", r.RemoteAddr,
 		)
 
+		page, ok := h.parsePagination(w, r)
+		if !ok {
+			return
+		}
+
 		workspaces, err := h.DB.GetWorkspacesByUserID(ctx.UserID)
 		if err != nil {
 			log.Error("failed to fetch workspaces from database",
@@ -55,22 +137,35 @@ func (h *Handler) ListWorkspaces() http.HandlerFunc {
 			return
 		}
 
-		respondJSON(w, workspaces)
+		// Clients from before pagination expect the full list, so only an explicit limit pages it.
+		// The list is still capped at the max page size.
+		if r.URL.Query().Get("limit") == "" {
+			_, maxSize := h.pageSizes()
+			page.Limit = min(max(len(workspaces), 1), maxSize)
+		}
+
+		respondJSON(w, paginate(w, workspaces, page))
 	}
 }
 
 // CreateWorkspace godoc
 // @Summary Create workspace
-// @Description Creates a new workspace
+// @Description Creates a new workspace. The response includes warnings for settings that are valid but inadvisable.
 // @Tags workspaces
 // @ID createWorkspace
 // @Security CookieAuth
 // @Accept json
 // @Produce json
-// @Param body body models.Workspace true "Workspace"
-// @Success 200 {object} models.Workspace
+// @Param body body WorkspaceRequest true "Workspace"
+// @Success 200 {object} WorkspaceResponse
 // @Failure 400 {object} ErrorResponse "Invalid request body"
 // @Failure 400 {object} ErrorResponse "Invalid workspace"
+// @Failure 400 {object} ErrorResponse "Commit author name and email are required for auto-commit"
+// @Failure 400 {object} ErrorResponse "Git URL not allowed"
+// @Failure 400 {object} ErrorResponse "Invalid git signing key"
+// @Failure 403 {object} ErrorResponse "Workspace limit reached"
+// @Failure 409 {object} ErrorResponse "Workspace name already exists"
+// @Failure 500 {object} ErrorResponse "Failed to get workspaces"
 // @Failure 500 {object} ErrorResponse "Failed to create workspace"
 // @Failure 500 {object} ErrorResponse "Failed to initialize workspace directory"
 // @Failure 500 {object} ErrorResponse "Failed to setup git repo"
@@ -87,20 +182,78 @@ func (h *Handler) CreateWorkspace() http.HandlerFunc {
 			"clientIP", r.RemoteAddr,
 		)
 
-			var workspace models.Workspace
-		if err := json.NewDecoder(r.Body).Decode(&workspace); err != nil {
+			var req WorkspaceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.Debug("invalid request body received",
 				"error", err.Error(),
 			)
 			respondError(w, "Invalid request body", http.StatusBadRequest)
 			return
 		}
+		workspace := req.workspace(nil)
+		workspace.NormalizeName(h.WorkspaceNameNormalization)
 
 		if err := workspace.ValidateGitSettings(); err != nil {
-			log.Debug("invalid git settings provided",
-				"error", err.Error(),
+			if !errors.Is(err, models.ErrMissingCommitIdentity) {
+				log.Debug("invalid git settings provided",
+					"error", err.Error(),
+				)
+				respondError(w, "Invalid workspace", http.StatusBadRequest)
+				return
+			}
+			if !h.CommitIdentityFallback {
+				log.Debug("auto-commit enabled without commit identity")
+				respondError(w, "Commit author name and email are required for auto-commit", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if workspace.GitEnabled {
+			if err := h.Storage.ValidateGitURL(workspace.GitURL); err != nil {
+				log.Debug("git URL not allowed",
+					"error", err.Error(),
+				)
+				respondError(w, "Git URL not allowed", http.StatusBadRequest)
+				return
+			}
+			for _, remote := range workspace.GitRemotes {
+				if err := h.Storage.ValidateGitURL(remote.URL); err != nil {
+					log.Debug("git remote URL not allowed",
+						"remote", remote.Name,
+						"error", err.Error(),
+					)
+					respondError(w, "Git URL not allowed", http.StatusBadRequest)
+					return
+				}
+			}
+			if !validSigningKey(workspace.GitSigningKey, w, log) {
+				return
+			}
+		}
+
+		if h.MaxWorkspaces > 0 {
+			workspaces, err := h.DB.GetWorkspacesByUserID(ctx.UserID)
+			if err != nil {
+				log.Error("failed to fetch workspaces from database",
+					"error", err.Error(),
+				)
+				respondError(w, "Failed to get workspaces", http.StatusInternalServerError)
+				return
+			}
+			if len(workspaces) >= h.MaxWorkspaces {
+				log.Debug("workspace limit reached",
+					"maxWorkspaces", h.MaxWorkspaces,
+				)
+				respondError(w, fmt.Sprintf("Workspace limit reached, a user may have at most %d workspaces", h.MaxWorkspaces), http.StatusForbidden)
+				return
+			}
+		}
+
+		if h.workspaceNameTaken(ctx.UserID, workspace.NormalizedName, 0) {
+			log.Debug("workspace name already exists",
+				"workspaceName", workspace.Name,
 			)
-			respondError(w, "Invalid workspace", http.StatusBadRequest)
+			respondError(w, "Workspace name already exists", http.StatusConflict)
 			return
 		}
 
@@ -154,6 +307,38 @@ func (h *Handler) CreateWorkspace() http.HandlerFunc {
 				respondError(w, "Failed to setup git repo: "+err.Error(), http.StatusInternalServerError)
 				return
 			}
+			if err := h.Storage.SetGitRemotes(ctx.UserID, workspace.ID, gitRemotes(workspace.GitRemotes)); err != nil {
+				log.Error("failed to set git remotes",
+					"error", err.Error(),
+					"workspaceID", workspace.ID,
+				)
+				respondError(w, "Failed to setup git repo: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if err := h.Storage.SetGitSigningKey(ctx.UserID, workspace.ID, workspace.GitSigningKey); err != nil {
+				log.Error("failed to set git signing key",
+					"error", err.Error(),
+					"workspaceID", workspace.ID,
+				)
+				respondError(w, "Failed to setup git repo: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if workspace.GitPinnedRef != "" && !h.pinGitRef(w, ctx.UserID, workspace.ID, workspace.GitPinnedRef, log) {
+				return
+			}
+		}
+
+		// Use the default home file if the workspace came with one, e.g. from a cloned repository
+		if workspace.HomeFile == "" && h.DefaultHomeFile != "" && h.fileExists(ctx.UserID, workspace.ID, h.DefaultHomeFile) {
+			if err := h.DB.UpdateHomeFile(workspace.ID, h.DefaultHomeFile); err != nil {
+				log.Error("failed to set default home file",
+					"error", err.Error(),
+					"workspaceID", workspace.ID,
+				)
+			} else {
+				workspace.HomeFile = h.DefaultHomeFile
+			}
 		}
 
 		log.Info("workspace created",
@@ -162,7 +347,7 @@ func (h *Handler) CreateWorkspace() http.HandlerFunc {
 			"theme", workspace.Theme,
 			"gitEnabled", workspace.GitEnabled,
 		)
-		respondJSON(w, workspace)
+		respondJSON(w, newWorkspaceResponse(&workspace))
 	}
 }
 
@@ -188,6 +373,43 @@ func (h *Handler) GetWorkspace() http.HandlerFunc {
 	}
 }
 
+// workspaceNameTaken reports whether another workspace of the user than workspaceID has the
+// normalized name. The name identifies the workspace in URLs, so it is checked even when names
+// are not normalized and the normalized name is the name itself.
+func (h *Handler) workspaceNameTaken(userID int, normalizedName string, workspaceID int) bool {
+	existing, err := h.DB.GetWorkspaceByNormalizedName(userID, normalizedName, h.WorkspaceNameNormalization == models.WorkspaceNamesCaseInsensitive)
+	return err == nil && existing.ID != workspaceID
+}
+
+// validSigningKey checks that key, if set, can sign commits and writes the error response if not
+func validSigningKey(key string, w http.ResponseWriter, log logging.Logger) bool {
+	if key == "" {
+		return true
+	}
+	if _, err := git.ParseSigningKey(key); err != nil {
+		log.Debug("invalid git signing key",
+			"error", err.Error(),
+		)
+		respondError(w, "Invalid git signing key", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// gitRemotes returns the additional remotes of a workspace as the remotes the git client pushes to
+func gitRemotes(remotes []models.GitRemote) []git.Remote {
+	result := make([]git.Remote, 0, len(remotes))
+	for _, remote := range remotes {
+		result = append(result, git.Remote{
+			Name:     remote.Name,
+			URL:      remote.URL,
+			Username: remote.User,
+			Token:    remote.Token,
+		})
+	}
+	return result
+}
+
 func gitSettingsChanged(newWorkspace, old *models.Workspace) bool {
 	// Check if Git was enabled/disabled
 	if newWorkspace.GitEnabled != old.GitEnabled {
@@ -200,7 +422,8 @@ func gitSettingsChanged(newWorkspace, old *models.Workspace) bool {
 			newWorkspace.GitUser != old.GitUser ||
 			newWorkspace.GitToken != old.GitToken ||
 			newWorkspace.GitCommitName != old.GitCommitName ||
-			newWorkspace.GitCommitEmail != old.GitCommitEmail
+			newWorkspace.GitCommitEmail != old.GitCommitEmail ||
+			newWorkspace.GitSigningKey != old.GitSigningKey
 	}
 
 	return false
@@ -208,18 +431,28 @@ func gitSettingsChanged(newWorkspace, old *models.Workspace) bool {
 
 // UpdateWorkspace godoc
 // @Summary Update workspace
-// @Description Updates the current workspace
+// @Description Updates the current workspace. The response includes warnings for settings that are valid but inadvisable.
+// @Description Setting gitPinnedRef checks out that tag or commit and makes the workspace read-only, clearing it returns to the branch.
+// @Description gitRemotes replaces the additional remotes changes are pushed to, leaving it out keeps them.
+// @Description The signing key and remote tokens are never returned, leaving them out keeps the stored ones.
 // @Tags workspaces
 // @ID updateWorkspace
 // @Security CookieAuth
 // @Accept json
 // @Produce json
 // @Param workspace_name path string true "Workspace name"
-// @Param body body models.Workspace true "Workspace"
-// @Success 200 {object} models.Workspace
+// @Param body body WorkspaceRequest true "Workspace"
+// @Success 200 {object} WorkspaceResponse
 // @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 400 {object} ErrorResponse "Commit author name and email are required for auto-commit"
+// @Failure 400 {object} ErrorResponse "Git URL not allowed"
+// @Failure 400 {object} ErrorResponse "Invalid git signing key"
+// @Failure 400 {object} ErrorResponse "Git ref not found"
+// @Failure 409 {object} ErrorResponse "Workspace name already exists"
+// @Failure 409 {object} ErrorResponse "Workspace has uncommitted changes"
 // @Failure 500 {object} ErrorResponse "Failed to update workspace"
 // @Failure 500 {object} ErrorResponse "Failed to setup git repo"
+// @Failure 500 {object} ErrorResponse "Failed to checkout git ref"
 // @Router /workspaces/{workspace_name} [put]
 func (h *Handler) UpdateWorkspace() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -234,18 +467,20 @@ func (h *Handler) UpdateWorkspace() http.HandlerFunc {
 			"clientIP", r.RemoteAddr,
 		)
 
-		var workspace models.Workspace
-		if err := json.NewDecoder(r.Body).Decode(&workspace); err != nil {
+		var req WorkspaceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.Debug("invalid request body received",
 				"error", err.Error(),
 			)
 			respondError(w, "Invalid request body", http.StatusBadRequest)
 			return
 		}
+		workspace := req.workspace(ctx.Workspace)
 
 		// Set IDs from the request
 		workspace.ID = ctx.Workspace.ID
 		workspace.UserID = ctx.UserID
+		workspace.NormalizeName(h.WorkspaceNameNormalization)
 
 		// Validate the workspace
 		if err := workspace.Validate(); err != nil {
@@ -256,9 +491,37 @@ func (h *Handler) UpdateWorkspace() http.HandlerFunc {
 			return
 		}
 
+		if err := workspace.ValidateCommitIdentity(); err != nil && !h.CommitIdentityFallback {
+			log.Debug("auto-commit enabled without commit identity")
+			respondError(w, "Commit author name and email are required for auto-commit", http.StatusBadRequest)
+			return
+		}
+
+		if workspace.GitEnabled && !validSigningKey(workspace.GitSigningKey, w, log) {
+			return
+		}
+
+		if workspace.NormalizedName != ctx.Workspace.NormalizedName &&
+			h.workspaceNameTaken(ctx.UserID, workspace.NormalizedName, ctx.Workspace.ID) {
+			log.Debug("workspace name already exists",
+				"workspaceName", workspace.Name,
+			)
+			respondError(w, "Workspace name already exists", http.StatusConflict)
+			return
+		}
+
+		// Remotes left out of the request are kept, disabling git removes them
+		remotes := workspace.GitRemotes
+		remotesChanged := workspace.GitEnabled && remotes != nil
+		if !workspace.GitEnabled {
+			workspace.GitRemotes = []models.GitRemote{}
+		} else if remotes == nil {
+			remotes = ctx.Workspace.GitRemotes
+		}
+
 		// Track what's changed for logging
 		changes := map[string]bool{
-			"gitSettings": gitSettingsChanged(&workspace, ctx.Workspace),
+			"gitSettings": gitSettingsChanged(&workspace, ctx.Workspace) || remotesChanged,
 			"name":        workspace.Name != ctx.Workspace.Name,
 			"theme":       workspace.Theme != ctx.Workspace.Theme,
 			"autoSave":    workspace.AutoSave != ctx.Workspace.AutoSave,
@@ -276,17 +539,55 @@ func (h *Handler) UpdateWorkspace() http.HandlerFunc {
 					workspace.GitCommitName,
 					workspace.GitCommitEmail,
 				); err != nil {
+					if storage.IsGitURLError(err) {
+						log.Debug("git URL not allowed",
+							"error", err.Error(),
+						)
+						respondError(w, "Git URL not allowed", http.StatusBadRequest)
+						return
+					}
 					log.Error("failed to setup git repository",
 						"error", err.Error(),
 					)
 					respondError(w, "Failed to setup git repo: "+err.Error(), http.StatusInternalServerError)
 					return
 				}
+				if err := h.Storage.SetGitRemotes(ctx.UserID, ctx.Workspace.ID, gitRemotes(remotes)); err != nil {
+					if storage.IsGitURLError(err) {
+						log.Debug("git remote URL not allowed",
+							"error", err.Error(),
+						)
+						respondError(w, "Git URL not allowed", http.StatusBadRequest)
+						return
+					}
+					log.Error("failed to set git remotes",
+						"error", err.Error(),
+					)
+					respondError(w, "Failed to setup git repo: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
+				if err := h.Storage.SetGitSigningKey(ctx.UserID, ctx.Workspace.ID, workspace.GitSigningKey); err != nil {
+					log.Error("failed to set git signing key",
+						"error", err.Error(),
+					)
+					respondError(w, "Failed to setup git repo: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
 			} else {
+				// Return to the branch first so the files are not left at the pinned ref
+				if ctx.Workspace.GitPinnedRef != "" && !h.pinGitRef(w, ctx.UserID, ctx.Workspace.ID, "", log) {
+					return
+				}
 				h.Storage.DisableGitRepo(ctx.UserID, ctx.Workspace.ID)
 			}
 		}
 
+		if workspace.GitEnabled && workspace.GitPinnedRef != ctx.Workspace.GitPinnedRef {
+			if !h.pinGitRef(w, ctx.UserID, ctx.Workspace.ID, workspace.GitPinnedRef, log) {
+				return
+			}
+		}
+
 		if err := h.DB.UpdateWorkspace(&workspace); err != nil {
 			log.Error("failed to update workspace in database",
 				"error", err.Error(),
@@ -294,14 +595,100 @@ func (h *Handler) UpdateWorkspace() http.HandlerFunc {
 			respondError(w, "Failed to update workspace", http.StatusInternalServerError)
 			return
 		}
+		workspace.GitRemotes = remotes
 
-		respondJSON(w, workspace)
+		respondJSON(w, newWorkspaceResponse(&workspace))
+	}
+}
+
+// RenameWorkspace godoc
+// @Summary Rename workspace
+// @Description Renames the current workspace and leaves its settings unchanged. The name is normalized as configured
+// @Description and must not be taken by another workspace of the user. The last opened workspace follows the rename.
+// @Tags workspaces
+// @ID renameWorkspace
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param workspace_name path string true "Workspace name"
+// @Param body body RenameWorkspaceRequest true "New name"
+// @Success 200 {object} models.Workspace
+// @Failure 400 {object} ErrorResponse "Invalid request body"
+// @Failure 400 {object} ErrorResponse "Workspace name is required"
+// @Failure 400 {object} ErrorResponse "Invalid workspace"
+// @Failure 409 {object} ErrorResponse "Workspace name already exists"
+// @Failure 500 {object} ErrorResponse "Failed to rename workspace"
+// @Router /workspaces/{workspace_name}/rename [post]
+func (h *Handler) RenameWorkspace() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := context.GetRequestContext(w, r)
+		if !ok {
+			return
+		}
+		log := getWorkspaceLogger().With(
+			"handler", "RenameWorkspace",
+			"userID", ctx.UserID,
+			"workspaceID", ctx.Workspace.ID,
+			"clientIP", r.RemoteAddr,
+		)
+
+		var req RenameWorkspaceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Debug("invalid request body received",
+				"error", err.Error(),
+			)
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		workspace := *ctx.Workspace
+		workspace.Name = req.NewName
+		workspace.NormalizeName(h.WorkspaceNameNormalization)
+		if strings.TrimSpace(workspace.Name) == "" {
+			respondError(w, "Workspace name is required", http.StatusBadRequest)
+			return
+		}
+
+		if err := workspace.Validate(); err != nil {
+			log.Debug("invalid workspace configuration",
+				"error", err.Error(),
+			)
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if h.workspaceNameTaken(ctx.UserID, workspace.NormalizedName, workspace.ID) {
+			log.Debug("workspace name already exists",
+				"workspaceName", workspace.Name,
+			)
+			respondError(w, "Workspace name already exists", http.StatusConflict)
+			return
+		}
+
+		// The stored remotes are left unchanged
+		remotes := workspace.GitRemotes
+		workspace.GitRemotes = nil
+		if err := h.DB.UpdateWorkspace(&workspace); err != nil {
+			log.Error("failed to rename workspace in database",
+				"error", err.Error(),
+			)
+			respondError(w, "Failed to rename workspace", http.StatusInternalServerError)
+			return
+		}
+		workspace.GitRemotes = remotes
+
+		log.Info("workspace renamed",
+			"oldName", ctx.Workspace.Name,
+			"newName", workspace.Name,
+		)
+		respondJSON(w, &workspace)
 	}
 }
 
 // DeleteWorkspace godoc
 // @Summary Delete workspace
-// @Description Deletes the current workspace
+// @Description Deletes the current workspace unless the user would be left with fewer than the configured minimum of workspaces.
+// @Description The next workspace name is empty when the user has no workspaces left.
 // @Tags workspaces
 // @ID deleteWorkspace
 // @Security CookieAuth
@@ -309,6 +696,7 @@ func (h *Handler) UpdateWorkspace() http.HandlerFunc {
 // @Param workspace_name path string true "Workspace name"
 // @Success 200 {object} DeleteWorkspaceResponse
 // @Failure 400 {object} ErrorResponse "Cannot delete the last workspace"
+// @Failure 400 {object} ErrorResponse "Cannot delete workspace, a user must keep at least N workspaces"
 // @Failure 500 {object} ErrorResponse "Failed to get workspaces"
 // @Failure 500 {object} ErrorResponse "Failed to start transaction"
 // @Failure 500 {object} ErrorResponse "Failed to update last workspace"
@@ -329,7 +717,7 @@ func (h *Handler) DeleteWorkspace() http.HandlerFunc {
 			"clientIP", r.RemoteAddr,
 		)
 
-		// Check if this is the user's last workspace
+		// Check that the user keeps the minimum of workspaces
 		workspaces, err := h.DB.GetWorkspacesByUserID(ctx.UserID)
 		if err != nil {
 			log.Error("failed to fetch workspaces from database",
@@ -339,13 +727,19 @@ func (h *Handler) DeleteWorkspace() http.HandlerFunc {
 			return
 		}
 
-		if len(workspaces) <= 1 {
-			log.Debug("attempted to delete last workspace")
-			respondError(w, "Cannot delete the last workspace", http.StatusBadRequest)
+		if len(workspaces) <= h.MinWorkspaces {
+			log.Debug("attempted to delete below the minimum workspaces",
+				"minWorkspaces", h.MinWorkspaces,
+			)
+			message := "Cannot delete the last workspace"
+			if h.MinWorkspaces > 1 {
+				message = fmt.Sprintf("Cannot delete workspace, a user must keep at least %d workspaces", h.MinWorkspaces)
+			}
+			respondError(w, message, http.StatusBadRequest)
 			return
 		}
 
-		// Find another workspace to set as last
+		// Find another workspace to set as last, none is left when the minimum is 0
 		var nextWorkspaceName string
 		var nextWorkspaceID int
 		for _, ws := range workspaces {
@@ -415,7 +809,7 @@ func (h *Handler) DeleteWorkspace() http.HandlerFunc {
 
 // GetLastWorkspaceName godoc
 // @Summary Get last workspace name
-// @Description Returns the name of the last opened workspace
+// @Description Returns the last opened workspace. If it no longer exists, the oldest workspace is returned and becomes the last workspace.
 // @Tags workspaces
 // @ID getLastWorkspaceName
 // @Security CookieAuth
@@ -435,16 +829,19 @@ func (h *Handler) GetLastWorkspaceName() http.HandlerFunc {
 			"clientIP", r.RemoteAddr,
 		)
 
-		workspaceName, err := h.DB.GetLastWorkspaceName(ctx.UserID)
+		workspace, err := h.DB.GetDefaultWorkspace(ctx.UserID)
 		if err != nil {
-			log.Error("failed to fetch last workspace name",
+			log.Error("failed to resolve last workspace",
 				"error", err.Error(),
 			)
 			respondError(w, "Failed to get last workspace", http.StatusInternalServerError)
 			return
 		}
 
-		respondJSON(w, &LastWorkspaceNameResponse{LastWorkspaceName: workspaceName})
+		respondJSON(w, &LastWorkspaceNameResponse{
+			LastWorkspaceName: workspace.Name,
+			Workspace:         workspace,
+		})
 	}
 }
 
@@ -5,9 +5,12 @@ package handlers_test
 import (
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
+	"lemma/internal/app"
+	"lemma/internal/handlers"
 	"lemma/internal/models"
 
 	"github.com/stretchr/testify/assert"
@@ -197,6 +200,28 @@ func testWorkspaceHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			assert.True(t, h.MockGit.IsInitialized())
 		})
 
+		t.Run("response includes warnings", func(t *testing.T) {
+			update := &models.Workspace{
+				Name:          workspace.Name,
+				Theme:         "dark",
+				GitEnabled:    true,
+				GitURL:        "http://github.com/test/repo.git",
+				GitUser:       "testuser",
+				GitToken:      "testtoken",
+				GitCommitName: "Test User",
+			}
+
+			rr := h.makeRequest(t, http.MethodPut, baseURL, update, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var response handlers.WorkspaceResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			assert.Equal(t, workspace.Name, response.Name)
+			require.Len(t, response.Warnings, 2)
+			assert.Contains(t, response.Warnings[0], "unencrypted")
+			assert.Contains(t, response.Warnings[1], "Commit author")
+		})
+
 		t.Run("invalid git settings", func(t *testing.T) {
 			update := &models.Workspace{
 				Name:       workspace.Name,
@@ -214,12 +239,12 @@ func testWorkspaceHandlers(t *testing.T, dbConfig DatabaseConfig) {
 			rr := h.makeRequest(t, http.MethodGet, "/api/v1/workspaces/_op/last", nil, h.RegularTestUser)
 			require.Equal(t, http.StatusOK, rr.Code)
 
-			var response struct {
-				LastWorkspaceName string `json:"lastWorkspaceName"`
-			}
+			var response handlers.LastWorkspaceNameResponse
 			err := json.NewDecoder(rr.Body).Decode(&response)
 			require.NoError(t, err)
 			assert.NotEmpty(t, response.LastWorkspaceName)
+			require.NotNil(t, response.Workspace)
+			assert.Equal(t, response.LastWorkspaceName, response.Workspace.Name)
 		})
 
 		t.Run("update last workspace", func(t *testing.T) {
@@ -245,6 +270,52 @@ func testWorkspaceHandlers(t *testing.T, dbConfig DatabaseConfig) {
 		})
 	})
 
+	t.Run("rename workspace", func(t *testing.T) {
+		t.Run("successful rename", func(t *testing.T) {
+			rr := h.makeRequest(t, http.MethodPost, baseURL+"/rename", handlers.RenameWorkspaceRequest{NewName: "Renamed Workspace"}, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var renamed models.Workspace
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&renamed))
+			assert.Equal(t, workspace.ID, renamed.ID)
+			assert.Equal(t, "Renamed Workspace", renamed.Name)
+			assert.Equal(t, "dark", renamed.Theme)
+
+			rr = h.makeRequest(t, http.MethodGet, baseURL, nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+
+			workspace.Name = renamed.Name
+			baseURL = "/api/v1/workspaces/" + url.PathEscape(workspace.Name)
+			rr = h.makeRequest(t, http.MethodGet, baseURL, nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			// The last workspace is stored by ID and follows the rename
+			rr = h.makeRequest(t, http.MethodGet, "/api/v1/workspaces/_op/last", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			var last handlers.LastWorkspaceNameResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&last))
+			assert.Equal(t, workspace.Name, last.LastWorkspaceName)
+		})
+
+		t.Run("name already exists", func(t *testing.T) {
+			other := &models.Workspace{Name: "Rename Target"}
+			rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", other, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodPost, baseURL+"/rename", handlers.RenameWorkspaceRequest{NewName: other.Name}, h.RegularTestUser)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+
+			// Renaming to the current name is not a conflict
+			rr = h.makeRequest(t, http.MethodPost, baseURL+"/rename", handlers.RenameWorkspaceRequest{NewName: workspace.Name}, h.RegularTestUser)
+			assert.Equal(t, http.StatusOK, rr.Code)
+		})
+
+		t.Run("empty name", func(t *testing.T) {
+			rr := h.makeRequest(t, http.MethodPost, baseURL+"/rename", handlers.RenameWorkspaceRequest{NewName: " "}, h.RegularTestUser)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	})
+
 	t.Run("delete workspace", func(t *testing.T) {
 		// Get current workspaces to know how many we have
 		rr := h.makeRequest(t, http.MethodGet, "/api/v1/workspaces", nil, h.RegularTestUser)
@@ -307,3 +378,128 @@ func testWorkspaceHandlers(t *testing.T, dbConfig DatabaseConfig) {
 		})
 	})
 }
+
+func TestWorkspaceLimits_Integration(t *testing.T) {
+	runWithDatabases(t, testWorkspaceLimits)
+}
+
+func testWorkspaceLimits(t *testing.T, dbConfig DatabaseConfig) {
+	createWorkspace := func(t *testing.T, h *testHarness, name string) *httptest.ResponseRecorder {
+		return h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", &models.Workspace{Name: name}, h.RegularTestUser)
+	}
+	deleteWorkspace := func(t *testing.T, h *testHarness, name string) *httptest.ResponseRecorder {
+		return h.makeRequest(t, http.MethodDelete, "/api/v1/workspaces/"+url.PathEscape(name), nil, h.RegularTestUser)
+	}
+	listWorkspaces := func(t *testing.T, h *testHarness) []*models.Workspace {
+		rr := h.makeRequest(t, http.MethodGet, "/api/v1/workspaces", nil, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var workspaces []*models.Workspace
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&workspaces))
+		return workspaces
+	}
+
+	t.Run("list without limit", func(t *testing.T) {
+		h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+			config.DefaultPageSize = 1
+		})
+		defer h.teardown(t)
+
+		rr := createWorkspace(t, h, "Second")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		// The default page size does not apply to workspaces
+		assert.Len(t, listWorkspaces(t, h), 2)
+
+		rr = h.makeRequest(t, http.MethodGet, "/api/v1/workspaces?limit=1", nil, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var workspaces []*models.Workspace
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&workspaces))
+		assert.Len(t, workspaces, 1)
+		assert.Equal(t, "2", rr.Header().Get(handlers.HeaderTotalCount))
+	})
+
+	t.Run("list without limit is capped at the max page size", func(t *testing.T) {
+		h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+			config.MaxPageSize = 1
+		})
+		defer h.teardown(t)
+
+		rr := createWorkspace(t, h, "Second")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = h.makeRequest(t, http.MethodGet, "/api/v1/workspaces", nil, h.RegularTestUser)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var workspaces []*models.Workspace
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&workspaces))
+		assert.Len(t, workspaces, 1)
+		assert.Equal(t, "1", rr.Header().Get(handlers.HeaderPaginationLimit))
+		assert.Equal(t, "2", rr.Header().Get(handlers.HeaderTotalCount))
+	})
+
+	t.Run("create at maximum", func(t *testing.T) {
+		h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+			config.MaxWorkspacesPerUser = 2
+		})
+		defer h.teardown(t)
+
+		// The user starts with the default workspace
+		rr := createWorkspace(t, h, "Second")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = createWorkspace(t, h, "Third")
+		require.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Contains(t, rr.Body.String(), "at most 2 workspaces")
+		assert.Len(t, listWorkspaces(t, h), 2)
+
+		// Other users have their own limit
+		rr = h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", &models.Workspace{Name: "Admin Second"}, h.AdminTestUser)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		// Deleting a workspace makes room for a new one
+		rr = deleteWorkspace(t, h, "Second")
+		require.Equal(t, http.StatusOK, rr.Code)
+		rr = createWorkspace(t, h, "Third")
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("delete at minimum", func(t *testing.T) {
+		h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+			config.MinWorkspacesPerUser = 2
+		})
+		defer h.teardown(t)
+
+		rr := createWorkspace(t, h, "Second")
+		require.Equal(t, http.StatusOK, rr.Code)
+		rr = createWorkspace(t, h, "Third")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = deleteWorkspace(t, h, "Third")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = deleteWorkspace(t, h, "Second")
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "at least 2 workspaces")
+		assert.Len(t, listWorkspaces(t, h), 2)
+	})
+
+	t.Run("delete all workspaces", func(t *testing.T) {
+		h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+			config.MinWorkspacesPerUser = 0
+		})
+		defer h.teardown(t)
+
+		workspaces := listWorkspaces(t, h)
+		require.Len(t, workspaces, 1)
+
+		rr := deleteWorkspace(t, h, workspaces[0].Name)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response handlers.DeleteWorkspaceResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Empty(t, response.NextWorkspaceName)
+		assert.Empty(t, listWorkspaces(t, h))
+
+		rr = createWorkspace(t, h, "Fresh Start")
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
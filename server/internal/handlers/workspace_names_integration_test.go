@@ -0,0 +1,127 @@
+//go:build integration
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"lemma/internal/app"
+	"lemma/internal/handlers"
+	"lemma/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceNameNormalization_Integration(t *testing.T) {
+	runWithDatabases(t, testWorkspaceNameNormalization)
+}
+
+func testWorkspaceNameNormalization(t *testing.T, dbConfig DatabaseConfig) {
+	createWorkspace := func(t *testing.T, h *testHarness, name string) *httptest.ResponseRecorder {
+		t.Helper()
+		return h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", &models.Workspace{Name: name}, h.RegularTestUser)
+	}
+
+	t.Run("lowercase", func(t *testing.T) {
+		h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+			config.WorkspaceNameNormalization = models.WorkspaceNamesCaseInsensitive
+		})
+		defer h.teardown(t)
+
+		var created models.Workspace
+		t.Run("create normalizes whitespace", func(t *testing.T) {
+			rr := createWorkspace(t, h, "  My   Notes ")
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+			assert.Equal(t, "My Notes", created.Name)
+		})
+
+		t.Run("create with name differing in case", func(t *testing.T) {
+			rr := createWorkspace(t, h, "my notes")
+			assert.Equal(t, http.StatusConflict, rr.Code)
+		})
+
+		t.Run("create with name differing in whitespace", func(t *testing.T) {
+			rr := createWorkspace(t, h, "My\tNotes")
+			assert.Equal(t, http.StatusConflict, rr.Code)
+		})
+
+		t.Run("rename to taken name", func(t *testing.T) {
+			rr := createWorkspace(t, h, "Other")
+			require.Equal(t, http.StatusOK, rr.Code)
+			var other models.Workspace
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&other))
+
+			other.Name = "MY NOTES"
+			rr = h.makeRequest(t, http.MethodPut, "/api/v1/workspaces/Other", &other, h.RegularTestUser)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+		})
+
+		t.Run("change case of own name", func(t *testing.T) {
+			created.Name = "my notes"
+			rr := h.makeRequest(t, http.MethodPut, "/api/v1/workspaces/"+url.PathEscape("My Notes"), &created, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			rr = h.makeRequest(t, http.MethodGet, "/api/v1/workspaces/"+url.PathEscape("my notes"), nil, h.RegularTestUser)
+			assert.Equal(t, http.StatusOK, rr.Code)
+		})
+
+		t.Run("other users are not affected", func(t *testing.T) {
+			rr := h.makeRequest(t, http.MethodPost, "/api/v1/workspaces", &models.Workspace{Name: "My Notes"}, h.AdminTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			var adminWorkspace models.Workspace
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&adminWorkspace))
+
+			t.Run("transfer to owner of name differing in case", func(t *testing.T) {
+				req := handlers.TransferWorkspaceRequest{UserID: h.RegularTestUser.userModel.ID}
+				rr := h.makeRequest(t, http.MethodPost, fmt.Sprintf("/api/v1/admin/workspaces/%d/transfer", adminWorkspace.ID), req, h.AdminTestUser)
+				assert.Equal(t, http.StatusConflict, rr.Code)
+			})
+		})
+
+		t.Run("pre-existing name stored with its case", func(t *testing.T) {
+			// The default workspace and rows migrated from before normalization keep the case in the normalized name
+			existing, err := h.DB.GetWorkspaceByName(h.RegularTestUser.userModel.ID, "Main")
+			require.NoError(t, err)
+			require.Equal(t, "Main", existing.NormalizedName)
+
+			assert.Equal(t, http.StatusConflict, createWorkspace(t, h, "main").Code)
+
+			other := models.Workspace{Name: "Other"}
+			rr := h.makeRequest(t, http.MethodGet, "/api/v1/workspaces/Other", nil, h.RegularTestUser)
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&other))
+			other.Name = "MAIN"
+			rr = h.makeRequest(t, http.MethodPut, "/api/v1/workspaces/Other", &other, h.RegularTestUser)
+			assert.Equal(t, http.StatusConflict, rr.Code)
+		})
+	})
+
+	t.Run("whitespace", func(t *testing.T) {
+		h := setupTestHarnessWithConfig(t, dbConfig, func(config *app.Config) {
+			config.WorkspaceNameNormalization = models.WorkspaceNamesTrimmed
+		})
+		defer h.teardown(t)
+
+		require.Equal(t, http.StatusOK, createWorkspace(t, h, "Notes").Code)
+		assert.Equal(t, http.StatusConflict, createWorkspace(t, h, " Notes  ").Code)
+		assert.Equal(t, http.StatusOK, createWorkspace(t, h, "notes").Code)
+		assert.Equal(t, http.StatusBadRequest, createWorkspace(t, h, "   ").Code)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		h := setupTestHarness(t, dbConfig)
+		defer h.teardown(t)
+
+		require.Equal(t, http.StatusOK, createWorkspace(t, h, "Notes").Code)
+		assert.Equal(t, http.StatusOK, createWorkspace(t, h, "notes").Code)
+		// The name identifies the workspace in URLs, so an exact duplicate is rejected
+		assert.Equal(t, http.StatusConflict, createWorkspace(t, h, "Notes").Code)
+	})
+}
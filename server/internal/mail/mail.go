@@ -0,0 +1,48 @@
+// Package mail provides the delivery of emails sent by the server, e.g. password reset tokens
+package mail
+
+import (
+	"lemma/internal/logging"
+	"strings"
+)
+
+// Message is an email to a single recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	// Secrets are values in the body, e.g. reset tokens, that are redacted when the message is logged
+	Secrets []string
+}
+
+// Mailer delivers emails. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// logMailer is a Mailer that only logs messages, used until a delivery method is configured
+type logMailer struct {
+	logger logging.Logger
+}
+
+// NewLogMailer creates a Mailer that logs messages instead of sending them.
+// The recipient and subject are logged as warnings, the body only at debug level
+// and with the secrets of the message redacted.
+func NewLogMailer() Mailer {
+	return &logMailer{logger: logging.WithGroup("mail")}
+}
+
+// Send logs the message
+func (m *logMailer) Send(msg Message) error {
+	m.logger.Warn("no mail delivery configured, email not sent",
+		"to", msg.To,
+		"subject", msg.Subject)
+	body := msg.Body
+	for _, secret := range msg.Secrets {
+		body = strings.ReplaceAll(body, secret, "[REDACTED]")
+	}
+	m.logger.Debug("unsent email body",
+		"to", msg.To,
+		"body", body)
+	return nil
+}
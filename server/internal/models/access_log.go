@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// FileAccessType represents how a file was accessed
+type FileAccessType string
+
+// File access types
+const (
+	FileAccessRead   FileAccessType = "read"
+	FileAccessExport FileAccessType = "export"
+	FileAccessBundle FileAccessType = "bundle"
+)
+
+// FileAccess is an entry of the access log of a workspace, recorded when a file is read
+// or downloaded while access logging is enabled for the workspace
+type FileAccess struct {
+	ID          int            `json:"id" db:"id,default"`
+	WorkspaceID int            `json:"workspaceId" db:"workspace_id"`
+	UserID      int            `json:"userId" db:"user_id"`
+	Type        FileAccessType `json:"type" db:"type"`
+	// Path is the file or directory accessed, empty for the whole workspace
+	Path      string    `json:"path" db:"path"`
+	IPAddress string    `json:"ipAddress" db:"ip_address"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at,default"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// ActivityType represents the kind of event recorded in a workspace activity feed
+type ActivityType string
+
+// Activity types
+const (
+	ActivityFileSaved       ActivityType = "file_saved"
+	ActivityFileUploaded    ActivityType = "file_uploaded"
+	ActivityFileMoved       ActivityType = "file_moved"
+	ActivityFileCopied      ActivityType = "file_copied"
+	ActivityFileDeleted     ActivityType = "file_deleted"
+	ActivityFileTransferred ActivityType = "file_transferred"
+	ActivityDirCreated      ActivityType = "dir_created"
+	ActivityDirDeleted      ActivityType = "dir_deleted"
+	ActivityGitCommit       ActivityType = "git_commit"
+	ActivityGitPull         ActivityType = "git_pull"
+)
+
+// Activity represents an event in a workspace activity feed
+type Activity struct {
+	ID          int          `json:"id" db:"id,default"`
+	WorkspaceID int          `json:"workspaceId" db:"workspace_id"`
+	UserID      int          `json:"actorId" db:"user_id"`
+	Type        ActivityType `json:"type" db:"type"`
+	Path        string       `json:"path,omitempty" db:"path"`
+	TargetPath  string       `json:"targetPath,omitempty" db:"target_path"`
+	Message     string       `json:"message,omitempty" db:"message"`
+	CreatedAt   time.Time    `json:"createdAt" db:"created_at,default"`
+}
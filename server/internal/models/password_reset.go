@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// PasswordResetToken is a single-use token that allows a user to set a new password without the current one
+type PasswordResetToken struct {
+	ID        int       `db:"id,default"`         // Unique token identifier
+	UserID    int       `db:"user_id"`            // ID of the user whose password can be reset
+	TokenHash string    `db:"token_hash"`         // SHA-256 hash of the token, the token itself is not stored
+	ExpiresAt time.Time `db:"expires_at"`         // When this token expires
+	CreatedAt time.Time `db:"created_at,default"` // When this token was created
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -11,6 +14,10 @@ type Workspace struct {
 	Name               string    `json:"name" db:"name" validate:"required"`
 	CreatedAt          time.Time `json:"createdAt" db:"created_at,default"`
 	LastOpenedFilePath string    `json:"lastOpenedFilePath" db:"last_opened_file_path"`
+	HomeFile           string    `json:"homeFile" db:"home_file"`
+
+	// NormalizedName is the form of the name compared for uniqueness, see NormalizeName
+	NormalizedName string `json:"-" db:"normalized_name"`
 
 	// Integrated settings
 	Theme                string `json:"theme" db:"theme" validate:"required,oneof=light dark"`
@@ -24,6 +31,45 @@ type Workspace struct {
 	GitCommitMsgTemplate string `json:"gitCommitMsgTemplate" db:"git_commit_msg_template"`
 	GitCommitName        string `json:"gitCommitName" db:"git_commit_name"`
 	GitCommitEmail       string `json:"gitCommitEmail" db:"git_commit_email" validate:"omitempty,required_if=GitEnabled true,email"`
+
+	// GitRemotes are additional remotes changes are pushed to after the primary remote, e.g. mirrors.
+	// They are stored separately from the workspace row, nil leaves the stored remotes unchanged on update.
+	// Their tokens are never sent to clients.
+	GitRemotes []GitRemote `json:"gitRemotes,omitempty" db:"-" validate:"excluded_unless=GitEnabled true,unique=Name,dive"`
+
+	// GitPinnedRef is a tag or commit the workspace is checked out at, the workspace is read-only while it is set
+	GitPinnedRef string `json:"gitPinnedRef" db:"git_pinned_ref" validate:"excluded_unless=GitEnabled true"`
+
+	// GitSigningKey is an armored GPG or OpenSSH private key commits are signed with, unsigned if empty.
+	// Keys protected by a passphrase are not supported. The key is never sent to clients.
+	GitSigningKey string `json:"-" db:"git_signing_key,ommitempty,encrypted" validate:"excluded_unless=GitEnabled true"`
+
+	// Save hooks applied to text files saved from the editor
+	NormalizeLineEndings   bool `json:"normalizeLineEndings" db:"normalize_line_endings"`
+	TrimTrailingWhitespace bool `json:"trimTrailingWhitespace" db:"trim_trailing_whitespace"`
+
+	// ResolveIncludes expands {{include: path}} directives when file content is read with includes resolved
+	ResolveIncludes bool `json:"resolveIncludes" db:"resolve_includes"`
+
+	// Markdown lint run when markdown files are saved. In strict mode files with issues are rejected,
+	// otherwise they are saved and the issues returned as warnings.
+	LintMarkdown bool `json:"lintMarkdown" db:"lint_markdown"`
+	LintStrict   bool `json:"lintStrict" db:"lint_strict"`
+	// LintRequiredFrontmatter is a comma-separated list of frontmatter fields every markdown file must set
+	LintRequiredFrontmatter string `json:"lintRequiredFrontmatter" db:"lint_required_frontmatter"`
+
+	// AccessLogging records who read or downloaded which file in the access log, for compliance
+	AccessLogging bool `json:"accessLogging" db:"access_logging"`
+}
+
+// GitRemote is an additional git remote of a workspace with its own credentials
+type GitRemote struct {
+	ID          int    `json:"-" db:"id,default"`
+	WorkspaceID int    `json:"-" db:"workspace_id"`
+	Name        string `json:"name" db:"name" validate:"required,max=64,excludesall= /,ne=origin"`
+	URL         string `json:"url" db:"url" validate:"required,url"`
+	User        string `json:"user" db:"git_user" validate:"required"`
+	Token       string `json:"-" db:"git_token,encrypted" validate:"required"`
 }
 
 // Validate validates the workspace struct
@@ -31,13 +77,85 @@ func (w *Workspace) Validate() error {
 	return validate.Struct(w)
 }
 
+// ErrMissingCommitIdentity is returned when auto-commit is enabled without a commit author name and email
+var ErrMissingCommitIdentity = errors.New("commit author name and email are required when auto-commit is enabled")
+
 // ValidateGitSettings validates the git settings if git is enabled
 func (w *Workspace) ValidateGitSettings() error {
-	return validate.StructExcept(w, "ID", "UserID", "Theme")
+	if err := validate.StructExcept(w, "ID", "UserID", "Theme"); err != nil {
+		return err
+	}
+	return w.ValidateCommitIdentity()
+}
+
+// ValidateCommitIdentity returns ErrMissingCommitIdentity if auto-commit is enabled
+// without a commit author name and email
+func (w *Workspace) ValidateCommitIdentity() error {
+	if !w.GitEnabled || !w.GitAutoCommit {
+		return nil
+	}
+	if strings.TrimSpace(w.GitCommitName) == "" || strings.TrimSpace(w.GitCommitEmail) == "" {
+		return ErrMissingCommitIdentity
+	}
+	return nil
+}
+
+// Warnings returns advisories for settings that are valid but likely not what the user wants
+func (w *Workspace) Warnings() []string {
+	warnings := []string{}
+
+	if w.GitAutoCommit && !w.GitEnabled {
+		warnings = append(warnings, "Auto-commit has no effect while git is disabled")
+	}
+
+	if !w.GitEnabled {
+		return warnings
+	}
+
+	if w.GitToken != "" && strings.HasPrefix(strings.ToLower(w.GitURL), "http://") {
+		warnings = append(warnings, "Git token is sent over an unencrypted connection, use an https:// remote")
+	}
+
+	if w.GitCommitName == "" || w.GitCommitEmail == "" {
+		warnings = append(warnings, "Commit author name or email is not set, commits will have an incomplete author")
+	}
+
+	if w.GitAutoCommit && !strings.Contains(w.GitCommitMsgTemplate, "${filename}") {
+		warnings = append(warnings, "Commit message template does not include ${filename}, auto-commits will not say which file changed")
+	}
+
+	return warnings
+}
+
+// WorkspaceNameNormalization decides how workspace names are cleaned up and compared for uniqueness
+type WorkspaceNameNormalization string
+
+const (
+	// WorkspaceNamesUnchanged keeps names as entered and allows duplicate names
+	WorkspaceNamesUnchanged WorkspaceNameNormalization = "none"
+	// WorkspaceNamesTrimmed trims names and collapses runs of whitespace, the result must be unique per user
+	WorkspaceNamesTrimmed WorkspaceNameNormalization = "whitespace"
+	// WorkspaceNamesCaseInsensitive is WorkspaceNamesTrimmed with names compared ignoring case
+	WorkspaceNamesCaseInsensitive WorkspaceNameNormalization = "lowercase"
+)
+
+// NormalizeName cleans up the name of the workspace according to n and sets the normalized name.
+// The display name keeps its case, only the normalized name is lowercased.
+func (w *Workspace) NormalizeName(n WorkspaceNameNormalization) {
+	if n == WorkspaceNamesTrimmed || n == WorkspaceNamesCaseInsensitive {
+		w.Name = strings.Join(strings.Fields(w.Name), " ")
+	}
+	w.NormalizedName = w.Name
+	if n == WorkspaceNamesCaseInsensitive {
+		w.NormalizedName = strings.ToLower(w.Name)
+	}
 }
 
 // SetDefaultSettings sets the default settings for the workspace
 func (w *Workspace) SetDefaultSettings() {
+	if w.NormalizedName == "" {
+		w.NormalizedName = w.Name
+	}
 
 	if w.Theme == "" {
 		w.Theme = "dark"
@@ -53,3 +171,104 @@ func (w *Workspace) SetDefaultSettings() {
 		w.GitCommitMsgTemplate = "${action} ${filename}"
 	}
 }
+
+// WorkspaceSettingsPatch is a partial update of workspace settings applied to many workspaces at once.
+// Nil fields are left unchanged. Git credentials and the remote are not part of the patch,
+// so git can only be disabled in bulk.
+type WorkspaceSettingsPatch struct {
+	Theme                  *string `json:"theme,omitempty" validate:"omitempty,oneof=light dark"`
+	AutoSave               *bool   `json:"autoSave,omitempty"`
+	ShowHiddenFiles        *bool   `json:"showHiddenFiles,omitempty"`
+	GitEnabled             *bool   `json:"gitEnabled,omitempty"`
+	GitAutoCommit          *bool   `json:"gitAutoCommit,omitempty"`
+	GitCommitMsgTemplate   *string `json:"gitCommitMsgTemplate,omitempty"`
+	NormalizeLineEndings   *bool   `json:"normalizeLineEndings,omitempty"`
+	TrimTrailingWhitespace *bool   `json:"trimTrailingWhitespace,omitempty"`
+	ResolveIncludes        *bool   `json:"resolveIncludes,omitempty"`
+	LintMarkdown           *bool   `json:"lintMarkdown,omitempty"`
+	LintStrict             *bool   `json:"lintStrict,omitempty"`
+	AccessLogging          *bool   `json:"accessLogging,omitempty"`
+}
+
+// Validate validates the settings patch
+func (p *WorkspaceSettingsPatch) Validate() error {
+	if err := validate.Struct(p); err != nil {
+		return err
+	}
+
+	if p.GitEnabled != nil && *p.GitEnabled {
+		return fmt.Errorf("git cannot be enabled in bulk, it requires credentials for each workspace")
+	}
+
+	if p.GitEnabled != nil && p.GitAutoCommit != nil && *p.GitAutoCommit {
+		return fmt.Errorf("auto-commit cannot be enabled while disabling git")
+	}
+
+	return nil
+}
+
+// IsEmpty reports whether the patch changes no settings
+func (p *WorkspaceSettingsPatch) IsEmpty() bool {
+	return len(p.Apply(&Workspace{})) == 0
+}
+
+// Apply applies the patch to a workspace and returns the db columns of the changed settings.
+// Disabling git also disables auto-commit and unpins the workspace.
+func (p *WorkspaceSettingsPatch) Apply(w *Workspace) []string {
+	var columns []string
+
+	if p.Theme != nil {
+		w.Theme = *p.Theme
+		columns = append(columns, "theme")
+	}
+	if p.AutoSave != nil {
+		w.AutoSave = *p.AutoSave
+		columns = append(columns, "auto_save")
+	}
+	if p.ShowHiddenFiles != nil {
+		w.ShowHiddenFiles = *p.ShowHiddenFiles
+		columns = append(columns, "show_hidden_files")
+	}
+	if p.GitEnabled != nil {
+		w.GitEnabled = *p.GitEnabled
+		columns = append(columns, "git_enabled")
+	}
+	if p.GitEnabled != nil && !*p.GitEnabled && w.GitPinnedRef != "" {
+		w.GitPinnedRef = ""
+		columns = append(columns, "git_pinned_ref")
+	}
+	if p.GitAutoCommit != nil || (p.GitEnabled != nil && !*p.GitEnabled) {
+		w.GitAutoCommit = p.GitAutoCommit != nil && *p.GitAutoCommit && w.GitEnabled
+		columns = append(columns, "git_auto_commit")
+	}
+	if p.GitCommitMsgTemplate != nil {
+		w.GitCommitMsgTemplate = *p.GitCommitMsgTemplate
+		columns = append(columns, "git_commit_msg_template")
+	}
+	if p.NormalizeLineEndings != nil {
+		w.NormalizeLineEndings = *p.NormalizeLineEndings
+		columns = append(columns, "normalize_line_endings")
+	}
+	if p.TrimTrailingWhitespace != nil {
+		w.TrimTrailingWhitespace = *p.TrimTrailingWhitespace
+		columns = append(columns, "trim_trailing_whitespace")
+	}
+	if p.ResolveIncludes != nil {
+		w.ResolveIncludes = *p.ResolveIncludes
+		columns = append(columns, "resolve_includes")
+	}
+	if p.LintMarkdown != nil {
+		w.LintMarkdown = *p.LintMarkdown
+		columns = append(columns, "lint_markdown")
+	}
+	if p.LintStrict != nil {
+		w.LintStrict = *p.LintStrict
+		columns = append(columns, "lint_strict")
+	}
+	if p.AccessLogging != nil {
+		w.AccessLogging = *p.AccessLogging
+		columns = append(columns, "access_logging")
+	}
+
+	return columns
+}
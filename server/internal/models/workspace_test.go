@@ -0,0 +1,169 @@
+package models_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"lemma/internal/models"
+)
+
+func TestWorkspaceWarnings(t *testing.T) {
+	gitWorkspace := func() *models.Workspace {
+		return &models.Workspace{
+			GitEnabled:           true,
+			GitURL:               "https://github.com/test/repo.git",
+			GitUser:              "user",
+			GitToken:             "token",
+			GitAutoCommit:        true,
+			GitCommitMsgTemplate: "${action} ${filename}",
+			GitCommitName:        "Test User",
+			GitCommitEmail:       "test@example.com",
+		}
+	}
+
+	tests := []struct {
+		name      string
+		workspace func() *models.Workspace
+		want      string
+	}{
+		{
+			name: "auto-commit without git",
+			workspace: func() *models.Workspace {
+				return &models.Workspace{GitAutoCommit: true}
+			},
+			want: "Auto-commit has no effect",
+		},
+		{
+			name: "token over http",
+			workspace: func() *models.Workspace {
+				w := gitWorkspace()
+				w.GitURL = "HTTP://git.example.com/repo.git"
+				return w
+			},
+			want: "unencrypted connection",
+		},
+		{
+			name: "missing commit author",
+			workspace: func() *models.Workspace {
+				w := gitWorkspace()
+				w.GitCommitEmail = ""
+				return w
+			},
+			want: "Commit author",
+		},
+		{
+			name: "template without filename",
+			workspace: func() *models.Workspace {
+				w := gitWorkspace()
+				w.GitCommitMsgTemplate = "Update notes"
+				return w
+			},
+			want: "${filename}",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			warnings := tc.workspace().Warnings()
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tc.want) {
+				t.Errorf("Warnings() = %v, want single warning containing %q", warnings, tc.want)
+			}
+		})
+	}
+
+	t.Run("no warnings", func(t *testing.T) {
+		for _, w := range []*models.Workspace{{}, gitWorkspace()} {
+			if warnings := w.Warnings(); warnings == nil || len(warnings) != 0 {
+				t.Errorf("Warnings() = %#v, want empty slice", warnings)
+			}
+		}
+	})
+}
+
+func TestValidateGitSettingsCommitIdentity(t *testing.T) {
+	gitWorkspace := func() *models.Workspace {
+		return &models.Workspace{
+			Name:           "Notes",
+			GitEnabled:     true,
+			GitURL:         "https://github.com/test/repo.git",
+			GitUser:        "user",
+			GitToken:       "token",
+			GitAutoCommit:  true,
+			GitCommitName:  "Test User",
+			GitCommitEmail: "test@example.com",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(w *models.Workspace)
+		wantErr bool
+	}{
+		{name: "identity set", modify: func(w *models.Workspace) {}},
+		{name: "missing name", modify: func(w *models.Workspace) { w.GitCommitName = "" }, wantErr: true},
+		{name: "blank name", modify: func(w *models.Workspace) { w.GitCommitName = "  " }, wantErr: true},
+		{name: "missing email", modify: func(w *models.Workspace) { w.GitCommitEmail = "" }, wantErr: true},
+		{
+			name: "auto-commit disabled",
+			modify: func(w *models.Workspace) {
+				w.GitAutoCommit = false
+				w.GitCommitName = ""
+				w.GitCommitEmail = ""
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := gitWorkspace()
+			tc.modify(w)
+			err := w.ValidateGitSettings()
+			if tc.wantErr {
+				if !errors.Is(err, models.ErrMissingCommitIdentity) {
+					t.Errorf("ValidateGitSettings() = %v, want %v", err, models.ErrMissingCommitIdentity)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ValidateGitSettings() = %v, want nil", err)
+			}
+		})
+	}
+
+	t.Run("git disabled", func(t *testing.T) {
+		w := &models.Workspace{GitAutoCommit: true}
+		if err := w.ValidateCommitIdentity(); err != nil {
+			t.Errorf("ValidateCommitIdentity() = %v, want nil", err)
+		}
+	})
+}
+
+func TestWorkspaceNormalizeName(t *testing.T) {
+	tests := []struct {
+		name           string
+		normalization  models.WorkspaceNameNormalization
+		input          string
+		wantName       string
+		wantNormalized string
+	}{
+		{"unchanged", models.WorkspaceNamesUnchanged, "  My   Notes ", "  My   Notes ", "  My   Notes "},
+		{"not configured", "", "My Notes ", "My Notes ", "My Notes "},
+		{"whitespace", models.WorkspaceNamesTrimmed, " My \t Notes  ", "My Notes", "My Notes"},
+		{"lowercase keeps display name", models.WorkspaceNamesCaseInsensitive, " My  Notes", "My Notes", "my notes"},
+		{"only whitespace", models.WorkspaceNamesTrimmed, "   ", "", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := &models.Workspace{Name: tc.input}
+			w.NormalizeName(tc.normalization)
+			if w.Name != tc.wantName {
+				t.Errorf("Name = %q, want %q", w.Name, tc.wantName)
+			}
+			if w.NormalizedName != tc.wantNormalized {
+				t.Errorf("NormalizedName = %q, want %q", w.NormalizedName, tc.wantNormalized)
+			}
+		})
+	}
+}
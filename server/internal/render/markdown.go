@@ -0,0 +1,120 @@
+// Package render converts markdown to HTML that is safe to embed in a page
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// ErrBinaryContent is returned when the content to render does not look like text
+var ErrBinaryContent = errors.New("content is not text")
+
+// DefaultAllowedElements are the HTML elements kept in rendered markdown if no allowlist is configured.
+// They cover everything CommonMark and the GFM extensions produce.
+var DefaultAllowedElements = []string{
+	"p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6",
+	"blockquote", "pre", "code", "em", "strong", "del",
+	"a", "img", "ul", "ol", "li", "input",
+	"table", "thead", "tbody", "tr", "th", "td",
+}
+
+// Attributes kept on allowed elements, everything else is stripped
+var (
+	languageClass = regexp.MustCompile(`^language-[\w.+#-]+$`)
+	cellAlignment = regexp.MustCompile(`^(left|center|right)$`)
+	checkboxType  = regexp.MustCompile(`^checkbox$`)
+	listStart     = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// allowAttributes adds the attributes element may keep to policy
+func allowAttributes(policy *bluemonday.Policy, element string) {
+	switch element {
+	case "a":
+		policy.AllowAttrs("href", "title").OnElements("a")
+	case "img":
+		policy.AllowAttrs("src", "alt", "title").OnElements("img")
+	case "code":
+		policy.AllowAttrs("class").Matching(languageClass).OnElements("code")
+	case "input":
+		// Task list items, the only inputs markdown produces
+		policy.AllowAttrs("type").Matching(checkboxType).OnElements("input")
+		policy.AllowAttrs("checked", "disabled").OnElements("input")
+	case "th", "td":
+		policy.AllowAttrs("align").Matching(cellAlignment).OnElements(element)
+	case "ol":
+		policy.AllowAttrs("start").Matching(listStart).OnElements("ol")
+	}
+}
+
+// Options configures a Renderer
+type Options struct {
+	// AllowedElements are the HTML elements kept in the output, any other element is removed.
+	// Defaults to DefaultAllowedElements.
+	AllowedElements []string
+}
+
+// Renderer converts markdown to sanitized HTML. It is safe for concurrent use.
+type Renderer struct {
+	markdown goldmark.Markdown
+	policy   *bluemonday.Policy
+}
+
+// New creates a renderer keeping only the elements allowed by options
+func New(options Options) *Renderer {
+	allowed := options.AllowedElements
+	if len(allowed) == 0 {
+		allowed = DefaultAllowedElements
+	}
+
+	policy := bluemonday.NewPolicy()
+	policy.AllowStandardURLs()
+	policy.AllowRelativeURLs(true)
+	policy.AllowElements(allowed...)
+	for _, element := range allowed {
+		allowAttributes(policy, element)
+	}
+
+	return &Renderer{
+		// Raw HTML is passed through to the sanitizer so allowlisted elements can be used in markdown
+		markdown: goldmark.New(
+			goldmark.WithExtensions(
+				extension.Strikethrough,
+				extension.Linkify,
+				extension.TaskList,
+				extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+			),
+			goldmark.WithRendererOptions(html.WithUnsafe()),
+		),
+		policy: policy,
+	}
+}
+
+// RenderMarkdown converts CommonMark with GFM tables, task lists, strikethrough and autolinks to HTML
+// and removes all elements and attributes that are not allowed
+func (r *Renderer) RenderMarkdown(src []byte) ([]byte, error) {
+	if bytes.IndexByte(src, 0) != -1 || !utf8.Valid(src) {
+		return nil, ErrBinaryContent
+	}
+
+	var buf bytes.Buffer
+	if err := r.markdown.Convert(src, &buf); err != nil {
+		return nil, fmt.Errorf("failed to render markdown: %w", err)
+	}
+
+	return r.policy.SanitizeBytes(buf.Bytes()), nil
+}
+
+var defaultRenderer = New(Options{})
+
+// RenderMarkdown converts markdown to HTML keeping only the DefaultAllowedElements
+func RenderMarkdown(src []byte) ([]byte, error) {
+	return defaultRenderer.RenderMarkdown(src)
+}
@@ -0,0 +1,115 @@
+package render_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"lemma/internal/render"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	testCases := []struct {
+		name     string
+		markdown string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "headings",
+			markdown: "# Title\n\n## Section\n\n###### Deep",
+			contains: []string{"<h1>Title</h1>", "<h2>Section</h2>", "<h6>Deep</h6>"},
+		},
+		{
+			name:     "links",
+			markdown: "[relative](notes/other.md) [absolute](https://example.com \"Example\") https://example.org",
+			contains: []string{
+				`<a href="notes/other.md"`,
+				`<a href="https://example.com" title="Example"`,
+				`<a href="https://example.org"`,
+			},
+		},
+		{
+			name:     "javascript links",
+			markdown: "[click](javascript:alert(1))",
+			contains: []string{"click"},
+			excludes: []string{"javascript", "<a"},
+		},
+		{
+			name:     "code fences",
+			markdown: "```go\nif a < b {\n}\n```",
+			contains: []string{`<pre><code class="language-go">if a &lt; b {`},
+		},
+		{
+			name:     "tables",
+			markdown: "| Name | Count |\n|:-----|------:|\n| a | 1 |",
+			contains: []string{"<table>", `<th align="left">Name</th>`, `<td align="right">1</td>`},
+		},
+		{
+			name:     "task lists",
+			markdown: "- [ ] open\n- [x] done",
+			contains: []string{
+				`<li><input disabled="" type="checkbox"> open</li>`,
+				`<li><input checked="" disabled="" type="checkbox"> done</li>`,
+			},
+		},
+		{
+			name:     "script is stripped",
+			markdown: "before\n\n<script>alert(1)</script>\n\nafter <script>alert(2)</script>",
+			contains: []string{"<p>before</p>", "after"},
+			excludes: []string{"<script", "alert"},
+		},
+		{
+			name:     "event handlers are stripped",
+			markdown: `<img src="x.png" onerror="alert(1)"> <a href="/x" onclick="alert(2)">x</a>`,
+			contains: []string{`<img src="x.png">`, `<a href="/x"`},
+			excludes: []string{"onerror", "onclick", "alert"},
+		},
+		{
+			name:     "elements outside the allowlist",
+			markdown: `<iframe src="https://example.com"></iframe><div style="color: red">text</div>`,
+			contains: []string{"text"},
+			excludes: []string{"<iframe", "<div", "style"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			html, err := render.RenderMarkdown([]byte(tc.markdown))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tc.contains {
+				if !strings.Contains(string(html), want) {
+					t.Errorf("RenderMarkdown() = %q, want it to contain %q", html, want)
+				}
+			}
+			for _, unwanted := range tc.excludes {
+				if strings.Contains(string(html), unwanted) {
+					t.Errorf("RenderMarkdown() = %q, want it not to contain %q", html, unwanted)
+				}
+			}
+		})
+	}
+
+	t.Run("binary content", func(t *testing.T) {
+		_, err := render.RenderMarkdown([]byte("text\x00binary"))
+		if !errors.Is(err, render.ErrBinaryContent) {
+			t.Errorf("error = %v, want %v", err, render.ErrBinaryContent)
+		}
+	})
+}
+
+func TestRendererAllowedElements(t *testing.T) {
+	renderer := render.New(render.Options{AllowedElements: []string{"p", "kbd"}})
+
+	html, err := renderer.RenderMarkdown([]byte("# Title\n\nPress <kbd>Ctrl</kbd> and [read](https://example.com)"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "Title\n<p>Press <kbd>Ctrl</kbd> and read</p>\n"
+	if string(html) != want {
+		t.Errorf("RenderMarkdown() = %q, want %q", html, want)
+	}
+}
@@ -0,0 +1,303 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// IndexManager provides functionalities to rebuild caches derived from workspace files.
+type IndexManager interface {
+	Reindex(userID, workspaceID int) (*ReindexResult, error)
+	StartCacheRefresh(interval time.Duration)
+	StopCacheRefresh()
+}
+
+// cacheBuilder is a cache derived from the files of a workspace.
+// Caches are invalidated by writes made through the storage service, and can be
+// rebuilt from disk to pick up changes made outside of the application.
+type cacheBuilder interface {
+	name() string
+	rebuild(userID, workspaceID int) error
+	invalidate(userID, workspaceID int)
+}
+
+// CacheTiming holds the time it took to rebuild a single cache
+type CacheTiming struct {
+	Name       string `json:"name"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// ReindexResult holds the timing of a workspace reindex
+type ReindexResult struct {
+	UserID      int           `json:"userID"`
+	WorkspaceID int           `json:"workspaceID"`
+	Caches      []CacheTiming `json:"caches"`
+	DurationMs  int64         `json:"durationMs"`
+}
+
+// Reindex rebuilds all caches of the workspace given by userID and workspaceID from disk.
+func (s *Service) Reindex(userID, workspaceID int) (*ReindexResult, error) {
+	log := getLogger()
+
+	workspacePath := s.GetWorkspacePath(userID, workspaceID)
+	if _, err := s.fs.Stat(workspacePath); s.fs.IsNotExist(err) {
+		return nil, fmt.Errorf("workspace directory does not exist")
+	}
+
+	result := &ReindexResult{
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Caches:      make([]CacheTiming, 0, len(s.caches)),
+	}
+
+	start := time.Now()
+	for _, cache := range s.caches {
+		cacheStart := time.Now()
+		if err := cache.rebuild(userID, workspaceID); err != nil {
+			return nil, fmt.Errorf("failed to rebuild %s cache: %w", cache.name(), err)
+		}
+		result.Caches = append(result.Caches, CacheTiming{
+			Name:       cache.name(),
+			DurationMs: time.Since(cacheStart).Milliseconds(),
+		})
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	log.Debug("workspace reindexed",
+		"userID", userID,
+		"workspaceID", workspaceID,
+		"durationMs", result.DurationMs)
+	return result, nil
+}
+
+// invalidateCaches drops the cached data of the workspace after its files changed
+func (s *Service) invalidateCaches(userID, workspaceID int) {
+	for _, cache := range s.caches {
+		cache.invalidate(userID, workspaceID)
+	}
+}
+
+// fileChanged updates the caches after a single file of the workspace was written or removed.
+// The file stats are adjusted by the given deltas instead of being recounted, other caches are invalidated.
+func (s *Service) fileChanged(userID, workspaceID int, files int, size int64) {
+	for _, cache := range s.caches {
+		if cache != cacheBuilder(s.fileStats) {
+			cache.invalidate(userID, workspaceID)
+		}
+	}
+	s.fileStats.adjust(userID, workspaceID, files, size)
+}
+
+// StartCacheRefresh recomputes the cached file statistics every interval
+// until StopCacheRefresh is called. Calling it again while running has no effect.
+func (s *Service) StartCacheRefresh(interval time.Duration) {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	if s.stopRefresh != nil {
+		return
+	}
+	stop := make(chan struct{})
+	s.stopRefresh = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.fileStats.refresh()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	getLogger().Debug("cache refresh started", "interval", interval.String())
+}
+
+// StopCacheRefresh stops the periodic refresh started by StartCacheRefresh
+func (s *Service) StopCacheRefresh() {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	if s.stopRefresh == nil {
+		return
+	}
+	close(s.stopRefresh)
+	s.stopRefresh = nil
+}
+
+// workspaceKey identifies a workspace in the caches
+type workspaceKey struct {
+	userID      int
+	workspaceID int
+}
+
+// fileStatsCache caches the file count statistics of workspaces and of the whole storage.
+// Invalidating a workspace also invalidates the total, as it includes the workspace.
+type fileStatsCache struct {
+	mu    sync.RWMutex
+	stats map[workspaceKey]*FileCountStats
+	total *FileCountStats
+	// gen is incremented on each invalidation so that stats computed
+	// concurrently with a write are not stored
+	gen uint64
+
+	count      func(userID, workspaceID int) (*FileCountStats, error)
+	countTotal func() (*FileCountStats, error)
+}
+
+func newFileStatsCache(
+	count func(userID, workspaceID int) (*FileCountStats, error),
+	countTotal func() (*FileCountStats, error),
+) *fileStatsCache {
+	return &fileStatsCache{
+		stats:      make(map[workspaceKey]*FileCountStats),
+		count:      count,
+		countTotal: countTotal,
+	}
+}
+
+func (c *fileStatsCache) name() string {
+	return "fileStats"
+}
+
+// get returns the cached stats of the workspace, computing them if not cached or fresh is set
+func (c *fileStatsCache) get(userID, workspaceID int, fresh bool) (*FileCountStats, error) {
+	c.mu.RLock()
+	stats, ok := c.stats[workspaceKey{userID, workspaceID}]
+	c.mu.RUnlock()
+
+	if !ok || fresh {
+		var err error
+		if stats, err = c.load(userID, workspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	copied := *stats
+	return &copied, nil
+}
+
+// getTotal returns the cached stats of the whole storage, computing them if not cached or fresh is set
+func (c *fileStatsCache) getTotal(fresh bool) (*FileCountStats, error) {
+	c.mu.RLock()
+	stats := c.total
+	c.mu.RUnlock()
+
+	if stats == nil || fresh {
+		var err error
+		if stats, err = c.loadTotal(); err != nil {
+			return nil, err
+		}
+	}
+
+	copied := *stats
+	return &copied, nil
+}
+
+func (c *fileStatsCache) rebuild(userID, workspaceID int) error {
+	c.mu.Lock()
+	c.total = nil
+	c.mu.Unlock()
+
+	_, err := c.load(userID, workspaceID)
+	return err
+}
+
+// load computes the stats of the workspace and stores them in the cache
+func (c *fileStatsCache) load(userID, workspaceID int) (*FileCountStats, error) {
+	c.mu.RLock()
+	gen := c.gen
+	c.mu.RUnlock()
+
+	stats, err := c.count(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	stats.CachedAt = time.Now().UTC()
+
+	c.mu.Lock()
+	if c.gen == gen {
+		c.stats[workspaceKey{userID, workspaceID}] = stats
+	}
+	c.mu.Unlock()
+	return stats, nil
+}
+
+// loadTotal computes the stats of the whole storage and stores them in the cache
+func (c *fileStatsCache) loadTotal() (*FileCountStats, error) {
+	c.mu.RLock()
+	gen := c.gen
+	c.mu.RUnlock()
+
+	stats, err := c.countTotal()
+	if err != nil {
+		return nil, err
+	}
+	stats.CachedAt = time.Now().UTC()
+
+	c.mu.Lock()
+	if c.gen == gen {
+		c.total = stats
+	}
+	c.mu.Unlock()
+	return stats, nil
+}
+
+// refresh recomputes all cached stats
+func (c *fileStatsCache) refresh() {
+	c.mu.RLock()
+	keys := make([]workspaceKey, 0, len(c.stats))
+	for key := range c.stats {
+		keys = append(keys, key)
+	}
+	hasTotal := c.total != nil
+	c.mu.RUnlock()
+
+	for _, key := range keys {
+		if _, err := c.load(key.userID, key.workspaceID); err != nil {
+			// The workspace is likely gone, stop caching it
+			c.invalidate(key.userID, key.workspaceID)
+		}
+	}
+	if hasTotal {
+		if _, err := c.loadTotal(); err != nil {
+			getLogger().Warn("failed to refresh total file stats", "error", err.Error())
+		}
+	}
+}
+
+// adjust applies a change in the number and size of files to the cached stats of the workspace
+// and the total. Stats that are not cached are left to be computed on the next read.
+func (c *fileStatsCache) adjust(userID, workspaceID int, files int, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if stats, ok := c.stats[workspaceKey{userID, workspaceID}]; ok {
+		c.stats[workspaceKey{userID, workspaceID}] = applyDelta(stats, files, size)
+	}
+	if c.total != nil {
+		c.total = applyDelta(c.total, files, size)
+	}
+	// A count started before the change may or may not include it, so it must not be stored
+	c.gen++
+}
+
+// applyDelta returns a copy of stats with the delta applied, as callers may hold the previous value
+func applyDelta(stats *FileCountStats, files int, size int64) *FileCountStats {
+	adjusted := *stats
+	adjusted.TotalFiles += files
+	adjusted.TotalSize += size
+	return &adjusted
+}
+
+func (c *fileStatsCache) invalidate(userID, workspaceID int) {
+	c.mu.Lock()
+	delete(c.stats, workspaceKey{userID, workspaceID})
+	c.total = nil
+	c.gen++
+	c.mu.Unlock()
+}
@@ -0,0 +1,204 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+// writeExternal writes a file into the workspace bypassing the storage service
+func writeExternal(t *testing.T, s *storage.Service, userID, workspaceID int, name string) {
+	t.Helper()
+	path := filepath.Join(s.GetWorkspacePath(userID, workspaceID), name)
+	if err := os.WriteFile(path, []byte("external"), 0644); err != nil {
+		t.Fatalf("failed to write external file: %v", err)
+	}
+}
+
+func TestFileStatsCache(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	getStats := func(fresh bool) *storage.FileCountStats {
+		t.Helper()
+		stats, err := s.GetFileStats(1, 1, fresh)
+		if err != nil {
+			t.Fatalf("failed to get file stats: %v", err)
+		}
+		return stats
+	}
+
+	getTotal := func(fresh bool) *storage.FileCountStats {
+		t.Helper()
+		stats, err := s.GetTotalFileStats(fresh)
+		if err != nil {
+			t.Fatalf("failed to get total file stats: %v", err)
+		}
+		return stats
+	}
+
+	initial := getStats(false)
+	if initial.TotalFiles != 0 {
+		t.Fatalf("TotalFiles = %d, want 0", initial.TotalFiles)
+	}
+	if initial.CachedAt.IsZero() {
+		t.Error("CachedAt is not set")
+	}
+	if getTotal(false).TotalFiles != 0 {
+		t.Fatal("expected empty total stats")
+	}
+
+	t.Run("served from cache", func(t *testing.T) {
+		writeExternal(t, s, 1, 1, "external.md")
+
+		stats := getStats(false)
+		if stats.TotalFiles != 0 {
+			t.Errorf("TotalFiles = %d, want cached 0", stats.TotalFiles)
+		}
+		if !stats.CachedAt.Equal(initial.CachedAt) {
+			t.Errorf("CachedAt = %v, want %v", stats.CachedAt, initial.CachedAt)
+		}
+		if total := getTotal(false); total.TotalFiles != 0 {
+			t.Errorf("total TotalFiles = %d, want cached 0", total.TotalFiles)
+		}
+	})
+
+	t.Run("fresh recomputes", func(t *testing.T) {
+		if stats := getStats(true); stats.TotalFiles != 1 {
+			t.Errorf("TotalFiles = %d, want 1", stats.TotalFiles)
+		}
+		if total := getTotal(true); total.TotalFiles != 1 {
+			t.Errorf("total TotalFiles = %d, want 1", total.TotalFiles)
+		}
+	})
+
+	t.Run("save adjusts", func(t *testing.T) {
+		if err := s.SaveFile(1, 1, "saved.md", []byte("saved")); err != nil {
+			t.Fatalf("failed to save file: %v", err)
+		}
+		if stats := getStats(false); stats.TotalFiles != 2 {
+			t.Errorf("TotalFiles = %d, want 2", stats.TotalFiles)
+		}
+		if total := getTotal(false); total.TotalFiles != 2 {
+			t.Errorf("total TotalFiles = %d, want 2", total.TotalFiles)
+		}
+	})
+
+	t.Run("delete adjusts", func(t *testing.T) {
+		if err := s.DeleteFile(1, 1, "saved.md"); err != nil {
+			t.Fatalf("failed to delete file: %v", err)
+		}
+		if stats := getStats(false); stats.TotalFiles != 1 {
+			t.Errorf("TotalFiles = %d, want 1", stats.TotalFiles)
+		}
+		if total := getTotal(false); total.TotalFiles != 1 {
+			t.Errorf("total TotalFiles = %d, want 1", total.TotalFiles)
+		}
+	})
+
+	t.Run("reindex rebuilds", func(t *testing.T) {
+		writeExternal(t, s, 1, 1, "reindexed.md")
+
+		result, err := s.Reindex(1, 1)
+		if err != nil {
+			t.Fatalf("failed to reindex: %v", err)
+		}
+		if len(result.Caches) != 1 || result.Caches[0].Name != "fileStats" {
+			t.Errorf("Caches = %+v, want fileStats timing", result.Caches)
+		}
+		if stats := getStats(false); stats.TotalFiles != 2 {
+			t.Errorf("TotalFiles = %d, want 2", stats.TotalFiles)
+		}
+	})
+
+	t.Run("refreshed on timer", func(t *testing.T) {
+		writeExternal(t, s, 1, 1, "timer.md")
+
+		s.StartCacheRefresh(10 * time.Millisecond)
+		defer s.StopCacheRefresh()
+
+		deadline := time.Now().Add(2 * time.Second)
+		for getStats(false).TotalFiles != 3 {
+			if time.Now().After(deadline) {
+				t.Fatal("cached stats were not refreshed")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
+
+func TestFileStatsDeltas(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	for _, workspaceID := range []int{1, 2} {
+		if err := s.InitializeUserWorkspace(1, workspaceID); err != nil {
+			t.Fatalf("failed to initialize workspace: %v", err)
+		}
+	}
+
+	// Prime the caches so that the following writes are applied as deltas
+	if _, err := s.GetFileStats(1, 1, false); err != nil {
+		t.Fatalf("failed to get file stats: %v", err)
+	}
+	if _, err := s.GetTotalFileStats(false); err != nil {
+		t.Fatalf("failed to get total file stats: %v", err)
+	}
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"create", func() error { return s.SaveFile(1, 1, "a.md", []byte("hello")) }},
+		{"create nested", func() error { return s.SaveFile(1, 1, "notes/b.md", []byte("nested file")) }},
+		{"grow", func() error { return s.SaveFile(1, 1, "a.md", []byte("hello, world")) }},
+		{"shrink", func() error { return s.SaveFile(1, 1, "notes/b.md", []byte("b")) }},
+		{"other workspace", func() error { return s.SaveFile(1, 2, "c.md", []byte("other")) }},
+		{"delete", func() error { return s.DeleteFile(1, 1, "a.md") }},
+		{"delete other workspace", func() error { return s.DeleteFile(1, 2, "c.md") }},
+	}
+
+	var lastCount time.Time
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+
+		cached, err := s.GetFileStats(1, 1, false)
+		if err != nil {
+			t.Fatalf("%s: failed to get file stats: %v", step.name, err)
+		}
+		cachedTotal, err := s.GetTotalFileStats(false)
+		if err != nil {
+			t.Fatalf("%s: failed to get total file stats: %v", step.name, err)
+		}
+
+		recounted, err := s.GetFileStats(1, 1, true)
+		if err != nil {
+			t.Fatalf("%s: failed to recount file stats: %v", step.name, err)
+		}
+		recountedTotal, err := s.GetTotalFileStats(true)
+		if err != nil {
+			t.Fatalf("%s: failed to recount total file stats: %v", step.name, err)
+		}
+
+		// The cached stats must have been adjusted, not recounted on read
+		if !lastCount.IsZero() && !cached.CachedAt.Equal(lastCount) {
+			t.Errorf("%s: stats were recounted instead of adjusted", step.name)
+		}
+		lastCount = recounted.CachedAt
+
+		if cached.TotalFiles != recounted.TotalFiles || cached.TotalSize != recounted.TotalSize {
+			t.Errorf("%s: cached stats = %d files/%d bytes, recount = %d files/%d bytes", step.name,
+				cached.TotalFiles, cached.TotalSize, recounted.TotalFiles, recounted.TotalSize)
+		}
+		if cachedTotal.TotalFiles != recountedTotal.TotalFiles || cachedTotal.TotalSize != recountedTotal.TotalSize {
+			t.Errorf("%s: cached total = %d files/%d bytes, recount = %d files/%d bytes", step.name,
+				cachedTotal.TotalFiles, cachedTotal.TotalSize, recountedTotal.TotalFiles, recountedTotal.TotalSize)
+		}
+	}
+}
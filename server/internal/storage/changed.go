@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"sort"
+	"time"
+)
+
+// ChangedFile represents a file modified after a point in time
+type ChangedFile struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// ListChangedFiles returns the files of the workspace modified after since, ordered by path.
+// The .git directory is skipped, as are symlinks unless following symlinks is enabled.
+func (s *Service) ListChangedFiles(userID, workspaceID int, since time.Time) ([]ChangedFile, error) {
+	workspacePath := s.GetWorkspacePath(userID, workspaceID)
+
+	changed := []ChangedFile{}
+	err := s.walkFiles(workspacePath, func(entry walkEntry) error {
+		info, err := entry.Info()
+		if s.fs.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(since) {
+			changed = append(changed, ChangedFile{
+				Path:    entry.path,
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].Path < changed[j].Path
+	})
+	return changed, nil
+}
@@ -0,0 +1,75 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestListChangedFiles(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+	workspacePath := s.GetWorkspacePath(1, 1)
+
+	files := []string{"a.md", "b.md", "notes/c.md", "notes/d.md", ".git/HEAD"}
+	for _, path := range files {
+		if err := s.SaveFile(1, 1, path, []byte("original")); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+
+	// All files were created before the recorded timestamp
+	past := time.Now().Add(-time.Hour)
+	for _, path := range files {
+		if err := os.Chtimes(filepath.Join(workspacePath, path), past, past); err != nil {
+			t.Fatalf("failed to set modification time of %s: %v", path, err)
+		}
+	}
+	since := time.Now().Add(-time.Minute)
+
+	for _, path := range []string{"b.md", "notes/d.md", ".git/HEAD"} {
+		if err := s.SaveFile(1, 1, path, []byte("modified")); err != nil {
+			t.Fatalf("failed to modify %s: %v", path, err)
+		}
+	}
+	if err := s.SaveFile(1, 1, "notes/new.md", []byte("new")); err != nil {
+		t.Fatalf("failed to save new file: %v", err)
+	}
+
+	changed, err := s.ListChangedFiles(1, 1, since)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"b.md", filepath.Join("notes", "d.md"), filepath.Join("notes", "new.md")}
+	if len(changed) != len(want) {
+		t.Fatalf("ListChangedFiles returned %d files, want %d: %v", len(changed), len(want), changed)
+	}
+	for i, file := range changed {
+		if file.Path != want[i] {
+			t.Errorf("changed[%d].Path = %q, want %q", i, file.Path, want[i])
+		}
+		if !file.ModTime.After(since) {
+			t.Errorf("changed[%d].ModTime = %v, want after %v", i, file.ModTime, since)
+		}
+	}
+	if changed[0].Size != int64(len("modified")) {
+		t.Errorf("changed[0].Size = %d, want %d", changed[0].Size, len("modified"))
+	}
+
+	t.Run("nothing changed", func(t *testing.T) {
+		changed, err := s.ListChangedFiles(1, 1, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(changed) != 0 {
+			t.Errorf("expected no changed files, got %v", changed)
+		}
+	})
+}
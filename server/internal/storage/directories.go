@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// directoryRelPath validates dirPath and returns its full path and the path relative to the workspace directory.
+// The workspace root and the .git directory are rejected, they cannot be created or deleted.
+func (s *Service) directoryRelPath(userID, workspaceID int, dirPath string) (string, string, error) {
+	fullPath, err := s.ValidatePath(userID, workspaceID, dirPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	relPath, err := filepath.Rel(s.GetWorkspacePath(userID, workspaceID), fullPath)
+	if err != nil || relPath == "." {
+		return "", "", &PathValidationError{Path: dirPath, Message: "workspace root not allowed"}
+	}
+	if first, _, _ := strings.Cut(relPath, string(filepath.Separator)); first == ".git" {
+		return "", "", &PathValidationError{Path: dirPath, Message: "git directory not allowed"}
+	}
+	return fullPath, relPath, nil
+}
+
+// CreateDirectory creates the directory at dirPath together with its missing parents.
+// If a file or directory already exists at dirPath, an error satisfying os.IsExist is returned.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) CreateDirectory(userID, workspaceID int, dirPath string) error {
+	if err := s.checkWritable(userID, workspaceID); err != nil {
+		return err
+	}
+
+	fullPath, relPath, err := s.directoryRelPath(userID, workspaceID, dirPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.fs.Stat(fullPath); err == nil {
+		return fmt.Errorf("directory %s: %w", relPath, os.ErrExist)
+	}
+	if err := s.fs.MkdirAll(fullPath, 0755); err != nil {
+		return err
+	}
+	s.invalidateCaches(userID, workspaceID)
+
+	getLogger().Debug("directory created",
+		"userID", userID,
+		"workspaceID", workspaceID,
+		"path", relPath)
+	return nil
+}
+
+// DeleteDirectory moves the directory at dirPath with all its content to the trash of the workspace.
+// A path that is not a directory is rejected with a PathValidationError.
+// Directories larger than the configured tree limits are rejected before anything is moved.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) DeleteDirectory(userID, workspaceID int, dirPath string) error {
+	if err := s.checkWritable(userID, workspaceID); err != nil {
+		return err
+	}
+
+	fullPath, relPath, err := s.directoryRelPath(userID, workspaceID, dirPath)
+	if err != nil {
+		return err
+	}
+
+	info, err := s.fs.Lstat(fullPath)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return &PathValidationError{Path: dirPath, Message: "not a directory"}
+	}
+
+	if err := s.checkTreeLimits(fullPath); err != nil {
+		return err
+	}
+
+	if err := s.moveToTrash(userID, workspaceID, relPath, fullPath); err != nil {
+		return err
+	}
+	s.invalidateCaches(userID, workspaceID)
+
+	getLogger().Debug("directory moved to trash",
+		"userID", userID,
+		"workspaceID", workspaceID,
+		"path", relPath)
+	return nil
+}
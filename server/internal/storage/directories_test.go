@@ -0,0 +1,140 @@
+package storage_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestDirectories(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+	workspacePath := s.GetWorkspacePath(1, 1)
+	nested := filepath.Join("projects", "2024", "drafts")
+
+	t.Run("create nested directory", func(t *testing.T) {
+		if err := s.CreateDirectory(1, 1, nested); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		info, err := os.Stat(filepath.Join(workspacePath, nested))
+		if err != nil || !info.IsDir() {
+			t.Fatalf("directory not created: %v", err)
+		}
+
+		if err := s.CreateDirectory(1, 1, nested); !errors.Is(err, os.ErrExist) {
+			t.Errorf("error = %v, want exist", err)
+		}
+	})
+
+	t.Run("delete populated directory", func(t *testing.T) {
+		if err := s.SaveFile(1, 1, filepath.Join(nested, "note.md"), []byte("draft")); err != nil {
+			t.Fatalf("failed to save file: %v", err)
+		}
+
+		if err := s.DeleteDirectory(1, 1, "projects"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(workspacePath, "projects")); !os.IsNotExist(err) {
+			t.Errorf("directory still exists: %v", err)
+		}
+
+		items, err := s.ListTrash(1, 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(items) != 1 || items[0].Path != "projects" || !items[0].IsDir {
+			t.Errorf("ListTrash = %+v, want projects directory", items)
+		}
+
+		if err := s.DeleteDirectory(1, 1, "projects"); !os.IsNotExist(err) {
+			t.Errorf("error = %v, want not exist", err)
+		}
+	})
+
+	t.Run("delete file as directory", func(t *testing.T) {
+		if err := s.SaveFile(1, 1, "file.md", []byte("file")); err != nil {
+			t.Fatalf("failed to save file: %v", err)
+		}
+		if err := s.DeleteDirectory(1, 1, "file.md"); !storage.IsPathValidationError(err) {
+			t.Errorf("error = %v, want PathValidationError", err)
+		}
+	})
+
+	t.Run("rejected paths", func(t *testing.T) {
+		for _, path := range []string{"../../etc", "..", ".", "", ".git", filepath.Join(".git", "objects")} {
+			if err := s.CreateDirectory(1, 1, path); !storage.IsPathValidationError(err) {
+				t.Errorf("CreateDirectory(%q) error = %v, want PathValidationError", path, err)
+			}
+			if err := s.DeleteDirectory(1, 1, path); !storage.IsPathValidationError(err) {
+				t.Errorf("DeleteDirectory(%q) error = %v, want PathValidationError", path, err)
+			}
+		}
+		if _, err := os.Stat(workspacePath); err != nil {
+			t.Errorf("workspace removed: %v", err)
+		}
+	})
+}
+
+func TestDeleteDirectoryTreeLimits(t *testing.T) {
+	s := storage.NewServiceWithOptions(t.TempDir(), storage.Options{
+		MaxTreeNodes: 4,
+		MaxTreeDepth: 3,
+	})
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	testCases := []struct {
+		name    string
+		files   []string
+		dir     string
+		wantErr bool
+	}{
+		{
+			name:  "within limits",
+			files: []string{"small/a.md", "small/sub/b.md"},
+			dir:   "small",
+		},
+		{
+			name:    "too many entries",
+			files:   []string{"wide/a.md", "wide/b.md", "wide/c.md", "wide/d.md", "wide/e.md"},
+			dir:     "wide",
+			wantErr: true,
+		},
+		{
+			name:    "too deep",
+			files:   []string{"deep/1/2/3/a.md"},
+			dir:     "deep",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, f := range tc.files {
+				if err := s.SaveFile(1, 1, f, []byte("content")); err != nil {
+					t.Fatalf("failed to save %s: %v", f, err)
+				}
+			}
+
+			err := s.DeleteDirectory(1, 1, tc.dir)
+			if tc.wantErr != storage.IsTreeLimitError(err) {
+				t.Fatalf("error = %v, want tree limit error %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, statErr := os.Stat(filepath.Join(s.GetWorkspacePath(1, 1), tc.dir))
+			if exists := statErr == nil; exists != tc.wantErr {
+				t.Errorf("directory exists = %v, want %v", exists, tc.wantErr)
+			}
+		})
+	}
+}
@@ -4,8 +4,15 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
+// ErrSameFile is returned when the source and destination of an operation are the same file
+var ErrSameFile = errors.New("source and destination are the same file")
+
+// ErrIncludeLimit is returned when resolving includes exceeds the number of includes or the included size
+var ErrIncludeLimit = errors.New("too much included content")
+
 // PathValidationError represents a path validation error (e.g., path traversal attempt)
 type PathValidationError struct {
 	Path    string
@@ -21,3 +28,179 @@ func IsPathValidationError(err error) bool {
 	var pathErr *PathValidationError
 	return err != nil && errors.As(err, &pathErr)
 }
+
+// GitURLError represents a git remote URL that is not allowed by the configuration
+type GitURLError struct {
+	URL     string
+	Message string
+}
+
+func (e *GitURLError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Message, e.URL)
+}
+
+// IsGitURLError checks if the error is a GitURLError
+func IsGitURLError(err error) bool {
+	var urlErr *GitURLError
+	return err != nil && errors.As(err, &urlErr)
+}
+
+// TreeLimitError represents a directory operation rejected because the tree exceeds the configured limits
+type TreeLimitError struct {
+	Path    string
+	Message string
+}
+
+func (e *TreeLimitError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Message, e.Path)
+}
+
+// IsTreeLimitError checks if the error is a TreeLimitError
+func IsTreeLimitError(err error) bool {
+	var limitErr *TreeLimitError
+	return err != nil && errors.As(err, &limitErr)
+}
+
+// BatchSaveError represents a failure to save one of the files in a batch
+type BatchSaveError struct {
+	Path string
+	Err  error
+}
+
+func (e *BatchSaveError) Error() string {
+	return fmt.Sprintf("failed to save %s: %v", e.Path, e.Err)
+}
+
+func (e *BatchSaveError) Unwrap() error {
+	return e.Err
+}
+
+// SaveHookError represents a failure of a required save hook
+type SaveHookError struct {
+	Hook string
+	Err  error
+}
+
+func (e *SaveHookError) Error() string {
+	return fmt.Sprintf("save hook %s failed: %v", e.Hook, e.Err)
+}
+
+func (e *SaveHookError) Unwrap() error {
+	return e.Err
+}
+
+// IsSaveHookError checks if the error is a SaveHookError
+func IsSaveHookError(err error) bool {
+	var hookErr *SaveHookError
+	return err != nil && errors.As(err, &hookErr)
+}
+
+// WorkspacePinnedError represents a write to a workspace that is pinned to a git ref
+type WorkspacePinnedError struct {
+	Ref string
+}
+
+func (e *WorkspacePinnedError) Error() string {
+	return fmt.Sprintf("workspace is pinned to %s and read-only", e.Ref)
+}
+
+// IsWorkspacePinnedError checks if the error is a WorkspacePinnedError
+func IsWorkspacePinnedError(err error) bool {
+	var pinnedErr *WorkspacePinnedError
+	return err != nil && errors.As(err, &pinnedErr)
+}
+
+// LintError represents a markdown file rejected by a strict lint
+type LintError struct {
+	Issues []LintIssue
+}
+
+func (e *LintError) Error() string {
+	if len(e.Issues) == 1 {
+		return "lint failed: " + e.Issues[0].String()
+	}
+	return fmt.Sprintf("lint failed with %d issues: %s", len(e.Issues), e.Issues[0].String())
+}
+
+// IsLintError checks if the error is a LintError
+func IsLintError(err error) bool {
+	var lintErr *LintError
+	return err != nil && errors.As(err, &lintErr)
+}
+
+// FrontmatterError represents a frontmatter block that cannot be parsed
+type FrontmatterError struct {
+	Message string
+}
+
+func (e *FrontmatterError) Error() string {
+	return "malformed frontmatter: " + e.Message
+}
+
+// IsFrontmatterError checks if the error is a FrontmatterError
+func IsFrontmatterError(err error) bool {
+	var frontmatterErr *FrontmatterError
+	return err != nil && errors.As(err, &frontmatterErr)
+}
+
+// SigningKeyError represents a commit signing key that cannot be parsed or used
+type SigningKeyError struct {
+	Err error
+}
+
+func (e *SigningKeyError) Error() string {
+	return fmt.Sprintf("invalid signing key: %v", e.Err)
+}
+
+func (e *SigningKeyError) Unwrap() error {
+	return e.Err
+}
+
+// IsSigningKeyError checks if the error is a SigningKeyError
+func IsSigningKeyError(err error) bool {
+	var keyErr *SigningKeyError
+	return err != nil && errors.As(err, &keyErr)
+}
+
+// RemotePushFailure is a failed push to one of the additional remotes of a workspace
+type RemotePushFailure struct {
+	Remote string
+	Err    error
+}
+
+// RemotePushError represents changes committed and pushed to the primary remote
+// that could not be pushed to some of the additional remotes
+type RemotePushError struct {
+	Failures []RemotePushFailure
+}
+
+func (e *RemotePushError) Error() string {
+	messages := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		messages = append(messages, fmt.Sprintf("%s: %v", failure.Remote, failure.Err))
+	}
+	return "failed to push to remotes: " + strings.Join(messages, "; ")
+}
+
+// IsRemotePushError checks if the error is a RemotePushError
+func IsRemotePushError(err error) bool {
+	var pushErr *RemotePushError
+	return err != nil && errors.As(err, &pushErr)
+}
+
+// ChecksumMismatchError represents a conditional save rejected because the file changed since it was read
+type ChecksumMismatchError struct {
+	Path     string
+	Checksum string // checksum of the current content, empty if the file does not exist
+	Content  []byte // current content of the file
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("file was modified: %s", e.Path)
+}
+
+// IsChecksumMismatchError checks if the error is a ChecksumMismatchError
+func IsChecksumMismatchError(err error) bool {
+	var mismatchErr *ChecksumMismatchError
+	return err != nil && errors.As(err, &mismatchErr)
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ExportWorkspace writes the files of a workspace to zw, with their paths placed below prefix.
+// The .git directory is skipped, as are symlinks unless following symlinks is enabled.
+// Workspace is identified by the given userID and workspaceID.
+func (s *Service) ExportWorkspace(userID, workspaceID int, zw *zip.Writer, prefix string) error {
+	workspacePath := s.GetWorkspacePath(userID, workspaceID)
+	return s.exportDirectory(workspacePath, prefix, zw)
+}
+
+// WriteZip writes a zip archive of the files below subPath to w, with paths relative to subPath.
+// An empty subPath archives the whole workspace, a file archives just that file.
+// Errors validating subPath are returned before anything is written to w.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) WriteZip(userID, workspaceID int, subPath string, w io.Writer) error {
+	fullPath, err := s.ValidatePath(userID, workspaceID, subPath)
+	if err != nil {
+		return err
+	}
+	info, err := s.fs.Stat(fullPath)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	if info.IsDir() {
+		err = s.exportDirectory(fullPath, "", zw)
+	} else {
+		err = s.exportFile(fullPath, info.Name(), info, zw)
+	}
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// exportDirectory writes the files below dir to zw below prefix
+func (s *Service) exportDirectory(dir, prefix string, zw *zip.Writer) error {
+	return s.walkFiles(dir, func(entry walkEntry) error {
+		info, err := entry.Info()
+		if s.fs.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return s.exportFile(entry.fullPath, path.Join(prefix, filepath.ToSlash(entry.path)), info, zw)
+	})
+}
+
+// exportFile writes a single file to zw under name
+func (s *Service) exportFile(fullPath, name string, info os.FileInfo, zw *zip.Writer) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	writer, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	file, err := s.fs.Open(fullPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(writer, file)
+	return err
+}
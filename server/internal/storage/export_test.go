@@ -0,0 +1,163 @@
+package storage_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestExportWorkspace(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	files := map[string]string{
+		"a.md":         "alpha",
+		"notes/b.md":   "beta",
+		".git/HEAD":    "ref: refs/heads/main",
+		"deep/er/c.md": "gamma",
+	}
+	for path, content := range files {
+		if err := s.SaveFile(1, 1, path, []byte(content)); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+
+	outside := filepath.Join(t.TempDir(), "secret.md")
+	if err := os.WriteFile(outside, []byte("secret"), 0644); err != nil {
+		t.Fatalf("failed to write file outside the workspace: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(s.GetWorkspacePath(1, 1), "link.md")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := s.ExportWorkspace(1, 1, zw, "export/notes"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close archive: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, file := range zr.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", file.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file.Name, err)
+		}
+		got[file.Name] = string(content)
+	}
+
+	want := map[string]string{
+		"export/notes/a.md":         "alpha",
+		"export/notes/notes/b.md":   "beta",
+		"export/notes/deep/er/c.md": "gamma",
+	}
+	if len(got) != len(want) {
+		t.Errorf("archive contains %d files, want %d: %v", len(got), len(want), got)
+	}
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("%s = %q, want %q", name, got[name], content)
+		}
+	}
+}
+
+func TestWriteZip(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	files := map[string]string{
+		"a.md":            "alpha",
+		"notes/b.md":      "beta",
+		"notes/deep/c.md": "gamma",
+		// The trash is kept outside of the workspaces, a directory named like it is regular content
+		"notes/.trash/d.md": "kept",
+	}
+	for path, content := range files {
+		if err := s.SaveFile(1, 1, path, []byte(content)); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		subPath string
+		want    []string
+	}{
+		{
+			name:    "workspace",
+			subPath: "",
+			want:    []string{"a.md", "notes/.trash/d.md", "notes/b.md", "notes/deep/c.md"},
+		},
+		{
+			name:    "subtree",
+			subPath: "notes",
+			want:    []string{".trash/d.md", "b.md", "deep/c.md"},
+		},
+		{
+			name:    "single file",
+			subPath: filepath.Join("notes", "b.md"),
+			want:    []string{"b.md"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := s.WriteZip(1, 1, tc.subPath, &buf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatalf("failed to read archive: %v", err)
+			}
+			var names []string
+			for _, file := range zr.File {
+				names = append(names, file.Name)
+			}
+			if !slices.Equal(names, tc.want) {
+				t.Errorf("entries = %v, want %v", names, tc.want)
+			}
+		})
+	}
+
+	t.Run("missing path", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := s.WriteZip(1, 1, "missing", &buf); !os.IsNotExist(err) {
+			t.Errorf("error = %v, want not exist", err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("wrote %d bytes before failing", buf.Len())
+		}
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := s.WriteZip(1, 1, "../../etc", &buf); !storage.IsPathValidationError(err) {
+			t.Errorf("error = %v, want PathValidationError", err)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ListFilesByExtension returns the paths of the files of the workspace with one of the given extensions,
+// ordered by path. Extensions are matched ignoring case, with or without the leading dot.
+// The .git directory is skipped, as are symlinks unless following symlinks is enabled.
+func (s *Service) ListFilesByExtension(userID, workspaceID int, extensions []string) ([]string, error) {
+	workspacePath := s.GetWorkspacePath(userID, workspaceID)
+
+	wanted := make(map[string]bool, len(extensions))
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		wanted[ext] = true
+	}
+
+	paths := []string{}
+	if len(wanted) == 0 {
+		return paths, nil
+	}
+	err := s.walkFiles(workspacePath, func(entry walkEntry) error {
+		if wanted[strings.ToLower(filepath.Ext(entry.name))] {
+			paths = append(paths, entry.path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
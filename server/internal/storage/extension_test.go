@@ -0,0 +1,63 @@
+package storage_test
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestListFilesByExtension(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	files := []string{"index.md", "notes/b.MD", "notes/a.md", "images/logo.png", "images/photo.jpg", "notes.txt", ".git/config.md"}
+	for _, path := range files {
+		if err := s.SaveFile(1, 1, path, []byte("content")); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		extensions []string
+		want       []string
+	}{
+		{
+			name:       "single extension",
+			extensions: []string{"md"},
+			want:       []string{"index.md", filepath.Join("notes", "a.md"), filepath.Join("notes", "b.MD")},
+		},
+		{
+			name:       "multiple extensions",
+			extensions: []string{"png", ".JPG"},
+			want:       []string{filepath.Join("images", "logo.png"), filepath.Join("images", "photo.jpg")},
+		},
+		{
+			name:       "no matches",
+			extensions: []string{"pdf"},
+			want:       []string{},
+		},
+		{
+			name:       "no extensions",
+			extensions: []string{"", " "},
+			want:       []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			paths, err := s.ListFilesByExtension(1, 1, tc.extensions)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if paths == nil || !slices.Equal(paths, tc.want) {
+				t.Errorf("ListFilesByExtension(%v) = %v, want %v", tc.extensions, paths, tc.want)
+			}
+		})
+	}
+}
@@ -1,38 +1,83 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 )
 
 // FileManager provides functionalities to interact with files in the storage.
 type FileManager interface {
-	ListFilesRecursively(userID, workspaceID int) ([]FileNode, error)
-	FindFileByName(userID, workspaceID int, filename string) ([]string, error)
+	ListFilesRecursively(userID, workspaceID int, withStat bool) ([]FileNode, error)
+	FindFileByName(userID, workspaceID int, filename string, caseSensitive bool) ([]string, error)
+	SearchContent(userID, workspaceID int, query string, opts SearchOptions) ([]SearchResult, error)
 	GetFileContent(userID, workspaceID int, filePath string) ([]byte, error)
-	SaveFile(userID, workspaceID int, filePath string, content []byte) error
+	OpenFile(userID, workspaceID int, filePath string) (io.ReadCloser, os.FileInfo, error)
+	StatFile(userID, workspaceID int, filePath string) (os.FileInfo, error)
+	FileHash(userID, workspaceID int, filePath string) (string, int64, error)
+	SaveFile(userID, workspaceID int, filePath string, content []byte, hooks ...SaveHook) error
+	SaveFileIfMatch(userID, workspaceID int, filePath string, content []byte, expectedChecksum string, hooks ...SaveHook) error
+	SaveFiles(userID, workspaceID int, files []FileContent, hooks ...SaveHook) error
 	MoveFile(userID, workspaceID int, srcPath string, dstPath string) error
+	CopyFile(userID, workspaceID int, srcPath, dstPath string, overwrite bool) error
+	UniqueFilePath(userID, workspaceID int, filePath string) (string, error)
+	TransferFile(userID, srcWorkspaceID int, srcPath string, dstWorkspaceID int, dstPath string, overwrite bool) error
 	DeleteFile(userID, workspaceID int, filePath string) error
-	GetFileStats(userID, workspaceID int) (*FileCountStats, error)
-	GetTotalFileStats() (*FileCountStats, error)
+	GetFileStats(userID, workspaceID int, fresh bool) (*FileCountStats, error)
+	GetTextStats(userID, workspaceID int, filePath string, recursive bool) (*TextStats, error)
+	TailFile(userID, workspaceID int, filePath string, n int) ([]byte, error)
+	ResolveIncludes(userID, workspaceID int, filePath string) ([]byte, error)
+	GetFrontmatter(userID, workspaceID int, filePath string) (map[string]interface{}, error)
+	UpdateFrontmatter(userID, workspaceID int, filePath string, fields map[string]interface{}, hooks ...SaveHook) error
+	GetFileMeta(userID, workspaceID int, filePath string) (*FileMeta, error)
+	ListNotesByTag(userID, workspaceID int, tag string) ([]FileMeta, error)
+	BuildLinkGraph(userID, workspaceID int) (LinkGraph, error)
+	ListChangedFiles(userID, workspaceID int, since time.Time) ([]ChangedFile, error)
+	ListRecentFiles(userID, workspaceID, limit int) ([]FileNode, error)
+	ListFilesByExtension(userID, workspaceID int, extensions []string) ([]string, error)
+	ListFileVersions(userID, workspaceID int, filePath string) ([]FileVersion, error)
+	GetFileVersion(userID, workspaceID int, filePath, version string) ([]byte, error)
+	RestoreFileVersion(userID, workspaceID int, filePath, version string) error
+	ListTrash(userID, workspaceID int) ([]TrashItem, error)
+	RestoreTrashedFile(userID, workspaceID int, filePath string) (string, error)
+	DeleteTrashedFile(userID, workspaceID int, filePath string) error
+	PurgeTrash(cutoff time.Time) (int, error)
+	PurgeWorkspaceTrash(userID, workspaceID int, cutoff time.Time) (int, error)
+	CreateDirectory(userID, workspaceID int, dirPath string) error
+	DeleteDirectory(userID, workspaceID int, dirPath string) error
+	GetTotalFileStats(fresh bool) (*FileCountStats, error)
+}
+
+// FileContent represents a file path together with its content.
+type FileContent struct {
+	Path    string
+	Content []byte
 }
 
 // FileNode represents a file or directory in the storage.
+// Size and ModTime are only set when listed with stats, a directory has the total size and the
+// latest modification time of the files below it.
 type FileNode struct {
 	ID       string     `json:"id"`
 	Name     string     `json:"name"`
 	Path     string     `json:"path"`
+	Size     int64      `json:"size,omitempty"`
+	ModTime  time.Time  `json:"modTime,omitzero"`
 	Children []FileNode `json:"children,omitempty"`
 }
 
 // ListFilesRecursively returns a list of all files in the workspace directory and its subdirectories.
 // Workspace is identified by the given userID and workspaceID.
-func (s *Service) ListFilesRecursively(userID, workspaceID int) ([]FileNode, error) {
+// If withStat is set, the nodes include their size and modification time.
+func (s *Service) ListFilesRecursively(userID, workspaceID int, withStat bool) ([]FileNode, error) {
 	workspacePath := s.GetWorkspacePath(userID, workspaceID)
-	nodes, err := s.walkDirectory(workspacePath, "")
+	nodes, err := s.walkDirectory(s.newWalkDir(workspacePath), "", withStat)
 	if err != nil {
 		return nil, err
 	}
@@ -41,16 +86,16 @@ func (s *Service) ListFilesRecursively(userID, workspaceID int) ([]FileNode, err
 }
 
 // walkDirectory recursively walks the directory and returns a list of files and directories.
-func (s *Service) walkDirectory(dir, prefix string) ([]FileNode, error) {
-	entries, err := s.fs.ReadDir(dir)
+func (s *Service) walkDirectory(dir *walkDir, prefix string, withStat bool) ([]FileNode, error) {
+	entries, err := s.readWalkDir(dir, prefix)
 	if err != nil {
 		return nil, err
 	}
 
 	// Split entries into directories and files
-	var dirs, files []os.DirEntry
+	var dirs, files []walkEntry
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.isDir() {
 			dirs = append(dirs, entry)
 		} else {
 			files = append(files, entry)
@@ -59,10 +104,10 @@ func (s *Service) walkDirectory(dir, prefix string) ([]FileNode, error) {
 
 	// Sort directories and files separately
 	sort.Slice(dirs, func(i, j int) bool {
-		return strings.ToLower(dirs[i].Name()) < strings.ToLower(dirs[j].Name())
+		return strings.ToLower(dirs[i].name) < strings.ToLower(dirs[j].name)
 	})
 	sort.Slice(files, func(i, j int) bool {
-		return strings.ToLower(files[i].Name()) < strings.ToLower(files[j].Name())
+		return strings.ToLower(files[i].name) < strings.ToLower(files[j].name)
 	})
 
 	// Create combined slice with directories first, then files
@@ -70,33 +115,46 @@ func (s *Service) walkDirectory(dir, prefix string) ([]FileNode, error) {
 
 	// Add directories first
 	for _, entry := range dirs {
-		name := entry.Name()
-		path := filepath.Join(prefix, name)
-		fullPath := filepath.Join(dir, name)
-
-		children, err := s.walkDirectory(fullPath, path)
+		children, err := s.walkDirectory(entry.dir, entry.path, withStat)
 		if err != nil {
 			return nil, err
 		}
 
 		node := FileNode{
-			ID:       path,
-			Name:     name,
-			Path:     path,
+			ID:       entry.path,
+			Name:     entry.name,
+			Path:     entry.path,
 			Children: children,
 		}
+		if withStat {
+			for _, child := range children {
+				node.Size += child.Size
+				if child.ModTime.After(node.ModTime) {
+					node.ModTime = child.ModTime
+				}
+			}
+		}
 		nodes = append(nodes, node)
 	}
 
 	// Then add files
 	for _, entry := range files {
-		name := entry.Name()
-		path := filepath.Join(prefix, name)
-
 		node := FileNode{
-			ID:   path,
-			Name: name,
-			Path: path,
+			ID:   entry.path,
+			Name: entry.name,
+			Path: entry.path,
+		}
+		if withStat {
+			info, err := entry.Info()
+			if err != nil {
+				// The file was removed since the directory was read
+				if s.fs.IsNotExist(err) {
+					continue
+				}
+				return nil, err
+			}
+			node.Size = info.Size()
+			node.ModTime = info.ModTime().UTC()
 		}
 		nodes = append(nodes, node)
 	}
@@ -104,25 +162,39 @@ func (s *Service) walkDirectory(dir, prefix string) ([]FileNode, error) {
 	return nodes, nil
 }
 
+// FlattenFileNodes returns the paths of the files in the tree of nodes, depth first in tree order.
+// With withDirs directory paths are included too, each before the paths below it.
+func FlattenFileNodes(nodes []FileNode, withDirs bool) []string {
+	paths := []string{}
+	var flatten func(nodes []FileNode)
+	flatten = func(nodes []FileNode) {
+		for _, node := range nodes {
+			// Directories always have a non-nil list of children
+			if node.Children == nil {
+				paths = append(paths, node.Path)
+				continue
+			}
+			if withDirs {
+				paths = append(paths, node.Path)
+			}
+			flatten(node.Children)
+		}
+	}
+	flatten(nodes)
+	return paths
+}
+
 // FindFileByName returns a list of file paths that match the given filename.
 // Files are searched recursively in the workspace directory and its subdirectories.
 // Workspace is identified by the given userID and workspaceID.
-func (s *Service) FindFileByName(userID, workspaceID int, filename string) ([]string, error) {
+// The filename is matched ignoring case unless caseSensitive is set.
+func (s *Service) FindFileByName(userID, workspaceID int, filename string, caseSensitive bool) ([]string, error) {
 	var foundPaths []string
 	workspacePath := s.GetWorkspacePath(userID, workspaceID)
 
-	err := filepath.Walk(workspacePath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			relPath, err := filepath.Rel(workspacePath, path)
-			if err != nil {
-				return err
-			}
-			if strings.EqualFold(info.Name(), filename) {
-				foundPaths = append(foundPaths, relPath)
-			}
+	err := s.walkFiles(workspacePath, func(entry walkEntry) error {
+		if fileNameMatches(entry.name, filename, caseSensitive) {
+			foundPaths = append(foundPaths, entry.path)
 		}
 		return nil
 	})
@@ -138,6 +210,14 @@ func (s *Service) FindFileByName(userID, workspaceID int, filename string) ([]st
 	return foundPaths, nil
 }
 
+// fileNameMatches reports whether name is filename, ignoring case unless caseSensitive is set
+func fileNameMatches(name, filename string, caseSensitive bool) bool {
+	if caseSensitive {
+		return name == filename
+	}
+	return strings.EqualFold(name, filename)
+}
+
 // GetFileContent returns the content of the file at the given filePath.
 // Path must be a relative path within the workspace directory given by userID and workspaceID.
 func (s *Service) GetFileContent(userID, workspaceID int, filePath string) ([]byte, error) {
@@ -148,39 +228,246 @@ func (s *Service) GetFileContent(userID, workspaceID int, filePath string) ([]by
 	return s.fs.ReadFile(fullPath)
 }
 
+// OpenFile opens the file at the given filePath for reading without loading it into memory
+// and returns it together with its FileInfo, e.g. to announce the size of a download.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+// The caller is responsible for closing the returned reader.
+func (s *Service) OpenFile(userID, workspaceID int, filePath string) (io.ReadCloser, os.FileInfo, error) {
+	fullPath, err := s.ValidatePath(userID, workspaceID, filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := s.fs.Open(fullPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Stat the open file where possible so the info matches the content even if the file is replaced
+	var info os.FileInfo
+	if f, ok := file.(interface{ Stat() (os.FileInfo, error) }); ok {
+		info, err = f.Stat()
+	} else {
+		info, err = s.fs.Stat(fullPath)
+	}
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, info, nil
+}
+
+// StatFile returns the FileInfo of the file or directory at the given filePath without reading it.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) StatFile(userID, workspaceID int, filePath string) (os.FileInfo, error) {
+	fullPath, err := s.ValidatePath(userID, workspaceID, filePath)
+	if err != nil {
+		return nil, err
+	}
+	return s.fs.Stat(fullPath)
+}
+
+// FileHash returns the hex encoded SHA-256 hash and the size of the file at the given filePath.
+// The file is streamed through the hasher, so large files are not loaded into memory.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) FileHash(userID, workspaceID int, filePath string) (string, int64, error) {
+	file, _, err := s.OpenFile(userID, workspaceID, filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
+
 // SaveFile writes the content to the file at the given filePath.
+// The content it replaces is kept as a file version if file versions are enabled.
+// Workspaces pinned to a git ref are read-only and return a WorkspacePinnedError.
+// The hooks are run in order before and after the file is written, a failing required hook
+// fails the save with a SaveHookError.
 // Path must be a relative path within the workspace directory given by userID and workspaceID.
-func (s *Service) SaveFile(userID, workspaceID int, filePath string, content []byte) error {
+func (s *Service) SaveFile(userID, workspaceID int, filePath string, content []byte, hooks ...SaveHook) error {
 	log := getLogger()
 
+	if err := s.checkWritable(userID, workspaceID); err != nil {
+		return err
+	}
+
 	fullPath, err := s.ValidatePath(userID, workspaceID, filePath)
 	if err != nil {
 		return err
 	}
 
+	content, err = runBeforeSaveHooks(hooks, filePath, content)
+	if err != nil {
+		return err
+	}
+
 	dir := filepath.Dir(fullPath)
 	if err := s.fs.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 
+	previous, statErr := s.fs.Lstat(fullPath)
+
+	if statErr == nil && previous.Mode().IsRegular() && s.maxFileVersions > 0 {
+		previousContent, err := s.fs.ReadFile(fullPath)
+		if err != nil {
+			return err
+		}
+		if err := s.saveFileVersion(userID, workspaceID, filePath, previousContent, content); err != nil {
+			return fmt.Errorf("failed to keep file version: %w", err)
+		}
+	}
+
 	if err := s.fs.WriteFile(fullPath, content, 0644); err != nil {
+		s.invalidateCaches(userID, workspaceID)
 		return err
 	}
 
+	// Adjust the cached stats by the change instead of recounting the workspace
+	switch {
+	case statErr != nil:
+		s.fileChanged(userID, workspaceID, 1, int64(len(content)))
+	case previous.Mode().IsRegular():
+		s.fileChanged(userID, workspaceID, 0, int64(len(content))-previous.Size())
+	default:
+		s.invalidateCaches(userID, workspaceID)
+	}
+
 	log.Debug("file saved",
 		"userID", userID,
 		"workspaceID", workspaceID,
 		"path", filePath,
 		"size", len(content))
+	return runAfterSaveHooks(hooks, filePath, content)
+}
+
+// Checksum returns the checksum of file content used for conditional saves, the hex encoded SHA-256 hash
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveFileIfMatch saves the file like SaveFile, but only if the checksum of its current content
+// is expectedChecksum. An empty expectedChecksum requires the file not to exist and "*" accepts
+// any existing file. Otherwise a ChecksumMismatchError with the current content is returned.
+// Conditional saves are serialized so two of them cannot both pass the check.
+func (s *Service) SaveFileIfMatch(userID, workspaceID int, filePath string, content []byte, expectedChecksum string, hooks ...SaveHook) error {
+	if err := s.checkWritable(userID, workspaceID); err != nil {
+		return err
+	}
+
+	fullPath, err := s.ValidatePath(userID, workspaceID, filePath)
+	if err != nil {
+		return err
+	}
+
+	s.conditionalSaveMu.Lock()
+	defer s.conditionalSaveMu.Unlock()
+
+	current, err := s.fs.ReadFile(fullPath)
+	exists := err == nil
+	if err != nil && !s.fs.IsNotExist(err) {
+		return err
+	}
+
+	checksum := ""
+	if exists {
+		checksum = Checksum(current)
+	}
+	if checksum != expectedChecksum && !(exists && expectedChecksum == "*") {
+		return &ChecksumMismatchError{Path: filePath, Checksum: checksum, Content: current}
+	}
+
+	return s.SaveFile(userID, workspaceID, filePath, content, hooks...)
+}
+
+// SaveFiles writes all the given files, or none of them.
+// Each file is saved like with SaveFile, so replaced content is kept as a file version and the hooks are run.
+// All paths are validated before anything is written. If saving any file fails, the files
+// already written are restored to their previous content or removed (best-effort rollback)
+// and a BatchSaveError identifying the failed file is returned.
+// Paths must be relative paths within the workspace directory given by userID and workspaceID.
+func (s *Service) SaveFiles(userID, workspaceID int, files []FileContent, hooks ...SaveHook) error {
+	log := getLogger()
+
+	if err := s.checkWritable(userID, workspaceID); err != nil {
+		return err
+	}
+
+	fullPaths := make([]string, len(files))
+	for i, file := range files {
+		fullPath, err := s.ValidatePath(userID, workspaceID, file.Path)
+		if err != nil {
+			return err
+		}
+		fullPaths[i] = fullPath
+	}
+
+	// previous holds the original content of overwritten files, nil for new files
+	previous := make([][]byte, 0, len(files))
+	rollback := func() {
+		defer s.invalidateCaches(userID, workspaceID)
+		for i := len(previous) - 1; i >= 0; i-- {
+			var err error
+			if previous[i] != nil {
+				err = s.fs.WriteFile(fullPaths[i], previous[i], 0644)
+			} else {
+				err = s.fs.Remove(fullPaths[i])
+			}
+			if err != nil {
+				log.Error("failed to roll back batch save",
+					"userID", userID,
+					"workspaceID", workspaceID,
+					"path", files[i].Path,
+					"error", err.Error())
+			}
+		}
+	}
+
+	for i, file := range files {
+		original, err := s.fs.ReadFile(fullPaths[i])
+		if err != nil {
+			original = nil
+		} else if original == nil {
+			original = []byte{}
+		}
+
+		if err := s.SaveFile(userID, workspaceID, file.Path, file.Content, hooks...); err != nil {
+			// A failing after-save hook leaves the file written, so it is rolled back as well
+			if _, statErr := s.fs.Stat(fullPaths[i]); statErr == nil {
+				previous = append(previous, original)
+			}
+			rollback()
+			return &BatchSaveError{Path: file.Path, Err: err}
+		}
+		previous = append(previous, original)
+	}
+
+	log.Debug("files saved",
+		"userID", userID,
+		"workspaceID", workspaceID,
+		"count", len(files))
 	return nil
 }
 
 // MoveFile moves a file from srcPath to dstPath within the workspace directory.
 // Both paths must be relative to the workspace directory given by userID and workspaceID.
 // If the destination file already exists, it will be overwritten.
+// Directories larger than the configured tree limits are rejected before anything is moved.
 func (s *Service) MoveFile(userID, workspaceID int, srcPath string, dstPath string) error {
 	log := getLogger()
 
+	if err := s.checkWritable(userID, workspaceID); err != nil {
+		return err
+	}
+
 	srcFullPath, err := s.ValidatePath(userID, workspaceID, srcPath)
 	if err != nil {
 		return err
@@ -191,9 +478,14 @@ func (s *Service) MoveFile(userID, workspaceID int, srcPath string, dstPath stri
 		return err
 	}
 
+	if err := s.checkTreeLimits(srcFullPath); err != nil {
+		return err
+	}
+
 	if err := s.fs.MoveFile(srcFullPath, dstFullPath); err != nil {
 		return err
 	}
+	s.invalidateCaches(userID, workspaceID)
 
 	log.Debug("file moved",
 		"userID", userID,
@@ -203,20 +495,213 @@ func (s *Service) MoveFile(userID, workspaceID int, srcPath string, dstPath stri
 	return nil
 }
 
-// DeleteFile deletes the file at the given filePath.
+// CopyFile copies the file at srcPath to dstPath within the workspace directory, creating parent directories.
+// Both paths must be relative to the workspace directory given by userID and workspaceID.
+// If the destination file already exists, an error satisfying os.IsExist is returned unless overwrite is set,
+// the replaced content is then kept as a file version like with SaveFile.
+func (s *Service) CopyFile(userID, workspaceID int, srcPath, dstPath string, overwrite bool) error {
+	log := getLogger()
+
+	if err := s.checkWritable(userID, workspaceID); err != nil {
+		return err
+	}
+
+	srcFullPath, err := s.ValidatePath(userID, workspaceID, srcPath)
+	if err != nil {
+		return err
+	}
+
+	dstFullPath, err := s.ValidatePath(userID, workspaceID, dstPath)
+	if err != nil {
+		return err
+	}
+
+	content, err := s.fs.ReadFile(srcFullPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.fs.Stat(dstFullPath); err == nil && !overwrite {
+		return fmt.Errorf("destination %s: %w", dstPath, os.ErrExist)
+	}
+
+	if err := s.SaveFile(userID, workspaceID, dstPath, content); err != nil {
+		return err
+	}
+
+	log.Debug("file copied",
+		"userID", userID,
+		"workspaceID", workspaceID,
+		"src", srcPath,
+		"dst", dstPath)
+	return nil
+}
+
+// maxUniqueSuffix is the highest numeric suffix UniqueFilePath tries before giving up
+const maxUniqueSuffix = 1000
+
+// UniqueFilePath returns filePath if no file exists there yet, otherwise the first free path
+// with a numeric suffix added to the file name, e.g. "notes/name (1).md".
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) UniqueFilePath(userID, workspaceID int, filePath string) (string, error) {
+	fullPath, err := s.ValidatePath(userID, workspaceID, filePath)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := s.fs.Stat(fullPath); s.fs.IsNotExist(err) {
+		return filePath, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	dir, base := filepath.Split(filePath)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		// Dotfiles like ".env" have no extension to keep
+		stem, ext = base, ""
+	}
+
+	for i := 1; i <= maxUniqueSuffix; i++ {
+		candidate := filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, i, ext))
+		if _, err := s.fs.Stat(filepath.Join(filepath.Dir(fullPath), filepath.Base(candidate))); s.fs.IsNotExist(err) {
+			return candidate, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf("no free file name found for %s", filePath)
+}
+
+// checkTreeLimits walks the directory at fullPath and returns a TreeLimitError if it contains
+// more nodes or is nested deeper than the configured limits. Files pass without being walked.
+func (s *Service) checkTreeLimits(fullPath string) error {
+	if s.maxTreeNodes <= 0 && s.maxTreeDepth <= 0 {
+		return nil
+	}
+
+	info, err := s.fs.Stat(fullPath)
+	if err != nil || !info.IsDir() {
+		// Missing files are reported by the operation itself
+		return nil
+	}
+
+	nodes := 0
+	var walk func(dir string, depth int) error
+	walk = func(dir string, depth int) error {
+		if s.maxTreeDepth > 0 && depth > s.maxTreeDepth {
+			return &TreeLimitError{Path: fullPath, Message: fmt.Sprintf("directory is nested deeper than %d levels", s.maxTreeDepth)}
+		}
+
+		entries, err := s.fs.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			nodes++
+			if s.maxTreeNodes > 0 && nodes > s.maxTreeNodes {
+				return &TreeLimitError{Path: fullPath, Message: fmt.Sprintf("directory contains more than %d entries", s.maxTreeNodes)}
+			}
+			if entry.IsDir() {
+				if err := walk(filepath.Join(dir, entry.Name()), depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	return walk(fullPath, 1)
+}
+
+// TransferFile moves a file from srcPath in the source workspace to dstPath in the destination workspace.
+// Both workspaces must belong to the given userID and paths must be relative to their workspace directories.
+// ErrSameFile is returned if both paths resolve to the same file. If the destination file already exists,
+// an error satisfying os.IsExist is returned unless overwrite is set, the replaced content is then kept
+// as a file version like with SaveFile.
+func (s *Service) TransferFile(userID, srcWorkspaceID int, srcPath string, dstWorkspaceID int, dstPath string, overwrite bool) error {
+	log := getLogger()
+
+	if err := s.checkWritable(userID, srcWorkspaceID); err != nil {
+		return err
+	}
+	if err := s.checkWritable(userID, dstWorkspaceID); err != nil {
+		return err
+	}
+
+	srcFullPath, err := s.ValidatePath(userID, srcWorkspaceID, srcPath)
+	if err != nil {
+		return err
+	}
+
+	dstFullPath, err := s.ValidatePath(userID, dstWorkspaceID, dstPath)
+	if err != nil {
+		return err
+	}
+
+	// Removing the source after writing it onto itself would delete the only copy
+	if srcFullPath == dstFullPath {
+		return ErrSameFile
+	}
+
+	content, err := s.fs.ReadFile(srcFullPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.fs.Stat(dstFullPath); err == nil && !overwrite {
+		return fmt.Errorf("destination %s: %w", dstPath, os.ErrExist)
+	}
+
+	if err := s.SaveFile(userID, dstWorkspaceID, dstPath, content); err != nil {
+		return err
+	}
+
+	if err := s.fs.Remove(srcFullPath); err != nil {
+		return err
+	}
+	s.invalidateCaches(userID, srcWorkspaceID)
+
+	log.Debug("file transferred",
+		"userID", userID,
+		"srcWorkspaceID", srcWorkspaceID,
+		"dstWorkspaceID", dstWorkspaceID,
+		"src", srcPath,
+		"dst", dstPath)
+	return nil
+}
+
+// DeleteFile moves the file at the given filePath to the trash of the workspace, where it is kept
+// until it is restored with RestoreTrashedFile or removed by DeleteTrashedFile or PurgeTrash.
 // Path must be a relative path within the workspace directory given by userID and workspaceID.
 func (s *Service) DeleteFile(userID, workspaceID int, filePath string) error {
 	log := getLogger()
+	if err := s.checkWritable(userID, workspaceID); err != nil {
+		return err
+	}
 	fullPath, err := s.ValidatePath(userID, workspaceID, filePath)
 	if err != nil {
 		return err
 	}
 
-	if err := s.fs.Remove(fullPath); err != nil {
+	info, err := s.fs.Lstat(fullPath)
+	if err != nil {
 		return err
 	}
 
-	log.Debug("file deleted",
+	if err := s.moveToTrash(userID, workspaceID, filePath, fullPath); err != nil {
+		return err
+	}
+	if info.Mode().IsRegular() {
+		s.fileChanged(userID, workspaceID, -1, -info.Size())
+	} else {
+		s.invalidateCaches(userID, workspaceID)
+	}
+
+	log.Debug("file moved to trash",
 		"userID", userID,
 		"workspaceID", workspaceID,
 		"path", filePath)
@@ -225,13 +710,15 @@ func (s *Service) DeleteFile(userID, workspaceID int, filePath string) error {
 
 // FileCountStats holds statistics about files in a workspace
 type FileCountStats struct {
-	TotalFiles int   `json:"totalFiles"`
-	TotalSize  int64 `json:"totalSize"`
+	TotalFiles int       `json:"totalFiles"`
+	TotalSize  int64     `json:"totalSize"`
+	CachedAt   time.Time `json:"cachedAt"`
 }
 
 // GetFileStats returns the total number of files and related statistics in a workspace
 // Workspace is identified by the given userID and workspaceID
-func (s *Service) GetFileStats(userID, workspaceID int) (*FileCountStats, error) {
+// The stats are served from cache unless fresh is set.
+func (s *Service) GetFileStats(userID, workspaceID int, fresh bool) (*FileCountStats, error) {
 	workspacePath := s.GetWorkspacePath(userID, workspaceID)
 
 	// Check if workspace exists
@@ -239,7 +726,7 @@ func (s *Service) GetFileStats(userID, workspaceID int) (*FileCountStats, error)
 		return nil, fmt.Errorf("workspace directory does not exist")
 	}
 
-	stats, err := s.countFilesInPath(workspacePath)
+	stats, err := s.fileStats.get(userID, workspaceID, fresh)
 	if err != nil {
 		return nil, err
 	}
@@ -248,8 +735,9 @@ func (s *Service) GetFileStats(userID, workspaceID int) (*FileCountStats, error)
 }
 
 // GetTotalFileStats returns the total file statistics for the storage.
-func (s *Service) GetTotalFileStats() (*FileCountStats, error) {
-	stats, err := s.countFilesInPath(s.RootDir)
+// The stats are served from cache unless fresh is set.
+func (s *Service) GetTotalFileStats(fresh bool) (*FileCountStats, error) {
+	stats, err := s.fileStats.getTotal(fresh)
 	if err != nil {
 		return nil, err
 	}
@@ -261,33 +749,15 @@ func (s *Service) GetTotalFileStats() (*FileCountStats, error) {
 func (s *Service) countFilesInPath(directoryPath string) (*FileCountStats, error) {
 	result := &FileCountStats{}
 
-	err := filepath.WalkDir(directoryPath, func(path string, d os.DirEntry, err error) error {
+	err := s.walkFiles(directoryPath, func(entry walkEntry) error {
+		// Get file info for size
+		info, err := entry.Info()
 		if err != nil {
-			return err
-		}
-
-		// Skip the .git directory
-		if d.IsDir() && d.Name() == ".git" {
-			return filepath.SkipDir
+			return fmt.Errorf("failed to get file info for %s: %w", entry.path, err)
 		}
 
-		// Only count regular files
-		if !d.IsDir() {
-			// Get relative path from workspace root
-			relPath, err := filepath.Rel(directoryPath, path)
-			if err != nil {
-				return fmt.Errorf("failed to get relative path: %w", err)
-			}
-
-			// Get file info for size
-			info, err := d.Info()
-			if err != nil {
-				return fmt.Errorf("failed to get file info for %s: %w", relPath, err)
-			}
-
-			result.TotalFiles++
-			result.TotalSize += info.Size()
-		}
+		result.TotalFiles++
+		result.TotalSize += info.Size()
 		return nil
 	})
 
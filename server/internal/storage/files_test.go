@@ -1,10 +1,18 @@
 package storage_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
 	"io/fs"
 	"lemma/internal/storage"
+	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	_ "lemma/internal/testenv"
 )
@@ -110,7 +118,7 @@ func TestListFilesRecursively(t *testing.T) {
 			},
 		}
 
-		files, err := s.ListFilesRecursively(1, 1)
+		files, err := s.ListFilesRecursively(1, 1, false)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -133,7 +141,7 @@ func TestListFilesRecursively(t *testing.T) {
 			},
 		}
 
-		files, err := s.ListFilesRecursively(1, 1)
+		files, err := s.ListFilesRecursively(1, 1, false)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -162,7 +170,7 @@ func TestListFilesRecursively(t *testing.T) {
 			},
 		}
 
-		files, err := s.ListFilesRecursively(1, 1)
+		files, err := s.ListFilesRecursively(1, 1, false)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -183,9 +191,111 @@ func TestListFilesRecursively(t *testing.T) {
 		if !dirFound {
 			t.Error("directory 'dir1' not found in results")
 		}
+		for _, f := range files {
+			if f.Size != 0 || !f.ModTime.IsZero() {
+				t.Errorf("%s has stats %d %v without stat", f.Name, f.Size, f.ModTime)
+			}
+		}
+	})
+
+	t.Run("with stat", func(t *testing.T) {
+		older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		newer := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		mockFS.ReadDirReturns = map[string]struct {
+			entries []fs.DirEntry
+			err     error
+		}{
+			"test-root/1/1": {
+				entries: []fs.DirEntry{
+					NewMockDirEntry("dir1", true),
+					NewMockDirEntry("empty", true),
+					NewMockFileEntry("file1.md", 10, older),
+				},
+			},
+			"test-root/1/1/dir1": {
+				entries: []fs.DirEntry{
+					NewMockFileEntry("file2.md", 20, older),
+					NewMockFileEntry("file3.md", 30, newer),
+				},
+			},
+			"test-root/1/1/empty": {
+				entries: []fs.DirEntry{},
+			},
+		}
+
+		files, err := s.ListFilesRecursively(1, 1, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(files) != 3 {
+			t.Fatalf("expected 3 entries at root, got %d", len(files))
+		}
+
+		dir, empty, file := files[0], files[1], files[2]
+		if file.Size != 10 || !file.ModTime.Equal(older) {
+			t.Errorf("file1.md stats = %d %v, want 10 %v", file.Size, file.ModTime, older)
+		}
+		if dir.Children[1].Size != 30 || !dir.Children[1].ModTime.Equal(newer) {
+			t.Errorf("file3.md stats = %d %v, want 30 %v", dir.Children[1].Size, dir.Children[1].ModTime, newer)
+		}
+		if dir.Size != 50 || !dir.ModTime.Equal(newer) {
+			t.Errorf("dir1 stats = %d %v, want the total 50 and the latest %v", dir.Size, dir.ModTime, newer)
+		}
+		if empty.Size != 0 || !empty.ModTime.IsZero() {
+			t.Errorf("empty stats = %d %v, want none", empty.Size, empty.ModTime)
+		}
+
+		data, err := json.Marshal(empty)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Contains(string(data), "size") || strings.Contains(string(data), "modTime") {
+			t.Errorf("empty directory JSON = %s, want no stats", data)
+		}
 	})
 }
 
+func TestFlattenFileNodes(t *testing.T) {
+	nodes := []storage.FileNode{
+		{Path: "docs", Children: []storage.FileNode{
+			{Path: "docs/api", Children: []storage.FileNode{
+				{Path: "docs/api/endpoints.md"},
+			}},
+			{Path: "docs/empty", Children: []storage.FileNode{}},
+			{Path: "docs/readme.md"},
+		}},
+		{Path: "test.md"},
+	}
+
+	testCases := []struct {
+		name     string
+		withDirs bool
+		want     []string
+	}{
+		{
+			name: "files only",
+			want: []string{"docs/api/endpoints.md", "docs/readme.md", "test.md"},
+		},
+		{
+			name:     "with directories",
+			withDirs: true,
+			want:     []string{"docs", "docs/api", "docs/api/endpoints.md", "docs/empty", "docs/readme.md", "test.md"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := storage.FlattenFileNodes(nodes, tc.withDirs); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("FlattenFileNodes() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	if got := storage.FlattenFileNodes(nil, true); got == nil || len(got) != 0 {
+		t.Errorf("FlattenFileNodes(nil) = %#v, want empty list", got)
+	}
+}
+
 func TestGetFileContent(t *testing.T) {
 	mockFS := NewMockFS()
 	s := storage.NewServiceWithOptions("test-root", storage.Options{
@@ -263,6 +373,141 @@ func TestGetFileContent(t *testing.T) {
 	}
 }
 
+func TestOpenFile(t *testing.T) {
+	mockFS := NewMockFS()
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:           mockFS,
+		NewGitClient: nil,
+	})
+
+	t.Run("large file is streamed", func(t *testing.T) {
+		largeContent := bytes.Repeat([]byte("0123456789abcdef"), 256*1024) // 4 MiB
+		expectedPath := filepath.Join("test-root", "1", "1", "large.bin")
+		mockFS.ReadFileReturns[expectedPath] = struct {
+			data []byte
+			err  error
+		}{largeContent, nil}
+
+		reader, info, err := s.OpenFile(1, 1, "large.bin")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer reader.Close()
+
+		if info == nil || info.Name() != "large.bin" {
+			t.Errorf("info = %v, want the info of large.bin", info)
+		}
+
+		var out bytes.Buffer
+		n, err := io.Copy(&out, reader)
+		if err != nil {
+			t.Fatalf("failed to copy content: %v", err)
+		}
+		if n != int64(len(largeContent)) {
+			t.Errorf("copied %d bytes, want %d", n, len(largeContent))
+		}
+		if !bytes.Equal(out.Bytes(), largeContent) {
+			t.Error("streamed content does not match")
+		}
+
+		if mockFS.OpenCalls[expectedPath] != 1 {
+			t.Errorf("expected 1 open call for %s, got %d", expectedPath, mockFS.OpenCalls[expectedPath])
+		}
+		if mockFS.ReadCalls[expectedPath] != 0 {
+			t.Errorf("expected file not to be read into memory, got %d read calls", mockFS.ReadCalls[expectedPath])
+		}
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		expectedPath := filepath.Join("test-root", "1", "1", "nonexistent.md")
+		mockFS.ReadFileReturns[expectedPath] = struct {
+			data []byte
+			err  error
+		}{nil, fs.ErrNotExist}
+
+		_, _, err := s.OpenFile(1, 1, "nonexistent.md")
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("error = %v, want %v", err, fs.ErrNotExist)
+		}
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		_, _, err := s.OpenFile(1, 1, "../../../etc/passwd")
+		if !storage.IsPathValidationError(err) {
+			t.Errorf("expected path validation error, got %v", err)
+		}
+	})
+}
+
+func TestFileHash(t *testing.T) {
+	mockFS := NewMockFS()
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:           mockFS,
+		NewGitClient: nil,
+	})
+
+	t.Run("known content", func(t *testing.T) {
+		expectedPath := filepath.Join("test-root", "1", "1", "hello.txt")
+		mockFS.ReadFileReturns[expectedPath] = struct {
+			data []byte
+			err  error
+		}{[]byte("hello"), nil}
+
+		hash, size, err := s.FileHash(1, 1, "hello.txt")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"; hash != want {
+			t.Errorf("hash = %q, want %q", hash, want)
+		}
+		if size != 5 {
+			t.Errorf("size = %d, want 5", size)
+		}
+		if mockFS.ReadCalls[expectedPath] != 0 {
+			t.Errorf("expected file not to be read into memory, got %d read calls", mockFS.ReadCalls[expectedPath])
+		}
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		expectedPath := filepath.Join("test-root", "1", "1", "empty.txt")
+		mockFS.ReadFileReturns[expectedPath] = struct {
+			data []byte
+			err  error
+		}{[]byte{}, nil}
+
+		hash, size, err := s.FileHash(1, 1, "empty.txt")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"; hash != want {
+			t.Errorf("hash = %q, want %q", hash, want)
+		}
+		if size != 0 {
+			t.Errorf("size = %d, want 0", size)
+		}
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		expectedPath := filepath.Join("test-root", "1", "1", "nonexistent.md")
+		mockFS.ReadFileReturns[expectedPath] = struct {
+			data []byte
+			err  error
+		}{nil, fs.ErrNotExist}
+
+		_, _, err := s.FileHash(1, 1, "nonexistent.md")
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("error = %v, want %v", err, fs.ErrNotExist)
+		}
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		_, _, err := s.FileHash(1, 1, "../../../etc/passwd")
+		if !storage.IsPathValidationError(err) {
+			t.Errorf("expected path validation error, got %v", err)
+		}
+	})
+}
+
 func TestSaveFile(t *testing.T) {
 	mockFS := NewMockFS()
 	s := storage.NewServiceWithOptions("test-root", storage.Options{
@@ -336,6 +581,175 @@ func TestSaveFile(t *testing.T) {
 	}
 }
 
+func TestSaveFiles(t *testing.T) {
+	t.Run("successful save", func(t *testing.T) {
+		mockFS := NewMockFS()
+		s := storage.NewServiceWithOptions("test-root", storage.Options{Fs: mockFS})
+
+		files := []storage.FileContent{
+			{Path: "one.md", Content: []byte("one")},
+			{Path: "dir/two.md", Content: []byte("two")},
+		}
+		if err := s.SaveFiles(1, 1, files); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, file := range files {
+			expectedPath := filepath.Join("test-root", "1", "1", file.Path)
+			if content, ok := mockFS.WriteCalls[expectedPath]; !ok || string(content) != string(file.Content) {
+				t.Errorf("written content for %s = %q, want %q", file.Path, content, file.Content)
+			}
+		}
+	})
+
+	t.Run("invalid path writes nothing", func(t *testing.T) {
+		mockFS := NewMockFS()
+		s := storage.NewServiceWithOptions("test-root", storage.Options{Fs: mockFS})
+
+		files := []storage.FileContent{
+			{Path: "one.md", Content: []byte("one")},
+			{Path: "../../../etc/passwd", Content: []byte("bad")},
+		}
+		err := s.SaveFiles(1, 1, files)
+		if !storage.IsPathValidationError(err) {
+			t.Fatalf("expected path validation error, got %v", err)
+		}
+		if len(mockFS.WriteCalls) != 0 {
+			t.Errorf("expected no writes, got %d", len(mockFS.WriteCalls))
+		}
+	})
+
+	t.Run("write error identifies file", func(t *testing.T) {
+		mockFS := NewMockFS()
+		mockFS.WriteFileError = fs.ErrPermission
+		s := storage.NewServiceWithOptions("test-root", storage.Options{Fs: mockFS})
+
+		err := s.SaveFiles(1, 1, []storage.FileContent{{Path: "one.md", Content: []byte("one")}})
+		var batchErr *storage.BatchSaveError
+		if !errors.As(err, &batchErr) {
+			t.Fatalf("expected BatchSaveError, got %v", err)
+		}
+		if batchErr.Path != "one.md" {
+			t.Errorf("failed path = %q, want %q", batchErr.Path, "one.md")
+		}
+	})
+
+	t.Run("saves like SaveFile", func(t *testing.T) {
+		s := storage.NewServiceWithOptions(t.TempDir(), storage.Options{MaxFileVersions: 5})
+		if err := s.InitializeUserWorkspace(1, 1); err != nil {
+			t.Fatalf("failed to initialize workspace: %v", err)
+		}
+		if err := s.SaveFile(1, 1, "one.md", []byte("original")); err != nil {
+			t.Fatalf("failed to save file: %v", err)
+		}
+
+		hook := &mockSaveHook{transform: bytes.ToUpper}
+		files := []storage.FileContent{{Path: "one.md", Content: []byte("changed")}}
+		if err := s.SaveFiles(1, 1, files, hook); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		content, err := s.GetFileContent(1, 1, "one.md")
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		if string(content) != "CHANGED" {
+			t.Errorf("content = %q, want the hook to be applied", content)
+		}
+
+		versions, err := s.ListFileVersions(1, 1, "one.md")
+		if err != nil {
+			t.Fatalf("failed to list versions: %v", err)
+		}
+		if len(versions) != 1 {
+			t.Fatalf("got %d versions, want 1", len(versions))
+		}
+		previous, err := s.GetFileVersion(1, 1, "one.md", versions[0].Version)
+		if err != nil {
+			t.Fatalf("failed to read version: %v", err)
+		}
+		if string(previous) != "original" {
+			t.Errorf("version content = %q, want %q", previous, "original")
+		}
+	})
+
+	t.Run("failing hook rolls back", func(t *testing.T) {
+		s := storage.NewService(t.TempDir())
+		if err := s.InitializeUserWorkspace(1, 1); err != nil {
+			t.Fatalf("failed to initialize workspace: %v", err)
+		}
+
+		hook := &mockSaveHook{required: true, afterErr: errors.New("hook failed")}
+		err := s.SaveFiles(1, 1, []storage.FileContent{{Path: "new.md", Content: []byte("new")}}, hook)
+		if !storage.IsSaveHookError(err) {
+			t.Fatalf("expected save hook error, got %v", err)
+		}
+		if _, err := s.GetFileContent(1, 1, "new.md"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected new.md to be removed, got %v", err)
+		}
+	})
+}
+
+func TestSaveFileIfMatch(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		expected string
+		wantErr  bool
+		want     string
+	}{
+		{name: "new file", expected: "", want: "first"},
+		{name: "existing file without checksum", expected: "", wantErr: true, want: "first"},
+		{name: "matching checksum", expected: storage.Checksum([]byte("first")), want: "second"},
+		{name: "stale checksum", expected: storage.Checksum([]byte("first")), wantErr: true, want: "second"},
+		{name: "any existing file", expected: "*", want: "third"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := s.SaveFileIfMatch(1, 1, "note.md", []byte(tc.want), tc.expected)
+			if tc.wantErr {
+				var mismatchErr *storage.ChecksumMismatchError
+				if !errors.As(err, &mismatchErr) {
+					t.Fatalf("error = %v, want ChecksumMismatchError", err)
+				}
+				if string(mismatchErr.Content) != tc.want || mismatchErr.Checksum != storage.Checksum([]byte(tc.want)) {
+					t.Errorf("current = %q (%s), want %q", mismatchErr.Content, mismatchErr.Checksum, tc.want)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			content, err := s.GetFileContent(1, 1, "note.md")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(content) != tc.want {
+				t.Errorf("content = %q, want %q", content, tc.want)
+			}
+		})
+	}
+
+	t.Run("deleted file", func(t *testing.T) {
+		err := s.SaveFileIfMatch(1, 1, "missing.md", []byte("content"), "*")
+		var mismatchErr *storage.ChecksumMismatchError
+		if !errors.As(err, &mismatchErr) || mismatchErr.Checksum != "" {
+			t.Errorf("error = %v, want ChecksumMismatchError without checksum", err)
+		}
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		err := s.SaveFileIfMatch(1, 1, "../../etc/passwd", []byte("content"), "")
+		if !storage.IsPathValidationError(err) {
+			t.Errorf("error = %v, want PathValidationError", err)
+		}
+	})
+}
+
 func TestDeleteFile(t *testing.T) {
 	mockFS := NewMockFS()
 	s := storage.NewServiceWithOptions("test-root", storage.Options{
@@ -379,7 +793,7 @@ func TestDeleteFile(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockFS.RemoveError = tc.mockErr
+			mockFS.MoveFileError = tc.mockErr
 			err := s.DeleteFile(tc.userID, tc.workspaceID, tc.filePath)
 
 			if tc.wantErr {
@@ -393,16 +807,18 @@ func TestDeleteFile(t *testing.T) {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
+			// The file is moved to the trash instead of being removed
 			expectedPath := filepath.Join("test-root", "1", "1", tc.filePath)
-			found := false
-			for _, p := range mockFS.RemoveCalls {
-				if p == expectedPath {
-					found = true
-					break
-				}
+			trashDir := filepath.Join("test-root", ".trash", "1", "1", tc.filePath)
+			dst, ok := mockFS.MoveCalls[expectedPath]
+			if !ok {
+				t.Fatal("expected move to trash not made")
+			}
+			if filepath.Dir(dst) != trashDir {
+				t.Errorf("moved to %s, want a file in %s", dst, trashDir)
 			}
-			if !found {
-				t.Error("expected delete call not made")
+			if len(mockFS.RemoveCalls) != 0 {
+				t.Errorf("unexpected removals: %v", mockFS.RemoveCalls)
 			}
 		})
 	}
@@ -509,3 +925,270 @@ func TestMoveFile(t *testing.T) {
 		})
 	}
 }
+
+func TestCopyFile(t *testing.T) {
+	srcFullPath := filepath.Join("test-root", "1", "1", "template.md")
+
+	testCases := []struct {
+		name       string
+		srcPath    string
+		dstPath    string
+		srcExists  bool
+		dstExists  bool
+		overwrite  bool
+		wantErr    bool
+		wantExists bool
+	}{
+		{
+			name:      "successful copy",
+			srcPath:   "template.md",
+			dstPath:   "notes/copy.md",
+			srcExists: true,
+		},
+		{
+			name:    "missing source",
+			srcPath: "template.md",
+			dstPath: "copy.md",
+			wantErr: true,
+		},
+		{
+			name:      "invalid source path",
+			srcPath:   "../../../etc/passwd",
+			dstPath:   "copy.md",
+			srcExists: true,
+			wantErr:   true,
+		},
+		{
+			name:      "invalid destination path",
+			srcPath:   "template.md",
+			dstPath:   "../../../etc/passwd",
+			srcExists: true,
+			wantErr:   true,
+		},
+		{
+			name:       "existing destination",
+			srcPath:    "template.md",
+			dstPath:    "copy.md",
+			srcExists:  true,
+			dstExists:  true,
+			wantErr:    true,
+			wantExists: true,
+		},
+		{
+			name:      "overwrite existing destination",
+			srcPath:   "template.md",
+			dstPath:   "copy.md",
+			srcExists: true,
+			dstExists: true,
+			overwrite: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockFS := NewMockFS()
+			if tc.srcExists {
+				mockFS.ReadFileReturns[srcFullPath] = struct {
+					data []byte
+					err  error
+				}{[]byte("template content"), nil}
+			}
+			if !tc.dstExists {
+				mockFS.StatError = fs.ErrNotExist
+			}
+			s := storage.NewServiceWithOptions("test-root", storage.Options{
+				Fs: mockFS,
+			})
+
+			err := s.CopyFile(1, 1, tc.srcPath, tc.dstPath, tc.overwrite)
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if tc.wantExists && !errors.Is(err, fs.ErrExist) {
+					t.Errorf("error = %v, want ErrExist", err)
+				}
+				if len(mockFS.WriteCalls) != 0 {
+					t.Errorf("unexpected writes: %v", mockFS.WriteCalls)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expectedDstPath := filepath.Join("test-root", "1", "1", tc.dstPath)
+			if string(mockFS.WriteCalls[expectedDstPath]) != "template content" {
+				t.Errorf("destination content = %q, want %q", mockFS.WriteCalls[expectedDstPath], "template content")
+			}
+			if len(mockFS.MkdirCalls) == 0 || mockFS.MkdirCalls[0] != filepath.Dir(expectedDstPath) {
+				t.Errorf("MkdirCalls = %v, want %s", mockFS.MkdirCalls, filepath.Dir(expectedDstPath))
+			}
+			if mockFS.ReadCalls[srcFullPath] != 1 {
+				t.Error("source was not read")
+			}
+		})
+	}
+}
+
+func TestCopyFileKeepsVersion(t *testing.T) {
+	s := storage.NewServiceWithOptions(t.TempDir(), storage.Options{
+		MaxFileVersions: 5,
+	})
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+	for path, content := range map[string]string{"template.md": "template", "copy.md": "replaced"} {
+		if err := s.SaveFile(1, 1, path, []byte(content)); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+
+	if err := s.CopyFile(1, 1, "template.md", "copy.md", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The overwritten content is kept like on any other save
+	versions, err := s.ListFileVersions(1, 1, "copy.md")
+	if err != nil {
+		t.Fatalf("failed to list versions: %v", err)
+	}
+	if len(versions) != 1 {
+		t.Fatalf("got %d versions, want 1", len(versions))
+	}
+	previous, err := s.GetFileVersion(1, 1, "copy.md", versions[0].Version)
+	if err != nil {
+		t.Fatalf("failed to get version: %v", err)
+	}
+	if string(previous) != "replaced" {
+		t.Errorf("version content = %q, want %q", previous, "replaced")
+	}
+}
+
+func TestMoveDirectoryTreeLimits(t *testing.T) {
+	s := storage.NewServiceWithOptions(t.TempDir(), storage.Options{
+		MaxTreeNodes: 4,
+		MaxTreeDepth: 3,
+	})
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	save := func(path string) {
+		t.Helper()
+		if err := s.SaveFile(1, 1, path, []byte("content")); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+
+	exists := func(path string) bool {
+		_, err := os.Stat(filepath.Join(s.GetWorkspacePath(1, 1), path))
+		return err == nil
+	}
+
+	testCases := []struct {
+		name    string
+		files   []string
+		src     string
+		dst     string
+		wantErr bool
+	}{
+		{
+			name:  "within limits",
+			files: []string{"small/a.md", "small/sub/b.md"},
+			src:   "small",
+			dst:   "small-moved",
+		},
+		{
+			name:    "too many entries",
+			files:   []string{"wide/a.md", "wide/b.md", "wide/c.md", "wide/d.md", "wide/e.md"},
+			src:     "wide",
+			dst:     "wide-moved",
+			wantErr: true,
+		},
+		{
+			name:    "too deep",
+			files:   []string{"deep/1/2/3/a.md"},
+			src:     "deep",
+			dst:     "deep-moved",
+			wantErr: true,
+		},
+		{
+			name:  "single file ignores limits",
+			files: []string{"file.md"},
+			src:   "file.md",
+			dst:   "file-moved.md",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, f := range tc.files {
+				save(f)
+			}
+
+			err := s.MoveFile(1, 1, tc.src, tc.dst)
+			if tc.wantErr {
+				if !storage.IsTreeLimitError(err) {
+					t.Fatalf("MoveFile() error = %v, want tree limit error", err)
+				}
+				if !exists(tc.files[0]) || exists(tc.dst) {
+					t.Error("directory was changed although the move was rejected")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("MoveFile() unexpected error: %v", err)
+			}
+			if exists(tc.src) || !exists(tc.dst) {
+				t.Errorf("%s was not moved to %s", tc.src, tc.dst)
+			}
+		})
+	}
+}
+
+func TestUniqueFilePath(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+	for _, path := range []string{"note.md", "note (1).md", "docs/.env", "docs/README"} {
+		if err := s.SaveFile(1, 1, path, []byte("content")); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "free path", path: "new.md", want: "new.md"},
+		{name: "skips taken suffixes", path: "note.md", want: "note (2).md"},
+		{name: "dotfile", path: "docs/.env", want: "docs/.env (1)"},
+		{name: "no extension", path: "docs/README", want: "docs/README (1)"},
+		{name: "invalid path", path: "../outside.md", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.UniqueFilePath(1, 1, tc.path)
+			if tc.wantErr {
+				if !storage.IsPathValidationError(err) {
+					t.Errorf("UniqueFilePath() error = %v, want path validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UniqueFilePath() unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("UniqueFilePath() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
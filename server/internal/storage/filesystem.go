@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"io"
 	"io/fs"
 	"lemma/internal/logging"
 	"os"
@@ -9,6 +10,7 @@ import (
 // fileSystem defines the interface for filesystem operations
 type fileSystem interface {
 	ReadFile(path string) ([]byte, error)
+	Open(path string) (io.ReadCloser, error)
 	WriteFile(path string, data []byte, perm fs.FileMode) error
 	MoveFile(src, dst string) error
 	Remove(path string) error
@@ -16,6 +18,7 @@ type fileSystem interface {
 	RemoveAll(path string) error
 	ReadDir(path string) ([]fs.DirEntry, error)
 	Stat(path string) (fs.FileInfo, error)
+	Lstat(path string) (fs.FileInfo, error)
 	IsNotExist(err error) bool
 }
 
@@ -34,6 +37,15 @@ type osFS struct{}
 // ReadFile reads the file at the given path.
 func (f *osFS) ReadFile(path string) ([]byte, error) { return os.ReadFile(path) }
 
+// Open opens the file at the given path for reading.
+func (f *osFS) Open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
 // WriteFile writes the given data to the file at the given path.
 func (f *osFS) WriteFile(path string, data []byte, perm fs.FileMode) error {
 	return os.WriteFile(path, data, perm)
@@ -75,5 +87,8 @@ func (f *osFS) ReadDir(path string) ([]fs.DirEntry, error) { return os.ReadDir(p
 // Stat returns the FileInfo for the file at the given path.
 func (f *osFS) Stat(path string) (fs.FileInfo, error) { return os.Stat(path) }
 
+// Lstat returns the FileInfo for the file at the given path without following symlinks.
+func (f *osFS) Lstat(path string) (fs.FileInfo, error) { return os.Lstat(path) }
+
 // IsNotExist returns true if the error is a "file does not exist" error.
 func (f *osFS) IsNotExist(err error) bool { return os.IsNotExist(err) }
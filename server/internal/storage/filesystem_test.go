@@ -1,7 +1,9 @@
 package storage_test
 
 import (
+	"bytes"
 	"errors"
+	"io"
 	"io/fs"
 	"path/filepath"
 	"time"
@@ -10,19 +12,27 @@ import (
 )
 
 type mockDirEntry struct {
-	name  string
-	isDir bool
+	name    string
+	isDir   bool
+	size    int64
+	modTime time.Time
 }
 
-func (m *mockDirEntry) Name() string               { return m.name }
-func (m *mockDirEntry) IsDir() bool                { return m.isDir }
-func (m *mockDirEntry) Type() fs.FileMode          { return fs.ModeDir }
-func (m *mockDirEntry) Info() (fs.FileInfo, error) { return nil, nil }
+func (m *mockDirEntry) Name() string      { return m.name }
+func (m *mockDirEntry) IsDir() bool       { return m.isDir }
+func (m *mockDirEntry) Type() fs.FileMode { return fs.ModeDir }
+func (m *mockDirEntry) Info() (fs.FileInfo, error) {
+	return MockDirInfo{name: m.name, size: m.size, modTime: m.modTime, isDir: m.isDir}, nil
+}
 
 func NewMockDirEntry(name string, isDir bool) fs.DirEntry {
 	return &mockDirEntry{name: name, isDir: isDir}
 }
 
+func NewMockFileEntry(name string, size int64, modTime time.Time) fs.DirEntry {
+	return &mockDirEntry{name: name, size: size, modTime: modTime}
+}
+
 // Extend mockFS to support directory operations
 type MockDirInfo struct {
 	name    string
@@ -42,6 +52,7 @@ func (m MockDirInfo) Sys() any           { return nil }
 type mockFS struct {
 	// Record operations for verification
 	ReadCalls   map[string]int
+	OpenCalls   map[string]int
 	WriteCalls  map[string][]byte
 	MoveCalls   map[string]string
 	RemoveCalls []string
@@ -67,6 +78,7 @@ type mockFS struct {
 func NewMockFS() *mockFS {
 	return &mockFS{
 		ReadCalls:   make(map[string]int),
+		OpenCalls:   make(map[string]int),
 		WriteCalls:  make(map[string][]byte),
 		MoveCalls:   make(map[string]string),
 		RemoveCalls: make([]string, 0),
@@ -86,6 +98,18 @@ func (m *mockFS) ReadFile(path string) ([]byte, error) {
 	return nil, errors.New("file not found")
 }
 
+// Open serves the content configured in ReadFileReturns as a stream
+func (m *mockFS) Open(path string) (io.ReadCloser, error) {
+	m.OpenCalls[path]++
+	if ret, ok := m.ReadFileReturns[path]; ok {
+		if ret.err != nil {
+			return nil, ret.err
+		}
+		return io.NopCloser(bytes.NewReader(ret.data)), nil
+	}
+	return nil, errors.New("file not found")
+}
+
 func (m *mockFS) WriteFile(path string, data []byte, _ fs.FileMode) error {
 	m.WriteCalls[path] = data
 	return m.WriteFileError
@@ -122,6 +146,10 @@ func (m *mockFS) Stat(path string) (fs.FileInfo, error) {
 	}, nil
 }
 
+func (m *mockFS) Lstat(path string) (fs.FileInfo, error) {
+	return m.Stat(path)
+}
+
 func (m *mockFS) ReadDir(path string) ([]fs.DirEntry, error) {
 	if ret, ok := m.ReadDirReturns[path]; ok {
 		return ret.entries, ret.err
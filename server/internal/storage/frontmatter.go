@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"bytes"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontmatterDelimiter opens and closes the YAML frontmatter block at the start of a markdown file
+const frontmatterDelimiter = "---"
+
+// ParseFrontmatter returns the fields of the YAML frontmatter block at the start of content and the
+// offset of the body following it. Content without frontmatter returns nil fields and offset 0.
+// A block that is not closed or not a YAML mapping returns a FrontmatterError.
+func ParseFrontmatter(content []byte) (map[string]interface{}, int, error) {
+	block, offset, found, err := splitFrontmatter(content)
+	if err != nil || !found {
+		return nil, 0, err
+	}
+
+	var fields map[string]interface{}
+	if err := yaml.Unmarshal(block, &fields); err != nil {
+		return nil, 0, &FrontmatterError{Message: err.Error()}
+	}
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	return fields, offset, nil
+}
+
+// splitFrontmatter returns the YAML between the frontmatter delimiters and the offset of the body.
+// The block may also be closed by "...", the YAML end of document marker.
+func splitFrontmatter(content []byte) ([]byte, int, bool, error) {
+	end := bytes.IndexByte(content, '\n')
+	if end < 0 || strings.TrimRight(string(content[:end]), "\r") != frontmatterDelimiter {
+		return nil, 0, false, nil
+	}
+
+	start := end + 1
+	for pos := start; pos < len(content); {
+		lineEnd, next := len(content), len(content)
+		if i := bytes.IndexByte(content[pos:], '\n'); i >= 0 {
+			lineEnd, next = pos+i, pos+i+1
+		}
+		line := strings.TrimRight(string(content[pos:lineEnd]), "\r")
+		if line == frontmatterDelimiter || line == "..." {
+			return content[start:pos], next, true, nil
+		}
+		pos = next
+	}
+	return nil, 0, true, &FrontmatterError{Message: "frontmatter block is not closed"}
+}
+
+// ReplaceFrontmatter returns content with its frontmatter block replaced by fields, the body is
+// kept unchanged. Empty fields remove the block. Fields are written in key order.
+func ReplaceFrontmatter(content []byte, fields map[string]interface{}) ([]byte, error) {
+	_, offset, err := ParseFrontmatter(content)
+	if err != nil {
+		return nil, err
+	}
+	body := content[offset:]
+
+	if len(fields) == 0 {
+		return append([]byte{}, body...), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(frontmatterDelimiter + "\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(fields); err != nil {
+		return nil, &FrontmatterError{Message: err.Error()}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, &FrontmatterError{Message: err.Error()}
+	}
+	buf.WriteString(frontmatterDelimiter + "\n")
+	buf.Write(body)
+	return buf.Bytes(), nil
+}
+
+// GetFrontmatter returns the frontmatter fields of the file at filePath, empty if it has none.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) GetFrontmatter(userID, workspaceID int, filePath string) (map[string]interface{}, error) {
+	content, err := s.GetFileContent(userID, workspaceID, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, _, err := ParseFrontmatter(content)
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	return fields, nil
+}
+
+// UpdateFrontmatter replaces the frontmatter of the file at filePath with fields and saves the file
+// with the given hooks, see ReplaceFrontmatter and SaveFile.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) UpdateFrontmatter(userID, workspaceID int, filePath string, fields map[string]interface{}, hooks ...SaveHook) error {
+	content, err := s.GetFileContent(userID, workspaceID, filePath)
+	if err != nil {
+		return err
+	}
+
+	content, err = ReplaceFrontmatter(content, fields)
+	if err != nil {
+		return err
+	}
+	return s.SaveFile(userID, workspaceID, filePath, content, hooks...)
+}
@@ -0,0 +1,169 @@
+package storage_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestParseFrontmatter(t *testing.T) {
+	testCases := []struct {
+		name       string
+		content    string
+		wantFields map[string]interface{}
+		wantBody   string
+		wantErr    bool
+	}{
+		{
+			name:       "fields and body",
+			content:    "---\ntitle: Note\ntags:\n  - a\n  - b\n---\n# Note\n",
+			wantFields: map[string]interface{}{"title": "Note", "tags": []interface{}{"a", "b"}},
+			wantBody:   "# Note\n",
+		},
+		{
+			name:       "windows line endings",
+			content:    "---\r\ntitle: Note\r\n---\r\nBody\r\n",
+			wantFields: map[string]interface{}{"title": "Note"},
+			wantBody:   "Body\r\n",
+		},
+		{
+			name:       "end of document marker",
+			content:    "---\ntitle: Note\n...\nBody",
+			wantFields: map[string]interface{}{"title": "Note"},
+			wantBody:   "Body",
+		},
+		{
+			name:       "empty block",
+			content:    "---\n---\nBody",
+			wantFields: map[string]interface{}{},
+			wantBody:   "Body",
+		},
+		{
+			name:     "no frontmatter",
+			content:  "# Note\n---\ntitle: Note\n---\n",
+			wantBody: "# Note\n---\ntitle: Note\n---\n",
+		},
+		{
+			name:    "not closed",
+			content: "---\ntitle: Note\n# Note\n",
+			wantErr: true,
+		},
+		{
+			name:    "not a mapping",
+			content: "---\n- a\n- b\n---\nBody",
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			content: "---\ntitle: [Note\n---\nBody",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fields, offset, err := storage.ParseFrontmatter([]byte(tc.content))
+			if tc.wantErr {
+				if !storage.IsFrontmatterError(err) {
+					t.Errorf("error = %v, want FrontmatterError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(fields, tc.wantFields) {
+				t.Errorf("fields = %#v, want %#v", fields, tc.wantFields)
+			}
+			if body := tc.content[offset:]; body != tc.wantBody {
+				t.Errorf("body = %q, want %q", body, tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestReplaceFrontmatter(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		fields  map[string]interface{}
+		want    string
+	}{
+		{
+			name:    "replace block",
+			content: "---\ntitle: Old\ndraft: true\n---\n# Note\n\n---\nmore\n",
+			fields:  map[string]interface{}{"title": "New", "tags": []string{"a"}},
+			want:    "---\ntags:\n  - a\ntitle: New\n---\n# Note\n\n---\nmore\n",
+		},
+		{
+			name:    "add block",
+			content: "# Note\n",
+			fields:  map[string]interface{}{"title": "Note"},
+			want:    "---\ntitle: Note\n---\n# Note\n",
+		},
+		{
+			name:    "remove block",
+			content: "---\ntitle: Note\n---\n# Note\n",
+			fields:  nil,
+			want:    "# Note\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := storage.ReplaceFrontmatter([]byte(tc.content), tc.fields)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("content = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("malformed block", func(t *testing.T) {
+		_, err := storage.ReplaceFrontmatter([]byte("---\ntitle: Note\n"), map[string]interface{}{"title": "New"})
+		if !storage.IsFrontmatterError(err) {
+			t.Errorf("error = %v, want FrontmatterError", err)
+		}
+	})
+}
+
+func TestUpdateFrontmatter(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	body := "# Note\n\nSome text.\n"
+	if err := s.SaveFile(1, 1, "note.md", []byte("---\ntitle: Old\n---\n"+body)); err != nil {
+		t.Fatalf("failed to save note.md: %v", err)
+	}
+
+	if err := s.UpdateFrontmatter(1, 1, "note.md", map[string]interface{}{"title": "New"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fields, err := s.GetFrontmatter(1, 1, "note.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fields["title"] != "New" {
+		t.Errorf("title = %v, want New", fields["title"])
+	}
+
+	content, err := s.GetFileContent(1, 1, "note.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "---\ntitle: New\n---\n" + body; string(content) != want {
+		t.Errorf("content = %q, want %q", content, want)
+	}
+
+	if _, err := s.GetFrontmatter(1, 1, "missing.md"); !os.IsNotExist(err) {
+		t.Errorf("error = %v, want not exist", err)
+	}
+}
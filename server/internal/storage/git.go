@@ -1,30 +1,119 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"lemma/internal/git"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 )
 
 // RepositoryManager defines the interface for managing Git repositories.
 type RepositoryManager interface {
+	ValidateGitURL(gitURL string) error
 	SetupGitRepo(userID, workspaceID int, gitURL, gitUser, gitToken, commitName, commitEmail string) error
 	DisableGitRepo(userID, workspaceID int)
-	StageCommitAndPush(userID, workspaceID int, message string) (git.CommitHash, error)
-	Pull(userID, workspaceID int) error
+	SetGitRemotes(userID, workspaceID int, remotes []git.Remote) error
+	SetGitSigningKey(userID, workspaceID int, key string) error
+	StageCommitAndPush(userID, workspaceID int, message string, author git.Author) (git.CommitHash, error)
+	Pull(userID, workspaceID int) (git.PullResult, error)
+	DiffWorkingTree(userID, workspaceID int, path string) (string, error)
+	ListDeletedFiles(userID, workspaceID int) ([]string, error)
+	ListDeletedFilesSince(userID, workspaceID int, commit string) ([]string, error)
+	RestoreDeletedFile(userID, workspaceID int, filePath string) error
+	CommitHistory(userID, workspaceID, limit int) ([]git.Commit, error)
+	GitStatus(userID, workspaceID int) (git.GitStatus, error)
+	CreateBundle(userID, workspaceID int, w io.Writer) error
+	PinGitRef(userID, workspaceID int, ref string) error
+	FlushPendingPushes()
+}
+
+// ValidateGitURL checks the gitURL against the allowed git hosts and, if enabled,
+// rejects non-http(s) schemes and hosts on localhost or private networks.
+func (s *Service) ValidateGitURL(gitURL string) error {
+	if len(s.allowedGitHosts) == 0 && !s.blockPrivateGitHosts {
+		return nil
+	}
+
+	u, err := url.Parse(gitURL)
+	if err != nil || u.Hostname() == "" {
+		return &GitURLError{URL: gitURL, Message: "invalid git URL"}
+	}
+	host := strings.ToLower(u.Hostname())
+
+	if s.blockPrivateGitHosts {
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return &GitURLError{URL: gitURL, Message: "git URL scheme not allowed"}
+		}
+		private, err := s.isPrivateHost(host)
+		if err != nil {
+			return &GitURLError{URL: gitURL, Message: "git host could not be resolved"}
+		}
+		if private {
+			return &GitURLError{URL: gitURL, Message: "git host not allowed"}
+		}
+	}
+
+	if len(s.allowedGitHosts) == 0 {
+		return nil
+	}
+	for _, allowed := range s.allowedGitHosts {
+		if strings.EqualFold(strings.TrimSpace(allowed), host) {
+			return nil
+		}
+	}
+
+	return &GitURLError{URL: gitURL, Message: "git host not allowed"}
+}
+
+// isPrivateHost reports whether host is localhost or has a loopback, private or link-local address.
+// Host names are resolved and are private if any of their addresses is.
+func (s *Service) isPrivateHost(host string) (bool, error) {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true, nil
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return git.IsPrivateIP(ip), nil
+	}
+
+	ips, err := s.lookupIP(host)
+	if err != nil {
+		return false, err
+	}
+	for _, ip := range ips {
+		if git.IsPrivateIP(ip) {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // SetupGitRepo sets up a Git repository for the given userID and workspaceID.
 // The repository is cloned from the given gitURL using the given gitUser and gitToken.
 func (s *Service) SetupGitRepo(userID, workspaceID int, gitURL, gitUser, gitToken, commitName, commitEmail string) error {
+	if err := s.ValidateGitURL(gitURL); err != nil {
+		return err
+	}
+
 	workspacePath := s.GetWorkspacePath(userID, workspaceID)
+	repo := s.newGitClient(gitURL, gitUser, gitToken, workspacePath, commitName, commitEmail)
 
+	defer s.lockGitRepo(userID, workspaceID)()
+	s.gitStateMu.Lock()
 	if _, ok := s.GitRepos[userID]; !ok {
 		s.GitRepos[userID] = make(map[int]git.Client)
 	}
+	s.GitRepos[userID][workspaceID] = repo
+	s.gitStateMu.Unlock()
+	auditCredentialUse(userID, workspaceID, "clone")
 
-	s.GitRepos[userID][workspaceID] = s.newGitClient(gitURL, gitUser, gitToken, workspacePath, commitName, commitEmail)
-
-	return s.GitRepos[userID][workspaceID].EnsureRepo()
+	return repo.EnsureRepo()
 }
 
 // DisableGitRepo disables the Git repository for the given userID and workspaceID.
@@ -34,52 +123,325 @@ func (s *Service) DisableGitRepo(userID, workspaceID int) {
 		"userID", userID,
 		"workspaceID", workspaceID)
 
+	s.gitStateMu.Lock()
 	if userRepos, ok := s.GitRepos[userID]; ok {
 		delete(userRepos, workspaceID)
 		if len(userRepos) == 0 {
 			delete(s.GitRepos, userID)
 		}
 	}
+	s.gitStateMu.Unlock()
+	s.clearPinnedRef(userID, workspaceID)
+	s.clearGitRemotes(userID, workspaceID)
+	s.clearGitSigner(userID, workspaceID)
+	s.cancelPendingPush(userID, workspaceID)
+}
+
+// SetGitRemotes sets the additional remotes StageCommitAndPush pushes to after the primary remote,
+// replacing the previous ones. The remote URLs are checked like the URL of the primary remote.
+func (s *Service) SetGitRemotes(userID, workspaceID int, remotes []git.Remote) error {
+	for _, remote := range remotes {
+		if err := s.ValidateGitURL(remote.URL); err != nil {
+			return err
+		}
+	}
+
+	if len(remotes) == 0 {
+		s.clearGitRemotes(userID, workspaceID)
+		return nil
+	}
+
+	s.gitStateMu.Lock()
+	defer s.gitStateMu.Unlock()
+	if _, ok := s.gitRemotes[userID]; !ok {
+		s.gitRemotes[userID] = make(map[int][]git.Remote)
+	}
+	s.gitRemotes[userID][workspaceID] = remotes
+	return nil
+}
+
+// getGitRemotes returns the additional remotes of the workspace
+func (s *Service) getGitRemotes(userID, workspaceID int) []git.Remote {
+	s.gitStateMu.RLock()
+	defer s.gitStateMu.RUnlock()
+	return s.gitRemotes[userID][workspaceID]
+}
+
+// clearGitRemotes forgets the additional remotes of the workspace
+func (s *Service) clearGitRemotes(userID, workspaceID int) {
+	s.gitStateMu.Lock()
+	defer s.gitStateMu.Unlock()
+	if userRemotes, ok := s.gitRemotes[userID]; ok {
+		delete(userRemotes, workspaceID)
+		if len(userRemotes) == 0 {
+			delete(s.gitRemotes, userID)
+		}
+	}
+}
+
+// SetGitSigningKey sets the GPG or SSH private key the commits of StageCommitAndPush are signed with,
+// an empty key disables signing. A key that cannot be used for signing returns a SigningKeyError.
+func (s *Service) SetGitSigningKey(userID, workspaceID int, key string) error {
+	if key == "" {
+		s.clearGitSigner(userID, workspaceID)
+		return nil
+	}
+
+	signer, err := git.ParseSigningKey(key)
+	if err != nil {
+		return &SigningKeyError{Err: err}
+	}
+
+	s.gitStateMu.Lock()
+	defer s.gitStateMu.Unlock()
+	if _, ok := s.gitSigners[userID]; !ok {
+		s.gitSigners[userID] = make(map[int]git.Signer)
+	}
+	s.gitSigners[userID][workspaceID] = signer
+	return nil
+}
+
+// getGitSigner returns the commit signer of the workspace, nil if commits are not signed
+func (s *Service) getGitSigner(userID, workspaceID int) git.Signer {
+	s.gitStateMu.RLock()
+	defer s.gitStateMu.RUnlock()
+	return s.gitSigners[userID][workspaceID]
+}
+
+// clearGitSigner disables commit signing for the workspace
+func (s *Service) clearGitSigner(userID, workspaceID int) {
+	s.gitStateMu.Lock()
+	defer s.gitStateMu.Unlock()
+	if userSigners, ok := s.gitSigners[userID]; ok {
+		delete(userSigners, workspaceID)
+		if len(userSigners) == 0 {
+			delete(s.gitSigners, userID)
+		}
+	}
 }
 
 // StageCommitAndPush stages, commit with the message, and pushes the changes to the Git repository.
+// The commit is attributed to author, empty fields use the commit identity of the workspace,
+// and signed if a signing key is set. The signature is part of the commit, so it is pushed with it.
+// With a push grace period the push is deferred and batched with the following commits, its errors
+// are logged instead of returned, unless the maximum of pending commits is reached and it runs right away.
 // The git repository belongs to the given userID and is associated with the given workspaceID.
-func (s *Service) StageCommitAndPush(userID, workspaceID int, message string) (git.CommitHash, error) {
+func (s *Service) StageCommitAndPush(userID, workspaceID int, message string, author git.Author) (git.CommitHash, error) {
 	repo, ok := s.getGitRepo(userID, workspaceID)
 	if !ok {
 		return git.CommitHash{}, fmt.Errorf("git settings not configured for this workspace")
 	}
+	defer s.lockGitRepo(userID, workspaceID)()
+	if err := s.checkWritable(userID, workspaceID); err != nil {
+		return git.CommitHash{}, err
+	}
 
-	hash, err := repo.Commit(message)
+	hash, err := repo.Commit(message, author, s.getGitSigner(userID, workspaceID))
 	if err != nil {
 		return git.CommitHash{}, err
 	}
 
-	if err = repo.Push(); err != nil {
-		return hash, err
+	remotes := s.getGitRemotes(userID, workspaceID)
+	if s.pushGracePeriod > 0 && !s.schedulePush(userID, workspaceID) {
+		return hash, nil
+	}
+	return hash, s.push(userID, workspaceID, repo, remotes)
+}
+
+// push pushes the commits of the workspace to the primary remote and to the additional remotes
+func (s *Service) push(userID, workspaceID int, repo git.Client, remotes []git.Remote) error {
+	auditCredentialUse(userID, workspaceID, "push")
+	pushErr := repo.Push()
+
+	// Additional remotes are pushed to even if another push failed, each failure is reported separately
+	remoteErr := &RemotePushError{}
+	for _, remote := range remotes {
+		if err := repo.PushTo(remote); err != nil {
+			getLogger().Warn("failed to push to additional remote",
+				"userID", userID,
+				"workspaceID", workspaceID,
+				"remote", remote.Name,
+				"error", err.Error())
+			remoteErr.Failures = append(remoteErr.Failures, RemotePushFailure{Remote: remote.Name, Err: err})
+		}
 	}
 
-	return hash, nil
+	if pushErr != nil {
+		return pushErr
+	}
+	if len(remoteErr.Failures) > 0 {
+		return remoteErr
+	}
+	return nil
 }
 
-// Pull pulls the changes from the remote Git repository.
+// Pull pulls the changes from the remote Git repository and returns the changed files.
 // The git repository belongs to the given userID and is associated with the given workspaceID.
-func (s *Service) Pull(userID, workspaceID int) error {
+// A git.PullConflictError is returned if the changes cannot be applied to the workspace.
+func (s *Service) Pull(userID, workspaceID int) (git.PullResult, error) {
+	repo, ok := s.getGitRepo(userID, workspaceID)
+	if !ok {
+		return git.PullResult{}, fmt.Errorf("git settings not configured for this workspace")
+	}
+	defer s.lockGitRepo(userID, workspaceID)()
+	if err := s.checkWritable(userID, workspaceID); err != nil {
+		return git.PullResult{}, err
+	}
+
+	auditCredentialUse(userID, workspaceID, "pull")
+	result, err := repo.Pull()
+	if err != nil {
+		return git.PullResult{}, err
+	}
+	if !result.UpToDate {
+		s.invalidateCaches(userID, workspaceID)
+	}
+
+	return result, nil
+}
+
+// CommitHistory returns at most limit commits of the workspace repository, newest first
+func (s *Service) CommitHistory(userID, workspaceID, limit int) ([]git.Commit, error) {
+	repo, ok := s.getGitRepo(userID, workspaceID)
+	if !ok {
+		return nil, fmt.Errorf("git settings not configured for this workspace")
+	}
+	defer s.lockGitRepo(userID, workspaceID)()
+
+	return repo.Log(limit)
+}
+
+// GitStatus returns the uncommitted changes of the workspace and how far it is ahead and behind the remote
+func (s *Service) GitStatus(userID, workspaceID int) (git.GitStatus, error) {
+	repo, ok := s.getGitRepo(userID, workspaceID)
+	if !ok {
+		return git.GitStatus{}, fmt.Errorf("git settings not configured for this workspace")
+	}
+	defer s.lockGitRepo(userID, workspaceID)()
+
+	return repo.Status()
+}
+
+// DiffWorkingTree returns a unified diff of the uncommitted changes in the workspace against HEAD.
+// If path is not empty, the diff is limited to that file or directory.
+func (s *Service) DiffWorkingTree(userID, workspaceID int, path string) (string, error) {
+	repo, ok := s.getGitRepo(userID, workspaceID)
+	if !ok {
+		return "", fmt.Errorf("git settings not configured for this workspace")
+	}
+	defer s.lockGitRepo(userID, workspaceID)()
+
+	if path != "" {
+		fullPath, err := s.ValidatePath(userID, workspaceID, path)
+		if err != nil {
+			return "", err
+		}
+		path, err = filepath.Rel(s.GetWorkspacePath(userID, workspaceID), fullPath)
+		if err != nil {
+			return "", &PathValidationError{Path: path, Message: "invalid path"}
+		}
+		if path == "." {
+			path = ""
+		}
+	}
+
+	return repo.DiffWorkingTree(path)
+}
+
+// ListDeletedFiles returns the paths of committed files that are missing from the workspace.
+func (s *Service) ListDeletedFiles(userID, workspaceID int) ([]string, error) {
+	repo, ok := s.getGitRepo(userID, workspaceID)
+	if !ok {
+		return nil, fmt.Errorf("git settings not configured for this workspace")
+	}
+	defer s.lockGitRepo(userID, workspaceID)()
+
+	return repo.ListDeletedFiles()
+}
+
+// ListDeletedFilesSince returns the paths of files in the given commit that are missing from the workspace.
+func (s *Service) ListDeletedFilesSince(userID, workspaceID int, commit string) ([]string, error) {
+	repo, ok := s.getGitRepo(userID, workspaceID)
+	if !ok {
+		return nil, fmt.Errorf("git settings not configured for this workspace")
+	}
+	defer s.lockGitRepo(userID, workspaceID)()
+
+	return repo.ListDeletedFilesSince(commit)
+}
+
+// CreateBundle writes a git bundle with the full history of the workspace repository to w.
+func (s *Service) CreateBundle(userID, workspaceID int, w io.Writer) error {
 	repo, ok := s.getGitRepo(userID, workspaceID)
 	if !ok {
 		return fmt.Errorf("git settings not configured for this workspace")
 	}
+	defer s.lockGitRepo(userID, workspaceID)()
+
+	return repo.CreateBundle(w)
+}
 
-	err := repo.Pull()
+// RestoreDeletedFile writes filePath back to the workspace from the most recent commit that contains it.
+// It returns os.ErrExist if the file exists and os.ErrNotExist if no commit contains it.
+func (s *Service) RestoreDeletedFile(userID, workspaceID int, filePath string) error {
+	repo, ok := s.getGitRepo(userID, workspaceID)
+	if !ok {
+		return fmt.Errorf("git settings not configured for this workspace")
+	}
+	defer s.lockGitRepo(userID, workspaceID)()
+
+	if err := s.checkWritable(userID, workspaceID); err != nil {
+		return err
+	}
+
+	fullPath, err := s.ValidatePath(userID, workspaceID, filePath)
 	if err != nil {
 		return err
 	}
 
+	if _, err := s.fs.Stat(fullPath); err == nil {
+		return os.ErrExist
+	} else if !s.fs.IsNotExist(err) {
+		return err
+	}
+
+	relPath, err := filepath.Rel(s.GetWorkspacePath(userID, workspaceID), fullPath)
+	if err != nil || relPath == "." {
+		return &PathValidationError{Path: filePath, Message: "invalid path"}
+	}
+
+	content, err := repo.ReadFileFromHistory(relPath)
+	if errors.Is(err, git.ErrFileNotInHistory) {
+		return os.ErrNotExist
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.fs.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return err
+	}
+	if err := s.fs.WriteFile(fullPath, content, 0644); err != nil {
+		return err
+	}
+	s.invalidateCaches(userID, workspaceID)
+
 	return nil
 }
 
+// auditCredentialUse records that the workspace git token is used for a remote operation
+func auditCredentialUse(userID, workspaceID int, operation string) {
+	getLogger().WithGroup("git").Info("git credentials used",
+		"userID", userID,
+		"workspaceID", workspaceID,
+		"operation", operation)
+}
+
 // getGitRepo returns the Git repository for the given user and workspace IDs.
+// Operations on the repository have to hold the lock of lockGitRepo.
 func (s *Service) getGitRepo(userID, workspaceID int) (git.Client, bool) {
+	s.gitStateMu.RLock()
+	defer s.gitStateMu.RUnlock()
 	userRepos, ok := s.GitRepos[userID]
 	if !ok {
 		return nil, false
@@ -87,3 +449,19 @@ func (s *Service) getGitRepo(userID, workspaceID int) (git.Client, bool) {
 	repo, ok := userRepos[workspaceID]
 	return repo, ok
 }
+
+// lockGitRepo locks the repository of the workspace against other git operations
+// and returns the function unlocking it
+func (s *Service) lockGitRepo(userID, workspaceID int) func() {
+	key := workspaceKey{userID, workspaceID}
+	s.gitLocksMu.Lock()
+	lock, ok := s.gitLocks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.gitLocks[key] = lock
+	}
+	s.gitLocksMu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
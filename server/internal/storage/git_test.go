@@ -1,12 +1,30 @@
 package storage_test
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
 	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"lemma/internal/git"
 	"lemma/internal/storage"
 	_ "lemma/internal/testenv"
+
+	"golang.org/x/crypto/ssh"
 )
 
 // MockGitClient implements git.Client interface for testing
@@ -17,7 +35,24 @@ type MockGitClient struct {
 	PushCalled    bool
 	EnsureCalled  bool
 	CommitMessage string
+	CommitSigner  git.Signer
+	DiffPath      string
+	Diff          string
+	DeletedFiles  []string
+	DeletedSince  string
+	History       map[string][]byte
+	Bundle        []byte
+	CheckedOut    string
+	PushedTo      []string
+	PushToErrors  map[string]error
+	PullResult    git.PullResult
+	Commits       []git.Commit
+	LogLimit      int
+	GitStatus     git.GitStatus
 	ReturnError   error
+
+	pushMu    sync.Mutex
+	pushCount int
 }
 
 func (m *MockGitClient) Clone() error {
@@ -25,27 +60,93 @@ func (m *MockGitClient) Clone() error {
 	return m.ReturnError
 }
 
-func (m *MockGitClient) Pull() error {
+func (m *MockGitClient) Pull() (git.PullResult, error) {
 	m.PullCalled = true
-	return m.ReturnError
+	return m.PullResult, m.ReturnError
 }
 
-func (m *MockGitClient) Commit(message string) (git.CommitHash, error) {
+func (m *MockGitClient) Commit(message string, _ git.Author, signer git.Signer) (git.CommitHash, error) {
 	m.CommitCalled = true
 	m.CommitMessage = message
+	m.CommitSigner = signer
 	return git.CommitHash{}, m.ReturnError
 }
 
 func (m *MockGitClient) Push() error {
+	m.pushMu.Lock()
+	defer m.pushMu.Unlock()
 	m.PushCalled = true
+	m.pushCount++
 	return m.ReturnError
 }
 
+// PushCount returns how many times Push was called, pushes may run on the timer of a push grace period
+func (m *MockGitClient) PushCount() int {
+	m.pushMu.Lock()
+	defer m.pushMu.Unlock()
+	return m.pushCount
+}
+
+func (m *MockGitClient) PushTo(remote git.Remote) error {
+	m.PushedTo = append(m.PushedTo, remote.Name)
+	return m.PushToErrors[remote.Name]
+}
+
 func (m *MockGitClient) EnsureRepo() error {
 	m.EnsureCalled = true
 	return m.ReturnError
 }
 
+func (m *MockGitClient) DiffWorkingTree(path string) (string, error) {
+	m.DiffPath = path
+	return m.Diff, m.ReturnError
+}
+
+func (m *MockGitClient) ListDeletedFiles() ([]string, error) {
+	return m.DeletedFiles, m.ReturnError
+}
+
+func (m *MockGitClient) ListDeletedFilesSince(commit string) ([]string, error) {
+	m.DeletedSince = commit
+	return m.DeletedFiles, m.ReturnError
+}
+
+func (m *MockGitClient) Log(limit int) ([]git.Commit, error) {
+	m.LogLimit = limit
+	return m.Commits, m.ReturnError
+}
+
+func (m *MockGitClient) Status() (git.GitStatus, error) {
+	return m.GitStatus, m.ReturnError
+}
+
+func (m *MockGitClient) CreateBundle(w io.Writer) error {
+	if m.ReturnError != nil {
+		return m.ReturnError
+	}
+	_, err := w.Write(m.Bundle)
+	return err
+}
+
+func (m *MockGitClient) Checkout(ref string) error {
+	if m.ReturnError != nil {
+		return m.ReturnError
+	}
+	m.CheckedOut = ref
+	return nil
+}
+
+func (m *MockGitClient) ReadFileFromHistory(path string) ([]byte, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	content, ok := m.History[path]
+	if !ok {
+		return nil, git.ErrFileNotInHistory
+	}
+	return content, nil
+}
+
 func TestSetupGitRepo(t *testing.T) {
 	mockFS := NewMockFS()
 
@@ -139,12 +240,12 @@ func TestGitOperations(t *testing.T) {
 	})
 
 	t.Run("operations on non-configured workspace", func(t *testing.T) {
-		_, err := s.StageCommitAndPush(1, 1, "test commit")
+		_, err := s.StageCommitAndPush(1, 1, "test commit", git.Author{})
 		if err == nil {
 			t.Error("expected error for non-configured workspace, got nil")
 		}
 
-		err = s.Pull(1, 1)
+		_, err = s.Pull(1, 1)
 		if err == nil {
 			t.Error("expected error for non-configured workspace, got nil")
 		}
@@ -158,7 +259,7 @@ func TestGitOperations(t *testing.T) {
 		s.GitRepos[1][1] = mockClient
 
 		// Test commit and push
-		_, err := s.StageCommitAndPush(1, 1, "test commit")
+		_, err := s.StageCommitAndPush(1, 1, "test commit", git.Author{})
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
@@ -173,7 +274,7 @@ func TestGitOperations(t *testing.T) {
 		}
 
 		// Test pull
-		err = s.Pull(1, 1)
+		_, err = s.Pull(1, 1)
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
@@ -190,19 +291,316 @@ func TestGitOperations(t *testing.T) {
 		s.GitRepos[1][1] = mockClient
 
 		// Test commit error
-		_, err := s.StageCommitAndPush(1, 1, "test commit")
+		_, err := s.StageCommitAndPush(1, 1, "test commit", git.Author{})
 		if err == nil {
 			t.Error("expected error for commit, got nil")
 		}
 
 		// Test pull error
-		err = s.Pull(1, 1)
+		_, err = s.Pull(1, 1)
 		if err == nil {
 			t.Error("expected error for pull, got nil")
 		}
 	})
 }
 
+// exclusiveGitClient records whether its operations ever overlap
+type exclusiveGitClient struct {
+	MockGitClient
+	active     atomic.Int32
+	overlapped atomic.Bool
+}
+
+// enter marks an operation as running for a moment and returns the function ending it
+func (c *exclusiveGitClient) enter() func() {
+	if c.active.Add(1) > 1 {
+		c.overlapped.Store(true)
+	}
+	time.Sleep(time.Millisecond)
+	return func() { c.active.Add(-1) }
+}
+
+func (c *exclusiveGitClient) Commit(string, git.Author, git.Signer) (git.CommitHash, error) {
+	defer c.enter()()
+	return git.CommitHash{}, nil
+}
+
+func (c *exclusiveGitClient) Push() error {
+	defer c.enter()()
+	return nil
+}
+
+func (c *exclusiveGitClient) Status() (git.GitStatus, error) {
+	defer c.enter()()
+	return git.GitStatus{}, nil
+}
+
+func TestPushGracePeriod(t *testing.T) {
+	newService := func(maxPending int) (*storage.Service, *MockGitClient) {
+		s := storage.NewServiceWithOptions("test-root", storage.Options{
+			Fs:                    NewMockFS(),
+			NewGitClient:          func(_, _, _, _, _, _ string) git.Client { return &MockGitClient{} },
+			PushGracePeriod:       50 * time.Millisecond,
+			PushMaxPendingCommits: maxPending,
+		})
+		mockClient := &MockGitClient{}
+		s.GitRepos = map[int]map[int]git.Client{1: {1: mockClient}}
+		return s, mockClient
+	}
+
+	t.Run("commits are pushed once after the grace period", func(t *testing.T) {
+		s, mockClient := newService(0)
+
+		for i := 0; i < 3; i++ {
+			if _, err := s.StageCommitAndPush(1, 1, "test commit", git.Author{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if got := mockClient.PushCount(); got != 0 {
+			t.Errorf("pushes before the grace period = %d, want 0", got)
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for mockClient.PushCount() == 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		time.Sleep(100 * time.Millisecond)
+		if got := mockClient.PushCount(); got != 1 {
+			t.Errorf("pushes after the grace period = %d, want 1", got)
+		}
+	})
+
+	t.Run("max pending commits pushes right away", func(t *testing.T) {
+		s, mockClient := newService(2)
+
+		if _, err := s.StageCommitAndPush(1, 1, "first", git.Author{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := mockClient.PushCount(); got != 0 {
+			t.Errorf("pushes after first commit = %d, want 0", got)
+		}
+
+		if _, err := s.StageCommitAndPush(1, 1, "second", git.Author{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := mockClient.PushCount(); got != 1 {
+			t.Errorf("pushes after second commit = %d, want 1", got)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+		if got := mockClient.PushCount(); got != 1 {
+			t.Errorf("pushes after the grace period = %d, want 1", got)
+		}
+	})
+
+	t.Run("flush pushes pending commits", func(t *testing.T) {
+		s, mockClient := newService(0)
+
+		if _, err := s.StageCommitAndPush(1, 1, "test commit", git.Author{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s.FlushPendingPushes()
+		if got := mockClient.PushCount(); got != 1 {
+			t.Errorf("pushes after flush = %d, want 1", got)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+		if got := mockClient.PushCount(); got != 1 {
+			t.Errorf("pushes after the grace period = %d, want 1", got)
+		}
+	})
+
+	t.Run("pending push uses the current remotes", func(t *testing.T) {
+		s, mockClient := newService(0)
+
+		if _, err := s.StageCommitAndPush(1, 1, "test commit", git.Author{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.SetGitRemotes(1, 1, []git.Remote{{Name: "backup", URL: "https://gitlab.com/user/repo.git"}}); err != nil {
+			t.Fatalf("failed to set remotes: %v", err)
+		}
+		s.FlushPendingPushes()
+		if want := []string{"backup"}; !reflect.DeepEqual(mockClient.PushedTo, want) {
+			t.Errorf("pushed to %v, want %v", mockClient.PushedTo, want)
+		}
+	})
+
+	t.Run("pending push waits for other git operations", func(t *testing.T) {
+		s := storage.NewServiceWithOptions("test-root", storage.Options{
+			Fs:              NewMockFS(),
+			PushGracePeriod: time.Millisecond,
+		})
+		client := &exclusiveGitClient{}
+		s.GitRepos = map[int]map[int]git.Client{1: {1: client}}
+
+		for i := 0; i < 50; i++ {
+			if _, err := s.StageCommitAndPush(1, 1, "test commit", git.Author{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := s.GitStatus(1, 1); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		s.FlushPendingPushes()
+		if client.overlapped.Load() {
+			t.Error("a pending push ran concurrently with another git operation")
+		}
+	})
+
+	t.Run("disable cancels pending push", func(t *testing.T) {
+		s, mockClient := newService(0)
+
+		if _, err := s.StageCommitAndPush(1, 1, "test commit", git.Author{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s.DisableGitRepo(1, 1)
+
+		time.Sleep(100 * time.Millisecond)
+		if got := mockClient.PushCount(); got != 0 {
+			t.Errorf("pushes after disable = %d, want 0", got)
+		}
+	})
+}
+
+func TestPull(t *testing.T) {
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:           NewMockFS(),
+		NewGitClient: func(_, _, _, _, _, _ string) git.Client { return &MockGitClient{} },
+	})
+	mockClient := &MockGitClient{}
+	s.GitRepos = map[int]map[int]git.Client{1: {1: mockClient}}
+
+	testCases := []struct {
+		name         string
+		result       git.PullResult
+		returnErr    error
+		wantFiles    []string
+		wantUpToDate bool
+		wantConflict bool
+	}{
+		{
+			name:      "changes pulled",
+			result:    git.PullResult{Files: []string{"notes/a.md", "b.md"}},
+			wantFiles: []string{"notes/a.md", "b.md"},
+		},
+		{
+			name:         "up to date",
+			result:       git.PullResult{UpToDate: true, Files: []string{}},
+			wantFiles:    []string{},
+			wantUpToDate: true,
+		},
+		{
+			name:         "conflict",
+			returnErr:    &git.PullConflictError{Reason: git.PullConflictDiverged, Files: []string{"b.md"}},
+			wantConflict: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient.PullResult = tc.result
+			mockClient.ReturnError = tc.returnErr
+
+			result, err := s.Pull(1, 1)
+			if tc.wantConflict {
+				var conflictErr *git.PullConflictError
+				if !errors.As(err, &conflictErr) {
+					t.Fatalf("expected pull conflict error, got %v", err)
+				}
+				if conflictErr.Reason != git.PullConflictDiverged || !reflect.DeepEqual(conflictErr.Files, []string{"b.md"}) {
+					t.Errorf("conflict = %+v, want diverged on b.md", conflictErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.UpToDate != tc.wantUpToDate {
+				t.Errorf("UpToDate = %v, want %v", result.UpToDate, tc.wantUpToDate)
+			}
+			if !reflect.DeepEqual(result.Files, tc.wantFiles) {
+				t.Errorf("Files = %v, want %v", result.Files, tc.wantFiles)
+			}
+		})
+	}
+}
+
+func TestCommitHistory(t *testing.T) {
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:           NewMockFS(),
+		NewGitClient: func(_, _, _, _, _, _ string) git.Client { return &MockGitClient{} },
+	})
+
+	if _, err := s.CommitHistory(1, 1, 10); err == nil {
+		t.Error("expected error for non-configured workspace, got nil")
+	}
+
+	commits := []git.Commit{{Hash: "b"}, {Hash: "a"}}
+	mockClient := &MockGitClient{Commits: commits}
+	s.GitRepos = map[int]map[int]git.Client{1: {1: mockClient}}
+
+	history, err := s.CommitHistory(1, 1, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(history, commits) {
+		t.Errorf("history = %+v, want %+v", history, commits)
+	}
+	if mockClient.LogLimit != 10 {
+		t.Errorf("limit = %d, want 10", mockClient.LogLimit)
+	}
+}
+
+func TestGitStatus(t *testing.T) {
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:           NewMockFS(),
+		NewGitClient: func(_, _, _, _, _, _ string) git.Client { return &MockGitClient{} },
+	})
+
+	if _, err := s.GitStatus(1, 1); err == nil {
+		t.Error("expected error for non-configured workspace, got nil")
+	}
+
+	testCases := []struct {
+		name   string
+		status git.GitStatus
+		clean  bool
+	}{
+		{
+			name:   "clean",
+			status: git.GitStatus{Modified: []string{}, Untracked: []string{}},
+			clean:  true,
+		},
+		{
+			name:   "dirty",
+			status: git.GitStatus{Modified: []string{"a.md"}, Untracked: []string{"b.md"}},
+			clean:  false,
+		},
+		{
+			name:   "ahead of remote",
+			status: git.GitStatus{Modified: []string{}, Untracked: []string{}, Ahead: 2},
+			clean:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s.GitRepos = map[int]map[int]git.Client{1: {1: &MockGitClient{GitStatus: tc.status}}}
+
+			status, err := s.GitStatus(1, 1)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(status, tc.status) {
+				t.Errorf("status = %+v, want %+v", status, tc.status)
+			}
+			if status.Clean() != tc.clean {
+				t.Errorf("Clean() = %v, want %v", status.Clean(), tc.clean)
+			}
+		})
+	}
+}
+
 func TestDisableGitRepo(t *testing.T) {
 	mockFS := NewMockFS()
 	s := storage.NewServiceWithOptions("test-root", storage.Options{
@@ -260,3 +658,546 @@ func TestDisableGitRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestGitRemotes(t *testing.T) {
+	mockFS := NewMockFS()
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:              mockFS,
+		NewGitClient:    func(_, _, _, _, _, _ string) git.Client { return &MockGitClient{} },
+		AllowedGitHosts: []string{"github.com", "gitlab.com"},
+	})
+
+	mockClient := &MockGitClient{PushToErrors: map[string]error{"mirror": errors.New("authentication required")}}
+	s.GitRepos[1] = map[int]git.Client{1: mockClient}
+
+	t.Run("disallowed remote URL", func(t *testing.T) {
+		err := s.SetGitRemotes(1, 1, []git.Remote{{Name: "private", URL: "https://git.internal/repo.git"}})
+		if !storage.IsGitURLError(err) {
+			t.Errorf("error = %v, want GitURLError", err)
+		}
+	})
+
+	t.Run("one remote fails", func(t *testing.T) {
+		err := s.SetGitRemotes(1, 1, []git.Remote{
+			{Name: "backup", URL: "https://gitlab.com/user/repo.git"},
+			{Name: "mirror", URL: "https://github.com/user/mirror.git"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err = s.StageCommitAndPush(1, 1, "test commit", git.Author{})
+		var remoteErr *storage.RemotePushError
+		if !errors.As(err, &remoteErr) {
+			t.Fatalf("error = %v, want RemotePushError", err)
+		}
+		if len(remoteErr.Failures) != 1 || remoteErr.Failures[0].Remote != "mirror" {
+			t.Errorf("failures = %+v, want only mirror", remoteErr.Failures)
+		}
+		if !mockClient.PushCalled {
+			t.Error("Push was not called")
+		}
+		if want := []string{"backup", "mirror"}; !reflect.DeepEqual(mockClient.PushedTo, want) {
+			t.Errorf("pushed to %v, want %v", mockClient.PushedTo, want)
+		}
+	})
+
+	t.Run("disabling git clears remotes", func(t *testing.T) {
+		s.DisableGitRepo(1, 1)
+		mockClient.PushedTo = nil
+		s.GitRepos[1] = map[int]git.Client{1: mockClient}
+
+		if _, err := s.StageCommitAndPush(1, 1, "test commit", git.Author{}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(mockClient.PushedTo) != 0 {
+			t.Errorf("pushed to %v, want no additional remotes", mockClient.PushedTo)
+		}
+	})
+}
+
+func TestGitStateConcurrentAccess(t *testing.T) {
+	s := storage.NewServiceWithOptions(t.TempDir(), storage.Options{
+		NewGitClient: func(_, _, _, _, _, _ string) git.Client { return &MockGitClient{} },
+	})
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+	s.GitRepos[1] = map[int]git.Client{1: &MockGitClient{}}
+	key := testSigningKey(t)
+
+	// Settings are changed while other requests write and commit, run with -race to detect unguarded access
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			if err := s.PinGitRef(1, 1, "v1.0"); err != nil {
+				t.Errorf("failed to pin: %v", err)
+			}
+			if err := s.PinGitRef(1, 1, ""); err != nil {
+				t.Errorf("failed to unpin: %v", err)
+			}
+			if err := s.SetGitRemotes(1, 1, []git.Remote{{Name: "backup", URL: "https://gitlab.com/user/repo.git"}}); err != nil {
+				t.Errorf("failed to set remotes: %v", err)
+			}
+			if err := s.SetGitSigningKey(1, 1, key); err != nil {
+				t.Errorf("failed to set signing key: %v", err)
+			}
+			_ = s.SetGitRemotes(1, 1, nil)
+			_ = s.SetGitSigningKey(1, 1, "")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			// Writes fail while the workspace is pinned
+			_ = s.SaveFile(1, 1, "note.md", []byte("content"))
+			_, _ = s.StageCommitAndPush(1, 1, "test commit", git.Author{})
+		}
+	}()
+	wg.Wait()
+}
+
+// testSigningKey returns a new OpenSSH private key
+func testSigningKey(t *testing.T) string {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return string(pem.EncodeToMemory(block))
+}
+
+func TestGitSigningKey(t *testing.T) {
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:           NewMockFS(),
+		NewGitClient: func(_, _, _, _, _, _ string) git.Client { return &MockGitClient{} },
+	})
+
+	mockClient := &MockGitClient{}
+	s.GitRepos[1] = map[int]git.Client{1: mockClient}
+
+	t.Run("unsigned by default", func(t *testing.T) {
+		if _, err := s.StageCommitAndPush(1, 1, "test commit", git.Author{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if mockClient.CommitSigner != nil {
+			t.Error("commit was signed without a signing key")
+		}
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		err := s.SetGitSigningKey(1, 1, "not a key")
+		if !storage.IsSigningKeyError(err) {
+			t.Errorf("error = %v, want SigningKeyError", err)
+		}
+	})
+
+	t.Run("signer passed to commit", func(t *testing.T) {
+		if err := s.SetGitSigningKey(1, 1, testSigningKey(t)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := s.StageCommitAndPush(1, 1, "test commit", git.Author{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if mockClient.CommitSigner == nil {
+			t.Fatal("commit was not signed")
+		}
+		signature, err := mockClient.CommitSigner.Sign(bytes.NewReader([]byte("commit")))
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		if !bytes.HasPrefix(signature, []byte("-----BEGIN SSH SIGNATURE-----")) {
+			t.Errorf("signature = %q, want an armored SSH signature", signature)
+		}
+	})
+
+	t.Run("disabling git clears the key", func(t *testing.T) {
+		s.DisableGitRepo(1, 1)
+		s.GitRepos[1] = map[int]git.Client{1: mockClient}
+
+		if _, err := s.StageCommitAndPush(1, 1, "test commit", git.Author{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if mockClient.CommitSigner != nil {
+			t.Error("commit was signed after git was disabled")
+		}
+	})
+}
+
+func TestValidateGitURL(t *testing.T) {
+	testCases := []struct {
+		name         string
+		allowedHosts []string
+		blockPrivate bool
+		gitURL       string
+		wantErr      bool
+	}{
+		{
+			name:    "no restrictions",
+			gitURL:  "http://127.0.0.1/repo.git",
+			wantErr: false,
+		},
+		{
+			name:         "allowed host",
+			allowedHosts: []string{"github.com", "gitlab.com"},
+			gitURL:       "https://github.com/user/repo.git",
+			wantErr:      false,
+		},
+		{
+			name:         "allowed host with port and different case",
+			allowedHosts: []string{"GitLab.com"},
+			gitURL:       "https://gitlab.com:443/user/repo.git",
+			wantErr:      false,
+		},
+		{
+			name:         "disallowed host",
+			allowedHosts: []string{"github.com"},
+			gitURL:       "https://evil.example.com/user/repo.git",
+			wantErr:      true,
+		},
+		{
+			name:         "public host with private blocking",
+			blockPrivate: true,
+			gitURL:       "https://github.com/user/repo.git",
+			wantErr:      false,
+		},
+		{
+			name:         "private IP",
+			blockPrivate: true,
+			gitURL:       "http://10.0.0.5/repo.git",
+			wantErr:      true,
+		},
+		{
+			name:         "loopback IPv6",
+			blockPrivate: true,
+			gitURL:       "http://[::1]:3000/repo.git",
+			wantErr:      true,
+		},
+		{
+			name:         "link-local metadata address",
+			blockPrivate: true,
+			gitURL:       "http://169.254.169.254/repo.git",
+			wantErr:      true,
+		},
+		{
+			name:         "localhost",
+			blockPrivate: true,
+			gitURL:       "http://localhost:8080/repo.git",
+			wantErr:      true,
+		},
+		{
+			name:         "non-http scheme",
+			blockPrivate: true,
+			gitURL:       "file:///etc/repo.git",
+			wantErr:      true,
+		},
+		{
+			name:         "host resolving to a private address",
+			blockPrivate: true,
+			gitURL:       "https://internal.example.com/repo.git",
+			wantErr:      true,
+		},
+		{
+			name:         "host with one private address among public ones",
+			blockPrivate: true,
+			gitURL:       "https://mixed.example.com/repo.git",
+			wantErr:      true,
+		},
+		{
+			name:         "unresolvable host",
+			blockPrivate: true,
+			gitURL:       "https://missing.example.com/repo.git",
+			wantErr:      true,
+		},
+	}
+
+	// Resolves the host names of the test cases without DNS
+	lookupIP := func(host string) ([]net.IP, error) {
+		switch host {
+		case "github.com":
+			return []net.IP{net.ParseIP("140.82.121.4")}, nil
+		case "internal.example.com":
+			return []net.IP{net.ParseIP("10.0.0.7")}, nil
+		case "mixed.example.com":
+			return []net.IP{net.ParseIP("93.184.216.34"), net.ParseIP("127.0.0.1")}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := storage.NewServiceWithOptions("test-root", storage.Options{
+				Fs:                   NewMockFS(),
+				NewGitClient:         func(_, _, _, _, _, _ string) git.Client { return &MockGitClient{} },
+				AllowedGitHosts:      tc.allowedHosts,
+				BlockPrivateGitHosts: tc.blockPrivate,
+				LookupIP:             lookupIP,
+			})
+
+			err := s.ValidateGitURL(tc.gitURL)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				} else if !storage.IsGitURLError(err) {
+					t.Errorf("expected GitURLError, got %T", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			// SetupGitRepo applies the same check
+			if err := s.SetupGitRepo(1, 1, tc.gitURL, "user", "token", "user", "user@example.com"); err != nil {
+				t.Errorf("SetupGitRepo unexpected error: %v", err)
+			}
+		})
+	}
+
+	t.Run("connection to private address refused", func(t *testing.T) {
+		// A host name can resolve to another address when connecting than when it was validated,
+		// so the address is checked again when git connects
+		storage.NewServiceWithOptions("test-root", storage.Options{
+			Fs:                   NewMockFS(),
+			BlockPrivateGitHosts: true,
+			LookupIP:             lookupIP,
+		})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("request reached the private address")
+		}))
+		defer server.Close()
+
+		err := git.New(server.URL+"/repo.git", "user", "token", t.TempDir(), "user", "user@example.com").Clone()
+		if err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Errorf("Clone() error = %v, want connection not allowed", err)
+		}
+	})
+}
+
+func TestDiffWorkingTree(t *testing.T) {
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:           NewMockFS(),
+		NewGitClient: func(_, _, _, _, _, _ string) git.Client { return &MockGitClient{} },
+	})
+
+	if _, err := s.DiffWorkingTree(1, 1, ""); err == nil {
+		t.Error("expected error for non-configured workspace, got nil")
+	}
+
+	mockClient := &MockGitClient{Diff: "diff --git a/note.md b/note.md"}
+	s.GitRepos[1] = map[int]git.Client{1: mockClient}
+
+	testCases := []struct {
+		name     string
+		path     string
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "whole workspace", path: "", wantPath: ""},
+		{name: "file", path: "notes/note.md", wantPath: "notes/note.md"},
+		{name: "unclean path", path: "notes/../note.md", wantPath: "note.md"},
+		{name: "workspace root", path: ".", wantPath: ""},
+		{name: "path traversal", path: "../other", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient.DiffPath = "unset"
+			diff, err := s.DiffWorkingTree(1, 1, tc.path)
+			if tc.wantErr {
+				if !storage.IsPathValidationError(err) {
+					t.Errorf("expected path validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff != mockClient.Diff {
+				t.Errorf("diff = %q, want %q", diff, mockClient.Diff)
+			}
+			if mockClient.DiffPath != tc.wantPath {
+				t.Errorf("client path = %q, want %q", mockClient.DiffPath, tc.wantPath)
+			}
+		})
+	}
+}
+
+func TestRestoreDeletedFile(t *testing.T) {
+	mockFS := NewMockFS()
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:           mockFS,
+		NewGitClient: func(_, _, _, _, _, _ string) git.Client { return &MockGitClient{} },
+	})
+
+	if err := s.RestoreDeletedFile(1, 1, "note.md"); err == nil {
+		t.Error("expected error for non-configured workspace, got nil")
+	}
+
+	mockClient := &MockGitClient{
+		DeletedFiles: []string{"notes/note.md"},
+		History:      map[string][]byte{"notes/note.md": []byte("restored content")},
+	}
+	s.GitRepos[1] = map[int]git.Client{1: mockClient}
+
+	files, err := s.ListDeletedFiles(1, 1)
+	if err != nil {
+		t.Fatalf("ListDeletedFiles unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[0] != "notes/note.md" {
+		t.Errorf("ListDeletedFiles = %v, want [notes/note.md]", files)
+	}
+
+	t.Run("file exists", func(t *testing.T) {
+		mockFS.StatError = nil
+		if err := s.RestoreDeletedFile(1, 1, "notes/note.md"); !os.IsExist(err) {
+			t.Errorf("expected exists error, got %v", err)
+		}
+	})
+
+	t.Run("restores content", func(t *testing.T) {
+		mockFS.StatError = fs.ErrNotExist
+		if err := s.RestoreDeletedFile(1, 1, "notes/note.md"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		fullPath := filepath.Join("test-root", "1", "1", "notes", "note.md")
+		if got := string(mockFS.WriteCalls[fullPath]); got != "restored content" {
+			t.Errorf("written content = %q, want %q", got, "restored content")
+		}
+	})
+
+	t.Run("not in history", func(t *testing.T) {
+		mockFS.StatError = fs.ErrNotExist
+		if err := s.RestoreDeletedFile(1, 1, "missing.md"); !os.IsNotExist(err) {
+			t.Errorf("expected not exist error, got %v", err)
+		}
+	})
+
+	t.Run("path traversal", func(t *testing.T) {
+		mockFS.StatError = fs.ErrNotExist
+		if err := s.RestoreDeletedFile(1, 1, "../other.md"); !storage.IsPathValidationError(err) {
+			t.Errorf("expected path validation error, got %v", err)
+		}
+	})
+}
+
+func TestCreateBundle(t *testing.T) {
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:           NewMockFS(),
+		NewGitClient: func(_, _, _, _, _, _ string) git.Client { return &MockGitClient{} },
+	})
+
+	var buf bytes.Buffer
+	if err := s.CreateBundle(1, 1, &buf); err == nil {
+		t.Error("expected error for non-configured workspace, got nil")
+	}
+
+	mockClient := &MockGitClient{Bundle: []byte("# v2 git bundle\n")}
+	s.GitRepos[1] = map[int]git.Client{1: mockClient}
+
+	if err := s.CreateBundle(1, 1, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "# v2 git bundle\n" {
+		t.Errorf("bundle = %q, want %q", buf.String(), "# v2 git bundle\n")
+	}
+
+	mockClient.ReturnError = errors.New("git operation failed")
+	if err := s.CreateBundle(1, 1, &buf); err == nil {
+		t.Error("expected error from git client, got nil")
+	}
+}
+
+func TestPinGitRef(t *testing.T) {
+	mockFS := NewMockFS()
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:           mockFS,
+		NewGitClient: func(_, _, _, _, _, _ string) git.Client { return &MockGitClient{} },
+	})
+
+	if err := s.PinGitRef(1, 1, "v1.0"); err == nil {
+		t.Error("expected error for non-configured workspace, got nil")
+	}
+
+	mockClient := &MockGitClient{}
+	s.GitRepos[1] = map[int]git.Client{1: mockClient}
+
+	t.Run("checkout error", func(t *testing.T) {
+		mockClient.ReturnError = git.ErrRefNotFound
+		defer func() { mockClient.ReturnError = nil }()
+
+		if err := s.PinGitRef(1, 1, "missing"); !errors.Is(err, git.ErrRefNotFound) {
+			t.Errorf("expected ref not found error, got %v", err)
+		}
+		if err := s.SaveFile(1, 1, "note.md", []byte("content")); err != nil {
+			t.Errorf("workspace should stay writable after a failed pin, got %v", err)
+		}
+	})
+
+	t.Run("pinned workspace is read-only", func(t *testing.T) {
+		if err := s.PinGitRef(1, 1, "v1.0"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if mockClient.CheckedOut != "v1.0" {
+			t.Errorf("checked out = %q, want %q", mockClient.CheckedOut, "v1.0")
+		}
+
+		writes := map[string]func() error{
+			"save":     func() error { return s.SaveFile(1, 1, "note.md", []byte("content")) },
+			"batch":    func() error { return s.SaveFiles(1, 1, []storage.FileContent{{Path: "a.md"}}) },
+			"move":     func() error { return s.MoveFile(1, 1, "note.md", "moved.md") },
+			"transfer": func() error { return s.TransferFile(1, 2, "other.md", 1, "note.md", true) },
+			"delete":   func() error { return s.DeleteFile(1, 1, "note.md") },
+			"restore":  func() error { return s.RestoreDeletedFile(1, 1, "note.md") },
+			"pull": func() error {
+				_, err := s.Pull(1, 1)
+				return err
+			},
+			"commit": func() error {
+				_, err := s.StageCommitAndPush(1, 1, "message", git.Author{})
+				return err
+			},
+		}
+		for name, write := range writes {
+			if err := write(); !storage.IsWorkspacePinnedError(err) {
+				t.Errorf("%s: expected workspace pinned error, got %v", name, err)
+			}
+		}
+		if mockClient.PullCalled || mockClient.CommitCalled {
+			t.Error("git client should not be called for a pinned workspace")
+		}
+
+		if err := s.SaveFile(1, 2, "other.md", []byte("content")); err != nil {
+			t.Errorf("other workspaces should stay writable, got %v", err)
+		}
+	})
+
+	t.Run("unpin", func(t *testing.T) {
+		if err := s.PinGitRef(1, 1, ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if mockClient.CheckedOut != "" {
+			t.Errorf("checked out = %q, want the branch", mockClient.CheckedOut)
+		}
+		if err := s.SaveFile(1, 1, "note.md", []byte("content")); err != nil {
+			t.Errorf("unexpected error after unpinning: %v", err)
+		}
+	})
+
+	t.Run("disabling git unpins", func(t *testing.T) {
+		if err := s.PinGitRef(1, 1, "v1.0"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s.DisableGitRepo(1, 1)
+		if err := s.SaveFile(1, 1, "note.md", []byte("content")); err != nil {
+			t.Errorf("unexpected error after disabling git: %v", err)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// SaveHook processes files saved with SaveFile, e.g. to format them or to update derived files.
+type SaveHook interface {
+	// Name identifies the hook in errors and logs
+	Name() string
+	// Required reports whether an error from the hook fails the save. Errors of optional hooks are logged.
+	Required() bool
+	// BeforeSave is called before the file at path is written and returns the content to write instead
+	BeforeSave(path string, content []byte) ([]byte, error)
+	// AfterSave is called after the file at path was written with the written content
+	AfterSave(path string, content []byte) error
+}
+
+// textHook is a built-in hook transforming text files, binary content is written unchanged
+type textHook struct {
+	name      string
+	transform func(content []byte) []byte
+}
+
+func (h *textHook) Name() string                   { return h.name }
+func (h *textHook) Required() bool                 { return false }
+func (h *textHook) AfterSave(string, []byte) error { return nil }
+
+func (h *textHook) BeforeSave(_ string, content []byte) ([]byte, error) {
+	if isBinary(content) {
+		return content, nil
+	}
+	return h.transform(content), nil
+}
+
+// NormalizeLineEndingsHook returns a hook that converts CRLF and CR line endings of text files to LF
+func NormalizeLineEndingsHook() SaveHook {
+	return &textHook{
+		name: "normalizeLineEndings",
+		transform: func(content []byte) []byte {
+			content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+			return bytes.ReplaceAll(content, []byte("\r"), []byte("\n"))
+		},
+	}
+}
+
+var trailingWhitespace = regexp.MustCompile(`(?m)[ \t]+$`)
+
+// TrimTrailingWhitespaceHook returns a hook that removes spaces and tabs at the end of lines of text files
+func TrimTrailingWhitespaceHook() SaveHook {
+	return &textHook{
+		name: "trimTrailingWhitespace",
+		transform: func(content []byte) []byte {
+			return trailingWhitespace.ReplaceAll(content, nil)
+		},
+	}
+}
+
+// runBeforeSaveHooks passes the content through the BeforeSave of each hook in order
+func runBeforeSaveHooks(hooks []SaveHook, path string, content []byte) ([]byte, error) {
+	for _, hook := range hooks {
+		transformed, err := hook.BeforeSave(path, content)
+		if err != nil {
+			if hook.Required() {
+				return nil, &SaveHookError{Hook: hook.Name(), Err: err}
+			}
+			getLogger().Warn("save hook failed",
+				"hook", hook.Name(),
+				"path", path,
+				"error", err.Error())
+			continue
+		}
+		content = transformed
+	}
+	return content, nil
+}
+
+// runAfterSaveHooks calls the AfterSave of each hook in order
+func runAfterSaveHooks(hooks []SaveHook, path string, content []byte) error {
+	for _, hook := range hooks {
+		if err := hook.AfterSave(path, content); err != nil {
+			if hook.Required() {
+				return &SaveHookError{Hook: hook.Name(), Err: err}
+			}
+			getLogger().Warn("save hook failed",
+				"hook", hook.Name(),
+				"path", path,
+				"error", err.Error())
+		}
+	}
+	return nil
+}
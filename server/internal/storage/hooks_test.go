@@ -0,0 +1,148 @@
+package storage_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+// mockSaveHook is a configurable SaveHook for testing
+type mockSaveHook struct {
+	required  bool
+	transform func([]byte) []byte
+	beforeErr error
+	afterErr  error
+	saved     []byte
+}
+
+func (h *mockSaveHook) Name() string   { return "mock" }
+func (h *mockSaveHook) Required() bool { return h.required }
+
+func (h *mockSaveHook) BeforeSave(_ string, content []byte) ([]byte, error) {
+	if h.beforeErr != nil {
+		return nil, h.beforeErr
+	}
+	if h.transform != nil {
+		return h.transform(content), nil
+	}
+	return content, nil
+}
+
+func (h *mockSaveHook) AfterSave(_ string, content []byte) error {
+	h.saved = content
+	return h.afterErr
+}
+
+func TestSaveHooks(t *testing.T) {
+	mockFS := NewMockFS()
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs: mockFS,
+	})
+	fullPath := filepath.Join("test-root", "1", "1", "note.md")
+
+	upper := func(content []byte) []byte { return bytes.ToUpper(content) }
+	hookErr := errors.New("hook failed")
+
+	testCases := []struct {
+		name        string
+		content     string
+		hooks       []storage.SaveHook
+		wantContent string
+		wantErr     bool
+	}{
+		{
+			name:        "no hooks",
+			content:     "line \r\n",
+			wantContent: "line \r\n",
+		},
+		{
+			name:        "transforming hook",
+			content:     "hello",
+			hooks:       []storage.SaveHook{&mockSaveHook{transform: upper}},
+			wantContent: "HELLO",
+		},
+		{
+			name:        "normalize line endings",
+			content:     "one\r\ntwo\rthree\n",
+			hooks:       []storage.SaveHook{storage.NormalizeLineEndingsHook()},
+			wantContent: "one\ntwo\nthree\n",
+		},
+		{
+			name:        "trim trailing whitespace",
+			content:     "one  \ntwo\t\n  indented \n",
+			hooks:       []storage.SaveHook{storage.TrimTrailingWhitespaceHook()},
+			wantContent: "one\ntwo\n  indented\n",
+		},
+		{
+			name:    "hooks run in order",
+			content: "one \r\ntwo\t\r\n",
+			hooks: []storage.SaveHook{
+				storage.NormalizeLineEndingsHook(),
+				storage.TrimTrailingWhitespaceHook(),
+				&mockSaveHook{transform: upper},
+			},
+			wantContent: "ONE\nTWO\n",
+		},
+		{
+			name:        "binary content is not transformed",
+			content:     "\x00\x01 \r\n",
+			hooks:       []storage.SaveHook{storage.NormalizeLineEndingsHook(), storage.TrimTrailingWhitespaceHook()},
+			wantContent: "\x00\x01 \r\n",
+		},
+		{
+			name:        "optional hook error is ignored",
+			content:     "hello",
+			hooks:       []storage.SaveHook{&mockSaveHook{beforeErr: hookErr}, &mockSaveHook{transform: upper}},
+			wantContent: "HELLO",
+		},
+		{
+			name:    "required hook error fails the save",
+			content: "hello",
+			hooks:   []storage.SaveHook{&mockSaveHook{required: true, beforeErr: hookErr}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			delete(mockFS.WriteCalls, fullPath)
+
+			err := s.SaveFile(1, 1, "note.md", []byte(tc.content), tc.hooks...)
+			if tc.wantErr {
+				if !storage.IsSaveHookError(err) || !errors.Is(err, hookErr) {
+					t.Errorf("expected save hook error, got %v", err)
+				}
+				if _, ok := mockFS.WriteCalls[fullPath]; ok {
+					t.Error("file was written despite failing hook")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := string(mockFS.WriteCalls[fullPath]); got != tc.wantContent {
+				t.Errorf("written content = %q, want %q", got, tc.wantContent)
+			}
+		})
+	}
+
+	t.Run("after save", func(t *testing.T) {
+		hook := &mockSaveHook{transform: upper}
+		if err := s.SaveFile(1, 1, "note.md", []byte("hello"), hook); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(hook.saved) != "HELLO" {
+			t.Errorf("AfterSave content = %q, want %q", hook.saved, "HELLO")
+		}
+
+		required := &mockSaveHook{required: true, afterErr: hookErr}
+		if err := s.SaveFile(1, 1, "note.md", []byte("hello"), required); !storage.IsSaveHookError(err) {
+			t.Errorf("expected save hook error, got %v", err)
+		}
+	})
+}
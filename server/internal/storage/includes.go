@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// includePattern matches transclusion directives such as {{include: notes/other.md}}
+var includePattern = regexp.MustCompile(`\{\{\s*include:\s*([^{}\n]+?)\s*\}\}`)
+
+// maxIncludeDepth limits how deeply nested included files are resolved
+const maxIncludeDepth = 8
+
+// maxIncludes limits how many directives are replaced while resolving the includes of a file
+const maxIncludes = 256
+
+// defaultMaxIncludeSize is the number of bytes includes may add to a file if no limit is set
+const defaultMaxIncludeSize = 10 << 20
+
+// ResolveIncludes returns the content of the file at filePath with {{include: path}} directives
+// replaced by the content of the referenced files, which are resolved the same way up to maxIncludeDepth.
+// Include paths are relative to the workspace root. Directives referencing invalid, missing or binary files,
+// or a file that is already being included, are left unchanged.
+// ErrBinaryFile is returned if the file itself does not look like text, and ErrIncludeLimit if more than
+// maxIncludes directives are replaced or the included content exceeds the configured size.
+func (s *Service) ResolveIncludes(userID, workspaceID int, filePath string) ([]byte, error) {
+	fullPath, err := s.ValidatePath(userID, workspaceID, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.fs.ReadFile(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	if isBinary(content) {
+		return nil, ErrBinaryFile
+	}
+
+	e := &includeExpansion{
+		s:           s,
+		userID:      userID,
+		workspaceID: workspaceID,
+		visiting:    map[string]bool{fullPath: true},
+		remaining:   s.maxIncludeSize,
+	}
+	if e.remaining <= 0 {
+		e.remaining = defaultMaxIncludeSize
+	}
+	expanded := e.expand(content, 1)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return expanded, nil
+}
+
+// includeExpansion is the state of resolving the includes of one file
+type includeExpansion struct {
+	s           *Service
+	userID      int
+	workspaceID int
+	visiting    map[string]bool // the files on the current include chain
+	includes    int             // the directives replaced so far
+	remaining   int64           // the bytes that may still be included
+	err         error
+}
+
+// expand replaces the include directives in content. Once a limit is exceeded, err is set
+// and the remaining directives are left unchanged.
+func (e *includeExpansion) expand(content []byte, depth int) []byte {
+	if depth > maxIncludeDepth {
+		return content
+	}
+
+	return includePattern.ReplaceAllFunc(content, func(directive []byte) []byte {
+		if e.err != nil {
+			return directive
+		}
+
+		path := string(includePattern.FindSubmatch(directive)[1])
+		fullPath, err := e.s.ValidatePath(e.userID, e.workspaceID, path)
+		if err != nil || e.visiting[fullPath] {
+			return directive
+		}
+
+		included, err := e.s.fs.ReadFile(fullPath)
+		if err != nil || isBinary(included) {
+			return directive
+		}
+
+		// Every include copies the file again, so a few files including each other
+		// repeatedly would otherwise grow the content exponentially with the depth
+		e.includes++
+		e.remaining -= int64(len(included))
+		if e.includes > maxIncludes || e.remaining < 0 {
+			e.err = ErrIncludeLimit
+			return directive
+		}
+
+		e.visiting[fullPath] = true
+		expanded := e.expand(included, depth+1)
+		delete(e.visiting, fullPath)
+
+		// The directive keeps its own line break, the one ending the included file is dropped
+		return bytes.TrimSuffix(expanded, []byte("\n"))
+	})
+}
@@ -0,0 +1,146 @@
+package storage_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestResolveIncludes(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	files := map[string]string{
+		"simple.md":         "# Notes\n{{include: parts/intro.md}}\nEnd\n",
+		"parts/intro.md":    "Intro text\n",
+		"nested.md":         "{{include: parts/outer.md}}",
+		"parts/outer.md":    "outer {{ include: parts/intro.md }}",
+		"missing.md":        "before {{include: nowhere.md}} after",
+		"traversal.md":      "{{include: ../../other/secret.md}}",
+		"cycle-a.md":        "A {{include: cycle-b.md}}",
+		"cycle-b.md":        "B {{include: cycle-a.md}}",
+		"self.md":           "self {{include: self.md}}",
+		"repeated.md":       "{{include: parts/intro.md}} and {{include: parts/intro.md}}",
+		"includes-image.md": "{{include: image.bin}}",
+		"image.bin":         "\x00\x01\x02",
+	}
+	for path, content := range files {
+		if err := s.SaveFile(1, 1, path, []byte(content)); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{name: "simple include", path: "simple.md", want: "# Notes\nIntro text\nEnd\n"},
+		{name: "nested include", path: "nested.md", want: "outer Intro text"},
+		{name: "missing include", path: "missing.md", want: "before {{include: nowhere.md}} after"},
+		{name: "path traversal", path: "traversal.md", want: "{{include: ../../other/secret.md}}"},
+		{name: "cycle", path: "cycle-a.md", want: "A B {{include: cycle-a.md}}"},
+		{name: "self include", path: "self.md", want: "self {{include: self.md}}"},
+		{name: "repeated include", path: "repeated.md", want: "Intro text and Intro text"},
+		{name: "binary include", path: "includes-image.md", want: "{{include: image.bin}}"},
+		{name: "binary file", path: "image.bin", wantErr: storage.ErrBinaryFile},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.ResolveIncludes(1, 1, tc.path)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("expected error %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("ResolveIncludes = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("max depth", func(t *testing.T) {
+		// Every level includes the next one, the chain is longer than the depth limit
+		for i := 0; i < 20; i++ {
+			content := "level"
+			if i < 19 {
+				content += " {{include: chain/" + string(rune('a'+i+1)) + ".md}}"
+			}
+			if err := s.SaveFile(1, 1, "chain/"+string(rune('a'+i))+".md", []byte(content)); err != nil {
+				t.Fatalf("failed to save chain file: %v", err)
+			}
+		}
+
+		got, err := s.ResolveIncludes(1, 1, "chain/a.md")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := strings.Count(string(got), "level"); n != 9 {
+			t.Errorf("resolved %d levels, want 9", n)
+		}
+		if !strings.HasSuffix(string(got), "{{include: chain/j.md}}") {
+			t.Errorf("expected the include beyond the depth limit to be left unchanged, got %q", got)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := s.ResolveIncludes(1, 1, "nope.md"); err == nil {
+			t.Error("expected error for missing file, got nil")
+		}
+	})
+
+	t.Run("too many includes", func(t *testing.T) {
+		// Every level includes the next one four times, 4^8 includes in total
+		for i := 0; i < 8; i++ {
+			content := strings.Repeat("{{include: bomb/"+string(rune('a'+i+1))+".md}}\n", 4)
+			if err := s.SaveFile(1, 1, "bomb/"+string(rune('a'+i))+".md", []byte(content)); err != nil {
+				t.Fatalf("failed to save bomb file: %v", err)
+			}
+		}
+
+		if _, err := s.ResolveIncludes(1, 1, "bomb/a.md"); !errors.Is(err, storage.ErrIncludeLimit) {
+			t.Errorf("expected ErrIncludeLimit, got %v", err)
+		}
+	})
+}
+
+func TestResolveIncludesSizeLimit(t *testing.T) {
+	s := storage.NewServiceWithOptions(t.TempDir(), storage.Options{MaxIncludeSize: 16})
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	files := map[string]string{
+		"page.md":  "{{include: small.md}}",
+		"twice.md": "{{include: small.md}} {{include: small.md}}",
+		"small.md": "0123456789",
+	}
+	for path, content := range files {
+		if err := s.SaveFile(1, 1, path, []byte(content)); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+
+	got, err := s.ResolveIncludes(1, 1, "page.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != "0123456789" {
+		t.Errorf("ResolveIncludes = %q, want %q", got, "0123456789")
+	}
+
+	if _, err := s.ResolveIncludes(1, 1, "twice.md"); !errors.Is(err, storage.ErrIncludeLimit) {
+		t.Errorf("expected ErrIncludeLimit, got %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"bytes"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Links between notes, either [[wiki links]] with an optional heading and label or markdown links
+var (
+	wikiLinkPattern     = regexp.MustCompile(`\[\[([^\[\]|#]+)(?:#[^\[\]|]*)?(?:\|[^\[\]]*)?\]\]`)
+	markdownLinkPattern = regexp.MustCompile(`\[[^\[\]]*\]\(\s*<?([^()\s<>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	urlSchemePattern    = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// LinkGraph holds the links between the files of a workspace. Links are read from markdown files,
+// all files of the workspace can be link targets. Paths are relative to the workspace.
+type LinkGraph struct {
+	// Links maps each file to the files it links to
+	Links map[string][]string `json:"links"`
+	// Backlinks maps each file to the files linking to it
+	Backlinks map[string][]string `json:"backlinks"`
+	// Broken maps each file to the link targets that did not resolve to a file
+	Broken map[string][]string `json:"broken"`
+
+	files map[string]bool
+}
+
+// HasFile reports whether the file at path was part of the workspace when the graph was built
+func (g *LinkGraph) HasFile(path string) bool {
+	return g.files[filepath.Clean(path)]
+}
+
+// BacklinksTo returns the files linking to the file at path, ordered by path
+func (g *LinkGraph) BacklinksTo(path string) []string {
+	if backlinks := g.Backlinks[filepath.Clean(path)]; backlinks != nil {
+		return backlinks
+	}
+	return []string{}
+}
+
+// BuildLinkGraph reads the wiki and markdown links of the markdown files of the workspace and resolves them.
+// A link is resolved as a path relative to the linking file, then relative to the workspace and otherwise
+// by file name like FindFileByName, ignoring case. Wiki links without an extension link to markdown files.
+// If several files have the name, the one in the directory of the linking file wins, then the shortest path.
+// External URLs and links within a file are ignored, links in fenced code blocks are not read.
+// The .git directory is skipped, as are files larger than 1 MiB and symlinks
+// unless following symlinks is enabled.
+func (s *Service) BuildLinkGraph(userID, workspaceID int) (LinkGraph, error) {
+	workspacePath := s.GetWorkspacePath(userID, workspaceID)
+
+	var paths []string
+	err := s.walkFiles(workspacePath, func(entry walkEntry) error {
+		paths = append(paths, entry.path)
+		return nil
+	})
+	if err != nil {
+		return LinkGraph{}, err
+	}
+	sort.Strings(paths)
+
+	graph := LinkGraph{
+		Links:     map[string][]string{},
+		Backlinks: map[string][]string{},
+		Broken:    map[string][]string{},
+		files:     make(map[string]bool, len(paths)),
+	}
+	for _, path := range paths {
+		graph.files[path] = true
+	}
+
+	for _, source := range paths {
+		if !isMarkdownFile(source) {
+			continue
+		}
+		fullPath := filepath.Join(workspacePath, source)
+		info, err := s.fs.Stat(fullPath)
+		if s.fs.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return LinkGraph{}, err
+		}
+		if info.Size() > maxNoteSize {
+			continue
+		}
+		content, err := s.fs.ReadFile(fullPath)
+		if err != nil {
+			return LinkGraph{}, err
+		}
+
+		linked := map[string]bool{}
+		broken := map[string]bool{}
+		for _, link := range extractLinks(content) {
+			target, ok := graph.resolveLink(source, link.target, link.wiki, paths)
+			if !ok {
+				if !broken[link.target] {
+					broken[link.target] = true
+					graph.Broken[source] = append(graph.Broken[source], link.target)
+				}
+				continue
+			}
+			if target == source || linked[target] {
+				continue
+			}
+			linked[target] = true
+			graph.Links[source] = append(graph.Links[source], target)
+			graph.Backlinks[target] = append(graph.Backlinks[target], source)
+		}
+		sort.Strings(graph.Links[source])
+		sort.Strings(graph.Broken[source])
+	}
+
+	return graph, nil
+}
+
+// link is a link target as written in a note
+type link struct {
+	target string
+	wiki   bool
+}
+
+// extractLinks returns the wiki and markdown links of content outside of fenced code blocks
+func extractLinks(content []byte) []link {
+	var links []link
+	inFence := false
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		trimmed := bytes.TrimSpace(line)
+		if bytes.HasPrefix(trimmed, []byte("```")) || bytes.HasPrefix(trimmed, []byte("~~~")) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		for _, match := range wikiLinkPattern.FindAllSubmatch(line, -1) {
+			if target := strings.TrimSpace(string(match[1])); target != "" {
+				links = append(links, link{target: target, wiki: true})
+			}
+		}
+		for _, match := range markdownLinkPattern.FindAllSubmatch(line, -1) {
+			target := string(match[1])
+			if urlSchemePattern.MatchString(target) || strings.HasPrefix(target, "#") {
+				continue
+			}
+			if i := strings.IndexAny(target, "#?"); i >= 0 {
+				target = target[:i]
+			}
+			if unescaped, err := url.PathUnescape(target); err == nil {
+				target = unescaped
+			}
+			if target == "" {
+				continue
+			}
+			links = append(links, link{target: target})
+		}
+	}
+	return links
+}
+
+// resolveLink returns the file the link target in source refers to
+func (g *LinkGraph) resolveLink(source, target string, wiki bool, paths []string) (string, bool) {
+	target = filepath.FromSlash(target)
+	if wiki && filepath.Ext(target) == "" {
+		target += ".md"
+	}
+
+	// Paths are tried relative to the linking file, then to the workspace
+	candidates := []string{
+		filepath.Join(filepath.Dir(source), target),
+		filepath.Clean(strings.TrimPrefix(target, string(filepath.Separator))),
+	}
+	for _, candidate := range candidates {
+		if g.files[candidate] {
+			return candidate, true
+		}
+	}
+
+	// Fall back to the file name anywhere in the workspace
+	name := filepath.Base(target)
+	best := ""
+	for _, path := range paths {
+		if !fileNameMatches(filepath.Base(path), name, false) {
+			continue
+		}
+		if best == "" || closerMatch(source, path, best) {
+			best = path
+		}
+	}
+	return best, best != ""
+}
+
+// closerMatch reports whether path is a better match than best for a link in source
+func closerMatch(source, path, best string) bool {
+	dir := filepath.Dir(source)
+	if inDir, bestInDir := filepath.Dir(path) == dir, filepath.Dir(best) == dir; inDir != bestInDir {
+		return inDir
+	}
+	return len(path) < len(best)
+}
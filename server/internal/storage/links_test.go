@@ -0,0 +1,79 @@
+package storage_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestBuildLinkGraph(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	files := map[string]string{
+		"index.md": "See [[Ideas]], [[projects/plan|the plan]] and [[Missing Note]].\n" +
+			"Also [ideas](ideas.md#today), [external](https://example.com) and [top](#top).\n",
+		"ideas.md":                 "Back to [[index]] and [[ideas]].\n```\n[[not a link]]\n```\n",
+		"projects/plan.md":         "[Ideas](../ideas.md), [logo](images/logo.png), [gone](old.md) and [[Notes]]\n",
+		"projects/notes.md":        "Nothing here\n",
+		"archive/notes.md":         "[[plan]]\n",
+		"projects/images/logo.png": "png",
+		".git/links.md":            "[[index]]\n",
+	}
+	for path, content := range files {
+		if err := s.SaveFile(1, 1, path, []byte(content)); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+
+	graph, err := s.BuildLinkGraph(1, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	plan := filepath.Join("projects", "plan.md")
+	projectNotes := filepath.Join("projects", "notes.md")
+	archiveNotes := filepath.Join("archive", "notes.md")
+	logo := filepath.Join("projects", "images", "logo.png")
+
+	wantLinks := map[string][]string{
+		"index.md":   {"ideas.md", plan},
+		"ideas.md":   {"index.md"},
+		plan:         {"ideas.md", logo, projectNotes},
+		archiveNotes: {plan},
+	}
+	if !reflect.DeepEqual(graph.Links, wantLinks) {
+		t.Errorf("Links = %v, want %v", graph.Links, wantLinks)
+	}
+
+	wantBacklinks := map[string][]string{
+		"index.md":   {"ideas.md"},
+		"ideas.md":   {"index.md", plan},
+		plan:         {archiveNotes, "index.md"},
+		logo:         {plan},
+		projectNotes: {plan},
+	}
+	if !reflect.DeepEqual(graph.Backlinks, wantBacklinks) {
+		t.Errorf("Backlinks = %v, want %v", graph.Backlinks, wantBacklinks)
+	}
+
+	wantBroken := map[string][]string{
+		"index.md": {"Missing Note"},
+		plan:       {"old.md"},
+	}
+	if !reflect.DeepEqual(graph.Broken, wantBroken) {
+		t.Errorf("Broken = %v, want %v", graph.Broken, wantBroken)
+	}
+
+	if !graph.HasFile(projectNotes) || graph.HasFile("missing.md") {
+		t.Errorf("HasFile reports the wrong files")
+	}
+	if backlinks := graph.BacklinksTo(archiveNotes); backlinks == nil || len(backlinks) != 0 {
+		t.Errorf("BacklinksTo(%q) = %v, want empty", archiveNotes, backlinks)
+	}
+}
@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// LintIssue is a problem found in a markdown file
+type LintIssue struct {
+	Rule    string `json:"rule"`
+	Line    int    `json:"line,omitempty"` // 1-based line of the issue, 0 for the whole file
+	Message string `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("line %d: %s (%s)", i.Line, i.Message, i.Rule)
+	}
+	return fmt.Sprintf("%s (%s)", i.Message, i.Rule)
+}
+
+// Linter checks the content of a markdown file, see DefaultLinter for the built-in rules
+type Linter interface {
+	Lint(content []byte) []LintIssue
+}
+
+// lintRule is a single check of the default linter
+type lintRule func(doc *markdownDoc) []LintIssue
+
+// ruleLinter is a Linter running a set of rules in order
+type ruleLinter struct {
+	rules []lintRule
+}
+
+// DefaultLinter returns a linter with the default rule set: the required frontmatter fields,
+// a single top-level heading and headings that increase by one level at a time
+func DefaultLinter(requiredFrontmatter []string) Linter {
+	var rules []lintRule
+	if len(requiredFrontmatter) > 0 {
+		rules = append(rules, requiredFrontmatterRule(requiredFrontmatter))
+	}
+	rules = append(rules, singleTitleRule, headingIncrementRule)
+	return &ruleLinter{rules: rules}
+}
+
+func (l *ruleLinter) Lint(content []byte) []LintIssue {
+	doc := parseMarkdownDoc(content)
+	var issues []LintIssue
+	for _, rule := range l.rules {
+		issues = append(issues, rule(doc)...)
+	}
+	return issues
+}
+
+// markdownHeading is an ATX heading of a markdown file
+type markdownHeading struct {
+	Level int
+	Line  int
+}
+
+// markdownDoc is the structure of a markdown file the lint rules check
+type markdownDoc struct {
+	// Frontmatter holds the top-level keys of the frontmatter, nil if the file has none
+	Frontmatter map[string]string
+	Headings    []markdownHeading
+}
+
+var (
+	atxHeading       = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]|$)`)
+	frontmatterKey   = regexp.MustCompile(`^([A-Za-z0-9_-]+)[ \t]*:(.*)$`)
+	codeFenceOpening = regexp.MustCompile("^ {0,3}(```|~~~)")
+)
+
+// parseMarkdownDoc extracts the frontmatter keys and the headings outside of code blocks
+func parseMarkdownDoc(content []byte) *markdownDoc {
+	doc := &markdownDoc{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+
+	line := 0
+	inFrontmatter := false
+	key := ""
+	fence := ""
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		if line == 1 && text == "---" {
+			inFrontmatter = true
+			doc.Frontmatter = map[string]string{}
+			continue
+		}
+		if inFrontmatter {
+			if text == "---" || text == "..." {
+				inFrontmatter = false
+			} else if m := frontmatterKey.FindStringSubmatch(text); m != nil {
+				key = m[1]
+				doc.Frontmatter[key] = strings.TrimSpace(m[2])
+			} else if key != "" && strings.TrimSpace(text) != "" && doc.Frontmatter[key] == "" {
+				// An indented block or list below a key is its value
+				doc.Frontmatter[key] = strings.TrimSpace(text)
+			}
+			continue
+		}
+
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimLeft(text, " "), fence) {
+				fence = ""
+			}
+			continue
+		}
+		if m := codeFenceOpening.FindStringSubmatch(text); m != nil {
+			fence = m[1]
+			continue
+		}
+
+		if m := atxHeading.FindStringSubmatch(text); m != nil {
+			doc.Headings = append(doc.Headings, markdownHeading{Level: len(m[1]), Line: line})
+		}
+	}
+	return doc
+}
+
+// requiredFrontmatterRule returns a rule that reports frontmatter fields that are missing or empty
+func requiredFrontmatterRule(fields []string) lintRule {
+	return func(doc *markdownDoc) []LintIssue {
+		if doc.Frontmatter == nil {
+			return []LintIssue{{
+				Rule:    "required-frontmatter",
+				Line:    1,
+				Message: fmt.Sprintf("missing frontmatter with fields %s", strings.Join(fields, ", ")),
+			}}
+		}
+		var issues []LintIssue
+		for _, field := range fields {
+			if value, ok := doc.Frontmatter[field]; !ok || value == "" || value == `""` || value == "''" {
+				issues = append(issues, LintIssue{
+					Rule:    "required-frontmatter",
+					Line:    1,
+					Message: fmt.Sprintf("missing frontmatter field %q", field),
+				})
+			}
+		}
+		return issues
+	}
+}
+
+// singleTitleRule reports top-level headings after the first one
+func singleTitleRule(doc *markdownDoc) []LintIssue {
+	var issues []LintIssue
+	seen := false
+	for _, heading := range doc.Headings {
+		if heading.Level != 1 {
+			continue
+		}
+		if seen {
+			issues = append(issues, LintIssue{
+				Rule:    "single-title",
+				Line:    heading.Line,
+				Message: "multiple top-level headings",
+			})
+		}
+		seen = true
+	}
+	return issues
+}
+
+// headingIncrementRule reports headings more than one level deeper than the previous heading
+func headingIncrementRule(doc *markdownDoc) []LintIssue {
+	var issues []LintIssue
+	previous := 0
+	for _, heading := range doc.Headings {
+		if previous > 0 && heading.Level > previous+1 {
+			issues = append(issues, LintIssue{
+				Rule:    "heading-increment",
+				Line:    heading.Line,
+				Message: fmt.Sprintf("heading level %d follows level %d", heading.Level, previous),
+			})
+		}
+		previous = heading.Level
+	}
+	return issues
+}
+
+// LintHook is a save hook linting markdown files. In strict mode files with issues are rejected
+// with a LintError, otherwise they are saved and the issues are available from Issues.
+type LintHook struct {
+	linter Linter
+	strict bool
+	issues []LintIssue
+}
+
+// NewLintHook returns a save hook linting markdown files with linter
+func NewLintHook(linter Linter, strict bool) *LintHook {
+	return &LintHook{linter: linter, strict: strict}
+}
+
+func (h *LintHook) Name() string                   { return "lint" }
+func (h *LintHook) Required() bool                 { return h.strict }
+func (h *LintHook) AfterSave(string, []byte) error { return nil }
+
+// Issues returns the issues found in the last saved file
+func (h *LintHook) Issues() []LintIssue {
+	return h.issues
+}
+
+func (h *LintHook) BeforeSave(path string, content []byte) ([]byte, error) {
+	h.issues = nil
+	if !isMarkdownFile(path) || isBinary(content) {
+		return content, nil
+	}
+
+	h.issues = h.linter.Lint(content)
+	if h.strict && len(h.issues) > 0 {
+		return nil, &LintError{Issues: h.issues}
+	}
+	return content, nil
+}
+
+// isMarkdownFile reports whether path has a markdown file extension
+func isMarkdownFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
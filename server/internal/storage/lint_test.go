@@ -0,0 +1,122 @@
+package storage_test
+
+import (
+	"testing"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestDefaultLinter(t *testing.T) {
+	testCases := []struct {
+		name      string
+		required  []string
+		content   string
+		wantRules []string
+	}{
+		{
+			name:    "valid note",
+			content: "# Title\n\n## Section\n\n### Subsection\n\n## Another\n",
+		},
+		{
+			name:      "missing frontmatter",
+			required:  []string{"title"},
+			content:   "# Title\n",
+			wantRules: []string{"required-frontmatter"},
+		},
+		{
+			name:      "missing frontmatter fields",
+			required:  []string{"title", "tags", "date"},
+			content:   "---\ntitle: Note\ntags: \"\"\n---\n# Title\n",
+			wantRules: []string{"required-frontmatter", "required-frontmatter"},
+		},
+		{
+			name:     "required frontmatter present",
+			required: []string{"title", "tags"},
+			content:  "---\ntitle: Note\ntags:\n  - one\n---\n# Title\n",
+		},
+		{
+			name:      "multiple titles",
+			content:   "# Title\n\n# Another title\n",
+			wantRules: []string{"single-title"},
+		},
+		{
+			name:      "skipped heading level",
+			content:   "# Title\n\n### Subsection\n",
+			wantRules: []string{"heading-increment"},
+		},
+		{
+			name:    "headings in code blocks are ignored",
+			content: "# Title\n\n```sh\n# comment\n### not a heading\n```\n",
+		},
+		{
+			name:    "frontmatter keys are not headings",
+			content: "---\ntitle: Note\n# comment: value\n---\n# Title\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := storage.DefaultLinter(tc.required).Lint([]byte(tc.content))
+			if len(issues) != len(tc.wantRules) {
+				t.Fatalf("Lint() = %+v, want rules %v", issues, tc.wantRules)
+			}
+			for i, issue := range issues {
+				if issue.Rule != tc.wantRules[i] {
+					t.Errorf("issue %d rule = %s, want %s", i, issue.Rule, tc.wantRules[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLintHook(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	linter := storage.DefaultLinter([]string{"title"})
+	note := "# Note\n"
+
+	t.Run("strict mode rejects the save", func(t *testing.T) {
+		hook := storage.NewLintHook(linter, true)
+		err := s.SaveFile(1, 1, "strict.md", []byte(note), hook)
+		if !storage.IsLintError(err) {
+			t.Fatalf("error = %v, want LintError", err)
+		}
+		if !storage.IsSaveHookError(err) {
+			t.Errorf("error = %v, want SaveHookError", err)
+		}
+		if _, err := s.GetFileContent(1, 1, "strict.md"); err == nil {
+			t.Error("rejected file was saved")
+		}
+	})
+
+	t.Run("warning mode saves with issues", func(t *testing.T) {
+		hook := storage.NewLintHook(linter, false)
+		if err := s.SaveFile(1, 1, "warning.md", []byte(note), hook); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if issues := hook.Issues(); len(issues) != 1 || issues[0].Rule != "required-frontmatter" {
+			t.Errorf("Issues() = %+v, want required-frontmatter", issues)
+		}
+		content, err := s.GetFileContent(1, 1, "warning.md")
+		if err != nil {
+			t.Fatalf("failed to read saved file: %v", err)
+		}
+		if string(content) != note {
+			t.Errorf("content = %q, want %q", content, note)
+		}
+	})
+
+	t.Run("other files are not linted", func(t *testing.T) {
+		hook := storage.NewLintHook(linter, true)
+		if err := s.SaveFile(1, 1, "note.txt", []byte(note), hook); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if issues := hook.Issues(); len(issues) != 0 {
+			t.Errorf("Issues() = %+v, want none", issues)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxNoteSize is the largest file in bytes whose frontmatter is read when listing notes by tag
+const maxNoteSize = 1 << 20
+
+// FileMeta holds the parsed frontmatter of a file and the offset of the body following it
+type FileMeta struct {
+	Path        string                 `json:"path"`
+	Frontmatter map[string]interface{} `json:"frontmatter"`
+	// BodyOffset is the byte offset of the content after the frontmatter block, 0 without frontmatter
+	BodyOffset int `json:"bodyOffset"`
+}
+
+// GetFileMeta returns the frontmatter of the file at filePath together with the offset of its body.
+// Files without frontmatter have empty fields and offset 0, a malformed block returns a FrontmatterError.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) GetFileMeta(userID, workspaceID int, filePath string) (*FileMeta, error) {
+	content, err := s.GetFileContent(userID, workspaceID, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, offset, err := ParseFrontmatter(content)
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	return &FileMeta{Path: filePath, Frontmatter: fields, BodyOffset: offset}, nil
+}
+
+// ListNotesByTag returns the markdown files of the workspace whose frontmatter tags contain tag, ordered by path.
+// Tags are compared ignoring case and a leading #, they may be a list or a comma-separated string.
+// Files without or with malformed frontmatter are skipped, as are the .git directory,
+// files larger than 1 MiB and symlinks unless following symlinks is enabled.
+func (s *Service) ListNotesByTag(userID, workspaceID int, tag string) ([]FileMeta, error) {
+	workspacePath := s.GetWorkspacePath(userID, workspaceID)
+
+	notes := []FileMeta{}
+	tag = normalizeTag(tag)
+	err := s.walkFiles(workspacePath, func(entry walkEntry) error {
+		if !isMarkdownFile(entry.name) {
+			return nil
+		}
+		info, err := entry.Info()
+		if s.fs.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Size() > maxNoteSize {
+			return nil
+		}
+
+		content, err := s.fs.ReadFile(entry.fullPath)
+		if err != nil {
+			return err
+		}
+		fields, offset, err := ParseFrontmatter(content)
+		if err != nil || !hasTag(fields["tags"], tag) {
+			return nil
+		}
+		notes = append(notes, FileMeta{Path: entry.path, Frontmatter: fields, BodyOffset: offset})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].Path < notes[j].Path
+	})
+	return notes, nil
+}
+
+// hasTag reports whether the tags frontmatter field contains tag
+func hasTag(tags interface{}, tag string) bool {
+	var values []string
+	switch tags := tags.(type) {
+	case []interface{}:
+		for _, value := range tags {
+			values = append(values, fmt.Sprint(value))
+		}
+	case string:
+		values = strings.Split(tags, ",")
+	default:
+		return false
+	}
+
+	for _, value := range values {
+		if normalizeTag(value) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTag returns tag in the form tags are compared in
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
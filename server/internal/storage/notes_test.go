@@ -0,0 +1,102 @@
+package storage_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestGetFileMeta(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	files := map[string]string{
+		"note.md":      "---\ntitle: Note\n---\n# Note\n",
+		"plain.md":     "# Plain\n",
+		"malformed.md": "---\ntitle: Note\n",
+	}
+	for path, content := range files {
+		if err := s.SaveFile(1, 1, path, []byte(content)); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+
+	meta, err := s.GetFileMeta(1, 1, "note.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if meta.Frontmatter["title"] != "Note" || files["note.md"][meta.BodyOffset:] != "# Note\n" {
+		t.Errorf("meta = %+v, want title Note and body offset before # Note", meta)
+	}
+
+	meta, err = s.GetFileMeta(1, 1, "plain.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if meta.Frontmatter == nil || len(meta.Frontmatter) != 0 || meta.BodyOffset != 0 {
+		t.Errorf("meta = %+v, want empty frontmatter and offset 0", meta)
+	}
+
+	if _, err := s.GetFileMeta(1, 1, "malformed.md"); !storage.IsFrontmatterError(err) {
+		t.Errorf("error = %v, want FrontmatterError", err)
+	}
+}
+
+func TestListNotesByTag(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	files := map[string]string{
+		"list.md":         "---\ntags:\n  - Go\n  - notes\n---\nBody",
+		"inline.md":       "---\ntags: [go]\n---\nBody",
+		"sub/string.md":   "---\ntags: \"#go, misc\"\n---\nBody",
+		"other.md":        "---\ntags: [rust]\n---\nBody",
+		"untagged.md":     "---\ntitle: Untagged\n---\nBody",
+		"plain.md":        "# go\n",
+		"malformed.md":    "---\ntags: [go\n---\nBody",
+		"text.txt":        "---\ntags: [go]\n---\nBody",
+		".git/config.md":  "---\ntags: [go]\n---\nBody",
+		"sub/numbered.md": "---\ntags: [2024]\n---\nBody",
+	}
+	for path, content := range files {
+		if err := s.SaveFile(1, 1, path, []byte(content)); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+
+	testCases := []struct {
+		tag  string
+		want []string
+	}{
+		{tag: "go", want: []string{"inline.md", "list.md", filepath.Join("sub", "string.md")}},
+		{tag: "#GO", want: []string{"inline.md", "list.md", filepath.Join("sub", "string.md")}},
+		{tag: "2024", want: []string{filepath.Join("sub", "numbered.md")}},
+		{tag: "missing", want: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.tag, func(t *testing.T) {
+			notes, err := s.ListNotesByTag(1, 1, tc.tag)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(notes) != len(tc.want) {
+				t.Fatalf("ListNotesByTag(%q) returned %d notes, want %d: %v", tc.tag, len(notes), len(tc.want), notes)
+			}
+			for i, note := range notes {
+				if note.Path != tc.want[i] {
+					t.Errorf("notes[%d].Path = %q, want %q", i, note.Path, tc.want[i])
+				}
+				if note.Frontmatter["tags"] == nil || files[filepath.ToSlash(note.Path)][note.BodyOffset:] != "Body" {
+					t.Errorf("notes[%d] = %+v, want its frontmatter and body offset", i, note)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// PinGitRef checks out the given tag or commit in the workspace repository and makes the
+// workspace read-only until it is unpinned. An empty ref unpins the workspace and checks out
+// its branch again. The workspace must have git configured.
+func (s *Service) PinGitRef(userID, workspaceID int, ref string) error {
+	log := getLogger().WithGroup("git")
+
+	repo, ok := s.getGitRepo(userID, workspaceID)
+	if !ok {
+		return fmt.Errorf("git settings not configured for this workspace")
+	}
+
+	unlock := s.lockGitRepo(userID, workspaceID)
+	err := repo.Checkout(ref)
+	unlock()
+	if err != nil {
+		return err
+	}
+	s.invalidateCaches(userID, workspaceID)
+
+	if ref == "" {
+		s.clearPinnedRef(userID, workspaceID)
+		log.Info("workspace unpinned",
+			"userID", userID,
+			"workspaceID", workspaceID)
+		return nil
+	}
+
+	s.gitStateMu.Lock()
+	if _, ok := s.pinnedRefs[userID]; !ok {
+		s.pinnedRefs[userID] = make(map[int]string)
+	}
+	s.pinnedRefs[userID][workspaceID] = ref
+	s.gitStateMu.Unlock()
+
+	log.Info("workspace pinned",
+		"userID", userID,
+		"workspaceID", workspaceID,
+		"ref", ref)
+	return nil
+}
+
+// checkWritable returns a WorkspacePinnedError if the workspace is pinned to a git ref
+func (s *Service) checkWritable(userID, workspaceID int) error {
+	s.gitStateMu.RLock()
+	defer s.gitStateMu.RUnlock()
+	if ref, ok := s.pinnedRefs[userID][workspaceID]; ok {
+		return &WorkspacePinnedError{Ref: ref}
+	}
+	return nil
+}
+
+// clearPinnedRef forgets the pinned ref of the workspace
+func (s *Service) clearPinnedRef(userID, workspaceID int) {
+	s.gitStateMu.Lock()
+	defer s.gitStateMu.Unlock()
+	if userPins, ok := s.pinnedRefs[userID]; ok {
+		delete(userPins, workspaceID)
+		if len(userPins) == 0 {
+			delete(s.pinnedRefs, userID)
+		}
+	}
+}
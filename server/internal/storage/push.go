@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"time"
+)
+
+// pendingPush holds the commits of a workspace that wait for the push grace period to end
+type pendingPush struct {
+	commits int
+	timer   *time.Timer
+}
+
+// schedulePush records a new commit of the workspace to be pushed once the grace period
+// since the first pending commit ends. It returns true without scheduling if the pending commits
+// reached the configured maximum, the caller then pushes them right away.
+func (s *Service) schedulePush(userID, workspaceID int) bool {
+	s.pushMu.Lock()
+	defer s.pushMu.Unlock()
+
+	key := workspaceKey{userID, workspaceID}
+	pending, ok := s.pendingPushes[key]
+	if !ok {
+		pending = &pendingPush{}
+		pending.timer = time.AfterFunc(s.pushGracePeriod, func() {
+			s.runPendingPush(key, pending)
+		})
+		s.pendingPushes[key] = pending
+	}
+	pending.commits++
+
+	if s.pushMaxPendingCommits > 0 && pending.commits >= s.pushMaxPendingCommits {
+		pending.timer.Stop()
+		delete(s.pendingPushes, key)
+		return true
+	}
+	return false
+}
+
+// runPendingPush pushes the pending commits of the workspace when the grace period ends,
+// unless they were pushed or cancelled in the meantime
+func (s *Service) runPendingPush(key workspaceKey, pending *pendingPush) {
+	s.pushMu.Lock()
+	if s.pendingPushes[key] != pending {
+		s.pushMu.Unlock()
+		return
+	}
+	delete(s.pendingPushes, key)
+	s.pushing.Add(1)
+	s.pushMu.Unlock()
+	defer s.pushing.Done()
+
+	s.pushPending(key, pending)
+}
+
+// pushPending pushes the pending commits of a workspace, there is no request to report
+// the errors to so they are logged. The repository and remotes are looked up when the push runs,
+// as the git settings may have changed since the commits were made.
+func (s *Service) pushPending(key workspaceKey, pending *pendingPush) {
+	repo, ok := s.getGitRepo(key.userID, key.workspaceID)
+	if !ok {
+		return
+	}
+	defer s.lockGitRepo(key.userID, key.workspaceID)()
+
+	if err := s.push(key.userID, key.workspaceID, repo, s.getGitRemotes(key.userID, key.workspaceID)); err != nil {
+		getLogger().WithGroup("git").Error("failed to push pending commits",
+			"userID", key.userID,
+			"workspaceID", key.workspaceID,
+			"commits", pending.commits,
+			"error", err.Error())
+	}
+}
+
+// cancelPendingPush drops the pending push of the workspace, its commits stay in the local repository
+func (s *Service) cancelPendingPush(userID, workspaceID int) {
+	s.pushMu.Lock()
+	defer s.pushMu.Unlock()
+
+	key := workspaceKey{userID, workspaceID}
+	if pending, ok := s.pendingPushes[key]; ok {
+		pending.timer.Stop()
+		delete(s.pendingPushes, key)
+	}
+}
+
+// FlushPendingPushes pushes the commits waiting for the push grace period of all workspaces
+// and waits for the pushes already running, so that no commits are left unpushed on shutdown.
+func (s *Service) FlushPendingPushes() {
+	s.pushMu.Lock()
+	pendingPushes := s.pendingPushes
+	s.pendingPushes = make(map[workspaceKey]*pendingPush)
+	s.pushMu.Unlock()
+
+	for key, pending := range pendingPushes {
+		pending.timer.Stop()
+		s.pushPending(key, pending)
+	}
+	s.pushing.Wait()
+}
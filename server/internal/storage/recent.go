@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// recentFiles is a min-heap of files ordered by modification time, the oldest file is on top
+type recentFiles []FileNode
+
+func (h recentFiles) Len() int           { return len(h) }
+func (h recentFiles) Less(i, j int) bool { return h[i].ModTime.Before(h[j].ModTime) }
+func (h recentFiles) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *recentFiles) Push(x any)        { *h = append(*h, x.(FileNode)) }
+func (h *recentFiles) Pop() any {
+	old := *h
+	n := len(old)
+	node := old[n-1]
+	*h = old[:n-1]
+	return node
+}
+
+// ListRecentFiles returns at most limit files of the workspace with the latest modification times,
+// newest first. Only the newest files are kept while walking so the whole tree is never sorted.
+// The .git directory is skipped, as are symlinks unless following symlinks is enabled.
+func (s *Service) ListRecentFiles(userID, workspaceID, limit int) ([]FileNode, error) {
+	if limit < 1 {
+		return []FileNode{}, nil
+	}
+	workspacePath := s.GetWorkspacePath(userID, workspaceID)
+
+	recent := make(recentFiles, 0, limit)
+	err := s.walkFiles(workspacePath, func(entry walkEntry) error {
+		info, err := entry.Info()
+		if s.fs.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if recent.Len() == limit {
+			if !info.ModTime().After(recent[0].ModTime) {
+				return nil
+			}
+			heap.Pop(&recent)
+		}
+		heap.Push(&recent, FileNode{
+			ID:      entry.path,
+			Name:    entry.name,
+			Path:    entry.path,
+			Size:    info.Size(),
+			ModTime: info.ModTime().UTC(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := []FileNode(recent)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime.After(files[j].ModTime)
+	})
+	return files, nil
+}
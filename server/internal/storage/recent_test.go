@@ -0,0 +1,94 @@
+package storage_test
+
+import (
+	"io/fs"
+	"testing"
+	"time"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestListRecentFiles(t *testing.T) {
+	mockFS := NewMockFS()
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:           mockFS,
+		NewGitClient: nil,
+	})
+
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	mockFS.ReadDirReturns = map[string]struct {
+		entries []fs.DirEntry
+		err     error
+	}{
+		"test-root/1/1": {
+			entries: []fs.DirEntry{
+				NewMockDirEntry(".git", true),
+				NewMockDirEntry("notes", true),
+				NewMockFileEntry("a.md", 1, day(2)),
+				NewMockFileEntry("b.md", 2, day(5)),
+			},
+		},
+		"test-root/1/1/notes": {
+			entries: []fs.DirEntry{
+				NewMockFileEntry("c.md", 3, day(4)),
+				NewMockFileEntry("d.md", 4, day(1)),
+				NewMockFileEntry("e.md", 5, day(3)),
+			},
+		},
+		"test-root/1/1/.git": {
+			entries: []fs.DirEntry{NewMockFileEntry("index", 6, day(9))},
+		},
+	}
+
+	testCases := []struct {
+		name  string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "newest first",
+			limit: 10,
+			want:  []string{"b.md", "notes/c.md", "notes/e.md", "a.md", "notes/d.md"},
+		},
+		{
+			name:  "limited",
+			limit: 2,
+			want:  []string{"b.md", "notes/c.md"},
+		},
+		{
+			name:  "zero limit",
+			limit: 0,
+			want:  []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := s.ListRecentFiles(1, 1, tc.limit)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(files) != len(tc.want) {
+				t.Fatalf("got %d files, want %d: %+v", len(files), len(tc.want), files)
+			}
+			for i, file := range files {
+				if file.Path != tc.want[i] {
+					t.Errorf("files[%d] = %s, want %s", i, file.Path, tc.want[i])
+				}
+			}
+		})
+	}
+
+	t.Run("stats", func(t *testing.T) {
+		files, err := s.ListRecentFiles(1, 1, 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(files) != 1 || files[0].Size != 2 || !files[0].ModTime.Equal(day(5)) {
+			t.Errorf("files = %+v, want b.md with size 2 and %v", files, day(5))
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// defaultSearchMaxFileSize is the size above which files are skipped by SearchContent if no cap is set
+const defaultSearchMaxFileSize = 1 << 20
+
+// SearchGrouping decides whether a content search returns a result per file or per matching line
+type SearchGrouping string
+
+const (
+	// SearchByFile returns a result per file with all of its matching lines
+	SearchByFile SearchGrouping = "file"
+	// SearchByMatch returns a result per matching line
+	SearchByMatch SearchGrouping = "match"
+)
+
+// SearchOptions configures a content search
+type SearchOptions struct {
+	// Limit is the maximum number of results returned, 0 returns all results
+	Limit int
+	// GroupBy decides whether results are files or matching lines, empty groups by file
+	GroupBy SearchGrouping
+	// MaxSnippets is the number of matching lines included in a file result, 0 includes all of them
+	MaxSnippets int
+	// ContextLines is the number of lines before and after a matching line included in its snippet
+	ContextLines int
+	// MaxFileSize is the size in bytes above which files are skipped, 0 uses a default of 1 MiB
+	MaxFileSize int64
+}
+
+// SearchMatch is a line of a file matching a search query
+type SearchMatch struct {
+	Line   int      `json:"line"`
+	Text   string   `json:"text"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// SearchResult holds the matching lines of a file, in the order they appear in the file.
+// MatchCount is the number of matching lines in the file, grouped by match it is always 1.
+type SearchResult struct {
+	Path       string        `json:"path"`
+	MatchCount int           `json:"matchCount"`
+	Matches    []SearchMatch `json:"matches"`
+}
+
+// SearchContent returns the files of the workspace whose content contains query, ignoring case.
+// Files with the most matching lines come first, files with the same number of matches are ordered by path.
+// Grouped by match, each matching line is a result, in the order of the files and then of the lines.
+// The .git directory, binary files and files larger than the size cap are skipped,
+// as are symlinks unless following symlinks is enabled.
+func (s *Service) SearchContent(userID, workspaceID int, query string, opts SearchOptions) ([]SearchResult, error) {
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = defaultSearchMaxFileSize
+	}
+
+	results := []SearchResult{}
+	if query == "" {
+		return results, nil
+	}
+
+	workspacePath := s.GetWorkspacePath(userID, workspaceID)
+	query = strings.ToLower(query)
+	err := s.walkFiles(workspacePath, func(entry walkEntry) error {
+		info, err := entry.Info()
+		if s.fs.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Size() > opts.MaxFileSize {
+			return nil
+		}
+
+		content, err := s.fs.ReadFile(entry.fullPath)
+		if err != nil {
+			return err
+		}
+		if isBinary(content) {
+			return nil
+		}
+		if matches := searchLines(content, query, opts.ContextLines); len(matches) > 0 {
+			results = append(results, SearchResult{Path: entry.path, MatchCount: len(matches), Matches: matches})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if len(results[i].Matches) != len(results[j].Matches) {
+			return len(results[i].Matches) > len(results[j].Matches)
+		}
+		return results[i].Path < results[j].Path
+	})
+	if opts.GroupBy == SearchByMatch {
+		results = splitMatches(results)
+	} else if opts.MaxSnippets > 0 {
+		for i := range results {
+			results[i].Matches = results[i].Matches[:min(len(results[i].Matches), opts.MaxSnippets)]
+		}
+	}
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, nil
+}
+
+// splitMatches returns a result for each matching line of the file results, keeping their order
+func splitMatches(results []SearchResult) []SearchResult {
+	split := []SearchResult{}
+	for _, result := range results {
+		for _, match := range result.Matches {
+			split = append(split, SearchResult{
+				Path:       result.Path,
+				MatchCount: 1,
+				Matches:    []SearchMatch{match},
+			})
+		}
+	}
+	return split
+}
+
+// searchLines returns the lines of content containing the lower-case query with contextLines of context
+func searchLines(content []byte, query string, contextLines int) []SearchMatch {
+	if !bytes.Contains(bytes.ToLower(content), []byte(query)) {
+		return nil
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+
+	var matches []SearchMatch
+	for i, line := range lines {
+		if !strings.Contains(strings.ToLower(line), query) {
+			continue
+		}
+		match := SearchMatch{Line: i + 1, Text: line}
+		if contextLines > 0 {
+			match.Before = lines[max(0, i-contextLines):i]
+			match.After = lines[i+1 : min(len(lines), i+1+contextLines)]
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
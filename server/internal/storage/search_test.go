@@ -0,0 +1,151 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestSearchContent(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	files := map[string]string{
+		"one.md":                          "# One\nA single Apple\n",
+		filepath.Join("notes", "many.md"): "apple pie\nbanana\nAPPLE juice\ncherry\napple tart\n",
+		filepath.Join("notes", "two.md"):  "first apple\nsecond apple\n",
+		"b.md":                            "first apple\nsecond apple\n",
+		"none.md":                         "nothing to see\n",
+		"binary.bin":                      "apple\x00\x01",
+		"huge.md":                         "apple\n" + strings.Repeat("x", 2<<20),
+		filepath.Join(".git", "config"):   "apple",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(s.GetWorkspacePath(1, 1), path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	paths := func(results []storage.SearchResult) []string {
+		var result []string
+		for _, r := range results {
+			result = append(result, r.Path)
+		}
+		return result
+	}
+
+	t.Run("ordered by number of matches", func(t *testing.T) {
+		results, err := s.SearchContent(1, 1, "Apple", storage.SearchOptions{ContextLines: 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := []string{filepath.Join("notes", "many.md"), "b.md", filepath.Join("notes", "two.md"), "one.md"}
+		if got := paths(results); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Fatalf("paths = %v, want %v", got, want)
+		}
+
+		many := results[0].Matches
+		if len(many) != 3 {
+			t.Fatalf("matches = %+v, want 3", many)
+		}
+		for i, line := range []int{1, 3, 5} {
+			if many[i].Line != line {
+				t.Errorf("match %d line = %d, want %d", i, many[i].Line, line)
+			}
+		}
+		if many[1].Text != "APPLE juice" {
+			t.Errorf("text = %q, want %q", many[1].Text, "APPLE juice")
+		}
+		if len(many[1].Before) != 1 || many[1].Before[0] != "banana" || len(many[1].After) != 1 || many[1].After[0] != "cherry" {
+			t.Errorf("context = %v / %v, want banana / cherry", many[1].Before, many[1].After)
+		}
+		if len(many[0].Before) != 0 {
+			t.Errorf("context before first line = %v, want none", many[0].Before)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		results, err := s.SearchContent(1, 1, "apple", storage.SearchOptions{Limit: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{filepath.Join("notes", "many.md"), "b.md"}
+		if got := paths(results); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("paths = %v, want %v", got, want)
+		}
+		if len(results[0].Matches[0].Before) != 0 || len(results[0].Matches[0].After) != 0 {
+			t.Errorf("context returned without ContextLines: %+v", results[0].Matches[0])
+		}
+	})
+
+	t.Run("size cap", func(t *testing.T) {
+		results, err := s.SearchContent(1, 1, "apple", storage.SearchOptions{MaxFileSize: 4 << 20})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(results) != 5 {
+			t.Errorf("paths = %v, want huge.md included", paths(results))
+		}
+	})
+
+	t.Run("snippets", func(t *testing.T) {
+		results, err := s.SearchContent(1, 1, "apple", storage.SearchOptions{GroupBy: storage.SearchByFile, MaxSnippets: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		many := results[0]
+		if many.MatchCount != 3 {
+			t.Errorf("match count = %d, want 3", many.MatchCount)
+		}
+		if len(many.Matches) != 2 || many.Matches[0].Line != 1 || many.Matches[1].Line != 3 {
+			t.Errorf("matches = %+v, want lines 1 and 3", many.Matches)
+		}
+	})
+
+	t.Run("grouped by match", func(t *testing.T) {
+		results, err := s.SearchContent(1, 1, "apple", storage.SearchOptions{GroupBy: storage.SearchByMatch, Limit: 4})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := []struct {
+			path string
+			line int
+		}{
+			{filepath.Join("notes", "many.md"), 1},
+			{filepath.Join("notes", "many.md"), 3},
+			{filepath.Join("notes", "many.md"), 5},
+			{"b.md", 1},
+		}
+		if len(results) != len(want) {
+			t.Fatalf("results = %+v, want %d", results, len(want))
+		}
+		for i, w := range want {
+			r := results[i]
+			if r.Path != w.path || r.MatchCount != 1 || len(r.Matches) != 1 || r.Matches[0].Line != w.line {
+				t.Errorf("result %d = %+v, want line %d of %s", i, r, w.line, w.path)
+			}
+		}
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		results, err := s.SearchContent(1, 1, "durian", storage.SearchOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("results = %+v, want none", results)
+		}
+	})
+}
@@ -2,6 +2,9 @@ package storage
 
 import (
 	"lemma/internal/git"
+	"net"
+	"sync"
+	"time"
 )
 
 // Manager interface combines all storage interfaces.
@@ -9,20 +12,78 @@ type Manager interface {
 	FileManager
 	WorkspaceManager
 	RepositoryManager
+	IndexManager
 }
 
 // Service represents the file system structure.
 type Service struct {
 	fs           fileSystem
 	newGitClient func(url, user, token, path, commitName, commitEmail string) git.Client
+	lookupIP     func(host string) ([]net.IP, error)
 	RootDir      string
 	GitRepos     map[int]map[int]git.Client // map[userID]map[workspaceID]*git.Client
+
+	// gitLocks serialize the operations on the repository of each workspace,
+	// git repositories are not safe for concurrent use
+	gitLocksMu sync.Mutex
+	gitLocks   map[workspaceKey]*sync.Mutex
+
+	// gitStateMu guards GitRepos and the git state below, which requests set and read concurrently
+	gitStateMu sync.RWMutex
+	pinnedRefs map[int]map[int]string       // map[userID]map[workspaceID]ref
+	gitRemotes map[int]map[int][]git.Remote // map[userID]map[workspaceID]additional remotes
+	gitSigners map[int]map[int]git.Signer   // map[userID]map[workspaceID]commit signer
+
+	allowedGitHosts      []string
+	blockPrivateGitHosts bool
+	followSymlinks       bool
+	maxTreeNodes         int
+	maxTreeDepth         int
+	maxFileVersions      int
+	maxIncludeSize       int64
+
+	pushGracePeriod       time.Duration
+	pushMaxPendingCommits int
+	pushMu                sync.Mutex
+	pendingPushes         map[workspaceKey]*pendingPush
+	pushing               sync.WaitGroup
+
+	fileStats *fileStatsCache
+	caches    []cacheBuilder
+
+	conditionalSaveMu sync.Mutex
+
+	refreshMu   sync.Mutex
+	stopRefresh chan struct{}
 }
 
 // Options represents the options for the storage service.
 type Options struct {
 	Fs           fileSystem
 	NewGitClient func(url, user, token, path, commitName, commitEmail string) git.Client
+
+	// AllowedGitHosts restricts git remotes to the given hosts, empty allows all hosts
+	AllowedGitHosts []string
+	// BlockPrivateGitHosts rejects non-http(s) remotes and remotes on localhost or private IPs.
+	// Git clients of the process also refuse to connect to private addresses.
+	BlockPrivateGitHosts bool
+	// LookupIP resolves the host names of git remotes checked for private addresses, defaults to net.LookupIP
+	LookupIP func(host string) ([]net.IP, error)
+	// FollowSymlinks allows symlinks inside workspaces, by default they are skipped and rejected
+	FollowSymlinks bool
+	// MaxTreeNodes limits the number of files and directories a recursive operation may touch, 0 disables the limit
+	MaxTreeNodes int
+	// MaxTreeDepth limits how deeply nested a directory handled by a recursive operation may be, 0 disables the limit
+	MaxTreeDepth int
+	// MaxFileVersions is how many previous versions of a file SaveFile keeps, 0 disables file versions
+	MaxFileVersions int
+	// MaxIncludeSize limits the bytes ResolveIncludes adds to a file, 0 uses a default of 10 MiB
+	MaxIncludeSize int64
+	// PushGracePeriod delays the push after a commit so that the commits made within it are pushed together,
+	// 0 pushes after every commit
+	PushGracePeriod time.Duration
+	// PushMaxPendingCommits pushes before the grace period ends once this many commits wait, 0 disables the limit
+	PushMaxPendingCommits int
 }
 
 // NewService creates a new Storage instance with the default options and the given rootDir root directory.
@@ -43,10 +104,47 @@ func NewServiceWithOptions(rootDir string, options Options) *Service {
 		options.NewGitClient = git.New
 	}
 
-	return &Service{
+	if options.LookupIP == nil {
+		options.LookupIP = net.LookupIP
+	}
+
+	if options.BlockPrivateGitHosts {
+		git.BlockPrivateHosts()
+	}
+
+	s := &Service{
 		fs:           options.Fs,
 		newGitClient: options.NewGitClient,
+		lookupIP:     options.LookupIP,
 		RootDir:      rootDir,
 		GitRepos:     make(map[int]map[int]git.Client),
+		pinnedRefs:   make(map[int]map[int]string),
+		gitRemotes:   make(map[int]map[int][]git.Remote),
+		gitSigners:   make(map[int]map[int]git.Signer),
+		gitLocks:     make(map[workspaceKey]*sync.Mutex),
+
+		allowedGitHosts:      options.AllowedGitHosts,
+		blockPrivateGitHosts: options.BlockPrivateGitHosts,
+		followSymlinks:       options.FollowSymlinks,
+		maxTreeNodes:         options.MaxTreeNodes,
+		maxTreeDepth:         options.MaxTreeDepth,
+		maxFileVersions:      options.MaxFileVersions,
+		maxIncludeSize:       options.MaxIncludeSize,
+
+		pushGracePeriod:       options.PushGracePeriod,
+		pushMaxPendingCommits: options.PushMaxPendingCommits,
+		pendingPushes:         make(map[workspaceKey]*pendingPush),
 	}
+
+	s.fileStats = newFileStatsCache(
+		func(userID, workspaceID int) (*FileCountStats, error) {
+			return s.countFilesInPath(s.GetWorkspacePath(userID, workspaceID))
+		},
+		func() (*FileCountStats, error) {
+			return s.countFilesInPath(s.RootDir)
+		},
+	)
+	s.caches = []cacheBuilder{s.fileStats}
+
+	return s
 }
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"unicode/utf8"
+)
+
+// tailChunkSize is the size of the chunks read backwards from the end of a file
+const tailChunkSize = 4096
+
+// maxTailSize is the most bytes read from the end of a file for its last lines
+const maxTailSize = 1 << 20
+
+// TailFile returns the last n lines of the file at the given filePath.
+// The file is read backwards from the end in chunks, so only the tail is loaded into memory.
+// At most 1 MiB is read, if the last n lines are longer only the whole lines within it are returned.
+// ErrBinaryFile is returned if the file does not look like text.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) TailFile(userID, workspaceID int, filePath string, n int) ([]byte, error) {
+	fullPath, err := s.ValidatePath(userID, workspaceID, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return []byte{}, nil
+	}
+
+	file, err := s.fs.Open(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var tail []byte
+	if seeker, ok := file.(io.ReadSeeker); ok {
+		tail, err = readTail(seeker, n)
+	} else {
+		var content []byte
+		if content, err = io.ReadAll(file); err == nil {
+			if isBinary(content) {
+				return nil, ErrBinaryFile
+			}
+			tail, _ = lastLines(content, n)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !utf8.Valid(tail) {
+		return nil, ErrBinaryFile
+	}
+	return tail, nil
+}
+
+// readTail reads chunks backwards from the end of r until it holds the last n lines
+func readTail(r io.ReadSeeker, n int) ([]byte, error) {
+	// Binary files are detected from the start of the file, the same sample isBinary uses
+	head := make([]byte, 8000)
+	read, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	if bytes.IndexByte(head[:read], 0) != -1 {
+		return nil, ErrBinaryFile
+	}
+
+	pos, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the line breaks of each new chunk are counted, a trailing line break ends
+	// the last line and is not counted, the same as in lastLines
+	var chunks [][]byte
+	newlines, total := 0, int64(0)
+	for pos > 0 && total < maxTailSize && newlines < n {
+		size := min(tailChunkSize, pos, maxTailSize-total)
+		pos -= size
+
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			return nil, err
+		}
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return nil, err
+		}
+		if bytes.IndexByte(chunk, 0) != -1 {
+			return nil, ErrBinaryFile
+		}
+		if len(chunks) == 0 && chunk[len(chunk)-1] == '\n' {
+			newlines--
+		}
+		newlines += bytes.Count(chunk, []byte{'\n'})
+		total += size
+		chunks = append(chunks, chunk)
+	}
+
+	// The chunks were read from the end
+	slices.Reverse(chunks)
+	tail, complete := lastLines(bytes.Join(chunks, nil), n)
+	if !complete && pos > 0 {
+		// The size limit was reached, a line starting before the bytes read is left out
+		if _, err := r.Seek(pos-1, io.SeekStart); err != nil {
+			return nil, err
+		}
+		prev := make([]byte, 1)
+		if _, err := io.ReadFull(r, prev); err != nil {
+			return nil, err
+		}
+		if prev[0] != '\n' {
+			_, tail, _ = bytes.Cut(tail, []byte{'\n'})
+		}
+	}
+	return tail, nil
+}
+
+// lastLines returns the last n lines of content and whether content holds more than n lines.
+// A trailing line break ends the last line rather than starting an empty one.
+func lastLines(content []byte, n int) ([]byte, bool) {
+	end := len(content)
+	if end > 0 && content[end-1] == '\n' {
+		end--
+	}
+
+	for range n {
+		idx := bytes.LastIndexByte(content[:end], '\n')
+		if idx == -1 {
+			return content, false
+		}
+		end = idx
+	}
+
+	return content[end+1:], true
+}
@@ -0,0 +1,101 @@
+package storage_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestTailFile(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	// Long enough to span several chunks read from the end
+	var log strings.Builder
+	for i := 1; i <= 2000; i++ {
+		fmt.Fprintf(&log, "%04d entry\n", i)
+	}
+
+	// Lines of 100 bytes, more of them than the 1 MiB read from the end holds
+	var large strings.Builder
+	for i := 1; i <= 30000; i++ {
+		fmt.Fprintf(&large, "%06d%s\n", i, strings.Repeat("x", 93))
+	}
+
+	// Lines of 64 bytes, the 1 MiB read from the end starts at a line
+	var aligned strings.Builder
+	for i := 1; i <= 20000; i++ {
+		fmt.Fprintf(&aligned, "%06d%s\n", i, strings.Repeat("x", 57))
+	}
+
+	files := map[string]string{
+		"aligned.log":   aligned.String(),
+		"large.log":     large.String(),
+		"journal.log":   log.String(),
+		"short.md":      "first\nsecond\nthird",
+		"empty.md":      "",
+		"binary.bin":    "\x00\x01\x02\n\x03",
+		"multibyte.txt": "ä\nö\nü\n",
+	}
+	for path, content := range files {
+		if err := s.SaveFile(1, 1, path, []byte(content)); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		path    string
+		lines   int
+		want    string
+		wantErr error
+	}{
+		{name: "last lines", path: "journal.log", lines: 3, want: "1998 entry\n1999 entry\n2000 entry\n"},
+		{name: "across chunks", path: "journal.log", lines: 500, want: log.String()[1500*11:]},
+		{name: "whole file", path: "journal.log", lines: 5000, want: log.String()},
+		{name: "size limit", path: "large.log", lines: 20000, want: large.String()[(30000-10485)*100:]},
+		{name: "size limit at line start", path: "aligned.log", lines: 20000, want: aligned.String()[(20000-16384)*64:]},
+		{name: "within size limit", path: "large.log", lines: 2, want: large.String()[29998*100:]},
+		{name: "no trailing newline", path: "short.md", lines: 2, want: "second\nthird"},
+		{name: "more lines than file", path: "short.md", lines: 10, want: "first\nsecond\nthird"},
+		{name: "multibyte", path: "multibyte.txt", lines: 1, want: "ü\n"},
+		{name: "empty file", path: "empty.md", lines: 5, want: ""},
+		{name: "binary file", path: "binary.bin", lines: 1, wantErr: storage.ErrBinaryFile},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tail, err := s.TailFile(1, 1, tc.path, tc.lines)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("expected error %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(tail) != tc.want {
+				t.Errorf("tail = %q, want %q", truncate(string(tail)), truncate(tc.want))
+			}
+		})
+	}
+
+	if _, err := s.TailFile(1, 1, "../outside.log", 1); !storage.IsPathValidationError(err) {
+		t.Errorf("expected path validation error, got %v", err)
+	}
+}
+
+// truncate shortens long values in failure messages
+func truncate(s string) string {
+	if len(s) > 100 {
+		return s[:50] + "..." + s[len(s)-50:]
+	}
+	return s
+}
@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// trashDirName is the directory in the root directory that holds deleted files until they are purged.
+// Like file versions it is kept outside of the workspace directories, so trashed files are not
+// listed, exported or committed.
+const trashDirName = ".trash"
+
+// TrashItem represents a deleted file or directory in the trash of a workspace
+type TrashItem struct {
+	Path      string    `json:"path"`
+	IsDir     bool      `json:"isDir"`
+	Size      int64     `json:"size"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// trashRoot returns the directory holding the trash of all workspaces
+func (s *Service) trashRoot() string {
+	return filepath.Join(s.RootDir, trashDirName)
+}
+
+// getTrashPath returns the trash directory of the workspace.
+// Each deleted path has a directory named by the escaped path, holding the deleted copies named by deletion time.
+func (s *Service) getTrashPath(userID, workspaceID int) string {
+	return filepath.Join(s.trashRoot(), fmt.Sprintf("%d", userID), fmt.Sprintf("%d", workspaceID))
+}
+
+// trashedPathDir returns the directory holding the deleted copies of the file at filePath
+// together with the path relative to the workspace directory.
+func (s *Service) trashedPathDir(userID, workspaceID int, filePath string) (string, string, error) {
+	fullPath, err := s.ValidatePath(userID, workspaceID, filePath)
+	if err != nil {
+		return "", "", err
+	}
+
+	relPath, err := filepath.Rel(s.GetWorkspacePath(userID, workspaceID), fullPath)
+	if err != nil || relPath == "." {
+		return "", "", &PathValidationError{Path: filePath, Message: "invalid file path"}
+	}
+	return filepath.Join(s.getTrashPath(userID, workspaceID), url.PathEscape(filepath.ToSlash(relPath))), relPath, nil
+}
+
+// moveToTrash moves the file or directory at fullPath into the trash of the workspace
+func (s *Service) moveToTrash(userID, workspaceID int, filePath, fullPath string) error {
+	dir, _, err := s.trashedPathDir(userID, workspaceID, filePath)
+	if err != nil {
+		return err
+	}
+	if err := s.fs.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	deletions, err := s.readTimestamps(dir, true)
+	if err != nil {
+		return err
+	}
+	deletedAt := time.Now().UnixNano()
+	if n := len(deletions); n > 0 && deletedAt <= deletions[n-1] {
+		deletedAt = deletions[n-1] + 1
+	}
+
+	return s.fs.MoveFile(fullPath, filepath.Join(dir, strconv.FormatInt(deletedAt, 10)))
+}
+
+// ListTrash returns the deleted files and directories of the workspace, most recently deleted first.
+// A path deleted several times is listed once for every deletion.
+func (s *Service) ListTrash(userID, workspaceID int) ([]TrashItem, error) {
+	trashPath := s.getTrashPath(userID, workspaceID)
+	entries, err := s.fs.ReadDir(trashPath)
+	if err != nil {
+		if s.fs.IsNotExist(err) {
+			return []TrashItem{}, nil
+		}
+		return nil, err
+	}
+
+	items := []TrashItem{}
+	for _, entry := range entries {
+		path, err := url.PathUnescape(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(trashPath, entry.Name())
+		deletions, err := s.readTimestamps(dir, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, deletedAt := range deletions {
+			info, err := s.fs.Lstat(filepath.Join(dir, strconv.FormatInt(deletedAt, 10)))
+			if err != nil {
+				return nil, err
+			}
+			item := TrashItem{
+				Path:      filepath.FromSlash(path),
+				IsDir:     info.IsDir(),
+				DeletedAt: time.Unix(0, deletedAt).UTC(),
+			}
+			if !item.IsDir {
+				item.Size = info.Size()
+			}
+			items = append(items, item)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// RestoreTrashedFile moves the most recently deleted copy of filePath out of the trash and returns
+// the path it was restored to. If a file exists at filePath again, the copy is restored next to it
+// with a numeric suffix, see UniqueFilePath. An unknown path returns an error satisfying os.IsNotExist.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) RestoreTrashedFile(userID, workspaceID int, filePath string) (string, error) {
+	log := getLogger()
+
+	if err := s.checkWritable(userID, workspaceID); err != nil {
+		return "", err
+	}
+
+	dir, relPath, err := s.trashedPathDir(userID, workspaceID, filePath)
+	if err != nil {
+		return "", err
+	}
+
+	deletions, err := s.readTimestamps(dir, true)
+	if err != nil {
+		return "", err
+	}
+	if len(deletions) == 0 {
+		return "", os.ErrNotExist
+	}
+	trashedPath := filepath.Join(dir, strconv.FormatInt(deletions[len(deletions)-1], 10))
+
+	restoredPath, err := s.UniqueFilePath(userID, workspaceID, relPath)
+	if err != nil {
+		return "", err
+	}
+	fullPath, err := s.ValidatePath(userID, workspaceID, restoredPath)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.fs.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return "", err
+	}
+	if err := s.fs.MoveFile(trashedPath, fullPath); err != nil {
+		return "", err
+	}
+	s.invalidateCaches(userID, workspaceID)
+
+	if len(deletions) == 1 {
+		if err := s.fs.Remove(dir); err != nil {
+			log.Warn("failed to remove empty trash directory",
+				"userID", userID,
+				"workspaceID", workspaceID,
+				"path", relPath,
+				"error", err.Error())
+		}
+	}
+
+	log.Debug("file restored from trash",
+		"userID", userID,
+		"workspaceID", workspaceID,
+		"path", relPath,
+		"restoredPath", restoredPath)
+	return restoredPath, nil
+}
+
+// DeleteTrashedFile permanently removes all deleted copies of filePath from the trash.
+// An unknown path returns an error satisfying os.IsNotExist.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) DeleteTrashedFile(userID, workspaceID int, filePath string) error {
+	dir, relPath, err := s.trashedPathDir(userID, workspaceID, filePath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.fs.Stat(dir); err != nil {
+		return err
+	}
+	if err := s.fs.RemoveAll(dir); err != nil {
+		return err
+	}
+
+	getLogger().Debug("file removed from trash",
+		"userID", userID,
+		"workspaceID", workspaceID,
+		"path", relPath)
+	return nil
+}
+
+// PurgeTrash permanently removes the files of all workspaces that were deleted before cutoff
+// and returns how many deleted copies were removed.
+func (s *Service) PurgeTrash(cutoff time.Time) (int, error) {
+	purged, err := s.purgeTrashDir(s.trashRoot(), 0, cutoff)
+	if err != nil {
+		return purged, fmt.Errorf("failed to purge trash: %w", err)
+	}
+
+	if purged > 0 {
+		getLogger().Info("trash purged",
+			"count", purged,
+			"cutoff", cutoff)
+	}
+	return purged, nil
+}
+
+// PurgeWorkspaceTrash permanently removes the files of the workspace that were deleted before cutoff
+// and returns how many deleted copies were removed.
+func (s *Service) PurgeWorkspaceTrash(userID, workspaceID int, cutoff time.Time) (int, error) {
+	purged, err := s.purgeTrashDir(s.getTrashPath(userID, workspaceID), 2, cutoff)
+	if err != nil {
+		return purged, fmt.Errorf("failed to purge trash: %w", err)
+	}
+
+	getLogger().Debug("workspace trash purged",
+		"userID", userID,
+		"workspaceID", workspaceID,
+		"count", purged,
+		"cutoff", cutoff)
+	return purged, nil
+}
+
+// purgeTrashDir removes the deleted copies older than cutoff below dir, which is at the given depth
+// of the trash layout <root>/<userID>/<workspaceID>/<escaped path>/<deletion time>
+func (s *Service) purgeTrashDir(dir string, depth int, cutoff time.Time) (int, error) {
+	if depth == 3 {
+		deletions, err := s.readTimestamps(dir, true)
+		if err != nil {
+			return 0, err
+		}
+		purged := 0
+		for _, deletedAt := range deletions {
+			if !time.Unix(0, deletedAt).Before(cutoff) {
+				continue
+			}
+			if err := s.fs.RemoveAll(filepath.Join(dir, strconv.FormatInt(deletedAt, 10))); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+		if purged > 0 && purged == len(deletions) {
+			if err := s.fs.Remove(dir); err != nil {
+				return purged, err
+			}
+		}
+		return purged, nil
+	}
+
+	entries, err := s.fs.ReadDir(dir)
+	if err != nil {
+		if s.fs.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	purged := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		n, err := s.purgeTrashDir(filepath.Join(dir, entry.Name()), depth+1, cutoff)
+		purged += n
+		if err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
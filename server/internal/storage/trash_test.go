@@ -0,0 +1,213 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestTrash(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+
+	notePath := filepath.Join("notes", "note.md")
+	saveFile := func(t *testing.T, path, content string) {
+		t.Helper()
+		if err := s.SaveFile(1, 1, path, []byte(content)); err != nil {
+			t.Fatalf("failed to save %s: %v", path, err)
+		}
+	}
+	getContent := func(t *testing.T, path string) string {
+		t.Helper()
+		content, err := s.GetFileContent(1, 1, path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		return string(content)
+	}
+
+	t.Run("delete moves to trash", func(t *testing.T) {
+		saveFile(t, notePath, "original")
+		if err := s.DeleteFile(1, 1, notePath); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := s.GetFileContent(1, 1, notePath); !os.IsNotExist(err) {
+			t.Errorf("expected file to be gone, got %v", err)
+		}
+
+		items, err := s.ListTrash(1, 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(items) != 1 || items[0].Path != notePath || items[0].Size != int64(len("original")) {
+			t.Fatalf("ListTrash = %+v, want %s", items, notePath)
+		}
+
+		nodes, err := s.ListFilesRecursively(1, 1, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, node := range nodes {
+			if node.Name == ".trash" {
+				t.Error("trash listed as workspace file")
+			}
+		}
+	})
+
+	t.Run("restore to original path", func(t *testing.T) {
+		restored, err := s.RestoreTrashedFile(1, 1, notePath)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if restored != notePath {
+			t.Errorf("restored to %s, want %s", restored, notePath)
+		}
+		if got := getContent(t, notePath); got != "original" {
+			t.Errorf("content = %q, want %q", got, "original")
+		}
+
+		items, err := s.ListTrash(1, 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(items) != 0 {
+			t.Errorf("ListTrash = %+v, want empty", items)
+		}
+	})
+
+	t.Run("restore with name collision", func(t *testing.T) {
+		if err := s.DeleteFile(1, 1, notePath); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		saveFile(t, notePath, "replacement")
+
+		restored, err := s.RestoreTrashedFile(1, 1, notePath)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := filepath.Join("notes", "note (1).md")
+		if restored != want {
+			t.Errorf("restored to %s, want %s", restored, want)
+		}
+		if got := getContent(t, want); got != "original" {
+			t.Errorf("restored content = %q, want %q", got, "original")
+		}
+		if got := getContent(t, notePath); got != "replacement" {
+			t.Errorf("existing content = %q, want %q", got, "replacement")
+		}
+	})
+
+	t.Run("restore most recent deletion", func(t *testing.T) {
+		saveFile(t, "twice.md", "first")
+		if err := s.DeleteFile(1, 1, "twice.md"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		saveFile(t, "twice.md", "second")
+		if err := s.DeleteFile(1, 1, "twice.md"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := s.RestoreTrashedFile(1, 1, "twice.md"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := getContent(t, "twice.md"); got != "second" {
+			t.Errorf("content = %q, want %q", got, "second")
+		}
+	})
+
+	t.Run("delete permanently", func(t *testing.T) {
+		if err := s.DeleteTrashedFile(1, 1, "twice.md"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.DeleteTrashedFile(1, 1, "twice.md"); !os.IsNotExist(err) {
+			t.Errorf("error = %v, want not exist", err)
+		}
+		if _, err := s.RestoreTrashedFile(1, 1, "twice.md"); !os.IsNotExist(err) {
+			t.Errorf("error = %v, want not exist", err)
+		}
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		saveFile(t, "old.md", "old")
+		if err := s.DeleteFile(1, 1, "old.md"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cutoff := time.Now()
+		saveFile(t, "new.md", "new")
+		if err := s.DeleteFile(1, 1, "new.md"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		purged, err := s.PurgeTrash(cutoff)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if purged != 1 {
+			t.Errorf("purged = %d, want 1", purged)
+		}
+
+		items, err := s.ListTrash(1, 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(items) != 1 || items[0].Path != "new.md" {
+			t.Errorf("ListTrash = %+v, want only new.md", items)
+		}
+	})
+
+	t.Run("purge workspace", func(t *testing.T) {
+		if err := s.InitializeUserWorkspace(1, 2); err != nil {
+			t.Fatalf("failed to initialize workspace: %v", err)
+		}
+		if err := s.SaveFile(1, 2, "other.md", []byte("other")); err != nil {
+			t.Fatalf("failed to save other.md: %v", err)
+		}
+		if err := s.DeleteFile(1, 2, "other.md"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		purged, err := s.PurgeWorkspaceTrash(1, 2, time.Now())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if purged != 1 {
+			t.Errorf("purged = %d, want 1", purged)
+		}
+
+		// The trash of other workspaces is left alone
+		items, err := s.ListTrash(1, 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(items) != 1 {
+			t.Errorf("ListTrash = %+v, want new.md", items)
+		}
+	})
+
+	t.Run("trash is not counted", func(t *testing.T) {
+		stats, err := s.GetTotalFileStats(true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		workspaceStats, err := s.GetFileStats(1, 1, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stats.TotalFiles != workspaceStats.TotalFiles {
+			t.Errorf("total files = %d, want %d", stats.TotalFiles, workspaceStats.TotalFiles)
+		}
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		if _, err := s.RestoreTrashedFile(1, 1, "../../etc/passwd"); !storage.IsPathValidationError(err) {
+			t.Errorf("error = %v, want PathValidationError", err)
+		}
+	})
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// versionsDirName is the directory in the root directory that holds the previous versions of saved files.
+// It is kept outside of the workspace directories so versions are not listed, exported or committed.
+const versionsDirName = ".versions"
+
+// FileVersion represents a previous version of a file kept when the file was overwritten
+type FileVersion struct {
+	Version   string    `json:"version"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// versionsRoot returns the directory holding the file versions of all workspaces
+func (s *Service) versionsRoot() string {
+	return filepath.Join(s.RootDir, versionsDirName)
+}
+
+// getVersionsPath returns the directory holding the file versions of the workspace
+func (s *Service) getVersionsPath(userID, workspaceID int) string {
+	return filepath.Join(s.versionsRoot(), fmt.Sprintf("%d", userID), fmt.Sprintf("%d", workspaceID))
+}
+
+// fileVersionsDir returns the directory holding the versions of the file at filePath.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) fileVersionsDir(userID, workspaceID int, filePath string) (string, error) {
+	fullPath, err := s.ValidatePath(userID, workspaceID, filePath)
+	if err != nil {
+		return "", err
+	}
+
+	relPath, err := filepath.Rel(s.GetWorkspacePath(userID, workspaceID), fullPath)
+	if err != nil || relPath == "." {
+		return "", &PathValidationError{Path: filePath, Message: "invalid file path"}
+	}
+	return filepath.Join(s.getVersionsPath(userID, workspaceID), relPath), nil
+}
+
+// saveFileVersion keeps the previous content of a file that is about to be overwritten and
+// removes the oldest versions beyond the configured limit. Unchanged content is not kept again.
+func (s *Service) saveFileVersion(userID, workspaceID int, filePath string, previous, content []byte) error {
+	if s.maxFileVersions <= 0 || bytes.Equal(previous, content) {
+		return nil
+	}
+
+	dir, err := s.fileVersionsDir(userID, workspaceID, filePath)
+	if err != nil {
+		return err
+	}
+	if err := s.fs.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	versions, err := s.readTimestamps(dir, false)
+	if err != nil {
+		return err
+	}
+
+	// Versions are named by their creation time and must stay unique and ordered
+	version := time.Now().UnixNano()
+	if n := len(versions); n > 0 && version <= versions[n-1] {
+		version = versions[n-1] + 1
+	}
+	if err := s.fs.WriteFile(filepath.Join(dir, strconv.FormatInt(version, 10)), previous, 0644); err != nil {
+		return err
+	}
+
+	versions = append(versions, version)
+	for len(versions) > s.maxFileVersions {
+		if err := s.fs.Remove(filepath.Join(dir, strconv.FormatInt(versions[0], 10))); err != nil {
+			return err
+		}
+		versions = versions[1:]
+	}
+	return nil
+}
+
+// readTimestamps returns the entries in dir named by a time in nanoseconds, oldest first.
+// Directories are skipped unless includeDirs is set.
+func (s *Service) readTimestamps(dir string, includeDirs bool) ([]int64, error) {
+	entries, err := s.fs.ReadDir(dir)
+	if err != nil {
+		if s.fs.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var timestamps []int64
+	for _, entry := range entries {
+		if entry.IsDir() && !includeDirs {
+			continue
+		}
+		timestamp, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil {
+			continue
+		}
+		timestamps = append(timestamps, timestamp)
+	}
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i] < timestamps[j]
+	})
+	return timestamps, nil
+}
+
+// ListFileVersions returns the previous versions of the file at filePath, oldest first.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) ListFileVersions(userID, workspaceID int, filePath string) ([]FileVersion, error) {
+	dir, err := s.fileVersionsDir(userID, workspaceID, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.readTimestamps(dir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]FileVersion, 0, len(versions))
+	for _, version := range versions {
+		name := strconv.FormatInt(version, 10)
+		info, err := s.fs.Stat(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, FileVersion{
+			Version:   name,
+			Size:      info.Size(),
+			CreatedAt: time.Unix(0, version).UTC(),
+		})
+	}
+	return result, nil
+}
+
+// GetFileVersion returns the content of a previous version of the file at filePath.
+// An unknown version returns an error satisfying os.IsNotExist.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) GetFileVersion(userID, workspaceID int, filePath, version string) ([]byte, error) {
+	dir, err := s.fileVersionsDir(userID, workspaceID, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := strconv.ParseInt(version, 10, 64); err != nil {
+		return nil, os.ErrNotExist
+	}
+	return s.fs.ReadFile(filepath.Join(dir, version))
+}
+
+// RestoreFileVersion overwrites the file at filePath with a previous version of it.
+// The content replaced by the restore is kept as a new version, so a restore can be undone.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) RestoreFileVersion(userID, workspaceID int, filePath, version string) error {
+	if err := s.checkWritable(userID, workspaceID); err != nil {
+		return err
+	}
+
+	content, err := s.GetFileVersion(userID, workspaceID, filePath, version)
+	if err != nil {
+		return err
+	}
+
+	if err := s.SaveFile(userID, workspaceID, filePath, content); err != nil {
+		return err
+	}
+
+	getLogger().Debug("file version restored",
+		"userID", userID,
+		"workspaceID", workspaceID,
+		"path", filePath,
+		"version", version)
+	return nil
+}
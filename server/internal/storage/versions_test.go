@@ -0,0 +1,220 @@
+package storage_test
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestSaveFileVersions(t *testing.T) {
+	filePath := filepath.Join("test-root", "1", "1", "note.md")
+	versionsDir := filepath.Join("test-root", ".versions", "1", "1", "note.md")
+
+	newService := func(maxVersions int) (*storage.Service, *mockFS) {
+		mockFS := NewMockFS()
+		mockFS.ReadFileReturns[filePath] = struct {
+			data []byte
+			err  error
+		}{[]byte("previous"), nil}
+		mockFS.ReadDirReturns = map[string]struct {
+			entries []fs.DirEntry
+			err     error
+		}{
+			versionsDir: {entries: []fs.DirEntry{
+				NewMockDirEntry("200", false),
+				NewMockDirEntry("100", false),
+			}},
+		}
+		s := storage.NewServiceWithOptions("test-root", storage.Options{
+			Fs:              mockFS,
+			MaxFileVersions: maxVersions,
+		})
+		return s, mockFS
+	}
+
+	// versionWrites returns the content written to new versions of the file
+	versionWrites := func(mockFS *mockFS) [][]byte {
+		var writes [][]byte
+		for path, data := range mockFS.WriteCalls {
+			if filepath.Dir(path) == versionsDir {
+				writes = append(writes, data)
+			}
+		}
+		return writes
+	}
+
+	t.Run("keeps previous content", func(t *testing.T) {
+		s, mockFS := newService(5)
+		if err := s.SaveFile(1, 1, "note.md", []byte("new")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		writes := versionWrites(mockFS)
+		if len(writes) != 1 || !bytes.Equal(writes[0], []byte("previous")) {
+			t.Errorf("version writes = %q, want the previous content", writes)
+		}
+		if !bytes.Equal(mockFS.WriteCalls[filePath], []byte("new")) {
+			t.Errorf("file content = %q, want %q", mockFS.WriteCalls[filePath], "new")
+		}
+		if len(mockFS.RemoveCalls) != 0 {
+			t.Errorf("unexpected removals: %v", mockFS.RemoveCalls)
+		}
+	})
+
+	t.Run("removes oldest versions beyond limit", func(t *testing.T) {
+		s, mockFS := newService(2)
+		if err := s.SaveFile(1, 1, "note.md", []byte("new")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := []string{filepath.Join(versionsDir, "100")}
+		if len(mockFS.RemoveCalls) != 1 || mockFS.RemoveCalls[0] != want[0] {
+			t.Errorf("RemoveCalls = %v, want %v", mockFS.RemoveCalls, want)
+		}
+	})
+
+	t.Run("unchanged content", func(t *testing.T) {
+		s, mockFS := newService(5)
+		if err := s.SaveFile(1, 1, "note.md", []byte("previous")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if writes := versionWrites(mockFS); len(writes) != 0 {
+			t.Errorf("unexpected version writes: %q", writes)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		s, mockFS := newService(0)
+		if err := s.SaveFile(1, 1, "note.md", []byte("new")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if writes := versionWrites(mockFS); len(writes) != 0 {
+			t.Errorf("unexpected version writes: %q", writes)
+		}
+		if mockFS.ReadCalls[filePath] != 0 {
+			t.Error("previous content read with file versions disabled")
+		}
+	})
+}
+
+func TestListFileVersions(t *testing.T) {
+	versionsDir := filepath.Join("test-root", ".versions", "1", "1", "notes", "note.md")
+
+	mockFS := NewMockFS()
+	mockFS.ReadDirReturns = map[string]struct {
+		entries []fs.DirEntry
+		err     error
+	}{
+		versionsDir: {entries: []fs.DirEntry{
+			NewMockDirEntry("2000", false),
+			NewMockDirEntry("1000", false),
+			NewMockDirEntry("invalid", false),
+			NewMockDirEntry("nested.md", true),
+		}},
+	}
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:              mockFS,
+		MaxFileVersions: 5,
+	})
+
+	versions, err := s.ListFileVersions(1, 1, "notes/note.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []storage.FileVersion{
+		{Version: "1000", Size: 1024, CreatedAt: time.Unix(0, 1000).UTC()},
+		{Version: "2000", Size: 1024, CreatedAt: time.Unix(0, 2000).UTC()},
+	}
+	if len(versions) != len(want) {
+		t.Fatalf("ListFileVersions returned %d versions, want %d: %v", len(versions), len(want), versions)
+	}
+	for i := range want {
+		if versions[i] != want[i] {
+			t.Errorf("versions[%d] = %+v, want %+v", i, versions[i], want[i])
+		}
+	}
+
+	t.Run("no versions", func(t *testing.T) {
+		versions, err := s.ListFileVersions(1, 1, "other.md")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(versions) != 0 {
+			t.Errorf("versions = %v, want none", versions)
+		}
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		_, err := s.ListFileVersions(1, 1, "../../etc/passwd")
+		if !storage.IsPathValidationError(err) {
+			t.Errorf("error = %v, want PathValidationError", err)
+		}
+	})
+}
+
+func TestRestoreFileVersion(t *testing.T) {
+	filePath := filepath.Join("test-root", "1", "1", "note.md")
+	versionPath := filepath.Join("test-root", ".versions", "1", "1", "note.md", "1000")
+
+	mockFS := NewMockFS()
+	mockFS.ReadFileReturns[versionPath] = struct {
+		data []byte
+		err  error
+	}{[]byte("old content"), nil}
+	mockFS.ReadFileReturns[filePath] = struct {
+		data []byte
+		err  error
+	}{[]byte("current content"), nil}
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:              mockFS,
+		MaxFileVersions: 5,
+	})
+
+	content, err := s.GetFileVersion(1, 1, "note.md", "1000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(content) != "old content" {
+		t.Errorf("content = %q, want %q", content, "old content")
+	}
+
+	if err := s.RestoreFileVersion(1, 1, "note.md", "1000"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(mockFS.WriteCalls[filePath]) != "old content" {
+		t.Errorf("file content = %q, want %q", mockFS.WriteCalls[filePath], "old content")
+	}
+
+	// The replaced content is kept, so the restore can be undone
+	kept := false
+	for path, data := range mockFS.WriteCalls {
+		if filepath.Dir(path) == filepath.Dir(versionPath) && string(data) == "current content" {
+			kept = true
+		}
+	}
+	if !kept {
+		t.Error("replaced content was not kept as a version")
+	}
+
+	t.Run("unknown version", func(t *testing.T) {
+		for _, version := range []string{"../1000", "latest", ""} {
+			_, err := s.GetFileVersion(1, 1, "note.md", version)
+			if !os.IsNotExist(err) {
+				t.Errorf("GetFileVersion(%q) error = %v, want not exist", version, err)
+			}
+		}
+
+		err := s.RestoreFileVersion(1, 1, "note.md", "2000")
+		if err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("error = %v, want file not found", err)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// walkDir is a directory visited by a walk, linked to the directory above it so that
+// followed symlinks leading back to a directory being walked can be detected
+type walkDir struct {
+	fullPath string
+	info     os.FileInfo
+	parent   *walkDir
+}
+
+// walkEntry is a file or directory visited by a walk
+type walkEntry struct {
+	entry    os.DirEntry
+	info     os.FileInfo
+	name     string
+	path     string
+	fullPath string
+	dir      *walkDir
+}
+
+// isDir reports whether the entry is a directory, or a followed symlink to one
+func (e walkEntry) isDir() bool {
+	return e.dir != nil
+}
+
+// Info returns the FileInfo of the entry, of the target for a followed symlink
+func (e walkEntry) Info() (os.FileInfo, error) {
+	if e.info != nil {
+		return e.info, nil
+	}
+	return e.entry.Info()
+}
+
+// newWalkDir returns the directory at fullPath to start a walk from
+func (s *Service) newWalkDir(fullPath string) *walkDir {
+	dir := &walkDir{fullPath: fullPath}
+	if s.followSymlinks {
+		// Only needed to detect symlink loops, a missing directory fails when it is read
+		dir.info, _ = s.fs.Stat(fullPath)
+	}
+	return dir
+}
+
+// readWalkDir returns the entries of dir visited by a walk, with their paths placed below prefix.
+// All walks share the same skip policy: the .git directory, the file versions and the trash are
+// left out, as are symlinks unless following symlinks is enabled. Followed symlinks are resolved,
+// dangling symlinks and symlinks to a directory that is already being walked are left out.
+func (s *Service) readWalkDir(dir *walkDir, prefix string) ([]walkEntry, error) {
+	entries, err := s.fs.ReadDir(dir.fullPath)
+	if err != nil {
+		return nil, err
+	}
+
+	walked := make([]walkEntry, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		fullPath := filepath.Join(dir.fullPath, name)
+		if name == ".git" || fullPath == s.versionsRoot() || fullPath == s.trashRoot() {
+			continue
+		}
+
+		e := walkEntry{
+			entry:    entry,
+			name:     name,
+			path:     filepath.Join(prefix, name),
+			fullPath: fullPath,
+		}
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			if !s.followSymlinks {
+				continue
+			}
+			e.info, err = s.fs.Stat(fullPath)
+			if s.fs.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			isDir = e.info.IsDir()
+			if isDir && dir.walking(e.info) {
+				continue
+			}
+		}
+
+		if isDir {
+			e.dir = &walkDir{fullPath: fullPath, info: e.info, parent: dir}
+			if s.followSymlinks && e.info == nil {
+				e.dir.info, _ = entry.Info()
+			}
+		}
+		walked = append(walked, e)
+	}
+	return walked, nil
+}
+
+// walking reports whether the directory with the given info is d or one of the directories above it
+func (d *walkDir) walking(info os.FileInfo) bool {
+	for ; d != nil; d = d.parent {
+		if d.info != nil && os.SameFile(d.info, info) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkFiles calls fn for every file below the directory at fullPath, with its path relative to
+// that directory, following the skip policy of readWalkDir. An error returned by fn stops the walk.
+func (s *Service) walkFiles(fullPath string, fn func(entry walkEntry) error) error {
+	return s.walkFilesBelow(s.newWalkDir(fullPath), "", fn)
+}
+
+// walkFilesBelow calls fn for every file below dir, with its path placed below prefix
+func (s *Service) walkFilesBelow(dir *walkDir, prefix string, fn func(entry walkEntry) error) error {
+	entries, err := s.readWalkDir(dir, prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.isDir() {
+			if err := s.walkFilesBelow(entry.dir, entry.path, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
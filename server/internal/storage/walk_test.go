@@ -0,0 +1,77 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestWalkSkipPolicy(t *testing.T) {
+	setup := func(t *testing.T, followSymlinks bool) *storage.Service {
+		s := storage.NewServiceWithOptions(t.TempDir(), storage.Options{
+			FollowSymlinks: followSymlinks,
+		})
+		if err := s.InitializeUserWorkspace(1, 1); err != nil {
+			t.Fatalf("failed to initialize workspace: %v", err)
+		}
+		workspacePath := s.GetWorkspacePath(1, 1)
+		for path, content := range map[string]string{
+			"a.md":            "alpha",
+			"notes/b.md":      "beta",
+			".git/HEAD.md":    "ref",
+			".trash/c.md":     "gamma",
+			"notes/.git/x.md": "nested",
+		} {
+			fullPath := filepath.Join(workspacePath, path)
+			if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+				t.Fatalf("failed to create directory: %v", err)
+			}
+			if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", path, err)
+			}
+		}
+		// A symlink back to the workspace would be walked forever if it was not detected
+		if err := os.Symlink(workspacePath, filepath.Join(workspacePath, "notes", "loop")); err != nil {
+			t.Fatalf("failed to create symlink: %v", err)
+		}
+		return s
+	}
+
+	// Each walk leaves out the .git directories and, unless followed, symlinks, while a
+	// directory named like the trash is regular content
+	want := []string{".trash/c.md", "a.md", "notes/b.md"}
+
+	for _, followSymlinks := range []bool{false, true} {
+		s := setup(t, followSymlinks)
+
+		paths, err := s.ListFilesByExtension(1, 1, []string{"md"})
+		if err != nil {
+			t.Fatalf("ListFilesByExtension() unexpected error: %v", err)
+		}
+		if !slices.Equal(paths, want) {
+			t.Errorf("ListFilesByExtension() with followSymlinks %v = %v, want %v", followSymlinks, paths, want)
+		}
+
+		nodes, err := s.ListFilesRecursively(1, 1, false)
+		if err != nil {
+			t.Fatalf("ListFilesRecursively() unexpected error: %v", err)
+		}
+		paths = storage.FlattenFileNodes(nodes, false)
+		slices.Sort(paths)
+		if !slices.Equal(paths, want) {
+			t.Errorf("ListFilesRecursively() with followSymlinks %v = %v, want %v", followSymlinks, paths, want)
+		}
+
+		stats, err := s.GetTextStats(1, 1, ".", true)
+		if err != nil {
+			t.Fatalf("GetTextStats() unexpected error: %v", err)
+		}
+		if stats.Files != len(want) {
+			t.Errorf("GetTextStats() with followSymlinks %v counted %d files, want %d", followSymlinks, stats.Files, len(want))
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"unicode/utf8"
+)
+
+// wordsPerMinute is the average reading speed used for reading time estimates
+const wordsPerMinute = 200
+
+// ErrBinaryFile is returned when text statistics are requested for a binary file
+var ErrBinaryFile = errors.New("file is not a text file")
+
+// TextStats holds word, character and line counts for text content
+type TextStats struct {
+	Files       int `json:"files"`
+	Words       int `json:"words"`
+	Characters  int `json:"characters"`
+	Lines       int `json:"lines"`
+	ReadingTime int `json:"readingTime"` // estimated reading time in minutes
+}
+
+// WordCount returns the word, character and line counts of the content
+// along with an estimated reading time.
+func WordCount(content []byte) TextStats {
+	stats := TextStats{
+		Files:      1,
+		Words:      len(bytes.Fields(content)),
+		Characters: utf8.RuneCount(content),
+	}
+
+	if len(content) > 0 {
+		stats.Lines = bytes.Count(content, []byte("\n"))
+		if content[len(content)-1] != '\n' {
+			stats.Lines++
+		}
+	}
+
+	stats.ReadingTime = readingTime(stats.Words)
+	return stats
+}
+
+// add adds the counts of other to the stats
+func (s *TextStats) add(other TextStats) {
+	s.Files += other.Files
+	s.Words += other.Words
+	s.Characters += other.Characters
+	s.Lines += other.Lines
+	s.ReadingTime = readingTime(s.Words)
+}
+
+// readingTime returns the reading time in whole minutes, rounded up
+func readingTime(words int) int {
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
+
+// isBinary reports whether the content looks like binary data
+func isBinary(content []byte) bool {
+	sample := content
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+	}
+	return bytes.IndexByte(sample, 0) != -1 || !utf8.Valid(content)
+}
+
+// GetTextStats returns the text statistics of the file at the given filePath.
+// If recursive is true, filePath is treated as a directory and the statistics of all
+// text files within it are summed up, skipping binary files and the .git directory.
+// Symlinks are skipped unless following symlinks is enabled, dangling symlinks are always skipped.
+// Path must be a relative path within the workspace directory given by userID and workspaceID.
+func (s *Service) GetTextStats(userID, workspaceID int, filePath string, recursive bool) (*TextStats, error) {
+	fullPath, err := s.ValidatePath(userID, workspaceID, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !recursive {
+		content, err := s.fs.ReadFile(fullPath)
+		if err != nil {
+			return nil, err
+		}
+		if isBinary(content) {
+			return nil, ErrBinaryFile
+		}
+		stats := WordCount(content)
+		return &stats, nil
+	}
+
+	stats := &TextStats{}
+	err = s.walkFiles(fullPath, func(entry walkEntry) error {
+		content, err := s.fs.ReadFile(entry.fullPath)
+		if err != nil {
+			return err
+		}
+		if !isBinary(content) {
+			stats.add(WordCount(content))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
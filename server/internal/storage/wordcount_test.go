@@ -0,0 +1,156 @@
+package storage_test
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lemma/internal/storage"
+	_ "lemma/internal/testenv"
+)
+
+func TestWordCount(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected storage.TextStats
+	}{
+		{
+			name:     "empty content",
+			content:  "",
+			expected: storage.TextStats{Files: 1},
+		},
+		{
+			name:     "single line",
+			content:  "Hello, world!",
+			expected: storage.TextStats{Files: 1, Words: 2, Characters: 13, Lines: 1, ReadingTime: 1},
+		},
+		{
+			name:     "multiple lines with trailing newline",
+			content:  "# Title\n\nSome  text here.\n",
+			expected: storage.TextStats{Files: 1, Words: 5, Characters: 26, Lines: 3, ReadingTime: 1},
+		},
+		{
+			name:     "unicode characters",
+			content:  "héllo wörld",
+			expected: storage.TextStats{Files: 1, Words: 2, Characters: 11, Lines: 1, ReadingTime: 1},
+		},
+		{
+			name:     "long text reading time",
+			content:  strings.Repeat("word ", 401),
+			expected: storage.TextStats{Files: 1, Words: 401, Characters: 2005, Lines: 1, ReadingTime: 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := storage.WordCount([]byte(tc.content))
+			if got != tc.expected {
+				t.Errorf("WordCount() = %+v, want %+v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetTextStats(t *testing.T) {
+	mockFS := NewMockFS()
+	s := storage.NewServiceWithOptions("test-root", storage.Options{
+		Fs:           mockFS,
+		NewGitClient: nil,
+	})
+
+	root := filepath.Join("test-root", "1", "1")
+	files := map[string][]byte{
+		filepath.Join(root, "note.md"):            []byte("one two three\nfour\n"),
+		filepath.Join(root, "docs", "guide.md"):   []byte("five six"),
+		filepath.Join(root, "image.png"):          {0x89, 'P', 'N', 'G', 0x00, 0x01},
+		filepath.Join(root, ".git", "HEAD"):       []byte("ref: refs/heads/main"),
+		filepath.Join(root, "docs", "binary.bin"): {0xff, 0xfe, 0x00},
+	}
+	for path, content := range files {
+		mockFS.ReadFileReturns[path] = struct {
+			data []byte
+			err  error
+		}{content, nil}
+	}
+	mockFS.ReadDirReturns = map[string]struct {
+		entries []fs.DirEntry
+		err     error
+	}{
+		root: {
+			entries: []fs.DirEntry{
+				NewMockDirEntry("note.md", false),
+				NewMockDirEntry("image.png", false),
+				NewMockDirEntry("docs", true),
+				NewMockDirEntry(".git", true),
+			},
+		},
+		filepath.Join(root, "docs"): {
+			entries: []fs.DirEntry{
+				NewMockDirEntry("guide.md", false),
+				NewMockDirEntry("binary.bin", false),
+			},
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		filePath  string
+		recursive bool
+		expected  storage.TextStats
+		wantErr   error
+	}{
+		{
+			name:     "single file",
+			filePath: "note.md",
+			expected: storage.TextStats{Files: 1, Words: 4, Characters: 19, Lines: 2, ReadingTime: 1},
+		},
+		{
+			name:     "binary file",
+			filePath: "image.png",
+			wantErr:  storage.ErrBinaryFile,
+		},
+		{
+			name:      "recursive workspace",
+			filePath:  "",
+			recursive: true,
+			expected:  storage.TextStats{Files: 2, Words: 6, Characters: 27, Lines: 3, ReadingTime: 1},
+		},
+		{
+			name:      "recursive subdirectory",
+			filePath:  "docs",
+			recursive: true,
+			expected:  storage.TextStats{Files: 1, Words: 2, Characters: 8, Lines: 1, ReadingTime: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.GetTextStats(1, 1, tc.filePath, tc.recursive)
+
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if *got != tc.expected {
+				t.Errorf("GetTextStats() = %+v, want %+v", *got, tc.expected)
+			}
+		})
+	}
+
+	t.Run("path traversal", func(t *testing.T) {
+		_, err := s.GetTextStats(1, 1, "../../../etc/passwd", false)
+		if !storage.IsPathValidationError(err) {
+			t.Errorf("expected path validation error, got %v", err)
+		}
+	})
+}
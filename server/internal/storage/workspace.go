@@ -1,7 +1,10 @@
 package storage
 
 import (
+	"archive/zip"
 	"fmt"
+	"io"
+	"io/fs"
 	"path/filepath"
 	"strings"
 )
@@ -12,6 +15,9 @@ type WorkspaceManager interface {
 	GetWorkspacePath(userID, workspaceID int) string
 	InitializeUserWorkspace(userID, workspaceID int) error
 	DeleteUserWorkspace(userID, workspaceID int) error
+	MoveWorkspaceStorage(fromUserID, toUserID, workspaceID int) error
+	ExportWorkspace(userID, workspaceID int, zw *zip.Writer, prefix string) error
+	WriteZip(userID, workspaceID int, subPath string, w io.Writer) error
 }
 
 // ValidatePath validates the if the given path is valid within the workspace directory.
@@ -33,14 +39,51 @@ func (s *Service) ValidatePath(userID, workspaceID int, path string) (string, er
 		return "", &PathValidationError{Path: path, Message: "path traversal attempt"}
 	}
 
+	if !s.followSymlinks {
+		if err := s.rejectSymlinks(workspacePath, cleanPath); err != nil {
+			return "", &PathValidationError{Path: path, Message: err.Error()}
+		}
+	}
+
 	return cleanPath, nil
 }
 
+// rejectSymlinks returns an error if any existing component of fullPath below
+// workspacePath is a symlink, as it could point outside of the workspace.
+func (s *Service) rejectSymlinks(workspacePath, fullPath string) error {
+	relPath, err := filepath.Rel(workspacePath, fullPath)
+	if err != nil || relPath == "." {
+		return nil
+	}
+
+	current := workspacePath
+	for _, part := range strings.Split(relPath, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		info, err := s.fs.Lstat(current)
+		if err != nil {
+			// The rest of the path does not exist yet
+			return nil
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("symlinks not allowed")
+		}
+	}
+	return nil
+}
+
 // GetWorkspacePath returns the path to the workspace directory for the given userID and workspaceID.
 func (s *Service) GetWorkspacePath(userID, workspaceID int) string {
 	return filepath.Join(s.RootDir, fmt.Sprintf("%d", userID), fmt.Sprintf("%d", workspaceID))
 }
 
+// workspaceDataPaths returns the directories kept for the workspace outside of its directory
+func (s *Service) workspaceDataPaths(userID, workspaceID int) []string {
+	return []string{
+		s.getVersionsPath(userID, workspaceID),
+		s.getTrashPath(userID, workspaceID),
+	}
+}
+
 // InitializeUserWorkspace creates the workspace directory for the given userID and workspaceID.
 func (s *Service) InitializeUserWorkspace(userID, workspaceID int) error {
 	log := getLogger()
@@ -57,7 +100,7 @@ func (s *Service) InitializeUserWorkspace(userID, workspaceID int) error {
 	return nil
 }
 
-// DeleteUserWorkspace deletes the workspace directory for the given userID and workspaceID.
+// DeleteUserWorkspace deletes the workspace directory, file versions and trash for the given userID and workspaceID.
 func (s *Service) DeleteUserWorkspace(userID, workspaceID int) error {
 	log := getLogger()
 	log.Debug("deleting workspace directory",
@@ -69,6 +112,57 @@ func (s *Service) DeleteUserWorkspace(userID, workspaceID int) error {
 	if err != nil {
 		return fmt.Errorf("failed to delete workspace directory: %w", err)
 	}
+	for _, path := range s.workspaceDataPaths(userID, workspaceID) {
+		if err := s.fs.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to delete workspace data: %w", err)
+		}
+	}
+	s.invalidateCaches(userID, workspaceID)
+
+	return nil
+}
+
+// MoveWorkspaceStorage moves the workspace directory of workspaceID from the storage of fromUserID to toUserID.
+// File versions and trash move along. The git repository of the previous owner is disabled, it has to be set up again for the new owner.
+func (s *Service) MoveWorkspaceStorage(fromUserID, toUserID, workspaceID int) error {
+	log := getLogger()
+	log.Debug("moving workspace directory",
+		"fromUserID", fromUserID,
+		"toUserID", toUserID,
+		"workspaceID", workspaceID)
+
+	srcPath := s.GetWorkspacePath(fromUserID, workspaceID)
+	dstPath := s.GetWorkspacePath(toUserID, workspaceID)
+
+	if _, err := s.fs.Stat(srcPath); err != nil {
+		return fmt.Errorf("failed to find workspace directory: %w", err)
+	}
+	if _, err := s.fs.Stat(dstPath); err == nil {
+		return fmt.Errorf("workspace directory already exists for user %d", toUserID)
+	}
+
+	if err := s.fs.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return fmt.Errorf("failed to create user directory: %w", err)
+	}
+	if err := s.fs.MoveFile(srcPath, dstPath); err != nil {
+		return fmt.Errorf("failed to move workspace directory: %w", err)
+	}
+
+	dstDataPaths := s.workspaceDataPaths(toUserID, workspaceID)
+	for i, srcData := range s.workspaceDataPaths(fromUserID, workspaceID) {
+		if _, err := s.fs.Stat(srcData); err != nil {
+			continue
+		}
+		if err := s.fs.MkdirAll(filepath.Dir(dstDataPaths[i]), 0755); err != nil {
+			return fmt.Errorf("failed to create workspace data directory: %w", err)
+		}
+		if err := s.fs.MoveFile(srcData, dstDataPaths[i]); err != nil {
+			return fmt.Errorf("failed to move workspace data: %w", err)
+		}
+	}
+
+	s.invalidateCaches(fromUserID, workspaceID)
+	s.DisableGitRepo(fromUserID, workspaceID)
 
 	return nil
 }
@@ -2,6 +2,7 @@ package storage_test
 
 import (
 	"errors"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -96,6 +97,114 @@ func TestValidatePath(t *testing.T) {
 	}
 }
 
+func TestSymlinks(t *testing.T) {
+	// Directory outside of the storage root the symlinks point to
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.md"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("failed to write outside file: %v", err)
+	}
+
+	setup := func(t *testing.T, followSymlinks bool) *storage.Service {
+		s := storage.NewServiceWithOptions(t.TempDir(), storage.Options{
+			FollowSymlinks: followSymlinks,
+		})
+		if err := s.InitializeUserWorkspace(1, 1); err != nil {
+			t.Fatalf("failed to initialize workspace: %v", err)
+		}
+		workspacePath := s.GetWorkspacePath(1, 1)
+		if err := os.WriteFile(filepath.Join(workspacePath, "note.md"), []byte("note"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if err := os.Symlink(outside, filepath.Join(workspacePath, "linked-dir")); err != nil {
+			t.Fatalf("failed to create symlink: %v", err)
+		}
+		if err := os.Symlink(filepath.Join(outside, "secret.md"), filepath.Join(workspacePath, "linked.md")); err != nil {
+			t.Fatalf("failed to create symlink: %v", err)
+		}
+		return s
+	}
+
+	t.Run("rejected by default", func(t *testing.T) {
+		s := setup(t, false)
+
+		for _, path := range []string{"linked.md", "linked-dir/secret.md", "linked-dir/new.md"} {
+			if _, err := s.ValidatePath(1, 1, path); !storage.IsPathValidationError(err) {
+				t.Errorf("ValidatePath(%q) error = %v, want path validation error", path, err)
+			}
+		}
+
+		if _, err := s.GetFileContent(1, 1, "linked-dir/secret.md"); !storage.IsPathValidationError(err) {
+			t.Errorf("GetFileContent() error = %v, want path validation error", err)
+		}
+		if err := s.SaveFile(1, 1, "linked-dir/new.md", []byte("escaped")); !storage.IsPathValidationError(err) {
+			t.Errorf("SaveFile() error = %v, want path validation error", err)
+		}
+		if _, err := os.Stat(filepath.Join(outside, "new.md")); !os.IsNotExist(err) {
+			t.Error("file was written outside of the workspace")
+		}
+
+		// Regular and not yet existing paths are still valid
+		for _, path := range []string{"note.md", "new/dir/file.md"} {
+			if _, err := s.ValidatePath(1, 1, path); err != nil {
+				t.Errorf("ValidatePath(%q) unexpected error: %v", path, err)
+			}
+		}
+
+		// Symlinks are skipped when counting words, dangling ones included
+		if err := os.Symlink(filepath.Join(outside, "missing.md"), filepath.Join(s.GetWorkspacePath(1, 1), "dangling.md")); err != nil {
+			t.Fatalf("failed to create symlink: %v", err)
+		}
+		stats, err := s.GetTextStats(1, 1, ".", true)
+		if err != nil {
+			t.Fatalf("GetTextStats() unexpected error: %v", err)
+		}
+		if stats.Files != 1 || stats.Words != 1 {
+			t.Errorf("GetTextStats() = %+v, want only note.md counted", stats)
+		}
+		if err := os.Remove(filepath.Join(s.GetWorkspacePath(1, 1), "dangling.md")); err != nil {
+			t.Fatalf("failed to remove symlink: %v", err)
+		}
+
+		// Symlinks are skipped when listing
+		nodes, err := s.ListFilesRecursively(1, 1, false)
+		if err != nil {
+			t.Fatalf("failed to list files: %v", err)
+		}
+		if len(nodes) != 1 || nodes[0].Name != "note.md" {
+			t.Errorf("ListFilesRecursively() = %+v, want only note.md", nodes)
+		}
+	})
+
+	t.Run("followed when enabled", func(t *testing.T) {
+		s := setup(t, true)
+
+		content, err := s.GetFileContent(1, 1, "linked.md")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(content) != "secret" {
+			t.Errorf("content = %q, want %q", content, "secret")
+		}
+
+		// Linked files and directories are counted
+		stats, err := s.GetTextStats(1, 1, ".", true)
+		if err != nil {
+			t.Fatalf("GetTextStats() unexpected error: %v", err)
+		}
+		if stats.Files != 3 || stats.Words != 3 {
+			t.Errorf("GetTextStats() = %+v, want note.md and both links to secret.md counted", stats)
+		}
+
+		nodes, err := s.ListFilesRecursively(1, 1, false)
+		if err != nil {
+			t.Fatalf("failed to list files: %v", err)
+		}
+		if len(nodes) != 3 {
+			t.Errorf("ListFilesRecursively() returned %d nodes, want 3", len(nodes))
+		}
+	})
+}
+
 func TestGetWorkspacePath(t *testing.T) {
 	mockFS := NewMockFS()
 	s := storage.NewServiceWithOptions("test-root", storage.Options{
@@ -274,3 +383,49 @@ func TestDeleteUserWorkspace(t *testing.T) {
 		})
 	}
 }
+
+func TestMoveWorkspaceStorage(t *testing.T) {
+	s := storage.NewService(t.TempDir())
+	if err := s.InitializeUserWorkspace(1, 1); err != nil {
+		t.Fatalf("failed to initialize workspace: %v", err)
+	}
+	if err := s.SaveFile(1, 1, "notes/note.md", []byte("content")); err != nil {
+		t.Fatalf("failed to save file: %v", err)
+	}
+
+	if err := s.MoveWorkspaceStorage(1, 2, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := s.GetFileContent(2, 1, "notes/note.md")
+	if err != nil {
+		t.Fatalf("file not found in the new location: %v", err)
+	}
+	if string(content) != "content" {
+		t.Errorf("content = %q, want %q", content, "content")
+	}
+	if _, err := os.Stat(s.GetWorkspacePath(1, 1)); !os.IsNotExist(err) {
+		t.Errorf("expected old workspace directory to be removed, got %v", err)
+	}
+
+	t.Run("missing source", func(t *testing.T) {
+		if err := s.MoveWorkspaceStorage(1, 2, 1); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	t.Run("existing destination", func(t *testing.T) {
+		if err := s.InitializeUserWorkspace(3, 1); err != nil {
+			t.Fatalf("failed to initialize workspace: %v", err)
+		}
+		err := s.MoveWorkspaceStorage(2, 3, 1)
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Errorf("error = %v, want error containing %q", err, "already exists")
+		}
+
+		// The workspace is left in place
+		if _, err := s.GetFileContent(2, 1, "notes/note.md"); err != nil {
+			t.Errorf("file no longer accessible: %v", err)
+		}
+	})
+}